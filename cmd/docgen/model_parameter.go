@@ -18,6 +18,11 @@ import (
 	"regexp"
 	"strings"
 
+	"sqlflow.org/sqlflow/pkg/sql/codegen/catboost"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/forecast"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/lightgbm"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/pytorch"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/sklearn"
 	"sqlflow.org/sqlflow/pkg/sql/codegen/tensorflow"
 	"sqlflow.org/sqlflow/pkg/sql/codegen/xgboost"
 )
@@ -32,6 +37,11 @@ SQLFlow connects a SQL engine (e.g., MySQL, Hive, or MaxCompute) and TensorFlow
 	docGenFunc := []func() string{
 		xgboost.DocGenInMarkdown,
 		tensorflow.DocGenInMarkdown,
+		pytorch.DocGenInMarkdown,
+		lightgbm.DocGenInMarkdown,
+		catboost.DocGenInMarkdown,
+		sklearn.DocGenInMarkdown,
+		forecast.DocGenInMarkdown,
 	}
 
 	section := regexp.MustCompile(`^#{1,5} `)
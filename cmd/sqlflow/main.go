@@ -28,7 +28,6 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
-	"syscall"
 
 	"github.com/joho/godotenv"
 	"golang.org/x/crypto/ssh/terminal"
@@ -53,6 +52,14 @@ const dotEnvFilename string = ".sqlflow_env"
 // if we are on sixel supported platform, assumed to be true for now
 var it2Check = true
 
+// outputFormat is the format of the table results, one of "table", "csv",
+// "tsv" and "json"
+var outputFormat = "table"
+
+// outputFile is the file that `\o file` redirects the table results to,
+// nil for the standard output
+var outputFile *os.File
+
 func isSpace(c byte) bool {
 	return len(bytes.TrimSpace([]byte{c})) == 0
 }
@@ -202,15 +209,19 @@ func runStmt(serverAddr string, stmt string, isTerminal bool, ds string) error {
 	if len(parts) == 2 && strings.ToUpper(parts[0]) == "USE" {
 		return switchDatabase(serverAddr, ds, parts[1])
 	}
+	// special case, process `\o file` to redirect the table results
+	if len(parts) > 0 && parts[0] == `\o` && len(parts) <= 2 {
+		return redirectOutput(strings.Join(parts[1:], ""))
+	}
 	return runStmtOnServer(serverAddr, stmt, isTerminal, ds)
 }
 
 func runStmtOnServer(serverAddr string, stmt string, isTerminal bool, ds string) error {
-	if !isTerminal {
+	if !isTerminal && outputFormat == "table" {
 		fmt.Println("sqlflow>", stmt)
 	}
 
-	table, err := tablewriter.Create("ascii", tablePageSize, os.Stdout)
+	table, err := createTableWriter()
 	if err != nil {
 		return err
 	}
@@ -239,6 +250,7 @@ func runStmtOnServer(serverAddr string, stmt string, isTerminal bool, ds string)
 		table:      table,
 		isTerminal: isTerminal,
 		it2Check:   it2Check,
+		resumable:  req.Session.Resumable,
 	}
 	return renderRPCRespStream(renderCtx)
 }
@@ -287,6 +299,48 @@ func switchDatabase(serverAddr, ds, db string) error {
 	return nil
 }
 
+// redirectOutput redirects the table results to file, or to the standard
+// output if file is "".
+func redirectOutput(file string) error {
+	if outputFile != nil {
+		outputFile.Close()
+		outputFile = nil
+	}
+	if file == "" {
+		fmt.Println("Output redirected to the standard output")
+		return nil
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	outputFile = f
+	fmt.Println("Output redirected to", file)
+	return nil
+}
+
+// createTableWriter returns the writer of the table results. The format
+// is that of the extension of outputFile like .csv, if any, or else
+// outputFormat.
+func createTableWriter() (tablewriter.TableWriter, error) {
+	format, w := outputFormat, io.Writer(os.Stdout)
+	if outputFile != nil {
+		w = outputFile
+		switch ext := strings.TrimPrefix(filepath.Ext(outputFile.Name()), "."); ext {
+		case "csv", "tsv", "json":
+			format = ext
+		}
+	}
+	switch format {
+	case "table":
+		format = "ascii"
+	case "csv", "tsv", "json":
+	default:
+		return nil, fmt.Errorf("unknown output format %s, expecting table, csv, tsv or json", format)
+	}
+	return tablewriter.Create(format, tablePageSize, w)
+}
+
 // getDataSource generates a data source string that is using database `db` from the original dataSource
 func getDataSource(dataSource, db string) string {
 	if db == "" {
@@ -298,7 +352,11 @@ func getDataSource(dataSource, db string) string {
 	}
 	pieces := strings.Split(other, "?")
 	switch driver {
-	case "maxcompute", "alisa":
+	case "maxcompute", "alisa", "clickhouse":
+		key := "curr_project"
+		if driver == "clickhouse" {
+			key = "database"
+		}
 		var v url.Values = url.Values{}
 		if len(pieces) == 2 {
 			v, e = url.ParseQuery(pieces[1])
@@ -306,25 +364,36 @@ func getDataSource(dataSource, db string) string {
 				log.Fatalf("unrecognized data source '%s'", dataSource)
 			}
 		}
-		v["curr_project"] = []string{db}
+		v[key] = []string{db}
 		return fmt.Sprintf("%s://%s?%s", driver, pieces[0], v.Encode())
-	case "mysql":
+	case "mysql", "postgres":
 		fallthrough
 	case "hive":
 		pieces[0] = strings.Split(pieces[0], "/")[0] + "/" + db
 		return fmt.Sprintf("%s://%s", driver, strings.Join(pieces, "?"))
+	case "sqlite3":
+		// The database db is the file db.db next to the current one.
+		pieces[0] = filepath.Join(filepath.Dir(pieces[0]), db+".db")
+		return fmt.Sprintf("%s://%s", driver, strings.Join(pieces, "?"))
 	}
 	log.Fatalf("unknown database '%s' in data source'%s'", driver, dataSource)
 	return ""
 }
 
 // initEnvFromFile initializes environment variables from the .env file
+// homeDir returns the home directory of the user, $HOME, or %USERPROFILE%
+// on Windows.
+func homeDir() string {
+	d, _ := os.UserHomeDir()
+	return d
+}
+
 func initEnvFromFile(f string) {
 	_ = godotenv.Load(f)
 }
 
 func main() {
-	initEnvFromFile(filepath.Join(os.Getenv("HOME"), dotEnvFilename))
+	initEnvFromFile(filepath.Join(homeDir(), dotEnvFilename))
 	serverAddr := flag.String("sqlflow_server", "", "SQLFlow server address, in host:port form. You can set it from environment variable SQLFLOW_SERVER")
 	ds := flag.String("datasource", "", "database connect string")
 	cliStmt := flag.String("execute", "", "execute SQLFlow from command line.  e.g. --execute 'select * from table1'")
@@ -332,7 +401,25 @@ func main() {
 	sqlFileName := flag.String("file", "", "execute SQLFlow from file.  e.g. --file '~/iris_dnn.sql'")
 	flag.StringVar(sqlFileName, "f", "", "execute SQLFlow from file, short for --file")
 	noAutoCompletion := flag.Bool("A", false, "No auto completion for sqlflow models. This gives a quicker start.")
+	noCache := flag.Bool("no-cache", false, "Run the workflow steps even if their outputs are up to date. You can set it from environment variable SQLFLOW_NO_CACHE")
+	dryRun := flag.Bool("dry-run", false, "Print the generated programs and the workflow of the SQL statements without running them. You can set it from environment variable SQLFLOW_DRY_RUN")
+	cleanupOnFailure := flag.String("cleanup-on-failure", "", "Drop the tables and the models created by the program if a statement fails, one of none, tables, models and all. You can set it from environment variable SQLFLOW_CLEANUP_ON_FAILURE")
+	flag.StringVar(&outputFormat, "output-format", "table", "The format of the table results, one of table, csv, tsv and json")
+	vars := defines{}
+	flag.Var(vars, "define", "Define a template variable of the script of -e or -f, e.g. --define date=20200601 substitutes ${date} in the script. You can repeat it to define many variables")
 	flag.Parse()
+	if *noCache {
+		os.Setenv("SQLFLOW_NO_CACHE", "true")
+	}
+	if *dryRun {
+		os.Setenv("SQLFLOW_DRY_RUN", "true")
+	}
+	if *cleanupOnFailure != "" {
+		os.Setenv("SQLFLOW_CLEANUP_ON_FAILURE", *cleanupOnFailure)
+	}
+	if _, err := createTableWriter(); err != nil {
+		log.Fatal(err)
+	}
 	if *serverAddr == "" {
 		*serverAddr = os.Getenv("SQLFLOW_SERVER")
 	}
@@ -348,8 +435,20 @@ func main() {
 	if err != nil {
 		log.Fatalf("error SQLFLOW_DATASOURCE: %v", err)
 	}
+	if flag.Arg(0) == "model" {
+		if err := runModelCommand(*serverAddr, *ds, flag.Args()[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if flag.Arg(0) == "tensorboard" {
+		if err := runTensorBoardCommand(*serverAddr, *ds, flag.Args()[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
-	isTerminal := !flagPassed("execute", "e", "file", "f") && terminal.IsTerminal(syscall.Stdin)
+	isTerminal := !flagPassed("execute", "e", "file", "f") && terminal.IsTerminal(int(os.Stdin.Fd()))
 	sqlFile := os.Stdin
 
 	if flagPassed("file", "f") && *sqlFileName != "-" {
@@ -364,6 +463,11 @@ func main() {
 	if flagPassed("execute", "e") {
 		reader = strings.NewReader(strings.TrimSpace(*cliStmt))
 	}
+	if flagPassed("execute", "e", "file", "f") {
+		if reader, err = expandReader(reader, *sqlFileName, flagPassed("execute", "e"), vars); err != nil {
+			log.Fatal(err)
+		}
+	}
 	scanner := bufio.NewScanner(reader)
 	if isTerminal {
 		if !it2Check {
@@ -0,0 +1,73 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	pb "sqlflow.org/sqlflow/pkg/proto"
+	"sqlflow.org/sqlflow/pkg/tablewriter"
+)
+
+const modelUsage = "usage: sqlflow model diff model_a model_b"
+
+// runModelCommand runs the subcommand `sqlflow model`, whose args are
+// like ["diff", "model_a", "model_b"].
+func runModelCommand(serverAddr, ds string, args []string) error {
+	if len(args) == 3 && args[0] == "diff" {
+		return diffModels(serverAddr, ds, args[1], args[2])
+	}
+	return fmt.Errorf(modelUsage)
+}
+
+// diffModels prints the differences between the metadata of the models
+// modelA and modelB side by side.
+func diffModels(serverAddr, ds, modelA, modelB string) error {
+	conn, err := createRPCConn(serverAddr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	client := pb.NewSQLFlowClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	res, err := client.DiffModels(ctx, &pb.DiffModelsRequest{
+		ModelA:  modelA,
+		ModelB:  modelB,
+		Session: sqlRequest("", ds).Session,
+	})
+	if err != nil {
+		return err
+	}
+	if len(res.Differences) == 0 {
+		fmt.Printf("%s and %s have the same metadata\n", modelA, modelB)
+		return nil
+	}
+	table, err := tablewriter.Create("ascii", tablePageSize, os.Stdout)
+	if err != nil {
+		return err
+	}
+	if err := table.SetHeader(map[string]interface{}{"columnNames": []string{"Field", modelA, modelB}}); err != nil {
+		return err
+	}
+	for _, d := range res.Differences {
+		if err := table.AppendRow([]interface{}{d.Field, d.A, d.B}); err != nil {
+			return err
+		}
+	}
+	return table.Flush()
+}
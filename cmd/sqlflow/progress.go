@@ -0,0 +1,111 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"sqlflow.org/sqlflow/pkg/proto"
+	"sqlflow.org/sqlflow/pkg/sql"
+)
+
+const progressBarWidth = 30
+
+// progressBar renders the Progress responses of a TRAIN statement as a
+// live progress bar with the ETA.
+type progressBar struct {
+	start time.Time
+	// startDone is the fraction of the training done at start.
+	startDone float64
+	// open tells if the bar is on the current line of the terminal.
+	open bool
+}
+
+// progressFraction returns the fraction of the training done at p, or -1
+// if the number of epochs is unknown.
+func progressFraction(p *proto.Progress) float64 {
+	if p.Epochs <= 0 {
+		return -1
+	}
+	done := float64(p.Epoch)
+	if p.Step > 0 {
+		done = float64(p.Epoch - 1)
+		if p.Steps > 0 {
+			done += float64(p.Step) / float64(p.Steps)
+		}
+	}
+	return math.Min(done/float64(p.Epochs), 1)
+}
+
+// format returns the line of p at now like
+// "[=============>                ]  45% epoch 5/10, loss: 0.5, ETA 1m20s".
+// The ETA is estimated by the time since the first progress.
+func (b *progressBar) format(p *proto.Progress, now time.Time) string {
+	f := progressFraction(p)
+	if b.start.IsZero() {
+		b.start, b.startDone = now, f
+	}
+	desc := sql.Progress{
+		Epoch:   int(p.Epoch),
+		Epochs:  int(p.Epochs),
+		Step:    p.Step,
+		Steps:   p.Steps,
+		Metrics: p.Metrics,
+	}.String()
+	if f < 0 {
+		return desc
+	}
+	filled := int(f * progressBarWidth)
+	bar := strings.Repeat("=", filled)
+	if filled < progressBarWidth {
+		bar += ">" + strings.Repeat(" ", progressBarWidth-filled-1)
+	}
+	line := fmt.Sprintf("[%s] %3.0f%% %s", bar, f*100, desc)
+	if f < 1 && f > b.startDone {
+		eta := time.Duration(float64(now.Sub(b.start)) / (f - b.startDone) * (1 - f))
+		line += ", ETA " + eta.Round(time.Second).String()
+	}
+	return line
+}
+
+// renderProgress renders p on the current line of the terminal, or logs
+// the ends of the epochs if not in a terminal.
+func renderProgress(ctx *renderContext, p *proto.Progress) {
+	if ctx.progress == nil {
+		ctx.progress = &progressBar{}
+	}
+	line := ctx.progress.format(p, time.Now())
+	if !ctx.isTerminal {
+		if p.Step == 0 {
+			log.Println(line)
+		}
+		return
+	}
+	// \x1b[K clears the rest of the previous line.
+	fmt.Printf("\r%s\x1b[K", line)
+	ctx.progress.open = true
+}
+
+// closeProgress moves to the next line after the progress bar, if any,
+// for the other responses.
+func closeProgress(ctx *renderContext) {
+	if ctx.progress != nil && ctx.progress.open {
+		fmt.Println()
+		ctx.progress.open = false
+	}
+}
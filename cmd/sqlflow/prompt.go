@@ -189,7 +189,7 @@ func (p *promptState) initHistory() {
 		p.navigateHistory(origInput, older, buf)
 	}
 	// Load existing history file
-	p.historyFileName = filepath.Join(os.Getenv("HOME"), ".sqlflow_history")
+	p.historyFileName = filepath.Join(homeDir(), ".sqlflow_history")
 	f, err := os.Open(p.historyFileName)
 	if err != nil {
 		return
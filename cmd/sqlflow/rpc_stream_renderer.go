@@ -18,8 +18,12 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"strconv"
 	"time"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
 	"sqlflow.org/sqlflow/pkg/proto"
 	"sqlflow.org/sqlflow/pkg/sql"
 	"sqlflow.org/sqlflow/pkg/step"
@@ -34,9 +38,23 @@ type renderContext struct {
 	isTerminal bool
 	it2Check   bool
 	output     *os.File
+	// resumable tells if the job of stream resumes by Fetch after
+	// disconnected.
+	resumable bool
+	// progress renders the Progress responses, nil before the first one.
+	progress *progressBar
 }
 
+// resumeRetries is the number of the retries of Fetch while the server
+// is unavailable when resuming a job.
+const resumeRetries = 30
+
 func render(ctx *renderContext, obj interface{}) error {
+	if r, ok := obj.(*proto.Response_Progress); ok {
+		renderProgress(ctx, r.Progress)
+		return nil
+	}
+	closeProgress(ctx)
 	var renderObj interface{}
 	switch r := obj.(type) {
 	case error:
@@ -70,17 +88,24 @@ func render(ctx *renderContext, obj interface{}) error {
 // renderRPCRespStream read all output from rpc
 // and render them according to calling environment
 func renderRPCRespStream(ctx *renderContext) (err error) {
+	received := 0
 	for {
 		var resp *proto.Response
 		resp, err = ctx.stream.Recv()
 		if err != nil {
 			if err.Error() == "EOF" {
 				err = nil
+			} else if id := resumableJob(ctx, err); id != "" {
+				err = resumeRPCRespStream(ctx, id, received)
+				if err != nil {
+					render(ctx, err)
+				}
 			} else {
 				render(ctx, err)
 			}
 			break
 		}
+		received++
 		render(ctx, resp.Response)
 		// the last response type is Job for the workflow mode, so break the loop here
 		if r, ok := resp.Response.(*proto.Response_Job); ok {
@@ -92,6 +117,7 @@ func renderRPCRespStream(ctx *renderContext) (err error) {
 			break
 		}
 	}
+	closeProgress(ctx)
 	ctx.table.Flush()
 	return
 }
@@ -112,3 +138,50 @@ func renderPRCFetchResp(ctx *renderContext, fetchReq *proto.FetchRequest) error
 		time.Sleep(time.Second)
 	}
 }
+
+// resumableJob returns the id of the job of ctx.stream if it can resume
+// from the error err of the stream, or "" if not.
+func resumableJob(ctx *renderContext, err error) string {
+	if !ctx.resumable || status.Code(err) != codes.Unavailable {
+		return ""
+	}
+	md, e := ctx.stream.Header()
+	if e != nil {
+		return ""
+	}
+	if ids := md.Get(proto.JobIDHeader); len(ids) == 1 {
+		return ids[0]
+	}
+	return ""
+}
+
+// resumeRPCRespStream fetches and renders the responses of the job id
+// after the received ones, and retries while the server is unavailable.
+func resumeRPCRespStream(ctx *renderContext, id string, received int) error {
+	req := &proto.FetchRequest{Job: &proto.Job{Id: id}, ResumeToken: strconv.Itoa(received)}
+	for retries := 0; ; {
+		resp, err := ctx.client.Fetch(ctx.ctx, req)
+		if status.Code(err) == codes.Unavailable && retries < resumeRetries {
+			retries++
+			time.Sleep(time.Second)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		retries = 0
+		for _, r := range resp.Responses.Response {
+			render(ctx, r.Response)
+			if j, ok := r.Response.(*proto.Response_Job); ok {
+				return renderPRCFetchResp(ctx, &proto.FetchRequest{Job: &proto.Job{Id: j.Job.Id}})
+			}
+		}
+		if resp.Eof {
+			return nil
+		}
+		if len(resp.Responses.Response) == 0 {
+			time.Sleep(time.Second)
+		}
+		req = resp.UpdatedFetchSince
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// reVariable matches the template variables like ${date} in the scripts.
+	reVariable   = regexp.MustCompile(`\$\{[A-Za-z_][A-Za-z0-9_]*\}`)
+	reIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// defines are the template variables of the -define key=value flags.
+type defines map[string]string
+
+func (d defines) String() string {
+	kvs := []string{}
+	for k, v := range d {
+		kvs = append(kvs, k+"="+v)
+	}
+	sort.Strings(kvs)
+	return strings.Join(kvs, ",")
+}
+
+func (d defines) Set(kv string) error {
+	kv = strings.TrimSpace(kv)
+	i := strings.Index(kv, "=")
+	if i < 0 || !reIdentifier.MatchString(kv[:i]) {
+		return fmt.Errorf("invalid -define %q, expecting key=value where key is an identifier", kv)
+	}
+	d[kv[:i]] = kv[i+1:]
+	return nil
+}
+
+// includeFile returns the file of the include directive `\i other.sql`
+// in line, or "" if line is not such a directive.
+func includeFile(line string) string {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, `\i `) {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimSuffix(line[3:], ";"))
+}
+
+// expandScript inlines the files of the include directives in script,
+// read from file or "" for the -e flag, and then substitutes the template
+// variables like ${date} by vars. The included files are relative to the
+// directory of file, or the working directory if file is "". It returns
+// an error if any variable is not in vars, so the scheduled jobs never
+// run a half-substituted script.
+func expandScript(script, file string, vars defines) (string, error) {
+	dir, including := ".", []string{}
+	if file != "" {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return "", err
+		}
+		dir, including = filepath.Dir(abs), []string{abs}
+	}
+	expanded, err := includeScripts(script, dir, including)
+	if err != nil {
+		return "", err
+	}
+	var undefined []string
+	expanded = reVariable.ReplaceAllStringFunc(expanded, func(v string) string {
+		name := v[2 : len(v)-1]
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		undefined = append(undefined, name)
+		return v
+	})
+	if len(undefined) > 0 {
+		return "", fmt.Errorf("undefined variables %s, please set them by -define key=value", strings.Join(undefined, ", "))
+	}
+	return expanded, nil
+}
+
+// includeScripts inlines the included files in script recursively.
+// including are the files being included, to detect the cycles.
+func includeScripts(script, dir string, including []string) (string, error) {
+	lines := strings.Split(script, "\n")
+	for i, line := range lines {
+		f := includeFile(line)
+		if f == "" {
+			continue
+		}
+		if !filepath.IsAbs(f) {
+			f = filepath.Join(dir, f)
+		}
+		f, err := filepath.Abs(f)
+		if err != nil {
+			return "", err
+		}
+		for _, g := range including {
+			if g == f {
+				return "", fmt.Errorf("%s includes itself by %s", f, strings.Join(append(including, f), " -> "))
+			}
+		}
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			return "", fmt.Errorf("include %s: %v", f, err)
+		}
+		included, err := includeScripts(string(b), filepath.Dir(f), append(including, f))
+		if err != nil {
+			return "", err
+		}
+		lines[i] = included
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// expandReader reads the script of the -e or -f flag from r and returns
+// the expanded script. file is "-" for the standard input.
+func expandReader(r io.Reader, file string, execute bool, vars defines) (io.Reader, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if execute || file == "-" {
+		file = ""
+	}
+	script, err := expandScript(string(b), file, vars)
+	if err != nil {
+		return nil, err
+	}
+	return strings.NewReader(script), nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+
+	"sqlflow.org/sqlflow/pkg/model"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+)
+
+const tensorBoardUsage = "usage: sqlflow tensorboard [-logdir dir] [-port port] [-no-launch] model"
+
+// runTensorBoardCommand runs the subcommand `sqlflow tensorboard`, which
+// downloads the TensorBoard logs of the model into a directory under
+// logdir named by the model, and launches TensorBoard on logdir, so the
+// models downloaded before show up as the other runs.
+func runTensorBoardCommand(serverAddr, ds string, args []string) error {
+	fs := flag.NewFlagSet("tensorboard", flag.ContinueOnError)
+	logDir := fs.String("logdir", filepath.Join(homeDir(), ".sqlflow_tensorboard"), "The directory to keep the TensorBoard logs of the models")
+	port := fs.Int("port", 6006, "The port of TensorBoard")
+	noLaunch := fs.Bool("no-launch", false, "Download the TensorBoard logs without launching TensorBoard")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf(tensorBoardUsage)
+	}
+	modelName := fs.Arg(0)
+	dir := filepath.Join(*logDir, modelName)
+	if err := downloadTensorBoardLogs(serverAddr, ds, modelName, dir); err != nil {
+		return err
+	}
+	fmt.Printf("Saved the TensorBoard logs of %s to %s\n", modelName, dir)
+	if *noLaunch {
+		return nil
+	}
+	cmd := exec.Command("tensorboard", "--logdir", *logDir, "--port", strconv.Itoa(*port))
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.Error); ok {
+			return fmt.Errorf("launch TensorBoard failed: %v, install it by `pip install tensorboard`, or launch it by `tensorboard --logdir %s`", err, *logDir)
+		}
+		return err
+	}
+	return nil
+}
+
+// downloadTensorBoardLogs replaces dir with the TensorBoard logs of the
+// model, and leaves dir as is if the download fails.
+func downloadTensorBoardLogs(serverAddr, ds, modelName, dir string) error {
+	conn, err := createRPCConn(serverAddr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := pb.NewSQLFlowClient(conn).GetTensorBoardLogs(ctx, &pb.TensorBoardLogsRequest{
+		Model:   modelName,
+		Session: sqlRequest("", ds).Session,
+	})
+	if err != nil {
+		return err
+	}
+	pr, pw := io.Pipe()
+	go func() {
+		for {
+			logs, err := stream.Recv()
+			if err == io.EOF {
+				pw.Close()
+				return
+			}
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			if _, err := pw.Write(logs.Content); err != nil {
+				return
+			}
+		}
+	}()
+	tmp := dir + ".downloading"
+	if err := os.RemoveAll(tmp); err != nil {
+		return err
+	}
+	if err := model.ExtractTensorBoardLogs(ctx, pr, tmp); err != nil {
+		pr.CloseWithError(err)
+		os.RemoveAll(tmp)
+		return err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dir)
+}
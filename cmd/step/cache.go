@@ -0,0 +1,124 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"log"
+	"os"
+	"strings"
+
+	"sqlflow.org/sqlflow/pkg/auth"
+	"sqlflow.org/sqlflow/pkg/database"
+	"sqlflow.org/sqlflow/pkg/parser"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+	"sqlflow.org/sqlflow/pkg/stepcache"
+)
+
+// defaultCacheTable is the step cache table in the database of the
+// datasource, if SQLFLOW_STEP_CACHE_TABLE is empty.
+const defaultCacheTable = "sqlflow_step_cache"
+
+// cachedStep returns the stepcache.Step of sqlStmt, or nil if it's not
+// a TRAIN, PREDICT, EVALUATE or EXPLAIN statement writing tables.
+func cachedStep(sqlStmt, dialect string) (*stepcache.Step, error) {
+	// Unescape sqlStmt like step.RunSQLProgramAndPrintResult.
+	sqlStmt = strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\r`, "\r").Replace(sqlStmt)
+	stmts, e := parser.Parse(dialect, sqlStmt)
+	if e != nil {
+		return nil, e
+	}
+	if len(stmts) != 1 || !stmts[0].IsExtendedSyntax() {
+		return nil, nil
+	}
+	s := stmts[0]
+	var attrs parser.Attributes
+	model, output := "", ""
+	switch {
+	case s.Train:
+		attrs, output = s.TrainAttrs, s.Save
+	case s.Predict:
+		attrs, model = s.PredAttrs, s.Model
+		// The result table of PREDICT is in the form of [db.]table.column.
+		if i := strings.LastIndex(s.Into, "."); i > 0 {
+			output = s.Into[:i]
+		}
+	case s.Explain:
+		attrs, model, output = s.ExplainAttrs, s.TrainedModel, s.ExplainInto
+	case s.Evaluate:
+		attrs, model, output = s.EvaluateAttrs, s.ModelToEvaluate, s.EvaluateInto
+	}
+	if output == "" {
+		return nil, nil
+	}
+	step := &stepcache.Step{SQL: s.Original, Attributes: map[string]string{}, Outputs: []string{output}}
+	for k, v := range attrs {
+		step.Attributes[k] = v.String()
+	}
+	inputs := append(append([]string{}, s.Inputs...), auth.QualifiedTables(s.StandardSelect.String())...)
+	if model != "" {
+		inputs = append(inputs, model)
+	}
+	for _, t := range inputs {
+		if t != output {
+			step.Inputs = append(step.Inputs, t)
+		}
+	}
+	return step, nil
+}
+
+// runWithCache runs sqlStmt by run, unless the cache has it that the
+// outputs of sqlStmt are up to date, or sess.NoCache.  The steps loading
+// the models from modelDir are not cached.
+func runWithCache(sqlStmt, modelDir string, sess *pb.Session) error {
+	if sess.NoCache || modelDir != "" {
+		return run(sqlStmt, modelDir, sess)
+	}
+	db, e := database.OpenAndConnectDBWithSession(sess)
+	if e != nil {
+		return e
+	}
+	defer db.Close()
+	step, e := cachedStep(sqlStmt, db.DriverName)
+	if e != nil || step == nil {
+		return run(sqlStmt, modelDir, sess)
+	}
+	table := os.Getenv("SQLFLOW_STEP_CACHE_TABLE")
+	if table == "" {
+		table = defaultCacheTable
+	}
+	cache, e := stepcache.New(db, table)
+	if e != nil {
+		log.Printf("step cache disabled: %v", e)
+		return run(sqlStmt, modelDir, sess)
+	}
+	fingerprint, e := cache.Fingerprint(step)
+	if e != nil {
+		log.Printf("step not cached: %v", e)
+		return run(sqlStmt, modelDir, sess)
+	}
+	if hit, e := cache.Hit(fingerprint, step.Outputs); e != nil {
+		log.Printf("look up the step cache failed: %v", e)
+	} else if hit {
+		log.Printf("SQLFlow Step Skipped:\n%s\nthe outputs %s are up to date, set SQLFLOW_NO_CACHE=true to run it anyway\n",
+			sqlStmt, strings.Join(step.Outputs, ", "))
+		return nil
+	}
+	if e := run(sqlStmt, modelDir, sess); e != nil {
+		return e
+	}
+	if e := cache.Save(fingerprint, step.Outputs); e != nil {
+		log.Printf("save the step cache failed: %v", e)
+	}
+	return nil
+}
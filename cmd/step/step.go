@@ -16,7 +16,10 @@ package main
 import (
 	"flag"
 	"log"
+	"os"
+	"path/filepath"
 
+	"sqlflow.org/sqlflow/pkg/model"
 	pb "sqlflow.org/sqlflow/pkg/proto"
 	"sqlflow.org/sqlflow/pkg/sql"
 	"sqlflow.org/sqlflow/pkg/step"
@@ -32,22 +35,40 @@ func (w *logWriter) Write(b []byte) (int, error) {
 	return len(b), nil
 }
 
-func run(sqlStmt string, sess *pb.Session) error {
+func run(sqlStmt, modelDir string, sess *pb.Session) error {
 	log.Printf("SQLFlow Step Execute:\n%s\n", sqlStmt)
 	tw, e := tablewriter.Create("protobuf", tablePageSize, &logWriter{})
 	if e != nil {
 		log.Fatalf("create tablewriter failed: %v", e)
 	}
 
-	return step.RunSQLProgramAndPrintResult(sqlStmt, "", sess, tw, false, false)
+	return step.RunSQLProgramAndPrintResult(sqlStmt, modelDir, sess, tw, false, false)
+}
+
+// exportModel copies the model saved in the datasource as name to the
+// directory dir, where the following steps load it by -model_dir.
+func exportModel(name, dir string, sess *pb.Session) error {
+	if e := os.MkdirAll(dir, 0755); e != nil {
+		return e
+	}
+	return model.Copy(name, "file://"+filepath.Join(dir, name), sess)
 }
 
 func main() {
 	execute := flag.String("execute", "", "execute SQLFlow from command line.  e.g. --execute 'select * from table1'")
 	flag.StringVar(execute, "e", "", "execute SQLFlow from command line, short for --execute")
+	modelDir := flag.String("model_dir", "", "models would be saved to and loaded from the local dir, otherwise the tables.")
+	export := flag.String("export_model", "", "copy the model in the table to -export_dir after executing, e.g. --export_model sqlflow_models.my_dnn_model")
+	exportDir := flag.String("export_dir", "", "the local dir to export the model of -export_model to.")
 	flag.Parse()
 
-	if e := run(*execute, sql.MakeSessionFromEnv()); e != nil {
+	sess := sql.MakeSessionFromEnv()
+	if e := runWithCache(*execute, *modelDir, sess); e != nil {
 		log.Fatal(e)
 	}
+	if *export != "" {
+		if e := exportModel(*export, *exportDir, sess); e != nil {
+			log.Fatalf("export model %s failed: %v", *export, e)
+		}
+	}
 }
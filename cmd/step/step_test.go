@@ -37,7 +37,7 @@ func TestStepStandardSQL(t *testing.T) {
 	session := makeTestSession(dbConnStr)
 	sql := `SELECT * FROM iris.train limit 5;`
 	out, e := step.GetStdout(func() error {
-		return run(sql, session)
+		return run(sql, "", session)
 	})
 	// check output result
 	a.NoError(e)
@@ -71,7 +71,7 @@ func TestStepSQLWithComment(t *testing.T) {
 	SELECT 1, 'a';\n\t
 `
 	_, e := step.GetStdout(func() error {
-		return run(sql, session)
+		return run(sql, "", session)
 	})
 	a.NoError(e)
 }
@@ -23,10 +23,17 @@ pip install --quiet \
     numpy==1.16.2 \
     tensorflow==2.0.1 \
     impyla==0.16.0 \
+    clickhouse-driver==0.1.3 \
+    psycopg2-binary==2.8.4 \
     pyodps==0.8.3 \
     dill==0.3.0 \
     shap==0.30.1 \
     xgboost==0.90 \
+    torch==1.4.0 \
+    lightgbm==2.3.1 \
+    catboost==0.22 \
     oss2==2.9.0 \
     plotille==3.7 \
-    seaborn==0.9.0
+    seaborn==0.9.0 \
+    tf2onnx==1.5.6 \
+    onnxmltools==1.6.1
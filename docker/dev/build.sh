@@ -22,7 +22,7 @@ cd $SQLFLOWPATH
 SQLFLOW_BIN=$SQLFLOWPATH/build
 echo "Build $SQLFLOWPATH into $SQLFLOW_BIN ..."
 
-echo "Build sqlflowserver, sqlflow, and step into $SQLFLOW_BIN ..."
+echo "Build sqlflowserver, sqlflowserving, sqlflow, and step into $SQLFLOW_BIN ..."
 go generate ./...
 GOBIN=$SQLFLOW_BIN go install ./...
 
@@ -23,12 +23,30 @@ public class CalciteParserAdaptor extends BaseParser {
     return "calcite";
   }
 
+  /**
+   * the configuration of the Calcite parser, which the dialects based on Calcite override for their
+   * quoting, casing and conformance.
+   *
+   * @return the parser configuration
+   */
+  protected SqlParser.Config parserConfig() {
+    return SqlParser.configBuilder().setParserFactory(SqlDdlParserImpl.FACTORY).build();
+  }
+
+  /**
+   * the lexical state of the tokenizer splitting statements, which should match the quoting of
+   * parserConfig, so that the quoted identifiers containing ';' are not split.
+   *
+   * @return one of the lexical states in SqlDdlParserImplTokenManager
+   */
+  protected int lexicalState() {
+    return SqlDdlParserImplTokenManager.DEFAULT;
+  }
+
   @Override
   protected int parseOneStmt(String sql, ParseResult result) throws Exception {
     try {
-      SqlParser.Config sqlParserConfig =
-          SqlParser.configBuilder().setParserFactory(SqlDdlParserImpl.FACTORY).build();
-      SqlParser parser = SqlParser.create(sql, sqlParserConfig);
+      SqlParser parser = SqlParser.create(sql, parserConfig());
       parser.parseQuery();
       return -1;
     } catch (SqlParseException e) {
@@ -38,9 +56,7 @@ public class CalciteParserAdaptor extends BaseParser {
 
   @Override
   protected boolean isSelectStmt(String sql) {
-    SqlParser.Config sqlParserConfig =
-        SqlParser.configBuilder().setParserFactory(SqlDdlParserImpl.FACTORY).build();
-    SqlParser parser = SqlParser.create(sql, sqlParserConfig);
+    SqlParser parser = SqlParser.create(sql, parserConfig());
     try {
       SqlNode node = parser.parseQuery();
       return SqlKind.QUERY.contains(node.getKind());
@@ -59,7 +75,7 @@ public class CalciteParserAdaptor extends BaseParser {
           }
         };
     // this lexer will automatically filter comments
-    SqlDdlParserImplTokenManager tm = new SqlDdlParserImplTokenManager(stream);
+    SqlDdlParserImplTokenManager tm = new SqlDdlParserImplTokenManager(stream, lexicalState());
     int pos = 0;
     boolean hasToken = false;
     while (true) {
@@ -107,7 +123,7 @@ public class CalciteParserAdaptor extends BaseParser {
           }
         };
     // this lexer will automatically filter comments
-    SqlDdlParserImplTokenManager tm = new SqlDdlParserImplTokenManager(stream);
+    SqlDdlParserImplTokenManager tm = new SqlDdlParserImplTokenManager(stream, lexicalState());
     Token token = tm.getNextToken();
     if (token.kind == SqlDdlParserImplTokenManager.EOF) {
       return sql.length();
@@ -0,0 +1,78 @@
+package org.sqlflow.parser.calcite;
+
+import org.apache.calcite.avatica.util.Casing;
+import org.apache.calcite.avatica.util.Quoting;
+import org.apache.calcite.sql.parser.SqlParser;
+import org.apache.calcite.sql.parser.ddl.SqlDdlParserImpl;
+import org.apache.calcite.sql.parser.ddl.SqlDdlParserImplTokenManager;
+import org.apache.calcite.sql.validate.SqlAbstractConformance;
+
+/**
+ * PostgresParserAdaptor parses the PostgreSQL dialect by Calcite, where the identifiers are quoted
+ * by double quotes, the unquoted identifiers are folded to lower case, and LIMIT is in the form of
+ * LIMIT count OFFSET start.
+ */
+public class PostgresParserAdaptor extends CalciteParserAdaptor {
+
+  public PostgresParserAdaptor() {}
+
+  @Override
+  public String dialect() {
+    return "postgresql";
+  }
+
+  private static class PostgresConformance extends SqlAbstractConformance {
+    @Override
+    public boolean isGroupByAlias() {
+      return true;
+    }
+
+    @Override
+    public boolean isGroupByOrdinal() {
+      return true;
+    }
+
+    @Override
+    public boolean isSortByOrdinal() {
+      return true;
+    }
+
+    @Override
+    public boolean isSortByAlias() {
+      return true;
+    }
+
+    @Override
+    public boolean isBangEqualAllowed() {
+      return true;
+    }
+
+    @Override
+    public boolean isPercentRemainderAllowed() {
+      return true;
+    }
+
+    // PostgreSQL rejects LIMIT start, count.
+    @Override
+    public boolean isLimitStartCountAllowed() {
+      return false;
+    }
+  }
+
+  @Override
+  protected SqlParser.Config parserConfig() {
+    return SqlParser.configBuilder()
+        .setParserFactory(SqlDdlParserImpl.FACTORY)
+        .setQuoting(Quoting.DOUBLE_QUOTE)
+        .setUnquotedCasing(Casing.TO_LOWER)
+        .setQuotedCasing(Casing.UNCHANGED)
+        .setCaseSensitive(true)
+        .setConformance(new PostgresConformance())
+        .build();
+  }
+
+  @Override
+  protected int lexicalState() {
+    return SqlDdlParserImplTokenManager.DQID;
+  }
+}
@@ -0,0 +1,87 @@
+package org.sqlflow.parser.calcite;
+
+import org.apache.calcite.avatica.util.Casing;
+import org.apache.calcite.avatica.util.Quoting;
+import org.apache.calcite.sql.parser.SqlParser;
+import org.apache.calcite.sql.parser.ddl.SqlDdlParserImpl;
+import org.apache.calcite.sql.parser.ddl.SqlDdlParserImplTokenManager;
+import org.apache.calcite.sql.validate.SqlAbstractConformance;
+
+/**
+ * SparkParserAdaptor parses the Spark SQL dialect by Calcite, where the identifiers are quoted by
+ * back-ticks and case-insensitive, LIMIT takes only the count, and MINUS is EXCEPT.
+ */
+public class SparkParserAdaptor extends CalciteParserAdaptor {
+
+  public SparkParserAdaptor() {}
+
+  @Override
+  public String dialect() {
+    return "sparksql";
+  }
+
+  private static class SparkConformance extends SqlAbstractConformance {
+    @Override
+    public boolean isGroupByAlias() {
+      return true;
+    }
+
+    @Override
+    public boolean isGroupByOrdinal() {
+      return true;
+    }
+
+    @Override
+    public boolean isHavingAlias() {
+      return true;
+    }
+
+    @Override
+    public boolean isSortByOrdinal() {
+      return true;
+    }
+
+    @Override
+    public boolean isSortByAlias() {
+      return true;
+    }
+
+    @Override
+    public boolean isBangEqualAllowed() {
+      return true;
+    }
+
+    @Override
+    public boolean isPercentRemainderAllowed() {
+      return true;
+    }
+
+    @Override
+    public boolean isMinusAllowed() {
+      return true;
+    }
+
+    // Spark SQL rejects LIMIT start, count.
+    @Override
+    public boolean isLimitStartCountAllowed() {
+      return false;
+    }
+  }
+
+  @Override
+  protected SqlParser.Config parserConfig() {
+    return SqlParser.configBuilder()
+        .setParserFactory(SqlDdlParserImpl.FACTORY)
+        .setQuoting(Quoting.BACK_TICK)
+        .setUnquotedCasing(Casing.UNCHANGED)
+        .setQuotedCasing(Casing.UNCHANGED)
+        .setCaseSensitive(false)
+        .setConformance(new SparkConformance())
+        .build();
+  }
+
+  @Override
+  protected int lexicalState() {
+    return SqlDdlParserImplTokenManager.BTID;
+  }
+}
@@ -0,0 +1,50 @@
+package org.sqlflow.parser.calcite;
+
+import static org.junit.Assert.assertEquals;
+import static org.junit.Assert.assertNotEquals;
+
+import java.util.List;
+import org.junit.Test;
+import org.sqlflow.parser.parse.ParseResult;
+
+public class PostgresParserAdaptorTest {
+  @Test
+  public void testParse() {
+    PostgresParserAdaptor parser = new PostgresParserAdaptor();
+    // double-quoted identifiers and LIMIT ... OFFSET
+    {
+      String sql =
+          "SELECT \"Sepal Length\", class FROM iris.train WHERE class != 0 LIMIT 10 OFFSET 5";
+      ParseResult parseResult = parser.parse(sql + ";");
+      assertEquals("", parseResult.error);
+      assertEquals(-1, parseResult.position);
+      assertEquals(1, parseResult.statements.size());
+      assertEquals(sql, parseResult.statements.get(0));
+    }
+    // the prefix of an extended statement
+    {
+      String sql = "SELECT * FROM iris.train ORDER BY 1 LIMIT 100 ";
+      ParseResult parseResult = parser.parse(sql + "TO TRAIN DNNClassifier LABEL class INTO m;");
+      assertEquals("", parseResult.error);
+      assertEquals(sql.length(), parseResult.position);
+      assertEquals(1, parseResult.statements.size());
+      assertEquals(sql, parseResult.statements.get(0));
+      assertEquals(true, parseResult.isUnfinishedSelect);
+    }
+    // MySQL's LIMIT start, count is not PostgreSQL, the program is not accepted in whole
+    {
+      ParseResult parseResult = parser.parse("SELECT * FROM iris.train LIMIT 5, 10;");
+      assertNotEquals(-1, parseResult.position);
+    }
+  }
+
+  @Test
+  public void testSplitQuotedIdentifiers() {
+    PostgresParserAdaptor parser = new PostgresParserAdaptor();
+    String single = "SELECT \"a;b\" FROM t;";
+    List<String> stmts = parser.splitStatements(single + single);
+    assertEquals(2, stmts.size());
+    assertEquals(single, stmts.get(0));
+    assertEquals(single, stmts.get(1));
+  }
+}
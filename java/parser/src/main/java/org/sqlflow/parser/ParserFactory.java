@@ -49,8 +49,8 @@ class ParserFactory {
               Object inst = c.getConstructor().newInstance();
               ParseInterface parser = (ParseInterface) inst;
               System.err.printf("ParserFactory loading class %s\n", className);
+              // A jar may have several parsers, like parser-calcite.
               parsers.put(parser.dialect(), c);
-              break;
             }
           }
         }
@@ -12,5 +12,7 @@ public class ParserFactoryTest {
     ParserFactory parserFactory = new ParserFactory(folderPath);
     parserFactory.newParser("hive");
     parserFactory.newParser("calcite");
+    parserFactory.newParser("postgresql");
+    parserFactory.newParser("sparksql");
   }
 }
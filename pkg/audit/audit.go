@@ -0,0 +1,121 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package audit records who ran which SQL programs on the SQLFlow
+// server, with what data and models, to a JSON lines file or a table.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// The status of a Record.
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Statement is a statement of a SQL program.
+type Statement struct {
+	// Type is "standard", "train", "predict", "explain", "evaluate" or
+	// "show_train".
+	Type string `json:"type"`
+	SQL  string `json:"sql"`
+	// Tables is the tables read or written by the statement, as far as
+	// the parser of the SQL dialect reports.
+	Tables []string `json:"tables,omitempty"`
+}
+
+// Record is a SQL program submitted to the server.
+type Record struct {
+	User     string `json:"user"`
+	SourceIP string `json:"source_ip"`
+	// Database is the database of the connection string.
+	Database   string      `json:"database"`
+	SQL        string      `json:"sql"`
+	Statements []Statement `json:"statements,omitempty"`
+	// ModelURIs is the models saved or loaded by the program, like
+	// "sqlflow_models.my_model".
+	ModelURIs []string  `json:"model_uris,omitempty"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	// RowsAffected is the sum of the rows affected by the standard
+	// statements, like INSERT, and RowsReturned is of the queries.
+	RowsAffected int64 `json:"rows_affected"`
+	RowsReturned int64 `json:"rows_returned"`
+	// WorkflowID is the workflow submitted in the workflow mode.
+	WorkflowID string `json:"workflow_id,omitempty"`
+}
+
+// Logger writes the Records.
+type Logger interface {
+	Log(r *Record) error
+}
+
+// MultiLogger writes the Records to each of the loggers.
+type MultiLogger []Logger
+
+// Log implements Logger.
+func (m MultiLogger) Log(r *Record) error {
+	errs := []string{}
+	for _, l := range m {
+		if e := l.Log(r); e != nil {
+			errs = append(errs, e.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("audit log failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// FileLogger writes the Records to a file in JSON lines.
+type FileLogger struct {
+	mu sync.Mutex
+	w  *lumberjack.Logger
+}
+
+// NewFileLogger returns a FileLogger appending to filename, which is
+// rotated per 32 megabytes with all the rotated files kept.
+func NewFileLogger(filename string) *FileLogger {
+	return &FileLogger{w: &lumberjack.Logger{
+		Filename: filename,
+		MaxSize:  32, // megabytes
+		Compress: true,
+	}}
+}
+
+// Log implements Logger.
+func (l *FileLogger) Log(r *Record) error {
+	b, e := json.Marshal(r)
+	if e != nil {
+		return e
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, e = l.w.Write(append(b, '\n'))
+	return e
+}
+
+// Close closes the file.
+func (l *FileLogger) Close() error {
+	return l.w.Close()
+}
@@ -0,0 +1,118 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"sqlflow.org/sqlflow/pkg/database"
+)
+
+func testRecord(sql string) *Record {
+	start := time.Date(2020, 5, 1, 8, 0, 0, 0, time.UTC)
+	return &Record{
+		User:       "alice",
+		SourceIP:   "10.0.0.1",
+		Database:   "iris",
+		SQL:        sql,
+		Statements: []Statement{{Type: "train", SQL: sql, Tables: []string{"iris.train"}}},
+		ModelURIs:  []string{"sqlflow_models.my_model"},
+		StartTime:  start,
+		EndTime:    start.Add(time.Minute),
+		Status:     StatusSucceeded,
+	}
+}
+
+func TestFileLogger(t *testing.T) {
+	a := assert.New(t)
+	dir, e := ioutil.TempDir("", "sqlflow_audit")
+	a.NoError(e)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "audit.jsonl")
+	l := NewFileLogger(filename)
+	a.NoError(l.Log(testRecord("SELECT 1;")))
+	a.NoError(l.Log(testRecord("SELECT 2;")))
+	a.NoError(l.Close())
+
+	b, e := ioutil.ReadFile(filename)
+	a.NoError(e)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	a.Equal(2, len(lines))
+	r := &Record{}
+	a.NoError(json.Unmarshal([]byte(lines[1]), r))
+	a.Equal(testRecord("SELECT 2;"), r)
+}
+
+type failLogger struct{}
+
+func (failLogger) Log(r *Record) error {
+	return fmt.Errorf("disk full")
+}
+
+func TestMultiLogger(t *testing.T) {
+	a := assert.New(t)
+	dir, e := ioutil.TempDir("", "sqlflow_audit")
+	a.NoError(e)
+	defer os.RemoveAll(dir)
+	l := NewFileLogger(filepath.Join(dir, "audit.jsonl"))
+	defer l.Close()
+	a.EqualError(MultiLogger{l, failLogger{}}.Log(testRecord("SELECT 1;")), "audit log failed: disk full")
+	_, e = os.Stat(filepath.Join(dir, "audit.jsonl"))
+	a.NoError(e)
+}
+
+func TestTableLogger(t *testing.T) {
+	a := assert.New(t)
+	dir, e := ioutil.TempDir("", "sqlflow_audit")
+	a.NoError(e)
+	defer os.RemoveAll(dir)
+	db, e := database.OpenAndConnectDB("sqlite3://" + filepath.Join(dir, "audit.db"))
+	a.NoError(e)
+	defer db.Close()
+
+	l, e := NewTableLogger(db, "audit_log")
+	a.NoError(e)
+	r := testRecord("SELECT * FROM iris.train TO TRAIN DNNClassifier INTO sqlflow_models.my_model;")
+	r.Status, r.Error, r.RowsAffected = StatusFailed, "runSQLProgram error", 3
+	a.NoError(l.Log(r))
+	// Creating the logger again keeps the records.
+	l, e = NewTableLogger(db, "audit_log")
+	a.NoError(e)
+	a.NoError(l.Log(testRecord("SELECT 1;")))
+
+	var user, models, start, status, errMsg, statements string
+	var affected int64
+	a.NoError(db.QueryRow("SELECT username, model_uris, start_time, status, error, rows_affected, statements FROM audit_log WHERE status = 'failed'").
+		Scan(&user, &models, &start, &status, &errMsg, &affected, &statements))
+	a.Equal("alice", user)
+	a.Equal("sqlflow_models.my_model", models)
+	a.Equal("2020-05-01T08:00:00.000000Z", start)
+	a.Equal("runSQLProgram error", errMsg)
+	a.Equal(int64(3), affected)
+	a.Contains(statements, `"tables":["iris.train"]`)
+	var n int
+	a.NoError(db.QueryRow("SELECT COUNT(*) FROM audit_log").Scan(&n))
+	a.Equal(2, n)
+
+	_, e = NewTableLogger(&database.DB{DriverName: "hive"}, "audit_log")
+	a.EqualError(e, "audit log to the tables of hive is not supported")
+}
@@ -0,0 +1,83 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"sqlflow.org/sqlflow/pkg/database"
+)
+
+// timeFormat is of fixed width, so the times in UTC sort as strings.
+const timeFormat = "2006-01-02T15:04:05.000000Z"
+
+var tableColumns = []string{"username", "source_ip", "db_name", "sql_text", "statements", "model_uris",
+	"start_time", "end_time", "status", "error", "rows_affected", "rows_returned", "workflow_id"}
+
+// TableLogger writes the Records to a table.
+type TableLogger struct {
+	db    *database.DB
+	table string
+}
+
+// NewTableLogger returns a TableLogger writing to table in db, which is
+// created if not exists. db is of MySQL, PostgreSQL or SQLite.
+func NewTableLogger(db *database.DB, table string) (*TableLogger, error) {
+	var text string
+	switch db.DriverName {
+	case "mysql":
+		// TEXT of MySQL is at most 64KB.
+		text = "LONGTEXT"
+	case "postgres", "sqlite3":
+		text = "TEXT"
+	default:
+		return nil, fmt.Errorf("audit log to the tables of %s is not supported", db.DriverName)
+	}
+	columns := []string{}
+	for _, c := range tableColumns {
+		typ := text
+		if strings.HasPrefix(c, "rows_") {
+			typ = "BIGINT"
+		}
+		columns = append(columns, c+" "+typ)
+	}
+	stmt := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", table, strings.Join(columns, ", "))
+	if _, e := db.Exec(stmt); e != nil {
+		return nil, fmt.Errorf("create the audit table %s failed: %v", table, e)
+	}
+	return &TableLogger{db: db, table: table}, nil
+}
+
+// Log implements Logger.
+func (l *TableLogger) Log(r *Record) error {
+	statements, e := json.Marshal(r.Statements)
+	if e != nil {
+		return e
+	}
+	placeholders := []string{}
+	for i := range tableColumns {
+		if l.db.DriverName == "postgres" {
+			placeholders = append(placeholders, fmt.Sprintf("$%d", i+1))
+		} else {
+			placeholders = append(placeholders, "?")
+		}
+	}
+	stmt := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", l.table, strings.Join(tableColumns, ", "), strings.Join(placeholders, ", "))
+	_, e = l.db.Exec(stmt, r.User, r.SourceIP, r.Database, r.SQL, string(statements), strings.Join(r.ModelURIs, ","),
+		r.StartTime.UTC().Format(timeFormat), r.EndTime.UTC().Format(timeFormat), r.Status, r.Error,
+		r.RowsAffected, r.RowsReturned, r.WorkflowID)
+	return e
+}
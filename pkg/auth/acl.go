@@ -0,0 +1,109 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+)
+
+// Wildcard in an ACL is any user or any database.
+const Wildcard = "*"
+
+// ACL is the databases that each user is allowed to access, like
+// {"alice": ["iris", "churn"], "admin": ["*"], "*": ["public"]}, where
+// the databases of the user "*" are allowed to all users.
+type ACL map[string][]string
+
+// LoadACL loads the ACL in the JSON file path.
+func LoadACL(path string) (ACL, error) {
+	b, e := ioutil.ReadFile(path)
+	if e != nil {
+		return nil, e
+	}
+	acl := ACL{}
+	if e := json.Unmarshal(b, &acl); e != nil {
+		return nil, fmt.Errorf("invalid ACL %s: %v", path, e)
+	}
+	return acl, nil
+}
+
+// Allow returns if user is allowed to access database. Only the users
+// allowed to access Wildcard can access "", the database of the
+// connection strings without one, where any database is accessible.
+func (acl ACL) Allow(user, database string) bool {
+	for _, u := range []string{user, Wildcard} {
+		for _, db := range acl[u] {
+			if db == Wildcard || (database != "" && strings.EqualFold(db, database)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Check returns an error if user is not allowed to access defaultDB,
+// the database of the connection string, or any of tables, whose
+// unqualified names are in defaultDB.
+func (acl ACL) Check(user, defaultDB string, tables []string) error {
+	if !acl.Allow(user, defaultDB) {
+		if defaultDB == "" {
+			return fmt.Errorf("user %s is not allowed to connect without a database", user)
+		}
+		return fmt.Errorf("user %s is not allowed to access database %s", user, defaultDB)
+	}
+	for _, t := range tables {
+		if db := DatabaseOf(t, defaultDB); !acl.Allow(user, db) {
+			return fmt.Errorf("user %s is not allowed to access database %s", user, db)
+		}
+	}
+	return nil
+}
+
+// DatabaseOf returns the database of table, like "iris" of
+// "iris.train", or defaultDB if table is unqualified.
+func DatabaseOf(table, defaultDB string) string {
+	if i := strings.Index(table, "."); i > 0 {
+		return strings.Trim(table[:i], "`")
+	}
+	return defaultDB
+}
+
+var qualifiedTableRE = regexp.MustCompile("(?i)\\b(?:from|join|into|table|update)\\s+(`?\\w+`?\\s*\\.\\s*`?\\w+`?)")
+
+// QualifiedTables returns the qualified table names following FROM,
+// JOIN, INTO, TABLE and UPDATE in sql, for the SQL dialects whose parsers
+// don't report the tables of statements. The tables in a FROM list after
+// the first are not found.
+func QualifiedTables(sql string) []string {
+	tables := []string{}
+	for _, m := range qualifiedTableRE.FindAllStringSubmatch(sql, -1) {
+		tables = append(tables, strings.Join(strings.Fields(strings.Replace(m[1], "`", "", -1)), ""))
+	}
+	return tables
+}
+
+var useRE = regexp.MustCompile("(?is)^(?:\\s+|--[^\\n]*\\n|/\\*.*?\\*/)*use\\s+`?(\\w+)`?\\s*;?\\s*$")
+
+// UsedDatabase returns the database of the USE statement sql, like
+// "iris" of "USE iris;", or "" if sql is not a USE statement.
+func UsedDatabase(sql string) string {
+	if m := useRE.FindStringSubmatch(sql); m != nil {
+		return m[1]
+	}
+	return ""
+}
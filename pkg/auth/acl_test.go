@@ -0,0 +1,70 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestACL(t *testing.T) {
+	a := assert.New(t)
+	dir, e := ioutil.TempDir("", "sqlflow_acl")
+	a.NoError(e)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "acl.json")
+	a.NoError(ioutil.WriteFile(path, []byte(`{"alice": ["iris", "churn"], "admin": ["*"], "*": ["public"]}`), 0644))
+	acl, e := LoadACL(path)
+	a.NoError(e)
+
+	a.True(acl.Allow("alice", "iris"))
+	a.True(acl.Allow("alice", "IRIS"))
+	a.True(acl.Allow("alice", "public"))
+	a.True(acl.Allow("bob", "public"))
+	a.False(acl.Allow("bob", "iris"))
+	a.False(acl.Allow("alice", ""))
+	a.True(acl.Allow("admin", ""))
+
+	a.NoError(acl.Check("alice", "iris", []string{"train", "churn.test", "public.t"}))
+	a.EqualError(acl.Check("alice", "iris", []string{"secret.t"}), "user alice is not allowed to access database secret")
+	a.EqualError(acl.Check("bob", "iris", nil), "user bob is not allowed to access database iris")
+	a.EqualError(acl.Check("alice", "", []string{"iris.train"}), "user alice is not allowed to connect without a database")
+	a.NoError(acl.Check("admin", "", []string{"secret.t"}))
+
+	a.NoError(ioutil.WriteFile(path, []byte(`["alice"]`), 0644))
+	_, e = LoadACL(path)
+	a.Error(e)
+}
+
+func TestQualifiedTables(t *testing.T) {
+	a := assert.New(t)
+	a.Equal([]string{"iris.train", "churn.test"}, QualifiedTables("SELECT t.a FROM iris.train t JOIN `churn` . `test` ON t.b = c.b"))
+	a.Equal([]string{"iris.t"}, QualifiedTables("INSERT INTO iris.t SELECT * FROM t2"))
+	a.Equal([]string{"iris.t"}, QualifiedTables("DROP TABLE IF EXISTS x; CREATE TABLE iris.t (a INT)"))
+	a.Equal([]string{}, QualifiedTables("SELECT a.b FROM t a"))
+	a.Equal("iris", DatabaseOf("iris.train", "churn"))
+	a.Equal("churn", DatabaseOf("train", "churn"))
+}
+
+func TestUsedDatabase(t *testing.T) {
+	a := assert.New(t)
+	a.Equal("iris", UsedDatabase("USE iris;"))
+	a.Equal("secret", UsedDatabase("-- switch\n/* to */ use `secret`\n"))
+	a.Equal("", UsedDatabase("SELECT * FROM users;"))
+	a.Equal("", UsedDatabase("SELECT 'use secret'"))
+}
@@ -0,0 +1,119 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package auth authenticates the callers of the SQLFlow gRPC server by
+// TLS client certificates or JWT/OIDC tokens, and authorizes them to
+// access databases by an ACL.
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrNoCredentials is returned by an Authenticator if the caller has no
+// credentials of its kind, so Chain tries the next one.
+var ErrNoCredentials = errors.New("no credentials")
+
+// Identity is an authenticated caller.
+type Identity struct {
+	User string
+	// Method is how the caller was authenticated, "tls" or "jwt".
+	Method string
+}
+
+// Authenticator authenticates the caller of a gRPC call.
+type Authenticator interface {
+	Authenticate(ctx context.Context) (*Identity, error)
+}
+
+// Chain returns an Authenticator trying each of auths in order, which
+// succeeds by the first one that finds the credentials of the caller.
+func Chain(auths ...Authenticator) Authenticator {
+	return chain(auths)
+}
+
+type chain []Authenticator
+
+func (c chain) Authenticate(ctx context.Context) (*Identity, error) {
+	for _, a := range c {
+		id, e := a.Authenticate(ctx)
+		if e == ErrNoCredentials {
+			continue
+		}
+		return id, e
+	}
+	return nil, ErrNoCredentials
+}
+
+type identityKey struct{}
+
+// NewContext returns a copy of ctx carrying id.
+func NewContext(ctx context.Context, id *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// FromContext returns the identity in ctx, nil if the caller was not
+// authenticated.
+func FromContext(ctx context.Context) *Identity {
+	id, _ := ctx.Value(identityKey{}).(*Identity)
+	return id
+}
+
+func authenticate(ctx context.Context, a Authenticator) (context.Context, error) {
+	id, e := a.Authenticate(ctx)
+	if e == ErrNoCredentials {
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+	if e != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "authentication failed: %v", e)
+	}
+	return NewContext(ctx, id), nil
+}
+
+// UnaryServerInterceptor rejects the unauthenticated unary calls, and
+// passes the identity of the caller to the handler in the context.
+func UnaryServerInterceptor(a Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, e := authenticate(ctx, a)
+		if e != nil {
+			return nil, e
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is the UnaryServerInterceptor of streaming
+// calls.
+func StreamServerInterceptor(a Authenticator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, e := authenticate(ss.Context(), a)
+		if e != nil {
+			return e
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
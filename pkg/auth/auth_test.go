@@ -0,0 +1,135 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func tlsContext(cn string) context.Context {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: cn}}
+	info := credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}}
+	return peer.NewContext(context.Background(), &peer.Peer{AuthInfo: info})
+}
+
+func TestTLSAuthenticator(t *testing.T) {
+	a := assert.New(t)
+	id, e := TLSAuthenticator{}.Authenticate(tlsContext("alice"))
+	a.NoError(e)
+	a.Equal(&Identity{User: "alice", Method: "tls"}, id)
+
+	_, e = TLSAuthenticator{}.Authenticate(tlsContext(""))
+	a.Error(e)
+	_, e = TLSAuthenticator{}.Authenticate(context.Background())
+	a.Equal(ErrNoCredentials, e)
+}
+
+func TestChain(t *testing.T) {
+	a := assert.New(t)
+	c := Chain(TLSAuthenticator{}, NewHMACAuthenticator(testSecret, "", "", ""))
+
+	id, e := c.Authenticate(tlsContext("alice"))
+	a.NoError(e)
+	a.Equal("alice", id.User)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+hs256Token(t, map[string]interface{}{"sub": "bob"})))
+	id, e = c.Authenticate(ctx)
+	a.NoError(e)
+	a.Equal(&Identity{User: "bob", Method: "jwt"}, id)
+
+	_, e = c.Authenticate(context.Background())
+	a.Equal(ErrNoCredentials, e)
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	a := assert.New(t)
+	intercept := UnaryServerInterceptor(TLSAuthenticator{})
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return FromContext(ctx).User, nil
+	}
+	user, e := intercept(tlsContext("alice"), nil, &grpc.UnaryServerInfo{}, handler)
+	a.NoError(e)
+	a.Equal("alice", user)
+
+	_, e = intercept(context.Background(), nil, &grpc.UnaryServerInfo{}, handler)
+	a.Equal(codes.Unauthenticated, status.Code(e))
+	_, e = intercept(tlsContext(""), nil, &grpc.UnaryServerInfo{}, handler)
+	a.Equal(codes.Unauthenticated, status.Code(e))
+}
+
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	a := assert.New(t)
+	intercept := StreamServerInterceptor(TLSAuthenticator{})
+	user := ""
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		user = FromContext(ss.Context()).User
+		return nil
+	}
+	a.NoError(intercept(nil, &fakeServerStream{ctx: tlsContext("alice")}, &grpc.StreamServerInfo{}, handler))
+	a.Equal("alice", user)
+
+	e := intercept(nil, &fakeServerStream{ctx: context.Background()}, &grpc.StreamServerInfo{}, handler)
+	a.Equal(codes.Unauthenticated, status.Code(e))
+}
+
+func TestHTTPContext(t *testing.T) {
+	a := assert.New(t)
+	c := Chain(TLSAuthenticator{}, NewHMACAuthenticator(testSecret, "", "", ""))
+
+	r := httptest.NewRequest(http.MethodPost, "/v1/run", nil)
+	r.Header.Set("Authorization", "Bearer "+hs256Token(t, map[string]interface{}{"sub": "bob"}))
+	ctx, e := HTTPContext(r, c)
+	a.NoError(e)
+	a.Equal(&Identity{User: "bob", Method: "jwt"}, FromContext(ctx))
+	p, ok := peer.FromContext(ctx)
+	a.True(ok)
+	a.Equal("192.0.2.1:1234", p.Addr.String())
+
+	r = httptest.NewRequest(http.MethodPost, "/v1/run", nil)
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "alice"}}
+	r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+	ctx, e = HTTPContext(r, c)
+	a.NoError(e)
+	a.Equal("alice", FromContext(ctx).User)
+
+	r = httptest.NewRequest(http.MethodPost, "/v1/run", nil)
+	_, e = HTTPContext(r, c)
+	a.Equal(codes.Unauthenticated, status.Code(e))
+	ctx, e = HTTPContext(r, nil)
+	a.NoError(e)
+	a.Nil(FromContext(ctx))
+}
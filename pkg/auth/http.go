@@ -0,0 +1,49 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// HTTPContext returns the context of the HTTP request r as that of a gRPC
+// call, whose metadata "authorization" is the Authorization header of r,
+// and whose peer has the address and the TLS connection of r, so the
+// Authenticators of the gRPC calls authenticate the HTTP requests too. If
+// a is not nil, it rejects the unauthenticated requests like
+// UnaryServerInterceptor, and the context carries the identity.
+func HTTPContext(r *http.Request, a Authenticator) (context.Context, error) {
+	ctx := r.Context()
+	if v := r.Header.Get("Authorization"); v != "" {
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", v))
+	}
+	p := &peer.Peer{}
+	if addr, e := net.ResolveTCPAddr("tcp", r.RemoteAddr); e == nil {
+		p.Addr = addr
+	}
+	if r.TLS != nil {
+		p.AuthInfo = credentials.TLSInfo{State: *r.TLS}
+	}
+	ctx = peer.NewContext(ctx, p)
+	if a == nil {
+		return ctx, nil
+	}
+	return authenticate(ctx, a)
+}
@@ -0,0 +1,271 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// leeway is the tolerated clock skew to check the exp and nbf claims.
+const leeway = time.Minute
+
+// JWTAuthenticator authenticates the callers by the JWT in the gRPC
+// metadata "authorization: Bearer <token>", signed by HS256 with a
+// shared secret, or by RS256 with the keys of an OIDC provider.
+type JWTAuthenticator struct {
+	issuer    string
+	audience  string
+	userClaim string
+	hmacKey   []byte
+	oidc      *oidcKeys
+	now       func() time.Time
+}
+
+// NewHMACAuthenticator returns a JWTAuthenticator of the tokens signed
+// by HS256 with secret. The tokens must be of issuer and audience
+// unless they are "", and the user is the claim userClaim, "sub" if "".
+func NewHMACAuthenticator(secret []byte, issuer, audience, userClaim string) *JWTAuthenticator {
+	return newJWTAuthenticator(issuer, audience, userClaim, secret, nil)
+}
+
+// NewOIDCAuthenticator returns a JWTAuthenticator of the ID tokens of
+// the OIDC provider issuer, like https://accounts.google.com. It gets the
+// signing keys of the provider by the OIDC discovery.
+func NewOIDCAuthenticator(issuer, audience, userClaim string) (*JWTAuthenticator, error) {
+	var conf struct {
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if e := getJSON(strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration", &conf); e != nil {
+		return nil, fmt.Errorf("OIDC discovery of %s failed: %v", issuer, e)
+	}
+	if conf.JWKSURI == "" {
+		return nil, fmt.Errorf("OIDC provider %s has no jwks_uri", issuer)
+	}
+	k := &oidcKeys{uri: conf.JWKSURI}
+	if e := k.refresh(); e != nil {
+		return nil, e
+	}
+	return newJWTAuthenticator(issuer, audience, userClaim, nil, k), nil
+}
+
+func newJWTAuthenticator(issuer, audience, userClaim string, hmacKey []byte, oidc *oidcKeys) *JWTAuthenticator {
+	if userClaim == "" {
+		userClaim = "sub"
+	}
+	return &JWTAuthenticator{
+		issuer:    issuer,
+		audience:  audience,
+		userClaim: userClaim,
+		hmacKey:   hmacKey,
+		oidc:      oidc,
+		now:       time.Now,
+	}
+}
+
+// Authenticate implements Authenticator.
+func (a *JWTAuthenticator) Authenticate(ctx context.Context) (*Identity, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+	for _, v := range md.Get("authorization") {
+		if strings.HasPrefix(v, "Bearer ") {
+			return a.Verify(strings.TrimPrefix(v, "Bearer "))
+		}
+	}
+	return nil, ErrNoCredentials
+}
+
+// Verify verifies token, and returns the identity of its user.
+func (a *JWTAuthenticator) Verify(token string) (*Identity, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed token")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if e := decodeSegment(parts[0], &header); e != nil {
+		return nil, fmt.Errorf("malformed token header: %v", e)
+	}
+	sig, e := base64.RawURLEncoding.DecodeString(parts[2])
+	if e != nil {
+		return nil, fmt.Errorf("malformed token signature: %v", e)
+	}
+	if e := a.verifySignature(header.Alg, header.Kid, parts[0]+"."+parts[1], sig); e != nil {
+		return nil, e
+	}
+	claims := map[string]interface{}{}
+	if e := decodeSegment(parts[1], &claims); e != nil {
+		return nil, fmt.Errorf("malformed token claims: %v", e)
+	}
+	return a.verifyClaims(claims)
+}
+
+func (a *JWTAuthenticator) verifySignature(alg, kid, signed string, sig []byte) error {
+	switch {
+	case alg == "HS256" && a.hmacKey != nil:
+		mac := hmac.New(sha256.New, a.hmacKey)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(mac.Sum(nil), sig) {
+			return fmt.Errorf("invalid token signature")
+		}
+		return nil
+	case alg == "RS256" && a.oidc != nil:
+		key, e := a.oidc.get(kid)
+		if e != nil {
+			return e
+		}
+		sum := sha256.Sum256([]byte(signed))
+		if rsa.VerifyPKCS1v15(key, crypto.SHA256, sum[:], sig) != nil {
+			return fmt.Errorf("invalid token signature")
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported token algorithm %q", alg)
+}
+
+func (a *JWTAuthenticator) verifyClaims(claims map[string]interface{}) (*Identity, error) {
+	now := a.now()
+	if exp, ok := claims["exp"].(float64); ok && now.After(time.Unix(int64(exp), 0).Add(leeway)) {
+		return nil, fmt.Errorf("token expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(leeway).Before(time.Unix(int64(nbf), 0)) {
+		return nil, fmt.Errorf("token not valid yet")
+	}
+	if a.issuer != "" && claims["iss"] != a.issuer {
+		return nil, fmt.Errorf("token of issuer %v, expected %s", claims["iss"], a.issuer)
+	}
+	if a.audience != "" && !hasAudience(claims["aud"], a.audience) {
+		return nil, fmt.Errorf("token not of audience %s", a.audience)
+	}
+	user, _ := claims[a.userClaim].(string)
+	if user == "" {
+		return nil, fmt.Errorf("token has no claim %s", a.userClaim)
+	}
+	return &Identity{User: user, Method: "jwt"}, nil
+}
+
+// hasAudience returns if aud, a string or a list of strings, has audience.
+func hasAudience(aud interface{}, audience string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == audience
+	case []interface{}:
+		for _, a := range v {
+			if a == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	b, e := base64.RawURLEncoding.DecodeString(seg)
+	if e != nil {
+		return e
+	}
+	return json.Unmarshal(b, v)
+}
+
+func getJSON(url string, v interface{}) error {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, e := client.Get(url)
+	if e != nil {
+		return e
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// minRefreshInterval limits the refreshing of the OIDC keys by the
+// tokens of unknown key IDs.
+const minRefreshInterval = time.Minute
+
+// oidcKeys is the RSA signing keys of an OIDC provider by key ID,
+// refreshed when a token is of an unknown key, since the providers
+// rotate their keys.
+type oidcKeys struct {
+	uri       string
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	refreshed time.Time
+}
+
+func (k *oidcKeys) get(kid string) (*rsa.PublicKey, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if key, ok := k.keys[kid]; ok {
+		return key, nil
+	}
+	if time.Since(k.refreshed) >= minRefreshInterval {
+		if e := k.refresh(); e != nil {
+			return nil, e
+		}
+		if key, ok := k.keys[kid]; ok {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown token key %q", kid)
+}
+
+func (k *oidcKeys) refresh() error {
+	var jwks struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if e := getJSON(k.uri, &jwks); e != nil {
+		return fmt.Errorf("get the OIDC keys failed: %v", e)
+	}
+	keys := map[string]*rsa.PublicKey{}
+	for _, jwk := range jwks.Keys {
+		if jwk.Kty != "RSA" {
+			continue
+		}
+		n, e := base64.RawURLEncoding.DecodeString(jwk.N)
+		if e != nil {
+			return fmt.Errorf("invalid OIDC key %s: %v", jwk.Kid, e)
+		}
+		exp, e := base64.RawURLEncoding.DecodeString(jwk.E)
+		if e != nil {
+			return fmt.Errorf("invalid OIDC key %s: %v", jwk.Kid, e)
+		}
+		keys[jwk.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(exp).Int64())}
+	}
+	k.keys, k.refreshed = keys, time.Now()
+	return nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testSecret = []byte("secret")
+
+func encodeSegment(t *testing.T, v interface{}) string {
+	b, e := json.Marshal(v)
+	assert.NoError(t, e)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func hs256Token(t *testing.T, claims map[string]interface{}) string {
+	signed := encodeSegment(t, map[string]string{"alg": "HS256", "typ": "JWT"}) + "." + encodeSegment(t, claims)
+	mac := hmac.New(sha256.New, testSecret)
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func rs256Token(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	signed := encodeSegment(t, map[string]string{"alg": "RS256", "kid": kid}) + "." + encodeSegment(t, claims)
+	sum := sha256.Sum256([]byte(signed))
+	sig, e := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+	assert.NoError(t, e)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestHMACAuthenticator(t *testing.T) {
+	a := assert.New(t)
+	auth := NewHMACAuthenticator(testSecret, "sqlflow", "sqlflowserver", "email")
+	now := time.Now().Unix()
+	claims := func(m map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{"iss": "sqlflow", "aud": "sqlflowserver", "email": "alice@example.com", "exp": now + 60}
+		for k, v := range m {
+			c[k] = v
+		}
+		return c
+	}
+
+	id, e := auth.Verify(hs256Token(t, claims(nil)))
+	a.NoError(e)
+	a.Equal(&Identity{User: "alice@example.com", Method: "jwt"}, id)
+	_, e = auth.Verify(hs256Token(t, claims(map[string]interface{}{"aud": []string{"a", "sqlflowserver"}})))
+	a.NoError(e)
+
+	_, e = auth.Verify(hs256Token(t, claims(map[string]interface{}{"exp": now - 3600})))
+	a.EqualError(e, "token expired")
+	_, e = auth.Verify(hs256Token(t, claims(map[string]interface{}{"nbf": now + 3600})))
+	a.EqualError(e, "token not valid yet")
+	_, e = auth.Verify(hs256Token(t, claims(map[string]interface{}{"iss": "evil"})))
+	a.EqualError(e, "token of issuer evil, expected sqlflow")
+	_, e = auth.Verify(hs256Token(t, claims(map[string]interface{}{"aud": "other"})))
+	a.EqualError(e, "token not of audience sqlflowserver")
+	_, e = auth.Verify(hs256Token(t, claims(map[string]interface{}{"email": ""})))
+	a.EqualError(e, "token has no claim email")
+
+	token := hs256Token(t, claims(nil))
+	_, e = auth.Verify(token[:len(token)-2] + "AA")
+	a.EqualError(e, "invalid token signature")
+	_, e = auth.Verify(encodeSegment(t, map[string]string{"alg": "none"}) + "." + encodeSegment(t, claims(nil)) + ".")
+	a.EqualError(e, `unsupported token algorithm "none"`)
+	_, e = auth.Verify("not a token")
+	a.EqualError(e, "malformed token")
+}
+
+func TestOIDCAuthenticator(t *testing.T) {
+	a := assert.New(t)
+	key, e := rsa.GenerateKey(rand.Reader, 2048)
+	a.NoError(e)
+	var issuer string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"issuer": %q, "jwks_uri": %q}`, issuer, issuer+"/keys")
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"keys": [{"kty": "RSA", "kid": "k1", "n": %q, "e": %q}]}`,
+			base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()))
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	issuer = ts.URL
+
+	auth, e := NewOIDCAuthenticator(issuer, "sqlflow", "")
+	a.NoError(e)
+	claims := map[string]interface{}{"iss": issuer, "aud": "sqlflow", "sub": "alice", "exp": time.Now().Unix() + 60}
+	id, e := auth.Verify(rs256Token(t, key, "k1", claims))
+	a.NoError(e)
+	a.Equal("alice", id.User)
+
+	_, e = auth.Verify(rs256Token(t, key, "k2", claims))
+	a.EqualError(e, `unknown token key "k2"`)
+	other, e := rsa.GenerateKey(rand.Reader, 2048)
+	a.NoError(e)
+	_, e = auth.Verify(rs256Token(t, other, "k1", claims))
+	a.EqualError(e, "invalid token signature")
+	_, e = auth.Verify(hs256Token(t, claims))
+	a.EqualError(e, `unsupported token algorithm "HS256"`)
+}
@@ -0,0 +1,93 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+)
+
+func TestDatabaseParseOptions(t *testing.T) {
+	a := assert.New(t)
+	opts := &Options{MaxOpenConns: 2, Retries: 1}
+	dataSource, e := parseOptions("root:root@tcp(127.0.0.1:3306)/iris?maxAllowedPacket=0&sqlflow_max_open_conns=10&sqlflow_keep_alive=1m&sqlflow_retries=3", opts)
+	a.NoError(e)
+	a.Equal("root:root@tcp(127.0.0.1:3306)/iris?maxAllowedPacket=0", dataSource)
+	a.Equal(&Options{MaxOpenConns: 10, KeepAlive: time.Minute, Retries: 3}, opts)
+
+	dataSource, e = parseOptions("root:root@127.0.0.1:10000/iris?sqlflow_conn_max_lifetime=1h&sqlflow_retry_delay=2s&sqlflow_max_retry_delay=1m&sqlflow_max_idle_conns=-1", opts)
+	a.NoError(e)
+	a.Equal("root:root@127.0.0.1:10000/iris", dataSource)
+	a.Equal(time.Hour, opts.ConnMaxLifetime)
+	a.Equal(2*time.Second, opts.retryDelay())
+	a.Equal(time.Minute, opts.maxRetryDelay())
+	a.Equal(-1, opts.MaxIdleConns)
+
+	dataSource, e = parseOptions("root:root@127.0.0.1:10000/iris?auth=NOSASL", opts)
+	a.NoError(e)
+	a.Equal("root:root@127.0.0.1:10000/iris?auth=NOSASL", dataSource)
+
+	_, e = parseOptions("root:root@127.0.0.1:10000/iris?sqlflow_unknown=1", opts)
+	a.Error(e)
+	_, e = parseOptions("root:root@127.0.0.1:10000/iris?sqlflow_retries=three", opts)
+	a.Error(e)
+}
+
+func TestDatabaseSessionOptions(t *testing.T) {
+	a := assert.New(t)
+	opts, e := SessionOptions(nil)
+	a.NoError(e)
+	a.Nil(opts)
+
+	opts, e = SessionOptions(&pb.Session{DbMaxOpenConns: 5, DbRetries: 2, DbKeepAlive: "30s"})
+	a.NoError(e)
+	a.Equal(&Options{MaxOpenConns: 5, Retries: 2, KeepAlive: 30 * time.Second}, opts)
+	a.Equal(defaultRetryDelay, opts.retryDelay())
+	a.Equal(defaultMaxRetryDelay, opts.maxRetryDelay())
+
+	_, e = SessionOptions(&pb.Session{DbRetryDelay: "soon"})
+	a.Error(e)
+}
+
+func TestDatabaseOpenWithOptions(t *testing.T) {
+	a := assert.New(t)
+	dir, e := ioutil.TempDir("", "sqlflow_sqlite")
+	a.NoError(e)
+	defer os.RemoveAll(dir)
+
+	url := "sqlite3://" + filepath.Join(dir, "iris.db") + "?sqlflow_max_open_conns=3&sqlflow_keep_alive=10ms"
+	db, e := OpenAndConnectDBWithOptions(url, &Options{MaxOpenConns: 1})
+	a.NoError(e)
+	a.Equal(url, db.URL())
+	a.Equal(3, db.Stats().MaxOpenConnections)
+	time.Sleep(50 * time.Millisecond)
+	a.NoError(db.Close())
+
+	db, e = OpenAndConnectDBWithSession(&pb.Session{DbConnStr: "sqlite3://" + filepath.Join(dir, "iris.db"), DbMaxOpenConns: 2})
+	a.NoError(e)
+	a.Equal(2, db.Stats().MaxOpenConnections)
+	a.NoError(db.Close())
+
+	// The directory doesn't exist, so every ping fails.
+	start := time.Now()
+	_, e = OpenAndConnectDBWithOptions("sqlite3://"+filepath.Join(dir, "no", "such", "iris.db"), &Options{Retries: 2, RetryDelay: 10 * time.Millisecond})
+	a.Error(e)
+	a.True(time.Since(start) >= 30*time.Millisecond)
+}
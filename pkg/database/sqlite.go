@@ -0,0 +1,98 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// SQLite has no CREATE DATABASE, and a table like iris.train is in the
+// database attached by ATTACH DATABASE '...' AS iris, which lasts as
+// long as the connection.  So OpenDB opens sqlite3://dir/iris.db with
+// the driver sqliteDriver, which attaches every file dir/*.db to each
+// new connection, like dir/churn.db as churn, and also dir/iris.db as
+// iris besides main.  The Python submitter does the same in
+// connect_with_data_source.
+const (
+	sqliteDriver     = "sqlflow_sqlite3"
+	sqliteFileSuffix = ".db"
+)
+
+func init() {
+	sql.Register(sqliteDriver, &sqlite3.SQLiteDriver{ConnectHook: attachSQLiteDatabases})
+}
+
+// attachSQLiteDatabases attaches the database files in the directory of
+// the main database file of c.  SQLite attaches at most 10 databases by
+// default.
+func attachSQLiteDatabases(c *sqlite3.SQLiteConn) error {
+	main, e := sqliteMainFile(c)
+	if e != nil {
+		return e
+	}
+	if main == "" {
+		// In-memory or temporary database.
+		return nil
+	}
+	files, e := filepath.Glob(filepath.Join(filepath.Dir(main), "*"+sqliteFileSuffix))
+	if e != nil {
+		return e
+	}
+	// NOTE: Attach the main database file first, so SQLite resolves the
+	// unqualified table names to it, even if the schema of main has not
+	// been reloaded after a change made through the attached alias.
+	sort.SliceStable(files, func(i, j int) bool { return files[i] == main && files[j] != main })
+	for _, f := range files {
+		name := strings.TrimSuffix(filepath.Base(f), sqliteFileSuffix)
+		if name == "main" || name == "temp" {
+			continue
+		}
+		stmt := fmt.Sprintf(`ATTACH DATABASE '%s' AS "%s"`, strings.Replace(f, "'", "''", -1), name)
+		if _, e := c.Exec(stmt, nil); e != nil {
+			return fmt.Errorf("failed to attach SQLite database %s: %v", f, e)
+		}
+	}
+	return nil
+}
+
+// sqliteMainFile returns the absolute path of the main database file
+// of c, or "" for an in-memory database.
+func sqliteMainFile(c *sqlite3.SQLiteConn) (string, error) {
+	rows, e := c.Query("PRAGMA database_list", nil)
+	if e != nil {
+		return "", e
+	}
+	defer rows.Close()
+	// Each row is (seq, name, file).
+	row := make([]driver.Value, len(rows.Columns()))
+	for {
+		if e := rows.Next(row); e != nil {
+			if e == io.EOF {
+				return "", nil
+			}
+			return "", e
+		}
+		if len(row) == 3 && fmt.Sprintf("%s", row[1]) == "main" {
+			return fmt.Sprintf("%s", row[2]), nil
+		}
+	}
+}
@@ -17,6 +17,9 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"sync"
 
 	"sqlflow.org/gomaxcompute"
@@ -59,6 +62,8 @@ func createTestingDB() *DB {
 		return createTestingHiveDB()
 	case "maxcompute":
 		return createTestingMaxComputeDB()
+	case "sqlite3":
+		return createTestingSQLiteDB()
 	default:
 		log.Panicf("Unrecognized environment variable SQLFLOW_TEST_DB %s", dbms)
 	}
@@ -137,6 +142,55 @@ func createTestingMaxComputeDB() *DB {
 	return db
 }
 
+func testingSQLiteURL() string {
+	dir := getEnv("SQLFLOW_TEST_DB_SQLITE_DIR", filepath.Join(os.TempDir(), "sqlflow_test_db"))
+	return fmt.Sprintf("sqlite3://%s", filepath.Join(dir, "iris"+sqliteFileSuffix))
+}
+
+var sqliteCreateDatabase = regexp.MustCompile(`(?i)CREATE DATABASE IF NOT EXISTS (\w+);`)
+
+// createSQLiteDatabases creates the database files of the CREATE
+// DATABASE statements in sqls next to the main database file of
+// dataSource, and returns sqls without these statements, which SQLite
+// doesn't support.
+func createSQLiteDatabases(dataSource string, sqls ...string) ([]string, error) {
+	dir := filepath.Dir(strings.Split(dataSource, "?")[0])
+	if e := os.MkdirAll(dir, 0755); e != nil {
+		return nil, e
+	}
+	names := []string{"sqlflow_models"}
+	stripped := []string{}
+	for _, s := range sqls {
+		for _, m := range sqliteCreateDatabase.FindAllStringSubmatch(s, -1) {
+			names = append(names, m[1])
+		}
+		stripped = append(stripped, sqliteCreateDatabase.ReplaceAllString(s, ""))
+	}
+	for _, name := range names {
+		f, e := os.OpenFile(filepath.Join(dir, name+sqliteFileSuffix), os.O_CREATE|os.O_RDWR, 0644)
+		if e != nil {
+			return nil, e
+		}
+		f.Close()
+	}
+	return stripped, nil
+}
+
+func createTestingSQLiteDB() *DB {
+	_, dataSource, e := ParseURL(testingSQLiteURL())
+	assertNoErr(e)
+	// NOTE: Create the database files before opening the database, so
+	// sqliteDriver attaches them to the connections.
+	sqls, e := createSQLiteDatabases(dataSource, testdata.IrisSQLiteSQL, testdata.SanityCheckSQL, testdata.ChurnSQL, testdata.HousingSQL)
+	assertNoErr(e)
+	db, e := OpenAndConnectDB(testingSQLiteURL())
+	assertNoErr(e)
+	for _, s := range sqls {
+		assertNoErr(testdata.Popularize(db.DB, s))
+	}
+	return db
+}
+
 // assertNoError prints the error if there is any in TestMain, which
 // log doesn't work.
 func assertNoErr(e error) {
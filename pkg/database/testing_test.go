@@ -30,6 +30,8 @@ func TestDatabaseGetTestingDBSingleton(t *testing.T) {
 		a.Equal(testingHiveURL(), db.URL())
 	case "maxcompute":
 		a.Equal(testingMaxComputeURL(), db.URL())
+	case "sqlite3":
+		a.Equal(testingSQLiteURL(), db.URL())
 	default:
 		a.Fail("Unrecognized environment variable SQLFLOW_TEST_DB %s", dbms)
 	}
@@ -58,3 +60,13 @@ func TestDatabaseTestingMaxComputeURL(t *testing.T) {
 		a.Equal(testingMaxComputeURL(), db.URL())
 	}
 }
+
+func TestDatabaseTestingSQLiteURL(t *testing.T) {
+	a := assert.New(t)
+	if db := GetTestingDBSingleton(); db.DriverName == "sqlite3" {
+		a.Equal(testingSQLiteURL(), db.URL())
+		var n int
+		a.NoError(db.QueryRow("SELECT COUNT(*) FROM iris.train").Scan(&n))
+		a.Equal(110, n)
+	}
+}
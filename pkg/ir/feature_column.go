@@ -34,6 +34,50 @@ type FieldDesc struct {
 	// if the column data is used as embedding(category_column()), the `num_buckets` should use the maxID
 	// appeared in the sample data. if error still occurs, users should set `num_buckets` manually.
 	MaxID int64
+	// TimeParts lists the parts, like "hour" and "dayofweek", extracted as
+	// the numeric features from a TIMESTAMP or DATE field, which is decoded
+	// as a dense float tensor of the shape [TimeFeatureSize(TimeParts, Cyclical)].
+	TimeParts []string `json:"time_parts"`
+	// Cyclical encodes each periodic time part p of the period T as
+	// sin(2*pi*p/T) and cos(2*pi*p/T), so that 23 o'clock is close to 0 o'clock.
+	Cyclical bool `json:"cyclical"`
+	// IsArray is true if the field saves arrays of numbers, like the Hive
+	// ARRAY<FLOAT> values, decoded as a dense tensor of Shape.
+	IsArray bool `json:"is_array"`
+	// JSONPaths lists the paths, like "$.age", of the numbers extracted from
+	// the JSON strings in the field as a dense float tensor of the shape
+	// [len(JSONPaths)].
+	JSONPaths []string `json:"json_paths"`
+	// Tokenizer, like "word", splits the texts in the field into tokens,
+	// decoded as the ids in the Vocabulary padded by -1 to the length Shape[0].
+	// The Vocabulary is built from the training data, and MaxID is its size.
+	Tokenizer string `json:"tokenizer"`
+}
+
+// TimePeriods maps the time parts of TIMESTAMP and DATE fields to their
+// periods. The part "year" is not periodic, so it is never encoded cyclically.
+var TimePeriods = map[string]int{
+	"minute":     60,
+	"hour":       24,
+	"dayofweek":  7,
+	"dayofmonth": 31,
+	"dayofyear":  366,
+	"month":      12,
+	"year":       0,
+}
+
+// TimeFeatureSize returns the number of the features extracted from a
+// TIMESTAMP or DATE field by parts.
+func TimeFeatureSize(parts []string, cyclical bool) int {
+	size := 0
+	for _, p := range parts {
+		if cyclical && TimePeriods[p] > 0 {
+			size += 2
+		} else {
+			size++
+		}
+	}
+	return size
 }
 
 // Possible DType values in FieldDesc
@@ -22,6 +22,9 @@ type Executor interface {
 	ExecuteExplain(*ExplainStmt) error
 	ExecuteEvaluate(*EvaluateStmt) error
 	ExecuteShowTrain(*ShowTrainStmt) error
+	ExecuteDropModel(*DropModelStmt) error
+	ExecuteShowModels(*ShowModelsStmt) error
+	ExecuteShowLineage(*ShowLineageStmt) error
 }
 
 // SQLFlowStmt has multiple implementations: TrainStmt, PredictStmt, ExplainStmt and standard SQL.
@@ -51,6 +54,17 @@ type TrainStmt struct {
 	// "select ... train ... with train.epoch = 1000, model.hidden_units = [10, 10]",
 	// the Attributes will be {"train.epoch": 1000, "model.hidden_units": [10 10]}.
 	Attributes map[string]interface{}
+	// SearchSpace maps the attributes to tune, like "num_leaves", to
+	// their candidates, the first of which is in Attributes. It's set by
+	// the code generators that support the hyperparameter tuning, see
+	// pkg/sql/codegen/tuning.
+	SearchSpace map[string][]interface{}
+	// Resources is the compute resources requested by the "resources.*"
+	// attributes in the WITH clause, which are not in Attributes.
+	Resources Resources
+	// Grouping is how the statement trains a model per group by the
+	// "train.group*" attributes, which are not in Attributes.
+	Grouping Grouping
 	// Features contain a map of a list of feature columns in the COLUMN clause.
 	// For multiple COLUMN clauses like
 	//   ```
@@ -62,6 +76,10 @@ type TrainStmt struct {
 	Features map[string][]FeatureColumn
 	// Label specifies the feature column in the LABEL clause.
 	Label FeatureColumn
+	// ColumnTypes maps the columns of Select to their database types
+	// unified by the feature derivation, like {"sepal_length": "FLOAT"}.
+	// It's nil if the feature columns are not derived.
+	ColumnTypes map[string]string
 	// Into specifies the table name in the INTO clause.
 	Into string
 	// When SQLFLOW_submitter == "pai", tmp tables will be created for training task
@@ -70,6 +88,37 @@ type TrainStmt struct {
 	TmpValidateTable string
 }
 
+// Resources is the compute resources requested for the job of a statement.
+// For example, after parsing
+// "select ... train ... with resources.gpu=1, resources.memory="8Gi"",
+// the Resources will be {GPU: 1, Memory: "8Gi"}.
+type Resources struct {
+	// CPU is the number of CPU cores, 0 means the default of the submitter.
+	CPU int
+	// GPU is the number of GPU cards, 0 means the default of the submitter.
+	GPU int
+	// Memory is in the format of the Kubernetes quantity like "512Mi" or
+	// "8Gi", "" means the default of the submitter.
+	Memory string
+}
+
+// IsEmpty returns whether no resource is requested.
+func (r Resources) IsEmpty() bool { return r == Resources{} }
+
+// Grouping describes the training of a model per distinct value of a
+// column, see pkg/sql/codegen/grouping.
+type Grouping struct {
+	// Column is the column of the groups, "" means training one model.
+	Column string
+	// Value is the only group to train, "" means all the groups. The
+	// Select and Into of the TrainStmt are of the group if it's set.
+	Value string
+	// IndexOnly is true if the statement saves the index of the models
+	// of the groups, which were trained by the other steps of the
+	// workflow, without training.
+	IndexOnly bool
+}
+
 // Execute generates and executes code for TrainStmt
 func (cl *TrainStmt) Execute(s Executor) error { return s.ExecuteTrain(cl) }
 
@@ -100,6 +149,9 @@ type PredictStmt struct {
 	// "select ... predict ... with predict.batch_size = 32 into ...",
 	// the Attributes will be {"predict.batch_size": 32}
 	Attributes map[string]interface{}
+	// Resources is the compute resources requested by the "resources.*"
+	// attributes in the WITH clause, which are not in Attributes.
+	Resources Resources
 	// Using is the model specified by USING clause.
 	Using string
 	// TrainStmt is the TrainStmt used for generating the training job of the corresponding model
@@ -215,3 +267,62 @@ func (sql *ShowTrainStmt) IsExtended() bool { return true }
 
 // GetOriginalSQL returns the original SQL statement used to get current IR result
 func (sql *ShowTrainStmt) GetOriginalSQL() string { return sql.OriginalSQL }
+
+// DropModelStmt deletes the model ModelName
+type DropModelStmt struct {
+	// OriginalSQL is the DROP MODEL stmt itself
+	OriginalSQL string
+	// The model to delete, all the versions of it if it's saved by
+	// versions
+	ModelName string
+}
+
+// Execute generates and executes code for DropModelStmt
+func (sql *DropModelStmt) Execute(s Executor) error { return s.ExecuteDropModel(sql) }
+
+// SetOriginalSQL sets the original sql string
+func (sql *DropModelStmt) SetOriginalSQL(s string) { sql.OriginalSQL = s }
+
+// IsExtended returns whether a SQLFlowStmt is an extended SQL statement
+func (sql *DropModelStmt) IsExtended() bool { return true }
+
+// GetOriginalSQL returns the original SQL statement used to get current IR result
+func (sql *DropModelStmt) GetOriginalSQL() string { return sql.OriginalSQL }
+
+// ShowModelsStmt lists the saved models
+type ShowModelsStmt struct {
+	// OriginalSQL is the SHOW MODELS stmt itself
+	OriginalSQL string
+}
+
+// Execute generates and executes code for ShowModelsStmt
+func (sql *ShowModelsStmt) Execute(s Executor) error { return s.ExecuteShowModels(sql) }
+
+// SetOriginalSQL sets the original sql string
+func (sql *ShowModelsStmt) SetOriginalSQL(s string) { sql.OriginalSQL = s }
+
+// IsExtended returns whether a SQLFlowStmt is an extended SQL statement
+func (sql *ShowModelsStmt) IsExtended() bool { return true }
+
+// GetOriginalSQL returns the original SQL statement used to get current IR result
+func (sql *ShowModelsStmt) GetOriginalSQL() string { return sql.OriginalSQL }
+
+// ShowLineageStmt lists the lineage of the model ModelName
+type ShowLineageStmt struct {
+	// OriginalSQL is the SHOW LINEAGE stmt itself
+	OriginalSQL string
+	// The model to list the lineage of
+	ModelName string
+}
+
+// Execute generates and executes code for ShowLineageStmt
+func (sql *ShowLineageStmt) Execute(s Executor) error { return s.ExecuteShowLineage(sql) }
+
+// SetOriginalSQL sets the original sql string
+func (sql *ShowLineageStmt) SetOriginalSQL(s string) { sql.OriginalSQL = s }
+
+// IsExtended returns whether a SQLFlowStmt is an extended SQL statement
+func (sql *ShowLineageStmt) IsExtended() bool { return true }
+
+// GetOriginalSQL returns the original SQL statement used to get current IR result
+func (sql *ShowLineageStmt) GetOriginalSQL() string { return sql.OriginalSQL }
@@ -0,0 +1,280 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jobqueue queues the training jobs of the SQLFlow server, and
+// runs them by their priorities within the limits of the concurrent jobs
+// of the server and of each user.
+package jobqueue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// StatusQueued is the status of the jobs waiting to run.
+	StatusQueued = "queued"
+	// StatusRunning is the status of the running jobs.
+	StatusRunning = "running"
+)
+
+// UserConfig is the quota and the priority of a user.
+type UserConfig struct {
+	// Quota is the max number of the running jobs of the user, 0 for the
+	// UserQuota of Config.
+	Quota int `json:"quota"`
+	// Priority is the priority of the jobs of the user, the higher ones
+	// run first.
+	Priority int `json:"priority"`
+}
+
+// Config is the limits of a Queue, like
+// {"max_concurrent": 4, "user_quota": 2, "users": {"alice": {"quota": 3, "priority": 10}}}.
+type Config struct {
+	// MaxConcurrent is the max number of the running jobs, 0 for no
+	// limit.
+	MaxConcurrent int `json:"max_concurrent"`
+	// UserQuota is the max number of the running jobs of each user who
+	// has no quota in Users, 0 for no limit.
+	UserQuota int `json:"user_quota"`
+	// DefaultPriority is the priority of the users not in Users.
+	DefaultPriority int `json:"default_priority"`
+	// Users is the quotas and the priorities of the users.
+	Users map[string]UserConfig `json:"users"`
+}
+
+// LoadConfig loads the Config in the JSON file path.
+func LoadConfig(path string) (*Config, error) {
+	b, e := ioutil.ReadFile(path)
+	if e != nil {
+		return nil, e
+	}
+	c := &Config{}
+	if e := json.Unmarshal(b, c); e != nil {
+		return nil, fmt.Errorf("invalid job queue config %s: %v", path, e)
+	}
+	if c.MaxConcurrent < 0 || c.UserQuota < 0 {
+		return nil, fmt.Errorf("invalid job queue config %s: negative limits", path)
+	}
+	return c, nil
+}
+
+func (c *Config) quota(user string) int {
+	if u, ok := c.Users[user]; ok && u.Quota > 0 {
+		return u.Quota
+	}
+	return c.UserQuota
+}
+
+func (c *Config) priority(user string) int {
+	if u, ok := c.Users[user]; ok {
+		return u.Priority
+	}
+	return c.DefaultPriority
+}
+
+// Job is a snapshot of a job in a Queue.
+type Job struct {
+	ID       string
+	User     string
+	SQL      string
+	Priority int
+	Status   string
+	// Position is the 1-based position of a queued job in the jobs to
+	// run, 0 for the running jobs.
+	Position   int
+	SubmitTime time.Time
+	StartTime  time.Time
+}
+
+type job struct {
+	Job
+	seq     uint64
+	started chan struct{}
+}
+
+// Queue runs the jobs by the priorities of their users, the jobs of
+// the same priority in the order of submission. A job waits if the
+// server or its user is running the max number of jobs, and the jobs
+// after it may run if they are within the limits.
+type Queue struct {
+	config  Config
+	mu      sync.Mutex
+	seq     uint64
+	queued  []*job
+	running map[string]*job
+	// runningOf is the number of the running jobs of each user.
+	runningOf map[string]int
+}
+
+// New returns a Queue limited by config.
+func New(config *Config) *Queue {
+	return &Queue{
+		config:    *config,
+		running:   map[string]*job{},
+		runningOf: map[string]int{},
+	}
+}
+
+// Submit queues the job id of user to run sql, and starts it if within
+// the limits. The caller waits for it to start by Wait, and calls Done
+// after it finishes.
+func (q *Queue) Submit(id, user, sql string) Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.seq++
+	j := &job{
+		Job: Job{
+			ID:         id,
+			User:       user,
+			SQL:        sql,
+			Priority:   q.config.priority(user),
+			Status:     StatusQueued,
+			SubmitTime: time.Now(),
+		},
+		seq:     q.seq,
+		started: make(chan struct{}),
+	}
+	q.queued = append(q.queued, j)
+	sort.SliceStable(q.queued, func(i, k int) bool {
+		if q.queued[i].Priority != q.queued[k].Priority {
+			return q.queued[i].Priority > q.queued[k].Priority
+		}
+		return q.queued[i].seq < q.queued[k].seq
+	})
+	q.schedule()
+	return q.snapshot(j)
+}
+
+// Wait waits for the job id to start, or returns the error of ctx if
+// it is done before then, when the job is removed from q.
+func (q *Queue) Wait(ctx context.Context, id string) error {
+	q.mu.Lock()
+	j := q.find(id)
+	q.mu.Unlock()
+	if j == nil {
+		return fmt.Errorf("no job %s in the queue", id)
+	}
+	select {
+	case <-j.started:
+		return nil
+	case <-ctx.Done():
+		q.Done(id)
+		return ctx.Err()
+	}
+}
+
+// Done removes the job id from q, and starts the queued jobs within the
+// limits.
+func (q *Queue) Done(id string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if j, ok := q.running[id]; ok {
+		delete(q.running, id)
+		if q.runningOf[j.User]--; q.runningOf[j.User] == 0 {
+			delete(q.runningOf, j.User)
+		}
+	} else {
+		for i, j := range q.queued {
+			if j.ID == id {
+				q.queued = append(q.queued[:i], q.queued[i+1:]...)
+				break
+			}
+		}
+	}
+	q.schedule()
+}
+
+// Get returns the job id, or false if it is not in q.
+func (q *Queue) Get(id string) (Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if j := q.find(id); j != nil {
+		return q.snapshot(j), true
+	}
+	return Job{}, false
+}
+
+// Jobs returns the running jobs in the order of their start time,
+// followed by the queued jobs in the order to run.
+func (q *Queue) Jobs() []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	running := make([]*job, 0, len(q.running))
+	for _, j := range q.running {
+		running = append(running, j)
+	}
+	sort.Slice(running, func(i, k int) bool {
+		if !running[i].StartTime.Equal(running[k].StartTime) {
+			return running[i].StartTime.Before(running[k].StartTime)
+		}
+		return running[i].seq < running[k].seq
+	})
+	jobs := []Job{}
+	for _, j := range append(running, q.queued...) {
+		jobs = append(jobs, q.snapshot(j))
+	}
+	return jobs
+}
+
+// schedule starts the queued jobs within the limits. It must be called
+// with q.mu locked.
+func (q *Queue) schedule() {
+	queued := q.queued[:0]
+	for _, j := range q.queued {
+		if q.config.MaxConcurrent > 0 && len(q.running) >= q.config.MaxConcurrent {
+			queued = append(queued, j)
+			continue
+		}
+		if quota := q.config.quota(j.User); quota > 0 && q.runningOf[j.User] >= quota {
+			queued = append(queued, j)
+			continue
+		}
+		j.Status, j.StartTime = StatusRunning, time.Now()
+		q.running[j.ID] = j
+		q.runningOf[j.User]++
+		close(j.started)
+	}
+	q.queued = queued
+}
+
+// find returns the job id in q, nil if not found. It must be called
+// with q.mu locked.
+func (q *Queue) find(id string) *job {
+	if j, ok := q.running[id]; ok {
+		return j
+	}
+	for _, j := range q.queued {
+		if j.ID == id {
+			return j
+		}
+	}
+	return nil
+}
+
+// snapshot returns a copy of j with its position. It must be called
+// with q.mu locked.
+func (q *Queue) snapshot(j *job) Job {
+	s := j.Job
+	for i, k := range q.queued {
+		if k == j {
+			s.Position = i + 1
+		}
+	}
+	return s
+}
@@ -0,0 +1,132 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobqueue
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueueLimits(t *testing.T) {
+	a := assert.New(t)
+	q := New(&Config{MaxConcurrent: 2, UserQuota: 1, Users: map[string]UserConfig{"admin": {Quota: 2, Priority: 10}}})
+
+	a1 := q.Submit("job1", "alice", "SELECT 1 TO TRAIN")
+	a.Equal(StatusRunning, a1.Status)
+	a.Equal(0, a1.Position)
+	a2 := q.Submit("job2", "alice", "SELECT 2 TO TRAIN")
+	a.Equal(StatusQueued, a2.Status)
+	a.Equal(1, a2.Position)
+	// The quota of alice does not block bob.
+	b1 := q.Submit("job3", "bob", "SELECT 3 TO TRAIN")
+	a.Equal(StatusRunning, b1.Status)
+	b2 := q.Submit("job4", "bob", "SELECT 4 TO TRAIN")
+	a.Equal(StatusQueued, b2.Status)
+	// The admin has a higher priority, but the server is full.
+	ad := q.Submit("job5", "admin", "SELECT 5 TO TRAIN")
+	a.Equal(StatusQueued, ad.Status)
+	a.Equal(1, ad.Position)
+	a.Equal(10, ad.Priority)
+
+	jobs := q.Jobs()
+	a.Equal(5, len(jobs))
+	ids := []string{}
+	for _, j := range jobs {
+		ids = append(ids, j.ID)
+	}
+	a.Equal([]string{a1.ID, b1.ID, ad.ID, a2.ID, b2.ID}, ids)
+
+	q.Done(a1.ID)
+	j, ok := q.Get(ad.ID)
+	a.True(ok)
+	a.Equal(StatusRunning, j.Status)
+	j, _ = q.Get(a2.ID)
+	a.Equal(StatusQueued, j.Status)
+	a.Equal(1, j.Position)
+	a.NoError(q.Wait(context.Background(), ad.ID))
+
+	_, ok = q.Get(a1.ID)
+	a.False(ok)
+	a.Error(q.Wait(context.Background(), a1.ID))
+
+	q.Done(b1.ID)
+	j, _ = q.Get(a2.ID)
+	a.Equal(StatusRunning, j.Status)
+	j, _ = q.Get(b2.ID)
+	a.Equal(StatusQueued, j.Status)
+}
+
+func TestQueueWait(t *testing.T) {
+	a := assert.New(t)
+	q := New(&Config{MaxConcurrent: 1})
+	j1 := q.Submit("job1", "alice", "SELECT 1 TO TRAIN")
+	j2 := q.Submit("job2", "bob", "SELECT 2 TO TRAIN")
+	j3 := q.Submit("job3", "carol", "SELECT 3 TO TRAIN")
+
+	done := make(chan error)
+	go func() { done <- q.Wait(context.Background(), j2.ID) }()
+	select {
+	case <-done:
+		t.Fatal("job started beyond the limit")
+	case <-time.After(10 * time.Millisecond):
+	}
+	q.Done(j1.ID)
+	a.NoError(<-done)
+
+	// A job leaves the queue if the caller gives up waiting.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	a.Equal(context.Canceled, q.Wait(ctx, j3.ID))
+	_, ok := q.Get(j3.ID)
+	a.False(ok)
+	a.Equal(1, len(q.Jobs()))
+}
+
+func TestQueueUnlimited(t *testing.T) {
+	a := assert.New(t)
+	q := New(&Config{})
+	for i := 0; i < 10; i++ {
+		a.Equal(StatusRunning, q.Submit(fmt.Sprintf("job%d", i), "alice", "SELECT 1 TO TRAIN").Status)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	a := assert.New(t)
+	dir, e := ioutil.TempDir("", "jobqueue")
+	a.NoError(e)
+	defer os.RemoveAll(dir)
+
+	f := path.Join(dir, "queue.json")
+	a.NoError(ioutil.WriteFile(f, []byte(`{"max_concurrent": 4, "user_quota": 2, "users": {"alice": {"quota": 3, "priority": 10}}}`), 0644))
+	c, e := LoadConfig(f)
+	a.NoError(e)
+	a.Equal(4, c.MaxConcurrent)
+	a.Equal(3, c.quota("alice"))
+	a.Equal(2, c.quota("bob"))
+	a.Equal(10, c.priority("alice"))
+	a.Equal(0, c.priority("bob"))
+
+	a.NoError(ioutil.WriteFile(f, []byte(`{"max_concurrent": -1}`), 0644))
+	_, e = LoadConfig(f)
+	a.Error(e)
+	_, e = LoadConfig(path.Join(dir, "missing.json"))
+	a.Error(e)
+}
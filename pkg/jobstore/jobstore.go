@@ -0,0 +1,201 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jobstore persists the jobs of the SQLFlow server, so the server
+// recovers them after restarting.
+package jobstore
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"sqlflow.org/sqlflow/pkg/database"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+)
+
+// Job is the persisted state of a job.
+type Job struct {
+	ID   string
+	User string
+	SQL  string
+	// Session is the session of the job without the credentials.
+	Session    *pb.Session
+	Phase      string
+	Message    string
+	WorkflowID string
+	StartTime  time.Time
+	// EndTime is zero for the unfinished jobs.
+	EndTime time.Time
+}
+
+// Store saves and loads the Jobs.
+type Store interface {
+	// Save inserts or updates job.
+	Save(job *Job) error
+	// Load returns the unfinished jobs, and the jobs finished since.
+	Load(since time.Time) ([]*Job, error)
+	// Prune deletes the jobs finished before.
+	Prune(before time.Time) error
+}
+
+// timeFormat is of fixed width, so the times in UTC sort as strings.
+const timeFormat = "2006-01-02T15:04:05.000000Z"
+
+var tableColumns = []string{"id", "username", "sql_text", "session", "phase", "message", "workflow_id",
+	"start_time", "end_time"}
+
+// TableStore saves the Jobs in a table.
+type TableStore struct {
+	db    *database.DB
+	table string
+}
+
+// NewTableStore returns a TableStore saving to table in db, which is
+// created if not exists. db is of MySQL, PostgreSQL or SQLite.
+func NewTableStore(db *database.DB, table string) (*TableStore, error) {
+	var text, id string
+	switch db.DriverName {
+	case "mysql":
+		// TEXT of MySQL is at most 64KB.
+		text, id = "LONGTEXT", "VARCHAR(64)"
+	case "postgres", "sqlite3":
+		text, id = "TEXT", "TEXT"
+	default:
+		return nil, fmt.Errorf("job store in the tables of %s is not supported", db.DriverName)
+	}
+	columns := []string{}
+	for _, c := range tableColumns {
+		switch c {
+		case "id":
+			columns = append(columns, c+" "+id+" PRIMARY KEY")
+		case "end_time":
+			// The empty end time sorts before all the others.
+			columns = append(columns, c+" VARCHAR(32)")
+		default:
+			columns = append(columns, c+" "+text)
+		}
+	}
+	stmt := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", table, strings.Join(columns, ", "))
+	if _, e := db.Exec(stmt); e != nil {
+		return nil, fmt.Errorf("create the job table %s failed: %v", table, e)
+	}
+	return &TableStore{db: db, table: table}, nil
+}
+
+// placeholder returns the i-th placeholder of the statements, 1-based.
+func (s *TableStore) placeholder(i int) string {
+	if s.db.DriverName == "postgres" {
+		return fmt.Sprintf("$%d", i)
+	}
+	return "?"
+}
+
+// Save implements Store.
+func (s *TableStore) Save(job *Job) error {
+	session, e := json.Marshal(Redact(job.Session))
+	if e != nil {
+		return e
+	}
+	end := ""
+	if !job.EndTime.IsZero() {
+		end = job.EndTime.UTC().Format(timeFormat)
+	}
+	placeholders := []string{}
+	for i := range tableColumns {
+		placeholders = append(placeholders, s.placeholder(i+1))
+	}
+	tx, e := s.db.Begin()
+	if e != nil {
+		return e
+	}
+	if _, e := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE id = %s", s.table, s.placeholder(1)), job.ID); e != nil {
+		tx.Rollback()
+		return e
+	}
+	stmt := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", s.table, strings.Join(tableColumns, ", "), strings.Join(placeholders, ", "))
+	if _, e := tx.Exec(stmt, job.ID, job.User, job.SQL, string(session), job.Phase, job.Message, job.WorkflowID,
+		job.StartTime.UTC().Format(timeFormat), end); e != nil {
+		tx.Rollback()
+		return e
+	}
+	return tx.Commit()
+}
+
+// Load implements Store.
+func (s *TableStore) Load(since time.Time) ([]*Job, error) {
+	stmt := fmt.Sprintf("SELECT %s FROM %s WHERE end_time = '' OR end_time >= %s ORDER BY start_time",
+		strings.Join(tableColumns, ", "), s.table, s.placeholder(1))
+	rows, e := s.db.Query(stmt, since.UTC().Format(timeFormat))
+	if e != nil {
+		return nil, e
+	}
+	defer rows.Close()
+	jobs := []*Job{}
+	for rows.Next() {
+		job, e := scanJob(rows)
+		if e != nil {
+			return nil, e
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+func scanJob(rows *sql.Rows) (*Job, error) {
+	job := &Job{Session: &pb.Session{}}
+	var session, start, end string
+	if e := rows.Scan(&job.ID, &job.User, &job.SQL, &session, &job.Phase, &job.Message, &job.WorkflowID, &start, &end); e != nil {
+		return nil, e
+	}
+	if e := json.Unmarshal([]byte(session), job.Session); e != nil {
+		return nil, fmt.Errorf("invalid session of job %s: %v", job.ID, e)
+	}
+	var e error
+	if job.StartTime, e = time.Parse(timeFormat, start); e != nil {
+		return nil, e
+	}
+	if end != "" {
+		if job.EndTime, e = time.Parse(timeFormat, end); e != nil {
+			return nil, e
+		}
+	}
+	return job, nil
+}
+
+// Prune implements Store.
+func (s *TableStore) Prune(before time.Time) error {
+	_, e := s.db.Exec(fmt.Sprintf("DELETE FROM %s WHERE end_time <> '' AND end_time < %s", s.table, s.placeholder(1)),
+		before.UTC().Format(timeFormat))
+	return e
+}
+
+var passwordRE = regexp.MustCompile(`(?i)((?:password|passwd|secret|token|access_key_secret)=)[^&]*`)
+var userinfoRE = regexp.MustCompile(`^([a-z0-9]+://[^:/@]*):[^@/]*@`)
+
+// Redact returns a copy of session without the credentials, like the
+// passwords in the connection string and the keys of the model storage.
+func Redact(session *pb.Session) *pb.Session {
+	if session == nil {
+		return &pb.Session{}
+	}
+	r := *session
+	r.DbConnStr = userinfoRE.ReplaceAllString(r.DbConnStr, "$1:***@")
+	r.DbConnStr = passwordRE.ReplaceAllString(r.DbConnStr, "$1***")
+	r.Token, r.HdfsPass, r.S3SecretAccessKey, r.ModelEncryptionKey = "", "", "", ""
+	r.AzureAccountKey, r.AzureSasToken, r.OssAccessKeySecret = "", "", ""
+	return &r
+}
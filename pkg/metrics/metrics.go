@@ -0,0 +1,249 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics exposes the metrics of the SQLFlow server in the
+// Prometheus text format, see
+// https://prometheus.io/docs/instrumenting/exposition_formats.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	counterType   = "counter"
+	gaugeType     = "gauge"
+	histogramType = "histogram"
+)
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]*metric{}
+)
+
+// metric is a metric of its series by the label values.
+type metric struct {
+	name    string
+	help    string
+	typ     string
+	labels  []string
+	buckets []float64 // the upper bounds of the buckets of a histogram
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	labelValues []string
+	value       float64  // of a counter or gauge
+	counts      []uint64 // of the buckets of a histogram
+	sum         float64
+	count       uint64
+}
+
+func register(name, help, typ string, buckets []float64, labels []string) *metric {
+	m := &metric{name: name, help: help, typ: typ, labels: labels, buckets: buckets, series: map[string]*series{}}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[name]; ok {
+		panic(fmt.Sprintf("metric %s registered twice", name))
+	}
+	registry[name] = m
+	return m
+}
+
+// with calls f with the series of labelValues under the lock of m.
+func (m *metric) with(labelValues []string, f func(s *series)) {
+	if len(labelValues) != len(m.labels) {
+		panic(fmt.Sprintf("metric %s has labels %v, got values %v", m.name, m.labels, labelValues))
+	}
+	key := strings.Join(labelValues, "\xff")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.series[key]
+	if !ok {
+		s = &series{labelValues: append([]string{}, labelValues...), counts: make([]uint64, len(m.buckets))}
+		m.series[key] = s
+	}
+	f(s)
+}
+
+// Counter is a cumulative metric that only increases.
+type Counter struct{ m *metric }
+
+// NewCounter registers a Counter of the labels.
+func NewCounter(name, help string, labels ...string) *Counter {
+	return &Counter{register(name, help, counterType, nil, labels)}
+}
+
+// Inc adds 1 to the series of labelValues.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds v, which must not be negative, to the series of labelValues.
+func (c *Counter) Add(v float64, labelValues ...string) {
+	c.m.with(labelValues, func(s *series) { s.value += v })
+}
+
+// Gauge is a metric that goes up and down.
+type Gauge struct{ m *metric }
+
+// NewGauge registers a Gauge of the labels.
+func NewGauge(name, help string, labels ...string) *Gauge {
+	return &Gauge{register(name, help, gaugeType, nil, labels)}
+}
+
+// Add adds v to the series of labelValues.
+func (g *Gauge) Add(v float64, labelValues ...string) {
+	g.m.with(labelValues, func(s *series) { s.value += v })
+}
+
+// Set sets the series of labelValues to v.
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.m.with(labelValues, func(s *series) { s.value = v })
+}
+
+// Inc adds 1 to the series of labelValues.
+func (g *Gauge) Inc(labelValues ...string) {
+	g.Add(1, labelValues...)
+}
+
+// Dec subtracts 1 from the series of labelValues.
+func (g *Gauge) Dec(labelValues ...string) {
+	g.Add(-1, labelValues...)
+}
+
+// Histogram counts the observations in buckets.
+type Histogram struct{ m *metric }
+
+// NewHistogram registers a Histogram of the labels with buckets, the
+// increasing upper bounds of the buckets.
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if !sort.Float64sAreSorted(buckets) {
+		panic(fmt.Sprintf("buckets of metric %s are not sorted", name))
+	}
+	return &Histogram{register(name, help, histogramType, buckets, labels)}
+}
+
+// Observe adds v to the series of labelValues.
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	h.m.with(labelValues, func(s *series) {
+		if i := sort.SearchFloat64s(h.m.buckets, v); i < len(s.counts) {
+			s.counts[i]++
+		}
+		s.sum += v
+		s.count++
+	})
+}
+
+// ExponentialBuckets returns count buckets from start, each factor times
+// the previous one.
+func ExponentialBuckets(start, factor float64, count int) []float64 {
+	buckets := make([]float64, count)
+	for i := range buckets {
+		buckets[i] = start
+		start *= factor
+	}
+	return buckets
+}
+
+// WriteText writes all the metrics in the Prometheus text format.
+func WriteText(w io.Writer) error {
+	registryMu.Lock()
+	names := []string{}
+	for name := range registry {
+		names = append(names, name)
+	}
+	registryMu.Unlock()
+	sort.Strings(names)
+
+	bw := bufio.NewWriter(w)
+	for _, name := range names {
+		registryMu.Lock()
+		m := registry[name]
+		registryMu.Unlock()
+		m.writeText(bw)
+	}
+	return bw.Flush()
+}
+
+func (m *metric) writeText(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", m.name, strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(m.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", m.name, m.typ)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	keys := []string{}
+	for key := range m.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s := m.series[key]
+		if m.typ != histogramType {
+			fmt.Fprintf(w, "%s%s %s\n", m.name, m.labelText(s.labelValues, ""), formatFloat(s.value))
+			continue
+		}
+		cumulative := uint64(0)
+		for i, upper := range m.buckets {
+			cumulative += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", m.name, m.labelText(s.labelValues, formatFloat(upper)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", m.name, m.labelText(s.labelValues, "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", m.name, m.labelText(s.labelValues, ""), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", m.name, m.labelText(s.labelValues, ""), s.count)
+	}
+}
+
+// labelText returns the labels like {type="train",le="0.1"}, where le
+// is the upper bound of a bucket of a histogram, "" if not a bucket.
+func (m *metric) labelText(values []string, le string) string {
+	pairs := []string{}
+	for i, l := range m.labels {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, l, escapeLabelValue(values[i])))
+	}
+	if le != "" {
+		pairs = append(pairs, fmt.Sprintf(`le="%s"`, le))
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func escapeLabelValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		WriteText(w)
+	})
+}
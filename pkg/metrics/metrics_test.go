@@ -0,0 +1,85 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteText(t *testing.T) {
+	a := assert.New(t)
+	c := NewCounter("test_requests_total", "Requests by code.", "code")
+	g := NewGauge("test_in_progress", "Requests in progress.")
+	h := NewHistogram("test_latency_seconds", "Latency.", []float64{0.1, 1}, "path")
+	c.Inc("200")
+	c.Add(2, "200")
+	c.Inc(`5"0\0`)
+	g.Inc()
+	g.Inc()
+	g.Dec()
+	g.Set(5)
+	g.Dec()
+	h.Observe(0.05, "/a")
+	h.Observe(0.1, "/a")
+	h.Observe(0.5, "/a")
+	h.Observe(3, "/a")
+
+	buf := &bytes.Buffer{}
+	a.NoError(WriteText(buf))
+	text := buf.String()
+	a.Contains(text, `# HELP test_requests_total Requests by code.
+# TYPE test_requests_total counter
+test_requests_total{code="200"} 3
+test_requests_total{code="5\"0\\0"} 1
+`)
+	a.Contains(text, `# TYPE test_in_progress gauge
+test_in_progress 4
+`)
+	a.Contains(text, `# TYPE test_latency_seconds histogram
+test_latency_seconds_bucket{path="/a",le="0.1"} 2
+test_latency_seconds_bucket{path="/a",le="1"} 3
+test_latency_seconds_bucket{path="/a",le="+Inf"} 4
+test_latency_seconds_sum{path="/a"} 3.65
+test_latency_seconds_count{path="/a"} 4
+`)
+	a.Panics(func() { c.Inc() })
+	a.Panics(func() { NewGauge("test_in_progress", "Again.") })
+}
+
+func TestHandler(t *testing.T) {
+	a := assert.New(t)
+	ObserveStatement("train", time.Now(), nil)
+	ObserveStatement("standard", time.Now(), fmt.Errorf("failed"))
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	a.Equal(http.StatusOK, w.Code)
+	a.True(strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain"))
+	a.Contains(w.Body.String(), `sqlflow_statements_total{type="train",status="succeeded"} 1`)
+	a.Contains(w.Body.String(), `sqlflow_statements_total{type="standard",status="failed"} 1`)
+	a.Contains(w.Body.String(), `sqlflow_statement_duration_seconds_count{type="train"} 1`)
+	a.Contains(w.Body.String(), "# TYPE sqlflow_active_sessions gauge")
+}
+
+func TestExponentialBuckets(t *testing.T) {
+	assert.Equal(t, []float64{1, 2, 4}, ExponentialBuckets(1, 2, 3))
+}
@@ -0,0 +1,70 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import "time"
+
+// The metrics of the SQLFlow server.
+var (
+	// Statements counts the statements by the type, like "train", and the
+	// status, "succeeded" or "failed".
+	Statements = NewCounter("sqlflow_statements_total", "Statements executed by type and status.", "type", "status")
+	// StatementDuration is the latency of the statements by the type.
+	StatementDuration = NewHistogram("sqlflow_statement_duration_seconds", "Latency of the statements by type.",
+		ExponentialBuckets(0.01, 4, 10), "type")
+	// ActiveSessions is the number of the Run calls in progress.
+	ActiveSessions = NewGauge("sqlflow_active_sessions", "Number of the SQL programs running on the server.")
+	// WorkflowSubmissions counts the workflows submitted by the status.
+	WorkflowSubmissions = NewCounter("sqlflow_workflow_submissions_total", "Workflows submitted by status.", "status")
+	// DBConnectionErrors counts the failed connections by the driver.
+	DBConnectionErrors = NewCounter("sqlflow_db_connection_errors_total", "Failed database connections by driver.", "driver")
+	// ModelSaveBytes and ModelLoadBytes are the sizes of the model files by
+	// the storage, like "sqlfs" or "oss".
+	ModelSaveBytes = NewHistogram("sqlflow_model_save_bytes", "Size of the saved models by storage.",
+		ExponentialBuckets(1<<10, 4, 12), "storage")
+	ModelLoadBytes = NewHistogram("sqlflow_model_load_bytes", "Size of the loaded models by storage.",
+		ExponentialBuckets(1<<10, 4, 12), "storage")
+	// ModelSaveDuration and ModelLoadDuration are the latency of saving and
+	// loading the models by the storage.
+	ModelSaveDuration = NewHistogram("sqlflow_model_save_duration_seconds", "Latency of saving the models by storage.",
+		ExponentialBuckets(0.01, 4, 10), "storage")
+	ModelLoadDuration = NewHistogram("sqlflow_model_load_duration_seconds", "Latency of loading the models by storage.",
+		ExponentialBuckets(0.01, 4, 10), "storage")
+	// Workdirs is the number of the working directories in use, and
+	// WorkdirBytes is the disk usage of the working directories measured
+	// by the last sweep.
+	Workdirs     = NewGauge("sqlflow_workdirs", "Number of the working directories in use.")
+	WorkdirBytes = NewGauge("sqlflow_workdir_bytes", "Disk usage of the working directories.")
+	// WorkdirsRemoved counts the removed working directories by the
+	// reason, "done" or "expired".
+	WorkdirsRemoved = NewCounter("sqlflow_workdirs_removed_total", "Working directories removed by reason.", "reason")
+	// WorkdirQuotaExceeded counts the statements rejected by the quota of
+	// the working directories of the sessions.
+	WorkdirQuotaExceeded = NewCounter("sqlflow_workdir_quota_exceeded_total", "Statements rejected by the workdir quota.")
+)
+
+// Status returns "succeeded" if e is nil, or "failed".
+func Status(e error) string {
+	if e != nil {
+		return "failed"
+	}
+	return "succeeded"
+}
+
+// ObserveStatement records a statement of typ that started at start and
+// finished with e.
+func ObserveStatement(typ string, start time.Time, e error) {
+	Statements.Inc(typ, Status(e))
+	StatementDuration.Observe(time.Since(start).Seconds(), typ)
+}
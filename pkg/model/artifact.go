@@ -0,0 +1,150 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"sqlflow.org/sqlflow/pkg/database"
+	"sqlflow.org/sqlflow/pkg/ir"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+)
+
+// artifactSeparator separates the model name and the artifact name in
+// the URI of an artifact, like "my_db.my_model__scaler".
+const artifactSeparator = "__"
+
+// An artifact name becomes a part of a table name, so it's restricted to
+// the characters valid in table names of all databases.
+var artifactNameRe = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+
+// artifactURI returns where the artifact name of the model at modelURI
+// is saved, which is modelURI itself if name is "". Artifacts are saved
+// as separate models next to the model, the ones in a sqlfs table are
+// in the tables suffixed by the artifact name, and the ones in files are
+// in the files suffixed by the artifact name.
+func artifactURI(modelURI, name string) (string, error) {
+	if name == "" {
+		return modelURI, nil
+	}
+	if !artifactNameRe.MatchString(name) || strings.Contains(name, artifactSeparator) {
+		return "", fmt.Errorf("invalid artifact name %q, should consist of letters, digits, and single underscores", name)
+	}
+	return modelURI + artifactSeparator + name, nil
+}
+
+// SaveArtifact works like Save, and saves the working directory as the
+// artifact name of the model at modelURI, like a scaler or a vocabulary
+// used along with the model. The artifact "" is the model itself.
+func (m *Model) SaveArtifact(modelURI, name string, trainStmt *ir.TrainStmt, session *pb.Session) error {
+	uri, e := artifactURI(modelURI, name)
+	if e != nil {
+		return e
+	}
+	return m.Save(uri, trainStmt, session)
+}
+
+// LoadArtifact works like Load, and loads the artifact name of the model
+// at modelURI saved by SaveArtifact.
+func LoadArtifact(modelURI, name, dst string, db *database.DB) (*Model, error) {
+	uri, e := artifactURI(modelURI, name)
+	if e != nil {
+		return nil, e
+	}
+	return Load(uri, dst, db)
+}
+
+// ListArtifacts returns the sorted names of the artifacts saved along
+// with the model at modelURI, not including the model itself. Only the
+// local filesystem and sqlfs tables in MySQL are supported now.
+func ListArtifacts(modelURI string, session *pb.Session) ([]string, error) {
+	scheme, p, e := parseModelURI(modelURI)
+	if e != nil {
+		return nil, e
+	}
+	var uris []string
+	switch scheme {
+	case "file":
+		const suffix = ".tar.gz"
+		files, e := filepath.Glob(p + artifactSeparator + "*" + suffix)
+		if e != nil {
+			return nil, e
+		}
+		for _, f := range files {
+			uris = append(uris, strings.TrimSuffix(f, suffix))
+		}
+	case "":
+		db, e := database.OpenAndConnectDB(session.DbConnStr)
+		if e != nil {
+			return nil, e
+		}
+		defer db.Close()
+		if uris, e = listArtifactTables(db, p); e != nil {
+			return nil, e
+		}
+	default:
+		return nil, fmt.Errorf("listing artifacts in %s is not supported now", scheme)
+	}
+
+	prefix := p + artifactSeparator
+	names := []string{}
+	for _, uri := range uris {
+		name := strings.TrimPrefix(uri, prefix)
+		// Skip what artifactURI doesn't accept, like the artifacts of
+		// the artifact my_model__a listed with my_model.
+		if artifactNameRe.MatchString(name) && !strings.Contains(name, artifactSeparator) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// listArtifactTables returns the sqlfs tables whose names start with
+// table and artifactSeparator.
+func listArtifactTables(db *database.DB, table string) ([]string, error) {
+	if db.DriverName != "mysql" {
+		return nil, fmt.Errorf("listing artifacts in %s is not supported now", db.DriverName)
+	}
+	schema, name := "", table
+	if i := strings.Index(table, "."); i >= 0 {
+		schema, name = table[:i], table[i+1:]
+	}
+	// Escape the underscores, which match any character in LIKE.
+	pattern := strings.Replace(name+artifactSeparator, "_", `\_`, -1) + "%"
+	stmt := `SELECT table_name FROM information_schema.columns
+WHERE column_name IN ('id', 'block') AND table_schema = IF(? = '', DATABASE(), ?) AND table_name LIKE ?
+GROUP BY table_name HAVING COUNT(*) = 2`
+	rows, e := db.Query(stmt, schema, schema, pattern)
+	if e != nil {
+		return nil, fmt.Errorf("list artifacts of %s failed: %v", table, e)
+	}
+	defer rows.Close()
+	tables := []string{}
+	for rows.Next() {
+		var t string
+		if e := rows.Scan(&t); e != nil {
+			return nil, e
+		}
+		if schema != "" {
+			t = schema + "." + t
+		}
+		tables = append(tables, t)
+	}
+	return tables, rows.Err()
+}
@@ -0,0 +1,93 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sqlflow.org/sqlflow/pkg/database"
+)
+
+func TestSaveAndLoadArtifacts(t *testing.T) {
+	a := assert.New(t)
+	cwd := mockWorkDir(t)
+	defer os.RemoveAll(cwd)
+	scaler, e := ioutil.TempDir("", "sqlflow_scaler")
+	a.NoError(e)
+	defer os.RemoveAll(scaler)
+	a.NoError(ioutil.WriteFile(filepath.Join(scaler, "scaler.json"), []byte(`{"mean":0}`), 0644))
+	modelDir, e := ioutil.TempDir("", "sqlflow_model_dir")
+	a.NoError(e)
+	defer os.RemoveAll(modelDir)
+
+	modelURI := "file://" + filepath.Join(modelDir, "my_dnn_model")
+	a.NoError(New(cwd, testTrainSelect).SaveArtifact(modelURI, "", nil, nil))
+	a.NoError(New(scaler, testTrainSelect).SaveArtifact(modelURI, "scaler", nil, nil))
+	a.NoError(New(cwd, testTrainSelect).SaveArtifact(modelURI, "vocab", nil, nil))
+	// Another model sharing the prefix.
+	a.NoError(New(cwd, testTrainSelect).Save(modelURI+"_2", nil, nil))
+
+	names, e := ListArtifacts(modelURI, nil)
+	a.NoError(e)
+	a.Equal([]string{"scaler", "vocab"}, names)
+
+	dst, e := ioutil.TempDir("", "sqlflow_model_dst")
+	a.NoError(e)
+	defer os.RemoveAll(dst)
+	_, e = LoadArtifact(modelURI, "scaler", dst, nil)
+	a.NoError(e)
+	b, e := ioutil.ReadFile(filepath.Join(dst, "scaler.json"))
+	a.NoError(e)
+	a.Equal(`{"mean":0}`, string(b))
+
+	// The artifact "" is the model itself.
+	_, e = LoadArtifact(modelURI, "", dst, nil)
+	a.NoError(e)
+	_, e = os.Stat(filepath.Join(dst, "my_dnn_model", "saved_model.pb"))
+	a.NoError(e)
+
+	for _, name := range []string{"../scaler", "a__b", "scaler.json"} {
+		a.Error(New(cwd, testTrainSelect).SaveArtifact(modelURI, name, nil, nil))
+	}
+	_, e = LoadArtifact(modelURI, "absent", dst, nil)
+	a.Error(e)
+}
+
+func TestSaveAndListArtifactsDB(t *testing.T) {
+	a := assert.New(t)
+	db := database.GetTestingDBSingleton()
+	if db.DriverName != "mysql" {
+		t.Skip("Skip as SQLFLOW_TEST_DB is not MySQL")
+	}
+	cwd := mockWorkDir(t)
+	defer os.RemoveAll(cwd)
+
+	session := database.GetSessionFromTestingDB()
+	table := "sqlflow_models.my_artifact_model"
+	a.NoError(New(cwd, testTrainSelect).SaveArtifact(table, "", nil, session))
+	a.NoError(New(cwd, testTrainSelect).SaveArtifact(table, "scaler", nil, session))
+	names, e := ListArtifacts(table, session)
+	a.NoError(e)
+	a.Equal([]string{"scaler"}, names)
+
+	m, e := LoadArtifact(table, "scaler", "", db)
+	a.NoError(e)
+	a.Equal(testTrainSelect, m.TrainSelect)
+	a.NoError(Delete(table, session))
+	a.NoError(Delete(table+"__scaler", session))
+}
@@ -0,0 +1,204 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+
+	pb "sqlflow.org/sqlflow/pkg/proto"
+)
+
+const (
+	azureBufferSize = 4 << 20
+	azureMaxBuffers = 4
+)
+
+// azureCredentials returns the storage account name, account key, and SAS
+// token in session. A nil session means the environment variables
+// MakeSessionFromEnv reads them from.
+func azureCredentials(session *pb.Session) (account, key, sas string) {
+	if session == nil {
+		return os.Getenv("SQLFLOW_AZURE_ACCOUNT_NAME"), os.Getenv("SQLFLOW_AZURE_ACCOUNT_KEY"), os.Getenv("SQLFLOW_AZURE_SAS_TOKEN")
+	}
+	return session.AzureAccountName, session.AzureAccountKey, session.AzureSasToken
+}
+
+// newAzureContainerURL returns the URL of container in the storage
+// account in session, authenticated by the account key, or the SAS token
+// if the key is empty.
+func newAzureContainerURL(container string, session *pb.Session) (azblob.ContainerURL, error) {
+	account, key, sas := azureCredentials(session)
+	if account == "" {
+		return azblob.ContainerURL{}, fmt.Errorf("azure storage account name is not set")
+	}
+	u := url.URL{Scheme: "https", Host: account + ".blob.core.windows.net", Path: "/" + container}
+	var cred azblob.Credential
+	switch {
+	case key != "":
+		c, e := azblob.NewSharedKeyCredential(account, key)
+		if e != nil {
+			// Don't show the key in the error message.
+			return azblob.ContainerURL{}, fmt.Errorf("invalid azure storage account key for account %s", account)
+		}
+		cred = c
+	case sas != "":
+		u.RawQuery = strings.TrimPrefix(sas, "?")
+		cred = azblob.NewAnonymousCredential()
+	default:
+		return azblob.ContainerURL{}, fmt.Errorf("neither azure storage account key nor SAS token is set for account %s", account)
+	}
+	return azblob.NewContainerURL(u, azblob.NewPipeline(cred, azblob.PipelineOptions{})), nil
+}
+
+// azureError returns a short error message of e. The message of
+// azblob.StorageError dumps the HTTP request and response, so it's not
+// shown to users.
+func azureError(op, container, blob string, e error) error {
+	se, ok := e.(azblob.StorageError)
+	if !ok {
+		return fmt.Errorf("%s az://%s/%s failed: %v", op, container, blob, e)
+	}
+	if se.Response().StatusCode == http.StatusForbidden {
+		return fmt.Errorf("%s az://%s/%s failed: azure authentication failed (%s), check the account key or SAS token", op, container, blob, se.ServiceCode())
+	}
+	return fmt.Errorf("%s az://%s/%s failed: %s (HTTP %d)", op, container, blob, se.ServiceCode(), se.Response().StatusCode)
+}
+
+// azureStorage stores the models in the block blobs az://container/blob.
+type azureStorage struct{}
+
+func (azureStorage) Put(ctx context.Context, p string, session *pb.Session, fn func(io.Writer) error) error {
+	container, blob := splitBucketKey(p)
+	return writeAzure(ctx, container, blob, session, fn)
+}
+
+func (azureStorage) Get(ctx context.Context, p string, session *pb.Session, fn func(io.Reader) error) error {
+	container, blob := splitBucketKey(p)
+	return readAzure(ctx, container, blob, session, fn)
+}
+
+func (azureStorage) Stat(ctx context.Context, p string, session *pb.Session) (int64, error) {
+	container, blob := splitBucketKey(p)
+	return statAzure(ctx, container, blob, session)
+}
+
+func (azureStorage) Delete(ctx context.Context, p string, session *pb.Session) error {
+	container, blob := splitBucketKey(p)
+	return deleteAzure(ctx, container, blob, session)
+}
+
+// writeAzure uploads what fn writes to the block blob
+// az://container/blob. It creates the container if it doesn't exist.
+func writeAzure(ctx context.Context, container, blob string, session *pb.Session, fn func(io.Writer) error) error {
+	if container == "" || blob == "" {
+		return fmt.Errorf("az modelURI should be az://container/blob, got container %q, blob %q", container, blob)
+	}
+	cu, e := newAzureContainerURL(container, session)
+	if e != nil {
+		return e
+	}
+	if _, e := cu.Create(ctx, nil, azblob.PublicAccessNone); e != nil {
+		se, ok := e.(azblob.StorageError)
+		// A SAS token of a container can't create containers, leave the
+		// authentication failure, if any, to the upload.
+		if !ok || (se.ServiceCode() != azblob.ServiceCodeContainerAlreadyExists && se.Response().StatusCode != http.StatusForbidden) {
+			return azureError("create container of", container, blob, e)
+		}
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(fn(pw))
+	}()
+	_, e = azblob.UploadStreamToBlockBlob(ctx, pr, cu.NewBlockBlobURL(blob), azblob.UploadStreamToBlockBlobOptions{
+		BufferSize: azureBufferSize,
+		MaxBuffers: azureMaxBuffers,
+	})
+	// Unblock the writing goroutine if the upload stopped early.
+	pr.CloseWithError(e)
+	if e != nil {
+		return azureError("upload model to", container, blob, e)
+	}
+	return nil
+}
+
+// readAzure calls fn with the content of the blob az://container/blob.
+func readAzure(ctx context.Context, container, blob string, session *pb.Session, fn func(io.Reader) error) error {
+	if container == "" || blob == "" {
+		return fmt.Errorf("az modelURI should be az://container/blob, got container %q, blob %q", container, blob)
+	}
+	cu, e := newAzureContainerURL(container, session)
+	if e != nil {
+		return e
+	}
+	resp, e := cu.NewBlobURL(blob).Download(ctx, 0, azblob.CountToEnd, azblob.BlobAccessConditions{}, false)
+	if e != nil {
+		return azureError("download model from", container, blob, e)
+	}
+	body := resp.Body(azblob.RetryReaderOptions{MaxRetryRequests: 3})
+	defer body.Close()
+	return fn(body)
+}
+
+// isAzureNotFound returns true if e is the error of an absent blob or
+// container.
+func isAzureNotFound(e error) bool {
+	se, ok := e.(azblob.StorageError)
+	return ok && (se.ServiceCode() == azblob.ServiceCodeBlobNotFound || se.ServiceCode() == azblob.ServiceCodeContainerNotFound)
+}
+
+// statAzure returns the size of the blob az://container/blob.
+func statAzure(ctx context.Context, container, blob string, session *pb.Session) (int64, error) {
+	if container == "" || blob == "" {
+		return 0, fmt.Errorf("az modelURI should be az://container/blob, got container %q, blob %q", container, blob)
+	}
+	cu, e := newAzureContainerURL(container, session)
+	if e != nil {
+		return 0, e
+	}
+	resp, e := cu.NewBlobURL(blob).GetProperties(ctx, azblob.BlobAccessConditions{})
+	if e != nil {
+		if isAzureNotFound(e) {
+			return 0, ErrModelNotFound
+		}
+		return 0, azureError("stat", container, blob, e)
+	}
+	return resp.ContentLength(), nil
+}
+
+// deleteAzure deletes the blob az://container/blob.
+func deleteAzure(ctx context.Context, container, blob string, session *pb.Session) error {
+	if container == "" || blob == "" {
+		return fmt.Errorf("az modelURI should be az://container/blob, got container %q, blob %q", container, blob)
+	}
+	cu, e := newAzureContainerURL(container, session)
+	if e != nil {
+		return e
+	}
+	if _, e := cu.NewBlobURL(blob).Delete(ctx, azblob.DeleteSnapshotsOptionInclude, azblob.BlobAccessConditions{}); e != nil {
+		if isAzureNotFound(e) {
+			return ErrModelNotFound
+		}
+		return azureError("delete", container, blob, e)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// sha256SumsFile is the last entry of the tarballs written by tarGzDir.
+// It lists the SHA-256 checksums of the regular files in the tarball in
+// the format of sha256sum(1), so Load and Verify can tell a corrupted
+// model from a good one before TensorFlow fails to restore it. The
+// checksums are computed while archiving, so they cannot be in the
+// model meta, which precedes the tarball.
+const sha256SumsFile = ".sqlflow_sha256sums"
+
+// maxSHA256SumsSize limits the size of sha256SumsFile read into memory.
+const maxSHA256SumsSize = 64 << 20
+
+// sha256Sums maps the cleaned entry names to the hex-encoded SHA-256
+// checksums of their content.
+type sha256Sums map[string]string
+
+func isSHA256SumsEntry(name string) bool {
+	return path.Clean(name) == sha256SumsFile
+}
+
+// writeSHA256Sums writes s as the entry sha256SumsFile to tw. The entry
+// has a fixed modification time, so archiving the same files gives the
+// same tarball, which Options.Dedup relies on.
+func writeSHA256Sums(tw *tar.Writer, s sha256Sums) error {
+	names := []string{}
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b bytes.Buffer
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s  %s\n", s[name], name)
+	}
+	hdr := &tar.Header{
+		Name:     sha256SumsFile,
+		Mode:     0644,
+		Size:     int64(b.Len()),
+		ModTime:  time.Unix(0, 0),
+		Typeflag: tar.TypeReg,
+	}
+	if e := tw.WriteHeader(hdr); e != nil {
+		return e
+	}
+	_, e := tw.Write(b.Bytes())
+	return e
+}
+
+// readSHA256Sums decodes the entry sha256SumsFile.
+func readSHA256Sums(r io.Reader) (sha256Sums, error) {
+	b, e := ioutil.ReadAll(io.LimitReader(r, maxSHA256SumsSize+1))
+	if e != nil {
+		return nil, fmt.Errorf("read %s failed: %v", sha256SumsFile, e)
+	}
+	if len(b) > maxSHA256SumsSize {
+		return nil, fmt.Errorf("%s exceeds %d bytes", sha256SumsFile, maxSHA256SumsSize)
+	}
+	s := sha256Sums{}
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), "  ", 2)
+		if len(fields) != 2 || len(fields[0]) != 64 {
+			return nil, fmt.Errorf("invalid line in %s: %q", sha256SumsFile, scanner.Text())
+		}
+		s[path.Clean(fields[1])] = fields[0]
+	}
+	return s, scanner.Err()
+}
+
+// check returns an error if a file in want is not in s, or has a
+// different checksum. The files not in want are not checked, like the
+// meta file rewritten by Copy.
+func (s sha256Sums) check(want sha256Sums) error {
+	names := []string{}
+	for name := range want {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		got, ok := s[name]
+		if !ok {
+			return fmt.Errorf("the model is corrupted: missing file %s", name)
+		}
+		if got != want[name] {
+			return fmt.Errorf("the model is corrupted: SHA-256 checksum mismatch of file %s", name)
+		}
+	}
+	return nil
+}
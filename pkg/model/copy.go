@@ -0,0 +1,209 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"sqlflow.org/sqlflow/pkg/database"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+)
+
+// Copy copies the model saved at srcURI to dstURI. The two URIs may use
+// different backends. The tarball is streamed from the source to the
+// destination without being extracted on the local filesystem.
+func Copy(srcURI, dstURI string, session *pb.Session) error {
+	ctx := context.Background()
+	return readModel(ctx, srcURI, session, func(m *Model, tarball io.Reader) error {
+		if m.Deduplicated {
+			// The chunks are not copied along with the manifest.
+			return fmt.Errorf("copying deduplicated models is not supported now")
+		}
+		return writeModel(ctx, dstURI, session, m, tarball)
+	})
+}
+
+// readModel calls fn with the model meta and the tar-gzipped working
+// directory saved at modelURI.
+func readModel(ctx context.Context, modelURI string, session *pb.Session, fn func(*Model, io.Reader) error) error {
+	stream := func(r io.Reader) error {
+		br := bufio.NewReader(&contextReader{ctx, r})
+		m, e := readMeta(br)
+		if e != nil {
+			return e
+		}
+		return fn(m, br)
+	}
+	scheme, p, e := parseModelURI(modelURI)
+	if e != nil {
+		return e
+	}
+	if scheme == "file" {
+		dir, file := filepath.Split(p)
+		return readTar(dir, file, fn)
+	}
+	if s, ok := storageOf(scheme); ok {
+		return s.Get(ctx, p, session, stream)
+	}
+	db, e := database.OpenAndConnectDB(session.DbConnStr)
+	if e != nil {
+		return e
+	}
+	defer db.Close()
+	return readDB(db, p, stream)
+}
+
+// writeModel saves the model meta m and the tar-gzipped working directory
+// to modelURI.
+func writeModel(ctx context.Context, modelURI string, session *pb.Session, m *Model, tarball io.Reader) error {
+	stream := func(w io.Writer) error {
+		if e := writeMeta(w, m); e != nil {
+			return e
+		}
+		_, e := io.Copy(w, &contextReader{ctx, tarball})
+		return e
+	}
+	scheme, p, e := parseModelURI(modelURI)
+	if e != nil {
+		return e
+	}
+	if scheme == "file" {
+		dir, file := filepath.Split(p)
+		return writeTar(ctx, dir, file, m, tarball)
+	}
+	if s, ok := storageOf(scheme); ok {
+		return s.Put(ctx, p, session, stream)
+	}
+	db, e := database.OpenAndConnectDB(session.DbConnStr)
+	if e != nil {
+		return e
+	}
+	defer db.Close()
+	return writeDB(db, p, session, nil, stream)
+}
+
+// readTar calls fn with the model meta decoded from the meta file in the
+// tarball modelDir/save.tar.gz, and the tarball itself.
+func readTar(modelDir, save string, fn func(*Model, io.Reader) error) error {
+	tarFile := filepath.Join(modelDir, save+".tar.gz")
+	f, e := os.Open(tarFile)
+	if e != nil {
+		return fmt.Errorf("load tar file(%s) failed: %v", tarFile, e)
+	}
+	defer f.Close()
+	if !isGzip(f) {
+		// The model is encrypted and saved in the format of writeTo.
+		br := bufio.NewReader(f)
+		m, e := readMeta(br)
+		if e != nil {
+			return e
+		}
+		return fn(m, br)
+	}
+	m, e := decodeMetaInTarGz(f, save)
+	if e != nil {
+		return fmt.Errorf("load tar file(%s) failed: %v", tarFile, e)
+	}
+	if _, e := f.Seek(0, io.SeekStart); e != nil {
+		return e
+	}
+	return fn(m, f)
+}
+
+// writeTar writes the tarball modelDir/save.tar.gz, which contains the
+// entries in tarball and the meta file save.json that the file backend
+// loads the model meta from. The encrypted tarball of an encrypted model
+// is written as is after the meta of m, like what saveTar does.
+func writeTar(ctx context.Context, modelDir, save string, m *Model, tarball io.Reader) (e error) {
+	modelFile := filepath.Join(modelDir, save+".tar.gz")
+	f, e := os.Create(modelFile)
+	if e != nil {
+		return fmt.Errorf("create tar file(%s) failed: %v", modelFile, e)
+	}
+	defer func() {
+		f.Close()
+		if e != nil {
+			os.Remove(modelFile)
+		}
+	}()
+	if m.Encryption != "" {
+		if e = writeMeta(f, m); e != nil {
+			return e
+		}
+		if _, e = io.Copy(f, &contextReader{ctx, tarball}); e != nil {
+			return e
+		}
+		return f.Close()
+	}
+
+	gr, e := gzip.NewReader(&contextReader{ctx, tarball})
+	if e != nil {
+		return fmt.Errorf("copy model failed: %v", e)
+	}
+	defer gr.Close()
+	gw := gzip.NewWriter(f)
+	tr, tw := tar.NewReader(gr), tar.NewWriter(gw)
+
+	// Write the meta file first like saveTar does.
+	jsonFile, gobFile := jsonMetaFile(save), gobMetaFile(save)
+	b, e := json.Marshal(m)
+	if e != nil {
+		return fmt.Errorf("json-encoding model failed: %v", e)
+	}
+	hdr := &tar.Header{Name: jsonFile, Mode: 0644, Size: int64(len(b)), Typeflag: tar.TypeReg}
+	if e := tw.WriteHeader(hdr); e != nil {
+		return e
+	}
+	if _, e := tw.Write(b); e != nil {
+		return e
+	}
+	for {
+		hdr, e := tr.Next()
+		if e == io.EOF {
+			break
+		}
+		if e != nil {
+			return fmt.Errorf("copy model failed: %v", e)
+		}
+		if name := path.Clean(hdr.Name); name == jsonFile || name == gobFile {
+			// Replaced by the meta file written above.
+			continue
+		}
+		if e := tw.WriteHeader(hdr); e != nil {
+			return e
+		}
+		if _, e := io.Copy(tw, tr); e != nil {
+			return e
+		}
+	}
+	if e := tw.Close(); e != nil {
+		return e
+	}
+	if e := gw.Close(); e != nil {
+		return e
+	}
+	if e = f.Close(); e != nil {
+		return fmt.Errorf("close tar file(%s) failed: %v", modelFile, e)
+	}
+	return nil
+}
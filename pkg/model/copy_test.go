@@ -0,0 +1,80 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sqlflow.org/sqlflow/pkg/database"
+)
+
+// assertCopied loads the model at modelURI and checks it's the one
+// saved from mockWorkDir.
+func assertCopied(t *testing.T, modelURI string) {
+	a := assert.New(t)
+	dst, e := ioutil.TempDir("", "sqlflow_model_dst")
+	a.NoError(e)
+	defer os.RemoveAll(dst)
+	m, e := Load(modelURI, dst, nil)
+	a.NoError(e)
+	a.Equal(testTrainSelect, m.TrainSelect)
+	b, e := ioutil.ReadFile(filepath.Join(dst, "my_dnn_model", "variables", "variables.index"))
+	a.NoError(e)
+	a.Equal("index", string(b))
+
+	m, e = Load(modelURI, "", nil)
+	a.NoError(e)
+	a.Equal(testTrainSelect, m.TrainSelect)
+}
+
+func TestCopyFileToFile(t *testing.T) {
+	a := assert.New(t)
+	cwd := mockWorkDir(t)
+	defer os.RemoveAll(cwd)
+	modelDir, e := ioutil.TempDir("", "sqlflow_model_dir")
+	a.NoError(e)
+	defer os.RemoveAll(modelDir)
+
+	srcURI := "file://" + filepath.Join(modelDir, "my_dnn_model")
+	dstURI := "file://" + filepath.Join(modelDir, "my_copied_model")
+	a.NoError(New(cwd, testTrainSelect).Save(srcURI, nil, nil))
+	a.NoError(Copy(srcURI, dstURI, nil))
+	assertCopied(t, dstURI)
+}
+
+func TestCopyDBToFile(t *testing.T) {
+	a := assert.New(t)
+	db := database.GetTestingDBSingleton()
+	if db.DriverName != "mysql" {
+		t.Skip("Skip as SQLFLOW_TEST_DB is not MySQL")
+	}
+	cwd := mockWorkDir(t)
+	defer os.RemoveAll(cwd)
+	modelDir, e := ioutil.TempDir("", "sqlflow_model_dir")
+	a.NoError(e)
+	defer os.RemoveAll(modelDir)
+
+	session := database.GetSessionFromTestingDB()
+	table := "sqlflow_models.my_copy_model"
+	a.NoError(New(cwd, testTrainSelect).Save(table, nil, session))
+	defer Delete(table, session)
+
+	dstURI := "file://" + filepath.Join(modelDir, "my_dnn_model")
+	a.NoError(Copy(table, dstURI, session))
+	assertCopied(t, dstURI)
+}
@@ -0,0 +1,280 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	pb "sqlflow.org/sqlflow/pkg/proto"
+)
+
+// encryptionAES256GCM is the value of Model.Encryption of models
+// encrypted by AES-256-GCM.
+const encryptionAES256GCM = "AES-256-GCM"
+
+// encryptChunkSize is the size of the plaintext sealed in a chunk. GCM
+// can't encrypt a stream, so the tarball is sealed chunk by chunk.
+const encryptChunkSize = 64 << 10
+
+// ErrDecryption is returned by Load if the encrypted model cannot be
+// authenticated with the given key.
+var ErrDecryption = errors.New("model decryption failed: wrong encryption key or corrupted model")
+
+// parseEncryptionKey decodes the base64-encoded 256-bit key s.
+func parseEncryptionKey(s string) ([]byte, error) {
+	if strings.HasPrefix(s, kmsScheme) {
+		// withEncryptionKey resolves the keys from KMS to encrypt, so
+		// this is decrypting a model that was encrypted by a raw key.
+		return nil, fmt.Errorf("model encryption key from KMS cannot decrypt the model encrypted by a raw key")
+	}
+	key, e := base64.StdEncoding.DecodeString(s)
+	if e != nil {
+		return nil, fmt.Errorf("model encryption key should be base64-encoded: %v", e)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("model encryption key should have 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
+// encryptionKeyOf returns the key in session or the environment variable
+// SQLFLOW_MODEL_ENCRYPTION_KEY, which is base64-encoded or like
+// kms://name/key-id.
+func encryptionKeyOf(session *pb.Session) string {
+	if session != nil && session.ModelEncryptionKey != "" {
+		return session.ModelEncryptionKey
+	}
+	return os.Getenv("SQLFLOW_MODEL_ENCRYPTION_KEY")
+}
+
+// resolveEncryptionKey returns opts.EncryptionKey if it's set, otherwise the
+// key by encryptionKeyOf. It returns nil if no key is set.
+func resolveEncryptionKey(session *pb.Session, opts *Options) ([]byte, error) {
+	if opts != nil && opts.EncryptionKey != nil {
+		if len(opts.EncryptionKey) != 32 {
+			return nil, fmt.Errorf("model encryption key should have 32 bytes, got %d", len(opts.EncryptionKey))
+		}
+		return opts.EncryptionKey, nil
+	}
+	s := encryptionKeyOf(session)
+	if s == "" {
+		return nil, nil
+	}
+	return parseEncryptionKey(s)
+}
+
+// withEncryptionKey returns a copy of opts with EncryptionKey resolved
+// by resolveEncryptionKey, or with the master key of the KMS if the key
+// is like kms://name/key-id.
+func withEncryptionKey(session *pb.Session, opts *Options) (*Options, error) {
+	if s := encryptionKeyOf(session); (opts == nil || opts.EncryptionKey == nil) && strings.HasPrefix(s, kmsScheme) {
+		if _, _, e := parseKeyURI(s); e != nil {
+			return nil, e
+		}
+		o := Options{}
+		if opts != nil {
+			o = *opts
+		}
+		o.keyURI = s
+		return &o, nil
+	}
+	key, e := resolveEncryptionKey(session, opts)
+	if e != nil || key == nil {
+		return opts, e
+	}
+	o := Options{}
+	if opts != nil {
+		o = *opts
+	}
+	o.EncryptionKey = key
+	return &o, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, e := aes.NewCipher(key)
+	if e != nil {
+		return nil, e
+	}
+	return cipher.NewGCM(block)
+}
+
+// newNonce returns a random base nonce for encryptWriter.
+func newNonce() ([]byte, error) {
+	return newRandomBytes(12)
+}
+
+func newRandomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, e := io.ReadFull(rand.Reader, b); e != nil {
+		return nil, e
+	}
+	return b, nil
+}
+
+// chunkNonce derives the nonce of the i-th chunk from the base nonce.
+func chunkNonce(nonce []byte, i uint64) []byte {
+	n := append([]byte{}, nonce...)
+	var c [8]byte
+	binary.BigEndian.PutUint64(c[:], i)
+	for j := range c {
+		n[len(n)-8+j] ^= c[j]
+	}
+	return n
+}
+
+// chunkAD is the additional data of a chunk. It marks the last chunk so
+// that a tarball truncated at a chunk boundary fails the decryption.
+func chunkAD(last bool) []byte {
+	if last {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+// encryptWriter seals what's written to it chunk by chunk. The last
+// chunk, written by Close, is always shorter than encryptChunkSize.
+type encryptWriter struct {
+	aead  cipher.AEAD
+	nonce []byte
+	i     uint64
+	w     io.Writer
+	buf   []byte
+}
+
+func newEncryptWriter(w io.Writer, key, nonce []byte) (*encryptWriter, error) {
+	aead, e := newGCM(key)
+	if e != nil {
+		return nil, e
+	}
+	return &encryptWriter{aead: aead, nonce: nonce, w: w}, nil
+}
+
+func (c *encryptWriter) Write(p []byte) (int, error) {
+	c.buf = append(c.buf, p...)
+	for len(c.buf) >= encryptChunkSize {
+		if e := c.seal(c.buf[:encryptChunkSize], false); e != nil {
+			return 0, e
+		}
+		c.buf = c.buf[encryptChunkSize:]
+	}
+	return len(p), nil
+}
+
+// Close writes the last chunk. It doesn't close the underlying writer.
+func (c *encryptWriter) Close() error {
+	return c.seal(c.buf, true)
+}
+
+func (c *encryptWriter) seal(chunk []byte, last bool) error {
+	sealed := c.aead.Seal(nil, chunkNonce(c.nonce, c.i), chunk, chunkAD(last))
+	c.i++
+	_, e := c.w.Write(sealed)
+	return e
+}
+
+// decryptReader opens the chunks written by encryptWriter.
+type decryptReader struct {
+	aead   cipher.AEAD
+	nonce  []byte
+	i      uint64
+	r      io.Reader
+	sealed []byte
+	buf    []byte
+	done   bool
+}
+
+func newDecryptReader(r io.Reader, key, nonce []byte) (*decryptReader, error) {
+	aead, e := newGCM(key)
+	if e != nil {
+		return nil, e
+	}
+	return &decryptReader{
+		aead:   aead,
+		nonce:  nonce,
+		r:      r,
+		sealed: make([]byte, encryptChunkSize+aead.Overhead()),
+	}, nil
+}
+
+func (c *decryptReader) Read(p []byte) (int, error) {
+	for len(c.buf) == 0 {
+		if c.done {
+			return 0, io.EOF
+		}
+		if e := c.open(); e != nil {
+			return 0, e
+		}
+	}
+	n := copy(p, c.buf)
+	c.buf = c.buf[n:]
+	return n, nil
+}
+
+// open reads and opens the next chunk into c.buf.
+func (c *decryptReader) open() error {
+	n, e := io.ReadFull(c.r, c.sealed)
+	if e != nil && e != io.ErrUnexpectedEOF && e != io.EOF {
+		return e
+	}
+	// Only the last chunk is shorter than a full one.
+	last := n < len(c.sealed)
+	buf, e := c.aead.Open(nil, chunkNonce(c.nonce, c.i), c.sealed[:n], chunkAD(last))
+	if e != nil {
+		return ErrDecryption
+	}
+	c.i++
+	c.buf, c.done = buf, last
+	return nil
+}
+
+// decryptTarball returns the decrypted tarball r of the model m. It opens
+// the first chunk, so that a wrong key fails with ErrDecryption before
+// the extraction starts. The data key of a model encrypted by a key from
+// KMS is unwrapped by the KeyManager in m.KeyURI.
+func decryptTarball(ctx context.Context, m *Model, r io.Reader, opts *Options) (io.Reader, error) {
+	if m.Encryption != encryptionAES256GCM {
+		return nil, fmt.Errorf("unsupported model encryption %q", m.Encryption)
+	}
+	var key []byte
+	var e error
+	if m.KeyURI != "" {
+		key, e = unwrapDataKey(ctx, m.KeyURI, m.WrappedKey)
+	} else {
+		key, e = resolveEncryptionKey(nil, opts)
+	}
+	if e != nil {
+		return nil, e
+	}
+	if key == nil {
+		return nil, fmt.Errorf("the model is encrypted, please set the encryption key by SQLFLOW_MODEL_ENCRYPTION_KEY")
+	}
+	dr, e := newDecryptReader(r, key, m.Nonce)
+	if e != nil {
+		return nil, e
+	}
+	if e := dr.open(); e != nil {
+		return nil, e
+	}
+	return dr, nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+)
+
+func TestEncryptWriterAndDecryptReader(t *testing.T) {
+	a := assert.New(t)
+	key := bytes.Repeat([]byte{7}, 32)
+	nonce, e := newNonce()
+	a.NoError(e)
+	for _, size := range []int{0, 1, encryptChunkSize - 1, encryptChunkSize, encryptChunkSize + 1, 3 * encryptChunkSize} {
+		plain := make([]byte, size)
+		for i := range plain {
+			plain[i] = byte(i)
+		}
+		var sealed bytes.Buffer
+		w, e := newEncryptWriter(&sealed, key, nonce)
+		a.NoError(e)
+		_, e = w.Write(plain)
+		a.NoError(e)
+		a.NoError(w.Close())
+
+		r, e := newDecryptReader(bytes.NewReader(sealed.Bytes()), key, nonce)
+		a.NoError(e)
+		b, e := ioutil.ReadAll(r)
+		a.NoError(e)
+		a.Equal(plain, b)
+
+		// Truncating at a chunk boundary or in a chunk fails.
+		for _, n := range []int{sealed.Len() - 1, sealed.Len() / 2} {
+			r, e = newDecryptReader(bytes.NewReader(sealed.Bytes()[:n]), key, nonce)
+			a.NoError(e)
+			_, e = ioutil.ReadAll(r)
+			a.Equal(ErrDecryption, e)
+		}
+	}
+}
+
+func TestSaveAndLoadEncrypted(t *testing.T) {
+	a := assert.New(t)
+	cwd := mockWorkDir(t)
+	defer os.RemoveAll(cwd)
+	modelDir, e := ioutil.TempDir("", "sqlflow_model_dir")
+	a.NoError(e)
+	defer os.RemoveAll(modelDir)
+
+	key := bytes.Repeat([]byte{1}, 32)
+	session := &pb.Session{ModelEncryptionKey: base64.StdEncoding.EncodeToString(key)}
+	modelURI := "file://" + filepath.Join(modelDir, "my_dnn_model")
+	a.NoError(New(cwd, testTrainSelect).Save(modelURI, nil, session))
+	b, e := ioutil.ReadFile(filepath.Join(modelDir, "my_dnn_model.tar.gz"))
+	a.NoError(e)
+	a.False(bytes.Contains(b, []byte("graph")))
+
+	// The model meta is not encrypted.
+	m, e := Load(modelURI, "", nil)
+	a.NoError(e)
+	a.Equal(testTrainSelect, m.TrainSelect)
+	a.Equal(encryptionAES256GCM, m.Encryption)
+
+	dst, e := ioutil.TempDir("", "sqlflow_model_dst")
+	a.NoError(e)
+	defer os.RemoveAll(dst)
+	_, e = Load(modelURI, dst, nil)
+	a.Error(e)
+	_, e = LoadWithOptions(context.Background(), modelURI, dst, nil, &Options{EncryptionKey: bytes.Repeat([]byte{2}, 32)})
+	a.Equal(ErrDecryption, e)
+	m, e = LoadWithOptions(context.Background(), modelURI, dst, nil, &Options{EncryptionKey: key})
+	a.NoError(e)
+	a.Equal(testTrainSelect, m.TrainSelect)
+	b, e = ioutil.ReadFile(filepath.Join(dst, "my_dnn_model", "saved_model.pb"))
+	a.NoError(e)
+	a.Equal("graph", string(b))
+
+	// LoadWithSession decrypts by the key in the session.
+	sessionDst, e := ioutil.TempDir("", "sqlflow_model_dst")
+	a.NoError(e)
+	defer os.RemoveAll(sessionDst)
+	_, e = LoadWithSession(context.Background(), modelURI, sessionDst, &pb.Session{ModelEncryptionKey: base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32))}, nil)
+	a.Equal(ErrDecryption, e)
+	_, e = LoadWithSession(context.Background(), modelURI, sessionDst, session, nil)
+	a.NoError(e)
+	b, e = ioutil.ReadFile(filepath.Join(sessionDst, "my_dnn_model", "saved_model.pb"))
+	a.NoError(e)
+	a.Equal("graph", string(b))
+
+	// Copy the encrypted model as is.
+	copyURI := "file://" + filepath.Join(modelDir, "my_copied_model")
+	a.NoError(Copy(modelURI, copyURI, nil))
+	_, e = LoadWithOptions(context.Background(), copyURI, dst, nil, &Options{EncryptionKey: key})
+	a.NoError(e)
+}
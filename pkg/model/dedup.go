@@ -0,0 +1,414 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sqlflow.org/sqlflow/pkg/database"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+)
+
+// dedupChunkSize is the size of the chunks that the files in a
+// deduplicated model are split into.
+const dedupChunkSize = 1 << 20
+
+// chunksDir is the directory next to the deduplicated models saved in
+// files, which holds the chunks shared by these models.
+const chunksDir = ".sqlflow_chunks"
+
+// chunksTable is the table in the database of the deduplicated models
+// saved in sqlfs tables, which holds the chunks shared by these models.
+const chunksTable = "sqlflow_model_chunks"
+
+// manifest lists the entries in the tarball of a deduplicated model. It
+// follows the model meta where the tarball would be.
+type manifest struct {
+	Entries []manifestEntry `json:"entries"`
+}
+
+// manifestEntry is a tarball entry. The content of a regular file is the
+// concatenation of Chunks, which are the hex-encoded SHA-256 sums of the
+// chunks.
+type manifestEntry struct {
+	Name     string    `json:"name"`
+	Typeflag byte      `json:"typeflag"`
+	Mode     int64     `json:"mode"`
+	Size     int64     `json:"size"`
+	ModTime  time.Time `json:"mod_time"`
+	Linkname string    `json:"linkname,omitempty"`
+	Chunks   []string  `json:"chunks,omitempty"`
+}
+
+// chunkStore stores gzipped chunks by the SHA-256 sums of the chunks.
+type chunkStore interface {
+	has(sum string) (bool, error)
+	put(sum string, gzipped []byte) error
+	get(sum string) ([]byte, error)
+}
+
+// fileChunks stores each chunk in a file in dir.
+type fileChunks struct {
+	dir string
+}
+
+func (c *fileChunks) has(sum string) (bool, error) {
+	_, e := os.Stat(filepath.Join(c.dir, sum))
+	if os.IsNotExist(e) {
+		return false, nil
+	}
+	return e == nil, e
+}
+
+func (c *fileChunks) put(sum string, gzipped []byte) error {
+	if e := os.MkdirAll(c.dir, 0755); e != nil {
+		return e
+	}
+	// Write to a temporary file and rename it, so a concurrent Save
+	// never sees a partial chunk.
+	f, e := ioutil.TempFile(c.dir, ".tmp_"+sum)
+	if e != nil {
+		return e
+	}
+	if _, e := f.Write(gzipped); e != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return e
+	}
+	if e := f.Close(); e != nil {
+		os.Remove(f.Name())
+		return e
+	}
+	return os.Rename(f.Name(), filepath.Join(c.dir, sum))
+}
+
+func (c *fileChunks) get(sum string) ([]byte, error) {
+	return ioutil.ReadFile(filepath.Join(c.dir, sum))
+}
+
+// dbChunks stores the chunks in a MySQL table.
+type dbChunks struct {
+	db    *database.DB
+	table string
+}
+
+// newDBChunks returns the chunk store of the model in the sqlfs table,
+// which is in the same database of the table.
+func newDBChunks(db *database.DB, table string) (*dbChunks, error) {
+	if db.DriverName != "mysql" {
+		return nil, fmt.Errorf("deduplicated models in %s is not supported now", db.DriverName)
+	}
+	t := chunksTable
+	if i := strings.Index(table, "."); i >= 0 {
+		t = table[:i] + "." + chunksTable
+	}
+	return &dbChunks{db: db, table: t}, nil
+}
+
+func (c *dbChunks) create() error {
+	stmt := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (sum CHAR(64) NOT NULL PRIMARY KEY, data LONGBLOB NOT NULL)", c.table)
+	if _, e := c.db.Exec(stmt); e != nil {
+		return fmt.Errorf("exec:[%s] failed: %v", stmt, e)
+	}
+	return nil
+}
+
+func (c *dbChunks) has(sum string) (bool, error) {
+	var n int
+	e := c.db.QueryRow(fmt.Sprintf("SELECT 1 FROM %s WHERE sum = ?", c.table), sum).Scan(&n)
+	if e == sql.ErrNoRows {
+		return false, nil
+	}
+	return e == nil, e
+}
+
+func (c *dbChunks) put(sum string, gzipped []byte) error {
+	// Another Save may have stored the same chunk meanwhile.
+	_, e := c.db.Exec(fmt.Sprintf("INSERT IGNORE INTO %s (sum, data) VALUES (?, ?)", c.table), sum, gzipped)
+	return e
+}
+
+func (c *dbChunks) get(sum string) ([]byte, error) {
+	var b []byte
+	if e := c.db.QueryRow(fmt.Sprintf("SELECT data FROM %s WHERE sum = ?", c.table), sum).Scan(&b); e != nil {
+		return nil, fmt.Errorf("read chunk %s from %s failed: %v", sum, c.table, e)
+	}
+	return b, nil
+}
+
+// saveDedup saves the model with the files split into chunks, and only
+// the chunks not in the chunk store are stored. What's saved at modelURI
+// is the model meta followed by the manifest of the chunks.
+func (m *Model) saveDedup(ctx context.Context, scheme, p string, session *pb.Session, opts *Options) (*SaveStats, error) {
+	if opts.encrypted() {
+		return nil, fmt.Errorf("deduplication of encrypted models is not supported now")
+	}
+	switch scheme {
+	case "file":
+		dir, file := filepath.Split(p)
+		return m.writeDedup(ctx, &fileChunks{filepath.Join(dir, chunksDir)}, opts, func(fn func(io.Writer) error) error {
+			return writeFile(filepath.Join(dir, file+".tar.gz"), fn)
+		})
+	case "":
+		db, e := database.OpenAndConnectDB(session.DbConnStr)
+		if e != nil {
+			return nil, e
+		}
+		defer db.Close()
+		chunks, e := newDBChunks(db, p)
+		if e != nil {
+			return nil, e
+		}
+		if e := chunks.create(); e != nil {
+			return nil, e
+		}
+		return m.writeDedup(ctx, chunks, opts, func(fn func(io.Writer) error) error {
+			return retry(ctx, opts, func() error {
+				return writeDB(db, p, session, opts, fn)
+			})
+		})
+	}
+	return nil, fmt.Errorf("deduplicated models in %s is not supported now", scheme)
+}
+
+// writeDedup stores the chunks of the files in workDir into chunks, and
+// calls write to save the model meta and the manifest.
+func (m *Model) writeDedup(ctx context.Context, chunks chunkStore, opts *Options, write func(func(io.Writer) error) error) (*SaveStats, error) {
+	// Archive workDir with tarGzDir, so the entries are the same as a
+	// model saved without deduplication.
+	pr, pw := io.Pipe()
+	done := make(chan *SaveStats, 1)
+	go func() {
+		stats, e := tarGzDir(ctx, m.workDir, pw, gzip.NoCompression, m.saveProgress(opts))
+		pw.CloseWithError(e)
+		done <- stats
+	}()
+	mf, stored, e := storeChunks(pr, chunks, opts.compressionLevel())
+	// Unblock tarGzDir if storeChunks failed.
+	pr.CloseWithError(e)
+	stats := <-done
+	if e != nil {
+		return nil, e
+	}
+
+	meta := *m
+	meta.Encryption, meta.Nonce, meta.KeyURI, meta.WrappedKey = "", nil, "", nil
+	meta.Deduplicated = true
+	var cw *countingWriter
+	e = write(func(w io.Writer) error {
+		cw = &countingWriter{w: w}
+		if e := writeMeta(cw, &meta); e != nil {
+			return e
+		}
+		return json.NewEncoder(cw).Encode(mf)
+	})
+	if e != nil {
+		return nil, e
+	}
+	for _, entry := range mf.Entries {
+		stats.Chunks += len(entry.Chunks)
+	}
+	stats.NewChunks = stored.n
+	stats.CompressedSize = stored.size + cw.n
+	return stats, nil
+}
+
+// chunkCount counts the chunks stored and their gzipped size.
+type chunkCount struct {
+	n    int
+	size int64
+}
+
+// storeChunks reads the tar-gzipped stream r to the end, stores the
+// chunks of the files that chunks doesn't have, and returns the manifest
+// of r.
+func storeChunks(r io.Reader, chunks chunkStore, level int) (*manifest, *chunkCount, error) {
+	gr, e := gzip.NewReader(r)
+	if e != nil {
+		return nil, nil, e
+	}
+	defer gr.Close()
+	tr := tar.NewReader(gr)
+	mf := &manifest{Entries: []manifestEntry{}}
+	stored := &chunkCount{}
+	buf := make([]byte, dedupChunkSize)
+	for {
+		hdr, e := tr.Next()
+		if e == io.EOF {
+			break
+		}
+		if e != nil {
+			return nil, nil, e
+		}
+		entry := manifestEntry{
+			Name:     hdr.Name,
+			Typeflag: hdr.Typeflag,
+			Mode:     hdr.Mode,
+			Size:     hdr.Size,
+			ModTime:  hdr.ModTime,
+			Linkname: hdr.Linkname,
+		}
+		for {
+			n, e := io.ReadFull(tr, buf)
+			if n > 0 {
+				h := sha256.Sum256(buf[:n])
+				sum := hex.EncodeToString(h[:])
+				entry.Chunks = append(entry.Chunks, sum)
+				if e := storeChunk(chunks, sum, buf[:n], level, stored); e != nil {
+					return nil, nil, fmt.Errorf("store chunk of %s failed: %v", hdr.Name, e)
+				}
+			}
+			if e == io.EOF || e == io.ErrUnexpectedEOF {
+				break
+			}
+			if e != nil {
+				return nil, nil, e
+			}
+		}
+		mf.Entries = append(mf.Entries, entry)
+	}
+	// Read the end of the gzip stream, so the writer is not blocked.
+	if _, e := io.Copy(ioutil.Discard, gr); e != nil {
+		return nil, nil, e
+	}
+	return mf, stored, nil
+}
+
+func storeChunk(chunks chunkStore, sum string, chunk []byte, level int, stored *chunkCount) error {
+	ok, e := chunks.has(sum)
+	if e != nil || ok {
+		return e
+	}
+	var b bytes.Buffer
+	gw, e := gzip.NewWriterLevel(&b, level)
+	if e != nil {
+		return e
+	}
+	if _, e := gw.Write(chunk); e != nil {
+		return e
+	}
+	if e := gw.Close(); e != nil {
+		return e
+	}
+	if e := chunks.put(sum, b.Bytes()); e != nil {
+		return e
+	}
+	stored.n++
+	stored.size += int64(b.Len())
+	return nil
+}
+
+// assembleTarGz returns the tar-gzipped stream of the entries in the
+// manifest decoded from r. It checks the SHA-256 sum of every chunk read
+// from chunks. The caller closes the returned reader to stop assembling.
+func assembleTarGz(ctx context.Context, r io.Reader, chunks chunkStore) (io.ReadCloser, error) {
+	if chunks == nil {
+		return nil, fmt.Errorf("loading deduplicated models from this storage is not supported now")
+	}
+	mf := &manifest{}
+	if e := json.NewDecoder(r).Decode(mf); e != nil {
+		return nil, fmt.Errorf("decode the manifest of the deduplicated model failed: %v", e)
+	}
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeManifestTarGz(ctx, pw, mf, chunks))
+	}()
+	return pr, nil
+}
+
+func writeManifestTarGz(ctx context.Context, w io.Writer, mf *manifest, chunks chunkStore) error {
+	gw, e := gzip.NewWriterLevel(w, gzip.BestSpeed)
+	if e != nil {
+		return e
+	}
+	tw := tar.NewWriter(gw)
+	for _, entry := range mf.Entries {
+		if e := ctx.Err(); e != nil {
+			return e
+		}
+		hdr := &tar.Header{
+			Name:     entry.Name,
+			Typeflag: entry.Typeflag,
+			Mode:     entry.Mode,
+			Size:     entry.Size,
+			ModTime:  entry.ModTime,
+			Linkname: entry.Linkname,
+		}
+		if e := tw.WriteHeader(hdr); e != nil {
+			return e
+		}
+		for _, sum := range entry.Chunks {
+			if e := copyChunk(tw, chunks, sum); e != nil {
+				return fmt.Errorf("read chunk of %s failed: %v", entry.Name, e)
+			}
+		}
+	}
+	if e := tw.Close(); e != nil {
+		return e
+	}
+	return gw.Close()
+}
+
+func copyChunk(w io.Writer, chunks chunkStore, sum string) error {
+	gzipped, e := chunks.get(sum)
+	if e != nil {
+		return e
+	}
+	gr, e := gzip.NewReader(bytes.NewReader(gzipped))
+	if e != nil {
+		return e
+	}
+	chunk, e := ioutil.ReadAll(gr)
+	if e != nil {
+		return e
+	}
+	if h := sha256.Sum256(chunk); hex.EncodeToString(h[:]) != sum {
+		return fmt.Errorf("chunk %s is corrupted", sum)
+	}
+	_, e = w.Write(chunk)
+	return e
+}
+
+// writeFile writes what fn writes to the file p, and removes the partial
+// file if fn fails.
+func writeFile(p string, fn func(io.Writer) error) (e error) {
+	f, e := os.Create(p)
+	if e != nil {
+		return fmt.Errorf("create file(%s) failed: %v", p, e)
+	}
+	defer func() {
+		f.Close()
+		if e != nil {
+			os.Remove(p)
+		}
+	}()
+	if e = fn(f); e != nil {
+		return e
+	}
+	return f.Close()
+}
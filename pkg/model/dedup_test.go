@@ -0,0 +1,139 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sqlflow.org/sqlflow/pkg/database"
+)
+
+// mockLargeWorkDir creates a working directory with a file of a few
+// chunks besides the files of mockWorkDir.
+func mockLargeWorkDir(t *testing.T) string {
+	a := assert.New(t)
+	cwd := mockWorkDir(t)
+	b := make([]byte, 3*dedupChunkSize+100)
+	rand.New(rand.NewSource(0)).Read(b)
+	a.NoError(ioutil.WriteFile(filepath.Join(cwd, "my_dnn_model", "variables", "variables.data"), b, 0644))
+	return cwd
+}
+
+func TestSaveAndLoadDedup(t *testing.T) {
+	a := assert.New(t)
+	cwd := mockLargeWorkDir(t)
+	defer os.RemoveAll(cwd)
+	modelDir, e := ioutil.TempDir("", "sqlflow_model_dir")
+	a.NoError(e)
+	defer os.RemoveAll(modelDir)
+
+	ctx := context.Background()
+	opts := &Options{Dedup: true}
+	modelURI := "file://" + filepath.Join(modelDir, "my_dnn_model")
+	stats, e := New(cwd, testTrainSelect).SaveWithOptions(ctx, modelURI, nil, nil, opts)
+	a.NoError(e)
+	// three mocked files, a file of four chunks, and the checksums
+	a.Equal(8, stats.Chunks)
+	a.Equal(8, stats.NewChunks)
+
+	// Saving the same working directory again stores no chunk.
+	stats, e = New(cwd, testTrainSelect).SaveWithOptions(ctx, modelURI, nil, nil, opts)
+	a.NoError(e)
+	a.Equal(8, stats.Chunks)
+	a.Equal(0, stats.NewChunks)
+	a.True(stats.CompressedSize < dedupChunkSize/100)
+
+	// Changing a small file stores only the chunk of the file and the
+	// chunk of the checksums.
+	a.NoError(ioutil.WriteFile(filepath.Join(cwd, "train.py"), []byte("print('bye')"), 0644))
+	stats, e = New(cwd, testTrainSelect).SaveWithOptions(ctx, modelURI, nil, nil, opts)
+	a.NoError(e)
+	a.Equal(2, stats.NewChunks)
+
+	m, e := LoadMeta(modelURI, nil)
+	a.NoError(e)
+	a.True(m.Deduplicated)
+	a.Equal(testTrainSelect, m.TrainSelect)
+
+	dst, e := ioutil.TempDir("", "sqlflow_model_dst")
+	a.NoError(e)
+	defer os.RemoveAll(dst)
+	_, e = Load(modelURI, dst, nil)
+	a.NoError(e)
+	for _, f := range []string{"train.py", "my_dnn_model/saved_model.pb", "my_dnn_model/variables/variables.data"} {
+		expected, e := ioutil.ReadFile(filepath.Join(cwd, f))
+		a.NoError(e)
+		b, e := ioutil.ReadFile(filepath.Join(dst, f))
+		a.NoError(e)
+		a.True(bytes.Equal(expected, b), f)
+	}
+
+	// Load fails if a chunk doesn't match its sum.
+	chunks, e := filepath.Glob(filepath.Join(modelDir, chunksDir, "*"))
+	a.NoError(e)
+	a.Equal(10, len(chunks))
+	b, e := ioutil.ReadFile(chunks[0])
+	a.NoError(e)
+	for _, c := range chunks[1:] {
+		a.NoError(ioutil.WriteFile(c, b, 0644))
+	}
+	dst2, e := ioutil.TempDir("", "sqlflow_model_dst")
+	a.NoError(e)
+	defer os.RemoveAll(dst2)
+	_, e = Load(modelURI, dst2, nil)
+	a.Error(e)
+	a.Contains(e.Error(), "corrupted")
+
+	_, e = New(cwd, testTrainSelect).SaveWithOptions(ctx, modelURI, nil, nil, &Options{Dedup: true, EncryptionKey: bytes.Repeat([]byte{1}, 32)})
+	a.Error(e)
+}
+
+func TestSaveAndLoadDedupDB(t *testing.T) {
+	a := assert.New(t)
+	db := database.GetTestingDBSingleton()
+	if db.DriverName != "mysql" {
+		t.Skip("deduplicated models are only supported in MySQL")
+	}
+	session := database.GetSessionFromTestingDB()
+	cwd := mockLargeWorkDir(t)
+	defer os.RemoveAll(cwd)
+
+	ctx := context.Background()
+	opts := &Options{Dedup: true}
+	table := "sqlflow_models.my_dedup_model"
+	_, e := New(cwd, testTrainSelect).SaveWithOptions(ctx, table, nil, session, opts)
+	a.NoError(e)
+	stats, e := New(cwd, testTrainSelect).SaveWithOptions(ctx, table, nil, session, opts)
+	a.NoError(e)
+	a.Equal(8, stats.Chunks)
+	a.Equal(0, stats.NewChunks)
+
+	dst, e := ioutil.TempDir("", "sqlflow_model_dst")
+	a.NoError(e)
+	defer os.RemoveAll(dst)
+	m, e := Load(table, dst, db)
+	a.NoError(e)
+	a.True(m.Deduplicated)
+	b, e := ioutil.ReadFile(filepath.Join(dst, "my_dnn_model", "variables", "variables.index"))
+	a.NoError(e)
+	a.Equal("index", string(b))
+	a.NoError(Delete(table, session))
+}
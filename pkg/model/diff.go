@@ -0,0 +1,107 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	pb "sqlflow.org/sqlflow/pkg/proto"
+)
+
+// Difference is a field whose values differ between the models compared
+// by Diff, like the attribute "attributes.train.epoch" or the training SQL
+// "train_select". A or B is "" if the model doesn't have the field.
+type Difference struct {
+	Field string
+	A     string
+	B     string
+}
+
+// DiffURIs loads the model meta at modelURIA and modelURIB and returns
+// their differences.  Like LoadMeta, it never reads the tarballs.
+func DiffURIs(modelURIA, modelURIB string, session *pb.Session) ([]Difference, error) {
+	a, e := LoadMeta(modelURIA, session)
+	if e != nil {
+		return nil, e
+	}
+	b, e := LoadMeta(modelURIB, session)
+	if e != nil {
+		return nil, e
+	}
+	return Diff(a, b), nil
+}
+
+// Diff returns the differences between the hyperparameters, the feature
+// columns, the training SQL, and the metrics of the cross-validation
+// and the tuning of the models a and b, sorted by the field.
+func Diff(a, b *Model) []Difference {
+	fa, fb := fieldsOf(a), fieldsOf(b)
+	diffs := []Difference{}
+	for f := range fb {
+		if _, ok := fa[f]; !ok {
+			fa[f] = ""
+		}
+	}
+	for f, va := range fa {
+		if vb := fb[f]; va != vb {
+			diffs = append(diffs, Difference{Field: f, A: va, B: vb})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Field < diffs[j].Field })
+	return diffs
+}
+
+// fieldsOf flattens the compared fields of m into a map.
+func fieldsOf(m *Model) map[string]string {
+	fields := map[string]string{"train_select": strings.TrimSpace(m.TrainSelect)}
+	md := m.Metadata
+	if md == nil {
+		return fields
+	}
+	fields["estimator"] = md.Estimator
+	fields["framework"] = md.Framework
+	fields["framework_version"] = md.FrameworkVersion
+	fields["sqlflow_version"] = md.SQLFlowVersion
+	fields["driver"] = md.Driver
+	fields["label"] = md.Label
+	for k, v := range md.Attributes {
+		if b, e := json.Marshal(v); e == nil {
+			fields["attributes."+k] = string(b)
+		}
+	}
+	for group, columns := range md.FeatureColumns {
+		fields["feature_columns."+group] = strings.Join(columns, ", ")
+	}
+	if cv := md.CrossValidation; cv != nil {
+		fields["cross_validation.kfold"] = fmt.Sprint(cv.KFold)
+		for name, s := range cv.Metrics {
+			fields["cross_validation."+name] = fmt.Sprintf("%g (std %g)", s.Mean, s.Std)
+		}
+	}
+	if t := md.Tuning; t != nil {
+		fields["tuning.algorithm"] = t.Algorithm
+		if t.Best >= 0 && t.Best < len(t.Trials) {
+			fields["tuning."+t.Metric] = fmt.Sprint(t.Trials[t.Best].Score)
+		}
+	}
+	for f, v := range fields {
+		if v == "" {
+			delete(fields, f)
+		}
+	}
+	return fields
+}
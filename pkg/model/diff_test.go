@@ -0,0 +1,72 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sqlflow.org/sqlflow/pkg/ir"
+)
+
+func TestDiff(t *testing.T) {
+	a := assert.New(t)
+	cwd := mockWorkDir(t)
+	defer os.RemoveAll(cwd)
+	modelDir, e := ioutil.TempDir("", "sqlflow_model_dir")
+	a.NoError(e)
+	defer os.RemoveAll(modelDir)
+
+	sepalLength := &ir.NumericColumn{FieldDesc: &ir.FieldDesc{Name: "sepal_length", DType: ir.Float, Shape: []int{1}}}
+	sepalWidth := &ir.NumericColumn{FieldDesc: &ir.FieldDesc{Name: "sepal_width", DType: ir.Float, Shape: []int{1}}}
+	trainStmt := &ir.TrainStmt{
+		Estimator:  "xgboost.gbtree",
+		Attributes: map[string]interface{}{"objective": "multi:softprob", "train.num_boost_round": 30},
+		Features:   map[string][]ir.FeatureColumn{"feature_columns": {sepalLength}},
+		Label:      &ir.NumericColumn{FieldDesc: &ir.FieldDesc{Name: "class", DType: ir.Int}},
+	}
+	modelA := "file://" + filepath.Join(modelDir, "model_a")
+	a.NoError(New(cwd, testTrainSelect).Save(modelA, trainStmt, nil))
+
+	trainStmt.Attributes = map[string]interface{}{"objective": "multi:softprob", "eta": 0.1}
+	trainStmt.Features = map[string][]ir.FeatureColumn{"feature_columns": {sepalLength, sepalWidth}}
+	cv := `{"kfold": 5, "metrics": {"accuracy_score": {"mean": 0.9, "std": 0.05}}}`
+	a.NoError(ioutil.WriteFile(filepath.Join(cwd, crossValidationFile), []byte(cv), 0644))
+	modelB := "file://" + filepath.Join(modelDir, "model_b")
+	a.NoError(New(cwd, testTrainSelect+" WHERE class < 2").Save(modelB, trainStmt, nil))
+
+	diffs, e := DiffURIs(modelA, modelB, nil)
+	a.NoError(e)
+	a.Equal([]Difference{
+		{Field: "attributes.eta", A: "", B: "0.1"},
+		{Field: "attributes.train.num_boost_round", A: "30", B: ""},
+		{Field: "cross_validation.accuracy_score", A: "", B: "0.9 (std 0.05)"},
+		{Field: "cross_validation.kfold", A: "", B: "5"},
+		{Field: "feature_columns.feature_columns", A: "NumericColumn(sepal_length)", B: "NumericColumn(sepal_length), NumericColumn(sepal_width)"},
+		{Field: "train_select", A: testTrainSelect, B: testTrainSelect + " WHERE class < 2"},
+	}, diffs)
+
+	diffs, e = DiffURIs(modelA, modelA, nil)
+	a.NoError(e)
+	a.Empty(diffs)
+
+	_, e = DiffURIs(modelA, "file://"+filepath.Join(modelDir, "no_such_model"), nil)
+	a.Error(e)
+
+	// Models saved without a TrainStmt only differ in the training SQL.
+	a.Equal([]Difference{{Field: "estimator", A: "", B: "xgboost.gbtree"}}, Diff(&Model{}, &Model{Metadata: &Metadata{Estimator: "xgboost.gbtree"}}))
+}
@@ -0,0 +1,77 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// exportMagic starts the header line of an exported model file, which
+// is followed by the format version, like "SQLFLOW-MODEL 1\n".
+const exportMagic = "SQLFLOW-MODEL"
+
+// exportVersion is the format version written by ExportFile. Version 1
+// is the header line followed by what writeTo writes.
+const exportVersion = 1
+
+// ExportFile writes the model into a single self-contained file, which
+// can be imported by ImportFile without any database or cloud storage.
+func (m *Model) ExportFile(path string) (e error) {
+	f, e := os.Create(path)
+	if e != nil {
+		return fmt.Errorf("create model file(%s) failed: %v", path, e)
+	}
+	defer func() {
+		f.Close()
+		if e != nil {
+			os.Remove(path)
+		}
+	}()
+	if _, e = fmt.Fprintf(f, "%s %d\n", exportMagic, exportVersion); e != nil {
+		return e
+	}
+	if _, e = m.writeTo(context.Background(), f, nil); e != nil {
+		return e
+	}
+	return f.Close()
+}
+
+// ImportFile reads the model file written by ExportFile, and untars the
+// model into dst if dst is not "".
+func ImportFile(path, dst string) (*Model, error) {
+	f, e := os.Open(path)
+	if e != nil {
+		return nil, fmt.Errorf("open model file(%s) failed: %v", path, e)
+	}
+	defer f.Close()
+	br := bufio.NewReader(f)
+	header, e := br.ReadString('\n')
+	fields := strings.Fields(header)
+	if e != nil || len(fields) != 2 || fields[0] != exportMagic {
+		return nil, fmt.Errorf("%s is not a model file exported by SQLFlow", path)
+	}
+	version, e := strconv.Atoi(fields[1])
+	if e != nil {
+		return nil, fmt.Errorf("%s has a malformed version %q", path, fields[1])
+	}
+	if version != exportVersion {
+		return nil, fmt.Errorf("%s has format version %d, this SQLFlow supports version %d", path, version, exportVersion)
+	}
+	return readFrom(context.Background(), br, dst, nil, nil, nil)
+}
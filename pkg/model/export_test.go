@@ -0,0 +1,59 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportAndImportFile(t *testing.T) {
+	a := assert.New(t)
+	cwd := mockWorkDir(t)
+	defer os.RemoveAll(cwd)
+	dir, e := ioutil.TempDir("", "sqlflow_model_export")
+	a.NoError(e)
+	defer os.RemoveAll(dir)
+
+	modelFile := filepath.Join(dir, "my_dnn_model.sqlflow")
+	a.NoError(New(cwd, testTrainSelect).ExportFile(modelFile))
+
+	m, e := ImportFile(modelFile, "")
+	a.NoError(e)
+	a.Equal(testTrainSelect, m.TrainSelect)
+
+	dst := filepath.Join(dir, "dst")
+	m, e = ImportFile(modelFile, dst)
+	a.NoError(e)
+	a.Equal(testTrainSelect, m.TrainSelect)
+	b, e := ioutil.ReadFile(filepath.Join(dst, "my_dnn_model", "variables", "variables.index"))
+	a.NoError(e)
+	a.Equal("index", string(b))
+
+	b, e = ioutil.ReadFile(modelFile)
+	a.NoError(e)
+	future := filepath.Join(dir, "future.sqlflow")
+	a.NoError(ioutil.WriteFile(future, append([]byte("SQLFLOW-MODEL 2\n"), b[len("SQLFLOW-MODEL 1\n"):]...), 0644))
+	_, e = ImportFile(future, "")
+	a.Error(e)
+	a.Contains(e.Error(), "format version 2")
+
+	_, e = ImportFile(filepath.Join(cwd, "train.py"), "")
+	a.Error(e)
+	a.Contains(e.Error(), "not a model file")
+}
@@ -0,0 +1,164 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/option"
+
+	pb "sqlflow.org/sqlflow/pkg/proto"
+)
+
+// gcsChunkSize is the chunk size of the resumable upload, which has no
+// limit on the object size. Larger chunks take fewer requests to upload a
+// large model, and each chunk is buffered in memory.
+const gcsChunkSize = 16 << 20
+
+// gcsCredentialsFile returns the service account key file in session. A
+// nil session means the environment variable MakeSessionFromEnv reads it
+// from.
+func gcsCredentialsFile(session *pb.Session) string {
+	if session == nil {
+		return os.Getenv("SQLFLOW_GCS_CREDENTIALS_FILE")
+	}
+	return session.GcsCredentialsFile
+}
+
+// newGCSClient creates a GCS client with the service account key file in
+// session, or the application default credentials if it's empty.
+func newGCSClient(ctx context.Context, session *pb.Session) (*storage.Client, error) {
+	opts := []option.ClientOption{}
+	if f := gcsCredentialsFile(session); f != "" {
+		opts = append(opts, option.WithCredentialsFile(f))
+	}
+	return storage.NewClient(ctx, opts...)
+}
+
+// gcsStorage stores the models in the objects gs://bucket/object.
+type gcsStorage struct{}
+
+func (gcsStorage) Put(ctx context.Context, p string, session *pb.Session, fn func(io.Writer) error) error {
+	bucket, object := splitBucketKey(p)
+	return writeGCS(ctx, bucket, object, session, fn)
+}
+
+func (gcsStorage) Get(ctx context.Context, p string, session *pb.Session, fn func(io.Reader) error) error {
+	bucket, object := splitBucketKey(p)
+	return readGCS(ctx, bucket, object, session, -1, fn)
+}
+
+// getHead reads only the leading n bytes by a range request.
+func (gcsStorage) getHead(ctx context.Context, p string, session *pb.Session, n int64, fn func(io.Reader) error) error {
+	bucket, object := splitBucketKey(p)
+	return readGCS(ctx, bucket, object, session, n, fn)
+}
+
+func (gcsStorage) Stat(ctx context.Context, p string, session *pb.Session) (int64, error) {
+	bucket, object := splitBucketKey(p)
+	return statGCS(ctx, bucket, object, session)
+}
+
+func (gcsStorage) Delete(ctx context.Context, p string, session *pb.Session) error {
+	bucket, object := splitBucketKey(p)
+	return deleteGCS(ctx, bucket, object, session)
+}
+
+// writeGCS uploads what fn writes to the object gs://bucket/object. GCS
+// has no directories, so the "path" of the object needs no creation.
+func writeGCS(ctx context.Context, bucket, object string, session *pb.Session, fn func(io.Writer) error) error {
+	if bucket == "" || object == "" {
+		return fmt.Errorf("gs modelURI should be gs://bucket/object, got bucket %q, object %q", bucket, object)
+	}
+	client, e := newGCSClient(ctx, session)
+	if e != nil {
+		return fmt.Errorf("cannot create GCS client: %v", e)
+	}
+	defer client.Close()
+
+	// Canceling the context aborts the upload, so a failed fn doesn't
+	// leave a partial object.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	w := client.Bucket(bucket).Object(object).NewWriter(ctx)
+	w.ChunkSize = gcsChunkSize
+	if e := fn(w); e != nil {
+		cancel()
+		w.Close()
+		return e
+	}
+	if e := w.Close(); e != nil {
+		return fmt.Errorf("upload model to gs://%s/%s failed: %v", bucket, object, e)
+	}
+	return nil
+}
+
+// readGCS calls fn with the leading length bytes of the object
+// gs://bucket/object, or the whole object if length is negative.
+func readGCS(ctx context.Context, bucket, object string, session *pb.Session, length int64, fn func(io.Reader) error) error {
+	if bucket == "" || object == "" {
+		return fmt.Errorf("gs modelURI should be gs://bucket/object, got bucket %q, object %q", bucket, object)
+	}
+	client, e := newGCSClient(ctx, session)
+	if e != nil {
+		return fmt.Errorf("cannot create GCS client: %v", e)
+	}
+	defer client.Close()
+	r, e := client.Bucket(bucket).Object(object).NewRangeReader(ctx, 0, length)
+	if e != nil {
+		return fmt.Errorf("download model from gs://%s/%s failed: %v", bucket, object, e)
+	}
+	defer r.Close()
+	return fn(r)
+}
+
+// statGCS returns the size of the object gs://bucket/object.
+func statGCS(ctx context.Context, bucket, object string, session *pb.Session) (int64, error) {
+	if bucket == "" || object == "" {
+		return 0, fmt.Errorf("gs modelURI should be gs://bucket/object, got bucket %q, object %q", bucket, object)
+	}
+	client, e := newGCSClient(ctx, session)
+	if e != nil {
+		return 0, fmt.Errorf("cannot create GCS client: %v", e)
+	}
+	defer client.Close()
+	attrs, e := client.Bucket(bucket).Object(object).Attrs(ctx)
+	if e != nil {
+		if e == storage.ErrObjectNotExist {
+			return 0, ErrModelNotFound
+		}
+		return 0, fmt.Errorf("stat gs://%s/%s failed: %v", bucket, object, e)
+	}
+	return attrs.Size, nil
+}
+
+// deleteGCS deletes the object gs://bucket/object.
+func deleteGCS(ctx context.Context, bucket, object string, session *pb.Session) error {
+	client, e := newGCSClient(ctx, session)
+	if e != nil {
+		return fmt.Errorf("cannot create GCS client: %v", e)
+	}
+	defer client.Close()
+	if e := client.Bucket(bucket).Object(object).Delete(ctx); e != nil {
+		if e == storage.ErrObjectNotExist {
+			return ErrModelNotFound
+		}
+		return fmt.Errorf("delete gs://%s/%s failed: %v", bucket, object, e)
+	}
+	return nil
+}
@@ -0,0 +1,288 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/colinmarc/hdfs/v2"
+	krb "gopkg.in/jcmturner/gokrb5.v7/client"
+	"gopkg.in/jcmturner/gokrb5.v7/config"
+	"gopkg.in/jcmturner/gokrb5.v7/credentials"
+	"gopkg.in/jcmturner/gokrb5.v7/keytab"
+
+	pb "sqlflow.org/sqlflow/pkg/proto"
+)
+
+// defaultHDFSServicePrincipal is the service principal of the namenodes
+// in most kerberized clusters, where _HOST is the namenode address.
+const defaultHDFSServicePrincipal = "nn/_HOST"
+
+// hdfsKerberos is the Kerberos authentication to HDFS in the session.
+type hdfsKerberos struct {
+	principal        string // like "sqlflow@EXAMPLE.COM"
+	keytab           string
+	ccache           string
+	servicePrincipal string
+}
+
+// hdfsKerberosOf returns the Kerberos authentication in session. A nil
+// session means the environment variables MakeSessionFromEnv reads them
+// from.
+func hdfsKerberosOf(session *pb.Session) hdfsKerberos {
+	if session == nil {
+		return hdfsKerberos{
+			principal:        os.Getenv("SQLFLOW_HDFS_KERBEROS_PRINCIPAL"),
+			keytab:           os.Getenv("SQLFLOW_HDFS_KERBEROS_KEYTAB"),
+			ccache:           os.Getenv("SQLFLOW_HDFS_KERBEROS_CCACHE"),
+			servicePrincipal: os.Getenv("SQLFLOW_HDFS_KERBEROS_SERVICE_PRINCIPAL"),
+		}
+	}
+	return hdfsKerberos{
+		principal:        session.HdfsKerberosPrincipal,
+		keytab:           session.HdfsKerberosKeytab,
+		ccache:           session.HdfsKerberosCcache,
+		servicePrincipal: session.HdfsKerberosServicePrincipal,
+	}
+}
+
+// krb5ConfigFile returns the Kerberos configuration file, $KRB5_CONFIG
+// like the MIT Kerberos tools, or /etc/krb5.conf by default, and
+// krb5.ini in %ProgramData%\MIT\Kerberos5 on Windows.
+func krb5ConfigFile() string {
+	if f := os.Getenv("KRB5_CONFIG"); f != "" {
+		return f
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("ProgramData"), "MIT", "Kerberos5", "krb5.ini")
+	}
+	return "/etc/krb5.conf"
+}
+
+// newKerberosClient logs in to the KDC by the keytab of the principal,
+// or by the ticket cache if there is no keytab. It returns nil if neither
+// is set, which means HDFS doesn't require Kerberos.
+func (k hdfsKerberos) newKerberosClient() (*krb.Client, error) {
+	if k.keytab == "" && k.ccache == "" {
+		return nil, nil
+	}
+	conf, e := config.Load(krb5ConfigFile())
+	if e != nil {
+		return nil, fmt.Errorf("load Kerberos configuration %s failed: %v", krb5ConfigFile(), e)
+	}
+	if k.keytab == "" {
+		cc, e := credentials.LoadCCache(k.ccache)
+		if e != nil {
+			return nil, fmt.Errorf("load Kerberos ticket cache %s failed: %v", k.ccache, e)
+		}
+		cl, e := krb.NewClientFromCCache(cc, conf)
+		if e != nil {
+			return nil, fmt.Errorf("create Kerberos client from ticket cache %s failed: %v", k.ccache, e)
+		}
+		return cl, nil
+	}
+	if k.principal == "" {
+		return nil, fmt.Errorf("the Kerberos principal of the keytab %s should be set", k.keytab)
+	}
+	user, realm := k.principal, conf.LibDefaults.DefaultRealm
+	if i := strings.LastIndex(k.principal, "@"); i >= 0 {
+		user, realm = k.principal[:i], k.principal[i+1:]
+	}
+	kt, e := keytab.Load(k.keytab)
+	if e != nil {
+		return nil, fmt.Errorf("load Kerberos keytab %s failed: %v", k.keytab, e)
+	}
+	cl := krb.NewClientWithKeytab(user, realm, kt, conf)
+	if e := cl.Login(); e != nil {
+		return nil, fmt.Errorf("Kerberos login as %s failed: %v", k.principal, e)
+	}
+	return cl, nil
+}
+
+// splitHDFSPath splits "namenode:port/path/to/model" into the namenode
+// address and the absolute path. The address is empty for
+// "/path/to/model", which is from a modelURI like hdfs:///path/to/model.
+func splitHDFSPath(uriPath string) (string, string) {
+	i := strings.Index(uriPath, "/")
+	if i < 0 {
+		return uriPath, ""
+	}
+	return uriPath[:i], uriPath[i:]
+}
+
+// newHDFSClient connects to the namenode at addr, or at the namenode in
+// session if addr is empty. It authenticates by Kerberos if there is a
+// keytab or a ticket cache in session.
+func newHDFSClient(addr string, session *pb.Session) (*hdfs.Client, error) {
+	user := os.Getenv("HADOOP_USER_NAME")
+	if session != nil {
+		if addr == "" {
+			addr = session.HdfsNamenodeAddr
+		}
+		if session.HdfsUser != "" {
+			user = session.HdfsUser
+		}
+	}
+	if addr == "" {
+		return nil, fmt.Errorf("no HDFS namenode address in the modelURI or the session")
+	}
+	opts := hdfs.ClientOptions{Addresses: []string{addr}, User: user}
+	k := hdfsKerberosOf(session)
+	cl, e := k.newKerberosClient()
+	if e != nil {
+		return nil, e
+	}
+	if cl != nil {
+		// The user is the principal if it's empty.
+		opts.KerberosClient = cl
+		opts.KerberosServicePrincipleName = k.servicePrincipal
+		if opts.KerberosServicePrincipleName == "" {
+			opts.KerberosServicePrincipleName = defaultHDFSServicePrincipal
+		}
+	} else if opts.User == "" {
+		// colinmarc/hdfs requires a user without Kerberos.
+		opts.User = "root"
+		if u := os.Getenv("USER"); u != "" {
+			opts.User = u
+		} else if u := os.Getenv("USERNAME"); u != "" { // on Windows
+			opts.User = u
+		}
+	}
+	return hdfs.NewClient(opts)
+}
+
+// hdfsError surfaces permission errors clearly.
+func hdfsError(op, p string, e error) error {
+	if os.IsPermission(e) {
+		return fmt.Errorf("%s hdfs file %s failed: permission denied", op, p)
+	}
+	return fmt.Errorf("%s hdfs file %s failed: %v", op, p, e)
+}
+
+// hdfsStorage stores the models in the HDFS files
+// hdfs://namenode:port/path/to/model.
+type hdfsStorage struct{}
+
+func (hdfsStorage) Put(ctx context.Context, p string, session *pb.Session, fn func(io.Writer) error) error {
+	addr, hp := splitHDFSPath(p)
+	return writeHDFS(addr, hp, session, fn)
+}
+
+func (hdfsStorage) Get(ctx context.Context, p string, session *pb.Session, fn func(io.Reader) error) error {
+	addr, hp := splitHDFSPath(p)
+	return readHDFS(addr, hp, session, fn)
+}
+
+func (hdfsStorage) Stat(ctx context.Context, p string, session *pb.Session) (int64, error) {
+	addr, hp := splitHDFSPath(p)
+	return statHDFS(addr, hp, session)
+}
+
+func (hdfsStorage) Delete(ctx context.Context, p string, session *pb.Session) error {
+	addr, hp := splitHDFSPath(p)
+	return deleteHDFS(addr, hp, session)
+}
+
+// writeHDFS writes what fn writes to the HDFS file p, creating its
+// parent directory if it doesn't exist.
+func writeHDFS(addr, p string, session *pb.Session, fn func(io.Writer) error) (e error) {
+	if p == "" || p == "/" {
+		return fmt.Errorf("hdfs modelURI should be hdfs://namenode:port/path/to/model or hdfs:///path/to/model")
+	}
+	client, e := newHDFSClient(addr, session)
+	if e != nil {
+		return fmt.Errorf("cannot connect to HDFS: %v", e)
+	}
+	defer client.Close()
+
+	if e := client.MkdirAll(path.Dir(p), 0755); e != nil {
+		return hdfsError("create the directory of", p, e)
+	}
+	// HDFS files cannot be overwritten in place.
+	if e := client.Remove(p); e != nil && !os.IsNotExist(e) {
+		return hdfsError("overwrite", p, e)
+	}
+	w, e := client.Create(p)
+	if e != nil {
+		return hdfsError("create", p, e)
+	}
+	defer func() {
+		w.Close()
+		if e != nil {
+			client.Remove(p)
+		}
+	}()
+	if e = fn(w); e != nil {
+		return hdfsError("write", p, e)
+	}
+	if e = w.Close(); e != nil {
+		return hdfsError("close", p, e)
+	}
+	return nil
+}
+
+// readHDFS calls fn with the content of the HDFS file p.
+func readHDFS(addr, p string, session *pb.Session, fn func(io.Reader) error) error {
+	client, e := newHDFSClient(addr, session)
+	if e != nil {
+		return fmt.Errorf("cannot connect to HDFS: %v", e)
+	}
+	defer client.Close()
+	r, e := client.Open(p)
+	if e != nil {
+		return hdfsError("open", p, e)
+	}
+	defer r.Close()
+	return fn(r)
+}
+
+// statHDFS returns the size of the HDFS file p.
+func statHDFS(addr, p string, session *pb.Session) (int64, error) {
+	client, e := newHDFSClient(addr, session)
+	if e != nil {
+		return 0, fmt.Errorf("cannot connect to HDFS: %v", e)
+	}
+	defer client.Close()
+	fi, e := client.Stat(p)
+	if e != nil {
+		if os.IsNotExist(e) {
+			return 0, ErrModelNotFound
+		}
+		return 0, hdfsError("stat", p, e)
+	}
+	return fi.Size(), nil
+}
+
+// deleteHDFS removes the HDFS file p.
+func deleteHDFS(addr, p string, session *pb.Session) error {
+	client, e := newHDFSClient(addr, session)
+	if e != nil {
+		return fmt.Errorf("cannot connect to HDFS: %v", e)
+	}
+	defer client.Close()
+	if e := client.Remove(p); e != nil {
+		if os.IsNotExist(e) {
+			return ErrModelNotFound
+		}
+		return hdfsError("delete", p, e)
+	}
+	return nil
+}
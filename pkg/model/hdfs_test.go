@@ -0,0 +1,63 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+)
+
+func TestHDFSKerberos(t *testing.T) {
+	a := assert.New(t)
+	dir, e := ioutil.TempDir("", "sqlflow_krb5")
+	a.NoError(e)
+	defer os.RemoveAll(dir)
+	conf := filepath.Join(dir, "krb5.conf")
+	a.NoError(ioutil.WriteFile(conf, []byte("[libdefaults]\n  default_realm = EXAMPLE.COM\n"), 0644))
+	for k, v := range map[string]string{
+		"KRB5_CONFIG":                             conf,
+		"SQLFLOW_HDFS_KERBEROS_PRINCIPAL":         "sqlflow@EXAMPLE.COM",
+		"SQLFLOW_HDFS_KERBEROS_KEYTAB":            filepath.Join(dir, "sqlflow.keytab"),
+		"SQLFLOW_HDFS_KERBEROS_CCACHE":            "",
+		"SQLFLOW_HDFS_KERBEROS_SERVICE_PRINCIPAL": "",
+	} {
+		defer os.Setenv(k, os.Getenv(k))
+		os.Setenv(k, v)
+	}
+
+	// Load passes a nil session, which means the environment variables.
+	k := hdfsKerberosOf(nil)
+	a.Equal("sqlflow@EXAMPLE.COM", k.principal)
+	_, e = k.newKerberosClient()
+	a.Error(e)
+	a.Contains(e.Error(), "load Kerberos keytab")
+
+	// No keytab or ticket cache means HDFS doesn't require Kerberos.
+	cl, e := hdfsKerberosOf(&pb.Session{}).newKerberosClient()
+	a.NoError(e)
+	a.Nil(cl)
+
+	_, e = hdfsKerberosOf(&pb.Session{HdfsKerberosKeytab: k.keytab}).newKerberosClient()
+	a.Error(e)
+	a.Contains(e.Error(), "principal")
+
+	_, e = hdfsKerberosOf(&pb.Session{HdfsKerberosCcache: filepath.Join(dir, "krb5cc")}).newKerberosClient()
+	a.Error(e)
+	a.Contains(e.Error(), "ticket cache")
+}
@@ -0,0 +1,109 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// kmsScheme prefixes the model encryption keys managed by a KeyManager,
+// like kms://name/key-id, where name is the registered KeyManager and
+// key-id is the master key in it.
+const kmsScheme = "kms://"
+
+// KeyManager is a key management service (KMS) protecting the model
+// encryption keys. If the encryption key is kms://name/key-id, Save
+// encrypts the tarball with a random data key, and saves the data key
+// wrapped by the master key key-id in the model meta. Load unwraps the
+// data key by the same KeyManager, so the master key never leaves the
+// KMS. A KeyManager connects to the KMS with its own credentials, like
+// the environment variables of the cloud.
+type KeyManager interface {
+	// WrapKey encrypts the data key by the master key keyID.
+	WrapKey(ctx context.Context, keyID string, key []byte) ([]byte, error)
+	// UnwrapKey decrypts the data key wrapped by WrapKey.
+	UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+}
+
+var (
+	keyManagersMu sync.RWMutex
+	keyManagers   = map[string]KeyManager{}
+)
+
+// RegisterKeyManager makes the encryption keys kms://name/key-id managed
+// by km. It replaces the KeyManager registered for name, if any.
+func RegisterKeyManager(name string, km KeyManager) {
+	if name == "" || strings.Contains(name, "/") {
+		panic(fmt.Sprintf("model: invalid key manager name %q", name))
+	}
+	if km == nil {
+		panic(fmt.Sprintf("model: register nil key manager %q", name))
+	}
+	keyManagersMu.Lock()
+	defer keyManagersMu.Unlock()
+	keyManagers[name] = km
+}
+
+// parseKeyURI returns the KeyManager and the master key of keyURI, which
+// is like kms://name/key-id.
+func parseKeyURI(keyURI string) (KeyManager, string, error) {
+	ss := strings.SplitN(strings.TrimPrefix(keyURI, kmsScheme), "/", 2)
+	if !strings.HasPrefix(keyURI, kmsScheme) || len(ss) != 2 || ss[0] == "" || ss[1] == "" {
+		return nil, "", fmt.Errorf("model encryption key from KMS should be like kms://name/key-id, got %s", keyURI)
+	}
+	keyManagersMu.RLock()
+	defer keyManagersMu.RUnlock()
+	km, ok := keyManagers[ss[0]]
+	if !ok {
+		return nil, "", fmt.Errorf("no key manager is registered for %s", keyURI)
+	}
+	return km, ss[1], nil
+}
+
+// newDataKey returns a random data key and the data key wrapped by the
+// master key keyURI.
+func newDataKey(ctx context.Context, keyURI string) ([]byte, []byte, error) {
+	km, keyID, e := parseKeyURI(keyURI)
+	if e != nil {
+		return nil, nil, e
+	}
+	key, e := newRandomBytes(32)
+	if e != nil {
+		return nil, nil, e
+	}
+	wrapped, e := km.WrapKey(ctx, keyID, key)
+	if e != nil {
+		return nil, nil, fmt.Errorf("wrap the model encryption key by %s: %v", keyURI, e)
+	}
+	return key, wrapped, nil
+}
+
+// unwrapDataKey returns the data key wrapped by the master key keyURI.
+func unwrapDataKey(ctx context.Context, keyURI string, wrapped []byte) ([]byte, error) {
+	km, keyID, e := parseKeyURI(keyURI)
+	if e != nil {
+		return nil, e
+	}
+	key, e := km.UnwrapKey(ctx, keyID, wrapped)
+	if e != nil {
+		return nil, fmt.Errorf("unwrap the model encryption key by %s: %v", keyURI, e)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("model encryption key unwrapped by %s should have 32 bytes, got %d", keyURI, len(key))
+	}
+	return key, nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+)
+
+// mockKeyManager wraps the data keys by XOR with its master keys.
+type mockKeyManager struct {
+	keys map[string][]byte
+}
+
+func (km *mockKeyManager) WrapKey(ctx context.Context, keyID string, key []byte) ([]byte, error) {
+	return km.xor(keyID, key)
+}
+
+func (km *mockKeyManager) UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	return km.xor(keyID, wrapped)
+}
+
+func (km *mockKeyManager) xor(keyID string, b []byte) ([]byte, error) {
+	master, ok := km.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("no master key %s", keyID)
+	}
+	out := make([]byte, len(b))
+	for i := range b {
+		out[i] = b[i] ^ master[i%len(master)]
+	}
+	return out, nil
+}
+
+func TestSaveAndLoadWithKMS(t *testing.T) {
+	a := assert.New(t)
+	km := &mockKeyManager{keys: map[string][]byte{"my-key": []byte("master")}}
+	RegisterKeyManager("mock", km)
+	cwd := mockWorkDir(t)
+	defer os.RemoveAll(cwd)
+	modelDir, e := ioutil.TempDir("", "sqlflow_model_dir")
+	a.NoError(e)
+	defer os.RemoveAll(modelDir)
+
+	session := &pb.Session{ModelEncryptionKey: "kms://mock/my-key"}
+	modelURI := "file://" + filepath.Join(modelDir, "my_dnn_model")
+	a.NoError(New(cwd, testTrainSelect).Save(modelURI, nil, session))
+	f, e := os.Open(filepath.Join(modelDir, "my_dnn_model.tar.gz"))
+	a.NoError(e)
+	a.False(isGzip(f))
+	f.Close()
+	_, e = os.Stat(filepath.Join(cwd, "save.json"))
+	a.True(os.IsNotExist(e))
+
+	m, e := LoadMeta(modelURI, nil)
+	a.NoError(e)
+	a.Equal(encryptionAES256GCM, m.Encryption)
+	m, e = Load(modelURI, "", nil)
+	a.NoError(e)
+	a.Equal(encryptionAES256GCM, m.Encryption)
+	a.Equal("kms://mock/my-key", m.KeyURI)
+	a.Equal(32, len(m.WrappedKey))
+
+	// Load unwraps the data key without the key in the session.
+	dst, e := ioutil.TempDir("", "sqlflow_model_dst")
+	a.NoError(e)
+	defer os.RemoveAll(dst)
+	_, e = Load(modelURI, dst, nil)
+	a.NoError(e)
+	b, e := ioutil.ReadFile(filepath.Join(dst, "my_dnn_model", "saved_model.pb"))
+	a.NoError(e)
+	a.Equal("graph", string(b))
+	a.NoError(Verify(modelURI, nil))
+	sessionDst, e := ioutil.TempDir("", "sqlflow_model_dst")
+	a.NoError(e)
+	defer os.RemoveAll(sessionDst)
+	_, e = LoadWithSession(context.Background(), modelURI, sessionDst, session, nil)
+	a.NoError(e)
+	b, e = ioutil.ReadFile(filepath.Join(sessionDst, "my_dnn_model", "saved_model.pb"))
+	a.NoError(e)
+	a.Equal("graph", string(b))
+	a.NoError(Verify(modelURI, session))
+
+	// A different master key unwraps a wrong data key.
+	km.keys["my-key"] = []byte("other")
+	e = Verify(modelURI, nil)
+	a.Error(e)
+	a.Contains(e.Error(), ErrDecryption.Error())
+	delete(km.keys, "my-key")
+	a.Error(Verify(modelURI, nil))
+
+	a.Error(New(cwd, testTrainSelect).Save(modelURI, nil, &pb.Session{ModelEncryptionKey: "kms://unknown/my-key"}))
+	a.Error(New(cwd, testTrainSelect).Save(modelURI, nil, &pb.Session{ModelEncryptionKey: "kms://mock"}))
+	_, e = New(cwd, testTrainSelect).SaveWithOptions(context.Background(), modelURI, nil, session, &Options{Dedup: true})
+	a.Error(e)
+	a.Panics(func() { RegisterKeyManager("", km) })
+}
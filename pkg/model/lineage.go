@@ -0,0 +1,179 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"time"
+
+	"sqlflow.org/sqlflow/pkg/database"
+)
+
+// lineageTable records where the models and the result tables come
+// from. Each lineage has a row for each source table, so the models
+// trained on a table are found by the source_table column.
+const lineageTable = "sqlflow.lineage"
+
+// Lineage describes a TRAIN, PREDICT, EVALUATE or EXPLAIN statement run
+// with a model.
+type Lineage struct {
+	Model string // Model is the model trained or used, like "my_db.my_model".
+	// Action is "train", "predict", "evaluate" or "explain".
+	Action string
+	// StatementHash is the SHA-256 of the statement by StatementHash.
+	StatementHash string
+	// SourceTables are the sorted tables the SELECT of the statement
+	// reads, like ["iris.train"].
+	SourceTables []string
+	// SourceSelect is the SELECT of the statement, whose WHERE clause
+	// selects the partitions of the source tables, if any.
+	SourceSelect string
+	// ParentModel is the model the training warm started from, if any.
+	ParentModel string
+	// OutputTable is the table the statement writes, like the result
+	// table of PREDICT, if any.
+	OutputTable string
+	CreatedAt   time.Time
+}
+
+// StatementHash returns the hex-encoded SHA-256 of the statement sql.
+func StatementHash(sql string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(sql)))
+}
+
+// createLineageTable creates the lineage table if it doesn't exist.
+// created_at is in Unix seconds.
+func createLineageTable(db *database.DB) error {
+	if db.DriverName != "mysql" {
+		return fmt.Errorf("model lineage in %s is not supported now", db.DriverName)
+	}
+	stmts := []string{
+		fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s", modelZooDB),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+model VARCHAR(255) NOT NULL,
+action VARCHAR(16) NOT NULL,
+statement_hash CHAR(64) NOT NULL,
+source_table VARCHAR(255) NOT NULL,
+source_select TEXT NOT NULL,
+parent_model VARCHAR(255) NOT NULL,
+output_table VARCHAR(255) NOT NULL,
+created_at BIGINT NOT NULL,
+INDEX (model),
+INDEX (source_table))`, lineageTable),
+	}
+	for _, stmt := range stmts {
+		if _, e := db.Exec(stmt); e != nil {
+			return fmt.Errorf("exec:[%s] failed: %v", stmt, e)
+		}
+	}
+	return nil
+}
+
+// RecordLineage saves l to the lineage table in db. A zero l.CreatedAt
+// means now.
+func RecordLineage(l *Lineage, db *database.DB) (e error) {
+	if e := createLineageTable(db); e != nil {
+		return e
+	}
+	createdAt := l.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	tables := l.SourceTables
+	if len(tables) == 0 {
+		tables = []string{""}
+	}
+	tx, e := db.Begin()
+	if e != nil {
+		return e
+	}
+	defer func() {
+		if e != nil {
+			tx.Rollback()
+		}
+	}()
+	stmt := fmt.Sprintf(`INSERT INTO %s (model, action, statement_hash, source_table, source_select, parent_model, output_table, created_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)`, lineageTable)
+	for _, t := range tables {
+		if _, e = tx.Exec(stmt, l.Model, l.Action, l.StatementHash, t, l.SourceSelect, l.ParentModel, l.OutputTable, createdAt.Unix()); e != nil {
+			return fmt.Errorf("record the lineage of model %s failed: %v", l.Model, e)
+		}
+	}
+	return tx.Commit()
+}
+
+// LineageOf returns the lineage of model in db, the earliest first.
+func LineageOf(model string, db *database.DB) ([]Lineage, error) {
+	if e := createLineageTable(db); e != nil {
+		return nil, e
+	}
+	stmt := fmt.Sprintf(`SELECT action, statement_hash, source_table, source_select, parent_model, output_table, created_at
+FROM %s WHERE model = ? ORDER BY created_at, action, statement_hash, output_table, source_table`, lineageTable)
+	rows, e := db.Query(stmt, model)
+	if e != nil {
+		return nil, fmt.Errorf("list the lineage of model %s failed: %v", model, e)
+	}
+	defer rows.Close()
+	lineages := []Lineage{}
+	for rows.Next() {
+		l := Lineage{Model: model}
+		var table string
+		var createdAt int64
+		if e := rows.Scan(&l.Action, &l.StatementHash, &table, &l.SourceSelect, &l.ParentModel, &l.OutputTable, &createdAt); e != nil {
+			return nil, e
+		}
+		l.CreatedAt = time.Unix(createdAt, 0)
+		// The rows of the source tables of a lineage are adjacent.
+		if n := len(lineages); n > 0 && sameLineage(&lineages[n-1], &l) {
+			lineages[n-1].SourceTables = append(lineages[n-1].SourceTables, table)
+			continue
+		}
+		if table != "" {
+			l.SourceTables = []string{table}
+		}
+		lineages = append(lineages, l)
+	}
+	return lineages, rows.Err()
+}
+
+func sameLineage(l1, l2 *Lineage) bool {
+	return l1.Action == l2.Action && l1.StatementHash == l2.StatementHash && l1.OutputTable == l2.OutputTable &&
+		l1.ParentModel == l2.ParentModel && l1.CreatedAt.Equal(l2.CreatedAt)
+}
+
+// ModelsTrainedOn returns the sorted models in db trained on the source
+// table, like "iris.train".
+func ModelsTrainedOn(table string, db *database.DB) ([]string, error) {
+	if e := createLineageTable(db); e != nil {
+		return nil, e
+	}
+	stmt := fmt.Sprintf(`SELECT DISTINCT model FROM %s WHERE action = 'train' AND source_table = ?`, lineageTable)
+	rows, e := db.Query(stmt, table)
+	if e != nil {
+		return nil, fmt.Errorf("list the models trained on %s failed: %v", table, e)
+	}
+	defer rows.Close()
+	models := []string{}
+	for rows.Next() {
+		var m string
+		if e := rows.Scan(&m); e != nil {
+			return nil, e
+		}
+		models = append(models, m)
+	}
+	sort.Strings(models)
+	return models, rows.Err()
+}
@@ -0,0 +1,73 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"sqlflow.org/sqlflow/pkg/database"
+)
+
+func TestStatementHash(t *testing.T) {
+	a := assert.New(t)
+	h := StatementHash("SELECT * FROM iris.train TO TRAIN DNNClassifier INTO m;")
+	a.Len(h, 64)
+	a.Equal(h, StatementHash("SELECT * FROM iris.train TO TRAIN DNNClassifier INTO m;"))
+	a.NotEqual(h, StatementHash("SELECT * FROM iris.test TO TRAIN DNNClassifier INTO m;"))
+}
+
+func TestLineageDB(t *testing.T) {
+	a := assert.New(t)
+	db := database.GetTestingDBSingleton()
+	if db.DriverName != "mysql" {
+		t.Skip("Skip as SQLFLOW_TEST_DB is not MySQL")
+	}
+	name := "sqlflow_models.my_lineage_model"
+	db.Exec(fmt.Sprintf("DELETE FROM %s WHERE model = ?", lineageTable), name)
+
+	train := &Lineage{
+		Model:         name,
+		Action:        "train",
+		StatementHash: StatementHash("train"),
+		SourceTables:  []string{"iris.train", "iris.weights"},
+		SourceSelect:  "SELECT * FROM iris.train JOIN iris.weights",
+		ParentModel:   "sqlflow_models.my_parent_model",
+		CreatedAt:     time.Unix(1000, 0),
+	}
+	predict := &Lineage{
+		Model:         name,
+		Action:        "predict",
+		StatementHash: StatementHash("predict"),
+		SourceTables:  []string{"iris.test"},
+		SourceSelect:  "SELECT * FROM iris.test",
+		OutputTable:   "iris.predict",
+		CreatedAt:     time.Unix(2000, 0),
+	}
+	a.NoError(RecordLineage(train, db))
+	a.NoError(RecordLineage(predict, db))
+
+	lineages, e := LineageOf(name, db)
+	a.NoError(e)
+	a.Equal([]Lineage{*train, *predict}, lineages)
+
+	models, e := ModelsTrainedOn("iris.weights", db)
+	a.NoError(e)
+	a.Contains(models, name)
+	models, e = ModelsTrainedOn("iris.test", db)
+	a.NoError(e)
+	a.NotContains(models, name)
+}
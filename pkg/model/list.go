@@ -0,0 +1,135 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"time"
+
+	"sqlflow.org/sqlflow/pkg/database"
+	"sqlflow.org/sqlflow/pkg/sqlfs"
+)
+
+// Info describes a model saved in a sqlfs table.
+type Info struct {
+	Name        string // Name is the sqlfs table, like "sqlflow_models.my_model".
+	TrainSelect string
+	Size        int64 // Size is the number of bytes stored in the table.
+	// Estimator is from the model metadata, "" for the models saved by
+	// older versions.
+	Estimator string
+	// Model and Version are the name and the version of a model saved by
+	// SaveVersion, like "my_db.my_model" and 2 of the table
+	// "my_db.my_model_v2". Model is Name and Version is 0 otherwise.
+	Model     string
+	Version   int64
+	CreatedAt time.Time // CreatedAt is when the version was saved, zero if Version is 0.
+}
+
+// sqlfsTablesQuery lists the tables with the sqlfs schema (id, block).
+const sqlfsTablesQuery = `SELECT CONCAT(table_schema, '.', table_name) FROM information_schema.columns
+WHERE column_name IN ('id', 'block')
+AND table_schema NOT IN ('information_schema', 'mysql', 'performance_schema', 'sys')
+GROUP BY table_schema, table_name HAVING COUNT(*) = 2`
+
+// List returns the models saved in db. It reads only the gob-encoded
+// header of each model, and skips sqlfs tables that don't hold a model.
+func List(db *database.DB) ([]Info, error) {
+	if db.DriverName != "mysql" {
+		return nil, fmt.Errorf("listing models in %s is not supported now", db.DriverName)
+	}
+	rows, e := db.Query(sqlfsTablesQuery)
+	if e != nil {
+		return nil, fmt.Errorf("list sqlfs tables failed: %v", e)
+	}
+	defer rows.Close()
+	tables := []string{}
+	for rows.Next() {
+		var table string
+		if e := rows.Scan(&table); e != nil {
+			return nil, e
+		}
+		tables = append(tables, table)
+	}
+	if e := rows.Err(); e != nil {
+		return nil, e
+	}
+
+	versions, e := listAllVersions(db)
+	if e != nil {
+		return nil, e
+	}
+	models := []Info{}
+	for _, table := range tables {
+		if strings.Contains(table, tmpTableSuffix) {
+			continue // being written by writeDB
+		}
+		m, e := loadDBMeta(db, table)
+		if e != nil {
+			continue // not a model
+		}
+		size, e := sqlfs.Size(db.DB, table)
+		if e != nil {
+			return nil, e
+		}
+		info := Info{Name: table, TrainSelect: m.TrainSelect, Size: size, Model: table}
+		if m.Metadata != nil {
+			info.Estimator = m.Metadata.Estimator
+		}
+		if v, ok := versions[table]; ok {
+			info.Model, info.Version, info.CreatedAt = v.Name, v.Version, v.CreatedAt
+		}
+		models = append(models, info)
+	}
+	return models, nil
+}
+
+// listAllVersions returns the versions of all the models saved by
+// SaveVersion by their sqlfs tables.
+func listAllVersions(db *database.DB) (map[string]Version, error) {
+	// The table doesn't exist if no model has been saved by versions.
+	if e := createModelZooTable(db); e != nil {
+		return nil, e
+	}
+	stmt := fmt.Sprintf("SELECT name, version, created_at FROM %s WHERE created_at IS NOT NULL", modelZooTable)
+	rows, e := db.Query(stmt)
+	if e != nil {
+		return nil, fmt.Errorf("list versions of models failed: %v", e)
+	}
+	defer rows.Close()
+	versions := map[string]Version{}
+	for rows.Next() {
+		var v Version
+		var createdAt int64
+		if e := rows.Scan(&v.Name, &v.Version, &createdAt); e != nil {
+			return nil, e
+		}
+		v.CreatedAt = time.Unix(createdAt, 0)
+		versions[versionTable(v.Name, v.Version)] = v
+	}
+	return versions, rows.Err()
+}
+
+// loadDBMeta decodes the model meta of the model in table
+// without reading the following tarball.
+func loadDBMeta(db *database.DB, table string) (*Model, error) {
+	sqlf, e := sqlfs.OpenLazy(db.DB, table)
+	if e != nil {
+		return nil, fmt.Errorf("cannot open sqlfs file %s: %v", table, e)
+	}
+	defer sqlf.Close()
+	return readMeta(bufio.NewReader(sqlf))
+}
@@ -0,0 +1,131 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"bufio"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// The model meta written before the tarball is a header line like
+// "SQLFLOW-META 1\n", followed by the JSON-encoded Model in one line.
+// Models saved by older versions of SQLFlow have a gob-encoded Model
+// instead, which never starts with metaMagic.
+const metaMagic = "SQLFLOW-META"
+
+// metaVersion is the version of the meta format written by writeMeta.
+const metaVersion = 1
+
+// writeMeta writes the header line and the JSON-encoded m to w.
+func writeMeta(w io.Writer, m *Model) error {
+	b, e := json.Marshal(m)
+	if e != nil {
+		return fmt.Errorf("json-encoding model failed: %v", e)
+	}
+	// json.Marshal escapes newlines in strings, so b is a single line.
+	_, e = fmt.Fprintf(w, "%s %d\n%s\n", metaMagic, metaVersion, b)
+	return e
+}
+
+// readMeta decodes the meta written by writeMeta, or the gob-encoded one
+// of older models, from br. It reads no byte beyond the meta, so the
+// rest of br is the tarball.
+//
+// The meta ends at the newline after the JSON-encoded Model. A gob
+// message is prefixed by its length, so gob.Decoder knows where it
+// ends. In both cases, br reads ahead at most its buffer size.
+func readMeta(br *bufio.Reader) (*Model, error) {
+	m := &Model{}
+	magic, e := br.Peek(len(metaMagic) + 1)
+	if e != nil || string(magic) != metaMagic+" " {
+		// gob.Decoder reads exactly one message from an io.ByteReader.
+		if e := gob.NewDecoder(br).Decode(m); e != nil {
+			return nil, fmt.Errorf("gob-decoding train select failed: %v", e)
+		}
+		return m, nil
+	}
+	header, e := br.ReadString('\n')
+	if e != nil {
+		return nil, fmt.Errorf("read model meta header failed: %v", e)
+	}
+	version, e := strconv.Atoi(strings.TrimSpace(header[len(metaMagic):]))
+	if e != nil || version != metaVersion {
+		return nil, fmt.Errorf("unsupported model meta header %q, this SQLFlow supports version %d", strings.TrimSpace(header), metaVersion)
+	}
+	line, e := br.ReadBytes('\n')
+	if e != nil {
+		return nil, fmt.Errorf("read model meta failed: %v", e)
+	}
+	if e := json.Unmarshal(line, m); e != nil {
+		return nil, fmt.Errorf("json-decoding model meta failed: %v", e)
+	}
+	return m, nil
+}
+
+// The file backend saves the model meta as the file save.json in the
+// tarball, and older versions saved save.gob.
+func jsonMetaFile(save string) string { return save + ".json" }
+func gobMetaFile(save string) string  { return save + ".gob" }
+
+// writeMetaFile writes the JSON-encoded m into the file p.
+func writeMetaFile(p string, m *Model) error {
+	b, e := json.Marshal(m)
+	if e != nil {
+		return fmt.Errorf("model.save: json-encoding model failed: %v", e)
+	}
+	if e := ioutil.WriteFile(p, b, 0644); e != nil {
+		return fmt.Errorf("create meta file :%s, error: %v", p, e)
+	}
+	return nil
+}
+
+// readMetaFile decodes dir/save.json, or dir/save.gob if the former
+// doesn't exist.
+func readMetaFile(dir, save string) (*Model, error) {
+	m := &Model{}
+	b, e := ioutil.ReadFile(filepath.Join(dir, jsonMetaFile(save)))
+	if os.IsNotExist(e) {
+		if e := readGob(filepath.Join(dir, gobMetaFile(save)), m); e != nil {
+			return nil, e
+		}
+		return m, nil
+	}
+	if e != nil {
+		return nil, fmt.Errorf("model.load: read meta file failed: %v", e)
+	}
+	if e := json.Unmarshal(b, m); e != nil {
+		return nil, fmt.Errorf("model.load: json-decoding model failed: %v", e)
+	}
+	return m, nil
+}
+
+func readGob(filePath string, object interface{}) error {
+	file, e := os.Open(filePath)
+	if e != nil {
+		return fmt.Errorf("model.load: gob-decoding model failed: %v", e)
+	}
+	defer file.Close()
+	if e := gob.NewDecoder(file).Decode(object); e != nil {
+		return fmt.Errorf("model.load: gob-decoding model failed: %v", e)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/gob"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteAndReadMeta(t *testing.T) {
+	a := assert.New(t)
+	m := &Model{TrainSelect: testTrainSelect + "\n-- multi-line"}
+
+	var buf bytes.Buffer
+	a.NoError(writeMeta(&buf, m))
+	a.True(strings.HasPrefix(buf.String(), "SQLFLOW-META 1\n{\"train_select\":"))
+	buf.WriteString("tarball")
+	br := bufio.NewReader(&buf)
+	decoded, e := readMeta(br)
+	a.NoError(e)
+	a.Equal(m.TrainSelect, decoded.TrainSelect)
+	rest, e := ioutil.ReadAll(br)
+	a.NoError(e)
+	a.Equal("tarball", string(rest))
+
+	// The gob-encoded meta of older models.
+	buf.Reset()
+	a.NoError(gob.NewEncoder(&buf).Encode(m))
+	buf.WriteString("tarball")
+	br = bufio.NewReader(&buf)
+	decoded, e = readMeta(br)
+	a.NoError(e)
+	a.Equal(m.TrainSelect, decoded.TrainSelect)
+	rest, e = ioutil.ReadAll(br)
+	a.NoError(e)
+	a.Equal("tarball", string(rest))
+
+	_, e = readMeta(bufio.NewReader(strings.NewReader("SQLFLOW-META 2\n{}\n")))
+	a.Error(e)
+}
+
+func TestLoadGobFile(t *testing.T) {
+	a := assert.New(t)
+	cwd := mockWorkDir(t)
+	defer os.RemoveAll(cwd)
+	modelDir, e := ioutil.TempDir("", "sqlflow_model_dir")
+	a.NoError(e)
+	defer os.RemoveAll(modelDir)
+
+	// Save the model like older versions.
+	f, e := os.Create(filepath.Join(cwd, "my_dnn_model.gob"))
+	a.NoError(e)
+	a.NoError(gob.NewEncoder(f).Encode(New(cwd, testTrainSelect)))
+	a.NoError(f.Close())
+	f, e = os.Create(filepath.Join(modelDir, "my_dnn_model.tar.gz"))
+	a.NoError(e)
+	_, e = tarGzDir(context.Background(), cwd, f, -1, nil)
+	a.NoError(e)
+	a.NoError(f.Close())
+
+	modelURI := "file://" + filepath.Join(modelDir, "my_dnn_model")
+	m, e := Load(modelURI, "", nil)
+	a.NoError(e)
+	a.Equal(testTrainSelect, m.TrainSelect)
+	dst, e := ioutil.TempDir("", "sqlflow_model_dst")
+	a.NoError(e)
+	defer os.RemoveAll(dst)
+	m, e = Load(modelURI, dst, nil)
+	a.NoError(e)
+	a.Equal(testTrainSelect, m.TrainSelect)
+
+	// Saving again replaces the gob file with the JSON one.
+	a.NoError(New(cwd, m.TrainSelect).Save(modelURI, nil, nil))
+	_, e = os.Stat(filepath.Join(cwd, "my_dnn_model.gob"))
+	a.True(os.IsNotExist(e))
+	b, e := ioutil.ReadFile(filepath.Join(cwd, "my_dnn_model.json"))
+	a.NoError(e)
+	a.Contains(string(b), `"train_select"`)
+}
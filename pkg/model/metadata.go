@@ -0,0 +1,286 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"sqlflow.org/sqlflow/pkg/database"
+	"sqlflow.org/sqlflow/pkg/ir"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+)
+
+// SQLFlowVersion is the version of SQLFlow recorded in the metadata of the
+// saved models. It's set at link time by
+//
+//	go build -ldflags "-X sqlflow.org/sqlflow/pkg/model.SQLFlowVersion=v0.1.0"
+var SQLFlowVersion = "unknown"
+
+// Metadata describes how a model was trained, so tools can inspect a
+// saved model without running SQLFlow or untarring the model. The feature
+// and label columns are in Model.Schema.
+type Metadata struct {
+	Estimator string `json:"estimator"`
+	// Framework is "xgboost", "pytorch", "lightgbm", "catboost",
+	// "sklearn" or "tensorflow".
+	Framework string `json:"framework"`
+	// FrameworkVersion is the version of Framework that trained the
+	// model. SQLFlow doesn't know it before the training runs, so it's
+	// set by the caller of Save, and Save keeps it if it's already in
+	// Model.Metadata.
+	FrameworkVersion string `json:"framework_version,omitempty"`
+	// Attributes is the WITH clause, like {"train.epoch": 10}. The
+	// attributes that cannot be JSON-encoded are dropped.
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	// FeatureColumns is the COLUMN clause by group, like
+	// {"feature_columns": ["NumericColumn(sepal_length)"]}.
+	FeatureColumns map[string][]string `json:"feature_columns,omitempty"`
+	Label          string              `json:"label,omitempty"`
+	// Driver is the database driver of the training data, like "mysql".
+	Driver         string `json:"driver,omitempty"`
+	SQLFlowVersion string `json:"sqlflow_version"`
+	// CrossValidation is the result of the k-fold cross-validation
+	// before the training, if any.
+	CrossValidation *CrossValidation `json:"cross_validation,omitempty"`
+	// Tuning is the trials of the hyperparameter tuning, if any. The
+	// Attributes are of the best trial.
+	Tuning *Tuning `json:"tuning,omitempty"`
+	// DataSnapshot is the snapshot of the training data set by WITH
+	// train.data_snapshot, if any.
+	DataSnapshot *DataSnapshot `json:"data_snapshot,omitempty"`
+}
+
+// crossValidationFile is written to the working directory by the
+// training programs that run the cross-validation, see
+// python/sqlflow_submitter/cross_validation.py.
+const crossValidationFile = "cross_validation.json"
+
+// CrossValidation is the result of the k-fold cross-validation set by
+// WITH validation.kfold=k.
+type CrossValidation struct {
+	KFold int `json:"kfold"`
+	// Metrics maps the names of the metrics to their mean and standard
+	// deviation over the folds.
+	Metrics map[string]MetricSummary `json:"metrics"`
+}
+
+// MetricSummary is the mean and the standard deviation of a metric.
+type MetricSummary struct {
+	Mean float64 `json:"mean"`
+	Std  float64 `json:"std"`
+}
+
+// readCrossValidation returns the result of the cross-validation in
+// cwd, or nil if there is none.
+func readCrossValidation(cwd string) (*CrossValidation, error) {
+	b, e := ioutil.ReadFile(filepath.Join(cwd, crossValidationFile))
+	if os.IsNotExist(e) {
+		return nil, nil
+	}
+	if e != nil {
+		return nil, e
+	}
+	cv := &CrossValidation{}
+	if e := json.Unmarshal(b, cv); e != nil {
+		return nil, fmt.Errorf("invalid %s: %v", crossValidationFile, e)
+	}
+	return cv, nil
+}
+
+// tuningFile is written to the working directory by the training
+// programs that tune the hyperparameters, see
+// python/sqlflow_submitter/tuning.py.
+const tuningFile = "tuning.json"
+
+// Tuning is the result of the hyperparameter tuning set by WITH
+// tuning.algorithm.
+type Tuning struct {
+	Algorithm string  `json:"algorithm"`
+	Metric    string  `json:"metric"`
+	Trials    []Trial `json:"trials"`
+	// Best is the index of the best trial in Trials.
+	Best int `json:"best"`
+}
+
+// Trial is a trial of the hyperparameter tuning.
+type Trial struct {
+	// Params are the attributes tuned in the trial, like
+	// {"num_leaves": 31}.
+	Params map[string]interface{} `json:"params"`
+	// Score is the metric on the validation data.
+	Score float64 `json:"score"`
+}
+
+// readTuning returns the result of the tuning in cwd, or nil if there is
+// none.
+func readTuning(cwd string) (*Tuning, error) {
+	b, e := ioutil.ReadFile(filepath.Join(cwd, tuningFile))
+	if os.IsNotExist(e) {
+		return nil, nil
+	}
+	if e != nil {
+		return nil, e
+	}
+	t := &Tuning{}
+	if e := json.Unmarshal(b, t); e != nil {
+		return nil, fmt.Errorf("invalid %s: %v", tuningFile, e)
+	}
+	if t.Best < 0 || t.Best >= len(t.Trials) {
+		return nil, fmt.Errorf("invalid %s: no best trial %d in %d trials", tuningFile, t.Best, len(t.Trials))
+	}
+	return t, nil
+}
+
+// dataSnapshotFile is written to the working directory by the training
+// programs that materialize the training data, see
+// python/sqlflow_submitter/snapshot.py.
+const dataSnapshotFile = "data_snapshot.json"
+
+// DataSnapshot is the snapshot of the training data, which is the files
+// of the training and the validation data under URI.
+type DataSnapshot struct {
+	URI string `json:"uri"`
+	// Format is "tfrecord" or "parquet".
+	Format  string   `json:"format"`
+	Columns []string `json:"columns"`
+	// Rows maps the names of the files under URI to their numbers of
+	// rows, like {"train.tfrecord": 110, "validation.tfrecord": 40}.
+	Rows map[string]int64 `json:"rows"`
+}
+
+// readDataSnapshot returns the snapshot of the training data in cwd, or
+// nil if there is none.
+func readDataSnapshot(cwd string) (*DataSnapshot, error) {
+	b, e := ioutil.ReadFile(filepath.Join(cwd, dataSnapshotFile))
+	if os.IsNotExist(e) {
+		return nil, nil
+	}
+	if e != nil {
+		return nil, e
+	}
+	ds := &DataSnapshot{}
+	if e := json.Unmarshal(b, ds); e != nil {
+		return nil, fmt.Errorf("invalid %s: %v", dataSnapshotFile, e)
+	}
+	return ds, nil
+}
+
+// FrameworkOf returns the framework of the estimator, which is the same
+// as Metadata.Framework of the models trained by the estimator.
+func FrameworkOf(estimator string) string {
+	if strings.HasPrefix(strings.ToUpper(estimator), "XGB") {
+		return "xgboost"
+	}
+	if strings.HasPrefix(strings.ToUpper(estimator), "TORCH.") {
+		return "pytorch"
+	}
+	if strings.HasPrefix(strings.ToUpper(estimator), "LIGHTGBM.") {
+		return "lightgbm"
+	}
+	if strings.HasPrefix(strings.ToUpper(estimator), "CATBOOST.") {
+		return "catboost"
+	}
+	if strings.HasPrefix(strings.ToUpper(estimator), "SKLEARN.") {
+		return "sklearn"
+	}
+	switch strings.ToUpper(estimator) {
+	case "SQLFLOW_MODELS.LSTMFORECASTER", "SQLFLOW_MODELS.DEEPARFORECASTER":
+		return "forecast"
+	}
+	return "tensorflow"
+}
+
+// describeColumn returns the type of fc and the names of the fields fc
+// reads, like "CrossColumn(sepal_length, sepal_width)".
+func describeColumn(fc ir.FeatureColumn) string {
+	t := reflect.TypeOf(fc)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	names := []string{}
+	for _, fd := range fc.GetFieldDesc() {
+		if fd != nil {
+			names = append(names, fd.Name)
+		}
+	}
+	return fmt.Sprintf("%s(%s)", t.Name(), strings.Join(names, ", "))
+}
+
+// metadataOf returns the metadata of a model trained by trainStmt on the
+// database in session. It keeps the FrameworkVersion in prev, if any.
+func metadataOf(trainStmt *ir.TrainStmt, session *pb.Session, prev *Metadata) *Metadata {
+	md := &Metadata{
+		Estimator:      trainStmt.Estimator,
+		Framework:      FrameworkOf(trainStmt.Estimator),
+		SQLFlowVersion: SQLFlowVersion,
+	}
+	if prev != nil {
+		md.FrameworkVersion = prev.FrameworkVersion
+	}
+	if len(trainStmt.Attributes) > 0 {
+		md.Attributes = map[string]interface{}{}
+		for k, v := range trainStmt.Attributes {
+			if _, e := json.Marshal(v); e == nil {
+				md.Attributes[k] = v
+			}
+		}
+	}
+	if len(trainStmt.Features) > 0 {
+		md.FeatureColumns = map[string][]string{}
+		groups := []string{}
+		for group := range trainStmt.Features {
+			groups = append(groups, group)
+		}
+		sort.Strings(groups)
+		for _, group := range groups {
+			for _, fc := range trainStmt.Features[group] {
+				md.FeatureColumns[group] = append(md.FeatureColumns[group], describeColumn(fc))
+			}
+		}
+	}
+	if trainStmt.Label != nil {
+		if fds := trainStmt.Label.GetFieldDesc(); len(fds) > 0 && fds[0] != nil {
+			md.Label = fds[0].Name
+		}
+	}
+	if session != nil && session.DbConnStr != "" {
+		// Never record the data source, which has the credentials.
+		if driver, _, e := database.ParseURL(session.DbConnStr); e == nil {
+			md.Driver = driver
+		}
+	}
+	return md
+}
+
+// LoadMetadata returns the metadata of the model saved at modelURI. Like
+// LoadMeta, it reads only the model meta and never the tarball. It
+// returns an error if the model was saved without a TrainStmt or by an
+// older version of SQLFlow.
+func LoadMetadata(modelURI string, session *pb.Session) (*Metadata, error) {
+	m, e := LoadMeta(modelURI, session)
+	if e != nil {
+		return nil, e
+	}
+	if m.Metadata == nil {
+		return nil, fmt.Errorf("model %s has no metadata, it was saved without the train statement or by an older version of SQLFlow", modelURI)
+	}
+	return m.Metadata, nil
+}
@@ -14,29 +14,79 @@
 package model
 
 import (
-	"bytes"
-	"encoding/gob"
+	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
-	"path"
 	"path/filepath"
-	"strings"
+	"time"
 
 	"sqlflow.org/sqlflow/pkg/database"
 	"sqlflow.org/sqlflow/pkg/ir"
+	"sqlflow.org/sqlflow/pkg/log"
+	"sqlflow.org/sqlflow/pkg/metrics"
 
 	pb "sqlflow.org/sqlflow/pkg/proto"
 	"sqlflow.org/sqlflow/pkg/sqlfs"
 )
 
+// tmpTableSuffix is appended to the table of the model, with a random
+// hex, to name the temporary table that writeDB writes the model to.
+const tmpTableSuffix = "_sqlflow_tmp_"
+
 const modelZooDB = "sqlflow"
 const modelZooTable = "sqlflow.trained_models"
 
+// ErrModelNotFound is returned by Delete if there is no model at modelURI.
+var ErrModelNotFound = errors.New("model not found")
+
 // Model represent a trained model, which could be saved to a filesystem or sqlfs.
 type Model struct {
 	workDir     string // We don't expose and gob workDir; instead we tar it.
-	TrainSelect string // TrainSelect is gob-encoded during I/O.
+	TrainSelect string `json:"train_select"`         // TrainSelect is encoded in the model meta during I/O.
+	Encryption  string `json:"encryption,omitempty"` // Encryption is the cipher of the tarball, "" if not encrypted.
+	Nonce       []byte `json:"nonce,omitempty"`      // Nonce is the base nonce of the encrypted tarball.
+	// KeyURI is the master key in the KMS, like kms://name/key-id, that
+	// wraps the data key of the encrypted tarball into WrappedKey. It's
+	// "" if the tarball is encrypted by a raw key.
+	KeyURI     string `json:"key_uri,omitempty"`
+	WrappedKey []byte `json:"wrapped_key,omitempty"`
+	// Schema is the feature and label columns in the TrainStmt passed to
+	// Save, nil for models saved without a TrainStmt or by older versions.
+	Schema *Schema `json:"schema,omitempty"`
+	// Deduplicated is true if the model meta is followed by the manifest
+	// of the chunks saved with Options.Dedup instead of the tarball.
+	Deduplicated bool `json:"deduplicated,omitempty"`
+	// Metadata describes the training, nil for models saved without a
+	// TrainStmt or by older versions.
+	Metadata *Metadata `json:"metadata,omitempty"`
+	// Groups lists the models trained per group by train.group_by, if
+	// the model is the index of them, otherwise nil.
+	Groups *Groups `json:"groups,omitempty"`
+}
+
+// Groups is the models of the groups of a column. The model of the group
+// column=value of the index saved as my_model is saved as
+// my_model/column=value.
+type Groups struct {
+	Column string   `json:"column"`
+	Values []string `json:"values"`
+}
+
+// SaveStats describes the tarball written by SaveWithStats.
+type SaveStats struct {
+	ModelURI       string // ModelURI is where the model was saved.
+	Files          int    // Files is the number of regular files archived.
+	Size           int64  // Size is the uncompressed size of workDir in bytes.
+	CompressedSize int64  // CompressedSize is the size of the tarball in bytes.
+	// Chunks is the number of chunks of the model saved with
+	// Options.Dedup, and NewChunks is how many of them were not stored
+	// before. CompressedSize counts only the new chunks and the
+	// manifest in this case.
+	Chunks    int
+	NewChunks int
 }
 
 // New an empty model.
@@ -48,161 +98,513 @@ func New(cwd, trainSelect string) *Model {
 
 // Save all files in workDir as a tarball to a filesystem or sqlfs.
 func (m *Model) Save(modelURI string, trainStmt *ir.TrainStmt, session *pb.Session) error {
-	if strings.Contains(modelURI, "://") {
-		uriParts := strings.Split(modelURI, "://")
-		if len(uriParts) == 2 {
-			// oss:// or file://
-			if uriParts[0] == "file" {
-				dir, file := path.Split(uriParts[1])
-				return m.saveTar(dir, file)
-			} else if uriParts[0] == "oss" {
-				return fmt.Errorf("save model to oss is not supported now")
+	return m.SaveContext(context.Background(), modelURI, trainStmt, session)
+}
+
+// SaveContext works like Save. It stops archiving and returns ctx.Err()
+// once ctx is done.
+func (m *Model) SaveContext(ctx context.Context, modelURI string, trainStmt *ir.TrainStmt, session *pb.Session) error {
+	_, e := m.SaveWithOptions(ctx, modelURI, trainStmt, session, nil)
+	return e
+}
+
+// SaveWithStats works like Save, and returns the sizes of the saved model.
+func (m *Model) SaveWithStats(modelURI string, trainStmt *ir.TrainStmt, session *pb.Session) (*SaveStats, error) {
+	return m.SaveWithOptions(context.Background(), modelURI, trainStmt, session, nil)
+}
+
+// SaveWithOptions works like SaveContext and SaveWithStats, and tunes the
+// saving with opts. A nil opts means the default options.
+func (m *Model) SaveWithOptions(ctx context.Context, modelURI string, trainStmt *ir.TrainStmt, session *pb.Session, opts *Options) (*SaveStats, error) {
+	opts, e := withEncryptionKey(session, opts)
+	if e != nil {
+		return nil, e
+	}
+	if trainStmt != nil {
+		m.Schema = schemaOf(trainStmt)
+		m.Metadata = metadataOf(trainStmt, session, m.Metadata)
+		if e := writePreprocessing(m.workDir, trainStmt); e != nil {
+			return nil, e
+		}
+		if m.Metadata.CrossValidation, e = readCrossValidation(m.workDir); e != nil {
+			return nil, e
+		}
+		if m.Metadata.Tuning, e = readTuning(m.workDir); e != nil {
+			return nil, e
+		}
+		if m.Metadata.DataSnapshot, e = readDataSnapshot(m.workDir); e != nil {
+			return nil, e
+		}
+		if t := m.Metadata.Tuning; t != nil {
+			if m.Metadata.Attributes == nil {
+				m.Metadata.Attributes = map[string]interface{}{}
+			}
+			for k, v := range t.Trials[t.Best].Params {
+				m.Metadata.Attributes[k] = v
 			}
-		} else {
-			return fmt.Errorf("error modelURI format: %s", modelURI)
 		}
 	}
-	db, err := database.OpenAndConnectDB(session.DbConnStr)
-	if err != nil {
-		return err
+	start := time.Now()
+	opts.event("model.save: start", log.Fields{"uri": modelURI}, nil)
+	stats, e := m.saveURI(ctx, modelURI, session, opts)
+	if e != nil {
+		opts.event("model.save: failed", log.Fields{"uri": modelURI, "duration": time.Since(start)}, e)
+		return nil, e
+	}
+	stats.ModelURI = modelURI
+	metrics.ModelSaveBytes.Observe(float64(stats.Size), storageName(modelURI))
+	metrics.ModelSaveDuration.Observe(time.Since(start).Seconds(), storageName(modelURI))
+	opts.event("model.save: done", log.Fields{
+		"uri":             modelURI,
+		"files":           stats.Files,
+		"size":            stats.Size,
+		"compressed_size": stats.CompressedSize,
+		"duration":        time.Since(start),
+	}, nil)
+	return stats, nil
+}
+
+func (m *Model) saveURI(ctx context.Context, modelURI string, session *pb.Session, opts *Options) (*SaveStats, error) {
+	scheme, p, e := parseModelURI(modelURI)
+	if e != nil {
+		return nil, e
 	}
-	if err := m.saveDB(db, modelURI, session); err != nil {
-		return err
+	if opts.dedup() {
+		return m.saveDedup(ctx, scheme, p, session, opts)
 	}
-	return nil
+	if scheme == "file" {
+		dir, file := filepath.Split(p)
+		if e := mkGroupDir(dir, file); e != nil {
+			return nil, e
+		}
+		return m.saveTar(ctx, dir, file, opts)
+	}
+	if s, ok := storageOf(scheme); ok {
+		return m.saveStorage(ctx, s, p, session, opts)
+	}
+	db, e := database.OpenAndConnectDB(session.DbConnStr)
+	if e != nil {
+		return nil, e
+	}
+	return m.saveDB(ctx, db, p, session, opts)
 }
 
 // Load untar a saved model to a directory on the local filesystem.
 // When dst=="", we do not untar model data, just extract the model meta
 func Load(modelURI, dst string, db *database.DB) (*Model, error) {
-	// FIXME(typhoonzero): unify arguments with save, use session,
-	// so that can pass oss credentials too.
-	if strings.Contains(modelURI, "://") {
-		uriParts := strings.Split(modelURI, "://")
-		if len(uriParts) == 2 {
-			// oss:// or file://
-			if uriParts[0] == "file" {
-				dir, file := path.Split(uriParts[1])
-				return loadTar(dir, dst, file)
-			} else if uriParts[0] == "oss" {
-				return nil, fmt.Errorf("load model from oss is not supported now")
-			}
-		} else {
-			return nil, fmt.Errorf("error modelURI format: %s", modelURI)
+	return LoadContext(context.Background(), modelURI, dst, db)
+}
+
+// LoadContext works like Load. It stops extracting and returns ctx.Err()
+// once ctx is done.
+func LoadContext(ctx context.Context, modelURI, dst string, db *database.DB) (*Model, error) {
+	return LoadWithOptions(ctx, modelURI, dst, db, nil)
+}
+
+// LoadWithOptions works like LoadContext with the options opts, which
+// can be nil. A failed Load leaves nothing extracted in dst. The
+// credentials of the cloud storages come from the environment, use
+// LoadWithSession to pass them like Save does.
+func LoadWithOptions(ctx context.Context, modelURI, dst string, db *database.DB, opts *Options) (*Model, error) {
+	return load(ctx, modelURI, dst, db, nil, opts)
+}
+
+// LoadWithSession works like LoadWithOptions, and connects to the storage
+// with the credentials in session, like Save does. A model in a sqlfs
+// table is loaded from the database session.DbConnStr.
+func LoadWithSession(ctx context.Context, modelURI, dst string, session *pb.Session, opts *Options) (*Model, error) {
+	return load(ctx, modelURI, dst, nil, session, opts)
+}
+
+func load(ctx context.Context, modelURI, dst string, db *database.DB, session *pb.Session, opts *Options) (*Model, error) {
+	start := time.Now()
+	opts.event("model.load: start", log.Fields{"uri": modelURI, "dst": dst}, nil)
+	// The models encrypted by a raw key are decrypted by the key in opts,
+	// session or the environment, like Save encrypts them.
+	var m *Model
+	opts, e := withEncryptionKey(session, opts)
+	if e == nil {
+		m, e = loadURI(ctx, modelURI, dst, db, session, opts)
+	}
+	if e != nil {
+		opts.event("model.load: failed", log.Fields{"uri": modelURI, "dst": dst, "duration": time.Since(start)}, e)
+		return nil, e
+	}
+	opts.event("model.load: done", log.Fields{"uri": modelURI, "dst": dst, "duration": time.Since(start)}, nil)
+	metrics.ModelLoadDuration.Observe(time.Since(start).Seconds(), storageName(modelURI))
+	if dst != "" {
+		if size, e := dirSize(dst, map[string]bool{}); e == nil {
+			metrics.ModelLoadBytes.Observe(float64(size), storageName(modelURI))
 		}
 	}
-	return loadDB(db, modelURI, dst)
+	return m, nil
+}
+
+// storageName returns the scheme of modelURI, or "sqlfs" for the models
+// in tables, as the label of the metrics.
+func storageName(modelURI string) string {
+	if scheme, _, e := parseModelURI(modelURI); e == nil && scheme != "" {
+		return scheme
+	}
+	return "sqlfs"
+}
+
+// loadURI loads the model at modelURI with the credentials in session,
+// or the ones in the environment if session is nil. A model in a sqlfs
+// table is loaded from db, or the database session.DbConnStr if db is
+// nil.
+func loadURI(ctx context.Context, modelURI, dst string, db *database.DB, session *pb.Session, opts *Options) (*Model, error) {
+	scheme, p, e := parseModelURI(modelURI)
+	if e != nil {
+		return nil, e
+	}
+	if scheme == "file" {
+		dir, file := filepath.Split(p)
+		return loadTar(ctx, dir, dst, file, opts)
+	}
+	if s, ok := storageOf(scheme); ok {
+		return loadStorage(ctx, s, p, dst, session, opts)
+	}
+	if db == nil && session != nil {
+		if db, e = database.OpenAndConnectDB(session.DbConnStr); e != nil {
+			return nil, e
+		}
+		defer db.Close()
+	}
+	return loadDB(ctx, db, p, dst, opts)
+}
+
+// LoadMeta returns the model meta saved at modelURI, like Load with
+// dst=="". It reads only the bytes up to the end of the meta and never
+// the following tarball, so it's cheap regardless of the model size. See
+// readMeta for how the end of the meta is found.
+func LoadMeta(modelURI string, session *pb.Session) (*Model, error) {
+	ctx := context.Background()
+	scheme, p, e := parseModelURI(modelURI)
+	if e != nil {
+		return nil, e
+	}
+	if scheme == "file" {
+		// The meta file is the first entry in the tarball.
+		dir, file := filepath.Split(p)
+		return loadTar(ctx, dir, "", file, nil)
+	}
+	if s, ok := storageOf(scheme); ok {
+		return loadStorageMeta(ctx, s, p, session)
+	}
+	db, e := database.OpenAndConnectDB(session.DbConnStr)
+	if e != nil {
+		return nil, e
+	}
+	defer db.Close()
+	return loadDBMeta(db, p)
+}
+
+// Delete removes the model saved at modelURI. It returns ErrModelNotFound
+// if there is no such model.
+func Delete(modelURI string, session *pb.Session) error {
+	scheme, p, e := parseModelURI(modelURI)
+	if e != nil {
+		return e
+	}
+	if scheme == "file" {
+		dir, file := filepath.Split(p)
+		return deleteTar(dir, file)
+	}
+	if s, ok := storageOf(scheme); ok {
+		return s.Delete(context.Background(), p, session)
+	}
+	db, e := database.OpenAndConnectDB(session.DbConnStr)
+	if e != nil {
+		return e
+	}
+	defer db.Close()
+	return deleteDB(db, p)
 }
 
 // saveDB creates a sqlfs table if it doesn't yet exist, and writes the
 // train select statement into the table, followed by the tar-gzipped
 // SQLFlow working directory, which contains the TensorFlow working
 // directory and the trained TensorFlow model.
-func (m *Model) saveDB(db *database.DB, table string, session *pb.Session) (e error) {
-	sqlf, e := sqlfs.Create(db.DB, db.DriverName, table, session)
+func (m *Model) saveDB(ctx context.Context, db *database.DB, table string, session *pb.Session, opts *Options) (stats *SaveStats, e error) {
+	// Retrying rewrites the table from the working directory.
+	e = retry(ctx, opts, func() error {
+		return writeDB(db, table, session, opts, func(w io.Writer) (e error) {
+			stats, e = m.writeTo(ctx, w, opts)
+			return e
+		})
+	})
 	if e != nil {
-		return fmt.Errorf("cannot create sqlfs file %s: %v", table, e)
+		return nil, e
 	}
-	defer sqlf.Close()
+	return stats, nil
+}
 
-	// Use a bytes.Buffer as the gob message container to separate
-	// the message from the following tarball.
-	var buf bytes.Buffer
-	if e := gob.NewEncoder(&buf).Encode(m); e != nil {
-		return fmt.Errorf("model.save: gob-encoding model failed: %v", e)
+// writeDB creates a sqlfs table, and calls fn to write the content. It
+// writes a temporary table and verifies that the table holds everything
+// fn wrote, then renames the temporary table to table by sqlfs.Rename.
+// It removes the temporary table if anything fails, so there is no
+// half-written model, and concurrent writes of the same table don't
+// interleave their chunks, the last renamed one wins.
+func writeDB(db *database.DB, table string, session *pb.Session, opts *Options, fn func(io.Writer) error) (e error) {
+	suffix, e := newRandomBytes(4)
+	if e != nil {
+		return e
 	}
-	if _, e := buf.WriteTo(sqlf); e != nil {
-		return fmt.Errorf("model.save: write the buffer failed: %v", e)
+	tmp := fmt.Sprintf("%s%s%x", table, tmpTableSuffix, suffix)
+	sqlf, e := sqlfs.Create(db.DB, db.DriverName, tmp, session, opts.chunkSize())
+	if e != nil {
+		return fmt.Errorf("cannot create sqlfs file %s: %v", table, e)
 	}
-	cmd := exec.Command("tar", "czf", "-", "-C", m.workDir, ".")
-	cmd.Stdout = sqlf
-	var errBuf bytes.Buffer
-	cmd.Stderr = &errBuf
+	closed := false
+	defer func() {
+		if e != nil {
+			if !closed {
+				sqlf.Close()
+			}
+			sqlfs.Remove(db.DB, tmp)
+		}
+	}()
 
-	if e := cmd.Run(); e != nil {
-		return fmt.Errorf("tar stderr: %v\ntar cmd %v", errBuf.String(), e)
+	w := &shortWriteChecker{w: sqlf}
+	if e = fn(w); e != nil {
+		return e
 	}
-
-	if e := sqlf.Close(); e != nil {
+	closed = true
+	if e = sqlf.Close(); e != nil {
 		return fmt.Errorf("close sqlfs error: %v", e)
 	}
+	size, e := sqlfs.Size(db.DB, tmp)
+	if e != nil {
+		return fmt.Errorf("verify sqlfs file %s failed: %v", table, e)
+	}
+	if expected := sqlfs.EncodedSize(w.n, opts.chunkSize()); size != expected {
+		return fmt.Errorf("sqlfs file %s is incomplete: stored %d bytes, expected %d", table, size, expected)
+	}
+	if e = sqlfs.Rename(db.DB, db.DriverName, tmp, table); e != nil {
+		return fmt.Errorf("cannot save sqlfs file %s: %v", table, e)
+	}
 	return nil
 }
 
-func (m *Model) saveTar(modelDir, save string) (e error) {
-	gobFile := filepath.Join(m.workDir, save+".gob")
-	if e := writeGob(gobFile, m); e != nil {
-		return e
+// saveTar writes the tarball modelDir/save.tar.gz, which contains the
+// meta file save.json. An encrypted model is written in the format of
+// writeTo instead, because the meta file would be encrypted too.
+func (m *Model) saveTar(ctx context.Context, modelDir, save string, opts *Options) (stats *SaveStats, e error) {
+	encrypted := opts.encrypted()
+	if !encrypted {
+		// Remove the meta file of older versions, which Load would
+		// read if save.json were missing.
+		if e := os.Remove(filepath.Join(m.workDir, gobMetaFile(save))); e != nil && !os.IsNotExist(e) {
+			return nil, e
+		}
+		if e := writeMetaFile(filepath.Join(m.workDir, jsonMetaFile(save)), m); e != nil {
+			return nil, e
+		}
 	}
 	modelFile := filepath.Join(modelDir, save+".tar.gz")
-	cmd := exec.Command("tar", "czf", modelFile, "-C", m.workDir, ".")
-	return cmd.Run()
+	f, e := os.Create(modelFile)
+	if e != nil {
+		return nil, fmt.Errorf("create tar file(%s) failed: %v", modelFile, e)
+	}
+	defer func() {
+		f.Close()
+		if e != nil {
+			// Don't leave a partial tarball behind.
+			os.Remove(modelFile)
+		}
+	}()
+	if encrypted {
+		stats, e = m.writeTo(ctx, f, opts)
+	} else {
+		w := newLimitWriter(f, opts.maxModelSize())
+		stats, e = tarGzDir(ctx, m.workDir, w, opts.compressionLevel(), m.saveProgress(opts), jsonMetaFile(save))
+	}
+	if e != nil {
+		return nil, e
+	}
+	if e = f.Close(); e != nil {
+		return nil, fmt.Errorf("close tar file(%s) failed: %v", modelFile, e)
+	}
+	return stats, nil
 }
 
-func loadTar(modelDir, cwd, save string) (m *Model, e error) {
+func loadTar(ctx context.Context, modelDir, cwd, save string, opts *Options) (m *Model, e error) {
 	tarFile := filepath.Join(modelDir, save+".tar.gz")
-	cmd := exec.Command("tar", "zxf", tarFile, "-C", cwd)
-	if e = cmd.Run(); e != nil {
+	f, e := os.Open(tarFile)
+	if e != nil {
 		return nil, fmt.Errorf("load tar file(%s) failed: %v", tarFile, e)
 	}
-	gobFile := filepath.Join(cwd, save+".gob")
-	m = &Model{}
-	if e = readGob(gobFile, m); e != nil {
-		return nil, e
+	defer f.Close()
+	var pg *progress
+	if cwd != "" {
+		if fi, e := f.Stat(); e == nil {
+			pg = newProgress(opts, fi.Size())
+		} else {
+			pg = newProgress(opts, -1)
+		}
 	}
-	return m, nil
+	if !isGzip(f) {
+		// Encrypted and deduplicated models are saved in the format of
+		// writeTo.
+		return readFrom(ctx, withProgress(f, pg), cwd, opts, pg, &fileChunks{filepath.Join(modelDir, chunksDir)})
+	}
+	if cwd == "" {
+		// Only the model meta is required, decode the meta file in the
+		// tarball without extracting it.
+		if m, e = decodeMetaInTarGz(f, save); e != nil {
+			return nil, fmt.Errorf("load tar file(%s) failed: %v", tarFile, e)
+		}
+		return m, nil
+	}
+	if e = untarGzStaged(ctx, withProgress(f, pg), cwd, opts.tempDir()); e != nil {
+		return nil, fmt.Errorf("load tar file(%s) failed: %v", tarFile, e)
+	}
+	pg.done()
+	return readMetaFile(cwd, save)
 }
 
 // load reads from the given sqlfs table for the train select
 // statement, and untar the SQLFlow working directory, which contains
 // the TensorFlow model, into directory cwd if cwd is not "".
-func loadDB(db *database.DB, table, cwd string) (m *Model, e error) {
+func loadDB(ctx context.Context, db *database.DB, table, cwd string, opts *Options) (m *Model, e error) {
+	// A failed readFrom leaves nothing extracted, so it's safe to retry.
+	var chunks chunkStore
+	if c, e := newDBChunks(db, table); e == nil {
+		chunks = c
+	}
+	e = retry(ctx, opts, func() error {
+		pg := dbProgress(db, table, cwd, opts)
+		return readDB(db, table, func(r io.Reader) (e error) {
+			m, e = readFrom(ctx, withProgress(r, pg), cwd, opts, pg, chunks)
+			return e
+		})
+	})
+	if e != nil {
+		return nil, e
+	}
+	return m, nil
+}
+
+// readDB opens a sqlfs table, and calls fn to read the content.
+func readDB(db *database.DB, table string, fn func(io.Reader) error) error {
 	sqlf, e := sqlfs.Open(db.DB, table)
 	if e != nil {
-		return nil, fmt.Errorf("cannot open sqlfs file %s: %v", table, e)
+		return fmt.Errorf("cannot open sqlfs file %s: %v", table, e)
 	}
 	defer sqlf.Close()
+	return fn(sqlf)
+}
 
-	var buf bytes.Buffer
-	if _, e := buf.ReadFrom(sqlf); e != nil {
-		return nil, fmt.Errorf("buf.ReadFrom %v", e)
+func deleteDB(db *database.DB, table string) error {
+	if !sqlfs.Exists(db.DB, table) {
+		return ErrModelNotFound
 	}
-	m = &Model{}
-	if e := gob.NewDecoder(&buf).Decode(m); e != nil {
-		return nil, fmt.Errorf("gob-decoding train select failed: %v", e)
+	if e := sqlfs.Remove(db.DB, table); e != nil {
+		return fmt.Errorf("cannot remove sqlfs file %s: %v", table, e)
 	}
+	return nil
+}
 
-	if cwd != "" { // empty in invalid param for tar -C
-		cmd := exec.Command("tar", "xzf", "-", "-C", cwd)
-		cmd.Stdin = &buf
-		output, err := cmd.CombinedOutput()
-		if err != nil {
-			return nil, fmt.Errorf("tar %v", string(output))
+func deleteTar(modelDir, save string) error {
+	tarFile := filepath.Join(modelDir, save+".tar.gz")
+	if e := os.Remove(tarFile); e != nil {
+		if os.IsNotExist(e) {
+			return ErrModelNotFound
 		}
+		return fmt.Errorf("delete tar file(%s) failed: %v", tarFile, e)
 	}
-	return m, nil
+	// The meta file is normally inside the tarball, remove the ones that
+	// may be left aside by other tools.
+	for _, meta := range []string{jsonMetaFile(save), gobMetaFile(save)} {
+		metaFile := filepath.Join(modelDir, meta)
+		if e := os.Remove(metaFile); e != nil && !os.IsNotExist(e) {
+			return fmt.Errorf("delete meta file(%s) failed: %v", metaFile, e)
+		}
+	}
+	return nil
 }
 
-func writeGob(filePath string, object interface{}) error {
-	file, e := os.Create(filePath)
+// writeTo writes the model meta by writeMeta followed by the
+// tar-gzipped working directory to w.
+func (m *Model) writeTo(ctx context.Context, w io.Writer, opts *Options) (*SaveStats, error) {
+	w = newLimitWriter(w, opts.maxModelSize())
+	// m may be loaded from an encrypted model, encrypt it again only if
+	// there is a key.
+	meta := *m
+	meta.Encryption, meta.Nonce, meta.KeyURI, meta.WrappedKey = "", nil, "", nil
+	key := opts.encryptionKey()
+	if key == nil && opts.kmsKeyURI() != "" {
+		var e error
+		if key, meta.WrappedKey, e = newDataKey(ctx, opts.kmsKeyURI()); e != nil {
+			return nil, fmt.Errorf("model.save: %v", e)
+		}
+		meta.KeyURI = opts.kmsKeyURI()
+	}
+	if key != nil {
+		nonce, e := newNonce()
+		if e != nil {
+			return nil, fmt.Errorf("model.save: generate nonce failed: %v", e)
+		}
+		meta.Encryption, meta.Nonce = encryptionAES256GCM, nonce
+	}
+	if e := writeMeta(w, &meta); e != nil {
+		return nil, fmt.Errorf("model.save: write the model meta failed: %v", e)
+	}
+	if key == nil {
+		return tarGzDir(ctx, m.workDir, w, opts.compressionLevel(), m.saveProgress(opts))
+	}
+
+	cw := &countingWriter{w: w}
+	ew, e := newEncryptWriter(cw, key, meta.Nonce)
+	if e != nil {
+		return nil, e
+	}
+	stats, e := tarGzDir(ctx, m.workDir, ew, opts.compressionLevel(), m.saveProgress(opts))
 	if e != nil {
-		return fmt.Errorf("create gob file :%s, error: %v", filePath, e)
+		return nil, e
 	}
-	defer file.Close()
-	if e := gob.NewEncoder(file).Encode(object); e != nil {
-		return fmt.Errorf("model.save: gob-encoding model failed: %v", e)
+	if e := ew.Close(); e != nil {
+		return nil, fmt.Errorf("model.save: encrypt the tarball failed: %v", e)
 	}
-	return nil
+	stats.CompressedSize = cw.n
+	return stats, nil
 }
 
-func readGob(filePath string, object interface{}) error {
-	file, e := os.Open(filePath)
+// readFrom decodes the model written by writeTo from r, and untars the
+// following tarball into cwd if cwd is not "". The tarball of a
+// deduplicated model is assembled from chunks, which is nil if the
+// storage doesn't support deduplicated models. It reports pg done after
+// the extraction succeeds.
+func readFrom(ctx context.Context, r io.Reader, cwd string, opts *Options, pg *progress, chunks chunkStore) (*Model, error) {
+	br := bufio.NewReader(r)
+	m, e := readMeta(br)
 	if e != nil {
-		return fmt.Errorf("model.load: gob-decoding model failed: %v", e)
+		return nil, e
 	}
-	defer file.Close()
-	if e := gob.NewDecoder(file).Decode(object); e != nil {
-		return fmt.Errorf("model.load: gob-decoding model failed: %v", e)
+
+	if cwd == "" {
+		return m, nil
 	}
-	return nil
+	var tarball io.Reader = br
+	if m.Encryption != "" {
+		r, e := decryptTarball(ctx, m, br, opts)
+		if e != nil {
+			return nil, e
+		}
+		tarball = r
+	}
+	if m.Deduplicated {
+		rc, e := assembleTarGz(ctx, br, chunks)
+		if e != nil {
+			return nil, e
+		}
+		defer rc.Close()
+		tarball = rc
+	}
+	if e := untarGzStaged(ctx, tarball, cwd, opts.tempDir()); e != nil {
+		return nil, e
+	}
+	pg.done()
+	return m, nil
 }
@@ -0,0 +1,629 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"sqlflow.org/sqlflow/pkg/database"
+	"sqlflow.org/sqlflow/pkg/log"
+	"sqlflow.org/sqlflow/pkg/metrics"
+	"sqlflow.org/sqlflow/pkg/sqlfs"
+)
+
+const testTrainSelect = "SELECT * FROM iris.train TO TRAIN DNNClassifier WITH model.n_classes=3, model.hidden_units=[10,20] LABEL class INTO my_dnn_model;"
+
+// mockWorkDir creates a working directory with a few files in it.
+func mockWorkDir(t *testing.T) string {
+	a := assert.New(t)
+	dir, e := ioutil.TempDir("", "sqlflow_model")
+	a.NoError(e)
+	a.NoError(os.MkdirAll(filepath.Join(dir, "my_dnn_model", "variables"), 0755))
+	a.NoError(ioutil.WriteFile(filepath.Join(dir, "my_dnn_model", "saved_model.pb"), []byte("graph"), 0644))
+	a.NoError(ioutil.WriteFile(filepath.Join(dir, "my_dnn_model", "variables", "variables.index"), []byte("index"), 0644))
+	a.NoError(ioutil.WriteFile(filepath.Join(dir, "train.py"), []byte("print('hello')"), 0644))
+	return dir
+}
+
+func TestSaveAndLoadFile(t *testing.T) {
+	a := assert.New(t)
+	cwd := mockWorkDir(t)
+	defer os.RemoveAll(cwd)
+	modelDir, e := ioutil.TempDir("", "sqlflow_model_dir")
+	a.NoError(e)
+	defer os.RemoveAll(modelDir)
+
+	modelURI := "file://" + filepath.Join(modelDir, "my_dnn_model")
+	stats, e := New(cwd, testTrainSelect).SaveWithStats(modelURI, nil, nil)
+	a.NoError(e)
+	a.Equal(modelURI, stats.ModelURI)
+	// three mocked files and the gob file
+	a.Equal(4, stats.Files)
+	a.True(stats.Size > int64(len("graph")+len("index")))
+	fi, e := os.Stat(filepath.Join(modelDir, "my_dnn_model.tar.gz"))
+	a.NoError(e)
+	a.Equal(fi.Size(), stats.CompressedSize)
+
+	dst, e := ioutil.TempDir("", "sqlflow_model_dst")
+	a.NoError(e)
+	defer os.RemoveAll(dst)
+	m, e := Load(modelURI, dst, nil)
+	a.NoError(e)
+	a.Equal(testTrainSelect, m.TrainSelect)
+	b, e := ioutil.ReadFile(filepath.Join(dst, "my_dnn_model", "variables", "variables.index"))
+	a.NoError(e)
+	a.Equal("index", string(b))
+
+	m, e = Load(modelURI, "", nil)
+	a.NoError(e)
+	a.Equal(testTrainSelect, m.TrainSelect)
+
+	buf := &bytes.Buffer{}
+	a.NoError(metrics.WriteText(buf))
+	a.Contains(buf.String(), `sqlflow_model_save_bytes_count{storage="file"}`)
+	a.Contains(buf.String(), `sqlflow_model_load_bytes_count{storage="file"}`)
+	a.Contains(buf.String(), `sqlflow_model_load_duration_seconds_count{storage="file"}`)
+
+	a.NoError(Delete(modelURI, nil))
+	_, e = os.Stat(filepath.Join(modelDir, "my_dnn_model.tar.gz"))
+	a.True(os.IsNotExist(e))
+	a.Equal(ErrModelNotFound, Delete(modelURI, nil))
+}
+
+func TestStorageName(t *testing.T) {
+	a := assert.New(t)
+	a.Equal("sqlfs", storageName("sqlflow_models.my_model"))
+	a.Equal("file", storageName("file:///tmp/my_model"))
+	a.Equal("sqlfs", storageName("bad:my_model"))
+}
+
+// TestSaveAndLoadWithoutTar checks that the models are archived without
+// the tar command, which the distroless containers don't have.
+func TestSaveAndLoadWithoutTar(t *testing.T) {
+	a := assert.New(t)
+	cwd := mockWorkDir(t)
+	defer os.RemoveAll(cwd)
+	modelDir, e := ioutil.TempDir("", "sqlflow_model_dir")
+	a.NoError(e)
+	defer os.RemoveAll(modelDir)
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	a.NoError(os.Setenv("PATH", modelDir))
+	_, e = exec.LookPath("tar")
+	a.Error(e)
+
+	modelURI := "file://" + filepath.Join(modelDir, "my_dnn_model")
+	a.NoError(New(cwd, testTrainSelect).Save(modelURI, nil, nil))
+	dst, e := ioutil.TempDir("", "sqlflow_model_dst")
+	a.NoError(e)
+	defer os.RemoveAll(dst)
+	_, e = Load(modelURI, dst, nil)
+	a.NoError(e)
+	b, e := ioutil.ReadFile(filepath.Join(dst, "my_dnn_model", "saved_model.pb"))
+	a.NoError(e)
+	a.Equal("graph", string(b))
+}
+
+func TestLoadMetaFile(t *testing.T) {
+	a := assert.New(t)
+	cwd := mockWorkDir(t)
+	defer os.RemoveAll(cwd)
+	modelDir, e := ioutil.TempDir("", "sqlflow_model_dir")
+	a.NoError(e)
+	defer os.RemoveAll(modelDir)
+
+	modelURI := "file://" + filepath.Join(modelDir, "my_dnn_model")
+	a.NoError(New(cwd, testTrainSelect).Save(modelURI, nil, nil))
+	f, e := os.Open(filepath.Join(modelDir, "my_dnn_model.tar.gz"))
+	a.NoError(e)
+	defer f.Close()
+	// LoadMeta stops at the first entry.
+	gr, e := gzip.NewReader(f)
+	a.NoError(e)
+	hdr, e := tar.NewReader(gr).Next()
+	a.NoError(e)
+	a.Equal("my_dnn_model.json", hdr.Name)
+
+	m, e := LoadMeta(modelURI, nil)
+	a.NoError(e)
+	a.Equal(testTrainSelect, m.TrainSelect)
+	_, e = LoadMeta("file://"+filepath.Join(modelDir, "no_such_model"), nil)
+	a.Error(e)
+}
+
+func TestSaveAndLoadGroupsFile(t *testing.T) {
+	a := assert.New(t)
+	cwd := mockWorkDir(t)
+	defer os.RemoveAll(cwd)
+	modelDir, e := ioutil.TempDir("", "sqlflow_model_dir")
+	a.NoError(e)
+	defer os.RemoveAll(modelDir)
+
+	// the model of a group is next to the index
+	groupURI := "file://" + filepath.Join(modelDir, "my_dnn_model", "region=cn")
+	a.NoError(New(cwd, testTrainSelect).Save(groupURI, nil, nil))
+	a.FileExists(filepath.Join(modelDir, "my_dnn_model", "region=cn.tar.gz"))
+	index := New(cwd, testTrainSelect)
+	index.Groups = &Groups{Column: "region", Values: []string{"cn"}}
+	a.NoError(index.Save("file://"+filepath.Join(modelDir, "my_dnn_model"), nil, nil))
+
+	m, e := LoadMeta("file://"+filepath.Join(modelDir, "my_dnn_model"), nil)
+	a.NoError(e)
+	a.Equal(&Groups{Column: "region", Values: []string{"cn"}}, m.Groups)
+	m, e = LoadMeta(groupURI, nil)
+	a.NoError(e)
+	a.Nil(m.Groups)
+}
+
+func TestSaveWithCompressionLevel(t *testing.T) {
+	a := assert.New(t)
+	cwd := mockWorkDir(t)
+	defer os.RemoveAll(cwd)
+	modelDir, e := ioutil.TempDir("", "sqlflow_model_dir")
+	a.NoError(e)
+	defer os.RemoveAll(modelDir)
+
+	m := New(cwd, testTrainSelect)
+	modelURI := "file://" + filepath.Join(modelDir, "my_dnn_model")
+	_, e = m.SaveWithOptions(context.Background(), modelURI, nil, nil, &Options{CompressionLevel: 100})
+	a.Error(e)
+	stats, e := m.SaveWithOptions(context.Background(), modelURI, nil, nil, &Options{CompressionLevel: gzip.BestSpeed})
+	a.NoError(e)
+	a.Equal(4, stats.Files)
+}
+
+func TestSaveWithMaxModelSize(t *testing.T) {
+	a := assert.New(t)
+	cwd := mockWorkDir(t)
+	defer os.RemoveAll(cwd)
+	modelDir, e := ioutil.TempDir("", "sqlflow_model_dir")
+	a.NoError(e)
+	defer os.RemoveAll(modelDir)
+
+	m := New(cwd, testTrainSelect)
+	modelURI := "file://" + filepath.Join(modelDir, "my_dnn_model")
+	_, e = m.SaveWithOptions(context.Background(), modelURI, nil, nil, &Options{MaxModelSize: 100})
+	a.Error(e)
+	a.Contains(e.Error(), "exceeds the maximum size of 100 bytes")
+	_, e = os.Stat(filepath.Join(modelDir, "my_dnn_model.tar.gz"))
+	a.True(os.IsNotExist(e))
+
+	stats, e := m.SaveWithOptions(context.Background(), modelURI, nil, nil, &Options{MaxModelSize: 1 << 20})
+	a.NoError(e)
+	a.True(stats.CompressedSize <= 1<<20)
+}
+
+func TestSaveAndLoadWithProgress(t *testing.T) {
+	a := assert.New(t)
+	cwd := mockWorkDir(t)
+	defer os.RemoveAll(cwd)
+	modelDir, e := ioutil.TempDir("", "sqlflow_model_dir")
+	a.NoError(e)
+	defer os.RemoveAll(modelDir)
+
+	var calls, processed, total int64
+	opts := &Options{Progress: func(p, t int64) {
+		a.True(p > processed)
+		calls, processed, total = calls+1, p, t
+	}}
+	modelURI := "file://" + filepath.Join(modelDir, "my_dnn_model")
+	stats, e := New(cwd, testTrainSelect).SaveWithOptions(context.Background(), modelURI, nil, nil, opts)
+	a.NoError(e)
+	a.True(calls > 0)
+	a.Equal(stats.Size, total)
+	a.Equal(total, processed)
+
+	dst, e := ioutil.TempDir("", "sqlflow_model_dst")
+	a.NoError(e)
+	defer os.RemoveAll(dst)
+	calls, processed = 0, 0
+	_, e = LoadWithOptions(context.Background(), modelURI, dst, nil, opts)
+	a.NoError(e)
+	fi, e := os.Stat(filepath.Join(modelDir, "my_dnn_model.tar.gz"))
+	a.NoError(e)
+	a.True(calls > 0)
+	a.Equal(fi.Size(), total)
+	a.Equal(total, processed)
+
+	// Loading the model meta reports nothing.
+	calls = 0
+	_, e = LoadWithOptions(context.Background(), modelURI, "", nil, opts)
+	a.NoError(e)
+	a.Equal(int64(0), calls)
+}
+
+func TestSaveAndLoadWithLogger(t *testing.T) {
+	a := assert.New(t)
+	cwd := mockWorkDir(t)
+	defer os.RemoveAll(cwd)
+	modelDir, e := ioutil.TempDir("", "sqlflow_model_dir")
+	a.NoError(e)
+	defer os.RemoveAll(modelDir)
+
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.Out, logger.Formatter = &buf, &logrus.JSONFormatter{}
+	opts := &Options{Logger: &log.Logger{Entry: logrus.NewEntry(logger)}}
+	modelURI := "file://" + filepath.Join(modelDir, "my_dnn_model")
+	stats, e := New(cwd, testTrainSelect).SaveWithOptions(context.Background(), modelURI, nil, nil, opts)
+	a.NoError(e)
+	events := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	a.Equal(2, len(events))
+	a.Contains(events[0], `"msg":"model.save: start"`)
+	a.Contains(events[1], `"msg":"model.save: done"`)
+	a.Contains(events[1], fmt.Sprintf(`"compressed_size":%d`, stats.CompressedSize))
+	a.Contains(events[1], `"uri":"`+modelURI+`"`)
+
+	buf.Reset()
+	_, e = LoadWithOptions(context.Background(), modelURI+"_absent", "", nil, opts)
+	a.Error(e)
+	a.Contains(buf.String(), `"msg":"model.load: failed"`)
+	a.Contains(buf.String(), `"level":"error"`)
+}
+
+func TestSaveContextCanceled(t *testing.T) {
+	a := assert.New(t)
+	cwd := mockWorkDir(t)
+	defer os.RemoveAll(cwd)
+	modelDir, e := ioutil.TempDir("", "sqlflow_model_dir")
+	a.NoError(e)
+	defer os.RemoveAll(modelDir)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	modelURI := "file://" + filepath.Join(modelDir, "my_dnn_model")
+	e = New(cwd, testTrainSelect).SaveContext(ctx, modelURI, nil, nil)
+	a.Error(e)
+	a.Contains(e.Error(), context.Canceled.Error())
+	_, e = os.Stat(filepath.Join(modelDir, "my_dnn_model.tar.gz"))
+	a.True(os.IsNotExist(e))
+}
+
+func TestLoadWithTempDir(t *testing.T) {
+	a := assert.New(t)
+	cwd := mockWorkDir(t)
+	defer os.RemoveAll(cwd)
+	modelDir, e := ioutil.TempDir("", "sqlflow_model_dir")
+	a.NoError(e)
+	defer os.RemoveAll(modelDir)
+	modelURI := "file://" + filepath.Join(modelDir, "my_dnn_model")
+	a.NoError(New(cwd, testTrainSelect).Save(modelURI, nil, nil))
+
+	// Concurrent Loads extract the same gob file name into their own
+	// temp dirs.
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	dirs := make([]string, 4)
+	for i := range errs {
+		dirs[i], e = ioutil.TempDir("", "sqlflow_model_load")
+		a.NoError(e)
+		defer os.RemoveAll(dirs[i])
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tempDir := filepath.Join(dirs[i], "tmp")
+			if errs[i] = os.Mkdir(tempDir, 0755); errs[i] != nil {
+				return
+			}
+			_, errs[i] = LoadWithOptions(context.Background(), modelURI, filepath.Join(dirs[i], "dst"), nil, &Options{TempDir: tempDir})
+		}(i)
+	}
+	wg.Wait()
+	for i, e := range errs {
+		a.NoError(e)
+		b, e := ioutil.ReadFile(filepath.Join(dirs[i], "dst", "my_dnn_model", "saved_model.pb"))
+		a.NoError(e)
+		a.Equal("graph", string(b))
+		fis, e := ioutil.ReadDir(filepath.Join(dirs[i], "tmp"))
+		a.NoError(e)
+		a.Empty(fis)
+	}
+
+	// A truncated tarball leaves nothing in the destination.
+	tarFile := filepath.Join(modelDir, "my_dnn_model.tar.gz")
+	fi, e := os.Stat(tarFile)
+	a.NoError(e)
+	a.NoError(os.Truncate(tarFile, fi.Size()/2))
+	dst, e := ioutil.TempDir("", "sqlflow_model_dst")
+	a.NoError(e)
+	defer os.RemoveAll(dst)
+	_, e = Load(modelURI, dst, nil)
+	a.Error(e)
+	fis, e := ioutil.ReadDir(dst)
+	a.NoError(e)
+	a.Empty(fis)
+}
+
+func TestLoadWithTempDirOnAnotherFilesystem(t *testing.T) {
+	a := assert.New(t)
+	cwd := mockWorkDir(t)
+	defer os.RemoveAll(cwd)
+	modelDir, e := ioutil.TempDir("", "sqlflow_model_dir")
+	a.NoError(e)
+	defer os.RemoveAll(modelDir)
+	modelURI := "file://" + filepath.Join(modelDir, "my_dnn_model")
+	a.NoError(New(cwd, testTrainSelect).Save(modelURI, nil, nil))
+
+	// Moving from the temp dir to the destination fails like they are
+	// on two filesystems.
+	defer func(r func(string, string) error) { rename = r }(rename)
+	rename = func(oldpath, newpath string) error {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EXDEV}
+	}
+	tempDir, e := ioutil.TempDir("", "sqlflow_model_tmp")
+	a.NoError(e)
+	defer os.RemoveAll(tempDir)
+	dst, e := ioutil.TempDir("", "sqlflow_model_dst")
+	a.NoError(e)
+	defer os.RemoveAll(dst)
+	_, e = LoadWithOptions(context.Background(), modelURI, dst, nil, &Options{TempDir: tempDir})
+	a.NoError(e)
+	b, e := ioutil.ReadFile(filepath.Join(dst, "my_dnn_model", "saved_model.pb"))
+	a.NoError(e)
+	a.Equal("graph", string(b))
+	b, e = ioutil.ReadFile(filepath.Join(dst, "my_dnn_model", "variables", "variables.index"))
+	a.NoError(e)
+	a.Equal("index", string(b))
+	fis, e := ioutil.ReadDir(tempDir)
+	a.NoError(e)
+	a.Empty(fis)
+
+	// The other errors of os.Rename are not retried by copying.
+	rename = func(oldpath, newpath string) error {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EACCES}
+	}
+	_, e = LoadWithOptions(context.Background(), modelURI, dst, nil, &Options{TempDir: tempDir})
+	a.Error(e)
+	a.Contains(e.Error(), syscall.EACCES.Error())
+}
+
+func TestSaveAndListDB(t *testing.T) {
+	a := assert.New(t)
+	db := database.GetTestingDBSingleton()
+	if db.DriverName != "mysql" {
+		t.Skip("Skip as SQLFLOW_TEST_DB is not MySQL")
+	}
+	cwd := mockWorkDir(t)
+	defer os.RemoveAll(cwd)
+
+	table := "sqlflow_models.my_list_model"
+	a.NoError(New(cwd, testTrainSelect).Save(table, nil, database.GetSessionFromTestingDB()))
+	models, e := List(db)
+	a.NoError(e)
+	found := false
+	for _, m := range models {
+		if m.Name == table {
+			found = true
+			a.Equal(testTrainSelect, m.TrainSelect)
+			a.True(m.Size > 0)
+			a.Equal(table, m.Model)
+			a.Equal(int64(0), m.Version)
+		}
+	}
+	a.True(found)
+
+	session := database.GetSessionFromTestingDB()
+	a.NoError(Delete(table, session))
+	a.Equal(ErrModelNotFound, Delete(table, session))
+}
+
+func TestLoadWithSessionDB(t *testing.T) {
+	a := assert.New(t)
+	cwd := mockWorkDir(t)
+	defer os.RemoveAll(cwd)
+	session := database.GetSessionFromTestingDB()
+
+	table := "sqlflow_models.my_session_model"
+	a.NoError(New(cwd, testTrainSelect).Save(table, nil, session))
+	dst, e := ioutil.TempDir("", "sqlflow_model_dst")
+	a.NoError(e)
+	defer os.RemoveAll(dst)
+	m, e := LoadWithSession(context.Background(), table, dst, session, nil)
+	a.NoError(e)
+	a.Equal(testTrainSelect, m.TrainSelect)
+	b, e := ioutil.ReadFile(filepath.Join(dst, "my_dnn_model", "saved_model.pb"))
+	a.NoError(e)
+	a.Equal("graph", string(b))
+	a.NoError(Delete(table, session))
+}
+
+func TestWriteDBFailsPartway(t *testing.T) {
+	a := assert.New(t)
+	db := database.GetTestingDBSingleton()
+	if db.DriverName != "mysql" {
+		t.Skip("Skip as SQLFLOW_TEST_DB is not MySQL")
+	}
+	table := "sqlflow_models.my_partial_model"
+	e := writeDB(db, table, database.GetSessionFromTestingDB(), nil, func(w io.Writer) error {
+		// Write more than a sqlfs block before failing.
+		if _, e := w.Write(make([]byte, 100*1024)); e != nil {
+			return e
+		}
+		return fmt.Errorf("injected write error")
+	})
+	a.Error(e)
+	a.False(sqlfs.Exists(db.DB, table))
+}
+
+func TestSaveConcurrentlyDB(t *testing.T) {
+	a := assert.New(t)
+	db := database.GetTestingDBSingleton()
+	if db.DriverName != "mysql" {
+		t.Skip("Skip as SQLFLOW_TEST_DB is not MySQL")
+	}
+	session := database.GetSessionFromTestingDB()
+	table := "sqlflow_models.my_concurrent_model"
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cwd := mockWorkDir(t)
+			defer os.RemoveAll(cwd)
+			errs[i] = New(cwd, fmt.Sprintf("%s -- %d", testTrainSelect, i)).Save(table, nil, session)
+		}(i)
+	}
+	wg.Wait()
+	// The saves may fail fast, but the saved model is one of them.
+	saved := 0
+	for _, e := range errs {
+		if e == nil {
+			saved++
+		}
+	}
+	a.True(saved > 0)
+	dst, e := ioutil.TempDir("", "sqlflow_model_dst")
+	a.NoError(e)
+	defer os.RemoveAll(dst)
+	m, e := LoadWithSession(context.Background(), table, dst, session, nil)
+	a.NoError(e)
+	a.True(strings.HasPrefix(m.TrainSelect, testTrainSelect))
+	a.NoError(Delete(table, session))
+}
+
+func TestSaveAndLoadVersionsDB(t *testing.T) {
+	a := assert.New(t)
+	db := database.GetTestingDBSingleton()
+	if db.DriverName != "mysql" {
+		t.Skip("Skip as SQLFLOW_TEST_DB is not MySQL")
+	}
+	cwd := mockWorkDir(t)
+	defer os.RemoveAll(cwd)
+	session := database.GetSessionFromTestingDB()
+	name := "sqlflow_models.my_versioned_model"
+	// The table doesn't exist before the first SaveVersion.
+	db.Exec(fmt.Sprintf("DELETE FROM %s WHERE name = ?", modelZooTable), name)
+	db.Exec(fmt.Sprintf("DELETE FROM %s WHERE name = ?", modelTagTable), name)
+
+	for i := int64(1); i <= 3; i++ {
+		trainSelect := fmt.Sprintf("%s -- v%d", testTrainSelect, i)
+		v, e := New(cwd, trainSelect).SaveVersion(name, session, &Options{KeepVersions: 3})
+		a.NoError(e)
+		a.Equal(i, v)
+	}
+	for i := int64(1); i <= 3; i++ {
+		m, e := LoadVersion(name, i, "", db)
+		a.NoError(e)
+		a.Equal(fmt.Sprintf("%s -- v%d", testTrainSelect, i), m.TrainSelect)
+	}
+	m, e := LoadVersion(name, 0, "", db)
+	a.NoError(e)
+	a.Equal(fmt.Sprintf("%s -- v%d", testTrainSelect, 3), m.TrainSelect)
+
+	// Keep the last two versions.
+	v, e := New(cwd, testTrainSelect).SaveVersion(name, session, &Options{KeepVersions: 2})
+	a.NoError(e)
+	a.Equal(int64(4), v)
+	versions, e := ListVersions(name, db)
+	a.NoError(e)
+	a.Equal(2, len(versions))
+	a.Equal(int64(4), versions[0].Version)
+	a.Equal(int64(3), versions[1].Version)
+	_, e = LoadVersion(name, 1, "", db)
+	a.Error(e)
+
+	// Tag version 3, and move the tag to version 4.
+	a.Equal(ErrModelNotFound, TagVersion(name, 1, "production", db))
+	a.NoError(TagVersion(name, 3, "production", db))
+	a.NoError(TagVersion(name, 3, "stable", db))
+	m, e = LoadTag(name, "production", "", db)
+	a.NoError(e)
+	a.Equal(fmt.Sprintf("%s -- v%d", testTrainSelect, 3), m.TrainSelect)
+	a.NoError(TagVersion(name, 4, "production", db))
+	m, e = LoadTag(name, "production", "", db)
+	a.NoError(e)
+	a.Equal(testTrainSelect, m.TrainSelect)
+	versions, e = ListVersions(name, db)
+	a.NoError(e)
+	a.Equal([]string{"production"}, versions[0].Tags)
+	a.Equal([]string{"stable"}, versions[1].Tags)
+	_, e = LoadTag(name, "nonexistent", "", db)
+	a.Equal(ErrModelNotFound, e)
+
+	// Pruning a version deletes its tags.
+	_, e = New(cwd, testTrainSelect).SaveVersion(name, session, &Options{KeepVersions: 1})
+	a.NoError(e)
+	_, e = LoadTag(name, "stable", "", db)
+	a.Equal(ErrModelNotFound, e)
+
+	models, e := List(db)
+	a.NoError(e)
+	found := false
+	for _, m := range models {
+		if m.Model == name {
+			found = true
+			a.Equal(versionTable(name, 5), m.Name)
+			a.Equal(int64(5), m.Version)
+			a.False(m.CreatedAt.IsZero())
+		}
+	}
+	a.True(found)
+	a.NoError(DeleteVersions(name, db))
+	a.Equal(ErrModelNotFound, DeleteVersions(name, db))
+	_, e = LoadVersion(name, 0, "", db)
+	a.Equal(ErrModelNotFound, e)
+}
+
+// BenchmarkSaveCompressionLevel shows the tradeoff between the save time
+// and the tarball size of compression levels.
+func BenchmarkSaveCompressionLevel(b *testing.B) {
+	cwd, e := ioutil.TempDir("", "sqlflow_model")
+	if e != nil {
+		b.Fatal(e)
+	}
+	defer os.RemoveAll(cwd)
+	// 32MB of periodic bytes, which compress differently by level.
+	data := make([]byte, 32<<20)
+	for i := range data {
+		data[i] = byte(i % 251 * (i % 7))
+	}
+	if e := ioutil.WriteFile(filepath.Join(cwd, "model.ckpt"), data, 0644); e != nil {
+		b.Fatal(e)
+	}
+	modelDir, e := ioutil.TempDir("", "sqlflow_model_dir")
+	if e != nil {
+		b.Fatal(e)
+	}
+	defer os.RemoveAll(modelDir)
+
+	m := New(cwd, testTrainSelect)
+	modelURI := "file://" + filepath.Join(modelDir, "my_model")
+	for _, level := range []int{gzip.BestSpeed, gzip.DefaultCompression, gzip.BestCompression} {
+		b.Run(fmt.Sprintf("level=%d", level), func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			var stats *SaveStats
+			for i := 0; i < b.N; i++ {
+				if stats, e = m.SaveWithOptions(context.Background(), modelURI, nil, nil, &Options{CompressionLevel: level}); e != nil {
+					b.Fatal(e)
+				}
+			}
+			b.ReportMetric(float64(stats.CompressedSize), "tarball-bytes")
+		})
+	}
+}
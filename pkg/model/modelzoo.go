@@ -0,0 +1,182 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+
+	"google.golang.org/grpc"
+	"sqlflow.org/sqlflow/pkg/database"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+)
+
+// zooChunkSize is the size of the content in each request to the Model
+// Zoo server, far below the 4MB limit of a gRPC message.
+const zooChunkSize = 64 * 1024
+
+// Release describes a trained model released to a Model Zoo server.
+type Release struct {
+	// Image is the Docker image of the model definition to run the
+	// model, like "hub.docker.com/my_group/my_models:v0.1".
+	Image string
+	// Requirements is the Python packages required to run the model, in
+	// the format of requirements.txt.
+	Requirements string
+	// Description is like which dataset trained the model.
+	Description string
+	// EvaluationMetrics is the evaluation result in JSON, like
+	// {"Accuracy": 0.87, "AUC": 0.73}.
+	EvaluationMetrics string
+}
+
+// Publish uploads the version of the model name tagged tag by
+// TagVersion in db to the Model Zoo server at zooAddr, as the trained
+// model of the same name and tag, which replaces the one released
+// before. release, which can be nil, describes the model. The tarball
+// is streamed as is, so an encrypted model is released encrypted.
+func Publish(zooAddr, name, tag string, db *database.DB, release *Release) error {
+	version, e := taggedVersion(name, tag, db)
+	if e != nil {
+		return e
+	}
+	return readDB(db, versionTable(name, version), func(r io.Reader) error {
+		br := bufio.NewReader(r)
+		m, e := readMeta(br)
+		if e != nil {
+			return e
+		}
+		return publish(context.Background(), zooAddr, name, tag, release, m, br)
+	})
+}
+
+// publish uploads the model meta m and the following tarball to the
+// Model Zoo server at zooAddr.
+func publish(ctx context.Context, zooAddr, name, tag string, release *Release, m *Model, tarball io.Reader) error {
+	if m.Deduplicated {
+		// The chunks are not uploaded along with the manifest.
+		return fmt.Errorf("publishing deduplicated models is not supported now")
+	}
+	if release == nil {
+		release = &Release{}
+	}
+	conn, e := grpc.Dial(zooAddr, grpc.WithInsecure())
+	if e != nil {
+		return fmt.Errorf("connect to the model zoo %s failed: %v", zooAddr, e)
+	}
+	defer conn.Close()
+	stream, e := pb.NewModelZooServerClient(conn).ReleaseTrainedModel(ctx)
+	if e != nil {
+		return fmt.Errorf("publish model %s:%s failed: %v", name, tag, e)
+	}
+	req := &pb.TrainedModelRequest{
+		Name:              name,
+		Tag:               tag,
+		Description:       release.Description,
+		EvaluationMetrics: release.EvaluationMetrics,
+		Image:             release.Image,
+		Requirements:      release.Requirements,
+	}
+	w := bufio.NewWriterSize(&zooWriter{stream: stream, req: req}, zooChunkSize)
+	if e := writeMeta(w, m); e != nil {
+		return fmt.Errorf("publish model %s:%s failed: %v", name, tag, e)
+	}
+	if _, e := io.Copy(w, tarball); e != nil {
+		return fmt.Errorf("publish model %s:%s failed: %v", name, tag, e)
+	}
+	if e := w.Flush(); e != nil {
+		return fmt.Errorf("publish model %s:%s failed: %v", name, tag, e)
+	}
+	res, e := stream.CloseAndRecv()
+	if e != nil {
+		return fmt.Errorf("publish model %s:%s failed: %v", name, tag, e)
+	}
+	if !res.Success {
+		return fmt.Errorf("publish model %s:%s failed: %s", name, tag, res.Message)
+	}
+	return nil
+}
+
+// zooWriter sends each Write as the content of a request, the first of
+// which is req.
+type zooWriter struct {
+	stream pb.ModelZooServer_ReleaseTrainedModelClient
+	req    *pb.TrainedModelRequest
+}
+
+func (w *zooWriter) Write(p []byte) (int, error) {
+	req := w.req
+	if req == nil {
+		req = &pb.TrainedModelRequest{}
+	}
+	w.req = nil
+	req.ContentTar = p
+	if e := w.stream.Send(req); e != nil {
+		return 0, e
+	}
+	return len(p), nil
+}
+
+// Pull downloads the trained model name:tag from the Model Zoo server at
+// zooAddr, and untars it into dst like Load. It returns the model and
+// how it's released.
+func Pull(zooAddr, name, tag, dst string) (*Model, *Release, error) {
+	ctx := context.Background()
+	conn, e := grpc.Dial(zooAddr, grpc.WithInsecure())
+	if e != nil {
+		return nil, nil, fmt.Errorf("connect to the model zoo %s failed: %v", zooAddr, e)
+	}
+	defer conn.Close()
+	stream, e := pb.NewModelZooServerClient(conn).GetTrainedModel(ctx, &pb.TrainedModelRequest{Name: name, Tag: tag})
+	if e != nil {
+		return nil, nil, fmt.Errorf("pull model %s:%s failed: %v", name, tag, e)
+	}
+	res, e := stream.Recv()
+	if e != nil {
+		return nil, nil, fmt.Errorf("pull model %s:%s failed: %v", name, tag, e)
+	}
+	release := &Release{
+		Image:             res.Image,
+		Requirements:      res.Requirements,
+		Description:       res.Description,
+		EvaluationMetrics: res.EvaluationMetrics,
+	}
+	r := &zooReader{stream: stream, buf: res.ContentTar}
+	m, e := readFrom(ctx, r, dst, nil, nil, nil)
+	if e != nil {
+		return nil, nil, fmt.Errorf("pull model %s:%s failed: %v", name, tag, e)
+	}
+	return m, release, nil
+}
+
+// zooReader reads the content in the responses of stream.
+type zooReader struct {
+	stream pb.ModelZooServer_GetTrainedModelClient
+	buf    []byte
+}
+
+func (r *zooReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		res, e := r.stream.Recv()
+		if e != nil {
+			return 0, e
+		}
+		r.buf = res.ContentTar
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
@@ -0,0 +1,214 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"sqlflow.org/sqlflow/pkg/database"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+)
+
+// fakeModelZoo keeps the released trained models in memory.
+type fakeModelZoo struct {
+	mu       sync.Mutex
+	released map[string]*pb.TrainedModelRequest
+	requests int
+}
+
+func (z *fakeModelZoo) ListModelDefs(context.Context, *pb.ListModelRequest) (*pb.ListModelResponse, error) {
+	return &pb.ListModelResponse{}, nil
+}
+
+func (z *fakeModelZoo) ListTrainedModels(context.Context, *pb.ListModelRequest) (*pb.ListModelResponse, error) {
+	return &pb.ListModelResponse{}, nil
+}
+
+func (z *fakeModelZoo) ReleaseModelDef(pb.ModelZooServer_ReleaseModelDefServer) error {
+	return fmt.Errorf("not implemented")
+}
+
+func (z *fakeModelZoo) DropModelDef(context.Context, *pb.ModelDefRequest) (*pb.ModelResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (z *fakeModelZoo) ReleaseTrainedModel(stream pb.ModelZooServer_ReleaseTrainedModelServer) error {
+	var first *pb.TrainedModelRequest
+	content := []byte{}
+	requests := 0
+	for {
+		req, e := stream.Recv()
+		if e == io.EOF {
+			break
+		}
+		if e != nil {
+			return e
+		}
+		if first == nil {
+			first = req
+		}
+		content = append(content, req.ContentTar...)
+		requests++
+	}
+	first.ContentTar = content
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	z.released[first.Name+":"+first.Tag] = first
+	z.requests = requests
+	return stream.SendAndClose(&pb.ModelResponse{Success: true})
+}
+
+func (z *fakeModelZoo) DropTrainedModel(context.Context, *pb.TrainedModelRequest) (*pb.ModelResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (z *fakeModelZoo) GetTrainedModel(req *pb.TrainedModelRequest, stream pb.ModelZooServer_GetTrainedModelServer) error {
+	z.mu.Lock()
+	m, ok := z.released[req.Name+":"+req.Tag]
+	z.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("no trained model %s:%s", req.Name, req.Tag)
+	}
+	e := stream.Send(&pb.TrainedModelResponse{
+		Name:              m.Name,
+		Tag:               m.Tag,
+		Description:       m.Description,
+		EvaluationMetrics: m.EvaluationMetrics,
+		Image:             m.Image,
+		Requirements:      m.Requirements,
+	})
+	if e != nil {
+		return e
+	}
+	// Send the content in small pieces.
+	for content := m.ContentTar; len(content) > 0; {
+		n := 100
+		if n > len(content) {
+			n = len(content)
+		}
+		if e := stream.Send(&pb.TrainedModelResponse{ContentTar: content[:n]}); e != nil {
+			return e
+		}
+		content = content[n:]
+	}
+	return nil
+}
+
+// startFakeModelZoo returns the address of a fake Model Zoo server and a
+// function to stop it.
+func startFakeModelZoo(t *testing.T) (*fakeModelZoo, string, func()) {
+	lis, e := net.Listen("tcp", "127.0.0.1:0")
+	if e != nil {
+		t.Fatalf("failed to listen: %v", e)
+	}
+	zoo := &fakeModelZoo{released: map[string]*pb.TrainedModelRequest{}}
+	s := grpc.NewServer()
+	pb.RegisterModelZooServerServer(s, zoo)
+	go s.Serve(lis)
+	return zoo, lis.Addr().String(), s.Stop
+}
+
+func TestPublishAndPull(t *testing.T) {
+	a := assert.New(t)
+	zoo, addr, stop := startFakeModelZoo(t)
+	defer stop()
+
+	cwd := mockWorkDir(t)
+	defer os.RemoveAll(cwd)
+	// A big random file to be uploaded in many requests.
+	big := make([]byte, 4*zooChunkSize)
+	rand.New(rand.NewSource(1)).Read(big)
+	a.NoError(ioutil.WriteFile(filepath.Join(cwd, "big"), big, 0644))
+	modelDir, e := ioutil.TempDir("", "sqlflow_model_dir")
+	a.NoError(e)
+	defer os.RemoveAll(modelDir)
+	modelURI := "file://" + filepath.Join(modelDir, "my_dnn_model")
+	a.NoError(New(cwd, testTrainSelect).Save(modelURI, nil, nil))
+
+	release := &Release{
+		Image:             "sqlflow/my_models:v0.1",
+		Requirements:      "tensorflow==2.0.1\n",
+		Description:       "trained on iris.train",
+		EvaluationMetrics: `{"Accuracy": 0.87}`,
+	}
+	e = readModel(context.Background(), modelURI, nil, func(m *Model, tarball io.Reader) error {
+		return publish(context.Background(), addr, "my_dnn_model", "v0.1", release, m, tarball)
+	})
+	a.NoError(e)
+	a.True(zoo.requests > 1)
+
+	dst, e := ioutil.TempDir("", "sqlflow_model_dst")
+	a.NoError(e)
+	defer os.RemoveAll(dst)
+	m, pulled, e := Pull(addr, "my_dnn_model", "v0.1", dst)
+	a.NoError(e)
+	a.Equal(testTrainSelect, m.TrainSelect)
+	a.Equal(release, pulled)
+	b, e := ioutil.ReadFile(filepath.Join(dst, "my_dnn_model", "variables", "variables.index"))
+	a.NoError(e)
+	a.Equal("index", string(b))
+	b, e = ioutil.ReadFile(filepath.Join(dst, "big"))
+	a.NoError(e)
+	a.True(bytes.Equal(big, b))
+
+	_, _, e = Pull(addr, "my_dnn_model", "v0.2", dst)
+	a.Error(e)
+	_, _, e = Pull(addr, "my_dnn_model", "", dst)
+	a.Error(e)
+}
+
+func TestPublishAndPullDB(t *testing.T) {
+	a := assert.New(t)
+	db := database.GetTestingDBSingleton()
+	if db.DriverName != "mysql" {
+		t.Skip("Skip as SQLFLOW_TEST_DB is not MySQL")
+	}
+	_, addr, stop := startFakeModelZoo(t)
+	defer stop()
+
+	cwd := mockWorkDir(t)
+	defer os.RemoveAll(cwd)
+	session := database.GetSessionFromTestingDB()
+	name := "sqlflow_models.my_published_model"
+	db.Exec(fmt.Sprintf("DELETE FROM %s WHERE name = ?", modelZooTable), name)
+	db.Exec(fmt.Sprintf("DELETE FROM %s WHERE name = ?", modelTagTable), name)
+	version, e := New(cwd, testTrainSelect).SaveVersion(name, session, nil)
+	a.NoError(e)
+	a.NoError(TagVersion(name, version, "v0.1", db))
+	a.NoError(Publish(addr, name, "v0.1", db, nil))
+	a.Equal(ErrModelNotFound, Publish(addr, name, "v0.2", db, nil))
+
+	dst, e := ioutil.TempDir("", "sqlflow_model_dst")
+	a.NoError(e)
+	defer os.RemoveAll(dst)
+	m, release, e := Pull(addr, name, "v0.1", dst)
+	a.NoError(e)
+	a.Equal(testTrainSelect, m.TrainSelect)
+	a.Equal(&Release{}, release)
+	b, e := ioutil.ReadFile(filepath.Join(dst, "my_dnn_model", "saved_model.pb"))
+	a.NoError(e)
+	a.Equal("graph", string(b))
+}
@@ -0,0 +1,73 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	pb "sqlflow.org/sqlflow/pkg/proto"
+)
+
+// PutFile copies the local file src to the object at uri, which is a
+// local file like file:///path/to/file, or in a registered Storage like
+// oss://bucket/key. It saves the outputs other than the models, like the
+// figures of TO EXPLAIN, to where the models could be saved.
+func PutFile(uri, src string, session *pb.Session) error {
+	scheme, p, e := parseModelURI(uri)
+	if e != nil {
+		return e
+	}
+	f, e := os.Open(src)
+	if e != nil {
+		return e
+	}
+	defer f.Close()
+	switch scheme {
+	case "":
+		return fmt.Errorf("cannot put the file %s into the table %s, the URI should be like file:///path or oss://bucket/key", src, uri)
+	case "file":
+		return copyFile(p, f)
+	}
+	s, _ := storageOf(scheme)
+	return s.Put(context.Background(), p, session, func(w io.Writer) error {
+		_, e := io.Copy(w, f)
+		return e
+	})
+}
+
+// copyFile writes what r reads to the file at path, creating the parent
+// directories if they don't exist.
+func copyFile(path string, r io.Reader) (e error) {
+	if e := os.MkdirAll(filepath.Dir(path), 0755); e != nil {
+		return e
+	}
+	f, e := os.Create(path)
+	if e != nil {
+		return e
+	}
+	defer func() {
+		f.Close()
+		if e != nil {
+			os.Remove(path)
+		}
+	}()
+	if _, e = io.Copy(f, r); e != nil {
+		return e
+	}
+	return f.Close()
+}
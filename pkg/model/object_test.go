@@ -0,0 +1,46 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPutFile(t *testing.T) {
+	a := assert.New(t)
+	dir, e := ioutil.TempDir("", "sqlflow_model_object")
+	a.NoError(e)
+	defer os.RemoveAll(dir)
+	src := filepath.Join(dir, "summary.png")
+	a.NoError(ioutil.WriteFile(src, []byte("png"), 0644))
+
+	dst := filepath.Join(dir, "explain", "my_model", "summary.png")
+	a.NoError(PutFile("file://"+dst, src, nil))
+	b, e := ioutil.ReadFile(dst)
+	a.NoError(e)
+	a.Equal("png", string(b))
+
+	s := &memStorage{objects: map[string][]byte{}}
+	RegisterStorage("memobject", s)
+	a.NoError(PutFile("memobject://bucket/explain/summary.png", src, nil))
+	a.Equal("png", string(s.objects["bucket/explain/summary.png"]))
+
+	a.Error(PutFile("my_db.my_table", src, nil))
+	a.Error(PutFile("file://"+dst, filepath.Join(dir, "no_such_file"), nil))
+}
@@ -0,0 +1,161 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"compress/gzip"
+	"time"
+
+	"sqlflow.org/sqlflow/pkg/log"
+)
+
+// Options tunes how a model is saved and loaded. The zero value keeps the
+// default behavior.
+type Options struct {
+	// CompressionLevel is the compress/gzip level of the tarball, like
+	// gzip.BestSpeed or gzip.BestCompression. Zero means
+	// gzip.DefaultCompression.
+	CompressionLevel int
+	// TempDir is where Load extracts the model before moving it into
+	// the destination directory. If it is on another filesystem than
+	// the destination, Load copies the extracted files instead, which
+	// is slower. "" means a staging directory inside the destination
+	// directory.
+	TempDir string
+	// KeepVersions is the number of latest versions SaveVersion keeps.
+	// Zero keeps all versions.
+	KeepVersions int
+	// EncryptionKey is the 256-bit AES key to encrypt the tarball in
+	// Save and to decrypt it in Load. nil means the key in pb.Session
+	// or the environment variable SQLFLOW_MODEL_ENCRYPTION_KEY, which
+	// is base64-encoded, or like kms://name/key-id for the master key
+	// key-id in the KeyManager registered as name.
+	EncryptionKey []byte
+	// keyURI is the master key of the KMS resolved by withEncryptionKey.
+	keyURI string
+	// MaxModelSize is the maximum number of bytes Save writes to the
+	// storage. Save fails once the model exceeds it, and removes what
+	// has been written. Zero means unlimited.
+	MaxModelSize int64
+	// Retries is the number of times saving to or loading from a
+	// database is retried after a transient error. Zero means two
+	// retries, and a negative number disables retrying.
+	Retries int
+	// RetryDelay is the delay before the first retry, which doubles for
+	// each following retry. Zero means 500ms.
+	RetryDelay time.Duration
+	// Progress, if not nil, is called as Save and Load stream the
+	// tarball. Save reports the bytes read from the files in the working
+	// directory, and total is their size. Load reports the bytes read
+	// from the storage, and total is the size of the saved model, or -1
+	// if it's unknown. For the sqlfs table, the sizes are of the
+	// base64-encoded payload.
+	Progress func(processed, total int64)
+	// Logger, if not nil, logs the start and the end of Save and Load,
+	// with the model URI, the sizes of the saved model, and the duration
+	// as fields. nil means no logging.
+	Logger *log.Logger
+	// Dedup, if true, makes Save split the files in the working
+	// directory into chunks keyed by their SHA-256 sums, and store only
+	// the chunks not stored before, so saving a model again uploads
+	// nearly nothing if most files are unchanged. The chunks are shared
+	// by the models in the same directory or database, and Delete
+	// leaves them in place. Only the local filesystem and sqlfs tables
+	// in MySQL are supported, and it doesn't work with EncryptionKey.
+	Dedup bool
+	// ChunkSize is the number of bytes Save writes to each row of a
+	// sqlfs table. Zero means the default of sqlfs.Create. Load reports
+	// the exact progress of a model saved with the same ChunkSize.
+	ChunkSize int
+}
+
+func (o *Options) compressionLevel() int {
+	if o == nil || o.CompressionLevel == 0 {
+		return gzip.DefaultCompression
+	}
+	return o.CompressionLevel
+}
+
+func (o *Options) tempDir() string {
+	if o == nil {
+		return ""
+	}
+	return o.TempDir
+}
+
+func (o *Options) encryptionKey() []byte {
+	if o == nil {
+		return nil
+	}
+	return o.EncryptionKey
+}
+
+func (o *Options) kmsKeyURI() string {
+	if o == nil {
+		return ""
+	}
+	return o.keyURI
+}
+
+// encrypted returns true if the model is encrypted by the key, or by a
+// data key from the KMS.
+func (o *Options) encrypted() bool {
+	return o.encryptionKey() != nil || o.kmsKeyURI() != ""
+}
+
+func (o *Options) maxModelSize() int64 {
+	if o == nil {
+		return 0
+	}
+	return o.MaxModelSize
+}
+
+func (o *Options) retries() int {
+	if o == nil || o.Retries == 0 {
+		return defaultRetries
+	}
+	return o.Retries
+}
+
+func (o *Options) retryDelay() time.Duration {
+	if o == nil || o.RetryDelay == 0 {
+		return defaultRetryDelay
+	}
+	return o.RetryDelay
+}
+
+func (o *Options) chunkSize() int {
+	if o == nil {
+		return 0
+	}
+	return o.ChunkSize
+}
+
+func (o *Options) dedup() bool {
+	return o != nil && o.Dedup
+}
+
+// event logs msg with fields to the Logger, at the error level with the
+// field "error" if e is not nil.
+func (o *Options) event(msg string, fields log.Fields, e error) {
+	if o == nil || o.Logger == nil {
+		return
+	}
+	entry := o.Logger.WithFields(fields)
+	if e != nil {
+		entry.WithField("error", e).Error(msg)
+		return
+	}
+	entry.Info(msg)
+}
@@ -0,0 +1,214 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+
+	pb "sqlflow.org/sqlflow/pkg/proto"
+)
+
+// ossPartSize is the size of the parts of the multipart upload, which
+// limits the model size to 10,000 parts.
+const ossPartSize = 4 << 20
+
+// ossCredentials returns the endpoint, access key ID, and access key
+// secret in session. A nil session means the environment variables
+// MakeSessionFromEnv reads them from.
+func ossCredentials(session *pb.Session) (endpoint, id, secret string) {
+	if session == nil {
+		return os.Getenv("SQLFLOW_OSS_MODEL_ENDPOINT"), os.Getenv("SQLFLOW_OSS_AK"), os.Getenv("SQLFLOW_OSS_SK")
+	}
+	return session.OssEndpoint, session.OssAccessKeyId, session.OssAccessKeySecret
+}
+
+// newOSSBucket returns bucket at the endpoint in session, authenticated
+// by the access key in session.
+func newOSSBucket(bucket string, session *pb.Session) (*oss.Bucket, error) {
+	endpoint, id, secret := ossCredentials(session)
+	if endpoint == "" || id == "" || secret == "" {
+		return nil, fmt.Errorf("oss endpoint, access key id, and access key secret should all be set to access oss://%s", bucket)
+	}
+	cli, e := oss.New(endpoint, id, secret)
+	if e != nil {
+		return nil, fmt.Errorf("cannot create oss client of %s: %v", endpoint, e)
+	}
+	b, e := cli.Bucket(bucket)
+	if e != nil {
+		return nil, fmt.Errorf("invalid oss bucket %q: %v", bucket, e)
+	}
+	return b, nil
+}
+
+// ossError returns a short error message of e without the request ID and
+// the endpoint in the message of oss.ServiceError.
+func ossError(op, bucket, key string, e error) error {
+	se, ok := e.(oss.ServiceError)
+	if !ok {
+		return fmt.Errorf("%s oss://%s/%s failed: %v", op, bucket, key, e)
+	}
+	if se.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("%s oss://%s/%s failed: oss authentication failed (%s), check the access key", op, bucket, key, se.Code)
+	}
+	return fmt.Errorf("%s oss://%s/%s failed: %s (HTTP %d)", op, bucket, key, se.Code, se.StatusCode)
+}
+
+// ossStorage stores the models in the objects oss://bucket/key.
+type ossStorage struct{}
+
+func (ossStorage) Put(ctx context.Context, p string, session *pb.Session, fn func(io.Writer) error) error {
+	bucket, key := splitBucketKey(p)
+	return writeOSS(ctx, bucket, key, session, fn)
+}
+
+func (ossStorage) Get(ctx context.Context, p string, session *pb.Session, fn func(io.Reader) error) error {
+	bucket, key := splitBucketKey(p)
+	return readOSS(ctx, bucket, key, session, fn)
+}
+
+func (ossStorage) Stat(ctx context.Context, p string, session *pb.Session) (int64, error) {
+	bucket, key := splitBucketKey(p)
+	return statOSS(bucket, key, session)
+}
+
+func (ossStorage) Delete(ctx context.Context, p string, session *pb.Session) error {
+	bucket, key := splitBucketKey(p)
+	return deleteOSS(bucket, key, session)
+}
+
+// writeOSS uploads what fn writes to the object oss://bucket/key.
+func writeOSS(ctx context.Context, bucket, key string, session *pb.Session, fn func(io.Writer) error) error {
+	if bucket == "" || key == "" {
+		return fmt.Errorf("oss modelURI should be oss://bucket/key, got bucket %q, key %q", bucket, key)
+	}
+	b, e := newOSSBucket(bucket, session)
+	if e != nil {
+		return e
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(fn(pw))
+	}()
+	e = uploadOSS(ctx, b, key, pr)
+	// Unblock the writing goroutine if the upload stopped early.
+	pr.CloseWithError(e)
+	if e != nil {
+		return ossError("upload model to", bucket, key, e)
+	}
+	return nil
+}
+
+// uploadOSS uploads r to the object key by a multipart upload, so the
+// size of r needn't be known ahead. It aborts the upload if anything
+// fails, so there is no half-written object.
+func uploadOSS(ctx context.Context, b *oss.Bucket, key string, r io.Reader) (e error) {
+	imur, e := b.InitiateMultipartUpload(key)
+	if e != nil {
+		return e
+	}
+	defer func() {
+		if e != nil {
+			b.AbortMultipartUpload(imur)
+		}
+	}()
+	parts := []oss.UploadPart{}
+	buf := make([]byte, ossPartSize)
+	for {
+		if e := ctx.Err(); e != nil {
+			return e
+		}
+		n, e := io.ReadFull(r, buf)
+		if n > 0 {
+			part, e := b.UploadPart(imur, bytes.NewReader(buf[:n]), int64(n), len(parts)+1)
+			if e != nil {
+				return e
+			}
+			parts = append(parts, part)
+		}
+		if e == io.EOF || e == io.ErrUnexpectedEOF {
+			break
+		}
+		if e != nil {
+			return e
+		}
+	}
+	_, e = b.CompleteMultipartUpload(imur, parts)
+	return e
+}
+
+// readOSS calls fn with the content of the object oss://bucket/key.
+func readOSS(ctx context.Context, bucket, key string, session *pb.Session, fn func(io.Reader) error) error {
+	if bucket == "" || key == "" {
+		return fmt.Errorf("oss modelURI should be oss://bucket/key, got bucket %q, key %q", bucket, key)
+	}
+	b, e := newOSSBucket(bucket, session)
+	if e != nil {
+		return e
+	}
+	body, e := b.GetObject(key)
+	if e != nil {
+		return ossError("download model from", bucket, key, e)
+	}
+	defer body.Close()
+	// The OSS SDK doesn't take a context, stop reading once ctx is done.
+	return fn(&contextReader{ctx, body})
+}
+
+// statOSS returns the size of the object oss://bucket/key.
+func statOSS(bucket, key string, session *pb.Session) (int64, error) {
+	if bucket == "" || key == "" {
+		return 0, fmt.Errorf("oss modelURI should be oss://bucket/key, got bucket %q, key %q", bucket, key)
+	}
+	b, e := newOSSBucket(bucket, session)
+	if e != nil {
+		return 0, e
+	}
+	h, e := b.GetObjectDetailedMeta(key)
+	if e != nil {
+		if se, ok := e.(oss.ServiceError); ok && se.StatusCode == http.StatusNotFound {
+			return 0, ErrModelNotFound
+		}
+		return 0, ossError("stat", bucket, key, e)
+	}
+	size, e := strconv.ParseInt(h.Get("Content-Length"), 10, 64)
+	if e != nil {
+		return 0, fmt.Errorf("stat oss://%s/%s failed: invalid Content-Length: %v", bucket, key, e)
+	}
+	return size, nil
+}
+
+// deleteOSS deletes the object oss://bucket/key.
+func deleteOSS(bucket, key string, session *pb.Session) error {
+	// DeleteObject succeeds even if the object doesn't exist.
+	if _, e := statOSS(bucket, key, session); e != nil {
+		return e
+	}
+	b, e := newOSSBucket(bucket, session)
+	if e != nil {
+		return e
+	}
+	if e := b.DeleteObject(key); e != nil {
+		return ossError("delete", bucket, key, e)
+	}
+	return nil
+}
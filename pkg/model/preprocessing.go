@@ -0,0 +1,270 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"sqlflow.org/sqlflow/pkg/ir"
+)
+
+// PreprocessingFile is the preprocessing spec saved in the working
+// directory, and so the tarball, of a model trained by a TrainStmt.
+const PreprocessingFile = "preprocessing.json"
+
+// Preprocessing is the feature columns of a TrainStmt after the feature
+// derivation, with the vocabularies, shapes, bucket boundaries, and other
+// statistics computed from the training data. PREDICT, EVALUATE, and
+// EXPLAIN reapply it instead of deriving the columns again from the
+// training table, which may have changed since the training.
+type Preprocessing struct {
+	Features map[string][]*Transform `json:"features"`
+	Label    *Transform              `json:"label,omitempty"`
+}
+
+// Transform is a feature column in Preprocessing. Type is the name of the
+// column in the COLUMN clause, like "numeric", "bucket", or "embedding",
+// and decides which of the other fields are set.
+type Transform struct {
+	Type  string        `json:"type"`
+	Field *ir.FieldDesc `json:"field,omitempty"` // Field is set except for "cross" and "key".
+	// Source is the column that a "bucket", "embedding", or "indicator"
+	// column transforms, nil for EMBEDDING(col_name, ...) and
+	// INDICATOR(col_name) before the derivation.
+	Source *Transform `json:"source,omitempty"`
+	// Keys is the columns of a "cross" column. A key that's a column
+	// name has the Type "key" and the Name.
+	Keys        []*Transform `json:"keys,omitempty"`
+	Boundaries  []int        `json:"boundaries,omitempty"`
+	BucketSize  int64        `json:"bucket_size,omitempty"`
+	Dimension   int          `json:"dimension,omitempty"`
+	Combiner    string       `json:"combiner,omitempty"`
+	Initializer string       `json:"initializer,omitempty"`
+	Name        string       `json:"name,omitempty"`
+}
+
+// PreprocessingOf returns the preprocessing spec of the feature and label
+// columns of trainStmt.
+func PreprocessingOf(trainStmt *ir.TrainStmt) (*Preprocessing, error) {
+	p := &Preprocessing{Features: map[string][]*Transform{}}
+	for group, fcs := range trainStmt.Features {
+		ts := []*Transform{}
+		for _, fc := range fcs {
+			t, e := transformOf(fc)
+			if e != nil {
+				return nil, e
+			}
+			ts = append(ts, t)
+		}
+		p.Features[group] = ts
+	}
+	if trainStmt.Label != nil {
+		t, e := transformOf(trainStmt.Label)
+		if e != nil {
+			return nil, e
+		}
+		p.Label = t
+	}
+	return p, nil
+}
+
+func transformOf(fc ir.FeatureColumn) (*Transform, error) {
+	switch c := fc.(type) {
+	case *ir.NumericColumn:
+		return &Transform{Type: "numeric", Field: c.FieldDesc}, nil
+	case *ir.BucketColumn:
+		src, e := transformOf(c.SourceColumn)
+		if e != nil {
+			return nil, e
+		}
+		return &Transform{Type: "bucket", Source: src, Boundaries: c.Boundaries}, nil
+	case *ir.CrossColumn:
+		t := &Transform{Type: "cross", BucketSize: int64(c.HashBucketSize)}
+		for _, k := range c.Keys {
+			switch k := k.(type) {
+			case string:
+				t.Keys = append(t.Keys, &Transform{Type: "key", Name: k})
+			case ir.FeatureColumn:
+				kt, e := transformOf(k)
+				if e != nil {
+					return nil, e
+				}
+				t.Keys = append(t.Keys, kt)
+			default:
+				return nil, fmt.Errorf("unsupported key %v of the cross column", k)
+			}
+		}
+		return t, nil
+	case *ir.CategoryIDColumn:
+		return &Transform{Type: "category_id", Field: c.FieldDesc, BucketSize: c.BucketSize}, nil
+	case *ir.CategoryHashColumn:
+		return &Transform{Type: "category_hash", Field: c.FieldDesc, BucketSize: c.BucketSize}, nil
+	case *ir.SeqCategoryIDColumn:
+		return &Transform{Type: "seq_category_id", Field: c.FieldDesc, BucketSize: int64(c.BucketSize)}, nil
+	case *ir.EmbeddingColumn:
+		t := &Transform{Type: "embedding", Dimension: c.Dimension, Combiner: c.Combiner, Initializer: c.Initializer, Name: c.Name}
+		if c.CategoryColumn != nil {
+			src, e := transformOf(c.CategoryColumn)
+			if e != nil {
+				return nil, e
+			}
+			t.Source = src
+		}
+		return t, nil
+	case *ir.IndicatorColumn:
+		t := &Transform{Type: "indicator", Name: c.Name}
+		if c.CategoryColumn != nil {
+			src, e := transformOf(c.CategoryColumn)
+			if e != nil {
+				return nil, e
+			}
+			t.Source = src
+		}
+		return t, nil
+	}
+	return nil, fmt.Errorf("unsupported feature column type %T", fc)
+}
+
+// FeatureColumns returns the feature and label columns to set to
+// TrainStmt.Features and TrainStmt.Label.
+func (p *Preprocessing) FeatureColumns() (map[string][]ir.FeatureColumn, ir.FeatureColumn, error) {
+	features := map[string][]ir.FeatureColumn{}
+	for group, ts := range p.Features {
+		fcs := []ir.FeatureColumn{}
+		for _, t := range ts {
+			fc, e := t.FeatureColumn()
+			if e != nil {
+				return nil, nil, e
+			}
+			fcs = append(fcs, fc)
+		}
+		features[group] = fcs
+	}
+	var label ir.FeatureColumn
+	if p.Label != nil {
+		fc, e := p.Label.FeatureColumn()
+		if e != nil {
+			return nil, nil, e
+		}
+		label = fc
+	}
+	return features, label, nil
+}
+
+// FeatureColumn returns the feature column t describes.
+func (t *Transform) FeatureColumn() (ir.FeatureColumn, error) {
+	switch t.Type {
+	case "numeric", "category_id", "category_hash", "seq_category_id":
+		if t.Field == nil {
+			return nil, fmt.Errorf("no field of the %s column", t.Type)
+		}
+	}
+	switch t.Type {
+	case "numeric":
+		return &ir.NumericColumn{FieldDesc: t.Field}, nil
+	case "bucket":
+		src, e := t.source()
+		if e != nil {
+			return nil, e
+		}
+		nc, ok := src.(*ir.NumericColumn)
+		if !ok {
+			return nil, fmt.Errorf("the source of the bucket column must be a numeric column, got %s", t.Source.Type)
+		}
+		return &ir.BucketColumn{SourceColumn: nc, Boundaries: t.Boundaries}, nil
+	case "cross":
+		c := &ir.CrossColumn{HashBucketSize: int(t.BucketSize)}
+		for _, k := range t.Keys {
+			if k.Type == "key" {
+				c.Keys = append(c.Keys, k.Name)
+				continue
+			}
+			fc, e := k.FeatureColumn()
+			if e != nil {
+				return nil, e
+			}
+			c.Keys = append(c.Keys, fc)
+		}
+		return c, nil
+	case "category_id":
+		return &ir.CategoryIDColumn{FieldDesc: t.Field, BucketSize: t.BucketSize}, nil
+	case "category_hash":
+		return &ir.CategoryHashColumn{FieldDesc: t.Field, BucketSize: t.BucketSize}, nil
+	case "seq_category_id":
+		return &ir.SeqCategoryIDColumn{FieldDesc: t.Field, BucketSize: int(t.BucketSize)}, nil
+	case "embedding":
+		c := &ir.EmbeddingColumn{Dimension: t.Dimension, Combiner: t.Combiner, Initializer: t.Initializer, Name: t.Name}
+		if t.Source != nil {
+			src, e := t.source()
+			if e != nil {
+				return nil, e
+			}
+			c.CategoryColumn = src
+		}
+		return c, nil
+	case "indicator":
+		c := &ir.IndicatorColumn{Name: t.Name}
+		if t.Source != nil {
+			src, e := t.source()
+			if e != nil {
+				return nil, e
+			}
+			c.CategoryColumn = src
+		}
+		return c, nil
+	}
+	return nil, fmt.Errorf("unsupported feature column type %q", t.Type)
+}
+
+func (t *Transform) source() (ir.FeatureColumn, error) {
+	if t.Source == nil {
+		return nil, fmt.Errorf("no source column of the %s column", t.Type)
+	}
+	return t.Source.FeatureColumn()
+}
+
+// writePreprocessing writes the preprocessing spec of trainStmt to cwd.
+func writePreprocessing(cwd string, trainStmt *ir.TrainStmt) error {
+	p, e := PreprocessingOf(trainStmt)
+	if e != nil {
+		return e
+	}
+	b, e := json.MarshalIndent(p, "", "  ")
+	if e != nil {
+		return e
+	}
+	return ioutil.WriteFile(filepath.Join(cwd, PreprocessingFile), b, 0644)
+}
+
+// ReadPreprocessing returns the preprocessing spec of the model loaded to
+// cwd, or nil if the model was saved without a TrainStmt or by an older
+// version.
+func ReadPreprocessing(cwd string) (*Preprocessing, error) {
+	b, e := ioutil.ReadFile(filepath.Join(cwd, PreprocessingFile))
+	if os.IsNotExist(e) {
+		return nil, nil
+	}
+	if e != nil {
+		return nil, e
+	}
+	p := &Preprocessing{}
+	if e := json.Unmarshal(b, p); e != nil {
+		return nil, fmt.Errorf("invalid %s: %v", PreprocessingFile, e)
+	}
+	return p, nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sqlflow.org/sqlflow/pkg/ir"
+)
+
+func TestSaveAndReadPreprocessing(t *testing.T) {
+	a := assert.New(t)
+	cwd := mockWorkDir(t)
+	defer os.RemoveAll(cwd)
+	modelDir, e := ioutil.TempDir("", "sqlflow_model_dir")
+	a.NoError(e)
+	defer os.RemoveAll(modelDir)
+
+	age := &ir.NumericColumn{FieldDesc: &ir.FieldDesc{Name: "age", DType: ir.Float, Shape: []int{1}}}
+	city := &ir.CategoryIDColumn{
+		FieldDesc:  &ir.FieldDesc{Name: "city", DType: ir.String, Shape: []int{1}, Vocabulary: map[string]string{"a": "", "b": ""}, MaxID: 2},
+		BucketSize: 2,
+	}
+	review := &ir.CategoryIDColumn{
+		FieldDesc:  &ir.FieldDesc{Name: "review", DType: ir.Int, Shape: []int{8}, Vocabulary: map[string]string{"good": ""}, MaxID: 1, Tokenizer: "word"},
+		BucketSize: 2,
+	}
+	trainStmt := &ir.TrainStmt{
+		Estimator: "DNNClassifier",
+		Features: map[string][]ir.FeatureColumn{
+			"feature_columns": {
+				age,
+				&ir.BucketColumn{SourceColumn: age, Boundaries: []int{18, 65}},
+				&ir.CrossColumn{Keys: []interface{}{"gender", age}, HashBucketSize: 10},
+				&ir.EmbeddingColumn{CategoryColumn: city, Dimension: 4, Combiner: "sum", Name: "city"},
+				&ir.IndicatorColumn{CategoryColumn: &ir.CategoryHashColumn{FieldDesc: &ir.FieldDesc{Name: "tag", DType: ir.String, Shape: []int{1}}, BucketSize: 100}, Name: "tag"},
+				&ir.EmbeddingColumn{CategoryColumn: review, Dimension: 8, Combiner: "mean", Name: "review"},
+			},
+			"wide": {&ir.SeqCategoryIDColumn{FieldDesc: &ir.FieldDesc{Name: "clicks", DType: ir.Int, Shape: []int{5}}, BucketSize: 50}},
+		},
+		Label: &ir.NumericColumn{FieldDesc: &ir.FieldDesc{Name: "class", DType: ir.Int, Shape: []int{1}}},
+	}
+
+	modelURI := "file://" + filepath.Join(modelDir, "my_model")
+	a.NoError(New(cwd, testTrainSelect).Save(modelURI, trainStmt, nil))
+
+	dst, e := ioutil.TempDir("", "sqlflow_model_dst")
+	a.NoError(e)
+	defer os.RemoveAll(dst)
+	_, e = Load(modelURI, dst, nil)
+	a.NoError(e)
+	p, e := ReadPreprocessing(dst)
+	a.NoError(e)
+	a.NotNil(p)
+	features, label, e := p.FeatureColumns()
+	a.NoError(e)
+	a.Equal(trainStmt.Features, features)
+	a.Equal(trainStmt.Label, label)
+
+	_, e = (&Transform{Type: "bucket", Source: &Transform{Type: "category_id", Field: &ir.FieldDesc{Name: "city"}}}).FeatureColumn()
+	a.Error(e)
+	_, e = (&Transform{Type: "numeric"}).FeatureColumn()
+	a.Error(e)
+	_, e = (&Transform{Type: "unknown"}).FeatureColumn()
+	a.Error(e)
+}
+
+func TestReadPreprocessingOfOlderModels(t *testing.T) {
+	a := assert.New(t)
+	cwd, e := ioutil.TempDir("", "sqlflow_model_dst")
+	a.NoError(e)
+	defer os.RemoveAll(cwd)
+	p, e := ReadPreprocessing(cwd)
+	a.NoError(e)
+	a.Nil(p)
+
+	a.NoError(ioutil.WriteFile(filepath.Join(cwd, PreprocessingFile), []byte("{"), 0644))
+	_, e = ReadPreprocessing(cwd)
+	a.Error(e)
+}
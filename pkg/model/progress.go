@@ -0,0 +1,162 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"sqlflow.org/sqlflow/pkg/database"
+	"sqlflow.org/sqlflow/pkg/sqlfs"
+)
+
+// progress counts the bytes processed and reports them to
+// Options.Progress. A nil *progress reports nothing, so callers don't
+// check whether the callback is set.
+type progress struct {
+	fn    func(processed, total int64)
+	n     int64
+	total int64
+	// scale converts the bytes counted to the unit of total, nil means
+	// they are in the same unit.
+	scale func(int64) int64
+}
+
+// newProgress returns nil if opts has no Progress callback.
+func newProgress(opts *Options, total int64) *progress {
+	if opts == nil || opts.Progress == nil {
+		return nil
+	}
+	return &progress{fn: opts.Progress, total: total}
+}
+
+func (p *progress) add(n int) {
+	if p == nil || n == 0 {
+		return
+	}
+	p.n += int64(n)
+	if p.scale != nil {
+		p.fn(p.scale(p.n), p.total)
+		return
+	}
+	p.fn(p.n, p.total)
+}
+
+// done reports that all bytes are processed. The readers of a tarball
+// may stop before reading the padding at the end of the stream, so Load
+// calls it after the extraction succeeds.
+func (p *progress) done() {
+	if p == nil || p.total < 0 {
+		return
+	}
+	n := p.n
+	if p.scale != nil {
+		n = p.scale(n)
+	}
+	if n < p.total {
+		p.fn(p.total, p.total)
+	}
+}
+
+// progressReader reports the bytes read through it to p.
+type progressReader struct {
+	r io.Reader
+	p *progress
+}
+
+func (r *progressReader) Read(b []byte) (int, error) {
+	n, e := r.r.Read(b)
+	r.p.add(n)
+	return n, e
+}
+
+// withProgress returns r itself if p is nil.
+func withProgress(r io.Reader, p *progress) io.Reader {
+	if p == nil {
+		return r
+	}
+	return &progressReader{r: r, p: p}
+}
+
+// dbProgress returns the progress of loading the model in the sqlfs
+// table into cwd. The total is the length of the base64-encoded payload,
+// and so are the bytes reported as processed.
+func dbProgress(db *database.DB, table, cwd string, opts *Options) *progress {
+	if cwd == "" || opts == nil || opts.Progress == nil {
+		return nil
+	}
+	size, e := sqlfs.Size(db.DB, table)
+	if e != nil {
+		return newProgress(opts, -1)
+	}
+	p := newProgress(opts, size)
+	p.scale = func(n int64) int64 { return sqlfs.EncodedSize(n, opts.chunkSize()) }
+	return p
+}
+
+// saveProgress returns the progress of archiving workDir, whose total is
+// the size of the files in workDir, or -1 if it can't be computed.
+func (m *Model) saveProgress(opts *Options) *progress {
+	if opts == nil || opts.Progress == nil {
+		return nil
+	}
+	total, e := dirSize(m.workDir, map[string]bool{})
+	if e != nil {
+		total = -1
+	}
+	return newProgress(opts, total)
+}
+
+// dirSize returns the total size of the regular files in dir. It
+// follows symlinks like addDirToTar does, so the size matches what
+// tarGzDir archives.
+func dirSize(dir string, ancestors map[string]bool) (int64, error) {
+	real, e := filepath.EvalSymlinks(dir)
+	if e != nil {
+		return 0, e
+	}
+	if ancestors[real] {
+		return 0, fmt.Errorf("symlink cycle at %s", dir)
+	}
+	ancestors[real] = true
+	defer delete(ancestors, real)
+
+	fis, e := ioutil.ReadDir(dir)
+	if e != nil {
+		return 0, e
+	}
+	size := int64(0)
+	for _, fi := range fis {
+		p := filepath.Join(dir, fi.Name())
+		if fi.Mode()&os.ModeSymlink != 0 {
+			if fi, e = os.Stat(p); e != nil {
+				return 0, e
+			}
+		}
+		switch {
+		case fi.IsDir():
+			n, e := dirSize(p, ancestors)
+			if e != nil {
+				return 0, e
+			}
+			size += n
+		case fi.Mode().IsRegular():
+			size += fi.Size()
+		}
+	}
+	return size, nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+const (
+	defaultRetries    = 2
+	defaultRetryDelay = 500 * time.Millisecond
+)
+
+// retryableErrors are the messages of transient database errors, like
+// dropped connections. Errors from sqlfs are wrapped by fmt.Errorf, so
+// they are matched by messages. Others, like a missing table, are
+// permanent.
+var retryableErrors = []string{
+	"bad connection",     // database/sql/driver.ErrBadConn
+	"invalid connection", // github.com/go-sql-driver/mysql.ErrInvalidConn
+	"server has gone away",
+	"connection refused",
+	"connection reset",
+	"broken pipe",
+	"i/o timeout",
+}
+
+func isRetryable(e error) bool {
+	msg := e.Error()
+	for _, r := range retryableErrors {
+		if strings.Contains(msg, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// retry calls fn until it succeeds or returns a permanent error, at most
+// opts.retries() more times after the first failure. The delay between
+// attempts starts from opts.retryDelay() and doubles each time.
+func retry(ctx context.Context, opts *Options, fn func() error) error {
+	delay := opts.retryDelay()
+	for i := 0; ; i++ {
+		e := fn()
+		if e == nil || i >= opts.retries() || !isRetryable(e) {
+			return e
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// flakyDB fails the first failures calls with err.
+type flakyDB struct {
+	failures int
+	calls    int
+	err      error
+}
+
+func (f *flakyDB) exec() error {
+	f.calls++
+	if f.calls <= f.failures {
+		return fmt.Errorf("cannot flush to table t: %v", f.err)
+	}
+	return nil
+}
+
+func TestRetry(t *testing.T) {
+	a := assert.New(t)
+	ctx := context.Background()
+	opts := &Options{RetryDelay: time.Millisecond}
+
+	db := &flakyDB{failures: 2, err: driver.ErrBadConn}
+	a.NoError(retry(ctx, opts, db.exec))
+	a.Equal(3, db.calls)
+
+	db = &flakyDB{failures: 3, err: driver.ErrBadConn}
+	a.Error(retry(ctx, opts, db.exec))
+	a.Equal(3, db.calls)
+
+	// Permanent errors are not retried.
+	db = &flakyDB{failures: 1, err: fmt.Errorf("Error 1146: Table 'sqlflow_models.t' doesn't exist")}
+	a.Error(retry(ctx, opts, db.exec))
+	a.Equal(1, db.calls)
+
+	db = &flakyDB{failures: 1, err: driver.ErrBadConn}
+	a.Error(retry(ctx, &Options{Retries: -1}, db.exec))
+	a.Equal(1, db.calls)
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	db = &flakyDB{failures: 1, err: driver.ErrBadConn}
+	a.Equal(context.Canceled, retry(ctx, opts, db.exec))
+	a.Equal(1, db.calls)
+}
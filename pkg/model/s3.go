@@ -0,0 +1,184 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	awssession "github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+
+	pb "sqlflow.org/sqlflow/pkg/proto"
+)
+
+// splitBucketKey splits "bucket/path/to/model" into the bucket name and
+// the object key.
+func splitBucketKey(uriPath string) (string, string) {
+	parts := strings.SplitN(uriPath, "/", 2)
+	if len(parts) < 2 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+// s3PartSize is the part size of the multipart upload. S3 allows 10,000
+// parts for an object, so the default 5MB of s3manager limits a model to
+// about 48GB, and 16MB raises the limit to about 156GB.
+const s3PartSize = 16 << 20
+
+// s3Credentials returns the region, access key ID, and secret access key
+// in session. A nil session means the environment variables
+// MakeSessionFromEnv reads them from.
+func s3Credentials(session *pb.Session) (region, id, secret string) {
+	if session == nil {
+		return os.Getenv("SQLFLOW_S3_REGION"), os.Getenv("SQLFLOW_S3_ACCESS_KEY_ID"), os.Getenv("SQLFLOW_S3_SECRET_ACCESS_KEY")
+	}
+	return session.S3Region, session.S3AccessKeyId, session.S3SecretAccessKey
+}
+
+// newS3Session creates an AWS session with the region and credentials in
+// session. Any of them left empty falls back to the standard AWS
+// environment variables, shared config files, or the IAM role.
+func newS3Session(session *pb.Session) (*awssession.Session, error) {
+	cfg := aws.NewConfig()
+	region, id, secret := s3Credentials(session)
+	if region != "" {
+		cfg = cfg.WithRegion(region)
+	}
+	if id != "" && secret != "" {
+		cfg = cfg.WithCredentials(credentials.NewStaticCredentials(id, secret, ""))
+	}
+	return awssession.NewSessionWithOptions(awssession.Options{
+		Config:            *cfg,
+		SharedConfigState: awssession.SharedConfigEnable,
+	})
+}
+
+// s3Storage stores the models in the objects s3://bucket/key.
+type s3Storage struct{}
+
+func (s3Storage) Put(ctx context.Context, p string, session *pb.Session, fn func(io.Writer) error) error {
+	bucket, key := splitBucketKey(p)
+	return writeS3(ctx, bucket, key, session, fn)
+}
+
+func (s3Storage) Get(ctx context.Context, p string, session *pb.Session, fn func(io.Reader) error) error {
+	bucket, key := splitBucketKey(p)
+	return readS3(ctx, bucket, key, session, fn)
+}
+
+func (s3Storage) Stat(ctx context.Context, p string, session *pb.Session) (int64, error) {
+	bucket, key := splitBucketKey(p)
+	return statS3(ctx, bucket, key, session)
+}
+
+func (s3Storage) Delete(ctx context.Context, p string, session *pb.Session) error {
+	bucket, key := splitBucketKey(p)
+	return deleteS3(ctx, bucket, key, session)
+}
+
+// writeS3 uploads what fn writes to the object s3://bucket/key.
+func writeS3(ctx context.Context, bucket, key string, session *pb.Session, fn func(io.Writer) error) error {
+	if bucket == "" || key == "" {
+		return fmt.Errorf("s3 modelURI should be s3://bucket/key, got bucket %q, key %q", bucket, key)
+	}
+	sess, e := newS3Session(session)
+	if e != nil {
+		return fmt.Errorf("cannot create s3 session: %v", e)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(fn(pw))
+	}()
+	uploader := s3manager.NewUploader(sess, func(u *s3manager.Uploader) {
+		u.PartSize = s3PartSize
+	})
+	_, e = uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   pr,
+	})
+	// Unblock the writing goroutine if the upload stopped early.
+	pr.CloseWithError(e)
+	if e != nil {
+		return fmt.Errorf("upload model to s3://%s/%s failed: %v", bucket, key, e)
+	}
+	// The upload finishes after reading io.EOF, which happens after fn
+	// returns.
+	return nil
+}
+
+// readS3 calls fn with the content of the object s3://bucket/key.
+func readS3(ctx context.Context, bucket, key string, session *pb.Session, fn func(io.Reader) error) error {
+	if bucket == "" || key == "" {
+		return fmt.Errorf("s3 modelURI should be s3://bucket/key, got bucket %q, key %q", bucket, key)
+	}
+	sess, e := newS3Session(session)
+	if e != nil {
+		return fmt.Errorf("cannot create s3 session: %v", e)
+	}
+	out, e := s3.New(sess).GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if e != nil {
+		return fmt.Errorf("download model from s3://%s/%s failed: %v", bucket, key, e)
+	}
+	defer out.Body.Close()
+	return fn(out.Body)
+}
+
+// statS3 returns the size of the object s3://bucket/key.
+func statS3(ctx context.Context, bucket, key string, session *pb.Session) (int64, error) {
+	if bucket == "" || key == "" {
+		return 0, fmt.Errorf("s3 modelURI should be s3://bucket/key, got bucket %q, key %q", bucket, key)
+	}
+	sess, e := newS3Session(session)
+	if e != nil {
+		return 0, fmt.Errorf("cannot create s3 session: %v", e)
+	}
+	out, e := s3.New(sess).HeadObjectWithContext(ctx, &s3.HeadObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	if e != nil {
+		if ae, ok := e.(awserr.Error); ok && (ae.Code() == "NotFound" || ae.Code() == s3.ErrCodeNoSuchKey) {
+			return 0, ErrModelNotFound
+		}
+		return 0, fmt.Errorf("stat s3://%s/%s failed: %v", bucket, key, e)
+	}
+	return aws.Int64Value(out.ContentLength), nil
+}
+
+// deleteS3 deletes the object s3://bucket/key.
+func deleteS3(ctx context.Context, bucket, key string, session *pb.Session) error {
+	// DeleteObject succeeds for absent keys, so check the existence first.
+	if _, e := statS3(ctx, bucket, key, session); e != nil {
+		return e
+	}
+	sess, e := newS3Session(session)
+	if e != nil {
+		return fmt.Errorf("cannot create s3 session: %v", e)
+	}
+	if _, e := s3.New(sess).DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)}); e != nil {
+		return fmt.Errorf("delete s3://%s/%s failed: %v", bucket, key, e)
+	}
+	return nil
+}
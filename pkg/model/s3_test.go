@@ -0,0 +1,53 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+)
+
+func TestS3AndGCSCredentials(t *testing.T) {
+	a := assert.New(t)
+	for k, v := range map[string]string{
+		"SQLFLOW_S3_REGION":            "us-west-2",
+		"SQLFLOW_S3_ACCESS_KEY_ID":     "id",
+		"SQLFLOW_S3_SECRET_ACCESS_KEY": "secret",
+		"SQLFLOW_GCS_CREDENTIALS_FILE": "/etc/gcs.json",
+	} {
+		defer os.Setenv(k, os.Getenv(k))
+		os.Setenv(k, v)
+	}
+
+	// Load passes a nil session, which means the environment variables.
+	region, id, secret := s3Credentials(nil)
+	a.Equal("us-west-2", region)
+	a.Equal("id", id)
+	a.Equal("secret", secret)
+	a.Equal("/etc/gcs.json", gcsCredentialsFile(nil))
+
+	session := &pb.Session{S3Region: "eu-central-1", GcsCredentialsFile: "/tmp/gcs.json"}
+	region, id, secret = s3Credentials(session)
+	a.Equal("eu-central-1", region)
+	a.Equal("", id)
+	a.Equal("", secret)
+	a.Equal("/tmp/gcs.json", gcsCredentialsFile(session))
+
+	sess, e := newS3Session(nil)
+	a.NoError(e)
+	a.Equal("us-west-2", *sess.Config.Region)
+}
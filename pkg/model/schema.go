@@ -0,0 +1,236 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"sqlflow.org/sqlflow/pkg/ir"
+)
+
+// Schema describes the columns of the training table that a model reads,
+// so a prediction can check its input table against the model.
+type Schema struct {
+	Features []Field `json:"features"`
+	Label    *Field  `json:"label,omitempty"` // nil if the model has no label, like a clustering model
+}
+
+// Field describes a column of the training table.
+type Field struct {
+	Name  string `json:"name"`
+	DType string `json:"dtype"` // "int", "float", or "string"
+	Shape []int  `json:"shape,omitempty"`
+	// Group is the key of the feature column in ir.TrainStmt.Features,
+	// like "feature_columns". It's "" for the label.
+	Group    string `json:"group,omitempty"`
+	IsSparse bool   `json:"is_sparse,omitempty"`
+	// Type is the database type of the column unified by the feature
+	// derivation, like "FLOAT", or "" if the model is saved by an older
+	// version or without the derivation.
+	Type string `json:"type,omitempty"`
+	// Categories is the sorted vocabulary of a categorical column of
+	// strings, like ["FEMALE", "MALE"]. The values of the column not in
+	// it are the unseen categories at the prediction.
+	Categories []string `json:"categories,omitempty"`
+}
+
+func dtypeName(dtype int) string {
+	switch dtype {
+	case ir.Int:
+		return "int"
+	case ir.Float:
+		return "float"
+	case ir.String:
+		return "string"
+	}
+	return fmt.Sprintf("unknown(%d)", dtype)
+}
+
+func newField(fd *ir.FieldDesc, group string, columnTypes map[string]string) Field {
+	f := Field{Name: fd.Name, DType: dtypeName(fd.DType), Shape: fd.Shape, Group: group, IsSparse: fd.IsSparse, Type: columnTypes[fd.Name]}
+	if fd.DType == ir.String && fd.Delimiter == "" && fd.Tokenizer == "" {
+		for v := range fd.Vocabulary {
+			f.Categories = append(f.Categories, v)
+		}
+		sort.Strings(f.Categories)
+	}
+	return f
+}
+
+// schemaOf returns the schema of the columns used by trainStmt. The
+// features are in the order of the sorted keys of trainStmt.Features and
+// then the order in the COLUMN clause. A column used by several feature
+// columns of a group appears once.
+func schemaOf(trainStmt *ir.TrainStmt) *Schema {
+	s := &Schema{Features: []Field{}}
+	groups := []string{}
+	for group := range trainStmt.Features {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	for _, group := range groups {
+		seen := map[string]bool{}
+		for _, fc := range trainStmt.Features[group] {
+			for _, fd := range fc.GetFieldDesc() {
+				if fd == nil || seen[fd.Name] {
+					continue
+				}
+				seen[fd.Name] = true
+				s.Features = append(s.Features, newField(fd, group, trainStmt.ColumnTypes))
+			}
+		}
+	}
+	if trainStmt.Label != nil {
+		if fds := trainStmt.Label.GetFieldDesc(); len(fds) > 0 && fds[0] != nil {
+			label := newField(fds[0], "", trainStmt.ColumnTypes)
+			s.Label = &label
+		}
+	}
+	return s
+}
+
+// CheckColumns returns an error naming the feature columns missing in
+// columns, like the column names of a table to predict.
+func (s *Schema) CheckColumns(columns []string) error {
+	has := map[string]bool{}
+	for _, c := range columns {
+		has[c] = true
+	}
+	missing := []string{}
+	for _, f := range s.Features {
+		if !has[f.Name] {
+			missing = append(missing, f.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("the model requires the feature column(s) %s, which are not in [%s]", strings.Join(missing, ", "), strings.Join(columns, ", "))
+	}
+	return nil
+}
+
+// SchemaDiff is the differences between the columns a model is trained on
+// and the columns to predict.
+type SchemaDiff struct {
+	// Missing is the columns of the model not in the columns to predict.
+	Missing []string
+	// Mismatches is the columns of the types that the model can't read.
+	Mismatches []TypeMismatch
+	// Unseen is the categorical columns with the values not seen at the
+	// training, which are set by the caller of Diff.
+	Unseen []UnseenCategories
+}
+
+// TypeMismatch is a column of the type Predict, but Train at the training.
+type TypeMismatch struct {
+	Column  string
+	Train   string
+	Predict string
+}
+
+// UnseenCategories is the values of a categorical column not in the
+// Categories of its Field, up to a limit.
+type UnseenCategories struct {
+	Column string
+	Values []string
+}
+
+// Empty returns if there's no difference.
+func (d *SchemaDiff) Empty() bool {
+	return len(d.Missing) == 0 && len(d.Mismatches) == 0 && len(d.Unseen) == 0
+}
+
+// String lists the differences, one per line.
+func (d *SchemaDiff) String() string {
+	lines := []string{}
+	if len(d.Missing) > 0 {
+		lines = append(lines, fmt.Sprintf("missing columns: %s", strings.Join(d.Missing, ", ")))
+	}
+	for _, m := range d.Mismatches {
+		lines = append(lines, fmt.Sprintf("column %s: %s, but %s at the training", m.Column, m.Predict, m.Train))
+	}
+	for _, u := range d.Unseen {
+		lines = append(lines, fmt.Sprintf("column %s: unseen categories %s", u.Column, strings.Join(u.Values, ", ")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// typeKinds maps the unified database types to the kinds that are read
+// alike by the models. The types not in it are compared by name.
+var typeKinds = map[string]string{
+	"TINYINT":    "int",
+	"SMALLINT":   "int",
+	"MEDIUMINT":  "int",
+	"INT":        "int",
+	"BIGINT":     "int",
+	"FLOAT":      "float",
+	"DOUBLE":     "float",
+	"DECIMAL":    "float",
+	"CHAR":       "string",
+	"VARCHAR":    "string",
+	"STRING":     "string",
+	"TEXT":       "string",
+	"TINYTEXT":   "string",
+	"MEDIUMTEXT": "string",
+	"LONGTEXT":   "string",
+	"DATE":       "time",
+	"DATETIME":   "time",
+	"TIMESTAMP":  "time",
+}
+
+// compatibleTypes returns if a model trained on a column of the database
+// type train reads the column of the type predict. The integers are read
+// as the floats, but not the other way around.
+func compatibleTypes(train, predict string) bool {
+	if train == "" || train == predict {
+		return true
+	}
+	tk, ok := typeKinds[train]
+	if !ok {
+		tk = train
+	}
+	pk, ok := typeKinds[predict]
+	if !ok {
+		pk = predict
+	}
+	return tk == pk || tk == "float" && pk == "int"
+}
+
+// Diff returns the differences between s and columns, which maps the
+// columns to predict in lower case to their database types unified like
+// Field.Type. The label is compared too if withLabel, like by EVALUATE.
+// The columns of the models saved by older versions have no types, so
+// only the missing columns are found.
+func (s *Schema) Diff(columns map[string]string, withLabel bool) *SchemaDiff {
+	d := &SchemaDiff{}
+	fields := s.Features
+	if withLabel && s.Label != nil {
+		fields = append(append([]Field{}, fields...), *s.Label)
+	}
+	seen := map[string]bool{}
+	for _, f := range fields {
+		if seen[f.Name] {
+			continue
+		}
+		seen[f.Name] = true
+		t, ok := columns[strings.ToLower(f.Name)]
+		if !ok {
+			d.Missing = append(d.Missing, f.Name)
+		} else if !compatibleTypes(f.Type, t) {
+			d.Mismatches = append(d.Mismatches, TypeMismatch{Column: f.Name, Train: f.Type, Predict: t})
+		}
+	}
+	return d
+}
@@ -0,0 +1,129 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sqlflow.org/sqlflow/pkg/ir"
+)
+
+func TestSaveAndLoadSchema(t *testing.T) {
+	a := assert.New(t)
+	cwd := mockWorkDir(t)
+	defer os.RemoveAll(cwd)
+	modelDir, e := ioutil.TempDir("", "sqlflow_model_dir")
+	a.NoError(e)
+	defer os.RemoveAll(modelDir)
+
+	sepalLength := &ir.NumericColumn{FieldDesc: &ir.FieldDesc{Name: "sepal_length", DType: ir.Float, Shape: []int{1}}}
+	sepalWidth := &ir.NumericColumn{FieldDesc: &ir.FieldDesc{Name: "sepal_width", DType: ir.Float, Shape: []int{1}}}
+	trainStmt := &ir.TrainStmt{
+		Features: map[string][]ir.FeatureColumn{
+			"wide_feature": {
+				&ir.CategoryIDColumn{FieldDesc: &ir.FieldDesc{Name: "kind", DType: ir.Int, IsSparse: true}, BucketSize: 10},
+			},
+			"deep_feature": {
+				sepalLength,
+				sepalWidth,
+				&ir.CrossColumn{Keys: []interface{}{sepalLength, sepalWidth}, HashBucketSize: 10},
+			},
+		},
+		Label: &ir.NumericColumn{FieldDesc: &ir.FieldDesc{Name: "class", DType: ir.Int}},
+	}
+	expected := &Schema{
+		Features: []Field{
+			{Name: "sepal_length", DType: "float", Shape: []int{1}, Group: "deep_feature"},
+			{Name: "sepal_width", DType: "float", Shape: []int{1}, Group: "deep_feature"},
+			{Name: "kind", DType: "int", Group: "wide_feature", IsSparse: true},
+		},
+		Label: &Field{Name: "class", DType: "int"},
+	}
+
+	modelURI := "file://" + filepath.Join(modelDir, "my_dnn_model")
+	a.NoError(New(cwd, testTrainSelect).Save(modelURI, trainStmt, nil))
+	m, e := LoadMeta(modelURI, nil)
+	a.NoError(e)
+	a.Equal(expected, m.Schema)
+
+	dst, e := ioutil.TempDir("", "sqlflow_model_dst")
+	a.NoError(e)
+	defer os.RemoveAll(dst)
+	m, e = Load(modelURI, dst, nil)
+	a.NoError(e)
+	a.Equal(expected, m.Schema)
+
+	a.NoError(m.Schema.CheckColumns([]string{"class", "kind", "sepal_width", "sepal_length"}))
+	e = m.Schema.CheckColumns([]string{"kind", "sepal_length"})
+	a.Error(e)
+	a.Contains(e.Error(), "sepal_width")
+
+	// Models saved without a TrainStmt have no schema.
+	a.NoError(New(cwd, testTrainSelect).Save(modelURI, nil, nil))
+	m, e = LoadMeta(modelURI, nil)
+	a.NoError(e)
+	a.Nil(m.Schema)
+}
+
+func TestSchemaDiff(t *testing.T) {
+	a := assert.New(t)
+	trainStmt := &ir.TrainStmt{
+		Features: map[string][]ir.FeatureColumn{
+			"feature_columns": {
+				&ir.NumericColumn{FieldDesc: &ir.FieldDesc{Name: "age", DType: ir.Int, Shape: []int{1}}},
+				&ir.NumericColumn{FieldDesc: &ir.FieldDesc{Name: "income", DType: ir.Float, Shape: []int{1}}},
+				&ir.NumericColumn{FieldDesc: &ir.FieldDesc{Name: "Height", DType: ir.Float, Shape: []int{1}}},
+				&ir.CategoryIDColumn{FieldDesc: &ir.FieldDesc{Name: "gender", DType: ir.String, Shape: []int{1},
+					Vocabulary: map[string]string{"MALE": "MALE", "FEMALE": "FEMALE"}}, BucketSize: 2},
+				&ir.NumericColumn{FieldDesc: &ir.FieldDesc{Name: "dense", DType: ir.Float, Shape: []int{4}, Delimiter: ","}},
+			},
+		},
+		Label:       &ir.NumericColumn{FieldDesc: &ir.FieldDesc{Name: "class", DType: ir.Int}},
+		ColumnTypes: map[string]string{"age": "INT", "income": "DOUBLE", "Height": "FLOAT", "gender": "VARCHAR", "dense": "VARCHAR", "class": "INT"},
+	}
+	s := schemaOf(trainStmt)
+	a.Equal([]string{"FEMALE", "MALE"}, s.Features[3].Categories)
+	a.Nil(s.Features[4].Categories)
+	a.Equal("INT", s.Label.Type)
+
+	// BIGINT reads like INT, and INT like FLOAT, but not the other way around.
+	columns := map[string]string{"age": "BIGINT", "income": "INT", "height": "FLOAT", "gender": "TEXT", "dense": "VARCHAR"}
+	d := s.Diff(columns, true)
+	a.Equal([]string{"class"}, d.Missing)
+	a.Empty(d.Mismatches)
+	a.True(s.Diff(columns, false).Empty())
+
+	d = s.Diff(map[string]string{"age": "DOUBLE", "income": "VARCHAR", "gender": "INT", "dense": "VARCHAR"}, false)
+	a.Equal([]string{"Height"}, d.Missing)
+	a.Equal([]TypeMismatch{
+		{Column: "age", Train: "INT", Predict: "DOUBLE"},
+		{Column: "income", Train: "DOUBLE", Predict: "VARCHAR"},
+		{Column: "gender", Train: "VARCHAR", Predict: "INT"},
+	}, d.Mismatches)
+	d.Unseen = []UnseenCategories{{Column: "gender", Values: []string{"NULL", "UNKNOWN"}}}
+	a.False(d.Empty())
+	a.Equal(`missing columns: Height
+column age: DOUBLE, but INT at the training
+column income: VARCHAR, but DOUBLE at the training
+column gender: INT, but VARCHAR at the training
+column gender: unseen categories NULL, UNKNOWN`, d.String())
+
+	// The models saved by older versions have no column types.
+	trainStmt.ColumnTypes = nil
+	a.True(schemaOf(trainStmt).Diff(map[string]string{"age": "VARCHAR", "income": "VARCHAR", "height": "VARCHAR", "gender": "INT", "dense": "INT"}, false).Empty())
+}
@@ -0,0 +1,165 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	pb "sqlflow.org/sqlflow/pkg/proto"
+)
+
+// Storage stores the saved models as objects, like an object store. A
+// Storage is registered by RegisterStorage for a scheme of modelURI, and
+// the path passed to its methods is the part of modelURI after
+// "scheme://". The credentials are in session, which is nil if Load is
+// called without a session; implementations usually fall back to the
+// environment then.
+type Storage interface {
+	// Put calls fn to write the object at path. It mustn't leave a
+	// partial object behind if fn fails.
+	Put(ctx context.Context, path string, session *pb.Session, fn func(io.Writer) error) error
+	// Get calls fn with the content of the object at path. fn may
+	// return before reading to the end.
+	Get(ctx context.Context, path string, session *pb.Session, fn func(io.Reader) error) error
+	// Stat returns the size of the object at path, or ErrModelNotFound
+	// if there is no such object.
+	Stat(ctx context.Context, path string, session *pb.Session) (int64, error)
+	// Delete removes the object at path, or returns ErrModelNotFound if
+	// there is no such object.
+	Delete(ctx context.Context, path string, session *pb.Session) error
+}
+
+// headGetter is implemented by the storages that can read the leading
+// bytes of an object for less than reading the object, which LoadMeta
+// uses before falling back to Get.
+type headGetter interface {
+	getHead(ctx context.Context, path string, session *pb.Session, n int64, fn func(io.Reader) error) error
+}
+
+var (
+	storagesMu sync.RWMutex
+	storages   = map[string]Storage{
+		"oss":  ossStorage{},
+		"s3":   s3Storage{},
+		"gs":   gcsStorage{},
+		"az":   azureStorage{},
+		"hdfs": hdfsStorage{},
+	}
+)
+
+// RegisterStorage makes the models whose URIs are scheme://path saved to
+// and loaded from s. It replaces the storage registered for scheme, if
+// any, including the built-in ones. The local filesystem file:// and the
+// sqlfs tables, which are modelURIs without a scheme, are not in the
+// registry, because they store the model meta in their own formats.
+func RegisterStorage(scheme string, s Storage) {
+	if scheme == "" || scheme == "file" {
+		panic(fmt.Sprintf("model: cannot register storage for the built-in scheme %q", scheme))
+	}
+	if s == nil {
+		panic(fmt.Sprintf("model: register nil storage for scheme %q", scheme))
+	}
+	storagesMu.Lock()
+	defer storagesMu.Unlock()
+	storages[scheme] = s
+}
+
+// storageOf returns the storage registered for scheme.
+func storageOf(scheme string) (Storage, bool) {
+	storagesMu.RLock()
+	defer storagesMu.RUnlock()
+	s, ok := storages[scheme]
+	return s, ok
+}
+
+// supportedSchemes returns the sorted schemes of modelURI, a modelURI
+// without a scheme names a sqlfs table in the database.
+func supportedSchemes() []string {
+	storagesMu.RLock()
+	defer storagesMu.RUnlock()
+	schemes := []string{"file"}
+	for scheme := range storages {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// saveStorage streams the model meta followed by the tar-gzipped working
+// directory to the object at path in s.
+func (m *Model) saveStorage(ctx context.Context, s Storage, path string, session *pb.Session, opts *Options) (stats *SaveStats, e error) {
+	e = s.Put(ctx, path, session, func(w io.Writer) (e error) {
+		stats, e = m.writeTo(ctx, w, opts)
+		return e
+	})
+	if e != nil {
+		return nil, e
+	}
+	return stats, nil
+}
+
+// loadStorage streams the object at path in s, and untars the model into
+// cwd if cwd is not "". When cwd is "", only the model meta is read.
+func loadStorage(ctx context.Context, s Storage, path, cwd string, session *pb.Session, opts *Options) (m *Model, e error) {
+	if cwd == "" {
+		return loadStorageMeta(ctx, s, path, session)
+	}
+	var pg *progress
+	if opts != nil && opts.Progress != nil {
+		// The size is only required for reporting the progress.
+		total := int64(-1)
+		if size, e := s.Stat(ctx, path, session); e == nil {
+			total = size
+		}
+		pg = newProgress(opts, total)
+	}
+	e = s.Get(ctx, path, session, func(r io.Reader) (e error) {
+		m, e = readFrom(ctx, withProgress(r, pg), cwd, opts, pg, nil)
+		return e
+	})
+	if e != nil {
+		return nil, e
+	}
+	return m, nil
+}
+
+// metaRange is the number of leading bytes loadStorageMeta reads to
+// decode the model meta from the storages implementing headGetter. It's
+// far longer than the model meta of most models.
+const metaRange = 64 << 10
+
+// loadStorageMeta reads the model meta of the object at path in s, from
+// the leading metaRange bytes if s supports it, so the following tarball
+// is not downloaded.
+func loadStorageMeta(ctx context.Context, s Storage, path string, session *pb.Session) (m *Model, e error) {
+	meta := func(r io.Reader) (e error) {
+		m, e = readMeta(bufio.NewReader(r))
+		return e
+	}
+	if h, ok := s.(headGetter); ok {
+		if e = h.getHead(ctx, path, session, metaRange, meta); e == nil {
+			return m, nil
+		}
+		// The model meta may be longer than metaRange.
+	}
+	if e = s.Get(ctx, path, session, meta); e != nil {
+		return nil, e
+	}
+	return m, nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+)
+
+// memStorage stores the objects in memory.
+type memStorage struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (s *memStorage) Put(ctx context.Context, path string, session *pb.Session, fn func(io.Writer) error) error {
+	var b bytes.Buffer
+	if e := fn(&b); e != nil {
+		return e
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[path] = b.Bytes()
+	return nil
+}
+
+func (s *memStorage) Get(ctx context.Context, path string, session *pb.Session, fn func(io.Reader) error) error {
+	s.mu.Lock()
+	b, ok := s.objects[path]
+	s.mu.Unlock()
+	if !ok {
+		return ErrModelNotFound
+	}
+	return fn(bytes.NewReader(b))
+}
+
+func (s *memStorage) Stat(ctx context.Context, path string, session *pb.Session) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.objects[path]
+	if !ok {
+		return 0, ErrModelNotFound
+	}
+	return int64(len(b)), nil
+}
+
+func (s *memStorage) Delete(ctx context.Context, path string, session *pb.Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.objects[path]; !ok {
+		return ErrModelNotFound
+	}
+	delete(s.objects, path)
+	return nil
+}
+
+func TestRegisterStorage(t *testing.T) {
+	a := assert.New(t)
+	a.Panics(func() { RegisterStorage("file", &memStorage{}) })
+	a.Panics(func() { RegisterStorage("", &memStorage{}) })
+
+	s := &memStorage{objects: map[string][]byte{}}
+	RegisterStorage("mem", s)
+	a.Contains(supportedSchemes(), "mem")
+
+	cwd := mockWorkDir(t)
+	defer os.RemoveAll(cwd)
+	modelURI := "mem://models/my_dnn_model"
+	a.NoError(Validate(modelURI, nil))
+	a.NoError(New(cwd, testTrainSelect).Save(modelURI, nil, nil))
+	a.Contains(s.objects, "models/my_dnn_model")
+	a.Equal(1, len(s.objects))
+
+	m, e := LoadMeta(modelURI, nil)
+	a.NoError(e)
+	a.Equal(testTrainSelect, m.TrainSelect)
+
+	dst, e := ioutil.TempDir("", "sqlflow_model_dst")
+	a.NoError(e)
+	defer os.RemoveAll(dst)
+	var total int64
+	opts := &Options{Progress: func(processed, t int64) { total = t }}
+	m, e = LoadWithOptions(context.Background(), modelURI, dst, nil, opts)
+	a.NoError(e)
+	a.Equal(testTrainSelect, m.TrainSelect)
+	a.Equal(int64(len(s.objects["models/my_dnn_model"])), total)
+	b, e := ioutil.ReadFile(filepath.Join(dst, "my_dnn_model", "saved_model.pb"))
+	a.NoError(e)
+	a.Equal("graph", string(b))
+
+	a.NoError(Copy(modelURI, modelURI+"_copy", nil))
+	a.NoError(Delete(modelURI, nil))
+	a.Equal(ErrModelNotFound, Delete(modelURI, nil))
+	a.Contains(s.objects, "models/my_dnn_model_copy")
+}
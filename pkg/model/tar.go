@@ -0,0 +1,501 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, e := c.w.Write(p)
+	c.n += int64(n)
+	return n, e
+}
+
+// shortWriteChecker counts the bytes written through it, and fails a
+// write that writes less than requested without an error.
+type shortWriteChecker struct {
+	w io.Writer
+	n int64
+}
+
+func (c *shortWriteChecker) Write(p []byte) (int, error) {
+	n, e := c.w.Write(p)
+	c.n += int64(n)
+	if e == nil && n < len(p) {
+		e = io.ErrShortWrite
+	}
+	return n, e
+}
+
+// limitWriter fails the write that makes the bytes written through it
+// exceed max.
+type limitWriter struct {
+	w   io.Writer
+	n   int64
+	max int64
+}
+
+// newLimitWriter returns w if max is zero, which means unlimited.
+func newLimitWriter(w io.Writer, max int64) io.Writer {
+	if max <= 0 {
+		return w
+	}
+	return &limitWriter{w: w, max: max}
+}
+
+func (l *limitWriter) Write(p []byte) (int, error) {
+	if l.n+int64(len(p)) > l.max {
+		return 0, fmt.Errorf("the model exceeds the maximum size of %d bytes", l.max)
+	}
+	n, e := l.w.Write(p)
+	l.n += int64(n)
+	return n, e
+}
+
+// contextReader fails reads with ctx.Err() once ctx is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *contextReader) Read(p []byte) (int, error) {
+	if e := c.ctx.Err(); e != nil {
+		return 0, e
+	}
+	return c.r.Read(p)
+}
+
+// tarGzDir writes the content of dir into w as a tar-gzipped stream with
+// the given compress/gzip level. The returned SaveStats is counted during
+// the same pass, without a separate walk of dir.
+//
+// Symlinks are followed: the tarball contains the files and directories
+// they point to as regular entries, so the extracted model doesn't depend
+// on anything outside of dir, like a shared vocabulary directory.
+//
+// The files in dir named by first are archived before the others, so a
+// reader can find them without decompressing the whole tarball.
+//
+// The bytes read from the files are reported to p. The last entry of the
+// tarball is sha256SumsFile, the checksums of the files.
+func tarGzDir(ctx context.Context, dir string, w io.Writer, level int, p *progress, first ...string) (*SaveStats, error) {
+	stats := &SaveStats{}
+	cw := &countingWriter{w: w}
+	gw, e := gzip.NewWriterLevel(cw, level)
+	if e != nil {
+		return nil, fmt.Errorf("archive %s failed: %v", dir, e)
+	}
+	tw := tar.NewWriter(gw)
+	sums := sha256Sums{}
+	if e := addDirToTar(ctx, tw, dir, "", stats, sums, p, map[string]bool{}, first); e != nil {
+		return nil, fmt.Errorf("archive %s failed: %v", dir, e)
+	}
+	if e := writeSHA256Sums(tw, sums); e != nil {
+		return nil, fmt.Errorf("archive %s failed: %v", dir, e)
+	}
+	if e := tw.Close(); e != nil {
+		return nil, fmt.Errorf("archive %s failed: %v", dir, e)
+	}
+	if e := gw.Close(); e != nil {
+		return nil, fmt.Errorf("archive %s failed: %v", dir, e)
+	}
+	stats.CompressedSize = cw.n
+	return stats, nil
+}
+
+// addDirToTar writes the content of dir into tw with the entry names
+// prefixed by prefix, in the order of file names except that the ones in
+// first go first. The checksums of the files are added to sums.
+// ancestors holds the real paths of the directories being archived to
+// detect symlink cycles.
+func addDirToTar(ctx context.Context, tw *tar.Writer, dir, prefix string, stats *SaveStats, sums sha256Sums, pg *progress, ancestors map[string]bool, first []string) error {
+	real, e := filepath.EvalSymlinks(dir)
+	if e != nil {
+		return e
+	}
+	if ancestors[real] {
+		return fmt.Errorf("symlink cycle at %s", dir)
+	}
+	ancestors[real] = true
+	defer delete(ancestors, real)
+
+	fis, e := ioutil.ReadDir(dir)
+	if e != nil {
+		return e
+	}
+	for i := len(first) - 1; i >= 0; i-- {
+		for j, fi := range fis {
+			if fi.Name() == first[i] {
+				// Move fis[j] to the front.
+				copy(fis[1:j+1], fis[:j])
+				fis[0] = fi
+				break
+			}
+		}
+	}
+	for _, fi := range fis {
+		if e := ctx.Err(); e != nil {
+			return e
+		}
+		p := filepath.Join(dir, fi.Name())
+		name := path.Join(prefix, fi.Name())
+		if fi.Mode()&os.ModeSymlink != 0 {
+			if fi, e = os.Stat(p); e != nil {
+				return fmt.Errorf("broken symlink %s: %v", p, e)
+			}
+		}
+		if !fi.IsDir() && !fi.Mode().IsRegular() {
+			continue // sockets, devices, and named pipes are not model files
+		}
+		if isSHA256SumsEntry(name) {
+			continue // written by tarGzDir after the other files
+		}
+		hdr, e := tar.FileInfoHeader(fi, "")
+		if e != nil {
+			return e
+		}
+		hdr.Name = name
+		if fi.IsDir() {
+			hdr.Name += "/"
+			if e := tw.WriteHeader(hdr); e != nil {
+				return e
+			}
+			if e := addDirToTar(ctx, tw, p, name, stats, sums, pg, ancestors, nil); e != nil {
+				return e
+			}
+			continue
+		}
+		if e := tw.WriteHeader(hdr); e != nil {
+			return e
+		}
+		sum, e := addFileToTar(ctx, tw, p, stats, pg)
+		if e != nil {
+			return e
+		}
+		sums[name] = sum
+	}
+	return nil
+}
+
+// addFileToTar copies the file p into tw, and returns its SHA-256
+// checksum.
+func addFileToTar(ctx context.Context, tw *tar.Writer, p string, stats *SaveStats, pg *progress) (string, error) {
+	f, e := os.Open(p)
+	if e != nil {
+		return "", e
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, e := io.Copy(io.MultiWriter(tw, h), withProgress(&contextReader{ctx, f}, pg))
+	if e != nil {
+		return "", e
+	}
+	stats.Files++
+	stats.Size += n
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// untarGz extracts the tar-gzipped stream r into dir. Tarballs written by
+// tarGzDir have no symlinks, the ones in tarballs created by other tools
+// are recreated as symlinks.
+//
+// If the tarball has the entry sha256SumsFile, untarGz checks the files
+// against it and doesn't extract it.
+//
+// Models may be uploaded by others, so untarGz refuses the tarball with
+// an entry that would be written outside of dir: an absolute path, a path
+// that escapes dir after cleaning, a path through a symlink that points
+// outside of dir, or a symlink to such a place.
+func untarGz(ctx context.Context, r io.Reader, dir string) error {
+	gr, e := gzip.NewReader(&contextReader{ctx, r})
+	if e != nil {
+		return fmt.Errorf("extract model failed: %v", e)
+	}
+	defer gr.Close()
+	root, e := filepath.EvalSymlinks(dir)
+	if e != nil {
+		return fmt.Errorf("extract model failed: %v", e)
+	}
+	tr := tar.NewReader(gr)
+	sums, want := sha256Sums{}, sha256Sums(nil)
+	for {
+		hdr, e := tr.Next()
+		if e == io.EOF {
+			if want != nil {
+				return sums.check(want)
+			}
+			return nil
+		}
+		if e != nil {
+			return fmt.Errorf("extract model failed: %v", e)
+		}
+		if isSHA256SumsEntry(hdr.Name) {
+			if want, e = readSHA256Sums(tr); e != nil {
+				return fmt.Errorf("extract model failed: %v", e)
+			}
+			continue
+		}
+		target, e := entryTarget(root, hdr.Name)
+		if e != nil {
+			return fmt.Errorf("extract model failed: illegal entry %q: %v", hdr.Name, e)
+		}
+		if target == root {
+			continue // the entry "./"
+		}
+		if e := os.MkdirAll(filepath.Dir(target), 0755); e != nil {
+			return e
+		}
+		if e := checkParent(root, target); e != nil {
+			return fmt.Errorf("extract model failed: illegal entry %q: %v", hdr.Name, e)
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if e := os.MkdirAll(target, 0755); e != nil {
+				return e
+			}
+		case tar.TypeReg, tar.TypeRegA:
+			if fi, e := os.Lstat(target); e == nil && fi.Mode()&os.ModeSymlink != 0 {
+				return fmt.Errorf("extract model failed: illegal entry %q: overwrites a symlink", hdr.Name)
+			}
+			h := sha256.New()
+			if e := extractFile(io.TeeReader(tr, h), target, os.FileMode(hdr.Mode)); e != nil {
+				return e
+			}
+			sums[path.Clean(hdr.Name)] = hex.EncodeToString(h.Sum(nil))
+		case tar.TypeSymlink:
+			link := filepath.FromSlash(hdr.Linkname)
+			if filepath.IsAbs(link) || path.IsAbs(hdr.Linkname) {
+				return fmt.Errorf("extract model failed: illegal entry %q: symlink to the absolute path %q", hdr.Name, hdr.Linkname)
+			}
+			if !isInside(root, filepath.Join(filepath.Dir(target), link)) {
+				return fmt.Errorf("extract model failed: illegal entry %q: symlink to %q is outside of the model", hdr.Name, hdr.Linkname)
+			}
+			if e := os.Symlink(link, target); e != nil {
+				return e
+			}
+			// The lexical check above doesn't know the symlinks in the
+			// link, like x/.. where x links to ".".
+			if real, e := filepath.EvalSymlinks(target); e == nil && !isInside(root, real) {
+				return fmt.Errorf("extract model failed: illegal entry %q: symlink to %q is outside of the model", hdr.Name, hdr.Linkname)
+			}
+		}
+	}
+}
+
+// entryTarget returns the path in root to extract the tarball entry name
+// to. It fails if name is absolute or escapes root.
+func entryTarget(root, name string) (string, error) {
+	native := filepath.FromSlash(name)
+	if path.IsAbs(name) || filepath.IsAbs(native) || filepath.VolumeName(native) != "" {
+		return "", fmt.Errorf("absolute path")
+	}
+	target := filepath.Join(root, native)
+	if !isInside(root, target) {
+		return "", fmt.Errorf("path escapes the extraction directory")
+	}
+	return target, nil
+}
+
+// checkParent makes sure the parent directory of target, with symlinks
+// extracted before resolved, is in root, so no entry is written through
+// a symlink to outside of root.
+func checkParent(root, target string) error {
+	parent, e := filepath.EvalSymlinks(filepath.Dir(target))
+	if e != nil {
+		return e
+	}
+	if !isInside(root, parent) {
+		return fmt.Errorf("path escapes the extraction directory through a symlink")
+	}
+	return nil
+}
+
+// isInside returns true if the cleaned path p is root or in root.
+func isInside(root, p string) bool {
+	rel, e := filepath.Rel(root, p)
+	return e == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// untarGzStaged extracts r into a staging directory created in tempDir,
+// or in dir if tempDir is "", and moves the extracted entries into dir
+// after the extraction succeeds. The entries are copied if tempDir is
+// on another filesystem. The staging directory is always removed, so a
+// failed extraction leaves nothing in dir.
+func untarGzStaged(ctx context.Context, r io.Reader, dir, tempDir string) error {
+	if tempDir == "" {
+		tempDir = dir
+	}
+	if e := os.MkdirAll(dir, 0755); e != nil {
+		return e
+	}
+	staging, e := ioutil.TempDir(tempDir, ".sqlflow_model_")
+	if e != nil {
+		return fmt.Errorf("create staging directory in %s failed: %v", tempDir, e)
+	}
+	defer os.RemoveAll(staging)
+	if e := untarGz(ctx, r, staging); e != nil {
+		return e
+	}
+	fis, e := ioutil.ReadDir(staging)
+	if e != nil {
+		return e
+	}
+	for _, fi := range fis {
+		target := filepath.Join(dir, fi.Name())
+		// os.Rename can't replace a non-empty directory.
+		if e := os.RemoveAll(target); e != nil {
+			return e
+		}
+		if e := moveEntry(filepath.Join(staging, fi.Name()), target); e != nil {
+			return fmt.Errorf("move %s into %s failed: %v", fi.Name(), dir, e)
+		}
+	}
+	return nil
+}
+
+// rename is os.Rename, and is replaced by the tests to move across
+// filesystems.
+var rename = os.Rename
+
+// moveEntry moves the file or directory src to target, or copies it and
+// removes src if they are on different filesystems, where os.Rename
+// fails with EXDEV.
+func moveEntry(src, target string) error {
+	e := rename(src, target)
+	if le, ok := e.(*os.LinkError); !ok || le.Err != syscall.EXDEV {
+		return e
+	}
+	if e := copyTree(src, target); e != nil {
+		os.RemoveAll(target)
+		return e
+	}
+	return os.RemoveAll(src)
+}
+
+// copyTree copies the directories, the regular files and the symlinks
+// in src to target, keeping their modes.
+func copyTree(src, target string) error {
+	return filepath.Walk(src, func(p string, fi os.FileInfo, e error) error {
+		if e != nil {
+			return e
+		}
+		rel, e := filepath.Rel(src, p)
+		if e != nil {
+			return e
+		}
+		dst := filepath.Join(target, rel)
+		switch {
+		case fi.IsDir():
+			return os.MkdirAll(dst, fi.Mode().Perm())
+		case fi.Mode()&os.ModeSymlink != 0:
+			link, e := os.Readlink(p)
+			if e != nil {
+				return e
+			}
+			return os.Symlink(link, dst)
+		case fi.Mode().IsRegular():
+			f, e := os.Open(p)
+			if e != nil {
+				return e
+			}
+			defer f.Close()
+			return extractFile(f, dst, fi.Mode().Perm())
+		}
+		return fmt.Errorf("unsupported file %s", p)
+	})
+}
+
+func extractFile(r io.Reader, target string, mode os.FileMode) error {
+	if e := os.MkdirAll(filepath.Dir(target), 0755); e != nil {
+		return e
+	}
+	f, e := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if e != nil {
+		return e
+	}
+	if _, e := io.Copy(f, r); e != nil {
+		f.Close()
+		return e
+	}
+	return f.Close()
+}
+
+// isGzip returns true if f starts with the gzip magic number. It seeks f
+// back to the beginning.
+func isGzip(f io.ReadSeeker) bool {
+	var magic [2]byte
+	_, e := io.ReadFull(f, magic[:])
+	f.Seek(0, io.SeekStart)
+	return e == nil && magic[0] == 0x1f && magic[1] == 0x8b
+}
+
+// decodeMetaInTarGz decodes the meta file save.json, or save.gob of
+// older models, in the tar-gzipped stream r without extracting the
+// tarball.
+func decodeMetaInTarGz(r io.Reader, save string) (*Model, error) {
+	gr, e := gzip.NewReader(r)
+	if e != nil {
+		return nil, e
+	}
+	defer gr.Close()
+	tr := tar.NewReader(gr)
+	var legacy *Model
+	for {
+		hdr, e := tr.Next()
+		if e == io.EOF {
+			break
+		}
+		if e != nil {
+			return nil, e
+		}
+		switch path.Clean(hdr.Name) {
+		case jsonMetaFile(save):
+			m := &Model{}
+			if e := json.NewDecoder(tr).Decode(m); e != nil {
+				return nil, fmt.Errorf("model.load: json-decoding model failed: %v", e)
+			}
+			return m, nil
+		case gobMetaFile(save):
+			legacy = &Model{}
+			if e := gob.NewDecoder(tr).Decode(legacy); e != nil {
+				return nil, fmt.Errorf("model.load: gob-decoding model failed: %v", e)
+			}
+		}
+	}
+	if legacy == nil {
+		return nil, fmt.Errorf("cannot find %s in the tarball", jsonMetaFile(save))
+	}
+	return legacy, nil
+}
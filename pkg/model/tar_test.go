@@ -0,0 +1,240 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// tarGzEntries returns the entry names in the tar-gzipped stream r.
+func tarGzEntries(t *testing.T, r io.Reader) []string {
+	a := assert.New(t)
+	gr, e := gzip.NewReader(r)
+	a.NoError(e)
+	tr := tar.NewReader(gr)
+	names := []string{}
+	for {
+		hdr, e := tr.Next()
+		if e == io.EOF {
+			break
+		}
+		a.NoError(e)
+		names = append(names, hdr.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TestTarGzDirPortable makes sure the tarball uses slash-separated names
+// on every platform, including Windows, and extracts to native paths.
+func TestTarGzDirPortable(t *testing.T) {
+	a := assert.New(t)
+	cwd := mockWorkDir(t)
+	defer os.RemoveAll(cwd)
+
+	var buf bytes.Buffer
+	_, e := tarGzDir(context.Background(), cwd, &buf, gzip.DefaultCompression, nil)
+	a.NoError(e)
+	a.Equal([]string{
+		sha256SumsFile,
+		"my_dnn_model/",
+		"my_dnn_model/saved_model.pb",
+		"my_dnn_model/variables/",
+		"my_dnn_model/variables/variables.index",
+		"train.py",
+	}, tarGzEntries(t, bytes.NewReader(buf.Bytes())))
+
+	dst, e := ioutil.TempDir("", "sqlflow_model_dst")
+	a.NoError(e)
+	defer os.RemoveAll(dst)
+	a.NoError(untarGz(context.Background(), &buf, dst))
+	b, e := ioutil.ReadFile(filepath.Join(dst, "my_dnn_model", "variables", "variables.index"))
+	a.NoError(e)
+	a.Equal("index", string(b))
+}
+
+func TestTarGzDirFollowsSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skip as creating symlinks requires privileges on Windows")
+	}
+	a := assert.New(t)
+	cwd := mockWorkDir(t)
+	defer os.RemoveAll(cwd)
+	shared, e := ioutil.TempDir("", "sqlflow_shared_vocab")
+	a.NoError(e)
+	defer os.RemoveAll(shared)
+	a.NoError(ioutil.WriteFile(filepath.Join(shared, "vocab.txt"), []byte("a\nb\n"), 0644))
+	a.NoError(os.Symlink(shared, filepath.Join(cwd, "vocab")))
+	a.NoError(os.Symlink("train.py", filepath.Join(cwd, "entry.py")))
+
+	var buf bytes.Buffer
+	stats, e := tarGzDir(context.Background(), cwd, &buf, gzip.DefaultCompression, nil)
+	a.NoError(e)
+	a.Equal(5, stats.Files)
+
+	dst, e := ioutil.TempDir("", "sqlflow_model_dst")
+	a.NoError(e)
+	defer os.RemoveAll(dst)
+	a.NoError(untarGz(context.Background(), &buf, dst))
+	for name, content := range map[string]string{
+		filepath.Join("vocab", "vocab.txt"): "a\nb\n",
+		"entry.py":                          "print('hello')",
+	} {
+		fi, e := os.Lstat(filepath.Join(dst, name))
+		a.NoError(e)
+		a.True(fi.Mode().IsRegular())
+		b, e := ioutil.ReadFile(filepath.Join(dst, name))
+		a.NoError(e)
+		a.Equal(content, string(b))
+	}
+
+	// A symlink cycle fails the archiving instead of looping forever.
+	a.NoError(os.Symlink(cwd, filepath.Join(cwd, "my_dnn_model", "loop")))
+	_, e = tarGzDir(context.Background(), cwd, ioutil.Discard, gzip.DefaultCompression, nil)
+	a.Error(e)
+}
+
+// shortWriter writes at most n bytes without an error.
+type shortWriter struct{ n int }
+
+func (w *shortWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n {
+		p = p[:w.n]
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
+
+func TestShortWriteChecker(t *testing.T) {
+	a := assert.New(t)
+	w := &shortWriteChecker{w: &shortWriter{n: 5}}
+	n, e := w.Write([]byte("abc"))
+	a.NoError(e)
+	a.Equal(3, n)
+	n, e = w.Write([]byte("defg"))
+	a.Equal(io.ErrShortWrite, e)
+	a.Equal(2, n)
+	a.Equal(int64(5), w.n)
+}
+
+// tarGzOf returns a tar-gzipped stream of hdrs, where the regular files
+// have their names as the content.
+func tarGzOf(t *testing.T, hdrs ...*tar.Header) io.Reader {
+	a := assert.New(t)
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for _, hdr := range hdrs {
+		if hdr.Typeflag == tar.TypeReg {
+			hdr.Mode, hdr.Size = 0644, int64(len(hdr.Name))
+		}
+		a.NoError(tw.WriteHeader(hdr))
+		if hdr.Typeflag == tar.TypeReg {
+			_, e := tw.Write([]byte(hdr.Name))
+			a.NoError(e)
+		}
+	}
+	a.NoError(tw.Close())
+	a.NoError(gw.Close())
+	return &buf
+}
+
+func TestUntarGzRejectsPathTraversal(t *testing.T) {
+	a := assert.New(t)
+	file := func(name string) *tar.Header { return &tar.Header{Name: name, Typeflag: tar.TypeReg} }
+	symlink := func(name, link string) *tar.Header {
+		return &tar.Header{Name: name, Linkname: link, Typeflag: tar.TypeSymlink}
+	}
+	for _, tc := range []struct {
+		hdrs    []*tar.Header
+		illegal string
+	}{
+		{[]*tar.Header{file("train.py"), file("../evil")}, "../evil"},
+		{[]*tar.Header{file("my_dnn_model/../../evil")}, "my_dnn_model/../../evil"},
+		{[]*tar.Header{file("/tmp/evil")}, "/tmp/evil"},
+		{[]*tar.Header{symlink("link", "../")}, "link"},
+		{[]*tar.Header{symlink("link", "/tmp")}, "link"},
+		// x/.. looks like the extraction directory, but x links to ".".
+		{[]*tar.Header{symlink("x", "."), symlink("link", "x/..")}, "link"},
+	} {
+		if runtime.GOOS == "windows" && tc.hdrs[0].Typeflag == tar.TypeSymlink {
+			continue
+		}
+		parent, e := ioutil.TempDir("", "sqlflow_model_parent")
+		a.NoError(e)
+		dst := filepath.Join(parent, "dst")
+		a.NoError(os.Mkdir(dst, 0755))
+		e = untarGz(context.Background(), tarGzOf(t, tc.hdrs...), dst)
+		a.Error(e)
+		if e != nil {
+			a.Contains(e.Error(), "illegal entry \""+tc.illegal+"\"")
+		}
+		_, e = os.Stat(filepath.Join(parent, "evil"))
+		a.True(os.IsNotExist(e))
+		os.RemoveAll(parent)
+	}
+
+	// Symlinks within the model are fine.
+	if runtime.GOOS != "windows" {
+		dst, e := ioutil.TempDir("", "sqlflow_model_dst")
+		a.NoError(e)
+		defer os.RemoveAll(dst)
+		a.NoError(untarGz(context.Background(), tarGzOf(t,
+			&tar.Header{Name: "./", Typeflag: tar.TypeDir, Mode: 0755},
+			file("my_dnn_model/saved_model.pb"),
+			symlink("model", "my_dnn_model"),
+			symlink("my_dnn_model/self", "../my_dnn_model")), dst))
+		b, e := ioutil.ReadFile(filepath.Join(dst, "model", "self", "saved_model.pb"))
+		a.NoError(e)
+		a.Equal("my_dnn_model/saved_model.pb", string(b))
+	}
+}
+
+func TestCopyTree(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skip as creating symlinks requires privileges on Windows")
+	}
+	a := assert.New(t)
+	src := mockWorkDir(t)
+	defer os.RemoveAll(src)
+	a.NoError(os.Chmod(filepath.Join(src, "train.py"), 0755))
+	a.NoError(os.Symlink("train.py", filepath.Join(src, "entry.py")))
+	dst, e := ioutil.TempDir("", "sqlflow_model_dst")
+	a.NoError(e)
+	defer os.RemoveAll(dst)
+
+	target := filepath.Join(dst, "model")
+	a.NoError(copyTree(src, target))
+	b, e := ioutil.ReadFile(filepath.Join(target, "my_dnn_model", "variables", "variables.index"))
+	a.NoError(e)
+	a.Equal("index", string(b))
+	fi, e := os.Stat(filepath.Join(target, "train.py"))
+	a.NoError(e)
+	a.Equal(os.FileMode(0755), fi.Mode().Perm())
+	link, e := os.Readlink(filepath.Join(target, "entry.py"))
+	a.NoError(e)
+	a.Equal("train.py", link)
+}
@@ -0,0 +1,83 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// isTensorBoardEventFile returns true if name is a TensorBoard event file
+// like events.out.tfevents.1590000000.hostname.
+func isTensorBoardEventFile(name string) bool {
+	return strings.Contains(filepath.Base(name), ".tfevents.")
+}
+
+// WriteTensorBoardLogs writes the TensorBoard event files in dir, where a
+// model is extracted, into w as a tar-gzipped stream with their paths in
+// dir, and returns the number of the files. The TensorFlow estimators
+// write the files into their model_dir, and the Keras models into the
+// tensorboard directory.
+func WriteTensorBoardLogs(ctx context.Context, dir string, w io.Writer) (int, error) {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	stats := &SaveStats{}
+	e := filepath.Walk(dir, func(p string, fi os.FileInfo, e error) error {
+		if e != nil {
+			return e
+		}
+		if !fi.Mode().IsRegular() || !isTensorBoardEventFile(p) {
+			return nil
+		}
+		rel, e := filepath.Rel(dir, p)
+		if e != nil {
+			return e
+		}
+		hdr, e := tar.FileInfoHeader(fi, "")
+		if e != nil {
+			return e
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if e := tw.WriteHeader(hdr); e != nil {
+			return e
+		}
+		_, e = addFileToTar(ctx, tw, p, stats, nil)
+		return e
+	})
+	if e != nil {
+		return 0, fmt.Errorf("archive the TensorBoard logs in %s failed: %v", dir, e)
+	}
+	if e := tw.Close(); e != nil {
+		return 0, e
+	}
+	if e := gw.Close(); e != nil {
+		return 0, e
+	}
+	return stats.Files, nil
+}
+
+// ExtractTensorBoardLogs extracts the stream written by
+// WriteTensorBoardLogs into dir.
+func ExtractTensorBoardLogs(ctx context.Context, r io.Reader, dir string) error {
+	if e := os.MkdirAll(dir, 0755); e != nil {
+		return e
+	}
+	return untarGz(ctx, r, dir)
+}
@@ -0,0 +1,67 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTensorBoardLogs(t *testing.T) {
+	a := assert.New(t)
+	cwd, e := ioutil.TempDir("", "sqlflow_models")
+	a.NoError(e)
+	defer os.RemoveAll(cwd)
+	files := map[string]string{
+		"model_save/events.out.tfevents.1590000000.host":           "estimator",
+		"model_save/eval/events.out.tfevents.1590000001.host":      "eval",
+		"tensorboard/train/events.out.tfevents.1590000002.host.v2": "keras",
+		"model_save/model.ckpt-100.index":                          "checkpoint",
+	}
+	for f, content := range files {
+		a.NoError(os.MkdirAll(filepath.Dir(filepath.Join(cwd, f)), 0755))
+		a.NoError(ioutil.WriteFile(filepath.Join(cwd, f), []byte(content), 0644))
+	}
+
+	var buf bytes.Buffer
+	n, e := WriteTensorBoardLogs(context.Background(), cwd, &buf)
+	a.NoError(e)
+	a.Equal(3, n)
+
+	dst, e := ioutil.TempDir("", "sqlflow_tensorboard")
+	a.NoError(e)
+	defer os.RemoveAll(dst)
+	logDir := filepath.Join(dst, "sqlflow_models.my_model")
+	a.NoError(ExtractTensorBoardLogs(context.Background(), &buf, logDir))
+	for f, content := range files {
+		b, e := ioutil.ReadFile(filepath.Join(logDir, f))
+		if isTensorBoardEventFile(f) {
+			a.NoError(e)
+			a.Equal(content, string(b))
+		} else {
+			a.True(os.IsNotExist(e))
+		}
+	}
+
+	buf.Reset()
+	n, e = WriteTensorBoardLogs(context.Background(), filepath.Join(cwd, "tensorboard", "no_such_dir"), &buf)
+	a.Error(e)
+	a.Equal(0, n)
+}
@@ -0,0 +1,109 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+var nonTableRuneRegex = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// parseModelURI splits modelURI into the scheme and the path. The scheme
+// is "" if modelURI is a sqlfs table name like "my_db.my_model".
+func parseModelURI(modelURI string) (scheme, path string, err error) {
+	if modelURI == "" {
+		return "", "", fmt.Errorf("modelURI is empty")
+	}
+	i := strings.Index(modelURI, "://")
+	if i < 0 {
+		slash := strings.Index(modelURI, "/")
+		if j := strings.Index(modelURI, ":"); j >= 0 && (slash < 0 || j < slash) {
+			return "", "", fmt.Errorf("malformed modelURI %q: scheme %q should be followed by \"://\"", modelURI, modelURI[:j])
+		}
+		if slash >= 0 {
+			return "", groupTable(modelURI), nil
+		}
+		return "", modelURI, nil
+	}
+	scheme, path = modelURI[:i], modelURI[i+len("://"):]
+	if !isSupportedScheme(scheme) {
+		return "", "", fmt.Errorf("malformed modelURI %q: unsupported scheme %q, should be one of %s", modelURI, scheme, strings.Join(supportedSchemes(), ", "))
+	}
+	if path == "" {
+		return "", "", fmt.Errorf("malformed modelURI %q: empty path after \"%s://\"", modelURI, scheme)
+	}
+	if strings.Contains(path, "://") {
+		return "", "", fmt.Errorf("malformed modelURI %q: path %q contains \"://\"", modelURI, path)
+	}
+	if scheme == "file" {
+		path = localPath(path, runtime.GOOS)
+	}
+	return scheme, path, nil
+}
+
+// localPath returns the path on the local filesystem of goos of the path
+// of a file:// URI. On Windows, the path of file:///C:/models/my_model is
+// C:\models\my_model.
+func localPath(path, goos string) string {
+	if goos != "windows" {
+		return path
+	}
+	if len(path) >= 3 && path[0] == '/' && path[2] == ':' && isDriveLetter(path[1]) {
+		path = path[1:]
+	}
+	return strings.Replace(path, "/", "\\", -1)
+}
+
+// groupTable returns the sqlfs table of the model of a group trained by
+// train.group_by, like my_db.my_model__region_cn of my_db.my_model/region=cn,
+// as the table names can't have '/'. The groups with the runes other than
+// the letters, the digits and '_' are followed by a hash to tell them apart.
+func groupTable(name string) string {
+	i := strings.Index(name, "/")
+	group := name[i+1:]
+	table := name[:i] + "__" + nonTableRuneRegex.ReplaceAllString(group, "_")
+	if j := strings.Index(group, "="); j < 0 || nonTableRuneRegex.MatchString(group[:j]+group[j+1:]) {
+		sum := sha256.Sum256([]byte(group))
+		table += "_" + hex.EncodeToString(sum[:4])
+	}
+	return table
+}
+
+// mkGroupDir creates the directory dir of the model file of a group, like
+// my_model/region=cn, which is next to the index my_model. The directories
+// of the other models should exist.
+func mkGroupDir(dir, file string) error {
+	if !strings.Contains(file, "=") {
+		return nil
+	}
+	return os.MkdirAll(dir, 0755)
+}
+
+func isDriveLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isSupportedScheme(scheme string) bool {
+	if scheme == "file" {
+		return true
+	}
+	_, ok := storageOf(scheme)
+	return ok
+}
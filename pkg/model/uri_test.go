@@ -0,0 +1,69 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseModelURI(t *testing.T) {
+	a := assert.New(t)
+	for _, c := range []struct {
+		uri, scheme, path string
+	}{
+		{"my_db.my_model", "", "my_db.my_model"},
+		{"file:///tmp/my_model", "file", "/tmp/my_model"},
+		{"s3://bucket/path/to/model", "s3", "bucket/path/to/model"},
+		{"gs://bucket/path/to/model", "gs", "bucket/path/to/model"},
+		{"az://container/path/to/model", "az", "container/path/to/model"},
+		{"hdfs:///path/to/model", "hdfs", "/path/to/model"},
+		{"oss://bucket/model", "oss", "bucket/model"},
+		{"oss://bucket/model/region=cn", "oss", "bucket/model/region=cn"},
+		{"my_db.my_model/region=cn", "", "my_db.my_model__region_cn"},
+		{"my_db.my_model/hour=10:00", "", "my_db.my_model__hour_10_00_96a5961f"},
+	} {
+		scheme, path, e := parseModelURI(c.uri)
+		a.NoError(e)
+		a.Equal(c.scheme, scheme)
+		a.Equal(c.path, path)
+	}
+
+	for _, c := range []struct {
+		uri, msg string
+	}{
+		{"", "modelURI is empty"},
+		{"file:/tmp/my_model", `scheme "file" should be followed by "://"`},
+		{"file://", `empty path after "file://"`},
+		{"ftp://host/model", `unsupported scheme "ftp"`},
+		{"://my_model", `unsupported scheme ""`},
+		{"file://s3://bucket/model", `path "s3://bucket/model" contains "://"`},
+	} {
+		_, _, e := parseModelURI(c.uri)
+		if a.Error(e, c.uri) {
+			a.Contains(e.Error(), c.msg)
+		}
+	}
+}
+
+func TestLocalPath(t *testing.T) {
+	a := assert.New(t)
+	a.Equal("/tmp/my_model", localPath("/tmp/my_model", "linux"))
+	a.Equal("/tmp/my_model", localPath("/tmp/my_model", "darwin"))
+	a.Equal(`C:\models\my_model`, localPath("/C:/models/my_model", "windows"))
+	a.Equal(`C:\models\my_model`, localPath(`C:\models\my_model`, "windows"))
+	a.Equal(`models\my_model`, localPath("models/my_model", "windows"))
+	a.Equal(`\\server\share\my_model`, localPath("//server/share/my_model", "windows"))
+}
@@ -0,0 +1,77 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"sqlflow.org/sqlflow/pkg/database"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+)
+
+// validateSuffix is appended to the model name to name the probe that
+// Validate writes and removes.
+const validateSuffix = "_sqlflow_validate"
+
+// Validate checks that Save can save a model to modelURI, so a TRAIN
+// statement can fail before the training instead of after it. It parses
+// modelURI, connects to the storage with the credentials in session, and
+// writes a small probe next to the model with the same code Save uses,
+// then removes the probe. The model at modelURI, if any, is untouched.
+func Validate(modelURI string, session *pb.Session) error {
+	ctx := context.Background()
+	scheme, p, e := parseModelURI(modelURI)
+	if e != nil {
+		return e
+	}
+	probe := func(w io.Writer) error {
+		_, e := io.WriteString(w, "SQLFlow validates the model URI")
+		return e
+	}
+	if scheme == "file" {
+		dir, file := filepath.Split(p)
+		if dir == "" {
+			dir = "." // not the default directory of ioutil.TempFile
+		}
+		if e := mkGroupDir(dir, file); e != nil {
+			return fmt.Errorf("cannot save model to %s: %v", modelURI, e)
+		}
+		f, e := ioutil.TempFile(dir, file+validateSuffix+"_*")
+		if e != nil {
+			return fmt.Errorf("cannot save model to %s: %v", modelURI, e)
+		}
+		f.Close()
+		return os.Remove(f.Name())
+	}
+	if s, ok := storageOf(scheme); ok {
+		if e := s.Put(ctx, p+validateSuffix, session, probe); e != nil {
+			return e
+		}
+		return s.Delete(ctx, p+validateSuffix, session)
+	}
+	db, e := database.OpenAndConnectDB(session.DbConnStr)
+	if e != nil {
+		return e
+	}
+	defer db.Close()
+	if e := writeDB(db, p+validateSuffix, session, nil, probe); e != nil {
+		return e
+	}
+	return deleteDB(db, p+validateSuffix)
+}
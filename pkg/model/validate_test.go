@@ -0,0 +1,56 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sqlflow.org/sqlflow/pkg/database"
+	"sqlflow.org/sqlflow/pkg/sqlfs"
+)
+
+func TestValidateFile(t *testing.T) {
+	a := assert.New(t)
+	modelDir, e := ioutil.TempDir("", "sqlflow_model_dir")
+	a.NoError(e)
+	defer os.RemoveAll(modelDir)
+
+	a.NoError(Validate("file://"+filepath.Join(modelDir, "my_dnn_model"), nil))
+	fis, e := ioutil.ReadDir(modelDir)
+	a.NoError(e)
+	a.Empty(fis)
+
+	a.Error(Validate("file://"+filepath.Join(modelDir, "absent", "my_dnn_model"), nil))
+	// the directory of the models of the groups is created
+	a.NoError(Validate("file://"+filepath.Join(modelDir, "my_dnn_model", "region=cn"), nil))
+	fis, e = ioutil.ReadDir(filepath.Join(modelDir, "my_dnn_model"))
+	a.NoError(e)
+	a.Empty(fis)
+	a.Error(Validate("file:/"+filepath.Join(modelDir, "my_dnn_model"), nil))
+	a.Error(Validate("oss://bucket/my_dnn_model", nil))
+}
+
+func TestValidateDB(t *testing.T) {
+	a := assert.New(t)
+	db := database.GetTestingDBSingleton()
+	session := database.GetSessionFromTestingDB()
+	table := "sqlflow_models.my_validated_model"
+	a.NoError(Validate(table, session))
+	a.False(sqlfs.Exists(db.DB, table))
+	a.False(sqlfs.Exists(db.DB, table+validateSuffix))
+}
@@ -0,0 +1,111 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+
+	pb "sqlflow.org/sqlflow/pkg/proto"
+)
+
+// Verify checks that the model saved at modelURI is loadable without
+// extracting it. It decodes the model meta, decrypts the tarball if the
+// model is encrypted, and reads every entry of the tarball to the end,
+// so the CRC-32 checksum of gzip, the SHA-256 checksums of the files
+// saved by this version of SQLFlow, and the authentication tags of an
+// encrypted model, are validated. It writes nothing to the local
+// filesystem.
+func Verify(modelURI string, session *pb.Session) error {
+	ctx := context.Background()
+	opts, e := withEncryptionKey(session, nil)
+	if e != nil {
+		return e
+	}
+	e = readModel(ctx, modelURI, session, func(m *Model, tarball io.Reader) error {
+		if m.Deduplicated {
+			return fmt.Errorf("verifying deduplicated models is not supported now")
+		}
+		if m.Encryption != "" {
+			r, e := decryptTarball(ctx, m, tarball, opts)
+			if e != nil {
+				return e
+			}
+			tarball = r
+		}
+		return verifyTarGz(tarball)
+	})
+	if e != nil {
+		return fmt.Errorf("verify model %s failed: %v", modelURI, e)
+	}
+	return nil
+}
+
+// verifyTarGz reads the tar-gzipped stream r to the end, and checks that
+// every entry can be extracted by untarGz.
+func verifyTarGz(r io.Reader) error {
+	gr, e := gzip.NewReader(r)
+	if e != nil {
+		return e
+	}
+	defer gr.Close()
+	// Any absolute directory works, entryTarget only checks the entry
+	// names.
+	root := filepath.FromSlash("/model")
+	tr := tar.NewReader(gr)
+	sums, want := sha256Sums{}, sha256Sums(nil)
+	for {
+		hdr, e := tr.Next()
+		if e == io.EOF {
+			break
+		}
+		if e != nil {
+			return e
+		}
+		if isSHA256SumsEntry(hdr.Name) {
+			if want, e = readSHA256Sums(tr); e != nil {
+				return e
+			}
+			continue
+		}
+		if _, e := entryTarget(root, hdr.Name); e != nil {
+			return fmt.Errorf("illegal entry %q: %v", hdr.Name, e)
+		}
+		h := sha256.New()
+		if _, e := io.Copy(h, tr); e != nil {
+			return fmt.Errorf("read entry %q failed: %v", hdr.Name, e)
+		}
+		if hdr.Typeflag == tar.TypeReg || hdr.Typeflag == tar.TypeRegA {
+			sums[path.Clean(hdr.Name)] = hex.EncodeToString(h.Sum(nil))
+		}
+	}
+	if want != nil {
+		if e := sums.check(want); e != nil {
+			return e
+		}
+	}
+	// The gzip reader validates the checksum after reading to its end.
+	if _, e := io.Copy(ioutil.Discard, gr); e != nil {
+		return e
+	}
+	return nil
+}
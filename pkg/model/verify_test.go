@@ -0,0 +1,140 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+)
+
+func TestVerify(t *testing.T) {
+	a := assert.New(t)
+	cwd := mockWorkDir(t)
+	defer os.RemoveAll(cwd)
+	modelDir, e := ioutil.TempDir("", "sqlflow_model_dir")
+	a.NoError(e)
+	defer os.RemoveAll(modelDir)
+
+	modelURI := "file://" + filepath.Join(modelDir, "my_dnn_model")
+	tarFile := filepath.Join(modelDir, "my_dnn_model.tar.gz")
+	a.NoError(New(cwd, testTrainSelect).Save(modelURI, nil, nil))
+	a.NoError(Verify(modelURI, nil))
+	b, e := ioutil.ReadFile(tarFile)
+	a.NoError(e)
+
+	// Flip a byte in the middle of the tarball.
+	corrupted := append([]byte{}, b...)
+	corrupted[len(b)/2] ^= 0xff
+	a.NoError(ioutil.WriteFile(tarFile, corrupted, 0644))
+	a.Error(Verify(modelURI, nil))
+
+	a.NoError(ioutil.WriteFile(tarFile, b[:len(b)-10], 0644))
+	a.Error(Verify(modelURI, nil))
+
+	// An entry that Load refuses to extract.
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	meta := []byte(`{"train_select":"SELECT 1"}`)
+	a.NoError(tw.WriteHeader(&tar.Header{Name: "my_dnn_model.json", Mode: 0644, Size: int64(len(meta)), Typeflag: tar.TypeReg}))
+	_, e = tw.Write(meta)
+	a.NoError(e)
+	a.NoError(tw.WriteHeader(&tar.Header{Name: "../evil", Mode: 0644, Typeflag: tar.TypeReg}))
+	a.NoError(tw.Close())
+	a.NoError(gw.Close())
+	a.NoError(ioutil.WriteFile(tarFile, buf.Bytes(), 0644))
+	e = Verify(modelURI, nil)
+	a.Error(e)
+	a.Contains(e.Error(), `illegal entry "../evil"`)
+}
+
+func TestVerifyChecksums(t *testing.T) {
+	a := assert.New(t)
+	cwd := mockWorkDir(t)
+	defer os.RemoveAll(cwd)
+	modelDir, e := ioutil.TempDir("", "sqlflow_model_dir")
+	a.NoError(e)
+	defer os.RemoveAll(modelDir)
+
+	modelURI := "file://" + filepath.Join(modelDir, "my_dnn_model")
+	tarFile := filepath.Join(modelDir, "my_dnn_model.tar.gz")
+	a.NoError(New(cwd, testTrainSelect).Save(modelURI, nil, nil))
+	dst, e := ioutil.TempDir("", "sqlflow_model_dst")
+	a.NoError(e)
+	defer os.RemoveAll(dst)
+	_, e = Load(modelURI, dst, nil)
+	a.NoError(e)
+	_, e = os.Stat(filepath.Join(dst, sha256SumsFile))
+	a.True(os.IsNotExist(e))
+
+	// Change the content of a file in a valid tarball.
+	f, e := os.Open(tarFile)
+	a.NoError(e)
+	gr, e := gzip.NewReader(f)
+	a.NoError(e)
+	b, e := ioutil.ReadAll(gr)
+	a.NoError(e)
+	f.Close()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	_, e = gw.Write(bytes.Replace(b, []byte("graph"), []byte("GRAPH"), 1))
+	a.NoError(e)
+	a.NoError(gw.Close())
+	a.NoError(ioutil.WriteFile(tarFile, buf.Bytes(), 0644))
+
+	e = Verify(modelURI, nil)
+	a.Error(e)
+	a.Contains(e.Error(), "checksum mismatch of file my_dnn_model/saved_model.pb")
+	dst2, e := ioutil.TempDir("", "sqlflow_model_dst")
+	a.NoError(e)
+	defer os.RemoveAll(dst2)
+	_, e = Load(modelURI, dst2, nil)
+	a.Error(e)
+	a.Contains(e.Error(), "checksum mismatch")
+	_, e = os.Stat(filepath.Join(dst2, "my_dnn_model"))
+	a.True(os.IsNotExist(e))
+}
+
+func TestVerifyEncrypted(t *testing.T) {
+	a := assert.New(t)
+	cwd := mockWorkDir(t)
+	defer os.RemoveAll(cwd)
+	modelDir, e := ioutil.TempDir("", "sqlflow_model_dir")
+	a.NoError(e)
+	defer os.RemoveAll(modelDir)
+
+	session := &pb.Session{ModelEncryptionKey: base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))}
+	modelURI := "file://" + filepath.Join(modelDir, "my_dnn_model")
+	a.NoError(New(cwd, testTrainSelect).Save(modelURI, nil, session))
+	a.NoError(Verify(modelURI, session))
+
+	wrongKey := &pb.Session{ModelEncryptionKey: base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32))}
+	a.Error(Verify(modelURI, wrongKey))
+
+	tarFile := filepath.Join(modelDir, "my_dnn_model.tar.gz")
+	b, e := ioutil.ReadFile(tarFile)
+	a.NoError(e)
+	b[len(b)-1] ^= 0xff
+	a.NoError(ioutil.WriteFile(tarFile, b, 0644))
+	a.Error(Verify(modelURI, session))
+}
@@ -0,0 +1,291 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"sqlflow.org/sqlflow/pkg/database"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+)
+
+// Version describes a version of a model saved by SaveVersion.
+type Version struct {
+	Name      string    // Name is the model name, like "my_db.my_model".
+	Version   int64     // Version starts from 1 and increments by one.
+	CreatedAt time.Time // CreatedAt is when the version was saved.
+	Tags      []string  // Tags are the sorted tags of the version set by TagVersion.
+}
+
+// modelTagTable maps the tags of a model name to its versions. A tag
+// names at most one version of a model, like a Docker image tag.
+const modelTagTable = "sqlflow.model_tags"
+
+// versionTable returns the sqlfs table holding the given version of name.
+func versionTable(name string, version int64) string {
+	return fmt.Sprintf("%s_v%d", name, version)
+}
+
+// createModelZooTable creates the trained_models table if it doesn't
+// exist. created_at is in Unix seconds, and is NULL if the version is
+// being saved.
+func createModelZooTable(db *database.DB) error {
+	if db.DriverName != "mysql" {
+		return fmt.Errorf("versioned models in %s is not supported now", db.DriverName)
+	}
+	stmts := []string{
+		fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s", modelZooDB),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+name VARCHAR(255) NOT NULL,
+version INT NOT NULL,
+created_at BIGINT NULL,
+PRIMARY KEY (name, version))`, modelZooTable),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+name VARCHAR(255) NOT NULL,
+tag VARCHAR(255) NOT NULL,
+version INT NOT NULL,
+PRIMARY KEY (name, tag))`, modelTagTable),
+	}
+	for _, stmt := range stmts {
+		if _, e := db.Exec(stmt); e != nil {
+			return fmt.Errorf("exec:[%s] failed: %v", stmt, e)
+		}
+	}
+	return nil
+}
+
+// SaveVersion saves the model as a new version of name, and returns the
+// version. Saves of different names don't interfere with each other.
+// If opts.KeepVersions > 0, older versions beyond the latest
+// opts.KeepVersions ones are deleted.
+func (m *Model) SaveVersion(name string, session *pb.Session, opts *Options) (int64, error) {
+	db, e := database.OpenAndConnectDB(session.DbConnStr)
+	if e != nil {
+		return 0, e
+	}
+	defer db.Close()
+	if opts, e = withEncryptionKey(session, opts); e != nil {
+		return 0, e
+	}
+	if e := createModelZooTable(db); e != nil {
+		return 0, e
+	}
+
+	version, e := reserveVersion(db, name)
+	if e != nil {
+		return 0, e
+	}
+	if _, e := m.saveDB(context.Background(), db, versionTable(name, version), session, opts); e != nil {
+		db.Exec(fmt.Sprintf("DELETE FROM %s WHERE name = ? AND version = ?", modelZooTable), name, version)
+		return 0, e
+	}
+	stmt := fmt.Sprintf("UPDATE %s SET created_at = ? WHERE name = ? AND version = ?", modelZooTable)
+	if _, e := db.Exec(stmt, time.Now().Unix(), name, version); e != nil {
+		return 0, fmt.Errorf("commit version %d of model %s failed: %v", version, name, e)
+	}
+
+	if opts != nil && opts.KeepVersions > 0 {
+		if e := pruneVersions(db, name, opts.KeepVersions); e != nil {
+			return 0, e
+		}
+	}
+	return version, nil
+}
+
+// reserveVersionRetries is how many times reserveVersion retries when a
+// concurrent Save of the same name reserved the same version.
+const reserveVersionRetries = 3
+
+// reserveVersion inserts a row of the next version of name with NULL
+// created_at, and returns the version.
+func reserveVersion(db *database.DB, name string) (int64, error) {
+	var e error
+	for i := 0; i < reserveVersionRetries; i++ {
+		var latest sql.NullInt64
+		stmt := fmt.Sprintf("SELECT MAX(version) FROM %s WHERE name = ?", modelZooTable)
+		if e = db.QueryRow(stmt, name).Scan(&latest); e != nil {
+			return 0, fmt.Errorf("reserve a version of model %s failed: %v", name, e)
+		}
+		version := latest.Int64 + 1
+		// The primary key (name, version) fails the insertion if a
+		// concurrent Save has reserved the version.
+		stmt = fmt.Sprintf("INSERT INTO %s (name, version) VALUES (?, ?)", modelZooTable)
+		if _, e = db.Exec(stmt, name, version); e == nil {
+			return version, nil
+		}
+	}
+	return 0, fmt.Errorf("reserve a version of model %s failed: %v", name, e)
+}
+
+// LoadVersion works like Load for the given version of name saved by
+// SaveVersion. Version 0 means the latest version.
+func LoadVersion(name string, version int64, dst string, db *database.DB) (*Model, error) {
+	if version == 0 {
+		stmt := fmt.Sprintf("SELECT MAX(version) FROM %s WHERE name = ? AND created_at IS NOT NULL", modelZooTable)
+		var latest sql.NullInt64
+		if e := db.QueryRow(stmt, name).Scan(&latest); e != nil {
+			return nil, fmt.Errorf("query the latest version of model %s failed: %v", name, e)
+		}
+		if !latest.Valid {
+			return nil, ErrModelNotFound
+		}
+		version = latest.Int64
+	}
+	return loadDB(context.Background(), db, versionTable(name, version), dst, nil)
+}
+
+// ListVersions returns the saved versions of name, the latest first.
+func ListVersions(name string, db *database.DB) ([]Version, error) {
+	// The tag table doesn't exist if the models were saved by older
+	// versions of SQLFlow.
+	if e := createModelZooTable(db); e != nil {
+		return nil, e
+	}
+	tags, e := versionTags(db, name)
+	if e != nil {
+		return nil, e
+	}
+	stmt := fmt.Sprintf(`SELECT version, created_at FROM %s
+WHERE name = ? AND created_at IS NOT NULL ORDER BY version DESC`, modelZooTable)
+	rows, e := db.Query(stmt, name)
+	if e != nil {
+		return nil, fmt.Errorf("list versions of model %s failed: %v", name, e)
+	}
+	defer rows.Close()
+	versions := []Version{}
+	for rows.Next() {
+		v := Version{Name: name}
+		var createdAt int64
+		if e := rows.Scan(&v.Version, &createdAt); e != nil {
+			return nil, e
+		}
+		v.CreatedAt = time.Unix(createdAt, 0)
+		v.Tags = tags[v.Version]
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
+
+// DeleteVersions removes all the versions of name saved by SaveVersion.
+// It returns ErrModelNotFound if there is no such version.
+func DeleteVersions(name string, db *database.DB) error {
+	versions, e := ListVersions(name, db)
+	if e != nil {
+		return e
+	}
+	if len(versions) == 0 {
+		return ErrModelNotFound
+	}
+	return pruneVersions(db, name, 0)
+}
+
+// pruneVersions deletes the versions of name older than the latest keep
+// ones.
+func pruneVersions(db *database.DB, name string, keep int) error {
+	versions, e := ListVersions(name, db)
+	if e != nil {
+		return e
+	}
+	if len(versions) <= keep {
+		return nil
+	}
+	for _, v := range versions[keep:] {
+		if e := deleteDB(db, versionTable(name, v.Version)); e != nil && e != ErrModelNotFound {
+			return e
+		}
+		for _, table := range []string{modelTagTable, modelZooTable} {
+			stmt := fmt.Sprintf("DELETE FROM %s WHERE name = ? AND version = ?", table)
+			if _, e := db.Exec(stmt, name, v.Version); e != nil {
+				return fmt.Errorf("delete version %d of model %s failed: %v", v.Version, name, e)
+			}
+		}
+	}
+	return nil
+}
+
+// versionTags returns the sorted tags of each version of name.
+func versionTags(db *database.DB, name string) (map[int64][]string, error) {
+	stmt := fmt.Sprintf("SELECT version, tag FROM %s WHERE name = ? ORDER BY tag", modelTagTable)
+	rows, e := db.Query(stmt, name)
+	if e != nil {
+		return nil, fmt.Errorf("list tags of model %s failed: %v", name, e)
+	}
+	defer rows.Close()
+	tags := map[int64][]string{}
+	for rows.Next() {
+		var version int64
+		var tag string
+		if e := rows.Scan(&version, &tag); e != nil {
+			return nil, e
+		}
+		tags[version] = append(tags[version], tag)
+	}
+	return tags, rows.Err()
+}
+
+// TagVersion tags the given version of name saved by SaveVersion, so the
+// version can be loaded by LoadTag, like tag "production" for rolling
+// back to a known good model. The tag is moved from the version it
+// tagged before, if any.
+func TagVersion(name string, version int64, tag string, db *database.DB) error {
+	if tag == "" {
+		return fmt.Errorf("the tag of model %s should not be empty", name)
+	}
+	if e := createModelZooTable(db); e != nil {
+		return e
+	}
+	var n int
+	stmt := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE name = ? AND version = ? AND created_at IS NOT NULL", modelZooTable)
+	if e := db.QueryRow(stmt, name, version).Scan(&n); e != nil {
+		return fmt.Errorf("query version %d of model %s failed: %v", version, name, e)
+	}
+	if n == 0 {
+		return ErrModelNotFound
+	}
+	stmt = fmt.Sprintf("REPLACE INTO %s (name, tag, version) VALUES (?, ?, ?)", modelTagTable)
+	if _, e := db.Exec(stmt, name, tag, version); e != nil {
+		return fmt.Errorf("tag version %d of model %s as %s failed: %v", version, name, tag, e)
+	}
+	return nil
+}
+
+// LoadTag works like LoadVersion for the version of name tagged by
+// TagVersion.
+func LoadTag(name, tag, dst string, db *database.DB) (*Model, error) {
+	version, e := taggedVersion(name, tag, db)
+	if e != nil {
+		return nil, e
+	}
+	return LoadVersion(name, version, dst, db)
+}
+
+// taggedVersion returns the version of name tagged by TagVersion, or
+// ErrModelNotFound if there is no such tag.
+func taggedVersion(name, tag string, db *database.DB) (int64, error) {
+	if e := createModelZooTable(db); e != nil {
+		return 0, e
+	}
+	var version int64
+	stmt := fmt.Sprintf("SELECT version FROM %s WHERE name = ? AND tag = ?", modelTagTable)
+	if e := db.QueryRow(stmt, name, tag).Scan(&version); e != nil {
+		if e == sql.ErrNoRows {
+			return 0, ErrModelNotFound
+		}
+		return 0, fmt.Errorf("query the version of model %s tagged %s failed: %v", name, tag, e)
+	}
+	return version, nil
+}
@@ -0,0 +1,43 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"sqlflow.org/sqlflow/pkg/ir"
+)
+
+// CheckWarmStart returns an error if trainStmt can't continue training
+// m, because m is trained by another estimator or on other columns.
+func (m *Model) CheckWarmStart(trainStmt *ir.TrainStmt) error {
+	if m.Metadata == nil || m.Schema == nil {
+		return fmt.Errorf("can't warm start from a model saved without metadata by an older version of SQLFlow")
+	}
+	if !strings.EqualFold(m.Metadata.Estimator, trainStmt.Estimator) {
+		return fmt.Errorf("can't warm start %s from a model trained by %s", trainStmt.Estimator, m.Metadata.Estimator)
+	}
+	want := schemaOf(trainStmt).Features
+	if len(want) != len(m.Schema.Features) {
+		return fmt.Errorf("can't warm start from a model trained on %d feature columns, received %d", len(m.Schema.Features), len(want))
+	}
+	for i, f := range m.Schema.Features {
+		if f.Name != want[i].Name || f.DType != want[i].DType || !reflect.DeepEqual(f.Shape, want[i].Shape) {
+			return fmt.Errorf("can't warm start from a model trained on the feature column %s %s%v, received %s %s%v", f.Name, f.DType, f.Shape, want[i].Name, want[i].DType, want[i].Shape)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sqlflow.org/sqlflow/pkg/ir"
+)
+
+func TestCheckWarmStart(t *testing.T) {
+	a := assert.New(t)
+	trainStmt := func(estimator string, names ...string) *ir.TrainStmt {
+		fcs := []ir.FeatureColumn{}
+		for _, name := range names {
+			fcs = append(fcs, &ir.NumericColumn{FieldDesc: &ir.FieldDesc{Name: name, DType: ir.Float, Shape: []int{1}}})
+		}
+		return &ir.TrainStmt{
+			Estimator: estimator,
+			Features:  map[string][]ir.FeatureColumn{"feature_columns": fcs},
+			Label:     &ir.NumericColumn{FieldDesc: &ir.FieldDesc{Name: "class", DType: ir.Int}},
+		}
+	}
+	prev := trainStmt("xgboost.gbtree", "sepal_length", "sepal_width")
+	m := &Model{Metadata: metadataOf(prev, nil, nil), Schema: schemaOf(prev)}
+
+	a.NoError(m.CheckWarmStart(trainStmt("XGBoost.gbtree", "sepal_length", "sepal_width")))
+	a.Error(m.CheckWarmStart(trainStmt("lightgbm.LGBMClassifier", "sepal_length", "sepal_width")))
+	a.Error(m.CheckWarmStart(trainStmt("xgboost.gbtree", "sepal_length")))
+	a.Error(m.CheckWarmStart(trainStmt("xgboost.gbtree", "sepal_width", "sepal_length")))
+
+	// Models saved by older versions have no metadata.
+	a.Error((&Model{}).CheckWarmStart(prev))
+}
@@ -21,12 +21,17 @@ import (
 
 	"sqlflow.org/sqlflow/pkg/database"
 	pb "sqlflow.org/sqlflow/pkg/proto"
+	"sqlflow.org/sqlflow/pkg/sqlfs"
 )
 
 const modelCollTable = "sqlflow_model_zoo.model_collection"
 const modelDefTable = "sqlflow_model_zoo.model_definition"
 const trainedModelTable = "sqlflow_model_zoo.trained_model"
 
+// contentChunkSize is the size of the content in each response of
+// GetTrainedModel, far below the 4MB limit of a gRPC message.
+const contentChunkSize = 64 * 1024
+
 // TODO(typhoonzero): create tables if these tables are not pre created?
 const createTableStmts = `CREATE DATABASE IF NOT EXISTS sqlflow_model_zoo;
 DROP TABLE IF EXISTS sqlflow_model_zoo.trained_model;
@@ -57,6 +62,8 @@ CREATE TABLE sqlflow_model_zoo.trained_model (
     url VARCHAR(255),
     description TEXT,
     metrics TEXT,
+    image VARCHAR(255),
+    requirements TEXT,
     PRIMARY KEY (id),
     FOREIGN KEY (model_def_id) REFERENCES model_definition(id)
 );`
@@ -94,7 +101,30 @@ func (s *modelZooServer) ListModelDefs(ctx context.Context, req *pb.ListModelReq
 }
 
 func (s *modelZooServer) ListTrainedModels(ctx context.Context, req *pb.ListModelRequest) (*pb.ListModelResponse, error) {
-	return &pb.ListModelResponse{}, nil
+	var sql string
+	if req.Size <= 0 {
+		sql = fmt.Sprintf("SELECT name, version FROM %s;", trainedModelTable)
+	} else {
+		sql = fmt.Sprintf("SELECT name, version FROM %s LIMIT %d OFFSET %d;",
+			trainedModelTable, req.Size, req.Start)
+	}
+	rows, err := s.DB.Query(sql)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	names := []string{}
+	tags := []string{}
+	for rows.Next() {
+		n := ""
+		tag := ""
+		if err := rows.Scan(&n, &tag); err != nil {
+			return nil, err
+		}
+		names = append(names, n)
+		tags = append(tags, tag)
+	}
+	return &pb.ListModelResponse{Names: names, Tags: tags, Size: int64(len(names))}, nil
 }
 
 func (s *modelZooServer) ReleaseModelDef(stream pb.ModelZooServer_ReleaseModelDefServer) error {
@@ -173,11 +203,151 @@ func (s *modelZooServer) DropModelDef(ctx context.Context, req *pb.ModelDefReque
 	return &pb.ModelResponse{Success: true, Message: ""}, nil
 }
 
+// ReleaseTrainedModel saves the content of the trained model to the
+// sqlfs table trainedModelTable_<id>, whose name is in the url column.
+// It replaces the trained model of the same name and tag, if any.
 func (s *modelZooServer) ReleaseTrainedModel(stream pb.ModelZooServer_ReleaseTrainedModelServer) error {
-	err := stream.SendAndClose(&pb.ModelResponse{})
-	return err
+	req, err := stream.Recv()
+	if err == io.EOF {
+		return fmt.Errorf("no trained model in the request")
+	}
+	if err != nil {
+		return err
+	}
+	if req.GetName() == "" || req.GetTag() == "" {
+		return fmt.Errorf("the name and the tag of the trained model should not be empty")
+	}
+	if err := s.dropTrainedModel(req.GetName(), req.GetTag()); err != nil {
+		return err
+	}
+	sql := fmt.Sprintf("INSERT INTO %s (name, version, description, metrics, image, requirements) VALUES (?, ?, ?, ?, ?, ?);", trainedModelTable)
+	res, err := s.DB.Exec(sql, req.GetName(), req.GetTag(), req.GetDescription(), req.GetEvaluationMetrics(), req.GetImage(), req.GetRequirements())
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	table := fmt.Sprintf("%s_%d", trainedModelTable, id)
+	if err := s.writeTrainedModel(stream, table, req.GetContentTar()); err != nil {
+		s.DB.Exec(fmt.Sprintf("DELETE FROM %s WHERE id=?;", trainedModelTable), id)
+		sqlfs.Remove(s.DB.DB, table)
+		return err
+	}
+	sql = fmt.Sprintf("UPDATE %s SET url=? WHERE id=?;", trainedModelTable)
+	if _, err := s.DB.Exec(sql, table, id); err != nil {
+		return err
+	}
+	return stream.SendAndClose(&pb.ModelResponse{Success: true, Message: ""})
+}
+
+// writeTrainedModel writes first and the content in the following
+// requests of stream to the sqlfs table.
+func (s *modelZooServer) writeTrainedModel(stream pb.ModelZooServer_ReleaseTrainedModelServer, table string, first []byte) error {
+	w, err := sqlfs.Create(s.DB.DB, s.DB.DriverName, table, &pb.Session{}, 0)
+	if err != nil {
+		return err
+	}
+	content := first
+	for {
+		if _, err := w.Write(content); err != nil {
+			w.Close()
+			return err
+		}
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			w.Close()
+			return err
+		}
+		content = req.GetContentTar()
+	}
+	return w.Close()
 }
 
 func (s *modelZooServer) DropTrainedModel(ctx context.Context, req *pb.TrainedModelRequest) (*pb.ModelResponse, error) {
-	return &pb.ModelResponse{}, nil
+	if err := s.dropTrainedModel(req.GetName(), req.GetTag()); err != nil {
+		return nil, err
+	}
+	return &pb.ModelResponse{Success: true, Message: ""}, nil
+}
+
+// dropTrainedModel deletes the trained model of name and tag and its
+// content, if any.
+func (s *modelZooServer) dropTrainedModel(name, tag string) error {
+	sql := fmt.Sprintf("SELECT id, url FROM %s WHERE name=? AND version=?;", trainedModelTable)
+	rows, err := s.DB.Query(sql, name, tag)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	ids := []int64{}
+	urls := []string{}
+	for rows.Next() {
+		var id int64
+		var url *string
+		if err := rows.Scan(&id, &url); err != nil {
+			return err
+		}
+		ids = append(ids, id)
+		if url != nil {
+			urls = append(urls, *url)
+		}
+	}
+	for _, url := range urls {
+		if err := sqlfs.Remove(s.DB.DB, url); err != nil {
+			return err
+		}
+	}
+	for _, id := range ids {
+		if _, err := s.DB.Exec(fmt.Sprintf("DELETE FROM %s WHERE id=?;", trainedModelTable), id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetTrainedModel sends the description of the trained model in the
+// first response, followed by the content.
+func (s *modelZooServer) GetTrainedModel(req *pb.TrainedModelRequest, stream pb.ModelZooServer_GetTrainedModelServer) error {
+	sql := fmt.Sprintf("SELECT url, description, metrics, image, requirements FROM %s WHERE name=? AND version=? AND url IS NOT NULL;", trainedModelTable)
+	var url, description, metrics, image, requirements string
+	err := s.DB.QueryRow(sql, req.GetName(), req.GetTag()).Scan(&url, &description, &metrics, &image, &requirements)
+	if err != nil {
+		return fmt.Errorf("no trained model %s:%s found: %v", req.GetName(), req.GetTag(), err)
+	}
+	r, err := sqlfs.Open(s.DB.DB, url)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	res := &pb.TrainedModelResponse{
+		Name:              req.GetName(),
+		Tag:               req.GetTag(),
+		Description:       description,
+		EvaluationMetrics: metrics,
+		Image:             image,
+		Requirements:      requirements,
+	}
+	if err := stream.Send(res); err != nil {
+		return err
+	}
+	buf := make([]byte, contentChunkSize)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if err := stream.Send(&pb.TrainedModelResponse{ContentTar: buf[:n]}); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
 }
@@ -16,6 +16,7 @@ package modelzooserver
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"net"
 	"strings"
@@ -87,4 +88,40 @@ func TestModelZooServer(t *testing.T) {
 	res, err = client.ListModelDefs(context.Background(), &pb.ListModelRequest{Start: 0, Size: -1})
 	a.NoError(err)
 	a.Equal(0, len(res.Names))
+
+	trainedStream, err := client.ReleaseTrainedModel(context.Background())
+	a.NoError(err)
+	trainedReq := &pb.TrainedModelRequest{Name: "my_dnn_model", Tag: "v0.1", Image: "group/mymodel:v0.1", ContentTar: []byte("model ")}
+	a.NoError(trainedStream.Send(trainedReq))
+	a.NoError(trainedStream.Send(&pb.TrainedModelRequest{ContentTar: []byte("content")}))
+	reply, err = trainedStream.CloseAndRecv()
+	a.NoError(err)
+	a.Equal(true, reply.Success)
+
+	res, err = client.ListTrainedModels(context.Background(), &pb.ListModelRequest{Start: 0, Size: -1})
+	a.NoError(err)
+	a.Equal([]string{"my_dnn_model"}, res.Names)
+	a.Equal([]string{"v0.1"}, res.Tags)
+
+	getStream, err := client.GetTrainedModel(context.Background(), trainedReq)
+	a.NoError(err)
+	content := []byte{}
+	first, err := getStream.Recv()
+	a.NoError(err)
+	a.Equal("group/mymodel:v0.1", first.Image)
+	for {
+		res, err := getStream.Recv()
+		if err == io.EOF {
+			break
+		}
+		a.NoError(err)
+		content = append(content, res.ContentTar...)
+	}
+	a.Equal("model content", string(content))
+
+	_, err = client.DropTrainedModel(context.Background(), trainedReq)
+	a.NoError(err)
+	res, err = client.ListTrainedModels(context.Background(), &pb.ListModelRequest{Start: 0, Size: -1})
+	a.NoError(err)
+	a.Equal(0, len(res.Names))
 }
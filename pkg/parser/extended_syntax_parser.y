@@ -63,12 +63,17 @@ type SQLFlowSelectStmt struct {
 	Explain  bool
 	Evaluate bool
 	ShowTrain bool
+	DropModel bool
+	ShowModels bool
+	ShowLineage bool
 	StandardSelect
 	TrainClause
 	PredictClause
 	ExplainClause
 	EvaluateClause
 	ShowTrainClause
+	DropModelClause
+	ShowLineageClause
 }
 
 type StandardSelect struct {
@@ -92,6 +97,7 @@ type PredictClause struct {
 	PredAttrs Attributes
 	Model     string
 	// FIXME(tony): rename into to predTable
+	// Into is empty or STDOUT if the prediction result goes to the client.
 	Into string
 }
 
@@ -114,6 +120,14 @@ type ShowTrainClause struct {
 	ModelName string
 }
 
+type DropModelClause struct {
+	ModelToDrop string
+}
+
+type ShowLineageClause struct {
+	LineageModel string
+}
+
 var parseResult *SQLFlowSelectStmt
 
 func attrsUnion(as1, as2 Attributes) Attributes {
@@ -143,11 +157,15 @@ func attrsUnion(as1, as2 Attributes) Attributes {
   expln ExplainClause
   evalt EvaluateClause
   shwtran ShowTrainClause
+  drpmdl DropModelClause
+  shwlng ShowLineageClause
 }
 
 %type  <eslt> sqlflow_select_stmt
 %type  <tran> train_clause
 %type  <shwtran> show_train_clause
+%type  <drpmdl> drop_model_clause
+%type  <shwlng> show_lineage_clause
 %type  <colc> column_clause
 %type  <labc> label_clause
 %type  <infr> predict_clause
@@ -159,7 +177,7 @@ func attrsUnion(as1, as2 Attributes) Attributes {
 %type  <atrs> attr
 %type  <atrs> attrs
 
-%token <val> SELECT FROM WHERE LIMIT TRAIN PREDICT EXPLAIN EVALUATE WITH COLUMN LABEL USING INTO FOR AS TO SHOW
+%token <val> SELECT FROM WHERE LIMIT TRAIN PREDICT EXPLAIN EVALUATE WITH COLUMN LABEL USING INTO FOR AS TO SHOW DROP MODEL MODELS LINEAGE
 %token <val> IDENT NUMBER STRING
 
 %left <val> AND OR
@@ -203,6 +221,23 @@ sqlflow_select_stmt
 		ShowTrain: true,
 		ShowTrainClause: $1}
 }
+| drop_model_clause end_of_stmt {
+	parseResult = &SQLFlowSelectStmt{
+		Extended: true,
+		DropModel: true,
+		DropModelClause: $1}
+}
+| SHOW MODELS end_of_stmt {
+	parseResult = &SQLFlowSelectStmt{
+		Extended: true,
+		ShowModels: true}
+}
+| show_lineage_clause end_of_stmt {
+	parseResult = &SQLFlowSelectStmt{
+		Extended: true,
+		ShowLineage: true,
+		ShowLineageClause: $1}
+}
 ;
 
 end_of_stmt
@@ -239,11 +274,22 @@ train_clause
 	$$.TrainAttrs = $5
 	$$.Save = $7
 }
+| TO TRAIN IDENT column_clause INTO IDENT {
+	$$.Estimator = $3
+	$$.Columns = $4
+	$$.Save = $6
+}
+| TO TRAIN IDENT INTO IDENT {
+	$$.Estimator = $3
+	$$.Save = $5
+}
 ;
 
 predict_clause
 : TO PREDICT IDENT USING IDENT { $$.Into = $3; $$.Model = $5 }
 | TO PREDICT IDENT WITH attrs USING IDENT { $$.Into = $3; $$.PredAttrs = $5; $$.Model = $7 }
+| TO PREDICT USING IDENT { $$.Model = $4 }
+| TO PREDICT WITH attrs USING IDENT { $$.PredAttrs = $4; $$.Model = $6 }
 ;
 
 explain_clause
@@ -262,6 +308,14 @@ show_train_clause
 : SHOW TRAIN IDENT { $$.ModelName = $3; }
 ;
 
+drop_model_clause
+: DROP MODEL IDENT { $$.ModelToDrop = $3; }
+;
+
+show_lineage_clause
+: SHOW LINEAGE FOR IDENT { $$.LineageModel = $4; }
+;
+
 optional_using
 : /* empty */  {}
 | USING IDENT  { $$ = $2 }
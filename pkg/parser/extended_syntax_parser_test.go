@@ -107,6 +107,26 @@ func TestExtendedSyntaxParseToTrainWithMultiColumns(t *testing.T) {
 	a.Equal("sqlflow_models.my_dnn_model", r.Save)
 }
 
+func TestExtendedSyntaxParseToTrainWithoutLabel(t *testing.T) {
+	a := assert.New(t)
+	for _, sql := range []string{
+		`TO TRAIN sklearn.IsolationForest COLUMN a, b INTO m;`,
+		`TO TRAIN sklearn.IsolationForest INTO m;`,
+		`TO TRAIN sklearn.IsolationForest WITH n_estimators = 50 COLUMN a, b INTO m;`,
+	} {
+		r, idx, e := parseSQLFlowStmt(sql)
+		a.NoError(e)
+		a.Equal(len(sql), idx)
+		a.True(r.Train)
+		a.Equal("sklearn.IsolationForest", r.Estimator)
+		a.Equal("", r.Label)
+		a.Equal("m", r.Save)
+	}
+	r, _, e := parseSQLFlowStmt(`TO TRAIN sklearn.IsolationForest COLUMN a, b INTO m;`)
+	a.NoError(e)
+	a.Equal("b", r.Columns["feature_columns"][1].String())
+}
+
 func TestExtendedSyntaxParseToPredict(t *testing.T) {
 	a := assert.New(t)
 	r, idx, e := parseSQLFlowStmt(testToPredict)
@@ -118,6 +138,22 @@ func TestExtendedSyntaxParseToPredict(t *testing.T) {
 	a.Equal("db.table.field", r.Into)
 }
 
+func TestExtendedSyntaxParseToPredictToClient(t *testing.T) {
+	a := assert.New(t)
+	for _, s := range []string{
+		`TO PREDICT USING sqlflow_models.my_dnn_model;`,
+		`TO PREDICT STDOUT USING sqlflow_models.my_dnn_model;`,
+		`TO PREDICT WITH predict.batch_size = 10 USING sqlflow_models.my_dnn_model;`,
+	} {
+		r, idx, e := parseSQLFlowStmt(s)
+		a.NoError(e)
+		a.Equal(len(s), idx)
+		a.True(r.Predict)
+		a.Equal("sqlflow_models.my_dnn_model", r.Model)
+		a.True(r.Into == "" || r.Into == "STDOUT")
+	}
+}
+
 func TestExtendedSyntaxParseToExplain(t *testing.T) {
 	a := assert.New(t)
 	s := `TO EXPLAIN my_model
@@ -183,15 +219,21 @@ INTO db.table;`
 func TestExtendedSyntaxParseNonSelectStmt(t *testing.T) {
 	a := assert.New(t)
 	{
-		r, idx, e := parseSQLFlowStmt(`DROP TABLE TO PREDICT`)
+		r, idx, e := parseSQLFlowStmt(`ALTER TABLE TO PREDICT`)
 		a.Nil(r)
 		a.Equal(0, idx)
 		a.Error(e)
 	}
 	{
-		r, idx, e := parseSQLFlowStmt(`   DROP TABLE TO PREDICT`)
+		r, idx, e := parseSQLFlowStmt(`   ALTER TABLE TO PREDICT`)
 		a.Nil(r)
-		a.Equal(3, idx) // right before DROP as there was an error.
+		a.Equal(3, idx) // right before ALTER as there was an error.
+		a.Error(e)
+	}
+	{
+		r, idx, e := parseSQLFlowStmt(`DROP TABLE TO PREDICT`)
+		a.Nil(r)
+		a.Equal(5, idx) // right before TABLE as DROP MODEL is extended.
 		a.Error(e)
 	}
 }
@@ -243,3 +285,59 @@ func TestExtendedShowTrainStmt(t *testing.T) {
 		a.Equal(11, idx)
 	}
 }
+
+func TestExtendedDropModelStmt(t *testing.T) {
+	a := assert.New(t)
+	{
+		testDropModel := `DROP MODEL sqlflow_models.my_dnn_model;`
+		r, idx, e := parseSQLFlowStmt(testDropModel)
+		a.NoError(e)
+		a.True(r.Extended)
+		a.True(r.DropModel)
+		a.Equal(`sqlflow_models.my_dnn_model`, r.ModelToDrop)
+		a.Equal(len(testDropModel), idx)
+	}
+	{
+		r, idx, e := parseSQLFlowStmt(`DROP MODEL ;`)
+		a.Nil(r)
+		a.Error(e)
+		a.Equal(11, idx)
+	}
+}
+
+func TestExtendedShowModelsStmt(t *testing.T) {
+	a := assert.New(t)
+	{
+		testShowModels := `SHOW MODELS;`
+		r, idx, e := parseSQLFlowStmt(testShowModels)
+		a.NoError(e)
+		a.True(r.Extended)
+		a.True(r.ShowModels)
+		a.False(r.ShowTrain)
+		a.Equal(len(testShowModels), idx)
+	}
+	{
+		r, _, e := parseSQLFlowStmt(`SHOW MODELS my_model;`)
+		a.Nil(r)
+		a.Error(e)
+	}
+}
+
+func TestExtendedShowLineageStmt(t *testing.T) {
+	a := assert.New(t)
+	{
+		testShowLineage := `SHOW LINEAGE FOR sqlflow_models.my_dnn_model;`
+		r, idx, e := parseSQLFlowStmt(testShowLineage)
+		a.NoError(e)
+		a.True(r.Extended)
+		a.True(r.ShowLineage)
+		a.False(r.ShowModels)
+		a.Equal(`sqlflow_models.my_dnn_model`, r.LineageModel)
+		a.Equal(len(testShowLineage), idx)
+	}
+	{
+		r, _, e := parseSQLFlowStmt(`SHOW LINEAGE sqlflow_models.my_dnn_model;`)
+		a.Nil(r)
+		a.Error(e)
+	}
+}
@@ -24,7 +24,8 @@ type javaParser struct {
 	typ string
 }
 
-// typ should be either "hive" or "calcite".
+// typ should be one of "hive", "calcite", "odps", "postgresql" and
+// "sparksql".
 func newJavaParser(typ string) *javaParser {
 	return &javaParser{typ: typ}
 }
@@ -38,6 +38,18 @@ func TestExternalParserCommonCasesForCalcite(t *testing.T) {
 	commonThirdPartyCases(p, a)
 }
 
+func TestExternalParserCommonCasesForPostgres(t *testing.T) {
+	a := assert.New(t)
+	p, _ := NewParser("postgres")
+	commonThirdPartyCases(p, a)
+}
+
+func TestExternalParserCommonCasesForSparkSQL(t *testing.T) {
+	a := assert.New(t)
+	p, _ := NewParser("sparksql")
+	commonThirdPartyCases(p, a)
+}
+
 // TODO(typhoonzero): add tests to test returned inputOutputTables.
 
 func TestExternalParserCommonCasesForAlisa(t *testing.T) {
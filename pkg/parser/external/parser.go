@@ -36,8 +36,12 @@ func NewParser(dialect string) (Parser, error) {
 		return newTiDBParser(), nil
 	case "hive":
 		return newJavaParser("hive"), nil
-	case "calcite", "maxcompute":
+	case "calcite", "maxcompute", "clickhouse", "sqlite3":
 		return newJavaParser("calcite"), nil
+	case "postgres":
+		return newJavaParser("postgresql"), nil
+	case "sparksql":
+		return newJavaParser("sparksql"), nil
 	case "alisa":
 		return newJavaParser("odps"), nil
 	default:
@@ -159,3 +159,45 @@ func (p *tidbParser) doParse(program string) ([]*Statement, int, error) {
 	// program is fully accepted
 	return retStmts, -1, nil
 }
+
+// TiDBTables returns the names of the tables in the MySQL statements sql,
+// like "iris.train" and "t" of "SELECT * FROM iris.train, t", by visiting
+// the table names in their ASTs, and the database of the last USE
+// statement in sql, or "" if there is none.
+func TiDBTables(sql string) ([]string, string, error) {
+	nodes, _, e := parser.New().Parse(sql, "", "")
+	if e != nil {
+		return nil, "", e
+	}
+	c := &tableNameCollector{tables: []string{}}
+	use := ""
+	for _, n := range nodes {
+		if u, ok := n.(*ast.UseStmt); ok {
+			use = u.DBName
+		}
+		n.Accept(c)
+	}
+	return c.tables, use, nil
+}
+
+// tableNameCollector collects the table names in an AST, which are in
+// the table sources of FROM and JOIN, the subqueries, and the targets of
+// INSERT, UPDATE, CREATE TABLE, DROP TABLE etc.
+type tableNameCollector struct {
+	tables []string
+}
+
+func (c *tableNameCollector) Enter(n ast.Node) (ast.Node, bool) {
+	if t, ok := n.(*ast.TableName); ok {
+		name := t.Name.O
+		if t.Schema.O != "" {
+			name = t.Schema.O + "." + name
+		}
+		c.tables = append(c.tables, name)
+	}
+	return n, false
+}
+
+func (c *tableNameCollector) Leave(n ast.Node) (ast.Node, bool) {
+	return n, true
+}
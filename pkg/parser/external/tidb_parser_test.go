@@ -173,3 +173,28 @@ func TestGetLeadingCommentLen(t *testing.T) {
 	a.Equal(25, p.getLeadingCommentLen("/* comment;*/--comment \n SELECT"))
 	a.Equal(25, p.getLeadingCommentLen("/* comment;*/\n--comment\n SELECT"))
 }
+
+func TestTiDBTables(t *testing.T) {
+	a := assert.New(t)
+	tables, use, e := TiDBTables("SELECT * FROM allowed.t, denied.secret;")
+	a.NoError(e)
+	a.Equal([]string{"allowed.t", "denied.secret"}, tables)
+	a.Equal("", use)
+
+	tables, _, e = TiDBTables("SELECT t.a FROM (iris.train t JOIN `churn`.`test` c ON t.b = c.b) WHERE t.a IN (SELECT a FROM x.y)")
+	a.NoError(e)
+	a.Equal([]string{"iris.train", "churn.test", "x.y"}, tables)
+	tables, _, e = TiDBTables("INSERT INTO iris.t SELECT * FROM (SELECT * FROM t2) s")
+	a.NoError(e)
+	a.Equal([]string{"iris.t", "t2"}, tables)
+	tables, _, e = TiDBTables("DROP TABLE IF EXISTS x; CREATE TABLE iris.t (a INT); UPDATE a.b SET c = 1")
+	a.NoError(e)
+	a.Equal([]string{"x", "iris.t", "a.b"}, tables)
+
+	tables, use, e = TiDBTables("USE secret; SELECT * FROM t")
+	a.NoError(e)
+	a.Equal([]string{"t"}, tables)
+	a.Equal("secret", use)
+	_, _, e = TiDBTables("SELECT * FROM")
+	a.Error(e)
+}
@@ -37,6 +37,7 @@ type lexer struct {
 	width    int    // width of last rune read from input
 	err      error  // the parser could return the error
 	previous int    // previous start, recorded for error position
+	last     int    // the type of the last emitted token
 }
 
 func newLexer(input string) *lexer {
@@ -55,6 +56,7 @@ func (l *lexer) emit(lval *extendedSyntaxSymType, typ int) int {
 	lval.val = l.input[l.start:l.pos]
 	l.previous = l.start
 	l.start = l.pos
+	l.last = typ
 	return typ
 }
 
@@ -145,10 +147,28 @@ func (l *lexer) emitIdentOrKeyword(lval *extendedSyntaxSymType) int {
 		"AS":       AS,
 		"TO":       TO,
 		"SHOW":     SHOW,
+		"DROP":     DROP,
 	}
 	if typ, ok := keywds[strings.ToUpper(l.input[l.start:l.pos])]; ok {
 		return l.emit(lval, typ)
 	}
+	// MODEL, MODELS and LINEAGE are keywords only in DROP MODEL, SHOW
+	// MODELS and SHOW LINEAGE, so that they are still valid identifiers
+	// like in "TO EXPLAIN model".
+	switch strings.ToUpper(l.input[l.start:l.pos]) {
+	case "MODEL":
+		if l.last == DROP {
+			return l.emit(lval, MODEL)
+		}
+	case "MODELS":
+		if l.last == SHOW {
+			return l.emit(lval, MODELS)
+		}
+	case "LINEAGE":
+		if l.last == SHOW {
+			return l.emit(lval, LINEAGE)
+		}
+	}
 	return l.emit(lval, IDENT)
 }
 
@@ -255,6 +255,30 @@ func TestShowTrain(t *testing.T) {
 	}
 }
 
+func TestDropModelAndShowModels(t *testing.T) {
+	a := assert.New(t)
+	types := []int{DROP, MODEL, IDENT, ';', SHOW, MODELS, ';', TO, EXPLAIN, IDENT, USING, IDENT}
+	vals := []string{"DROP", "model", "my_model", ";", "SHOW", "MODELS", ";", "TO", "EXPLAIN", "model", "USING", "models"}
+	l := newLexer(`DROP model my_model; SHOW MODELS; TO EXPLAIN model USING models`)
+	var n extendedSyntaxSymType
+	for i, t := range types {
+		a.Equal(t, l.Lex(&n))
+		a.Equal(vals[i], n.val)
+	}
+}
+
+func TestShowLineage(t *testing.T) {
+	a := assert.New(t)
+	types := []int{SHOW, LINEAGE, FOR, IDENT, ';', TO, EXPLAIN, IDENT}
+	vals := []string{"SHOW", "LINEAGE", "FOR", "my_model", ";", "TO", "EXPLAIN", "lineage"}
+	l := newLexer(`SHOW LINEAGE FOR my_model; TO EXPLAIN lineage`)
+	var n extendedSyntaxSymType
+	for i, t := range types {
+		a.Equal(t, l.Lex(&n))
+		a.Equal(vals[i], n.val)
+	}
+}
+
 func TestLexerUnmatchedQuotation(t *testing.T) {
 	a := assert.New(t)
 	l := newLexer(`TO TRAIN "some_thing`)
@@ -39,6 +39,40 @@ func (stmt *SQLFlowStmt) IsExtendedSyntax() bool {
 	return stmt.SQLFlowSelectStmt != nil
 }
 
+// Type returns the type of stmt, "standard", "train", "predict",
+// "explain", "evaluate", "show_train", "drop_model", "show_models" or
+// "show_lineage".
+func (stmt *SQLFlowStmt) Type() string {
+	switch {
+	case !stmt.IsExtendedSyntax():
+		return "standard"
+	case stmt.Train:
+		return "train"
+	case stmt.Predict:
+		return "predict"
+	case stmt.Explain:
+		return "explain"
+	case stmt.Evaluate:
+		return "evaluate"
+	case stmt.ShowTrain:
+		return "show_train"
+	case stmt.DropModel:
+		return "drop_model"
+	case stmt.ShowModels:
+		return "show_models"
+	case stmt.ShowLineage:
+		return "show_lineage"
+	}
+	return "unknown"
+}
+
+// isStandalone returns true if the extended statement has no standard
+// SELECT part, like SHOW TRAIN, DROP MODEL, SHOW MODELS and SHOW
+// LINEAGE.
+func (stmt *SQLFlowSelectStmt) isStandalone() bool {
+	return stmt.ShowTrain || stmt.DropModel || stmt.ShowModels || stmt.ShowLineage
+}
+
 // ParseStatement parses a SQL program by calling Parse, and
 // asserts that this program contains one and only one statement.
 func ParseStatement(dialect, program string) (*SQLFlowStmt, error) {
@@ -81,7 +115,7 @@ func Parse(dialect, program string) ([]*SQLFlowStmt, error) {
 		}
 		// SELECT ... .TO ...
 		if len(sqls) > 0 && sqls[len(sqls)-1].IsUnfinishedSelect {
-			if extended.ShowTrain {
+			if extended.isStandalone() {
 				return nil, fmt.Errorf("select should followed by 'to train/predict/explain'")
 			}
 			left := all[len(all)-1].Original
@@ -92,7 +126,7 @@ func Parse(dialect, program string) ([]*SQLFlowStmt, error) {
 			program = program[j:]
 		} else {
 			// Purely extended sql stmt
-			if !extended.ShowTrain {
+			if !extended.isStandalone() {
 				return nil, fmt.Errorf("invalid 'to train/predict/explain' with no 'select'")
 			}
 			sql := &SQLFlowStmt{Original: program[:j], SQLFlowSelectStmt: extended}
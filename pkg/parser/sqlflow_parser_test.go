@@ -261,6 +261,28 @@ SHOW TRAIN  sqlflow_fraud_detection_model;
 	}
 }
 
+func TestStatementType(t *testing.T) {
+	a := assert.New(t)
+	s, err := Parse("mysql", `SELECT 1;
+SELECT * FROM t TO TRAIN DNNClassifier LABEL c INTO m;
+SELECT * FROM t TO PREDICT p.c USING m;
+SELECT * FROM t TO EXPLAIN m;
+SELECT * FROM t TO EVALUATE m LABEL c INTO e;
+SHOW TRAIN m;
+DROP MODEL m;
+SHOW MODELS;
+SHOW LINEAGE FOR m;`)
+	a.NoError(err)
+	types := []string{}
+	for _, stmt := range s {
+		types = append(types, stmt.Type())
+	}
+	a.Equal([]string{"standard", "train", "predict", "explain", "evaluate", "show_train", "drop_model", "show_models", "show_lineage"}, types)
+	a.Equal("m", s[6].ModelToDrop)
+	a.Equal("SHOW MODELS;", s[7].Original)
+	a.Equal("m", s[8].LineageModel)
+}
+
 func TestParseFirstSQLStatement(t *testing.T) {
 	a := assert.New(t)
 
@@ -15,6 +15,7 @@ package pipe
 
 import (
 	"errors"
+	"sync"
 )
 
 // ErrClosedPipe will occur when manipulating an already closed pipe
@@ -24,8 +25,9 @@ var ErrClosedPipe = errors.New("pipe: write on closed pipe")
 // - wrCh: chan for piping data
 // - done: chan for signaling Close from Reader to Writer
 type pipe struct {
-	wrCh chan interface{}
-	done chan struct{}
+	wrCh  chan interface{}
+	done  chan struct{}
+	close sync.Once
 }
 
 // Reader reads real data
@@ -51,8 +53,10 @@ func Pipe() (*Reader, *Writer) {
 }
 
 // Close closes the reader; subsequent writes to the
+// write half of the pipe will return ErrClosedPipe. It is safe to call
+// Close more than once.
 func (r *Reader) Close() {
-	close(r.p.done)
+	r.p.close.Do(func() { close(r.p.done) })
 }
 
 // ReadAll returns the data chan. The caller should
@@ -67,6 +71,12 @@ func (w *Writer) Close() {
 	close(w.p.wrCh)
 }
 
+// Done returns a chan that is closed when the reader is closed, so the
+// writer can stop the work whose results are no longer read.
+func (w *Writer) Done() <-chan struct{} {
+	return w.p.done
+}
+
 // Write writes the item to the underlying data stream.
 // It returns ErrClosedPipe when the data stream is closed.
 func (w *Writer) Write(item interface{}) error {
@@ -69,4 +69,10 @@ func TestPipeReaderClose(t *testing.T) {
 	case <-time.After(time.Second):
 		a.True(false, "time out on writer return")
 	}
+	select {
+	case <-wr.Done():
+	default:
+		a.True(false, "writer is not done after the reader is closed")
+	}
+	rd.Close()
 }
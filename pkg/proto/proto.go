@@ -14,5 +14,11 @@
 //go:generate protoc --go_out=plugins=grpc:. sqlflow.proto
 //go:generate protoc --go_out=plugins=grpc:. parser.proto
 //go:generate protoc --go_out=plugins=grpc:. modelzooserver.proto
+//go:generate protoc --go_out=plugins=grpc:. serving.proto
 
 package proto
+
+// JobIDHeader is the key of the response header of Run whose value is
+// the id of the job to poll by FetchJobStatus, to cancel by CancelJob,
+// and to resume by Fetch.
+const JobIDHeader = "sqlflow-job-id"
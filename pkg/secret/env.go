@@ -0,0 +1,88 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secret
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	Register("env", openEnv)
+	Register("file", openFile)
+}
+
+// EnvPrefix is the prefix of the environment variables of the secrets of the
+// env provider. The workflow steps don't inherit these variables from the
+// server, so they are passed to the steps by SQLFLOW_WORKFLOW_SECRET.
+const EnvPrefix = "SQLFLOW_SECRET_"
+
+// IsSecretEnv returns if the environment variable name is a secret of the
+// env provider.
+func IsSecretEnv(name string) bool {
+	return strings.HasPrefix(name, EnvPrefix) && name != ProviderEnv
+}
+
+// Env returns the secret name in the environment variable SQLFLOW_SECRET_NAME,
+// where NAME is name in upper case with "." and "-" replaced by "_", like
+// SQLFLOW_SECRET_PROD_MYSQL_PASSWORD of prod_mysql_password.
+type Env struct{}
+
+func openEnv(u *url.URL) (Provider, error) {
+	return Env{}, nil
+}
+
+// Secret implements Provider.
+func (Env) Secret(ctx context.Context, name string) (string, error) {
+	key := EnvPrefix + strings.NewReplacer(".", "_", "-", "_").Replace(strings.ToUpper(name))
+	if key == ProviderEnv {
+		return "", fmt.Errorf("%s is not a secret", key)
+	}
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", key)
+	}
+	return v, nil
+}
+
+// File returns the secret name in the file name in Dir, like the files of a
+// Kubernetes Secret mounted as a volume. The trailing newline is removed.
+type File struct {
+	Dir string
+}
+
+// openFile opens the URI like file:///etc/sqlflow/secrets.
+func openFile(u *url.URL) (Provider, error) {
+	if u.Host != "" || u.Path == "" {
+		return nil, fmt.Errorf("file secret provider should be like file:///path/to/dir, got %s", u)
+	}
+	return File{Dir: filepath.FromSlash(u.Path)}, nil
+}
+
+// Secret implements Provider.
+func (f File) Secret(ctx context.Context, name string) (string, error) {
+	if !validName.MatchString(name) {
+		return "", fmt.Errorf("invalid secret name %q", name)
+	}
+	b, e := ioutil.ReadFile(filepath.Join(f.Dir, name))
+	if e != nil {
+		return "", e
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
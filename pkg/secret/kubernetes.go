@@ -0,0 +1,149 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secret
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+func init() {
+	Register("k8s", openKubernetes)
+}
+
+// The files of the service account of the Pods.
+var (
+	serviceAccountDir       = "/var/run/secrets/kubernetes.io/serviceaccount"
+	serviceAccountToken     = serviceAccountDir + "/token"
+	serviceAccountCA        = serviceAccountDir + "/ca.crt"
+	serviceAccountNamespace = serviceAccountDir + "/namespace"
+)
+
+// Kubernetes returns the secrets in the keys of a Kubernetes Secret by the
+// API server.
+type Kubernetes struct {
+	// Endpoint is the API server, like https://kubernetes.default.svc.
+	Endpoint  string
+	Namespace string
+	// Name is the name of the Secret.
+	Name string
+	// TokenFile is the bearer token of the requests, like the token of
+	// the service account, which is read by every request since it is
+	// rotated. No token is sent if it is "".
+	TokenFile string
+	Client    *http.Client
+}
+
+// openKubernetes opens the URI like k8s://namespace/secret, which requests
+// the API server of the cluster by the service account of the Pod, or
+// k8s+http://127.0.0.1:8001/namespace/secret, which requests the API server
+// at the host, like kubectl proxy, without a token. The namespace of
+// k8s:///secret is the one of the Pod.
+func openKubernetes(u *url.URL) (Provider, error) {
+	k := &Kubernetes{Client: http.DefaultClient}
+	path := strings.Trim(u.Path, "/")
+	if u.Scheme == "k8s" {
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if host == "" {
+			return nil, fmt.Errorf("%s should run in a Kubernetes cluster", u)
+		}
+		k.Endpoint = "https://" + net.JoinHostPort(host, port)
+		k.TokenFile = serviceAccountToken
+		ca, e := ioutil.ReadFile(serviceAccountCA)
+		if e != nil {
+			return nil, e
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("invalid CA certificate %s", serviceAccountCA)
+		}
+		k.Client = &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+		if u.Host != "" {
+			path = u.Host + "/" + path
+		}
+	} else {
+		scheme := strings.TrimPrefix(u.Scheme, "k8s+")
+		if scheme != "http" && scheme != "https" {
+			return nil, fmt.Errorf("unsupported Kubernetes API server scheme %s", scheme)
+		}
+		k.Endpoint = scheme + "://" + u.Host
+	}
+	ss := strings.Split(path, "/")
+	switch {
+	case len(ss) == 2 && ss[0] != "" && ss[1] != "":
+		k.Namespace, k.Name = ss[0], ss[1]
+	case len(ss) == 1 && ss[0] != "" && u.Scheme == "k8s":
+		ns, e := ioutil.ReadFile(serviceAccountNamespace)
+		if e != nil {
+			return nil, e
+		}
+		k.Namespace, k.Name = strings.TrimSpace(string(ns)), ss[0]
+	default:
+		return nil, fmt.Errorf("Kubernetes secret provider should be like k8s://namespace/secret, got %s", u)
+	}
+	ttl, e := cacheTTL(u)
+	if e != nil {
+		return nil, e
+	}
+	return newCache(k, ttl), nil
+}
+
+// Secret implements Provider.
+func (k *Kubernetes) Secret(ctx context.Context, name string) (string, error) {
+	req, e := http.NewRequest("GET", fmt.Sprintf("%s/api/v1/namespaces/%s/secrets/%s",
+		strings.TrimRight(k.Endpoint, "/"), url.PathEscape(k.Namespace), url.PathEscape(k.Name)), nil)
+	if e != nil {
+		return "", e
+	}
+	if k.TokenFile != "" {
+		token, e := ioutil.ReadFile(k.TokenFile)
+		if e != nil {
+			return "", e
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+	resp, e := k.Client.Do(req.WithContext(ctx))
+	if e != nil {
+		return "", e
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get Kubernetes secret %s/%s: %s", k.Namespace, k.Name, resp.Status)
+	}
+	var s struct {
+		Data map[string]string `json:"data"`
+	}
+	if e := json.NewDecoder(resp.Body).Decode(&s); e != nil {
+		return "", fmt.Errorf("failed to decode Kubernetes secret %s/%s: %v", k.Namespace, k.Name, e)
+	}
+	v, ok := s.Data[name]
+	if !ok {
+		return "", fmt.Errorf("Kubernetes secret %s/%s has no key %s", k.Namespace, k.Name, name)
+	}
+	b, e := base64.StdEncoding.DecodeString(v)
+	if e != nil {
+		return "", fmt.Errorf("invalid key %s of Kubernetes secret %s/%s: %v", name, k.Namespace, k.Name, e)
+	}
+	return string(b), nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secret
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKubernetes(t *testing.T) {
+	a := assert.New(t)
+	var auth, path string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, path = r.Header.Get("Authorization"), r.URL.Path
+		if path != "/api/v1/namespaces/sqlflow/secrets/sqlflow-secrets" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		// cEBzcw== is p@ss.
+		fmt.Fprint(w, `{"kind": "Secret", "data": {"prod_mysql_password": "cEBzcw=="}}`)
+	}))
+	defer ts.Close()
+
+	p, e := Open("k8s+" + ts.URL + "/sqlflow/sqlflow-secrets")
+	a.NoError(e)
+	v, e := p.Secret(context.Background(), "prod_mysql_password")
+	a.NoError(e)
+	a.Equal("p@ss", v)
+	a.Equal("", auth)
+	_, e = p.Secret(context.Background(), "missing")
+	a.EqualError(e, "Kubernetes secret sqlflow/sqlflow-secrets has no key missing")
+
+	token, e := ioutil.TempFile("", "sqlflow_token")
+	a.NoError(e)
+	defer os.Remove(token.Name())
+	token.WriteString("t0ken\n")
+	token.Close()
+	k := &Kubernetes{Endpoint: ts.URL, Namespace: "default", Name: "sqlflow-secrets", TokenFile: token.Name(), Client: http.DefaultClient}
+	_, e = k.Secret(context.Background(), "prod_mysql_password")
+	a.EqualError(e, "failed to get Kubernetes secret default/sqlflow-secrets: 404 Not Found")
+	a.Equal("Bearer t0ken", auth)
+
+	_, e = Open("k8s+" + ts.URL + "/sqlflow")
+	a.Error(e)
+	_, e = Open("k8s+ftp://host/sqlflow/sqlflow-secrets")
+	a.Error(e)
+}
+
+func TestVault(t *testing.T) {
+	a := assert.New(t)
+	var token string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token = r.Header.Get("X-Vault-Token")
+		switch r.URL.Path {
+		case "/v1/secret/data/sqlflow":
+			fmt.Fprint(w, `{"data": {"data": {"prod_mysql_password": "p@ss", "port": 3306}, "metadata": {"version": 1}}}`)
+		case "/v1/kv/sqlflow":
+			fmt.Fprint(w, `{"data": {"prod_mysql_password": "p@ss1"}}`)
+		default:
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer ts.Close()
+
+	os.Setenv("VAULT_TOKEN", "s.t0ken")
+	defer os.Unsetenv("VAULT_TOKEN")
+	p, e := Open("vault+" + ts.URL + "/secret/data/sqlflow")
+	a.NoError(e)
+	v, e := p.Secret(context.Background(), "prod_mysql_password")
+	a.NoError(e)
+	a.Equal("p@ss", v)
+	a.Equal("s.t0ken", token)
+	_, e = p.Secret(context.Background(), "port")
+	a.EqualError(e, "key port of Vault secret secret/data/sqlflow is not a string")
+
+	v, e = (&Vault{Endpoint: ts.URL, Path: "kv/sqlflow", Client: http.DefaultClient}).Secret(context.Background(), "prod_mysql_password")
+	a.NoError(e)
+	a.Equal("p@ss1", v)
+	_, e = (&Vault{Endpoint: ts.URL, Path: "kv/other", Client: http.DefaultClient}).Secret(context.Background(), "prod_mysql_password")
+	a.EqualError(e, "failed to read Vault secret kv/other: 403 Forbidden")
+
+	_, e = Open("vault://host:8200")
+	a.Error(e)
+	_, e = Open("vault+" + ts.URL + "/secret/data/sqlflow?ttl=never")
+	a.Error(e)
+}
@@ -0,0 +1,236 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package secret resolves the references to the secrets, like
+// ${secret:prod_mysql_password}, in the connection strings and the storage
+// credentials, so the passwords don't show up in the requests, the logs and
+// the workflows. The references are resolved by a Provider, like the
+// environment variables, the files, the Kubernetes Secrets or Vault, when
+// the statements run.
+package secret
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	pb "sqlflow.org/sqlflow/pkg/proto"
+)
+
+// ProviderEnv is the environment variable of the URI of the default
+// Provider, like file:///etc/sqlflow/secrets, or env if it is not set.
+const ProviderEnv = "SQLFLOW_SECRET_PROVIDER"
+
+var (
+	reference = regexp.MustCompile(`\$\{secret:([^}]*)\}`)
+	validName = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]*$`)
+)
+
+// Provider returns the values of the secrets.
+type Provider interface {
+	// Secret returns the value of the secret name.
+	Secret(ctx context.Context, name string) (string, error)
+}
+
+// Opener returns the Provider of the URI like k8s://namespace/secret.
+type Opener func(u *url.URL) (Provider, error)
+
+var (
+	openersMu sync.RWMutex
+	openers   = map[string]Opener{}
+)
+
+// Register makes the providers of the URIs name://... and name+scheme://...
+// opened by open. It replaces the Opener registered for name, if any.
+func Register(name string, open Opener) {
+	if name == "" || strings.ContainsAny(name, "+:/") {
+		panic(fmt.Sprintf("secret: invalid provider name %q", name))
+	}
+	if open == nil {
+		panic(fmt.Sprintf("secret: register nil opener %q", name))
+	}
+	openersMu.Lock()
+	defer openersMu.Unlock()
+	openers[name] = open
+}
+
+// Open returns the Provider of uri, like vault://host:8200/secret/data/sqlflow,
+// by the Opener registered for the scheme, or for the part of the scheme
+// before "+". A uri without "://", like env, is the scheme.
+func Open(uri string) (Provider, error) {
+	if !strings.Contains(uri, "://") {
+		uri += "://"
+	}
+	u, e := url.Parse(uri)
+	if e != nil {
+		return nil, fmt.Errorf("invalid secret provider %s: %v", uri, e)
+	}
+	name := strings.SplitN(u.Scheme, "+", 2)[0]
+	openersMu.RLock()
+	open, ok := openers[name]
+	openersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no secret provider is registered for %s", uri)
+	}
+	return open(u)
+}
+
+var (
+	defaultMu       sync.Mutex
+	defaultProvider Provider
+	defaultErr      error
+)
+
+// Default returns the Provider set by SetDefault, or the one opened by the
+// URI in the environment variable SQLFLOW_SECRET_PROVIDER.
+func Default() (Provider, error) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultProvider == nil && defaultErr == nil {
+		uri := os.Getenv(ProviderEnv)
+		if uri == "" {
+			uri = "env"
+		}
+		if defaultProvider, defaultErr = Open(uri); defaultErr != nil {
+			defaultErr = fmt.Errorf("%s: %v", ProviderEnv, defaultErr)
+		}
+	}
+	return defaultProvider, defaultErr
+}
+
+// SetDefault sets the Provider returned by Default.
+func SetDefault(p Provider) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultProvider, defaultErr = p, nil
+}
+
+// IsReference returns if s has references to secrets.
+func IsReference(s string) bool {
+	return reference.MatchString(s)
+}
+
+// Resolve replaces the references to secrets in s with their values by the
+// default Provider.
+func Resolve(s string) (string, error) {
+	if !IsReference(s) {
+		return s, nil
+	}
+	p, e := Default()
+	if e != nil {
+		return "", e
+	}
+	return ResolveWith(context.Background(), p, s)
+}
+
+// ResolveWith replaces the references to secrets in s with their values by
+// p. The errors have the names of the secrets, but never the values.
+func ResolveWith(ctx context.Context, p Provider, s string) (string, error) {
+	var b strings.Builder
+	last := 0
+	for _, m := range reference.FindAllStringSubmatchIndex(s, -1) {
+		name := s[m[2]:m[3]]
+		if !validName.MatchString(name) {
+			return "", fmt.Errorf("invalid secret name %q", name)
+		}
+		v, e := p.Secret(ctx, name)
+		if e != nil {
+			return "", fmt.Errorf("failed to resolve secret %s: %v", name, e)
+		}
+		b.WriteString(s[last:m[0]])
+		b.WriteString(v)
+		last = m[1]
+	}
+	b.WriteString(s[last:])
+	return b.String(), nil
+}
+
+// ResolveSession returns a copy of session with the references to secrets
+// in the connection string, the token and the credentials of the model
+// storage resolved. session is not modified, so the requests, the job
+// store and the workflows keep the references.
+func ResolveSession(session *pb.Session) (*pb.Session, error) {
+	if session == nil {
+		return nil, nil
+	}
+	r := *session
+	for _, f := range []*string{&r.DbConnStr, &r.Token, &r.HdfsPass,
+		&r.S3AccessKeyId, &r.S3SecretAccessKey, &r.ModelEncryptionKey,
+		&r.AzureAccountKey, &r.AzureSasToken, &r.OssAccessKeyId, &r.OssAccessKeySecret} {
+		v, e := Resolve(*f)
+		if e != nil {
+			return nil, e
+		}
+		*f = v
+	}
+	return &r, nil
+}
+
+// cache keeps the values of the secrets of a Provider for ttl, so the remote
+// providers are not requested by every connection.
+type cache struct {
+	p   Provider
+	ttl time.Duration
+
+	mu     sync.Mutex
+	values map[string]cachedValue
+}
+
+type cachedValue struct {
+	value   string
+	expires time.Time
+}
+
+func newCache(p Provider, ttl time.Duration) Provider {
+	if ttl <= 0 {
+		return p
+	}
+	return &cache{p: p, ttl: ttl, values: map[string]cachedValue{}}
+}
+
+// Secret implements Provider.
+func (c *cache) Secret(ctx context.Context, name string) (string, error) {
+	c.mu.Lock()
+	v, ok := c.values[name]
+	c.mu.Unlock()
+	if ok && time.Now().Before(v.expires) {
+		return v.value, nil
+	}
+	s, e := c.p.Secret(ctx, name)
+	if e != nil {
+		return "", e
+	}
+	c.mu.Lock()
+	c.values[name] = cachedValue{s, time.Now().Add(c.ttl)}
+	c.mu.Unlock()
+	return s, nil
+}
+
+// cacheTTL returns the duration in the query ttl of u, like ttl=5m, or a
+// minute by default.
+func cacheTTL(u *url.URL) (time.Duration, error) {
+	ttl := u.Query().Get("ttl")
+	if ttl == "" {
+		return time.Minute, nil
+	}
+	d, e := time.ParseDuration(ttl)
+	if e != nil {
+		return 0, fmt.Errorf("invalid ttl %s: %v", ttl, e)
+	}
+	return d, nil
+}
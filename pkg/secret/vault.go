@@ -0,0 +1,119 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secret
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+func init() {
+	Register("vault", openVault)
+}
+
+// Vault returns the secrets in the keys of a secret of the KV secrets engine
+// of HashiCorp Vault, version 1 or 2.
+type Vault struct {
+	// Endpoint is like https://vault:8200.
+	Endpoint string
+	// Path is the API path of the secret without /v1, like
+	// secret/data/sqlflow of the secret sqlflow of the KV version 2
+	// engine at secret/.
+	Path string
+	// Token is the Vault token, and Namespace is the namespace of Vault
+	// Enterprise, if any.
+	Token     string
+	Namespace string
+	Client    *http.Client
+}
+
+// openVault opens the URI like vault://vault:8200/secret/data/sqlflow, or
+// vault+http://... for a Vault without TLS, by the token in the environment
+// variable VAULT_TOKEN, and the namespace in VAULT_NAMESPACE.
+func openVault(u *url.URL) (Provider, error) {
+	scheme := "https"
+	if u.Scheme != "vault" {
+		scheme = strings.TrimPrefix(u.Scheme, "vault+")
+	}
+	if scheme != "http" && scheme != "https" {
+		return nil, fmt.Errorf("unsupported Vault scheme %s", scheme)
+	}
+	path := strings.Trim(u.Path, "/")
+	if u.Host == "" || path == "" {
+		return nil, fmt.Errorf("Vault secret provider should be like vault://host:8200/secret/data/name, got %s", u)
+	}
+	ttl, e := cacheTTL(u)
+	if e != nil {
+		return nil, e
+	}
+	return newCache(&Vault{
+		Endpoint:  scheme + "://" + u.Host,
+		Path:      path,
+		Token:     os.Getenv("VAULT_TOKEN"),
+		Namespace: os.Getenv("VAULT_NAMESPACE"),
+		Client:    http.DefaultClient,
+	}, ttl), nil
+}
+
+// Secret implements Provider.
+func (v *Vault) Secret(ctx context.Context, name string) (string, error) {
+	req, e := http.NewRequest("GET", strings.TrimRight(v.Endpoint, "/")+"/v1/"+v.Path, nil)
+	if e != nil {
+		return "", e
+	}
+	if v.Token != "" {
+		req.Header.Set("X-Vault-Token", v.Token)
+	}
+	if v.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.Namespace)
+	}
+	resp, e := v.Client.Do(req.WithContext(ctx))
+	if e != nil {
+		return "", e
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to read Vault secret %s: %s", v.Path, resp.Status)
+	}
+	var s struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if e := json.NewDecoder(resp.Body).Decode(&s); e != nil {
+		return "", fmt.Errorf("failed to decode Vault secret %s: %v", v.Path, e)
+	}
+	data := s.Data
+	// The KV version 2 engine returns the keys in data.data.
+	if _, ok := data["metadata"]; ok {
+		if d, ok := data["data"]; ok {
+			data = nil
+			if e := json.Unmarshal(d, &data); e != nil {
+				return "", fmt.Errorf("failed to decode Vault secret %s: %v", v.Path, e)
+			}
+		}
+	}
+	raw, ok := data[name]
+	if !ok {
+		return "", fmt.Errorf("Vault secret %s has no key %s", v.Path, name)
+	}
+	var value string
+	if e := json.Unmarshal(raw, &value); e != nil {
+		return "", fmt.Errorf("key %s of Vault secret %s is not a string", name, v.Path)
+	}
+	return value, nil
+}
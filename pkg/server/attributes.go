@@ -0,0 +1,59 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "sqlflow.org/sqlflow/pkg/proto"
+	sf "sqlflow.org/sqlflow/pkg/sql"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/attribute"
+)
+
+// GetAttributes implements `rpc GetAttributes (AttributesRequest) returns (AttributesResponse)`
+func (s *Server) GetAttributes(ctx context.Context, req *pb.AttributesRequest) (*pb.AttributesResponse, error) {
+	if req.Estimator == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "no estimator")
+	}
+	dict, e := sf.AttributeDictionary(req.Estimator)
+	if e != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", e)
+	}
+	res := &pb.AttributesResponse{}
+	for name, desc := range dict {
+		typ := ""
+		if desc.Type != attribute.Unknown {
+			typ = desc.Type.String()
+		}
+		def, e := json.Marshal(desc.Default)
+		if e != nil {
+			return nil, e
+		}
+		res.Attributes = append(res.Attributes, &pb.AttributesResponse_Attribute{
+			Name:    name,
+			Type:    typ,
+			Default: string(def),
+			Doc:     desc.Doc,
+		})
+	}
+	sort.Slice(res.Attributes, func(i, j int) bool {
+		return res.Attributes[i].Name < res.Attributes[j].Name
+	})
+	return res, nil
+}
@@ -0,0 +1,220 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	pb "sqlflow.org/sqlflow/pkg/proto"
+)
+
+var (
+	// cursorBufferSize is the max number of the buffered responses of a
+	// resumable job, whose statement waits for the client to read them
+	// if the buffer is full.
+	cursorBufferSize = 10000
+	// cursorRetained is the number of the responses sent by Run that the
+	// server keeps for the client to resume from.
+	cursorRetained = 1000
+	// cursorIdleTimeout is how long the server keeps the responses of a
+	// resumable job that no client reads. A job waiting for the client
+	// is cancelled after then.
+	cursorIdleTimeout = 10 * time.Minute
+	// defaultFetchPageSize is the number of the responses returned by
+	// Fetch if the page size is not specified.
+	defaultFetchPageSize = 1000
+)
+
+var errCursorExpired = errors.New("the results expired without being fetched")
+
+// cursor buffers the responses of a resumable job for Run and Fetch.
+type cursor struct {
+	mu        sync.Mutex
+	responses []*pb.Response
+	// offset is the offset of responses[0] in all the responses.
+	offset   int64
+	eof      bool
+	err      error
+	expired  bool
+	cancel   func()
+	lastRead time.Time
+	// changed is closed and renewed on every change of the cursor.
+	changed chan struct{}
+}
+
+func newCursor(cancel func()) *cursor {
+	return &cursor{cancel: cancel, lastRead: time.Now(), changed: make(chan struct{})}
+}
+
+// notify wakes up the waiters of c. It must be called with c.mu locked.
+func (c *cursor) notify() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// append buffers res, and waits if the buffer is full. It returns an
+// error if the cursor expires.
+func (c *cursor) append(res *pb.Response) error {
+	for {
+		c.mu.Lock()
+		if c.expired {
+			c.mu.Unlock()
+			return errCursorExpired
+		}
+		if len(c.responses) < cursorBufferSize {
+			c.responses = append(c.responses, res)
+			c.notify()
+			c.mu.Unlock()
+			return nil
+		}
+		changed, idle := c.changed, cursorIdleTimeout-time.Since(c.lastRead)
+		c.mu.Unlock()
+		select {
+		case <-changed:
+		case <-time.After(idle):
+			c.expireIfIdle()
+		}
+	}
+}
+
+// expireIfIdle expires c and cancels the job if no client reads c within
+// cursorIdleTimeout.
+func (c *cursor) expireIfIdle() {
+	c.mu.Lock()
+	expire := !c.expired && time.Since(c.lastRead) >= cursorIdleTimeout
+	if expire {
+		c.expired, c.responses = true, nil
+		c.notify()
+	}
+	c.mu.Unlock()
+	if expire {
+		c.cancel()
+	}
+}
+
+// close records the end of the responses, and the error e of the job.
+func (c *cursor) close(e error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.eof, c.err = true, e
+	c.notify()
+}
+
+// read drops the responses before drop, and returns at most n responses
+// from offset, and if they are the last ones. It returns the error of
+// the job after the last responses are read.
+func (c *cursor) read(offset, drop int64, n int) ([]*pb.Response, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.expired {
+		return nil, false, errCursorExpired
+	}
+	c.lastRead = time.Now()
+	if k := drop - c.offset; k > 0 {
+		if k > int64(len(c.responses)) {
+			k = int64(len(c.responses))
+		}
+		c.responses = c.responses[k:]
+		c.offset += k
+		c.notify()
+	}
+	if offset < c.offset {
+		return nil, false, fmt.Errorf("the responses before %d were dropped", c.offset)
+	}
+	i := offset - c.offset
+	if i > int64(len(c.responses)) {
+		return nil, false, fmt.Errorf("no response at %d", offset)
+	}
+	j := i + int64(n)
+	if j > int64(len(c.responses)) {
+		j = int64(len(c.responses))
+	}
+	page := append([]*pb.Response{}, c.responses[i:j]...)
+	if !c.eof || j < int64(len(c.responses)) || (len(page) > 0 && c.err != nil) {
+		return page, false, nil
+	}
+	return page, true, c.err
+}
+
+// stream sends the responses of c to the client of Run until the last
+// one, and returns the error of the job. It returns earlier if the client
+// disconnects, and the client may resume from the responses received.
+func (c *cursor) stream(stream pb.SQLFlow_RunServer) error {
+	offset := int64(0)
+	for {
+		c.mu.Lock()
+		changed := c.changed
+		c.mu.Unlock()
+		page, eof, e := c.read(offset, offset-int64(cursorRetained), cursorBufferSize)
+		for _, res := range page {
+			if e := stream.Send(res); e != nil {
+				return e
+			}
+			offset++
+		}
+		if eof || e != nil {
+			return e
+		}
+		if len(page) == 0 {
+			select {
+			case <-changed:
+			case <-stream.Context().Done():
+				return stream.Context().Err()
+			}
+		}
+	}
+}
+
+// idle returns if c expired, or all the responses are buffered and no
+// client reads c within cursorIdleTimeout.
+func (c *cursor) idle() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.expired || (c.eof && time.Since(c.lastRead) >= cursorIdleTimeout)
+}
+
+// cursorRegistry is the cursors of the resumable jobs. The zero value is
+// an empty registry.
+type cursorRegistry struct {
+	mu      sync.Mutex
+	cursors map[string]*cursor
+}
+
+// open returns a new cursor of the job id cancelled by cancel, and drops
+// the idle cursors.
+func (r *cursorRegistry) open(id string, cancel func()) *cursor {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cursors == nil {
+		r.cursors = map[string]*cursor{}
+	}
+	for id, c := range r.cursors {
+		if c.idle() {
+			delete(r.cursors, id)
+		}
+	}
+	c := newCursor(cancel)
+	r.cursors[id] = c
+	return c
+}
+
+// get returns the cursor of the job id, nil if not found.
+func (r *cursorRegistry) get(id string) *cursor {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cursors[id]
+}
@@ -0,0 +1,268 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"sqlflow.org/sqlflow/pkg/auth"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+)
+
+// The paths of the RPCs in the HTTP gateway.
+const (
+	httpRunPath   = "/v1/run"
+	httpFetchPath = "/v1/fetch"
+)
+
+// jsonMarshaler writes the messages with the field names in the proto
+// file, like db_conn_str.
+var jsonMarshaler = &jsonpb.Marshaler{OrigName: true}
+
+// gateway serves the RPCs Run and Fetch of s by HTTP and JSON.
+type gateway struct {
+	s           *Server
+	auth        auth.Authenticator
+	allowOrigin string
+}
+
+// NewHTTPHandler returns the HTTP handler serving the RPCs Run and Fetch
+// of s by JSON, for the clients without gRPC, like the browsers.
+//
+//	POST /v1/run    takes a pb.Request, and streams the pb.Responses
+//	POST /v1/fetch  takes a pb.FetchRequest, and returns a pb.FetchResponse
+//
+// a authenticates the callers like the interceptors of the gRPC server,
+// and nil means no authentication. If allowOrigin is not "", the
+// responses allow the cross-origin requests from it, or from any origin
+// if it is "*".
+func NewHTTPHandler(s *Server, a auth.Authenticator, allowOrigin string) http.Handler {
+	g := &gateway{s: s, auth: a, allowOrigin: allowOrigin}
+	mux := http.NewServeMux()
+	mux.HandleFunc(httpRunPath, g.handle(g.run))
+	mux.HandleFunc(httpFetchPath, g.handle(g.fetch))
+	return mux
+}
+
+// handle authenticates the POST requests for f, and answers the CORS
+// preflight requests.
+func (g *gateway) handle(f func(ctx context.Context, w http.ResponseWriter, r *http.Request) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if g.allowOrigin != "" {
+			h := w.Header()
+			h.Set("Access-Control-Allow-Origin", g.allowOrigin)
+			h.Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+			h.Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+			h.Set("Access-Control-Expose-Headers", pb.JobIDHeader)
+			if r.Method == http.MethodOptions {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			writeHTTPError(w, http.StatusMethodNotAllowed, status.Errorf(codes.Unimplemented, "method %s is not allowed", r.Method))
+			return
+		}
+		ctx, e := auth.HTTPContext(r, g.auth)
+		if e == nil {
+			e = f(ctx, w, r)
+		}
+		if e != nil {
+			writeHTTPError(w, httpStatus(status.Code(e)), e)
+		}
+	}
+}
+
+// run streams the responses of Run as the lines of JSON, or as the
+// server-sent events if the request accepts text/event-stream. It
+// returns the errors before streaming, and writes the error after
+// streaming as the last line or the event "error".
+func (g *gateway) run(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	req := &pb.Request{}
+	if e := jsonpb.Unmarshal(r.Body, req); e != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid request: %v", e)
+	}
+	stream := &httpRunServer{ctx: ctx, w: w, sse: strings.Contains(r.Header.Get("Accept"), "text/event-stream")}
+	e := g.s.Run(req, stream)
+	if !stream.started {
+		if e == nil {
+			stream.start()
+		}
+		return e
+	}
+	if e != nil {
+		stream.writeError(e)
+	}
+	return nil
+}
+
+func (g *gateway) fetch(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	req := &pb.FetchRequest{}
+	if e := jsonpb.Unmarshal(r.Body, req); e != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid request: %v", e)
+	}
+	res, e := g.s.Fetch(ctx, req)
+	if e != nil {
+		return e
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return jsonMarshaler.Marshal(w, res)
+}
+
+// httpRunServer is the pb.SQLFlow_RunServer writing the responses to an
+// HTTP response.
+type httpRunServer struct {
+	grpc.ServerStream
+	ctx     context.Context
+	w       http.ResponseWriter
+	sse     bool // write the server-sent events instead of the JSON lines
+	started bool
+}
+
+func (s *httpRunServer) Context() context.Context {
+	return s.ctx
+}
+
+// SetHeader sets the HTTP headers by md before the first response.
+func (s *httpRunServer) SetHeader(md metadata.MD) error {
+	if s.started {
+		return fmt.Errorf("the header has been sent")
+	}
+	for k, v := range md {
+		for _, v := range v {
+			s.w.Header().Add(k, v)
+		}
+	}
+	return nil
+}
+
+func (s *httpRunServer) SendHeader(md metadata.MD) error {
+	if e := s.SetHeader(md); e != nil {
+		return e
+	}
+	s.start()
+	return nil
+}
+
+func (s *httpRunServer) SetTrailer(metadata.MD) {}
+
+func (s *httpRunServer) start() {
+	if s.started {
+		return
+	}
+	s.started = true
+	if s.sse {
+		s.w.Header().Set("Content-Type", "text/event-stream")
+		s.w.Header().Set("Cache-Control", "no-cache")
+	} else {
+		s.w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+	s.w.WriteHeader(http.StatusOK)
+	s.flush()
+}
+
+func (s *httpRunServer) Send(res *pb.Response) error {
+	b, e := jsonMarshaler.MarshalToString(res)
+	if e != nil {
+		return e
+	}
+	return s.writeLine("", b)
+}
+
+// writeError writes e as a JSON object with the field "error".
+func (s *httpRunServer) writeError(e error) {
+	st := status.Convert(e)
+	b, _ := json.Marshal(map[string]interface{}{"error": httpErrorBody{int(st.Code()), st.Message()}})
+	s.writeLine("error", string(b))
+}
+
+// writeLine writes b, which has no line breaks, as the data of the
+// event, or as a line if not sse.
+func (s *httpRunServer) writeLine(event, b string) error {
+	s.start()
+	var e error
+	if !s.sse {
+		_, e = fmt.Fprintf(s.w, "%s\n", b)
+	} else if event != "" {
+		_, e = fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, b)
+	} else {
+		_, e = fmt.Fprintf(s.w, "data: %s\n\n", b)
+	}
+	if e != nil {
+		return e
+	}
+	s.flush()
+	return nil
+}
+
+func (s *httpRunServer) flush() {
+	if f, ok := s.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+type httpErrorBody struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// writeHTTPError writes e as the JSON object {"error": {"code": <the gRPC
+// status code>, "message": <the message>}} with the HTTP status code.
+func writeHTTPError(w http.ResponseWriter, code int, e error) {
+	st := status.Convert(e)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]interface{}{"error": httpErrorBody{int(st.Code()), st.Message()}})
+}
+
+// httpStatus maps the gRPC status codes to the HTTP status codes like
+// grpc-gateway.
+func httpStatus(c codes.Code) int {
+	switch c {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499 // client closed request
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
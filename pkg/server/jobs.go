@@ -0,0 +1,237 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"sync"
+	"time"
+
+	"sqlflow.org/sqlflow/pkg/jobstore"
+	"sqlflow.org/sqlflow/pkg/log"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+)
+
+// The phases of the jobs.
+const (
+	JobPending   = "Pending"
+	JobRunning   = "Running"
+	JobSucceeded = "Succeeded"
+	JobFailed    = "Failed"
+	JobCancelled = "Cancelled"
+)
+
+// jobTTL is how long the server keeps the status of a finished job, and
+// the user of a submitted workflow.
+var jobTTL = 24 * time.Hour
+
+type job struct {
+	jobstore.Job
+	cancel    func()
+	cancelled bool
+	// workflow is true for the workflows submitted by the jobs, whose
+	// status is of the workflow backend.
+	workflow bool
+}
+
+// jobRegistry is the jobs running on the server, and the finished ones
+// within jobTTL. The zero value is an empty registry.
+type jobRegistry struct {
+	mu   sync.Mutex
+	jobs map[string]*job
+	// store persists the jobs if not nil.
+	store jobstore.Store
+}
+
+// recover loads the jobs in store, and persists the jobs to it. The
+// jobs unfinished in store were interrupted by the restart of the
+// server, except for the workflows they submitted.
+func (r *jobRegistry) recover(store jobstore.Store) error {
+	now := time.Now()
+	if e := store.Prune(now.Add(-jobTTL)); e != nil {
+		return e
+	}
+	jobs, e := store.Load(now.Add(-jobTTL))
+	if e != nil {
+		return e
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.jobs == nil {
+		r.jobs = map[string]*job{}
+	}
+	r.store = store
+	for _, j := range jobs {
+		if j.EndTime.IsZero() {
+			j.Phase, j.Message, j.EndTime = JobFailed, "interrupted by the restart of the server", now
+			if e := store.Save(j); e != nil {
+				return e
+			}
+		}
+		r.jobs[j.ID] = &job{Job: *j}
+		if j.WorkflowID != "" {
+			r.jobs[j.WorkflowID] = newWorkflowJob(j)
+		}
+	}
+	return nil
+}
+
+// persist saves job to the store. It must be called without r.mu
+// locked.
+func (r *jobRegistry) persist(job jobstore.Job) {
+	if r.store == nil {
+		return
+	}
+	if e := r.store.Save(&job); e != nil {
+		log.GetDefaultLogger().Errorf("failed to save job %s: %v", job.ID, e)
+	}
+}
+
+// start registers the pending job id of req, which is cancelled by
+// cancel.
+func (r *jobRegistry) start(id string, req *pb.Request, cancel func()) {
+	r.mu.Lock()
+	if r.jobs == nil {
+		r.jobs = map[string]*job{}
+	}
+	now := time.Now()
+	for id, j := range r.jobs {
+		if !j.EndTime.IsZero() && now.Sub(j.EndTime) > jobTTL {
+			delete(r.jobs, id)
+		}
+	}
+	j := &job{
+		Job: jobstore.Job{
+			ID:        id,
+			User:      req.Session.GetUserId(),
+			SQL:       req.Sql,
+			Session:   req.Session,
+			Phase:     JobPending,
+			StartTime: now,
+		},
+		cancel: cancel,
+	}
+	r.jobs[id] = j
+	r.mu.Unlock()
+	r.persist(j.Job)
+}
+
+func (r *jobRegistry) setPhase(id, phase string) {
+	r.mu.Lock()
+	j, ok := r.jobs[id]
+	if !ok || !j.EndTime.IsZero() {
+		r.mu.Unlock()
+		return
+	}
+	j.Phase = phase
+	saved := j.Job
+	r.mu.Unlock()
+	r.persist(saved)
+}
+
+// newWorkflowJob returns the job of the workflow submitted by j, which
+// has the user, the SQL program and the session of j.
+func newWorkflowJob(j *jobstore.Job) *job {
+	end := j.EndTime
+	if end.IsZero() {
+		end = time.Now()
+	}
+	return &job{Job: jobstore.Job{ID: j.WorkflowID, User: j.User, SQL: j.SQL, Session: j.Session, EndTime: end}, workflow: true}
+}
+
+// addWorkflow records the workflow submitted by the job id, so CancelJob
+// checks the user of the workflow, and GetStepArtifacts finds the SQL
+// program of the workflow.
+func (r *jobRegistry) addWorkflow(id, workflowID string) {
+	r.mu.Lock()
+	j, ok := r.jobs[id]
+	if !ok {
+		r.mu.Unlock()
+		return
+	}
+	j.WorkflowID = workflowID
+	r.jobs[workflowID] = newWorkflowJob(&j.Job)
+	saved := j.Job
+	r.mu.Unlock()
+	r.persist(saved)
+}
+
+// finish records the result e of the job id.
+func (r *jobRegistry) finish(id string, e error) {
+	r.mu.Lock()
+	j, ok := r.jobs[id]
+	if !ok {
+		r.mu.Unlock()
+		return
+	}
+	j.EndTime, j.cancel = time.Now(), nil
+	switch {
+	case j.cancelled:
+		j.Phase = JobCancelled
+	case e != nil:
+		j.Phase, j.Message = JobFailed, e.Error()
+	default:
+		j.Phase = JobSucceeded
+	}
+	saved := j.Job
+	r.mu.Unlock()
+	r.persist(saved)
+}
+
+// get returns the user of the job id, and its status if it is not a
+// workflow, or false if the job is unknown.
+func (r *jobRegistry) get(id string) (string, *pb.JobStatus, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	j, ok := r.jobs[id]
+	if !ok {
+		return "", nil, false
+	}
+	if j.workflow {
+		return j.User, nil, true
+	}
+	return j.User, &pb.JobStatus{Job: &pb.Job{Id: id}, Phase: j.Phase, Message: j.Message}, true
+}
+
+// getWorkflow returns the user, the SQL program and the session of the
+// workflow id, or false if it's not a workflow submitted by the server.
+func (r *jobRegistry) getWorkflow(id string) (string, string, *pb.Session, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	j, ok := r.jobs[id]
+	if !ok || !j.workflow {
+		return "", "", nil, false
+	}
+	return j.User, j.SQL, j.Session, true
+}
+
+// cancel cancels the job id if it is running, and returns its status,
+// or nil if it is not a job of the server.
+func (r *jobRegistry) cancel(id string) *pb.JobStatus {
+	r.mu.Lock()
+	j, ok := r.jobs[id]
+	if !ok || j.workflow {
+		r.mu.Unlock()
+		return nil
+	}
+	cancel := j.cancel
+	if cancel != nil {
+		j.cancelled = true
+	}
+	r.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	_, st, _ := r.get(id)
+	return st
+}
@@ -0,0 +1,103 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"sqlflow.org/sqlflow/pkg/model"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+	"sqlflow.org/sqlflow/pkg/secret"
+	sf "sqlflow.org/sqlflow/pkg/sql"
+	"sqlflow.org/sqlflow/pkg/workdir"
+)
+
+// DiffModels implements `rpc DiffModels (DiffModelsRequest) returns (DiffModelsResponse)`
+func (s *Server) DiffModels(ctx context.Context, req *pb.DiffModelsRequest) (*pb.DiffModelsResponse, error) {
+	if req.ModelA == "" || req.ModelB == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "two models are required")
+	}
+	// The callers need to be allowed to SHOW TRAIN both models.
+	r := &pb.Request{Sql: fmt.Sprintf("SHOW TRAIN %s; SHOW TRAIN %s;", req.ModelA, req.ModelB), Session: req.Session}
+	if e := s.authorize(ctx, r); e != nil {
+		return nil, e
+	}
+	session, e := secret.ResolveSession(r.Session)
+	if e != nil {
+		return nil, e
+	}
+	diffs, e := model.DiffURIs(sf.ModelURI(s.modelDir, req.ModelA), sf.ModelURI(s.modelDir, req.ModelB), session)
+	if e != nil {
+		return nil, e
+	}
+	res := &pb.DiffModelsResponse{}
+	for _, d := range diffs {
+		res.Differences = append(res.Differences, &pb.DiffModelsResponse_Difference{Field: d.Field, A: d.A, B: d.B})
+	}
+	return res, nil
+}
+
+// tensorBoardChunkSize is the size of the chunks of the event files sent
+// by GetTensorBoardLogs.
+const tensorBoardChunkSize = 1 << 20
+
+// GetTensorBoardLogs implements `rpc GetTensorBoardLogs (TensorBoardLogsRequest) returns (stream TensorBoardLogs)`
+func (s *Server) GetTensorBoardLogs(req *pb.TensorBoardLogsRequest, stream pb.SQLFlow_GetTensorBoardLogsServer) error {
+	if req.Model == "" {
+		return status.Errorf(codes.InvalidArgument, "the model is required")
+	}
+	// The callers need to be allowed to SHOW TRAIN the model.
+	r := &pb.Request{Sql: fmt.Sprintf("SHOW TRAIN %s;", req.Model), Session: req.Session}
+	if e := s.authorize(stream.Context(), r); e != nil {
+		return e
+	}
+	session, e := secret.ResolveSession(r.Session)
+	if e != nil {
+		return e
+	}
+	dir, e := workdir.Default().Create(session.GetUserId())
+	if e != nil {
+		return e
+	}
+	defer dir.Remove()
+	if _, e := model.LoadWithSession(stream.Context(), sf.ModelURI(s.modelDir, req.Model), dir.Path, session, nil); e != nil {
+		return e
+	}
+	w := bufio.NewWriterSize(&tensorBoardLogsWriter{stream}, tensorBoardChunkSize)
+	n, e := model.WriteTensorBoardLogs(stream.Context(), dir.Path, w)
+	if e != nil {
+		return e
+	}
+	if n == 0 {
+		return status.Errorf(codes.NotFound, "model %s has no TensorBoard logs", req.Model)
+	}
+	return w.Flush()
+}
+
+// tensorBoardLogsWriter sends the bytes written to it as TensorBoardLogs.
+type tensorBoardLogsWriter struct {
+	stream pb.SQLFlow_GetTensorBoardLogsServer
+}
+
+func (w *tensorBoardLogsWriter) Write(p []byte) (int, error) {
+	if e := w.stream.Send(&pb.TensorBoardLogs{Content: append([]byte(nil), p...)}); e != nil {
+		return 0, e
+	}
+	return len(p), nil
+}
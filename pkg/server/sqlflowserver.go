@@ -20,14 +20,29 @@ package server
 import (
 	"context"
 	"fmt"
+	"net"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"sqlflow.org/sqlflow/pkg/audit"
+	"sqlflow.org/sqlflow/pkg/auth"
 	"sqlflow.org/sqlflow/pkg/database"
+	"sqlflow.org/sqlflow/pkg/jobqueue"
+	"sqlflow.org/sqlflow/pkg/jobstore"
 	"sqlflow.org/sqlflow/pkg/log"
+	"sqlflow.org/sqlflow/pkg/metrics"
 	"sqlflow.org/sqlflow/pkg/workflow"
 
 	"sqlflow.org/sqlflow/pkg/parser"
+	"sqlflow.org/sqlflow/pkg/parser/external"
 	"sqlflow.org/sqlflow/pkg/pipe"
 	pb "sqlflow.org/sqlflow/pkg/proto"
 	sf "sqlflow.org/sqlflow/pkg/sql"
@@ -40,6 +55,16 @@ type Server struct {
 	// To do this we need to refactor current pkg structure, so that we will not have circular dependency.
 	run      func(sql string, modelDir string, session *pb.Session) *pipe.Reader
 	modelDir string
+	// acl authorizes the authenticated callers to access databases, nil
+	// if all callers are allowed.
+	acl auth.ACL
+	// audit records the SQL programs, nil if not audited.
+	audit audit.Logger
+	// queue queues the SQL programs with TRAIN statements, nil if they
+	// run immediately.
+	queue   *jobqueue.Queue
+	jobs    jobRegistry
+	cursors cursorRegistry
 }
 
 // NewServer returns a server instance
@@ -48,8 +73,39 @@ func NewServer(run func(string, string, *pb.Session) *pipe.Reader,
 	return &Server{run: run, modelDir: modelDir}
 }
 
+// SetACL makes the server run the statements of only the callers
+// authenticated by auth.UnaryServerInterceptor and
+// auth.StreamServerInterceptor and allowed by acl to access the
+// databases of the statements.
+func (s *Server) SetACL(acl auth.ACL) {
+	s.acl = acl
+}
+
+// SetAuditLogger makes the server record every SQL program to l.
+func (s *Server) SetAuditLogger(l audit.Logger) {
+	s.audit = l
+}
+
+// SetJobQueue makes the server run the SQL programs with TRAIN
+// statements after they start in q.
+func (s *Server) SetJobQueue(q *jobqueue.Queue) {
+	s.queue = q
+}
+
+// SetJobStore recovers the jobs in store after the restart of the
+// server, and makes the server save the jobs to store.
+func (s *Server) SetJobStore(store jobstore.Store) error {
+	return s.jobs.recover(store)
+}
+
 // Fetch implements `rpc Fetch (Job) returns(JobStatus)`
 func (s *Server) Fetch(ctx context.Context, job *pb.FetchRequest) (*pb.FetchResponse, error) {
+	if c := s.cursors.get(job.Job.GetId()); c != nil {
+		return s.fetchCursor(ctx, c, job)
+	}
+	if e := s.checkWorkflowUser(ctx, job.Job.GetId()); e != nil {
+		return nil, e
+	}
 	// FIXME(tony): to make function fetch easily to mock, we should decouple server package
 	// with argo package by introducing s.fetch
 	_, wf, e := workflow.New(getWorkflowBackend())
@@ -59,12 +115,123 @@ func (s *Server) Fetch(ctx context.Context, job *pb.FetchRequest) (*pb.FetchResp
 	return wf.Fetch(job)
 }
 
+// fetchCursor returns the responses of a resumable job in c after the
+// resume token of req.
+func (s *Server) fetchCursor(ctx context.Context, c *cursor, req *pb.FetchRequest) (*pb.FetchResponse, error) {
+	if id := auth.FromContext(ctx); id != nil {
+		if user, _, _ := s.jobs.get(req.Job.Id); id.User != user {
+			return nil, status.Errorf(codes.PermissionDenied, "user %s is not allowed to fetch job %s", id.User, req.Job.Id)
+		}
+	}
+	offset := int64(0)
+	if req.ResumeToken != "" {
+		var e error
+		if offset, e = strconv.ParseInt(req.ResumeToken, 10, 64); e != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid resume token %s", req.ResumeToken)
+		}
+	}
+	n := int(req.PageSize)
+	if n <= 0 || n > cursorBufferSize {
+		n = defaultFetchPageSize
+	}
+	page, eof, e := c.read(offset, offset, n)
+	if e != nil {
+		return nil, e
+	}
+	return &pb.FetchResponse{
+		UpdatedFetchSince: &pb.FetchRequest{
+			Job:         req.Job,
+			ResumeToken: strconv.FormatInt(offset+int64(len(page)), 10),
+			PageSize:    req.PageSize,
+		},
+		Eof:       eof,
+		Responses: &pb.FetchResponse_Responses{Response: page},
+	}, nil
+}
+
 // Run implements `rpc Run (Request) returns (stream Response)`
 func (s *Server) Run(req *pb.Request, stream pb.SQLFlow_RunServer) error {
+	metrics.ActiveSessions.Inc()
+	defer metrics.ActiveSessions.Dec()
+	var rec *audit.Record
+	if s.audit != nil {
+		rec = newAuditRecord(stream.Context(), req)
+	}
+	if e := s.authorize(stream.Context(), req); e != nil {
+		s.finishJob("", rec, req, e)
+		return e
+	}
+	id := log.UUID()
+	if e := stream.SendHeader(metadata.Pairs(pb.JobIDHeader, id)); e != nil {
+		s.finishJob("", rec, req, e)
+		return e
+	}
+	if !req.Session.GetResumable() {
+		ctx, cancel := context.WithCancel(stream.Context())
+		defer cancel()
+		s.jobs.start(id, req, cancel)
+		e := s.runJob(ctx, id, req, rec, stream.Send)
+		s.finishJob(id, rec, req, e)
+		return e
+	}
+	// A resumable job keeps running after the client disconnects, and
+	// buffers the responses for the client to resume by Fetch.
+	ctx, cancel := context.WithCancel(context.Background())
+	s.jobs.start(id, req, cancel)
+	cur := s.cursors.open(id, cancel)
+	go func() {
+		defer cancel()
+		e := s.runJob(ctx, id, req, rec, cur.append)
+		s.finishJob(id, rec, req, e)
+		cur.close(e)
+	}()
+	return cur.stream(stream)
+}
+
+// finishJob records the result e of running req as the job id, which is
+// "" if req fails before it starts.
+func (s *Server) finishJob(id string, rec *audit.Record, req *pb.Request, e error) {
+	if id != "" {
+		s.jobs.finish(id, e)
+	}
+	if rec != nil {
+		s.logAudit(rec, req, e)
+	}
+}
+
+// runJob runs req as the job id until ctx is done, and sends the
+// responses by send.
+func (s *Server) runJob(ctx context.Context, id string, req *pb.Request, rec *audit.Record, send func(*pb.Response) error) error {
+	// A dry run only generates the programs, so it doesn't wait in the
+	// job queue.
+	if s.queue != nil && !req.Session.GetDryRun() {
+		queued, e := s.enqueue(ctx, send, id, req)
+		// Removing the job from the queue releases its slot even if ctx
+		// is done right after the job starts.
+		if queued {
+			defer s.queue.Done(id)
+		}
+		if ctx.Err() != nil {
+			return status.Errorf(codes.Canceled, "job %s is cancelled", id)
+		}
+		if e != nil {
+			return e
+		}
+	}
+	s.jobs.setPhase(id, JobRunning)
 	rd := s.run(req.Sql, s.modelDir, req.Session)
 	defer rd.Close()
+	// Closing the reader stops the running statement.
+	go func() {
+		<-ctx.Done()
+		rd.Close()
+	}()
 
+	workflowID := ""
 	for r := range rd.ReadAll() {
+		if ctx.Err() != nil {
+			break
+		}
 		var res *pb.Response
 		var err error
 		switch s := r.(type) {
@@ -73,12 +240,30 @@ func (s *Server) Run(req *pb.Request, stream pb.SQLFlow_RunServer) error {
 		case map[string]interface{}:
 			res, err = pb.EncodeHead(s)
 		case []interface{}:
+			if rec != nil {
+				rec.RowsReturned++
+			}
 			res, err = pb.EncodeRow(s)
 		case sf.Figures:
 			res, err = pb.EncodeMessage(s.Image)
+		case sf.Progress:
+			res = &pb.Response{Response: &pb.Response_Progress{Progress: &pb.Progress{
+				Epoch:   int32(s.Epoch),
+				Epochs:  int32(s.Epochs),
+				Step:    s.Step,
+				Steps:   s.Steps,
+				Metrics: s.Metrics,
+			}}}
 		case string:
+			if rec != nil {
+				rec.RowsAffected += rowsAffected(s)
+			}
 			res, err = pb.EncodeMessage(s)
 		case pb.Job:
+			if rec != nil {
+				rec.WorkflowID = s.Id
+			}
+			workflowID = s.Id
 			res = &pb.Response{Response: &pb.Response_Job{Job: &s}}
 		case sf.EndOfExecution:
 			// FIXME(tony): decouple server package with sql package by introducing s.numberOfStatement
@@ -98,7 +283,7 @@ func (s *Server) Run(req *pb.Request, stream pb.SQLFlow_RunServer) error {
 					SpentTimeSeconds: eoeMsg.EndTime - eoeMsg.StartTime,
 				}
 				eoeResponse := &pb.Response{Response: &pb.Response_Eoe{Eoe: eoe}}
-				if err := stream.Send(eoeResponse); err != nil {
+				if err := send(eoeResponse); err != nil {
 					return err
 				}
 			} else {
@@ -110,13 +295,332 @@ func (s *Server) Run(req *pb.Request, stream pb.SQLFlow_RunServer) error {
 		if err != nil {
 			return err
 		}
-		if err := stream.Send(res); err != nil {
+		if err := send(res); err != nil {
 			return err
 		}
 	}
+	if ctx.Err() != nil {
+		return status.Errorf(codes.Canceled, "job %s is cancelled", id)
+	}
+	if workflowID != "" {
+		s.jobs.addWorkflow(id, workflowID)
+	}
+	return nil
+}
+
+// enqueue submits req as the job id to the job queue if it has TRAIN
+// statements, and waits for the job to start or ctx to be done. It
+// returns if req is submitted, even if it fails waiting.
+func (s *Server) enqueue(ctx context.Context, send func(*pb.Response) error, id string, req *pb.Request) (bool, error) {
+	dialect, _, e := database.ParseURL(req.Session.GetDbConnStr())
+	if e != nil {
+		return false, e
+	}
+	stmts, e := parser.Parse(dialect, req.Sql)
+	if e != nil {
+		return false, e
+	}
+	train := false
+	for _, stmt := range stmts {
+		train = train || stmt.Type() == "train"
+	}
+	if !train {
+		return false, nil
+	}
+	job := s.queue.Submit(id, queueUser(ctx, req), req.Sql)
+	if job.Status == jobqueue.StatusQueued {
+		res, e := pb.EncodeMessage(fmt.Sprintf("job %s is queued at position %d", id, job.Position))
+		if e == nil {
+			e = send(res)
+		}
+		if e != nil {
+			return true, e
+		}
+	}
+	return true, s.queue.Wait(ctx, id)
+}
+
+// queueUser returns the user whose quota and priority the job of req
+// runs by, which is the authenticated caller. Without authentication,
+// it falls back to the user_id in the session supplied by the client.
+func queueUser(ctx context.Context, req *pb.Request) string {
+	if id := auth.FromContext(ctx); id != nil {
+		return id.User
+	}
+	return req.Session.GetUserId()
+}
+
+// FetchJobStatus implements `rpc FetchJobStatus (Job) returns (JobStatus)`
+func (s *Server) FetchJobStatus(ctx context.Context, job *pb.Job) (*pb.JobStatus, error) {
+	user, st, ok := s.jobs.get(job.Id)
+	if id := auth.FromContext(ctx); id != nil {
+		// The authenticated callers fetch the status of only their own jobs.
+		if !ok {
+			return nil, status.Errorf(codes.NotFound, "no job %s", job.Id)
+		}
+		if id.User != user {
+			return nil, status.Errorf(codes.PermissionDenied, "user %s is not allowed to fetch the status of job %s", id.User, job.Id)
+		}
+	}
+	if st != nil {
+		return st, nil
+	}
+	_, wf, e := workflow.New(getWorkflowBackend())
+	if e != nil {
+		return nil, e
+	}
+	phase, msg, e := wf.Status(job.Id)
+	if e != nil {
+		return nil, e
+	}
+	return &pb.JobStatus{Job: job, Phase: phase, Message: msg}, nil
+}
+
+// CancelJob implements `rpc CancelJob (Job) returns (JobStatus)`
+func (s *Server) CancelJob(ctx context.Context, job *pb.Job) (*pb.JobStatus, error) {
+	user, st, ok := s.jobs.get(job.Id)
+	if id := auth.FromContext(ctx); id != nil {
+		// The authenticated callers cancel only their own jobs.
+		if !ok {
+			return nil, status.Errorf(codes.NotFound, "no job %s", job.Id)
+		}
+		if id.User != user {
+			return nil, status.Errorf(codes.PermissionDenied, "user %s is not allowed to cancel job %s", id.User, job.Id)
+		}
+	}
+	if st != nil {
+		log.WithFields(log.Fields{"user": user, "job": job.Id, "event": "cancelJob"}).Info("cancelled")
+		return s.jobs.cancel(job.Id), nil
+	}
+	_, wf, e := workflow.New(getWorkflowBackend())
+	if e != nil {
+		return nil, e
+	}
+	if e := wf.Terminate(job.Id); e != nil {
+		return nil, e
+	}
+	log.WithFields(log.Fields{"user": user, "job": job.Id, "event": "cancelJob"}).Info("terminated the workflow")
+	return s.FetchJobStatus(ctx, job)
+}
+
+// ListJobs implements `rpc ListJobs (ListJobsRequest) returns (ListJobsResponse)`
+func (s *Server) ListJobs(ctx context.Context, req *pb.ListJobsRequest) (*pb.ListJobsResponse, error) {
+	res := &pb.ListJobsResponse{}
+	if s.queue == nil {
+		return res, nil
+	}
+	for _, j := range s.queue.Jobs() {
+		if req.User == "" || j.User == req.User {
+			res.Jobs = append(res.Jobs, queuedJob(ctx, j))
+		}
+	}
+	return res, nil
+}
+
+// GetJob implements `rpc GetJob (GetJobRequest) returns (QueuedJob)`
+func (s *Server) GetJob(ctx context.Context, req *pb.GetJobRequest) (*pb.QueuedJob, error) {
+	if s.queue != nil {
+		if j, ok := s.queue.Get(req.Id); ok {
+			return queuedJob(ctx, j), nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "no job %s in the queue", req.Id)
+}
+
+// queuedJob returns j to the caller of ctx, without the SQL program if
+// the caller is authenticated as another user.
+func queuedJob(ctx context.Context, j jobqueue.Job) *pb.QueuedJob {
+	res := &pb.QueuedJob{
+		Id:         j.ID,
+		User:       j.User,
+		Sql:        j.SQL,
+		Priority:   int32(j.Priority),
+		Status:     j.Status,
+		Position:   int32(j.Position),
+		SubmitTime: j.SubmitTime.Unix(),
+	}
+	if !j.StartTime.IsZero() {
+		res.StartTime = j.StartTime.Unix()
+	}
+	if id := auth.FromContext(ctx); id != nil && id.User != j.User {
+		res.Sql = ""
+	}
+	return res
+}
+
+// authorize records the authenticated caller in the session of req, and
+// checks if the caller is allowed to access the databases of req.
+func (s *Server) authorize(ctx context.Context, req *pb.Request) error {
+	id := auth.FromContext(ctx)
+	if id != nil {
+		if req.Session == nil {
+			req.Session = &pb.Session{}
+		}
+		req.Session.UserId = id.User
+	}
+	if s.acl == nil {
+		return nil
+	}
+	if id == nil {
+		return status.Error(codes.Unauthenticated, "authentication required")
+	}
+	logger := log.WithFields(log.Fields{
+		"user":   id.User,
+		"method": id.Method,
+		"event":  "authorize",
+	})
+	dialect, _, e := database.ParseURL(req.Session.DbConnStr)
+	if e != nil {
+		return e
+	}
+	defaultDB, e := database.GetDatabaseName(req.Session.DbConnStr)
+	if e != nil {
+		return e
+	}
+	stmts, e := parser.Parse(dialect, req.Sql)
+	if e != nil {
+		return e
+	}
+	if e := s.acl.Check(id.User, defaultDB, nil); e != nil {
+		logger.Warningf("denied: %v", e)
+		return status.Error(codes.PermissionDenied, e.Error())
+	}
+	// A USE statement switches the database of the unqualified tables in
+	// the following statements.
+	db := defaultDB
+	tables := []string{}
+	for _, stmt := range stmts {
+		t, use, e := statementTables(dialect, stmt)
+		if e != nil {
+			return status.Errorf(codes.InvalidArgument, "failed to find the tables of %s: %v", stmt.Original, e)
+		}
+		if use != "" {
+			db = use
+		}
+		if e := s.acl.Check(id.User, db, t); e != nil {
+			logger.Warningf("denied: %v", e)
+			return status.Error(codes.PermissionDenied, e.Error())
+		}
+		tables = append(tables, t...)
+	}
+	logger.Infof("allowed to access %s, tables: %v", defaultDB, tables)
 	return nil
 }
 
+// statementTables returns the tables that stmt reads or writes, and the
+// database of stmt if it is a USE statement. The parsers of Hive and
+// MaxCompute report the tables, and the tables of MySQL are in the AST of
+// the TiDB parser.
+func statementTables(dialect string, stmt *parser.SQLFlowStmt) ([]string, string, error) {
+	tables := append(append([]string{}, stmt.Inputs...), stmt.Outputs...)
+	use := auth.UsedDatabase(stmt.Original)
+	if dialect == "mysql" || dialect == "tidb" {
+		sql := stmt.Original
+		if stmt.IsExtendedSyntax() {
+			sql = stmt.StandardSelect.String()
+		}
+		if strings.TrimSpace(sql) != "" {
+			t, u, e := external.TiDBTables(sql)
+			if e != nil {
+				return nil, "", e
+			}
+			tables, use = append(tables, t...), u
+		}
+	}
+	if !stmt.IsExtendedSyntax() {
+		return tables, use, nil
+	}
+	// The result table of PREDICT is in the form of [db.]table.column.
+	predictTable := ""
+	if i := strings.LastIndex(stmt.Into, "."); i > 0 {
+		predictTable = stmt.Into[:i]
+	}
+	for _, t := range []string{stmt.Save, predictTable, stmt.Model, stmt.TrainedModel,
+		stmt.ExplainInto, stmt.ModelToEvaluate, stmt.EvaluateInto, stmt.ModelName, stmt.ModelToDrop, stmt.LineageModel} {
+		if t != "" {
+			tables = append(tables, t)
+		}
+	}
+	return tables, use, nil
+}
+
+func newAuditRecord(ctx context.Context, req *pb.Request) *audit.Record {
+	rec := &audit.Record{SQL: req.Sql, StartTime: time.Now()}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		rec.SourceIP = p.Addr.String()
+		if host, _, e := net.SplitHostPort(rec.SourceIP); e == nil {
+			rec.SourceIP = host
+		}
+	}
+	return rec
+}
+
+// logAudit completes rec by the result err of running req, and logs it.
+func (s *Server) logAudit(rec *audit.Record, req *pb.Request, err error) {
+	rec.EndTime = time.Now()
+	rec.Status = audit.StatusSucceeded
+	if err != nil {
+		rec.Status, rec.Error = audit.StatusFailed, err.Error()
+	}
+	if req.Session != nil {
+		rec.User = req.Session.UserId
+		rec.Database, _ = database.GetDatabaseName(req.Session.DbConnStr)
+		if dialect, _, e := database.ParseURL(req.Session.DbConnStr); e == nil {
+			// The SQL program may not parse, which is recorded as the error.
+			if stmts, e := parser.Parse(dialect, req.Sql); e == nil {
+				rec.Statements, rec.ModelURIs = auditStatements(dialect, stmts, rec.Database)
+			}
+		}
+	}
+	if e := s.audit.Log(rec); e != nil {
+		log.GetDefaultLogger().Errorf("audit log of user %s failed: %v", rec.User, e)
+	}
+}
+
+// auditStatements returns the statements and the models of stmts in the
+// SQL dialect, with the models of unqualified names in defaultDB.
+func auditStatements(dialect string, stmts []*parser.SQLFlowStmt, defaultDB string) ([]audit.Statement, []string) {
+	statements, models := []audit.Statement{}, []string{}
+	for _, stmt := range stmts {
+		tables, _, _ := statementTables(dialect, stmt)
+		st := audit.Statement{Type: stmt.Type(), SQL: stmt.Original, Tables: tables}
+		model := ""
+		switch st.Type {
+		case "train":
+			model = stmt.Save
+		case "predict":
+			model = stmt.Model
+		case "explain":
+			model = stmt.TrainedModel
+		case "evaluate":
+			model = stmt.ModelToEvaluate
+		case "show_train":
+			model = stmt.ModelName
+		}
+		if model != "" {
+			if !strings.Contains(model, ".") && !strings.Contains(model, "://") && defaultDB != "" {
+				model = defaultDB + "." + model
+			}
+			models = append(models, model)
+		}
+		statements = append(statements, st)
+	}
+	return statements, models
+}
+
+var rowsAffectedRE = regexp.MustCompile(`^(\d+) rows? affected$`)
+
+// rowsAffected returns the number of rows in a message of a standard
+// statement like "3 rows affected" or "1 row affected", 0 for other
+// messages.
+func rowsAffected(msg string) int64 {
+	if m := rowsAffectedRE.FindStringSubmatch(msg); m != nil {
+		n, _ := strconv.ParseInt(m[1], 10, 64)
+		return n
+	}
+	return 0
+}
+
 // SubmitWorkflow submits an Argo workflow
 //
 // TODO(wangkuiyi): Make SubmitWorkflow return an error in addition to
@@ -135,7 +639,19 @@ func SubmitWorkflow(sqlProgram string, modelDir string, session *pb.Session) *pi
 	startTime := time.Now()
 	go func() {
 		defer wr.Close()
+		if session.DryRun {
+			yaml, e := workflow.Compile(getWorkflowBackend(), sqlProgram, session, logger)
+			var res interface{} = e
+			if e == nil {
+				res = fmt.Sprintf("Generated workflow of the SQL program:\n%s", yaml)
+			}
+			if e := wr.Write(res); e != nil {
+				logger.Errorf("piping: %v", e)
+			}
+			return
+		}
 		wfID, e := workflow.Run(getWorkflowBackend(), sqlProgram, session, logger)
+		metrics.WorkflowSubmissions.Inc(metrics.Status(e))
 		defer logger.Infof("submitted, workflowID:%s, spent:%.f, SQL:%s, error:%v", wfID, time.Since(startTime).Seconds(), sqlProgram, e)
 		if e != nil {
 			if e := wr.Write(e); e != nil {
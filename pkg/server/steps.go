@@ -0,0 +1,172 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"sqlflow.org/sqlflow/pkg/auth"
+	"sqlflow.org/sqlflow/pkg/database"
+	"sqlflow.org/sqlflow/pkg/parser"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+	"sqlflow.org/sqlflow/pkg/workflow"
+)
+
+// stepPollInterval is how often StreamStepLogs reads the new logs of a
+// step to follow.
+var stepPollInterval = 2 * time.Second
+
+// stepWorkflow returns the workflow backend of the steps, which the
+// tests replace.
+var stepWorkflow = func() (workflow.Workflow, error) {
+	_, wf, e := workflow.New(getWorkflowBackend())
+	return wf, e
+}
+
+// checkWorkflowUser returns an error if the authenticated caller of ctx
+// is not the user of the workflow id.
+func (s *Server) checkWorkflowUser(ctx context.Context, id string) error {
+	caller := auth.FromContext(ctx)
+	if caller == nil {
+		return nil
+	}
+	user, _, _, ok := s.jobs.getWorkflow(id)
+	if !ok {
+		return status.Errorf(codes.NotFound, "no workflow %s", id)
+	}
+	if caller.User != user {
+		return status.Errorf(codes.PermissionDenied, "user %s is not allowed to access workflow %s", caller.User, id)
+	}
+	return nil
+}
+
+func isStepFinished(phase string) bool {
+	return phase == JobSucceeded || phase == JobFailed
+}
+
+// StreamStepLogs implements `rpc StreamStepLogs (StepRequest) returns (stream StepLogs)`
+func (s *Server) StreamStepLogs(req *pb.StepRequest, stream pb.SQLFlow_StreamStepLogsServer) error {
+	id := req.Job.GetId()
+	if e := s.checkWorkflowUser(stream.Context(), id); e != nil {
+		return e
+	}
+	wf, e := stepWorkflow()
+	if e != nil {
+		return e
+	}
+	offset, sent := 0, false
+	lastPhase := ""
+	for {
+		lines, phase, e := wf.StepLogs(id, int(req.Step), offset)
+		if e != nil {
+			return e
+		}
+		offset += len(lines)
+		if len(lines) > 0 || phase != lastPhase || !sent {
+			if e := stream.Send(&pb.StepLogs{Lines: lines, Phase: phase}); e != nil {
+				return e
+			}
+			sent, lastPhase = true, phase
+		}
+		if !req.Follow || isStepFinished(phase) {
+			return nil
+		}
+		if phase == "" {
+			// The step never starts after the workflow finished, like
+			// failing in the previous steps.
+			wfPhase, _, e := wf.Status(id)
+			if e != nil {
+				return e
+			}
+			if isStepFinished(wfPhase) {
+				return nil
+			}
+		}
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-time.After(stepPollInterval):
+		}
+	}
+}
+
+// GetStepArtifacts implements `rpc GetStepArtifacts (StepRequest) returns (StepArtifacts)`
+func (s *Server) GetStepArtifacts(ctx context.Context, req *pb.StepRequest) (*pb.StepArtifacts, error) {
+	id := req.Job.GetId()
+	if e := s.checkWorkflowUser(ctx, id); e != nil {
+		return nil, e
+	}
+	_, program, session, ok := s.jobs.getWorkflow(id)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "no workflow %s submitted by the server", id)
+	}
+	dialect, _, e := database.ParseURL(session.GetDbConnStr())
+	if e != nil {
+		return nil, e
+	}
+	stmts, e := parser.Parse(dialect, program)
+	if e != nil {
+		return nil, e
+	}
+	// The workflows run a step for each statement.
+	if req.Step < 1 || int(req.Step) > len(stmts) {
+		return nil, status.Errorf(codes.NotFound, "no step %d in workflow %s of %d steps", req.Step, id, len(stmts))
+	}
+	wf, e := stepWorkflow()
+	if e != nil {
+		return nil, e
+	}
+	_, phase, e := wf.StepLogs(id, int(req.Step), -1)
+	if e != nil {
+		return nil, e
+	}
+	stmt := stmts[req.Step-1]
+	return &pb.StepArtifacts{Sql: stmt.Original, Phase: phase, Artifacts: stepArtifacts(stmt)}, nil
+}
+
+// stepArtifacts returns the models and the tables that stmt writes, as
+// far as the parser of the SQL dialect reports.
+func stepArtifacts(stmt *parser.SQLFlowStmt) []*pb.StepArtifacts_Artifact {
+	artifacts := []*pb.StepArtifacts_Artifact{}
+	add := func(typ, name string) {
+		if name != "" {
+			artifacts = append(artifacts, &pb.StepArtifacts_Artifact{Type: typ, Name: name})
+		}
+	}
+	if !stmt.IsExtendedSyntax() {
+		for _, t := range stmt.Outputs {
+			add("table", t)
+		}
+		return artifacts
+	}
+	switch {
+	case stmt.Train:
+		add("model", stmt.Save)
+	case stmt.Predict:
+		// The result table of PREDICT is in the form of [db.]table.column.
+		if i := strings.LastIndex(stmt.Into, "."); i > 0 {
+			add("table", stmt.Into[:i])
+		}
+	case stmt.Explain:
+		add("table", stmt.ExplainInto)
+	case stmt.Evaluate:
+		add("table", stmt.EvaluateInto)
+	}
+	return artifacts
+}
@@ -0,0 +1,249 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package serving serves the predictions of the models saved by pkg/model,
+// so online services can use the models trained in SQLFlow without
+// re-implementing the feature preprocessing.
+package serving
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"sync"
+
+	"sqlflow.org/sqlflow/pkg/database"
+	"sqlflow.org/sqlflow/pkg/model"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+)
+
+// supportedFrameworks are the frameworks of the models that the
+// predictor, python/sqlflow_submitter/serving.py, loads.
+var supportedFrameworks = map[string]bool{
+	"xgboost":  true,
+	"lightgbm": true,
+	"catboost": true,
+	"sklearn":  true,
+	"pytorch":  true,
+}
+
+// predictorCmd returns the command running the predictor in cwd.
+var predictorCmd = func(cwd, framework, attributes string) *exec.Cmd {
+	cmd := exec.Command("python", "-u", "-m", "sqlflow_submitter.serving", framework, attributes)
+	cmd.Dir = cwd
+	cmd.Stderr = os.Stderr
+	return cmd
+}
+
+// predictorRequest is a line of the stdin of the predictor, which has
+// the flattened features of the rows in the order of Schema.Features.
+type predictorRequest struct {
+	X [][]float64 `json:"x"`
+}
+
+// predictorResponse is a line of the stdout of the predictor.
+type predictorResponse struct {
+	Predictions []float64 `json:"predictions"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// Server predicts by a model in its working directory. It implements
+// pb.ModelServingServer, and http.Handler for the JSON requests like
+// {"rows": [{"sepal_length": 5.1, "petal_width": [0.2]}]}.
+type Server struct {
+	model *model.Model
+	cmd   *exec.Cmd
+	// The predictor handles one request at a time.
+	mu     sync.Mutex
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+}
+
+// NewServer loads the model at modelURI, which is on the model storage
+// or in a table of db, into cwd, and starts the predictor.
+func NewServer(modelURI, cwd string, db *database.DB) (*Server, error) {
+	m, e := model.Load(modelURI, cwd, db)
+	if e != nil {
+		return nil, e
+	}
+	return newServer(m, cwd)
+}
+
+func newServer(m *model.Model, cwd string) (*Server, error) {
+	if m.Metadata == nil || m.Schema == nil {
+		return nil, fmt.Errorf("the model has no metadata or schema, please save it by a newer version of SQLFlow")
+	}
+	if !supportedFrameworks[m.Metadata.Framework] {
+		return nil, fmt.Errorf("serving %s models is not supported", m.Metadata.Framework)
+	}
+	for _, f := range m.Schema.Features {
+		if f.IsSparse || f.DType == "string" {
+			return nil, fmt.Errorf("feature %s: serving sparse or string features is not supported", f.Name)
+		}
+	}
+	attrs, e := json.Marshal(m.Metadata.Attributes)
+	if e != nil {
+		return nil, e
+	}
+	s := &Server{model: m, cmd: predictorCmd(cwd, m.Metadata.Framework, string(attrs))}
+	if s.stdin, e = s.cmd.StdinPipe(); e != nil {
+		return nil, e
+	}
+	stdout, e := s.cmd.StdoutPipe()
+	if e != nil {
+		return nil, e
+	}
+	s.stdout = bufio.NewReader(stdout)
+	if e := s.cmd.Start(); e != nil {
+		return nil, fmt.Errorf("start the predictor failed: %v", e)
+	}
+	return s, nil
+}
+
+// Close stops the predictor.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stdin.Close()
+	return s.cmd.Wait()
+}
+
+// flatten returns the features of rows flattened in the order of
+// Schema.Features.
+func (s *Server) flatten(rows []map[string][]float64) ([][]float64, error) {
+	x := make([][]float64, 0, len(rows))
+	for i, row := range rows {
+		r := []float64{}
+		for _, f := range s.model.Schema.Features {
+			values, ok := row[f.Name]
+			if !ok {
+				return nil, fmt.Errorf("row %d: missing feature %s", i, f.Name)
+			}
+			size := 1
+			for _, d := range f.Shape {
+				size *= d
+			}
+			if len(values) != size {
+				return nil, fmt.Errorf("row %d: feature %s should have %d values, got %d", i, f.Name, size, len(values))
+			}
+			r = append(r, values...)
+		}
+		x = append(x, r)
+	}
+	return x, nil
+}
+
+func (s *Server) predict(rows []map[string][]float64) ([]float64, error) {
+	x, e := s.flatten(rows)
+	if e != nil {
+		return nil, e
+	}
+	b, e := json.Marshal(&predictorRequest{X: x})
+	if e != nil {
+		return nil, e
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, e := fmt.Fprintf(s.stdin, "%s\n", b); e != nil {
+		return nil, fmt.Errorf("write to the predictor failed: %v", e)
+	}
+	line, e := s.stdout.ReadBytes('\n')
+	if e != nil {
+		return nil, fmt.Errorf("read from the predictor failed: %v", e)
+	}
+	r := &predictorResponse{}
+	if e := json.Unmarshal(line, r); e != nil {
+		return nil, fmt.Errorf("invalid response of the predictor %q: %v", line, e)
+	}
+	if r.Error != "" {
+		return nil, fmt.Errorf("predict failed: %s", r.Error)
+	}
+	if len(r.Predictions) != len(rows) {
+		return nil, fmt.Errorf("the predictor returns %d predictions of %d rows", len(r.Predictions), len(rows))
+	}
+	return r.Predictions, nil
+}
+
+// Predict implements pb.ModelServingServer.
+func (s *Server) Predict(ctx context.Context, req *pb.PredictRequest) (*pb.PredictResponse, error) {
+	rows := make([]map[string][]float64, len(req.Rows))
+	for i, r := range req.Rows {
+		rows[i] = make(map[string][]float64)
+		for name, f := range r.Features {
+			rows[i][name] = f.Values
+		}
+	}
+	preds, e := s.predict(rows)
+	if e != nil {
+		return nil, e
+	}
+	return &pb.PredictResponse{Predictions: preds}, nil
+}
+
+// restFeature is a scalar or a list of numbers in a JSON request.
+type restFeature []float64
+
+func (f *restFeature) UnmarshalJSON(b []byte) error {
+	var v float64
+	if e := json.Unmarshal(b, &v); e == nil {
+		*f = restFeature{v}
+		return nil
+	}
+	return json.Unmarshal(b, (*[]float64)(f))
+}
+
+type restRequest struct {
+	Rows []map[string]restFeature `json:"rows"`
+}
+
+type restResponse struct {
+	Predictions []float64 `json:"predictions,omitempty"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// ServeHTTP predicts by a POST request with a JSON body like
+// {"rows": [{"sepal_length": 5.1}]}, and responds like
+// {"predictions": [0]}.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(&restResponse{Error: "only POST is allowed"})
+		return
+	}
+	req := &restRequest{}
+	if e := json.NewDecoder(r.Body).Decode(req); e != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&restResponse{Error: fmt.Sprintf("invalid request: %v", e)})
+		return
+	}
+	rows := make([]map[string][]float64, len(req.Rows))
+	for i, row := range req.Rows {
+		rows[i] = make(map[string][]float64)
+		for name, f := range row {
+			rows[i][name] = f
+		}
+	}
+	preds, e := s.predict(rows)
+	if e != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&restResponse{Error: e.Error()})
+		return
+	}
+	json.NewEncoder(w).Encode(&restResponse{Predictions: preds})
+}
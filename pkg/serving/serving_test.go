@@ -0,0 +1,131 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serving
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sqlflow.org/sqlflow/pkg/model"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+)
+
+// TestFakePredictor is not a test but the fake predictor run by the
+// tests, which predicts the sum of the features of each row.
+func TestFakePredictor(t *testing.T) {
+	if os.Getenv("SQLFLOW_FAKE_PREDICTOR") != "1" {
+		return
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		req := &predictorRequest{}
+		json.Unmarshal(scanner.Bytes(), req)
+		resp := &predictorResponse{Predictions: []float64{}}
+		for _, x := range req.X {
+			sum := 0.0
+			for _, v := range x {
+				sum += v
+			}
+			if sum < 0 {
+				resp = &predictorResponse{Error: "negative features"}
+				break
+			}
+			resp.Predictions = append(resp.Predictions, sum)
+		}
+		b, _ := json.Marshal(resp)
+		fmt.Println(string(b))
+	}
+	os.Exit(0)
+}
+
+func newFakeServer(t *testing.T, framework string) (*Server, error) {
+	predictorCmd = func(cwd, framework, attributes string) *exec.Cmd {
+		cmd := exec.Command(os.Args[0], "-test.run=TestFakePredictor")
+		cmd.Env = append(os.Environ(), "SQLFLOW_FAKE_PREDICTOR=1")
+		return cmd
+	}
+	m := &model.Model{
+		Metadata: &model.Metadata{Framework: framework},
+		Schema: &model.Schema{Features: []model.Field{
+			{Name: "a", DType: "float"},
+			{Name: "b", DType: "float", Shape: []int{2}},
+		}},
+	}
+	return newServer(m, "")
+}
+
+func TestPredict(t *testing.T) {
+	a := assert.New(t)
+	s, e := newFakeServer(t, "xgboost")
+	a.NoError(e)
+	defer s.Close()
+
+	resp, e := s.Predict(context.Background(), &pb.PredictRequest{Rows: []*pb.FeatureRow{
+		{Features: map[string]*pb.Feature{"a": {Values: []float64{1}}, "b": {Values: []float64{2, 3}}}},
+		{Features: map[string]*pb.Feature{"a": {Values: []float64{0}}, "b": {Values: []float64{1, 1}}}},
+	}})
+	a.NoError(e)
+	a.Equal([]float64{6, 2}, resp.Predictions)
+
+	_, e = s.Predict(context.Background(), &pb.PredictRequest{Rows: []*pb.FeatureRow{
+		{Features: map[string]*pb.Feature{"a": {Values: []float64{1}}}},
+	}})
+	a.EqualError(e, "row 0: missing feature b")
+	_, e = s.Predict(context.Background(), &pb.PredictRequest{Rows: []*pb.FeatureRow{
+		{Features: map[string]*pb.Feature{"a": {Values: []float64{1}}, "b": {Values: []float64{2}}}},
+	}})
+	a.EqualError(e, "row 0: feature b should have 2 values, got 1")
+	_, e = s.Predict(context.Background(), &pb.PredictRequest{Rows: []*pb.FeatureRow{
+		{Features: map[string]*pb.Feature{"a": {Values: []float64{-9}}, "b": {Values: []float64{2, 3}}}},
+	}})
+	a.EqualError(e, "predict failed: negative features")
+}
+
+func TestServeHTTP(t *testing.T) {
+	a := assert.New(t)
+	s, e := newFakeServer(t, "sklearn")
+	a.NoError(e)
+	defer s.Close()
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/predict", strings.NewReader(`{"rows": [{"a": 1, "b": [2, 3]}]}`)))
+	a.Equal(http.StatusOK, w.Code)
+	a.JSONEq(`{"predictions": [6]}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/predict", strings.NewReader(`{"rows": [{"a": 1}]}`)))
+	a.Equal(http.StatusBadRequest, w.Code)
+	a.JSONEq(`{"error": "row 0: missing feature b"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/predict", nil))
+	a.Equal(http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestNewServerUnsupported(t *testing.T) {
+	a := assert.New(t)
+	_, e := newFakeServer(t, "tensorflow")
+	a.EqualError(e, "serving tensorflow models is not supported")
+	_, e = newServer(&model.Model{}, "")
+	a.Error(e)
+}
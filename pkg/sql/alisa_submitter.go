@@ -29,6 +29,7 @@ import (
 	"sqlflow.org/sqlflow/pkg/ir"
 	pb "sqlflow.org/sqlflow/pkg/proto"
 	"sqlflow.org/sqlflow/pkg/sql/codegen/pai"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/prediction"
 )
 
 var resourceName = "job.tar.gz"
@@ -63,6 +64,9 @@ func (s *alisaSubmitter) submitAlisaTask(submitCode, codeResourceURL, paramsReso
 }
 
 func (s *alisaSubmitter) ExecuteTrain(ts *ir.TrainStmt) (e error) {
+	if e = checkData(s.Writer, s.Db, ts); e != nil {
+		return e
+	}
 	ts.TmpTrainTable, ts.TmpValidateTable, e = createTempTrainAndValTable(ts.Select, ts.ValidationSelect, s.Session.DbConnStr)
 	if e != nil {
 		return e
@@ -104,6 +108,12 @@ func (s *alisaSubmitter) ExecuteTrain(ts *ir.TrainStmt) (e error) {
 }
 
 func (s *alisaSubmitter) ExecutePredict(ps *ir.PredictStmt) error {
+	if prediction.Partition(ps.Attributes) != "" {
+		return fmt.Errorf("predict.partition is not supported on PAI")
+	}
+	if ps.ResultTable == "" {
+		return fmt.Errorf("predicting to the client is not supported on PAI, please specify the result table")
+	}
 	dbName, tableName, err := createTmpTableFromSelect(ps.Select, s.Session.DbConnStr)
 	if err != nil {
 		return err
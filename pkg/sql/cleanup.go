@@ -0,0 +1,157 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sqlflow.org/sqlflow/pkg/database"
+	"sqlflow.org/sqlflow/pkg/ir"
+	"sqlflow.org/sqlflow/pkg/pipe"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/evaluation"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/prediction"
+)
+
+// The values of Session.CleanupOnFailure, which artifacts runSQLProgram
+// drops if a statement of the program fails.
+const (
+	cleanupNone   = "none"
+	cleanupTables = "tables"
+	cleanupModels = "models"
+	cleanupAll    = "all"
+)
+
+var (
+	reCreateTable = regexp.MustCompile(`(?is)^\s*create\s+(?:temporary\s+)?table\s+(if\s+not\s+exists\s+)?([^\s(;]+)`)
+	reDropTable   = regexp.MustCompile(`(?is)^\s*drop\s+table\s+(?:if\s+exists\s+)?([^\s;]+)`)
+)
+
+// artifact is a table or a model that a statement of a SQL program
+// creates.
+type artifact struct {
+	typ  string // "table" or "model"
+	name string
+}
+
+func (a artifact) String() string { return a.typ + " " + a.name }
+
+// artifactTracker records the artifacts created by the succeeded
+// statements of a SQL program, so to drop them if a later statement
+// fails.
+type artifactTracker struct {
+	policy    string
+	artifacts []artifact
+}
+
+func newArtifactTracker(policy string) (*artifactTracker, error) {
+	policy = strings.ToLower(strings.TrimSpace(policy))
+	switch policy {
+	case "":
+		policy = cleanupNone
+	case cleanupNone, cleanupTables, cleanupModels, cleanupAll:
+	default:
+		return nil, fmt.Errorf("unrecognized cleanup_on_failure %q, should be one of none, tables, models and all", policy)
+	}
+	return &artifactTracker{policy: policy}, nil
+}
+
+func (t *artifactTracker) enabled() bool { return t.policy != cleanupNone }
+
+// drops tells if cleanup drops the artifacts of typ.
+func (t *artifactTracker) drops(typ string) bool {
+	return t.policy == cleanupAll || t.policy == typ+"s"
+}
+
+func (t *artifactTracker) add(typ, name string) {
+	if name == "" {
+		return
+	}
+	t.forget(typ, name)
+	t.artifacts = append(t.artifacts, artifact{typ, name})
+}
+
+func (t *artifactTracker) forget(typ, name string) {
+	for i, a := range t.artifacts {
+		if a.typ == typ && strings.EqualFold(a.name, name) {
+			t.artifacts = append(t.artifacts[:i], t.artifacts[i+1:]...)
+			return
+		}
+	}
+}
+
+// track records the artifacts that stmt created.  Standard SQL
+// statements create the tables of CREATE TABLE, except those of CREATE
+// TABLE IF NOT EXISTS, which may exist before the program.
+func (t *artifactTracker) track(stmt ir.SQLFlowStmt) {
+	if !t.enabled() {
+		return
+	}
+	switch s := stmt.(type) {
+	case *ir.TrainStmt:
+		t.add("model", s.Into)
+	case *ir.PredictStmt:
+		// The result table of the appended or partitioned writes may
+		// exist before the program.
+		if prediction.WriteMode(s.Attributes) != prediction.Append && prediction.Partition(s.Attributes) == "" {
+			t.add("table", s.ResultTable)
+		}
+	case *ir.ExplainStmt:
+		t.add("table", s.Into)
+	case *ir.EvaluateStmt:
+		// The long result table keeps the history of evaluations.
+		if evaluation.ResultSchema(s.Attributes) != evaluation.LongSchema {
+			t.add("table", s.Into)
+		}
+	case *ir.DropModelStmt:
+		t.forget("model", s.ModelName)
+	case *ir.NormalStmt:
+		sql := string(*s)
+		if m := reCreateTable.FindStringSubmatch(sql); m != nil && m[1] == "" {
+			t.add("table", m[2])
+		} else if m := reDropTable.FindStringSubmatch(sql); m != nil {
+			t.forget("table", m[1])
+		}
+	}
+}
+
+// cleanup drops the artifacts of the policy in the reverse order of
+// their creation, and writes the artifacts kept to wr.
+func (t *artifactTracker) cleanup(wr *pipe.Writer, db *database.DB, submitter Submitter) {
+	if !t.enabled() {
+		return
+	}
+	kept := []string{}
+	for i := len(t.artifacts) - 1; i >= 0; i-- {
+		a := t.artifacts[i]
+		if !t.drops(a.typ) {
+			kept = append(kept, a.String())
+			continue
+		}
+		var e error
+		if a.typ == "model" {
+			e = submitter.ExecuteDropModel(&ir.DropModelStmt{ModelName: a.name})
+		} else if _, e = db.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", a.name)); e == nil {
+			e = wr.Write(fmt.Sprintf("table %s dropped", a.name))
+		}
+		if e != nil {
+			wr.Write(fmt.Sprintf("failed to drop %s: %v", a, e))
+			kept = append(kept, a.String())
+		}
+	}
+	if len(kept) > 0 {
+		wr.Write(fmt.Sprintf("artifacts kept after the failure: %s", strings.Join(kept, ", ")))
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sqlflow.org/sqlflow/pkg/database"
+	"sqlflow.org/sqlflow/pkg/ir"
+	"sqlflow.org/sqlflow/pkg/pipe"
+)
+
+func TestNewArtifactTracker(t *testing.T) {
+	a := assert.New(t)
+	tracker, e := newArtifactTracker("")
+	a.NoError(e)
+	a.False(tracker.enabled())
+	tracker, e = newArtifactTracker("ALL")
+	a.NoError(e)
+	a.True(tracker.drops("table"))
+	a.True(tracker.drops("model"))
+	tracker, e = newArtifactTracker("tables")
+	a.NoError(e)
+	a.True(tracker.drops("table"))
+	a.False(tracker.drops("model"))
+	_, e = newArtifactTracker("everything")
+	a.Error(e)
+}
+
+func TestArtifactTrackerTrack(t *testing.T) {
+	a := assert.New(t)
+	tracker, _ := newArtifactTracker("all")
+	normal := func(sql string) *ir.NormalStmt {
+		s := ir.NormalStmt(sql)
+		return &s
+	}
+	tracker.track(normal("CREATE TABLE tmp.a AS SELECT * FROM iris.train"))
+	tracker.track(normal("create table if not exists tmp.b (x int)"))
+	tracker.track(normal("create table tmp.c(x int)"))
+	tracker.track(normal("SELECT * FROM tmp.a"))
+	tracker.track(&ir.TrainStmt{Into: "sqlflow_models.my_model"})
+	tracker.track(&ir.PredictStmt{ResultTable: "iris.predict"})
+	tracker.track(&ir.PredictStmt{ResultTable: "iris.daily_predict", Attributes: map[string]interface{}{"predict.partition": "dt=20200601"}})
+	tracker.track(&ir.EvaluateStmt{Into: "iris.evaluation", Attributes: map[string]interface{}{"validation.result_schema": "long"}})
+	tracker.track(&ir.ExplainStmt{Into: "iris.explain"})
+	tracker.track(normal("DROP TABLE IF EXISTS tmp.c"))
+	a.Equal([]artifact{
+		{"table", "tmp.a"},
+		{"model", "sqlflow_models.my_model"},
+		{"table", "iris.predict"},
+		{"table", "iris.explain"},
+	}, tracker.artifacts)
+
+	tracker.track(&ir.DropModelStmt{ModelName: "sqlflow_models.my_model"})
+	a.Equal(3, len(tracker.artifacts))
+
+	tracker, _ = newArtifactTracker("none")
+	tracker.track(normal("CREATE TABLE tmp.a AS SELECT 1"))
+	a.Empty(tracker.artifacts)
+}
+
+func TestArtifactTrackerCleanup(t *testing.T) {
+	a := assert.New(t)
+	dir, e := ioutil.TempDir("", "sqlflow_cleanup")
+	a.NoError(e)
+	defer os.RemoveAll(dir)
+	db, e := database.OpenAndConnectDB("sqlite3://" + filepath.Join(dir, "cleanup.db"))
+	a.NoError(e)
+	defer db.Close()
+	_, e = db.Exec("CREATE TABLE tmp_a (x int)")
+	a.NoError(e)
+
+	tracker, _ := newArtifactTracker("tables")
+	tracker.track(&ir.TrainStmt{Into: "my_model"})
+	s := ir.NormalStmt("CREATE TABLE tmp_a (x int)")
+	tracker.track(&s)
+
+	rd, wr := pipe.Pipe()
+	go func() {
+		defer wr.Close()
+		tracker.cleanup(wr, db, &defaultSubmitter{})
+	}()
+	messages := []interface{}{}
+	for r := range rd.ReadAll() {
+		messages = append(messages, r)
+	}
+	a.Equal([]interface{}{"table tmp_a dropped", "artifacts kept after the failure: model my_model"}, messages)
+	_, e = db.Exec("SELECT * FROM tmp_a")
+	a.Error(e)
+}
@@ -14,30 +14,59 @@
 package sql
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"os"
 	"os/exec"
+	"runtime"
+	"syscall"
+	"time"
 )
 
+// errCancelled is the error of the commands cancelled by runCmd.
+var errCancelled = errors.New("cancelled")
+
+// cancelGracePeriod is how long runCmd waits for a cancelled command to
+// exit before killing it.
+var cancelGracePeriod = 10 * time.Second
+
 func tryRun(cmd string, args ...string) bool {
 	return exec.Command(cmd, args...).Run() == nil
 }
 
+// python returns the Python interpreter in PATH, python, or python3 if
+// there is no python, like on macOS since 12.3.
+func python() string {
+	if _, e := exec.LookPath("python"); e != nil {
+		if _, e := exec.LookPath("python3"); e == nil {
+			return "python3"
+		}
+	}
+	return "python"
+}
+
 func hasPython() bool {
-	return tryRun("python", "-V")
+	return tryRun(python(), "-V")
 }
 
 func hasTensorFlow() bool {
-	return tryRun("python", "-c", "import tensorflow")
+	return tryRun(python(), "-c", "import tensorflow")
 }
 
 func hasDatabaseConnector(driverName string) bool {
 	if driverName == "hive" {
-		return tryRun("python", "-c", "from impala.dbapi import connect")
+		return tryRun(python(), "-c", "from impala.dbapi import connect")
 	} else if driverName == "mysql" {
-		return tryRun("python", "-c", "from MySQLdb import connect")
+		return tryRun(python(), "-c", "from MySQLdb import connect")
+	} else if driverName == "postgres" {
+		return tryRun(python(), "-c", "from psycopg2 import connect")
+	} else if driverName == "sqlite3" {
+		return tryRun(python(), "-c", "from sqlite3 import connect")
+	} else if driverName == "clickhouse" {
+		return tryRun(python(), "-c", "from clickhouse_driver.dbapi import connect")
 	} else if driverName == "maxcompute" {
-		return tryRun("python", "-c", "from odps import ODPS")
+		return tryRun(python(), "-c", "from odps import ODPS")
 	}
 	return false
 }
@@ -60,7 +89,7 @@ func hasDockerImage(image string) bool {
 
 func sqlflowCmd(cwd, driverName string) (cmd *exec.Cmd) {
 	if hasPython() && hasTensorFlow() && hasDatabaseConnector(driverName) {
-		cmd = exec.Command("python", "-u")
+		cmd = exec.Command(python(), "-u")
 		cmd.Dir = cwd
 	} else if hasDocker() {
 		const tfImg = "sqlflow/sqlflow"
@@ -76,3 +105,37 @@ func sqlflowCmd(cwd, driverName string) (cmd *exec.Cmd) {
 	}
 	return cmd
 }
+
+// runCmd runs cmd like cmd.Run, but terminates it by SIGTERM if cancel
+// is closed before it exits, and kills it if it is still running after
+// cancelGracePeriod. The Docker client forwards SIGTERM to the container.
+// Windows has no SIGTERM, so the command is killed at once.
+func runCmd(cmd *exec.Cmd, cancel <-chan struct{}) error {
+	if e := cmd.Start(); e != nil {
+		return e
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	select {
+	case e := <-done:
+		return e
+	case <-cancel:
+	}
+	if e := terminate(cmd.Process); e != nil {
+		cmd.Process.Kill()
+	}
+	select {
+	case <-done:
+	case <-time.After(cancelGracePeriod):
+		cmd.Process.Kill()
+		<-done
+	}
+	return errCancelled
+}
+
+func terminate(p *os.Process) error {
+	if runtime.GOOS == "windows" {
+		return p.Kill()
+	}
+	return p.Signal(syscall.SIGTERM)
+}
@@ -0,0 +1,45 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunCmd(t *testing.T) {
+	a := assert.New(t)
+	a.NoError(runCmd(exec.Command("true"), nil))
+	a.Error(runCmd(exec.Command("false"), nil))
+
+	cancel := make(chan struct{})
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		close(cancel)
+	}()
+	start := time.Now()
+	a.Equal(errCancelled, runCmd(exec.Command("sleep", "60"), cancel))
+	a.True(time.Since(start) < 10*time.Second)
+
+	// Kill the commands ignoring SIGTERM after the grace period.
+	defer func(d time.Duration) { cancelGracePeriod = d }(cancelGracePeriod)
+	cancelGracePeriod = 100 * time.Millisecond
+	cancel = make(chan struct{})
+	close(cancel)
+	cmd := exec.Command("sh", "-c", `trap "" TERM; sleep 5`)
+	a.Equal(errCancelled, runCmd(cmd, cancel))
+}
@@ -77,42 +77,73 @@ func (d Dictionary) FillDefaults(attrs map[string]interface{}) {
 // Validate validates the attribute based on dictionary. The validation includes
 //   1. Type checking
 //   2. Customer checker
+// It reports the errors of all the attributes, one line for each, in the
+// order of the keys.
 func (d Dictionary) Validate(attrs map[string]interface{}) error {
-	for k, v := range attrs {
-		var desc *Description
-		desc, ok := d[k]
-		if !ok {
-			// Support attribute definition like "model.*" to match attributes start with "model"
-			keyParts := strings.Split(k, ".")
-			if len(keyParts) == 2 {
-				wildCard := fmt.Sprintf("%s.*", keyParts[0])
-				descWild, okWildCard := d[wildCard]
-				if okWildCard {
-					desc = descWild
-				} else {
-					return fmt.Errorf(errUnsupportedAttribute, k)
-				}
-			} else {
-				return fmt.Errorf(errUnsupportedAttribute, k)
-			}
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	msgs := []string{}
+	for _, k := range keys {
+		if err := d.validate(k, attrs[k]); err != nil {
+			msgs = append(msgs, err.Error())
 		}
+	}
+	if len(msgs) > 0 {
+		return fmt.Errorf("%s", strings.Join(msgs, "\n"))
+	}
+	return nil
+}
 
-		if desc.Type != Unknown && desc.Type != reflect.TypeOf(v) {
-			// Allow implicit conversion from int to float to ease typing
-			if !(desc.Type == Float && reflect.TypeOf(v) == Int) {
-				return fmt.Errorf(errUnexpectedType, k, desc.Type, v)
+func (d Dictionary) validate(k string, v interface{}) error {
+	var desc *Description
+	desc, ok := d[k]
+	if !ok {
+		// Support attribute definition like "model.*" to match attributes start with "model"
+		keyParts := strings.Split(k, ".")
+		if len(keyParts) == 2 {
+			wildCard := fmt.Sprintf("%s.*", keyParts[0])
+			descWild, okWildCard := d[wildCard]
+			if okWildCard {
+				desc = descWild
+			} else {
+				return d.unsupportedAttributeError(k)
 			}
+		} else {
+			return d.unsupportedAttributeError(k)
 		}
+	}
 
-		if desc.Checker != nil {
-			if err := desc.Checker(v); err != nil {
-				return err
-			}
+	if desc.Type != Unknown && desc.Type != reflect.TypeOf(v) {
+		// Allow implicit conversion from int to float to ease typing,
+		// and the lists of integers parsed from the WITH clause, which
+		// are []interface{}.
+		if !(desc.Type == Float && reflect.TypeOf(v) == Int) && !(desc.Type == IntList && isIntList(v)) {
+			return fmt.Errorf(errUnexpectedType, k, desc.Type, v)
 		}
 	}
+
+	if desc.Checker != nil {
+		return desc.Checker(v)
+	}
 	return nil
 }
 
+func isIntList(v interface{}) bool {
+	l, ok := v.([]interface{})
+	if !ok {
+		return false
+	}
+	for _, i := range l {
+		if _, ok := i.(int); !ok {
+			return false
+		}
+	}
+	return true
+}
+
 // GenerateTableInHTML generates the attribute dictionary table in HTML format
 func (d Dictionary) GenerateTableInHTML() string {
 	l := []string{`<table>`,
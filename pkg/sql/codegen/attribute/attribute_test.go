@@ -37,10 +37,18 @@ func TestDictionaryValidate(t *testing.T) {
 	tb := Dictionary{"a": {Int, 1, "attribute a", checker}, "b": {Float, 1, "attribute b", nil}}
 	a.NoError(tb.Validate(map[string]interface{}{"a": 1}))
 	a.EqualError(tb.Validate(map[string]interface{}{"a": -1}), "some error")
-	a.EqualError(tb.Validate(map[string]interface{}{"_a": -1}), fmt.Sprintf(errUnsupportedAttribute, "_a"))
+	a.EqualError(tb.Validate(map[string]interface{}{"_a": -1}), fmt.Sprintf(errUnsupportedAttribute, "_a")+", did you mean a?")
+	a.EqualError(tb.Validate(map[string]interface{}{"xyzw": -1}), fmt.Sprintf(errUnsupportedAttribute, "xyzw"))
 	a.EqualError(tb.Validate(map[string]interface{}{"a": 1.0}), fmt.Sprintf(errUnexpectedType, "a", "int", 1.))
 	a.NoError(tb.Validate(map[string]interface{}{"b": float32(1.0)}))
 	a.NoError(tb.Validate(map[string]interface{}{"b": 1}))
+	a.EqualError(tb.Validate(map[string]interface{}{"b": "1", "a": -1, "xyzw": -1}),
+		"some error\n"+fmt.Sprintf(errUnexpectedType, "b", "float32", "1")+"\n"+fmt.Sprintf(errUnsupportedAttribute, "xyzw"))
+
+	tb = Dictionary{"c": {IntList, []int{10}, "attribute c", nil}}
+	a.NoError(tb.Validate(map[string]interface{}{"c": []int{10, 10}}))
+	a.NoError(tb.Validate(map[string]interface{}{"c": []interface{}{10, 10}}))
+	a.Error(tb.Validate(map[string]interface{}{"c": []interface{}{10, "10"}}))
 }
 
 func TestParamsDocs(t *testing.T) {
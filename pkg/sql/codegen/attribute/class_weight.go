@@ -0,0 +1,63 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attribute
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ClassWeightBalanced is the train.class_weight that weights the classes
+// inversely proportional to their frequencies in the training data.
+const ClassWeightBalanced = "balanced"
+
+// ParseClassWeight returns the weights of the labels in the attribute
+// train.class_weight like "0:1,1:50", or nil for "" and "balanced",
+// whose weights are computed from the training data.
+func ParseClassWeight(classWeight string) (map[int]float64, error) {
+	if classWeight == "" || classWeight == ClassWeightBalanced {
+		return nil, nil
+	}
+	weights := map[int]float64{}
+	for _, pair := range strings.Split(classWeight, ",") {
+		kv := strings.Split(pair, ":")
+		if len(kv) != 2 {
+			return nil, fmt.Errorf(`train.class_weight should be "balanced" or like "0:1,1:50", received %q`, classWeight)
+		}
+		label, e := strconv.Atoi(strings.TrimSpace(kv[0]))
+		if e != nil {
+			return nil, fmt.Errorf("train.class_weight: invalid label %q", kv[0])
+		}
+		weight, e := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64)
+		if e != nil || weight <= 0 {
+			return nil, fmt.Errorf("train.class_weight: the weight of label %d should be a positive number, received %q", label, kv[1])
+		}
+		if _, ok := weights[label]; ok {
+			return nil, fmt.Errorf("train.class_weight: duplicated label %d", label)
+		}
+		weights[label] = weight
+	}
+	return weights, nil
+}
+
+// ClassWeightChecker checks the attribute train.class_weight.
+func ClassWeightChecker(attr interface{}) error {
+	s, ok := attr.(string)
+	if !ok {
+		return fmt.Errorf("expected type string, received %T", attr)
+	}
+	_, e := ParseClassWeight(s)
+	return e
+}
@@ -0,0 +1,40 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attribute
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseClassWeight(t *testing.T) {
+	a := assert.New(t)
+	w, e := ParseClassWeight("")
+	a.NoError(e)
+	a.Nil(w)
+	w, e = ParseClassWeight("balanced")
+	a.NoError(e)
+	a.Nil(w)
+	w, e = ParseClassWeight("0:1, 1:50.5")
+	a.NoError(e)
+	a.Equal(map[int]float64{0: 1, 1: 50.5}, w)
+
+	for _, bad := range []string{"Balanced", "0:1,1", "a:1", "0:-1", "0:x", "0:1,0:2"} {
+		_, e = ParseClassWeight(bad)
+		a.Error(e, bad)
+	}
+	a.Error(ClassWeightChecker(1))
+	a.NoError(ClassWeightChecker("balanced"))
+}
@@ -0,0 +1,42 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attribute
+
+import "strings"
+
+// Group groups attrs by prefixes, like "model." and "train.", and
+// removes the prefixes from the keys. The prefix "" groups the keys
+// without any prefix, so the keys like "validation.select" belong to no
+// group unless "validation." is in prefixes.
+func Group(attrs map[string]interface{}, prefixes ...string) map[string]map[string]interface{} {
+	groups := map[string]map[string]interface{}{}
+	for _, p := range prefixes {
+		groups[p] = map[string]interface{}{}
+	}
+	for key, attr := range attrs {
+		if !strings.Contains(key, ".") {
+			if g, ok := groups[""]; ok {
+				g[key] = attr
+			}
+			continue
+		}
+		for _, p := range prefixes {
+			if p != "" && strings.HasPrefix(key, p) {
+				groups[p][key[len(p):]] = attr
+				break
+			}
+		}
+	}
+	return groups
+}
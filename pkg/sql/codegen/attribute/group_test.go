@@ -0,0 +1,36 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attribute
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup(t *testing.T) {
+	a := assert.New(t)
+	attrs := map[string]interface{}{"model.a": "b", "optimizer.c": "d", "train.e": "f", "validation.select": "g", "h": "i"}
+	groups := Group(attrs, "model.", "optimizer.", "train.")
+	a.Equal(3, len(groups))
+	a.Equal(map[string]interface{}{"a": "b"}, groups["model."])
+	a.Equal(map[string]interface{}{"c": "d"}, groups["optimizer."])
+	a.Equal(map[string]interface{}{"e": "f"}, groups["train."])
+
+	// The prefix "" groups only the keys without any prefix.
+	groups = Group(attrs, "", "train.")
+	a.Equal(map[string]interface{}{"h": "i"}, groups[""])
+	a.Equal(map[string]interface{}{"e": "f"}, groups["train."])
+	a.Equal(map[string]interface{}{}, Group(nil, "")[""])
+}
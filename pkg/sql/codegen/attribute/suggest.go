@@ -0,0 +1,96 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attribute
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxSuggestions is the max number of the attributes that Suggest
+// returns.
+const maxSuggestions = 3
+
+// editDistance returns the Levenshtein distance of a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(min(prev[j]+1, cur[j-1]+1), prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// Suggest returns the attributes in d closest to the unsupported
+// attribute key by the edit distance ignoring the case, like
+// model.hidden_units for model.hidden_unit, or nil if none is close
+// enough. The wildcards like model.* are never suggested.
+func (d Dictionary) Suggest(key string) []string {
+	// Allow a typo every four characters, at least two.
+	maxDistance := len(key) / 4
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+	best := maxDistance + 1
+	suggestions := []string{}
+	for k := range d {
+		if strings.Contains(k, "*") {
+			continue
+		}
+		dist := editDistance(strings.ToLower(key), strings.ToLower(k))
+		if dist < best {
+			best = dist
+			suggestions = []string{k}
+		} else if dist == best {
+			suggestions = append(suggestions, k)
+		}
+	}
+	if len(suggestions) == 0 {
+		return nil
+	}
+	sort.Strings(suggestions)
+	if len(suggestions) > maxSuggestions {
+		suggestions = suggestions[:maxSuggestions]
+	}
+	return suggestions
+}
+
+// unsupportedAttributeError returns the error of the unsupported
+// attribute key, with the suggestions of d if any.
+func (d Dictionary) unsupportedAttributeError(key string) error {
+	if s := d.Suggest(key); len(s) > 0 {
+		return fmt.Errorf(errUnsupportedAttribute+", did you mean %s?", key, strings.Join(s, " or "))
+	}
+	return fmt.Errorf(errUnsupportedAttribute, key)
+}
@@ -0,0 +1,48 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attribute
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEditDistance(t *testing.T) {
+	a := assert.New(t)
+	a.Equal(0, editDistance("", ""))
+	a.Equal(3, editDistance("abc", ""))
+	a.Equal(1, editDistance("hidden_unit", "hidden_units"))
+	a.Equal(3, editDistance("kitten", "sitting"))
+}
+
+func TestSuggest(t *testing.T) {
+	a := assert.New(t)
+	d := Dictionary{
+		"model.hidden_units": {IntList, nil, "", nil},
+		"model.n_classes":    {Int, nil, "", nil},
+		"train.epoch":        {Int, 1, "", nil},
+		"train.batch_size":   {Int, 1, "", nil},
+		"optimizer.*":        {Unknown, nil, "", nil},
+	}
+	a.Equal([]string{"model.hidden_units"}, d.Suggest("model.hidden_unit"))
+	a.Equal([]string{"train.epoch"}, d.Suggest("Train.Epochs"))
+	a.Nil(d.Suggest("validation.select"))
+	a.Nil(d.Suggest("optimizer"))
+
+	e := d.Validate(map[string]interface{}{"model.hidden_unit": []int{10}})
+	a.EqualError(e, "unsupported attribute model.hidden_unit, did you mean model.hidden_units?")
+	e = d.Validate(map[string]interface{}{"foo": 1})
+	a.EqualError(e, "unsupported attribute foo")
+}
@@ -0,0 +1,60 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package catboost
+
+import (
+	"bytes"
+	"text/template"
+)
+
+// DocGenInMarkdown generates the doc of the CatBoost in Markdown format.
+func DocGenInMarkdown() string {
+	var doc bytes.Buffer
+	docTemplate.Execute(&doc, attributeDictionary.GenerateTableInHTML())
+
+	return doc.String()
+}
+
+const docTemplateText = `# CatBoost Parameters
+
+## TRAIN
+
+### Example
+
+` + "```SQL" + `
+SELECT * FROM iris.train
+TO TRAIN catboost.CatBoostClassifier
+WITH
+    classes_count = 3,
+    depth = 4,
+    iterations = 30,
+    validation.select = "SELECT * FROM iris.test"
+LABEL class
+INTO sqlflow_models.my_catboost_model;
+` + "```" + `
+
+### Model Types
+
+` + "`catboost.CatBoostClassifier`, `catboost.CatBoostRegressor`" + `
+
+### Parameters
+
+{{.}}
+
+## PREDICT
+
+TBD
+`
+
+var docTemplate = template.Must(template.New("Doc").Parse(docTemplateText))
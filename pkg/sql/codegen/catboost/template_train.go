@@ -0,0 +1,62 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package catboost
+
+import "text/template"
+
+type trainFiller struct {
+	DataSource          string
+	TrainSelect         string
+	ValidationSelect    string
+	Estimator           string
+	ModelParamsJSON     string
+	TrainParamsJSON     string
+	CrossValidationJSON string
+	TuningJSON          string
+	FieldDescJSON       string
+	FeatureColumnNames  []string
+	LabelJSON           string
+	WarmStart           bool
+}
+
+const trainTemplateText = `
+from sqlflow_submitter.catboost.train import train
+import json
+
+model_params = json.loads('''{{.ModelParamsJSON}}''')
+train_params = json.loads('''{{.TrainParamsJSON}}''')
+cv_params = json.loads('''{{.CrossValidationJSON}}''')
+tuning_params = json.loads('''{{.TuningJSON}}''')
+feature_metas = json.loads('''{{.FieldDescJSON}}''')
+label_meta = json.loads('''{{.LabelJSON}}''')
+
+feature_column_names = [{{range .FeatureColumnNames}}
+"{{.}}",
+{{end}}]
+
+train(datasource='''{{.DataSource}}''',
+      select='''{{.TrainSelect}}''',
+      validation_select='''{{.ValidationSelect}}''',
+      estimator="{{.Estimator}}",
+      model_params=model_params,
+      train_params=train_params,
+      feature_metas=feature_metas,
+      feature_column_names=feature_column_names,
+      label_meta=label_meta,
+      cv_params=cv_params,
+      tuning_params=tuning_params,
+      warm_start="{{.WarmStart}}" == "true")
+`
+
+var trainTemplate = template.Must(template.New("Train").Parse(trainTemplateText))
@@ -0,0 +1,88 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package datacheck validates the rows of the training data by a SQL
+// condition before the training, as set by WITH data.validate.
+package datacheck
+
+import (
+	"fmt"
+	"strings"
+
+	"sqlflow.org/sqlflow/pkg/sql/codegen/attribute"
+)
+
+// The values of data.on_violation.
+const (
+	Fail = "fail"
+	Drop = "drop"
+)
+
+// Attributes are the attributes of TRAIN validating the rows of the
+// training and the validation data, for the code generators that train
+// on the SELECT.
+var Attributes = attribute.Dictionary{
+	"data.validate": {attribute.String, nil, `[default=""]
+The SQL condition every row of the training and the validation data should meet, like "age >= 0 AND income IS NOT NULL". A row violates it unless the condition is true, so the NULL of "age >= 0" is a violation. SQLFlow counts the violations before the training, and reports them in the output. Not set means no validation.`, func(i interface{}) error {
+		if s, _ := i.(string); strings.Contains(s, ";") {
+			return fmt.Errorf("data.validate should be a SQL condition, got %s", s)
+		}
+		return nil
+	}},
+	"data.on_violation": {attribute.String, nil, `[default="fail"]
+What to do if any row violates data.validate, "fail" fails the training, and "drop" trains without the rows.`, func(i interface{}) error {
+		if s, _ := i.(string); s != Fail && s != Drop {
+			return fmt.Errorf(`data.on_violation should be "fail" or "drop", got %v`, i)
+		}
+		return nil
+	}},
+}
+
+// Rule returns the condition of data.validate in attrs, or "" for no
+// validation.
+func Rule(attrs map[string]interface{}) string {
+	r, _ := attrs["data.validate"].(string)
+	return strings.TrimSpace(r)
+}
+
+// OnViolation returns data.on_violation in attrs.
+func OnViolation(attrs map[string]interface{}) string {
+	if s, _ := attrs["data.on_violation"].(string); s != "" {
+		return s
+	}
+	return Fail
+}
+
+// Check returns an error if attrs set data.on_violation without
+// data.validate.
+func Check(attrs map[string]interface{}) error {
+	if _, ok := attrs["data.on_violation"]; ok && Rule(attrs) == "" {
+		return fmt.Errorf("data.on_violation requires data.validate")
+	}
+	return nil
+}
+
+// CountQuery returns the query of the number of the rows of slct and the
+// number of the rows violating rule.
+func CountQuery(slct, rule string) string {
+	return fmt.Sprintf("SELECT COUNT(*), COALESCE(SUM(CASE WHEN (%s) THEN 0 ELSE 1 END), 0) FROM (%s) AS sqlflow_validate", rule, trimSelect(slct))
+}
+
+// Filter returns the SELECT of the rows of slct meeting rule.
+func Filter(slct, rule string) string {
+	return fmt.Sprintf("SELECT * FROM (%s) AS sqlflow_validated WHERE (%s)", trimSelect(slct), rule)
+}
+
+func trimSelect(slct string) string {
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(slct), ";"))
+}
@@ -0,0 +1,43 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacheck
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataCheck(t *testing.T) {
+	a := assert.New(t)
+	attrs := map[string]interface{}{}
+	a.Equal("", Rule(attrs))
+	a.Equal(Fail, OnViolation(attrs))
+	a.NoError(Check(attrs))
+
+	attrs["data.on_violation"] = Drop
+	a.Error(Check(attrs))
+	attrs["data.validate"] = " age >= 0 AND income IS NOT NULL "
+	a.NoError(Check(attrs))
+	a.Equal("age >= 0 AND income IS NOT NULL", Rule(attrs))
+	a.Equal(Drop, OnViolation(attrs))
+
+	a.Equal("SELECT COUNT(*), COALESCE(SUM(CASE WHEN (age >= 0) THEN 0 ELSE 1 END), 0) FROM (SELECT * FROM t) AS sqlflow_validate",
+		CountQuery("SELECT * FROM t;", "age >= 0"))
+	a.Equal("SELECT * FROM (SELECT * FROM t) AS sqlflow_validated WHERE (age >= 0)", Filter("SELECT * FROM t ;", "age >= 0"))
+
+	a.NoError(Attributes.Validate(map[string]interface{}{"data.validate": "age >= 0", "data.on_violation": "fail"}))
+	a.Error(Attributes.Validate(map[string]interface{}{"data.validate": "age >= 0; DROP TABLE t"}))
+	a.Error(Attributes.Validate(map[string]interface{}{"data.on_violation": "skip"}))
+}
@@ -0,0 +1,65 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package evaluation
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"sqlflow.org/sqlflow/pkg/sql/codegen/attribute"
+)
+
+// CrossValidationAttributes are the attributes of TRAIN of the k-fold
+// cross-validation, for the code generators that support it.
+var CrossValidationAttributes = attribute.Dictionary{
+	"validation.kfold": {attribute.Int, 0, `[default=0]
+The number of folds of the k-fold cross-validation on the training data before training the model, 0 means no cross-validation.
+range: 0 or [2, Infinity]`, kfoldChecker},
+	"validation.metrics": {attribute.String, nil, `[default="accuracy_score" for the classifiers, "RMSE" for the regressors]
+Metrics of the cross-validation, separated by commas, like "AUC,F1".`, nil},
+}
+
+func kfoldChecker(attr interface{}) error {
+	if k, ok := attr.(int); !ok || k < 0 || k == 1 {
+		return fmt.Errorf("validation.kfold should be 0 or an integer >= 2, received %v", attr)
+	}
+	return nil
+}
+
+// CrossValidation are the parameters of the k-fold cross-validation of
+// TRAIN passed to the Python training functions as cv_params.
+type CrossValidation struct {
+	KFold   int      `json:"kfold"`
+	Metrics []string `json:"metrics"`
+}
+
+// ResolveCrossValidation returns the parameters of the cross-validation
+// in attrs, checked by CrossValidationAttributes. The metrics are
+// defaultMetrics, separated by commas, if validation.metrics is not set.
+func ResolveCrossValidation(attrs map[string]interface{}, defaultMetrics string) *CrossValidation {
+	cv := &CrossValidation{Metrics: splitMetrics(defaultMetrics)}
+	if k, ok := attrs["validation.kfold"].(int); ok {
+		cv.KFold = k
+	}
+	if m, ok := attrs["validation.metrics"].(string); ok {
+		cv.Metrics = splitMetrics(m)
+	}
+	return cv
+}
+
+// JSON returns the parameters as cv_params.
+func (cv *CrossValidation) JSON() (string, error) {
+	b, err := json.Marshal(cv)
+	return string(b), err
+}
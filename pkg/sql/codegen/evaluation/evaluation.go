@@ -0,0 +1,122 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package evaluation
+
+import (
+	"encoding/json"
+	"strings"
+
+	"sqlflow.org/sqlflow/pkg/ir"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/attribute"
+)
+
+const (
+	// WideSchema is the default schema of the result table of EVALUATE,
+	// which has the column loss and a column of each metric, and a row of
+	// the values. The table is recreated by each evaluation.
+	WideSchema = "wide"
+	// LongSchema is the schema of the result table of EVALUATE keyed by
+	// the model, its version, and the time of the evaluation, which has
+	// the columns model, model_version, evaluated_at, metric and value,
+	// and a row of each metric. The rows are appended to the table, so
+	// it tracks the evaluations of the model over time.
+	LongSchema = "long"
+)
+
+var attributeDictionary = attribute.Dictionary{
+	"validation.metrics": {attribute.String, nil, `Metrics to be evaluated, separated by commas, like "AUC,PR_AUC,F1".
+The metrics of the models other than TensorFlow are AUC, PR_AUC, F1, RMSE, MAE, NDCG
+and the functions in sklearn.metrics, like accuracy_score.`, nil},
+	"validation.result_schema": {attribute.String, WideSchema, `[default="wide"]
+The schema of the result table.
+possible values: "wide", a column of each metric, and "long", a row of each metric keyed by the model, its version and the evaluation time`, attribute.StringChoicesChecker(WideSchema, LongSchema)},
+	"validation.model_version": {attribute.String, "", `[default=""]
+The version of the model recorded in the result table of the schema "long".`, nil},
+	"validation.threshold": {attribute.Float, float32(0.5), `[default=0.5]
+The threshold of the positive class of F1.
+range: [0,1]`, attribute.Float32RangeChecker(0, 1, true, true)},
+	"validation.ndcg_k": {attribute.Int, 0, `[default=0]
+Only consider the highest k scores of NDCG, 0 means all.
+range: [0, Infinity]`, attribute.IntLowerBoundChecker(0, true)},
+}
+
+// Params are the parameters of an evaluation in the WITH clause of
+// EVALUATE.
+type Params struct {
+	Metrics      []string `json:"-"`
+	ResultSchema string   `json:"result_schema"`
+	ModelName    string   `json:"model_name"`
+	ModelVersion string   `json:"model_version"`
+	Threshold    float32  `json:"threshold"`
+	NDCGK        int      `json:"ndcg_k"`
+}
+
+// Resolve returns the parameters of evalStmt. The metrics are
+// defaultMetrics, separated by commas, if validation.metrics is not set.
+// The attributes not in the dictionary are left to the code generators.
+func Resolve(evalStmt *ir.EvaluateStmt, defaultMetrics string) (*Params, error) {
+	attrs := map[string]interface{}{}
+	for k, v := range evalStmt.Attributes {
+		if _, ok := attributeDictionary[k]; ok {
+			attrs[k] = v
+		}
+	}
+	attributeDictionary.FillDefaults(attrs)
+	if err := attributeDictionary.Validate(attrs); err != nil {
+		return nil, err
+	}
+	metrics := defaultMetrics
+	if m, ok := attrs["validation.metrics"]; ok {
+		metrics = m.(string)
+	}
+	return &Params{
+		Metrics:      splitMetrics(metrics),
+		ResultSchema: attrs["validation.result_schema"].(string),
+		ModelName:    evalStmt.ModelName,
+		ModelVersion: attrs["validation.model_version"].(string),
+		Threshold:    attrs["validation.threshold"].(float32),
+		NDCGK:        attrs["validation.ndcg_k"].(int),
+	}, nil
+}
+
+// ResultSchema returns the schema of the result table set by
+// validation.result_schema in attrs.
+func ResultSchema(attrs map[string]interface{}) string {
+	if s, ok := attrs["validation.result_schema"].(string); ok && s == LongSchema {
+		return LongSchema
+	}
+	return WideSchema
+}
+
+func splitMetrics(metrics string) []string {
+	names := []string{}
+	for _, m := range strings.Split(metrics, ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			names = append(names, m)
+		}
+	}
+	return names
+}
+
+// MetricNames returns the metrics separated by commas.
+func (p *Params) MetricNames() string {
+	return strings.Join(p.Metrics, ",")
+}
+
+// JSON returns the parameters passed to the Python evaluation functions
+// as evaluation_params.
+func (p *Params) JSON() (string, error) {
+	b, err := json.Marshal(p)
+	return string(b), err
+}
@@ -0,0 +1,76 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package evaluation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sqlflow.org/sqlflow/pkg/ir"
+)
+
+func TestResolve(t *testing.T) {
+	a := assert.New(t)
+	stmt := &ir.EvaluateStmt{ModelName: "sqlflow_models.my_model", Attributes: map[string]interface{}{}}
+	p, err := Resolve(stmt, "accuracy_score")
+	a.NoError(err)
+	a.Equal([]string{"accuracy_score"}, p.Metrics)
+	a.Equal(WideSchema, p.ResultSchema)
+	s, err := p.JSON()
+	a.NoError(err)
+	a.Equal(`{"result_schema":"wide","model_name":"sqlflow_models.my_model","model_version":"","threshold":0.5,"ndcg_k":0}`, s)
+
+	stmt.Attributes = map[string]interface{}{
+		"validation.metrics":       "AUC, PR_AUC,F1,",
+		"validation.result_schema": "long",
+		"validation.model_version": "v2",
+		"validation.threshold":     float32(0.3),
+		"validation.ndcg_k":        10,
+		"label_col":                "class",
+	}
+	p, err = Resolve(stmt, "accuracy_score")
+	a.NoError(err)
+	a.Equal("AUC,PR_AUC,F1", p.MetricNames())
+	a.Equal(LongSchema, p.ResultSchema)
+	a.Equal("v2", p.ModelVersion)
+	a.Equal(float32(0.3), p.Threshold)
+	a.Equal(10, p.NDCGK)
+	a.Equal(LongSchema, ResultSchema(stmt.Attributes))
+
+	stmt.Attributes = map[string]interface{}{"validation.result_schema": "narrow"}
+	_, err = Resolve(stmt, "accuracy_score")
+	a.Error(err)
+	a.Equal(WideSchema, ResultSchema(stmt.Attributes))
+	stmt.Attributes = map[string]interface{}{"validation.threshold": float32(1.5)}
+	_, err = Resolve(stmt, "accuracy_score")
+	a.Error(err)
+}
+
+func TestResolveCrossValidation(t *testing.T) {
+	a := assert.New(t)
+	attrs := map[string]interface{}{}
+	CrossValidationAttributes.FillDefaults(attrs)
+	a.NoError(CrossValidationAttributes.Validate(attrs))
+	cv := ResolveCrossValidation(attrs, "RMSE")
+	a.Equal(&CrossValidation{KFold: 0, Metrics: []string{"RMSE"}}, cv)
+
+	attrs = map[string]interface{}{"validation.kfold": 5, "validation.metrics": "AUC,F1"}
+	a.NoError(CrossValidationAttributes.Validate(attrs))
+	s, err := ResolveCrossValidation(attrs, "accuracy_score").JSON()
+	a.NoError(err)
+	a.Equal(`{"kfold":5,"metrics":["AUC","F1"]}`, s)
+
+	a.Error(CrossValidationAttributes.Validate(map[string]interface{}{"validation.kfold": 1}))
+	a.Error(CrossValidationAttributes.Validate(map[string]interface{}{"validation.kfold": -1}))
+}
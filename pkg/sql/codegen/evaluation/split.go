@@ -0,0 +1,63 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package evaluation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sqlflow.org/sqlflow/pkg/sql/codegen/attribute"
+)
+
+// SplitAttributes are the attributes of TRAIN splitting the validation
+// data from the training data by time, for the code generators that
+// support validation.select.
+var SplitAttributes = attribute.Dictionary{
+	"validation.split_column": {attribute.String, nil, `[default=""]
+The time column to split the result of the SELECT by, the rows at or after validation.split_at are the validation data, and the rows before are the training data, so the model is never validated on the data older than what it's trained on.
+example: "event_time"`, nil},
+	"validation.split_at": {attribute.String, nil, `[default=""]
+The time to split the result of the SELECT by validation.split_column.
+example: "2020-06-01"`, nil},
+}
+
+var reSplitColumn = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// SplitByTime returns the SELECTs of the training and the validation
+// data split from slct by validation.split_column and validation.split_at
+// in attrs, or slct and validationSelect if they are not set.
+func SplitByTime(slct, validationSelect string, attrs map[string]interface{}) (string, string, error) {
+	column, at := attrs["validation.split_column"], attrs["validation.split_at"]
+	if column == nil && at == nil {
+		return slct, validationSelect, nil
+	}
+	c, ok := column.(string)
+	if !ok || !reSplitColumn.MatchString(c) {
+		return "", "", fmt.Errorf("validation.split_at requires validation.split_column to be a column name, received %v", column)
+	}
+	t, ok := at.(string)
+	if !ok || t == "" {
+		return "", "", fmt.Errorf("validation.split_column requires validation.split_at to be a time like \"2020-06-01\", received %v", at)
+	}
+	if validationSelect != "" {
+		return "", "", fmt.Errorf("validation.split_column and validation.select can't be both set")
+	}
+	slct = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(slct), ";"))
+	t = strings.Replace(t, "'", "''", -1)
+	split := func(op string) string {
+		return fmt.Sprintf("SELECT * FROM (%s) AS sqlflow_split WHERE %s %s '%s'", slct, c, op, t)
+	}
+	return split("<"), split(">="), nil
+}
@@ -0,0 +1,49 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package evaluation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitByTime(t *testing.T) {
+	a := assert.New(t)
+	train, validation, err := SplitByTime("SELECT * FROM t;", "", map[string]interface{}{})
+	a.NoError(err)
+	a.Equal("SELECT * FROM t;", train)
+	a.Equal("", validation)
+	train, validation, err = SplitByTime("SELECT * FROM t;", "SELECT * FROM v", map[string]interface{}{})
+	a.NoError(err)
+	a.Equal("SELECT * FROM v", validation)
+
+	attrs := map[string]interface{}{"validation.split_column": "event_time", "validation.split_at": "2020-06-01"}
+	train, validation, err = SplitByTime("SELECT * FROM t WHERE a > 1 ;\n", "", attrs)
+	a.NoError(err)
+	a.Equal("SELECT * FROM (SELECT * FROM t WHERE a > 1) AS sqlflow_split WHERE event_time < '2020-06-01'", train)
+	a.Equal("SELECT * FROM (SELECT * FROM t WHERE a > 1) AS sqlflow_split WHERE event_time >= '2020-06-01'", validation)
+
+	_, _, err = SplitByTime("SELECT * FROM t", "SELECT * FROM v", attrs)
+	a.EqualError(err, "validation.split_column and validation.select can't be both set")
+	_, _, err = SplitByTime("SELECT * FROM t", "", map[string]interface{}{"validation.split_column": "event_time"})
+	a.EqualError(err, `validation.split_column requires validation.split_at to be a time like "2020-06-01", received <nil>`)
+	_, _, err = SplitByTime("SELECT * FROM t", "", map[string]interface{}{"validation.split_at": "2020-06-01"})
+	a.EqualError(err, "validation.split_at requires validation.split_column to be a column name, received <nil>")
+	_, _, err = SplitByTime("SELECT * FROM t", "", map[string]interface{}{"validation.split_column": "a; DROP TABLE t", "validation.split_at": "2020-06-01"})
+	a.Error(err)
+	train, _, err = SplitByTime("SELECT * FROM t", "", map[string]interface{}{"validation.split_column": "ts", "validation.split_at": "2020'"})
+	a.NoError(err)
+	a.Equal("SELECT * FROM (SELECT * FROM t) AS sqlflow_split WHERE ts < '2020'''", train)
+}
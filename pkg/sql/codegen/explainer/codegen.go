@@ -0,0 +1,148 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package explainer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"sqlflow.org/sqlflow/pkg/ir"
+	"sqlflow.org/sqlflow/pkg/model"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/attribute"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/fieldmeta"
+	tf "sqlflow.org/sqlflow/pkg/sql/codegen/tensorflow"
+)
+
+const (
+	// KernelExplainer explains a model by SHAP KernelExplainer, and is
+	// the default explainer.
+	KernelExplainer = "KernelExplainer"
+	// PermutationImportance explains a model by the change of its
+	// predictions when the values of a feature are shuffled.
+	PermutationImportance = "PermutationImportance"
+
+	summaryAttrPrefix = "summary."
+)
+
+// frameworks are the frameworks whose models this package explains. Their
+// models are saved by the submitters in sqlflow_submitter, so they can be
+// loaded in the working directory after pkg/model loads the model.
+var frameworks = map[string]bool{"pytorch": true, "lightgbm": true, "catboost": true, "sklearn": true}
+
+var attributeDictionary = attribute.Dictionary{
+	"explain.sample_size": {attribute.Int, 100, `[default=100]
+The number of rows sampled from the SELECT to explain.
+range: [1, Infinity]`, attribute.IntLowerBoundChecker(1, true)},
+	"explain.background_size": {attribute.Int, 50, `[default=50]
+The number of k-means centers of the sampled rows that KernelExplainer
+uses as the background dataset.
+range: [1, Infinity]`, attribute.IntLowerBoundChecker(1, true)},
+	"explain.n_repeats": {attribute.Int, 5, `[default=5]
+The number of times PermutationImportance shuffles a feature.
+range: [1, Infinity]`, attribute.IntLowerBoundChecker(1, true)},
+	"explain.random_state": {attribute.Int, 0, `[default=0]
+The seed of sampling the rows and shuffling the features.`, nil},
+	"summary.*": {attribute.Unknown, nil, `The parameters of shap.summary_plot, like summary.plot_type="bar".`, nil},
+}
+
+// Supports returns true if the models of the estimator can be explained
+// by this package.
+func Supports(estimator string) bool {
+	return frameworks[model.FrameworkOf(estimator)]
+}
+
+func resolveExplainer(explainer string) (string, error) {
+	switch explainer {
+	case "", KernelExplainer:
+		return KernelExplainer, nil
+	case PermutationImportance:
+		return PermutationImportance, nil
+	}
+	return "", fmt.Errorf("unsupported explainer %s, currently supports %s, %s", explainer, KernelExplainer, PermutationImportance)
+}
+
+func resolveParams(attrs map[string]interface{}, group string) map[string]interface{} {
+	sp := make(map[string]interface{})
+	for k, v := range attrs {
+		if strings.HasPrefix(k, group) {
+			sp[k[len(group):]] = v
+		}
+	}
+	return sp
+}
+
+// Explain generates a Python program to explain a model trained by any of
+// PyTorch, LightGBM, CatBoost, and scikit-learn. The program runs the
+// explainer, KernelExplainer or PermutationImportance, on the rows sampled
+// from the SELECT, plots the attributions, and writes the attribution of
+// each feature into explainStmt.Into if it's not empty.
+func Explain(explainStmt *ir.ExplainStmt, session *pb.Session) (string, error) {
+	if tf.IsPAI() {
+		return "", fmt.Errorf("explaining %s models is not supported on PAI", explainStmt.TrainStmt.Estimator)
+	}
+	framework := model.FrameworkOf(explainStmt.TrainStmt.Estimator)
+	if !frameworks[framework] {
+		return "", fmt.Errorf("unsupported model %s, currently supports the models of pytorch, lightgbm, catboost, and sklearn", explainStmt.TrainStmt.Estimator)
+	}
+	explainer, err := resolveExplainer(explainStmt.Explainer)
+	if err != nil {
+		return "", err
+	}
+	attributeDictionary.FillDefaults(explainStmt.Attributes)
+	if err := attributeDictionary.Validate(explainStmt.Attributes); err != nil {
+		return "", err
+	}
+	summaryParams, err := json.Marshal(resolveParams(explainStmt.Attributes, summaryAttrPrefix))
+	if err != nil {
+		return "", err
+	}
+	if len(explainStmt.TrainStmt.Features) != 1 {
+		return "", fmt.Errorf("explainer only support 1 feature column set, received %d", len(explainStmt.TrainStmt.Features))
+	}
+	xs, err := fieldmeta.Features(explainStmt.TrainStmt.Features["feature_columns"], true)
+	if err != nil {
+		return "", err
+	}
+	f, fs, err := fieldmeta.ResolveFeatures(xs)
+	if err != nil {
+		return "", err
+	}
+
+	r := explainFiller{
+		DataSource:         session.DbConnStr,
+		DatasetSQL:         explainStmt.Select,
+		Framework:          framework,
+		Explainer:          explainer,
+		FeatureMetaJSON:    string(f),
+		FeatureColumnNames: fs,
+		SummaryParamsJSON:  string(summaryParams),
+		SampleSize:         explainStmt.Attributes["explain.sample_size"].(int),
+		BackgroundSize:     explainStmt.Attributes["explain.background_size"].(int),
+		NRepeats:           explainStmt.Attributes["explain.n_repeats"].(int),
+		RandomState:        explainStmt.Attributes["explain.random_state"].(int),
+		ResultTable:        explainStmt.Into,
+		HDFSNameNodeAddr:   session.HdfsNamenodeAddr,
+		HiveLocation:       session.HiveLocation,
+		HDFSUser:           session.HdfsUser,
+		HDFSPass:           session.HdfsPass,
+	}
+	var program bytes.Buffer
+	if err := explainTemplate.Execute(&program, r); err != nil {
+		return "", err
+	}
+	return program.String(), nil
+}
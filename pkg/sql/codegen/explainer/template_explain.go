@@ -0,0 +1,66 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package explainer
+
+import "text/template"
+
+type explainFiller struct {
+	DataSource         string
+	DatasetSQL         string
+	Framework          string
+	Explainer          string
+	FeatureMetaJSON    string
+	FeatureColumnNames []string
+	SummaryParamsJSON  string
+	SampleSize         int
+	BackgroundSize     int
+	NRepeats           int
+	RandomState        int
+	ResultTable        string
+	HDFSNameNodeAddr   string
+	HiveLocation       string
+	HDFSUser           string
+	HDFSPass           string
+}
+
+const explainTemplateText = `
+import json
+from sqlflow_submitter.generic_explain import explain
+
+feature_metas = json.loads('''{{.FeatureMetaJSON}}''')
+summary_params = json.loads('''{{.SummaryParamsJSON}}''')
+
+feature_column_names = [{{range .FeatureColumnNames}}
+"{{.}}",
+{{end}}]
+
+explain(datasource='''{{.DataSource}}''',
+        select='''{{.DatasetSQL}}''',
+        framework="{{.Framework}}",
+        explainer="{{.Explainer}}",
+        feature_metas=feature_metas,
+        feature_column_names=feature_column_names,
+        summary_params=summary_params,
+        sample_size={{.SampleSize}},
+        background_size={{.BackgroundSize}},
+        n_repeats={{.NRepeats}},
+        random_state={{.RandomState}},
+        result_table='''{{.ResultTable}}''',
+        hdfs_namenode_addr='''{{.HDFSNameNodeAddr}}''',
+        hive_location='''{{.HiveLocation}}''',
+        hdfs_user='''{{.HDFSUser}}''',
+        hdfs_pass='''{{.HDFSPass}}''')
+`
+
+var explainTemplate = template.Must(template.New("Explain").Parse(explainTemplateText))
@@ -0,0 +1,101 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fieldmeta
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"sqlflow.org/sqlflow/pkg/ir"
+	tf "sqlflow.org/sqlflow/pkg/sql/codegen/tensorflow"
+)
+
+// FieldMeta delicates Field Meta with Json format which used in code generator
+type FieldMeta struct {
+	FeatureName string   `json:"feature_name"`
+	DType       string   `json:"dtype"`
+	Delimiter   string   `json:"delimiter"`
+	Shap        []int    `json:"shape"`
+	IsSparse    bool     `json:"is_sparse"`
+	TimeParts   []string `json:"time_parts"`
+	Cyclical    bool     `json:"cyclical"`
+	IsArray     bool     `json:"is_array"`
+	JSONPaths   []string `json:"json_paths"`
+	Tokenizer   string   `json:"tokenizer"`
+	// Vocabulary is only set for the TEXT columns, whose ids are decoded by it
+	Vocabulary map[string]string `json:"vocabulary,omitempty"`
+}
+
+// Resolve returns the FieldMeta of desc.
+func Resolve(desc *ir.FieldDesc) FieldMeta {
+	fm := FieldMeta{
+		FeatureName: desc.Name,
+		DType:       tf.DTypeToString(desc.DType),
+		Delimiter:   desc.Delimiter,
+		Shap:        desc.Shape,
+		IsSparse:    desc.IsSparse,
+		TimeParts:   desc.TimeParts,
+		Cyclical:    desc.Cyclical,
+		IsArray:     desc.IsArray,
+		JSONPaths:   desc.JSONPaths,
+		Tokenizer:   desc.Tokenizer,
+	}
+	if desc.Tokenizer != "" {
+		fm.Vocabulary = desc.Vocabulary
+	}
+	return fm
+}
+
+// ResolveFeatures returns the JSON of the FieldMetas of fds keyed by
+// their names, and the names in the order of fds.
+func ResolveFeatures(fds []ir.FieldDesc) ([]byte, []string, error) {
+	ret := make(map[string]FieldMeta)
+	featureNames := []string{}
+	for _, f := range fds {
+		ret[f.Name] = Resolve(&f)
+		featureNames = append(featureNames, f.Name)
+	}
+	f, e := json.Marshal(ret)
+	return f, featureNames, e
+}
+
+// Features returns the FieldDescs of the feature columns fcs, which
+// must be numeric columns. If dense is true, the sparse columns are
+// rejected.
+func Features(fcs []ir.FeatureColumn, dense bool) ([]ir.FieldDesc, error) {
+	var features []ir.FieldDesc
+	for _, fc := range fcs {
+		switch c := fc.(type) {
+		case *ir.NumericColumn:
+			if dense && c.FieldDesc.IsSparse {
+				return nil, fmt.Errorf("unsupported sparse feature column %s", c.FieldDesc.Name)
+			}
+			features = append(features, *c.FieldDesc)
+		default:
+			return nil, fmt.Errorf("unsupported feature column type %T on %v", c, c)
+		}
+	}
+	return features, nil
+}
+
+// Label returns the FieldDesc of the label column l, which must be a
+// numeric column.
+func Label(l ir.FeatureColumn) (ir.FieldDesc, error) {
+	switch c := l.(type) {
+	case *ir.NumericColumn:
+		return *c.FieldDesc, nil
+	default:
+		return ir.FieldDesc{}, fmt.Errorf("unsupported label column type %T on %v", c, c)
+	}
+}
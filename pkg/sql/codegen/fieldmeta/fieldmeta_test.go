@@ -0,0 +1,56 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fieldmeta
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sqlflow.org/sqlflow/pkg/ir"
+)
+
+func TestResolveFeatures(t *testing.T) {
+	a := assert.New(t)
+	fcs := []ir.FeatureColumn{
+		&ir.NumericColumn{FieldDesc: &ir.FieldDesc{Name: "c1", DType: ir.Float, Shape: []int{1}}},
+		&ir.NumericColumn{FieldDesc: &ir.FieldDesc{Name: "c2", DType: ir.Int, Shape: []int{10}, IsSparse: true}},
+	}
+	fds, e := Features(fcs, false)
+	a.NoError(e)
+	a.Equal(2, len(fds))
+	_, e = Features(fcs, true)
+	a.EqualError(e, "unsupported sparse feature column c2")
+	_, e = Features([]ir.FeatureColumn{&ir.BucketColumn{SourceColumn: fcs[0].(*ir.NumericColumn)}}, false)
+	a.Error(e)
+
+	b, names, e := ResolveFeatures(fds)
+	a.NoError(e)
+	a.Equal([]string{"c1", "c2"}, names)
+	metas := map[string]FieldMeta{}
+	a.NoError(json.Unmarshal(b, &metas))
+	a.Equal("float32", metas["c1"].DType)
+	a.Equal([]int{10}, metas["c2"].Shap)
+	a.True(metas["c2"].IsSparse)
+
+	label, e := Label(fcs[0])
+	a.NoError(e)
+	a.Equal("c1", label.Name)
+	_, e = Label(&ir.BucketColumn{SourceColumn: fcs[0].(*ir.NumericColumn)})
+	a.Error(e)
+
+	// Only the TEXT columns carry their vocabularies.
+	a.Nil(Resolve(&ir.FieldDesc{Name: "c3", Vocabulary: map[string]string{"a": "1"}}).Vocabulary)
+	a.Equal(map[string]string{"a": "1"}, Resolve(&ir.FieldDesc{Name: "c3", Tokenizer: "word", Vocabulary: map[string]string{"a": "1"}}).Vocabulary)
+}
@@ -0,0 +1,224 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forecast
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"sqlflow.org/sqlflow/pkg/ir"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/attribute"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/datacheck"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/evaluation"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/prediction"
+	tf "sqlflow.org/sqlflow/pkg/sql/codegen/tensorflow"
+)
+
+const (
+	lstmForecaster   = "SQLFLOW_MODELS.LSTMFORECASTER"
+	deepARForecaster = "SQLFLOW_MODELS.DEEPARFORECASTER"
+)
+
+var attributeDictionary = attribute.Dictionary{
+	"model.time_column": {attribute.String, nil, `[default=the first column in the COLUMN clause]
+The column to order the rows by, whose values are times like "2020-06-01", or numbers.`, nil},
+	"model.window_size": {attribute.Int, 28, `[default=28]
+Number of the past rows to forecast by.
+range: [1, Infinity]`, attribute.IntLowerBoundChecker(1, true)},
+	"model.horizon": {attribute.Int, 7, `[default=7]
+Number of the future rows to forecast.
+range: [1, Infinity]`, attribute.IntLowerBoundChecker(1, true)},
+	"model.lstm_units": {attribute.Int, 32, `[default=32]
+Number of the units of the LSTM layer.
+range: [1, Infinity]`, attribute.IntLowerBoundChecker(1, true)},
+	"optimizer.learning_rate": {attribute.Float, float32(0.001), `[default=0.001]
+The learning rate of the Adam optimizer.
+range: (0, Infinity]`, attribute.Float32LowerBoundChecker(0, false)},
+	"train.batch_size": {attribute.Int, 32, `[default=32]
+Number of the windows in each iteration.
+range: [1, Infinity]`, attribute.IntLowerBoundChecker(1, true)},
+	"train.epoch": {attribute.Int, 10, `[default=10]
+Number of passes over the windows of the training data.
+range: [1, Infinity]`, attribute.IntLowerBoundChecker(1, true)},
+	"validation.select": {attribute.String, "", `[default=""]
+Specify the dataset for validation.
+example: "SELECT * FROM shop.sales WHERE ts >= '2020-06-01'"`, nil},
+}.Update(evaluation.SplitAttributes).Update(datacheck.Attributes)
+
+// Supports returns if the estimator is a forecasting model.
+func Supports(estimator string) bool {
+	switch strings.ToUpper(estimator) {
+	case lstmForecaster, deepARForecaster:
+		return true
+	}
+	return false
+}
+
+// AttributeDictionary returns the attributes of TRAIN by the forecasting
+// models. The caller shouldn't modify it.
+func AttributeDictionary() attribute.Dictionary {
+	return attributeDictionary
+}
+
+// InitializeAttributes initializes the attributes of the forecasting models
+// and does type checking for them. It sets model.time_column to the first
+// column in the COLUMN clause if it's not set, so it must be called before
+// the feature derivation, which reorders the columns.
+func InitializeAttributes(trainStmt *ir.TrainStmt) error {
+	if !Supports(trainStmt.Estimator) {
+		return fmt.Errorf("unsupported model name %v, currently supports sqlflow_models.LSTMForecaster, sqlflow_models.DeepARForecaster", trainStmt.Estimator)
+	}
+	if _, ok := trainStmt.Attributes["model.time_column"]; !ok {
+		fcs := trainStmt.Features["feature_columns"]
+		if len(fcs) == 0 || len(fcs[0].GetFieldDesc()) == 0 {
+			return fmt.Errorf("%s requires the time column, in model.time_column or the first column in the COLUMN clause", trainStmt.Estimator)
+		}
+		trainStmt.Attributes["model.time_column"] = fcs[0].GetFieldDesc()[0].Name
+	}
+	attributeDictionary.FillDefaults(trainStmt.Attributes)
+	if err := attributeDictionary.Validate(trainStmt.Attributes); err != nil {
+		return err
+	}
+	if trainStmt.Attributes["model.time_column"] == labelName(trainStmt) {
+		return fmt.Errorf("the time column %s can't be the label", labelName(trainStmt))
+	}
+	return nil
+}
+
+func labelName(trainStmt *ir.TrainStmt) string {
+	if trainStmt.Label == nil || len(trainStmt.Label.GetFieldDesc()) == 0 {
+		return ""
+	}
+	return trainStmt.Label.GetFieldDesc()[0].Name
+}
+
+// TimeColumn returns the column that the rows of trainStmt are ordered by.
+func TimeColumn(trainStmt *ir.TrainStmt) string {
+	c, _ := trainStmt.Attributes["model.time_column"].(string)
+	return c
+}
+
+// resolveSeries returns the columns of the series that trainStmt forecasts
+// by, the label first.
+func resolveSeries(trainStmt *ir.TrainStmt) ([]string, error) {
+	label := labelName(trainStmt)
+	if label == "" {
+		return nil, fmt.Errorf("%s requires the label to forecast", trainStmt.Estimator)
+	}
+	series := []string{label}
+	for _, fc := range trainStmt.Features["feature_columns"] {
+		name := fc.GetFieldDesc()[0].Name
+		if name == TimeColumn(trainStmt) || name == label {
+			continue
+		}
+		if _, ok := fc.(*ir.NumericColumn); !ok {
+			return nil, fmt.Errorf("the series to forecast by should be numeric columns, received %T on %s", fc, name)
+		}
+		series = append(series, name)
+	}
+	if strings.ToUpper(trainStmt.Estimator) == deepARForecaster && len(series) > 1 {
+		return nil, fmt.Errorf("%s forecasts the label by its own history, received the columns %s", trainStmt.Estimator, strings.Join(series[1:], ", "))
+	}
+	return series, nil
+}
+
+// resolveParams returns the JSON of the model.* and the train.* attributes
+// of trainStmt, whose keys are without the prefixes.
+func resolveParams(trainStmt *ir.TrainStmt) (string, string, error) {
+	modelParams, trainParams := map[string]interface{}{}, map[string]interface{}{}
+	for k, v := range trainStmt.Attributes {
+		switch {
+		case k == "model.time_column":
+		case strings.HasPrefix(k, "model."):
+			modelParams[k[len("model."):]] = v
+		case k == "optimizer.learning_rate":
+			modelParams["learning_rate"] = v
+		case strings.HasPrefix(k, "train."):
+			trainParams[k[len("train."):]] = v
+		}
+	}
+	mp, err := json.Marshal(modelParams)
+	if err != nil {
+		return "", "", err
+	}
+	tp, err := json.Marshal(trainParams)
+	if err != nil {
+		return "", "", err
+	}
+	return string(mp), string(tp), nil
+}
+
+// Train generates a Python program for training a forecasting model.
+func Train(trainStmt *ir.TrainStmt, session *pb.Session) (string, error) {
+	if tf.IsPAI() {
+		return "", fmt.Errorf("forecasting models are not supported on PAI")
+	}
+	series, err := resolveSeries(trainStmt)
+	if err != nil {
+		return "", err
+	}
+	mp, tp, err := resolveParams(trainStmt)
+	if err != nil {
+		return "", err
+	}
+	r := trainFiller{
+		DataSource:       session.DbConnStr,
+		TrainSelect:      trainStmt.Select,
+		ValidationSelect: trainStmt.ValidationSelect,
+		Estimator:        trainStmt.Estimator,
+		TimeColumn:       TimeColumn(trainStmt),
+		SeriesColumns:    series,
+		ModelParamsJSON:  mp,
+		TrainParamsJSON:  tp,
+	}
+	var program bytes.Buffer
+	if err := trainTemplate.Execute(&program, r); err != nil {
+		return "", err
+	}
+	return program.String(), nil
+}
+
+// Pred generates a Python program for forecasting the model.horizon rows
+// following the result of the SELECT of predStmt.
+func Pred(predStmt *ir.PredictStmt, session *pb.Session) (string, error) {
+	if tf.IsPAI() {
+		return "", fmt.Errorf("forecasting models are not supported on PAI")
+	}
+	series, err := resolveSeries(predStmt.TrainStmt)
+	if err != nil {
+		return "", err
+	}
+	r := predFiller{
+		DataSource:       session.DbConnStr,
+		PredSelect:       predStmt.Select,
+		TimeColumn:       TimeColumn(predStmt.TrainStmt),
+		SeriesColumns:    series,
+		ResultTable:      predStmt.ResultTable,
+		ResultColumn:     predStmt.ResultColumn,
+		HDFSNameNodeAddr: session.HdfsNamenodeAddr,
+		HiveLocation:     session.HiveLocation,
+		HDFSUser:         session.HdfsUser,
+		HDFSPass:         session.HdfsPass,
+		WriteBatchSize:   prediction.BatchSize(predStmt.Attributes),
+		Partition:        prediction.Partition(predStmt.Attributes),
+	}
+	var program bytes.Buffer
+	if err := predTemplate.Execute(&program, r); err != nil {
+		return "", err
+	}
+	return program.String(), nil
+}
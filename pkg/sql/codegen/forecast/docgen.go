@@ -0,0 +1,67 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forecast
+
+import (
+	"bytes"
+	"text/template"
+)
+
+// DocGenInMarkdown generates the doc of the forecasting models in Markdown format.
+func DocGenInMarkdown() string {
+	var doc bytes.Buffer
+	docTemplate.Execute(&doc, attributeDictionary.GenerateTableInHTML())
+
+	return doc.String()
+}
+
+const docTemplateText = `# Forecasting Parameters
+
+## TRAIN
+
+### Example
+
+` + "```SQL" + `
+SELECT ts, sales FROM shop.daily_sales
+TO TRAIN sqlflow_models.LSTMForecaster
+WITH
+    model.window_size = 28,
+    model.horizon = 7
+COLUMN ts, sales
+LABEL sales
+INTO sqlflow_models.my_forecaster;
+` + "```" + `
+
+The models are trained on the sliding windows of the rows ordered by the time column, which is the first column in the COLUMN clause, or model.time_column. The other columns and the label are the series to forecast the label by. sqlflow_models.DeepARForecaster forecasts the label by its own history only.
+
+### Model Types
+
+` + "`sqlflow_models.LSTMForecaster`, `sqlflow_models.DeepARForecaster`" + `
+
+### Parameters
+
+{{.}}
+
+## PREDICT
+
+` + "```SQL" + `
+SELECT ts, sales FROM shop.daily_sales
+TO PREDICT shop.sales_forecast.sales
+USING sqlflow_models.my_forecaster;
+` + "```" + `
+
+The result table has the time column and the forecasted label of the model.horizon rows following the last model.window_size rows of the SELECT, whose times are by the interval of the last two rows.
+`
+
+var docTemplate = template.Must(template.New("Doc").Parse(docTemplateText))
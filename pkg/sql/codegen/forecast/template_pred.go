@@ -0,0 +1,54 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forecast
+
+import "text/template"
+
+type predFiller struct {
+	DataSource       string
+	PredSelect       string
+	TimeColumn       string
+	SeriesColumns    []string
+	ResultTable      string
+	ResultColumn     string
+	HDFSNameNodeAddr string
+	HiveLocation     string
+	HDFSUser         string
+	HDFSPass         string
+	WriteBatchSize   int
+	Partition        string
+}
+
+const predTemplateText = `
+from sqlflow_submitter.forecast.predict import pred
+
+series_columns = [{{range .SeriesColumns}}
+"{{.}}",
+{{end}}]
+
+pred(datasource='''{{.DataSource}}''',
+     select='''{{.PredSelect}}''',
+     time_column="{{.TimeColumn}}",
+     series_columns=series_columns,
+     result_table='''{{.ResultTable}}''',
+     result_column="{{.ResultColumn}}",
+     hdfs_namenode_addr='''{{.HDFSNameNodeAddr}}''',
+     hive_location='''{{.HiveLocation}}''',
+     hdfs_user='''{{.HDFSUser}}''',
+     hdfs_pass='''{{.HDFSPass}}''',
+     write_batch_size={{.WriteBatchSize}},
+     partition="{{.Partition}}")
+`
+
+var predTemplate = template.Must(template.New("Pred").Parse(predTemplateText))
@@ -0,0 +1,50 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forecast
+
+import "text/template"
+
+type trainFiller struct {
+	DataSource       string
+	TrainSelect      string
+	ValidationSelect string
+	Estimator        string
+	TimeColumn       string
+	SeriesColumns    []string
+	ModelParamsJSON  string
+	TrainParamsJSON  string
+}
+
+const trainTemplateText = `
+import json
+from sqlflow_submitter.forecast.train import train
+
+model_params = json.loads('''{{.ModelParamsJSON}}''')
+train_params = json.loads('''{{.TrainParamsJSON}}''')
+
+series_columns = [{{range .SeriesColumns}}
+"{{.}}",
+{{end}}]
+
+train(datasource='''{{.DataSource}}''',
+      select='''{{.TrainSelect}}''',
+      validation_select='''{{.ValidationSelect}}''',
+      estimator="{{.Estimator}}",
+      time_column="{{.TimeColumn}}",
+      series_columns=series_columns,
+      model_params=model_params,
+      train_params=train_params)
+`
+
+var trainTemplate = template.Must(template.New("Train").Parse(trainTemplateText))
@@ -0,0 +1,166 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grouping
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sqlflow.org/sqlflow/pkg/database"
+	"sqlflow.org/sqlflow/pkg/ir"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/attribute"
+)
+
+// MaxGroups is the most groups a statement trains the models of.
+const MaxGroups = 100
+
+var columnRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// groupByRegex matches the attribute train.group_by in the WITH clause,
+// before which StepSQL and IndexSQL insert the attributes of the steps.
+var groupByRegex = regexp.MustCompile(`train\.group_by\s*=`)
+
+// Attributes are the attributes of TRAIN training a model per group.
+var Attributes = attribute.Dictionary{
+	"train.group_by": {attribute.String, "", `[default=""]
+The column to train a model per distinct value of, like "region". The model of the group region=cn is saved as my_model/region=cn, and PREDICT USING my_model predicts each row by the model of its group. Not set means training one model.`, func(i interface{}) error {
+		if s, _ := i.(string); s != "" && !columnRegex.MatchString(s) {
+			return fmt.Errorf("train.group_by should be a column name, got %s", s)
+		}
+		return nil
+	}},
+	"train.group": {attribute.String, "", `[default=""]
+The only group of train.group_by to train the model of, which the workflow sets for the step of each group. Not set means all the groups.`, func(i interface{}) error {
+		s, _ := i.(string)
+		return CheckValue(s)
+	}},
+	"train.group_index": {attribute.Bool, false, `[default=false]
+Whether to save the index of the models of the groups without training them, which the workflow sets for the step after the steps of the groups.`, nil},
+}
+
+// Parse removes the attributes of Attributes from attrs and returns the
+// grouping they describe.
+func Parse(attrs map[string]interface{}) (ir.Grouping, error) {
+	grouping := map[string]interface{}{}
+	for k := range Attributes {
+		if v, ok := attrs[k]; ok {
+			grouping[k] = v
+			delete(attrs, k)
+		}
+	}
+	if e := Attributes.Validate(grouping); e != nil {
+		return ir.Grouping{}, e
+	}
+	Attributes.FillDefaults(grouping)
+	g := ir.Grouping{
+		Column:    grouping["train.group_by"].(string),
+		Value:     grouping["train.group"].(string),
+		IndexOnly: grouping["train.group_index"].(bool),
+	}
+	if g.Column == "" && (g.Value != "" || g.IndexOnly) {
+		return ir.Grouping{}, fmt.Errorf("train.group and train.group_index require train.group_by")
+	}
+	if g.Value != "" && g.IndexOnly {
+		return ir.Grouping{}, fmt.Errorf("train.group doesn't work with train.group_index")
+	}
+	return g, nil
+}
+
+// CheckValue returns an error if the group value can't be written in the
+// attribute train.group, which has no escaping of the quotes.
+func CheckValue(value string) error {
+	if strings.ContainsAny(value, "\"'\\") {
+		return fmt.Errorf("the group %s with quotes or backslashes is not supported", value)
+	}
+	return nil
+}
+
+// ModelName returns the name of the model of the group column=value of
+// the model name, like my_model/region=cn.
+func ModelName(name, column, value string) string {
+	return fmt.Sprintf("%s/%s=%s", name, column, value)
+}
+
+// Select returns the SELECT of the rows of the group column=value in the
+// rows of slct.
+func Select(slct, column, value string) string {
+	return fmt.Sprintf("SELECT * FROM (%s) AS sqlflow_group WHERE %s = '%s'", trimSelect(slct), column, value)
+}
+
+// ValuesQuery returns the SELECT of the groups of column in the rows of
+// slct, at most MaxGroups+1 of them so that too many groups are told. The
+// rows with NULL in column are in no group.
+func ValuesQuery(slct, column string) string {
+	return fmt.Sprintf("SELECT DISTINCT %[2]s FROM (%[1]s) AS sqlflow_groups WHERE %[2]s IS NOT NULL ORDER BY %[2]s LIMIT %[3]d", trimSelect(slct), column, MaxGroups+1)
+}
+
+// Values returns the groups of column in the rows of slct in order.
+func Values(db *database.DB, slct, column string) ([]string, error) {
+	rows, e := db.Query(ValuesQuery(slct, column))
+	if e != nil {
+		return nil, fmt.Errorf("query the groups of %s failed: %v", column, e)
+	}
+	defer rows.Close()
+	values := []string{}
+	for rows.Next() {
+		var v string
+		if e := rows.Scan(&v); e != nil {
+			return nil, e
+		}
+		if e := CheckValue(v); e != nil {
+			return nil, e
+		}
+		values = append(values, v)
+	}
+	if e := rows.Err(); e != nil {
+		return nil, e
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("no groups of %s in the SELECT", column)
+	}
+	if len(values) > MaxGroups {
+		return nil, fmt.Errorf("the SELECT has more than %d groups of %s", MaxGroups, column)
+	}
+	return values, nil
+}
+
+func trimSelect(slct string) string {
+	return strings.TrimRight(strings.TrimSpace(slct), ";")
+}
+
+// StepSQL returns the statement sql training with train.group_by, which
+// trains the model of the group value only.
+func StepSQL(sql, value string) (string, error) {
+	if e := CheckValue(value); e != nil {
+		return "", e
+	}
+	return insertAttribute(sql, fmt.Sprintf(`train.group = "%s"`, value))
+}
+
+// IndexSQL returns the statement sql training with train.group_by, which
+// saves the index of the models of the groups trained by StepSQL.
+func IndexSQL(sql string) (string, error) {
+	return insertAttribute(sql, "train.group_index = true")
+}
+
+func insertAttribute(sql, attr string) (string, error) {
+	loc := groupByRegex.FindAllStringIndex(sql, -1)
+	if len(loc) == 0 {
+		return "", fmt.Errorf("no train.group_by in %s", sql)
+	}
+	// The WITH clause follows the SELECT, which may have the same text.
+	i := loc[len(loc)-1][0]
+	return sql[:i] + attr + ", " + sql[i:], nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grouping
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sqlflow.org/sqlflow/pkg/ir"
+)
+
+func TestParse(t *testing.T) {
+	a := assert.New(t)
+	attrs := map[string]interface{}{"train.group_by": "region", "train.epoch": 10}
+	g, e := Parse(attrs)
+	a.NoError(e)
+	a.Equal(ir.Grouping{Column: "region"}, g)
+	a.Equal(map[string]interface{}{"train.epoch": 10}, attrs)
+
+	g, e = Parse(map[string]interface{}{"train.group_by": "region", "train.group": "cn"})
+	a.NoError(e)
+	a.Equal(ir.Grouping{Column: "region", Value: "cn"}, g)
+	g, e = Parse(map[string]interface{}{"train.group_by": "region", "train.group_index": true})
+	a.NoError(e)
+	a.Equal(ir.Grouping{Column: "region", IndexOnly: true}, g)
+	g, e = Parse(map[string]interface{}{"train.epoch": 10})
+	a.NoError(e)
+	a.Equal(ir.Grouping{}, g)
+
+	_, e = Parse(map[string]interface{}{"train.group_by": "region; DROP TABLE t"})
+	a.Error(e)
+	_, e = Parse(map[string]interface{}{"train.group": "cn"})
+	a.Error(e)
+	_, e = Parse(map[string]interface{}{"train.group_by": "region", "train.group": "cn", "train.group_index": true})
+	a.Error(e)
+	_, e = Parse(map[string]interface{}{"train.group_by": "region", "train.group": `c"n`})
+	a.Error(e)
+}
+
+func TestQueries(t *testing.T) {
+	a := assert.New(t)
+	a.Equal("my_model/region=cn", ModelName("my_model", "region", "cn"))
+	a.Equal("SELECT * FROM (SELECT * FROM t) AS sqlflow_group WHERE region = 'cn'", Select("SELECT * FROM t;\n", "region", "cn"))
+	a.Equal("SELECT DISTINCT region FROM (SELECT * FROM t) AS sqlflow_groups WHERE region IS NOT NULL ORDER BY region LIMIT 101", ValuesQuery("SELECT * FROM t", "region"))
+}
+
+func TestStepSQL(t *testing.T) {
+	a := assert.New(t)
+	sql := `SELECT * FROM t WHERE note = 'train.group_by = 1' TO TRAIN DNNClassifier WITH model.n_classes = 3, train.group_by = "region" LABEL class INTO my_model;`
+	s, e := StepSQL(sql, "cn")
+	a.NoError(e)
+	a.Equal(`SELECT * FROM t WHERE note = 'train.group_by = 1' TO TRAIN DNNClassifier WITH model.n_classes = 3, train.group = "cn", train.group_by = "region" LABEL class INTO my_model;`, s)
+	s, e = IndexSQL(sql)
+	a.NoError(e)
+	a.Equal(`SELECT * FROM t WHERE note = 'train.group_by = 1' TO TRAIN DNNClassifier WITH model.n_classes = 3, train.group_index = true, train.group_by = "region" LABEL class INTO my_model;`, s)
+
+	_, e = StepSQL(sql, "c'n")
+	a.Error(e)
+	_, e = IndexSQL("SELECT * FROM t TO TRAIN DNNClassifier LABEL class INTO my_model;")
+	a.Error(e)
+}
@@ -0,0 +1,80 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imbalance
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"sqlflow.org/sqlflow/pkg/sql/codegen/attribute"
+)
+
+// Attributes are the attributes of TRAIN to handle the imbalanced labels
+// of the classifiers, like the fraud labels of which only a few are
+// positive, for the code generators that support them. See
+// python/sqlflow_submitter/imbalance.py.
+var Attributes = attribute.Dictionary{
+	"train.class_weight": {attribute.String, "", `[default=""]
+The weights of the classes in the loss. "balanced" weights each class by n_samples / (n_classes * n_samples_of_the_class) of the training data, or specify the weights of the labels like "0:1,1:50", the labels not specified have the weight 1. The labels should be non-negative integers.
+example: "balanced"`, attribute.ClassWeightChecker},
+	"train.oversample_minority": {attribute.Bool, false, `[default=false]
+Repeat the rows of the minority classes in the training data, so each class has about as many rows as the majority class. The labels should be non-negative integers.`, nil},
+}
+
+// ClassWeight returns the attribute train.class_weight in attrs as a
+// Python value, which is None if not set, "balanced", or a dict like
+// {0: 1, 1: 50}.
+func ClassWeight(attrs map[string]interface{}) string {
+	s, _ := attrs["train.class_weight"].(string)
+	if s == "" {
+		return "None"
+	}
+	if s == attribute.ClassWeightBalanced {
+		return fmt.Sprintf("%q", s)
+	}
+	// Check has validated the attribute.
+	weights, _ := attribute.ParseClassWeight(s)
+	labels := []int{}
+	for label := range weights {
+		labels = append(labels, label)
+	}
+	sort.Ints(labels)
+	pairs := []string{}
+	for _, label := range labels {
+		pairs = append(pairs, fmt.Sprintf("%d: %v", label, weights[label]))
+	}
+	return fmt.Sprintf("{%s}", strings.Join(pairs, ", "))
+}
+
+// Oversample returns true if attrs oversample the minority classes.
+func Oversample(attrs map[string]interface{}) bool {
+	b, _ := attrs["train.oversample_minority"].(bool)
+	return b
+}
+
+// IsSet returns true if attrs weight or oversample the classes.
+func IsSet(attrs map[string]interface{}) bool {
+	s, _ := attrs["train.class_weight"].(string)
+	return s != "" || Oversample(attrs)
+}
+
+// Check returns an error if attrs both weight and oversample the classes,
+// which would correct the imbalance twice.
+func Check(attrs map[string]interface{}) error {
+	if s, _ := attrs["train.class_weight"].(string); s != "" && Oversample(attrs) {
+		return fmt.Errorf("train.class_weight doesn't work with train.oversample_minority")
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imbalance
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttributes(t *testing.T) {
+	a := assert.New(t)
+	attrs := map[string]interface{}{}
+	Attributes.FillDefaults(attrs)
+	a.NoError(Attributes.Validate(attrs))
+	a.False(IsSet(attrs))
+	a.Equal("None", ClassWeight(attrs))
+	a.NoError(Check(attrs))
+
+	attrs["train.class_weight"] = "balanced"
+	a.NoError(Attributes.Validate(attrs))
+	a.True(IsSet(attrs))
+	a.Equal(`"balanced"`, ClassWeight(attrs))
+
+	attrs["train.class_weight"] = "1:50,0:1"
+	a.NoError(Attributes.Validate(attrs))
+	a.Equal("{0: 1, 1: 50}", ClassWeight(attrs))
+
+	attrs["train.oversample_minority"] = true
+	a.Error(Check(attrs))
+	attrs["train.class_weight"] = ""
+	a.NoError(Check(attrs))
+	a.True(IsSet(attrs))
+	a.True(Oversample(attrs))
+
+	a.Error(Attributes.Validate(map[string]interface{}{"train.class_weight": "0:1,1"}))
+}
@@ -0,0 +1,326 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lightgbm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"sqlflow.org/sqlflow/pkg/ir"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/attribute"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/datacheck"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/evaluation"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/fieldmeta"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/prediction"
+	tf "sqlflow.org/sqlflow/pkg/sql/codegen/tensorflow"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/tuning"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/warmstart"
+)
+
+// The parameters are named as in LightGBM, see
+// https://lightgbm.readthedocs.io/en/latest/Parameters.html
+var attributeDictionary = attribute.Dictionary{
+	"objective": {attribute.String, nil, `[default="binary" for LGBMClassifier, "multiclass" if num_class > 2, "regression" for LGBMRegressor]
+Learning objective.
+possible values: "regression", "regression_l1", "huber", "poisson", "quantile", "binary", "multiclass", "multiclassova", "lambdarank"`, attribute.StringChoicesChecker("regression", "regression_l1", "huber", "poisson", "quantile", "binary", "multiclass", "multiclassova", "lambdarank")},
+	"boosting": {attribute.String, nil, `[default="gbdt"]
+possible values: "gbdt", "rf", "dart", "goss"`, attribute.StringChoicesChecker("gbdt", "rf", "dart", "goss")},
+	"num_class": {attribute.Int, nil, `Number of classes, only for multiclass objectives.
+range: [2, Infinity]`, attribute.IntLowerBoundChecker(2, true)},
+	"learning_rate": {attribute.Float, nil, `[default=0.1]
+Shrinkage rate.
+range: (0, Infinity]`, attribute.Float32LowerBoundChecker(0, false)},
+	"num_leaves": {attribute.Int, nil, `[default=31]
+Max number of leaves in one tree.
+range: [2, 131072]`, attribute.IntRangeChecker(2, 131072, true, true)},
+	"max_depth": {attribute.Int, nil, `[default=-1]
+Limit the max depth of the trees, <= 0 means no limit.`, nil},
+	"min_data_in_leaf": {attribute.Int, nil, `[default=20]
+Minimal number of data in one leaf.
+range: [0, Infinity]`, attribute.IntLowerBoundChecker(0, true)},
+	"feature_fraction": {attribute.Float, nil, `[default=1.0]
+The ratio of features selected on each iteration.
+range: (0, 1]`, attribute.Float32RangeChecker(0, 1, false, true)},
+	"bagging_fraction": {attribute.Float, nil, `[default=1.0]
+The ratio of data selected without resampling on each iteration.
+range: (0, 1]`, attribute.Float32RangeChecker(0, 1, false, true)},
+	"bagging_freq": {attribute.Int, nil, `[default=0]
+Frequency for bagging, 0 means disable bagging.
+range: [0, Infinity]`, attribute.IntLowerBoundChecker(0, true)},
+	"lambda_l1": {attribute.Float, nil, `[default=0]
+L1 regularization.
+range: [0, Infinity]`, attribute.Float32LowerBoundChecker(0, true)},
+	"lambda_l2": {attribute.Float, nil, `[default=0]
+L2 regularization.
+range: [0, Infinity]`, attribute.Float32LowerBoundChecker(0, true)},
+	"metric": {attribute.String, nil, `Metrics to be evaluated on the validation data, separated by commas, like "auc,binary_logloss".`, nil},
+	"seed":   {attribute.Int, nil, `Seed of the random number generators.`, nil},
+	"verbosity": {attribute.Int, nil, `[default=1]
+Controls the level of LightGBM's verbosity.`, nil},
+	"train.num_boost_round": {attribute.Int, 100, `[default=100]
+Number of boosting iterations.
+range: [1, Infinity]`, attribute.IntLowerBoundChecker(1, true)},
+	"train.early_stopping_rounds": {attribute.Int, 0, `[default=0]
+Stop the training if the validation metrics don't improve in these rounds, 0 means no early stopping.
+It requires validation.select.
+range: [0, Infinity]`, attribute.IntLowerBoundChecker(0, true)},
+	"validation.select": {attribute.String, "", `[default=""]
+Specify the dataset for validation.
+example: "SELECT * FROM iris.test"`, nil},
+}.Update(evaluation.CrossValidationAttributes).Update(evaluation.SplitAttributes).Update(datacheck.Attributes).Update(tuning.Attributes).Update(warmstart.Attributes)
+
+func isClassifier(estimator string) (bool, error) {
+	switch strings.ToUpper(estimator) {
+	case "LIGHTGBM.LGBMCLASSIFIER":
+		return true, nil
+	case "LIGHTGBM.LGBMREGRESSOR":
+		return false, nil
+	}
+	return false, fmt.Errorf("unsupported model name %v, currently supports lightgbm.LGBMClassifier, lightgbm.LGBMRegressor", estimator)
+}
+
+func resolveModelParams(trainStmt *ir.TrainStmt) error {
+	classifier, err := isClassifier(trainStmt.Estimator)
+	if err != nil {
+		return err
+	}
+	if _, ok := trainStmt.Attributes["objective"]; ok {
+		return nil
+	}
+	if !classifier {
+		trainStmt.Attributes["objective"] = "regression"
+	} else if n, ok := trainStmt.Attributes["num_class"].(int); ok && n > 2 {
+		trainStmt.Attributes["objective"] = "multiclass"
+	} else {
+		trainStmt.Attributes["objective"] = "binary"
+	}
+	return nil
+}
+
+// AttributeDictionary returns the attributes of TRAIN by the LightGBM
+// estimators. The caller shouldn't modify it.
+func AttributeDictionary() attribute.Dictionary {
+	return attributeDictionary
+}
+
+// InitializeAttributes initializes the attributes of LightGBM and does type checking for them
+func InitializeAttributes(trainStmt *ir.TrainStmt) error {
+	if err := resolveModelParams(trainStmt); err != nil {
+		return err
+	}
+	attributeDictionary.FillDefaults(trainStmt.Attributes)
+	space, err := tuning.SplitSearchSpace(attributeDictionary, trainStmt.Attributes)
+	if err != nil {
+		return err
+	}
+	trainStmt.SearchSpace = space
+	if err := attributeDictionary.Validate(trainStmt.Attributes); err != nil {
+		return err
+	}
+	if strings.HasPrefix(trainStmt.Attributes["objective"].(string), "multiclass") {
+		if _, ok := trainStmt.Attributes["num_class"]; !ok {
+			return fmt.Errorf("num_class is required by the objective %s", trainStmt.Attributes["objective"])
+		}
+	}
+	if trainStmt.Attributes["train.early_stopping_rounds"].(int) > 0 && trainStmt.ValidationSelect == "" {
+		return fmt.Errorf("train.early_stopping_rounds requires validation.select")
+	}
+	if err := warmstart.Check(trainStmt.Attributes); err != nil {
+		return err
+	}
+	return tuning.CheckValidation(space, trainStmt.ValidationSelect, trainStmt.Attributes["validation.kfold"].(int))
+}
+
+// resolveMetas returns the JSON of the feature metas, the feature names,
+// and the JSON of the label meta of trainStmt.
+func resolveMetas(trainStmt *ir.TrainStmt) (string, []string, string, error) {
+	if len(trainStmt.Features) != 1 {
+		return "", nil, "", fmt.Errorf("lightgbm only support 1 feature column set, received %d", len(trainStmt.Features))
+	}
+	featureFieldDesc, err := fieldmeta.Features(trainStmt.Features["feature_columns"], false)
+	if err != nil {
+		return "", nil, "", err
+	}
+	labelFieldDesc, err := fieldmeta.Label(trainStmt.Label)
+	if err != nil {
+		return "", nil, "", err
+	}
+	f, featureNames, err := fieldmeta.ResolveFeatures(featureFieldDesc)
+	if err != nil {
+		return "", nil, "", err
+	}
+	l, err := json.Marshal(fieldmeta.Resolve(&labelFieldDesc))
+	if err != nil {
+		return "", nil, "", err
+	}
+	return string(f), featureNames, string(l), nil
+}
+
+// defaultMetric returns the default metric of the cross-validation and
+// the tuning of the estimator.
+func defaultMetric(estimator string) (string, error) {
+	classifier, err := isClassifier(estimator)
+	if err != nil {
+		return "", err
+	}
+	if classifier {
+		return "accuracy_score", nil
+	}
+	return "RMSE", nil
+}
+
+// resolveValidation returns the JSON of the parameters of the
+// cross-validation and the tuning of trainStmt.
+func resolveValidation(trainStmt *ir.TrainStmt) (string, string, error) {
+	metric, err := defaultMetric(trainStmt.Estimator)
+	if err != nil {
+		return "", "", err
+	}
+	cv, err := evaluation.ResolveCrossValidation(trainStmt.Attributes, metric).JSON()
+	if err != nil {
+		return "", "", err
+	}
+	groups := map[string]string{"": "model_params", "train.": "train_params"}
+	params, err := tuning.Resolve(trainStmt.Attributes, trainStmt.SearchSpace, groups, metric)
+	if err != nil {
+		return "", "", err
+	}
+	tp, err := params.JSON()
+	if err != nil {
+		return "", "", err
+	}
+	return cv, tp, nil
+}
+
+// Train generates a Python program for train a LightGBM model.
+func Train(trainStmt *ir.TrainStmt, session *pb.Session) (string, error) {
+	if tf.IsPAI() {
+		return "", fmt.Errorf("lightgbm models are not supported on PAI")
+	}
+	if err := resolveModelParams(trainStmt); err != nil {
+		return "", err
+	}
+	f, fs, l, err := resolveMetas(trainStmt)
+	if err != nil {
+		return "", err
+	}
+	params := attribute.Group(trainStmt.Attributes, "", "train.")
+	// train.warm_start is for SQLFlow to load the model instead of LightGBM.
+	delete(params["train."], "warm_start")
+	mp, err := json.Marshal(params[""])
+	if err != nil {
+		return "", err
+	}
+	tp, err := json.Marshal(params["train."])
+	if err != nil {
+		return "", err
+	}
+	cv, tuningParams, err := resolveValidation(trainStmt)
+	if err != nil {
+		return "", err
+	}
+	r := trainFiller{
+		DataSource:          session.DbConnStr,
+		TrainSelect:         trainStmt.Select,
+		ValidationSelect:    trainStmt.ValidationSelect,
+		Estimator:           trainStmt.Estimator,
+		ModelParamsJSON:     string(mp),
+		TrainParamsJSON:     string(tp),
+		CrossValidationJSON: cv,
+		TuningJSON:          tuningParams,
+		FieldDescJSON:       f,
+		FeatureColumnNames:  fs,
+		LabelJSON:           l,
+		WarmStart:           warmstart.Model(trainStmt.Attributes) != "",
+	}
+	var program bytes.Buffer
+	if err := trainTemplate.Execute(&program, r); err != nil {
+		return "", err
+	}
+	return program.String(), nil
+}
+
+// Pred generates a Python program for predict a LightGBM model.
+func Pred(predStmt *ir.PredictStmt, session *pb.Session) (string, error) {
+	if tf.IsPAI() {
+		return "", fmt.Errorf("lightgbm models are not supported on PAI")
+	}
+	f, fs, l, err := resolveMetas(predStmt.TrainStmt)
+	if err != nil {
+		return "", err
+	}
+	r := predFiller{
+		DataSource:         session.DbConnStr,
+		PredSelect:         predStmt.Select,
+		FeatureMetaJSON:    f,
+		FeatureColumnNames: fs,
+		LabelMetaJSON:      l,
+		ResultTable:        predStmt.ResultTable,
+		HDFSNameNodeAddr:   session.HdfsNamenodeAddr,
+		HiveLocation:       session.HiveLocation,
+		HDFSUser:           session.HdfsUser,
+		HDFSPass:           session.HdfsPass,
+		WriteBatchSize:     prediction.BatchSize(predStmt.Attributes),
+		NumWriters:         prediction.NumWriters(predStmt.Attributes),
+		Partition:          prediction.Partition(predStmt.Attributes),
+	}
+	var program bytes.Buffer
+	if err := predTemplate.Execute(&program, r); err != nil {
+		return "", err
+	}
+	return program.String(), nil
+}
+
+// Evaluate generates a Python program for evaluating a LightGBM model.
+func Evaluate(evalStmt *ir.EvaluateStmt, session *pb.Session) (string, error) {
+	if tf.IsPAI() {
+		return "", fmt.Errorf("lightgbm models are not supported on PAI")
+	}
+	f, fs, l, err := resolveMetas(evalStmt.TrainStmt)
+	if err != nil {
+		return "", err
+	}
+	// NOTE: support the metrics in https://scikit-learn.org/stable/modules/classes.html#sklearn-metrics-metrics
+	// and the ones in sqlflow_submitter/evaluation.py.
+	params, err := evaluation.Resolve(evalStmt, "accuracy_score")
+	if err != nil {
+		return "", err
+	}
+	evalParams, err := params.JSON()
+	if err != nil {
+		return "", err
+	}
+	r := evalFiller{
+		DataSource:         session.DbConnStr,
+		PredSelect:         evalStmt.Select,
+		FeatureMetaJSON:    f,
+		FeatureColumnNames: fs,
+		LabelMetaJSON:      l,
+		MetricNames:        params.MetricNames(),
+		EvalParamsJSON:     evalParams,
+		ResultTable:        evalStmt.Into,
+		HDFSNameNodeAddr:   session.HdfsNamenodeAddr,
+		HiveLocation:       session.HiveLocation,
+		HDFSUser:           session.HdfsUser,
+		HDFSPass:           session.HdfsPass,
+	}
+	var program bytes.Buffer
+	if err := evalTemplate.Execute(&program, r); err != nil {
+		return "", err
+	}
+	return program.String(), nil
+}
@@ -13,7 +13,12 @@
 
 package pai
 
-import "fmt"
+import (
+	"fmt"
+
+	"sqlflow.org/sqlflow/pkg/ir"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/resource"
+)
 
 // PSConfig implicates Parameter Server Config
 type PSConfig struct {
@@ -27,6 +32,8 @@ type WorkerConfig struct {
 	Count int `json:"count"`
 	GPU   int `json:"gpu"`
 	CPU   int `json:"cpu"`
+	// Memory is in MB, 0 means the default of PAI.
+	Memory int `json:"memory,omitempty"`
 }
 
 // ClusterConfig implicates PAI distributed task meta
@@ -90,3 +97,23 @@ func GetClusterConfig(attrs map[string]interface{}) (*ClusterConfig, error) {
 	}
 	return cc, nil
 }
+
+// requestResources sets the resources of each worker to the ones requested
+// by the "resources.*" attributes, which override "train.worker_cpu" and
+// "train.worker_gpu". Note that PAI counts the CPU in 1/100 cores.
+func (cc *ClusterConfig) requestResources(r ir.Resources) error {
+	if r.CPU > 0 {
+		cc.Worker.CPU = r.CPU * 100
+	}
+	if r.GPU > 0 {
+		cc.Worker.GPU = r.GPU
+	}
+	if r.Memory != "" {
+		n, e := resource.MemoryBytes(r.Memory)
+		if e != nil {
+			return e
+		}
+		cc.Worker.Memory = int(n >> 20)
+	}
+	return nil
+}
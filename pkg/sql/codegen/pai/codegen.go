@@ -109,6 +109,9 @@ func Train(ir *ir.TrainStmt, session *pb.Session, tarball, paramsFile, modelName
 	if e != nil {
 		return "", "", "", e
 	}
+	if e = cc.requestResources(ir.Resources); e != nil {
+		return "", "", "", e
+	}
 	currProject, e := database.GetDatabaseName(session.DbConnStr)
 	if e != nil {
 		return "", "", "", e
@@ -183,6 +186,9 @@ func Predict(ir *ir.PredictStmt, session *pb.Session, tarball, paramsFile, model
 		if err != nil {
 			return
 		}
+		if e = cc.requestResources(ir.Resources); e != nil {
+			return
+		}
 		// NOTE(typhoonzero): submit a PAI TF job to install xgboost and run.
 		if paiCmd, e = getTFPAICmd(cc, tarball, paramsFile, modelName, ossModelPath, ir.TmpPredictTable, "", ir.ResultTable, currProject, cwd); e != nil {
 			return
@@ -193,6 +199,9 @@ func Predict(ir *ir.PredictStmt, session *pb.Session, tarball, paramsFile, model
 		if err != nil {
 			return
 		}
+		if e = cc.requestResources(ir.Resources); e != nil {
+			return
+		}
 		if code, e = TFLoadAndPredict(ir, session, ossModelPath); e != nil {
 			return
 		}
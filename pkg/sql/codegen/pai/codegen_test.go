@@ -56,6 +56,8 @@ var knownPredictParams = append(
 		"hive_location",
 		"hdfs_user",
 		"hdfs_pass",
+		"write_batch_size",
+		"num_writers",
 	},
 	knownTrainParams...,
 )
@@ -159,4 +161,20 @@ func TestPredictCodegen(t *testing.T) {
 	a.False(hasUnknownParameters(tfCode, knownPredictParams))
 	expectedPAICmd := fmt.Sprintf("pai -name tensorflow1150 -project algo_public_dev -DmaxHungTimeBeforeGCInSeconds=0 -DjobName=sqlflow_my_dnn_model -Dtags=dnn -Dscript=%s -DentryFile=entry.py -Dtables=odps://iris/tables/predict -Doutputs=odps://iris/tables/predict -DhyperParameters=\"%s\" -DcheckpointDir='oss://sqlflow-models/iris/sqlflow/my_dnn_model/?role_arn=xxx&host=xxx' -DgpuRequired='0'", scriptPath, paramsPath)
 	a.Equal(expectedPAICmd, paiCmd)
+
+	ir.Resources.GPU = 1
+	_, paiCmd, _, e = Predict(ir, sess, scriptPath, paramsPath, "my_dnn_model", ossModelPath, "", ModelTypeTF)
+	a.NoError(e)
+	a.True(strings.HasSuffix(paiCmd, "-DgpuRequired='1'"))
+}
+
+func TestRequestResources(t *testing.T) {
+	a := assert.New(t)
+	cc, e := GetClusterConfig(map[string]interface{}{"train.worker_cpu": 800})
+	a.NoError(e)
+	a.NoError(cc.requestResources(ir.Resources{}))
+	a.Equal(WorkerConfig{Count: 1, CPU: 800}, cc.Worker)
+
+	a.NoError(cc.requestResources(ir.Resources{CPU: 4, GPU: 1, Memory: "8Gi"}))
+	a.Equal(WorkerConfig{Count: 1, CPU: 400, GPU: 1, Memory: 8192}, cc.Worker)
 }
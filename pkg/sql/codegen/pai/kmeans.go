@@ -36,6 +36,12 @@ all the columns of input table.`, nil},
 excluded the special feature columns from the SELECT statement.`, nil},
 }
 
+// KMeansAttributeDictionary returns the attributes of TRAIN by the PAI
+// KMeans estimator. The caller shouldn't modify it.
+func KMeansAttributeDictionary() attribute.Dictionary {
+	return kmeansAttributes
+}
+
 // InitializeKMeansAttributes initializes the attributes of KMeans and does type checking for them
 func InitializeKMeansAttributes(trainStmt *ir.TrainStmt) error {
 	kmeansAttributes.FillDefaults(trainStmt.Attributes)
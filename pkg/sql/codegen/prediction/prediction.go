@@ -0,0 +1,146 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prediction
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sqlflow.org/sqlflow/pkg/sql/codegen/attribute"
+)
+
+// Prefix is the prefix of the attributes of PREDICT.
+const Prefix = "predict."
+
+// The values of predict.write_mode.
+const (
+	// Overwrite drops and recreates the result table, or the partition.
+	Overwrite = "overwrite"
+	// Append creates the result table if it doesn't exist and appends
+	// the rows.
+	Append = "append"
+	// ErrorIfExists fails if the result table, or the partition, exists.
+	ErrorIfExists = "error"
+)
+
+// The values of predict.unseen_categories.
+const (
+	// IgnoreUnseen predicts the rows with the unseen categories as if
+	// the categorical columns were missing.
+	IgnoreUnseen = "ignore"
+	// ErrorOnUnseen fails if the SELECT has any unseen categories.
+	ErrorOnUnseen = "error"
+)
+
+var rePartition = regexp.MustCompile(`^\w+=[\w.-]+(,\w+=[\w.-]+)*$`)
+
+// Attributes are the attributes of PREDICT of all the code generators,
+// which configure how the prediction result is written to the result
+// table, see python/sqlflow_submitter/db_writer/parallel.py.
+var Attributes = attribute.Dictionary{
+	"predict.batch_size": {attribute.Int, 100, `[default=100]
+The number of rows of the prediction result in each INSERT to the result table.
+range: [1, Infinity]`, attribute.IntLowerBoundChecker(1, true)},
+	"predict.num_writers": {attribute.Int, 1, `[default=1]
+The number of writers inserting the prediction result to the result table in parallel, each of which connects to the database. The writers of Hive and PAI don't work in parallel.
+range: [1, 64]`, attribute.IntRangeChecker(1, 64, true, true)},
+	"predict.write_mode": {attribute.String, Overwrite, `[default="overwrite"]
+How to write the prediction result to the result table.
+possible values: "overwrite", drops and recreates the table, or the partition of predict.partition; "append", creates the table if it doesn't exist and appends the rows; "error", fails if the table, or the partition of predict.partition, exists`, attribute.StringChoicesChecker(Overwrite, Append, ErrorIfExists)},
+	"predict.partition": {attribute.String, "", `[default=""]
+The partition of the result table to write on Hive and MaxCompute, like "dt=20200601" or "dt=20200601,region=cn". The result table is partitioned by the STRING columns of the partition, which shouldn't be in the SELECT.`, checkPartition},
+	"predict.unseen_categories": {attribute.String, IgnoreUnseen, `[default="ignore"]
+How to predict the rows with the values of the categorical columns not seen at the training.
+possible values: "ignore", predicts them as if the columns were missing; "error", checks the SELECT before the prediction and fails with the unseen values`, attribute.StringChoicesChecker(IgnoreUnseen, ErrorOnUnseen)},
+}
+
+func checkPartition(v interface{}) error {
+	if s, ok := v.(string); ok && s != "" && !rePartition.MatchString(s) {
+		return fmt.Errorf(`partition %q should be like "dt=20200601,region=cn"`, s)
+	}
+	return nil
+}
+
+// InitializeAttributes fills the defaults of the attributes of PREDICT in
+// attrs and does type checking for them. It leaves the attributes without
+// Prefix, like the ones of the PAI cluster, to the code generators.
+func InitializeAttributes(attrs map[string]interface{}) error {
+	predictAttrs := make(map[string]interface{})
+	for k, v := range attrs {
+		if strings.HasPrefix(k, Prefix) {
+			predictAttrs[k] = v
+		}
+	}
+	if e := Attributes.Validate(predictAttrs); e != nil {
+		return e
+	}
+	Attributes.FillDefaults(attrs)
+	return nil
+}
+
+// BatchSize returns the number of rows in each INSERT by attrs.
+func BatchSize(attrs map[string]interface{}) int {
+	return intAttr(attrs, "predict.batch_size")
+}
+
+// NumWriters returns the number of the parallel writers by attrs.
+func NumWriters(attrs map[string]interface{}) int {
+	return intAttr(attrs, "predict.num_writers")
+}
+
+// WriteMode returns how to write the result table by attrs, one of
+// Overwrite, Append and ErrorIfExists.
+func WriteMode(attrs map[string]interface{}) string {
+	return stringAttr(attrs, "predict.write_mode")
+}
+
+// Partition returns the partition of the result table to write by
+// attrs, or "" if the result table isn't partitioned.
+func Partition(attrs map[string]interface{}) string {
+	return stringAttr(attrs, "predict.partition")
+}
+
+// UnseenCategories returns how to predict the unseen categories by attrs,
+// IgnoreUnseen or ErrorOnUnseen.
+func UnseenCategories(attrs map[string]interface{}) string {
+	return stringAttr(attrs, "predict.unseen_categories")
+}
+
+// PartitionColumns splits partition like "dt=20200601,region=cn" into
+// the columns and their values.
+func PartitionColumns(partition string) ([]string, []string) {
+	columns, values := []string{}, []string{}
+	for _, kv := range strings.Split(partition, ",") {
+		if kv := strings.SplitN(kv, "=", 2); len(kv) == 2 {
+			columns = append(columns, kv[0])
+			values = append(values, kv[1])
+		}
+	}
+	return columns, values
+}
+
+func stringAttr(attrs map[string]interface{}, name string) string {
+	if v, ok := attrs[name].(string); ok {
+		return v
+	}
+	return Attributes[name].Default.(string)
+}
+
+func intAttr(attrs map[string]interface{}, name string) int {
+	if v, ok := attrs[name].(int); ok {
+		return v
+	}
+	return Attributes[name].Default.(int)
+}
@@ -0,0 +1,62 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prediction
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInitializeAttributes(t *testing.T) {
+	a := assert.New(t)
+	attrs := map[string]interface{}{"predict.batch_size": 1000, "train.worker_cpu": 400}
+	a.NoError(InitializeAttributes(attrs))
+	a.Equal(1000, BatchSize(attrs))
+	a.Equal(1, NumWriters(attrs))
+	a.Equal(400, attrs["train.worker_cpu"])
+
+	a.Equal(100, BatchSize(map[string]interface{}{}))
+	a.Error(InitializeAttributes(map[string]interface{}{"predict.batch_size": 0}))
+	a.Error(InitializeAttributes(map[string]interface{}{"predict.num_writers": 65}))
+	a.Error(InitializeAttributes(map[string]interface{}{"predict.num_writer": 2}))
+}
+
+func TestWriteModeAndPartition(t *testing.T) {
+	a := assert.New(t)
+	attrs := map[string]interface{}{}
+	a.NoError(InitializeAttributes(attrs))
+	a.Equal(Overwrite, WriteMode(attrs))
+	a.Equal("", Partition(attrs))
+
+	attrs = map[string]interface{}{"predict.write_mode": "append", "predict.partition": "dt=20200601,region=cn"}
+	a.NoError(InitializeAttributes(attrs))
+	a.Equal(Append, WriteMode(attrs))
+	columns, values := PartitionColumns(Partition(attrs))
+	a.Equal([]string{"dt", "region"}, columns)
+	a.Equal([]string{"20200601", "cn"}, values)
+
+	a.Error(InitializeAttributes(map[string]interface{}{"predict.write_mode": "upsert"}))
+	a.Error(InitializeAttributes(map[string]interface{}{"predict.partition": "dt"}))
+	a.Error(InitializeAttributes(map[string]interface{}{"predict.partition": "dt='20200601'"}))
+}
+
+func TestUnseenCategories(t *testing.T) {
+	a := assert.New(t)
+	a.Equal(IgnoreUnseen, UnseenCategories(map[string]interface{}{}))
+	attrs := map[string]interface{}{"predict.unseen_categories": "error"}
+	a.NoError(InitializeAttributes(attrs))
+	a.Equal(ErrorOnUnseen, UnseenCategories(attrs))
+	a.Error(InitializeAttributes(map[string]interface{}{"predict.unseen_categories": "drop"}))
+}
@@ -0,0 +1,284 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pytorch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"sqlflow.org/sqlflow/pkg/ir"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/attribute"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/datacheck"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/evaluation"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/fieldmeta"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/prediction"
+	tf "sqlflow.org/sqlflow/pkg/sql/codegen/tensorflow"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/tuning"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/warmstart"
+)
+
+// estimatorPrefix is the prefix of the PyTorch estimators, like
+// torch.DNNClassifier.
+const estimatorPrefix = "torch."
+
+var commonAttributes = attribute.Dictionary{
+	"model.hidden_units": {attribute.IntList, []int{10, 10}, `[default=[10, 10]]
+The number of units of each hidden layer.`, nil},
+	"model.dropout": {attribute.Float, float32(0), `[default=0]
+The probability to drop out a unit of the hidden layers.
+range: [0,1)`, attribute.Float32RangeChecker(0, 1, true, false)},
+	"model.optimizer": {attribute.String, "Adam", `[default="Adam"]
+The optimizer in torch.optim.
+possible values: "Adam", "Adagrad", "RMSprop", "SGD"`, attribute.StringChoicesChecker("Adam", "Adagrad", "RMSprop", "SGD")},
+	"optimizer.learning_rate": {attribute.Float, float32(0.001), `[default=0.001]
+The learning rate of the optimizer.
+range: (0, Infinity]`, attribute.Float32LowerBoundChecker(0, false)},
+	"train.batch_size": {attribute.Int, 64, `[default=64]
+Batch size for each iteration.
+range: [1, Infinity]`, attribute.IntLowerBoundChecker(1, true)},
+	"train.epoch": {attribute.Int, 1, `[default=1]
+Number of passes over the training data.
+range: [1, Infinity]`, attribute.IntLowerBoundChecker(1, true)},
+	"validation.select": {attribute.String, "", `[default=""]
+Specify the dataset for validation.
+example: "SELECT * FROM iris.test"`, nil},
+}.Update(evaluation.SplitAttributes).Update(datacheck.Attributes)
+
+var classifierAttributes = attribute.Dictionary{
+	"model.n_classes": {attribute.Int, 2, `[default=2]
+Number of classes.
+range: [2, Infinity]`, attribute.IntLowerBoundChecker(2, true)},
+}
+
+// attributeDictionaries maps the supported estimators, in upper case
+// without estimatorPrefix, to their attributes.
+var attributeDictionaries = map[string]attribute.Dictionary{
+	"DNNCLASSIFIER": attribute.Dictionary{}.Update(commonAttributes).Update(classifierAttributes).Update(tuning.Attributes).Update(warmstart.Attributes),
+	"DNNREGRESSOR":  attribute.Dictionary{}.Update(commonAttributes).Update(tuning.Attributes).Update(warmstart.Attributes),
+}
+
+// isPyTorchModel returns true if the estimator is a PyTorch one, like
+// torch.DNNClassifier.
+func isPyTorchModel(estimator string) bool {
+	return strings.HasPrefix(strings.ToLower(estimator), estimatorPrefix)
+}
+
+// modelClass returns the name of the model class in
+// sqlflow_submitter.pytorch.model of the estimator.
+func modelClass(estimator string) (string, error) {
+	if !isPyTorchModel(estimator) {
+		return "", fmt.Errorf("unsupported model name %v, currently supports torch.DNNClassifier, torch.DNNRegressor", estimator)
+	}
+	switch strings.ToUpper(estimator[len(estimatorPrefix):]) {
+	case "DNNCLASSIFIER":
+		return "DNNClassifier", nil
+	case "DNNREGRESSOR":
+		return "DNNRegressor", nil
+	}
+	return "", fmt.Errorf("unsupported model name %v, currently supports torch.DNNClassifier, torch.DNNRegressor", estimator)
+}
+
+// AttributeDictionary returns the attributes of TRAIN by the PyTorch
+// estimator. The caller shouldn't modify it.
+func AttributeDictionary(estimator string) (attribute.Dictionary, error) {
+	if _, err := modelClass(estimator); err != nil {
+		return nil, err
+	}
+	return attributeDictionaries[strings.ToUpper(estimator[len(estimatorPrefix):])], nil
+}
+
+// InitializeAttributes initializes the attributes of PyTorch and does type checking for them
+func InitializeAttributes(trainStmt *ir.TrainStmt) error {
+	if _, err := modelClass(trainStmt.Estimator); err != nil {
+		return err
+	}
+	d := attributeDictionaries[strings.ToUpper(trainStmt.Estimator[len(estimatorPrefix):])]
+	d.FillDefaults(trainStmt.Attributes)
+	space, err := tuning.SplitSearchSpace(d, trainStmt.Attributes)
+	if err != nil {
+		return err
+	}
+	trainStmt.SearchSpace = space
+	if err := d.Validate(trainStmt.Attributes); err != nil {
+		return err
+	}
+	if err := warmstart.Check(trainStmt.Attributes); err != nil {
+		return err
+	}
+	return tuning.CheckValidation(space, trainStmt.ValidationSelect, 0)
+}
+
+// resolveMetas returns the JSON of the feature metas, the feature names,
+// and the JSON of the label meta of trainStmt.
+func resolveMetas(trainStmt *ir.TrainStmt) (string, []string, string, error) {
+	if len(trainStmt.Features) != 1 {
+		return "", nil, "", fmt.Errorf("pytorch only support 1 feature column set, received %d", len(trainStmt.Features))
+	}
+	featureFieldDesc, err := fieldmeta.Features(trainStmt.Features["feature_columns"], true)
+	if err != nil {
+		return "", nil, "", err
+	}
+	labelFieldDesc, err := fieldmeta.Label(trainStmt.Label)
+	if err != nil {
+		return "", nil, "", err
+	}
+	f, fs, err := fieldmeta.ResolveFeatures(featureFieldDesc)
+	if err != nil {
+		return "", nil, "", err
+	}
+	l, err := json.Marshal(fieldmeta.Resolve(&labelFieldDesc))
+	if err != nil {
+		return "", nil, "", err
+	}
+	return string(f), fs, string(l), nil
+}
+
+// Train generates a Python program for train a PyTorch model.
+func Train(trainStmt *ir.TrainStmt, session *pb.Session) (string, error) {
+	if tf.IsPAI() {
+		return "", fmt.Errorf("pytorch models are not supported on PAI")
+	}
+	cls, err := modelClass(trainStmt.Estimator)
+	if err != nil {
+		return "", err
+	}
+	f, fs, l, err := resolveMetas(trainStmt)
+	if err != nil {
+		return "", err
+	}
+	params := attribute.Group(trainStmt.Attributes, "model.", "optimizer.", "train.")
+	optimizer := params["model."]["optimizer"].(string)
+	delete(params["model."], "optimizer")
+	batchSize := params["train."]["batch_size"].(int)
+	epoch := params["train."]["epoch"].(int)
+
+	mp, err := json.Marshal(params["model."])
+	if err != nil {
+		return "", err
+	}
+	op, err := json.Marshal(params["optimizer."])
+	if err != nil {
+		return "", err
+	}
+	metric := "RMSE"
+	if cls == "DNNClassifier" {
+		metric = "accuracy_score"
+	}
+	groups := map[string]string{"model.": "model_params", "optimizer.": "optimizer_params", "train.": "train_params"}
+	tuningParams, err := tuning.Resolve(trainStmt.Attributes, trainStmt.SearchSpace, groups, metric)
+	if err != nil {
+		return "", err
+	}
+	tp, err := tuningParams.JSON()
+	if err != nil {
+		return "", err
+	}
+
+	r := trainFiller{
+		DataSource:          session.DbConnStr,
+		TrainSelect:         trainStmt.Select,
+		ValidationSelect:    trainStmt.ValidationSelect,
+		Estimator:           trainStmt.Estimator,
+		ModelClass:          cls,
+		ModelParamsJSON:     string(mp),
+		Optimizer:           optimizer,
+		OptimizerParamsJSON: string(op),
+		TuningJSON:          tp,
+		FieldDescJSON:       f,
+		FeatureColumnNames:  fs,
+		LabelJSON:           l,
+		BatchSize:           batchSize,
+		Epoch:               epoch,
+		WarmStart:           warmstart.Model(trainStmt.Attributes) != "",
+	}
+	var program bytes.Buffer
+	if err := trainTemplate.Execute(&program, r); err != nil {
+		return "", err
+	}
+	return program.String(), nil
+}
+
+// Pred generates a Python program for predict a PyTorch model.
+func Pred(predStmt *ir.PredictStmt, session *pb.Session) (string, error) {
+	if tf.IsPAI() {
+		return "", fmt.Errorf("pytorch models are not supported on PAI")
+	}
+	f, fs, l, err := resolveMetas(predStmt.TrainStmt)
+	if err != nil {
+		return "", err
+	}
+	r := predFiller{
+		DataSource:         session.DbConnStr,
+		PredSelect:         predStmt.Select,
+		FeatureMetaJSON:    f,
+		FeatureColumnNames: fs,
+		LabelMetaJSON:      l,
+		ResultTable:        predStmt.ResultTable,
+		HDFSNameNodeAddr:   session.HdfsNamenodeAddr,
+		HiveLocation:       session.HiveLocation,
+		HDFSUser:           session.HdfsUser,
+		HDFSPass:           session.HdfsPass,
+		WriteBatchSize:     prediction.BatchSize(predStmt.Attributes),
+		NumWriters:         prediction.NumWriters(predStmt.Attributes),
+		Partition:          prediction.Partition(predStmt.Attributes),
+	}
+	var program bytes.Buffer
+	if err := predTemplate.Execute(&program, r); err != nil {
+		return "", err
+	}
+	return program.String(), nil
+}
+
+// Evaluate generates a Python program for evaluating a PyTorch model.
+func Evaluate(evalStmt *ir.EvaluateStmt, session *pb.Session) (string, error) {
+	if tf.IsPAI() {
+		return "", fmt.Errorf("pytorch models are not supported on PAI")
+	}
+	f, fs, l, err := resolveMetas(evalStmt.TrainStmt)
+	if err != nil {
+		return "", err
+	}
+	// NOTE: support the metrics in https://scikit-learn.org/stable/modules/classes.html#sklearn-metrics-metrics
+	// and the ones in sqlflow_submitter/evaluation.py.
+	params, err := evaluation.Resolve(evalStmt, "accuracy_score")
+	if err != nil {
+		return "", err
+	}
+	evalParams, err := params.JSON()
+	if err != nil {
+		return "", err
+	}
+	r := evalFiller{
+		DataSource:         session.DbConnStr,
+		PredSelect:         evalStmt.Select,
+		FeatureMetaJSON:    f,
+		FeatureColumnNames: fs,
+		LabelMetaJSON:      l,
+		MetricNames:        params.MetricNames(),
+		EvalParamsJSON:     evalParams,
+		ResultTable:        evalStmt.Into,
+		HDFSNameNodeAddr:   session.HdfsNamenodeAddr,
+		HiveLocation:       session.HiveLocation,
+		HDFSUser:           session.HdfsUser,
+		HDFSPass:           session.HdfsPass,
+	}
+	var program bytes.Buffer
+	if err := evalTemplate.Execute(&program, r); err != nil {
+		return "", err
+	}
+	return program.String(), nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pytorch
+
+import (
+	"bytes"
+	"text/template"
+)
+
+// DocGenInMarkdown generates the doc of the PyTorch models in Markdown format.
+func DocGenInMarkdown() string {
+	var doc bytes.Buffer
+	docTemplate.Execute(&doc, attributeDictionaries["DNNCLASSIFIER"].GenerateTableInHTML())
+
+	return doc.String()
+}
+
+const docTemplateText = `# PyTorch Parameters
+
+## TRAIN
+
+### Example
+
+` + "```SQL" + `
+SELECT * FROM iris.train
+TO TRAIN torch.DNNClassifier
+WITH
+    model.n_classes = 3,
+    model.hidden_units = [10, 20],
+    optimizer.learning_rate = 0.01,
+    train.epoch = 10
+LABEL class
+INTO sqlflow_models.my_torch_model;
+` + "```" + `
+
+### Model Types
+
+` + "`torch.DNNClassifier`, `torch.DNNRegressor`" + `
+
+### Parameters
+
+{{.}}
+
+` + "`model.n_classes`" + ` is only for ` + "`torch.DNNClassifier`" + `.
+
+## PREDICT
+
+TBD
+`
+
+var docTemplate = template.Must(template.New("Doc").Parse(docTemplateText))
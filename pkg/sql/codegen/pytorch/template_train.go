@@ -0,0 +1,67 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pytorch
+
+import "text/template"
+
+type trainFiller struct {
+	DataSource          string
+	TrainSelect         string
+	ValidationSelect    string
+	Estimator           string
+	ModelClass          string
+	ModelParamsJSON     string
+	Optimizer           string
+	OptimizerParamsJSON string
+	TuningJSON          string
+	FieldDescJSON       string
+	FeatureColumnNames  []string
+	LabelJSON           string
+	BatchSize           int
+	Epoch               int
+	WarmStart           bool
+}
+
+const trainTemplateText = `
+from sqlflow_submitter.pytorch.train import train
+import json
+
+model_params = json.loads('''{{.ModelParamsJSON}}''')
+optimizer_params = json.loads('''{{.OptimizerParamsJSON}}''')
+tuning_params = json.loads('''{{.TuningJSON}}''')
+feature_metas = json.loads('''{{.FieldDescJSON}}''')
+label_meta = json.loads('''{{.LabelJSON}}''')
+
+feature_column_names = [{{range .FeatureColumnNames}}
+"{{.}}",
+{{end}}]
+
+train(datasource='''{{.DataSource}}''',
+      select='''{{.TrainSelect}}''',
+      validation_select='''{{.ValidationSelect}}''',
+      estimator="{{.Estimator}}",
+      model_class="{{.ModelClass}}",
+      model_params=model_params,
+      optimizer="{{.Optimizer}}",
+      optimizer_params=optimizer_params,
+      feature_metas=feature_metas,
+      feature_column_names=feature_column_names,
+      label_meta=label_meta,
+      batch_size={{.BatchSize}},
+      epoch={{.Epoch}},
+      tuning_params=tuning_params,
+      warm_start="{{.WarmStart}}" == "true")
+`
+
+var trainTemplate = template.Must(template.New("Train").Parse(trainTemplateText))
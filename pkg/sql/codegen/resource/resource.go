@@ -0,0 +1,113 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"sqlflow.org/sqlflow/pkg/ir"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/attribute"
+)
+
+// Prefix is the prefix of the attributes requesting the resources.
+const Prefix = "resources."
+
+// GPUResourceName is the Kubernetes resource name of the GPU cards.
+const GPUResourceName = "nvidia.com/gpu"
+
+var memoryRegex = regexp.MustCompile(`^([0-9]+)(Ki|Mi|Gi|Ti|K|M|G|T)?$`)
+
+var bytesOfUnit = map[string]int64{
+	"":   1,
+	"K":  1000,
+	"M":  1000 * 1000,
+	"G":  1000 * 1000 * 1000,
+	"T":  1000 * 1000 * 1000 * 1000,
+	"Ki": 1 << 10,
+	"Mi": 1 << 20,
+	"Gi": 1 << 30,
+	"Ti": 1 << 40,
+}
+
+// Attributes are the attributes of TRAIN and PREDICT requesting the
+// resources of the job.
+var Attributes = attribute.Dictionary{
+	"resources.cpu": {attribute.Int, 0, `[default=0]
+The number of CPU cores of each worker. 0 means the default of the submitter.
+range: [0, Infinity]`, attribute.IntLowerBoundChecker(0, true)},
+	"resources.gpu": {attribute.Int, 0, `[default=0]
+The number of GPU cards of each worker. 0 means the default of the submitter.
+range: [0, Infinity]`, attribute.IntLowerBoundChecker(0, true)},
+	"resources.memory": {attribute.String, "", `[default=""]
+The memory of each worker, like "512Mi" or "8Gi". Not set means the default of the submitter.`, func(i interface{}) error {
+		if s, _ := i.(string); s != "" {
+			_, e := MemoryBytes(s)
+			return e
+		}
+		return nil
+	}},
+}
+
+// MemoryBytes returns the bytes of the memory quantity, like "8Gi".
+func MemoryBytes(memory string) (int64, error) {
+	m := memoryRegex.FindStringSubmatch(memory)
+	if m == nil {
+		return 0, fmt.Errorf(`resources.memory should be like "512Mi" or "8Gi", got %s`, memory)
+	}
+	n, e := strconv.ParseInt(m[1], 10, 64)
+	if e != nil {
+		return 0, fmt.Errorf("resources.memory %s: %v", memory, e)
+	}
+	return n * bytesOfUnit[m[2]], nil
+}
+
+// Parse removes the attributes with Prefix from attrs and returns the
+// resources they request.
+func Parse(attrs map[string]interface{}) (ir.Resources, error) {
+	requested := map[string]interface{}{}
+	for k, v := range attrs {
+		if strings.HasPrefix(k, Prefix) {
+			requested[k] = v
+			delete(attrs, k)
+		}
+	}
+	if e := Attributes.Validate(requested); e != nil {
+		return ir.Resources{}, e
+	}
+	Attributes.FillDefaults(requested)
+	return ir.Resources{
+		CPU:    requested["resources.cpu"].(int),
+		GPU:    requested["resources.gpu"].(int),
+		Memory: requested["resources.memory"].(string),
+	}, nil
+}
+
+// Requests returns the Kubernetes resource requests of r, like
+// {"cpu": "4", "memory": "8Gi", "nvidia.com/gpu": "1"}.
+func Requests(r ir.Resources) map[string]string {
+	requests := map[string]string{}
+	if r.CPU > 0 {
+		requests["cpu"] = strconv.Itoa(r.CPU)
+	}
+	if r.Memory != "" {
+		requests["memory"] = r.Memory
+	}
+	if r.GPU > 0 {
+		requests[GPUResourceName] = strconv.Itoa(r.GPU)
+	}
+	return requests
+}
@@ -0,0 +1,65 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sqlflow.org/sqlflow/pkg/ir"
+)
+
+func TestParse(t *testing.T) {
+	a := assert.New(t)
+	attrs := map[string]interface{}{
+		"resources.gpu":    1,
+		"resources.cpu":    4,
+		"resources.memory": "8Gi",
+		"train.epoch":      10,
+	}
+	r, e := Parse(attrs)
+	a.NoError(e)
+	a.Equal(ir.Resources{CPU: 4, GPU: 1, Memory: "8Gi"}, r)
+	a.Equal(map[string]interface{}{"train.epoch": 10}, attrs)
+	a.Equal(map[string]string{"cpu": "4", "memory": "8Gi", "nvidia.com/gpu": "1"}, Requests(r))
+
+	r, e = Parse(map[string]interface{}{"train.epoch": 10})
+	a.NoError(e)
+	a.True(r.IsEmpty())
+	a.Equal(map[string]string{}, Requests(r))
+
+	_, e = Parse(map[string]interface{}{"resources.gpu": "1"})
+	a.Error(e)
+	_, e = Parse(map[string]interface{}{"resources.cpu": -1})
+	a.Error(e)
+	_, e = Parse(map[string]interface{}{"resources.memory": "8GB"})
+	a.Error(e)
+	_, e = Parse(map[string]interface{}{"resources.disk": "8Gi"})
+	a.Error(e)
+}
+
+func TestMemoryBytes(t *testing.T) {
+	a := assert.New(t)
+	n, e := MemoryBytes("8Gi")
+	a.NoError(e)
+	a.Equal(int64(8<<30), n)
+	n, e = MemoryBytes("512M")
+	a.NoError(e)
+	a.Equal(int64(512000000), n)
+	n, e = MemoryBytes("1024")
+	a.NoError(e)
+	a.Equal(int64(1024), n)
+	_, e = MemoryBytes("1.5Gi")
+	a.Error(e)
+}
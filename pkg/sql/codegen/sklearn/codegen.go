@@ -0,0 +1,432 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sklearn
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"sqlflow.org/sqlflow/pkg/ir"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/attribute"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/datacheck"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/evaluation"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/fieldmeta"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/prediction"
+	tf "sqlflow.org/sqlflow/pkg/sql/codegen/tensorflow"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/tuning"
+)
+
+// estimator describes a scikit-learn estimator, like
+// sklearn.RandomForestClassifier.
+type estimator struct {
+	// class is the Python class of the estimator.
+	class string
+	// supervised estimators require the LABEL clause.
+	supervised bool
+	// anomaly detectors PREDICT the anomaly scores, the higher, the more
+	// abnormal.
+	anomaly bool
+	// attributes are the parameters of the constructor of class.
+	attributes attribute.Dictionary
+}
+
+var commonAttributes = attribute.Dictionary{
+	"random_state": {attribute.Int, nil, `Seed of the random number generator.`, nil},
+}
+
+var parallelAttributes = attribute.Dictionary{
+	"n_jobs": {attribute.Int, nil, `[default=None]
+The number of jobs to run in parallel, -1 means using all processors.`, nil},
+}
+
+var validationAttributes = attribute.Dictionary{
+	"validation.select": {attribute.String, "", `[default=""]
+Specify the dataset to report the score of the model on.
+example: "SELECT * FROM iris.test"`, nil},
+}.Update(evaluation.CrossValidationAttributes).Update(evaluation.SplitAttributes).Update(datacheck.Attributes).Update(tuning.Attributes)
+
+var logisticRegressionAttributes = attribute.Dictionary{
+	"C": {attribute.Float, nil, `[default=1.0]
+Inverse of regularization strength.
+range: (0, Infinity]`, attribute.Float32LowerBoundChecker(0, false)},
+	"penalty": {attribute.String, nil, `[default="l2"]
+The norm used in the penalization.
+possible values: "l1", "l2", "elasticnet", "none"`, attribute.StringChoicesChecker("l1", "l2", "elasticnet", "none")},
+	"solver": {attribute.String, nil, `[default="lbfgs"]
+Algorithm to use in the optimization problem.
+possible values: "newton-cg", "lbfgs", "liblinear", "sag", "saga"`, attribute.StringChoicesChecker("newton-cg", "lbfgs", "liblinear", "sag", "saga")},
+	"max_iter": {attribute.Int, nil, `[default=100]
+Maximum number of iterations taken for the solvers to converge.
+range: [1, Infinity]`, attribute.IntLowerBoundChecker(1, true)},
+	"multi_class": {attribute.String, nil, `[default="auto"]
+possible values: "auto", "ovr", "multinomial"`, attribute.StringChoicesChecker("auto", "ovr", "multinomial")},
+	"l1_ratio": {attribute.Float, nil, `The Elastic-Net mixing parameter, only for penalty="elasticnet".
+range: [0, 1]`, attribute.Float32RangeChecker(0, 1, true, true)},
+	"tol": {attribute.Float, nil, `[default=1e-4]
+Tolerance for stopping criteria.`, nil},
+	"fit_intercept": {attribute.Bool, nil, `[default=true]
+Whether to add a constant to the decision function.`, nil},
+	"class_weight": {attribute.String, nil, `[default=None]
+possible values: "balanced"`, attribute.StringChoicesChecker("balanced")},
+}
+
+func randomForestAttributes(criteria ...string) attribute.Dictionary {
+	return attribute.Dictionary{
+		"n_estimators": {attribute.Int, nil, `[default=100]
+The number of trees in the forest.
+range: [1, Infinity]`, attribute.IntLowerBoundChecker(1, true)},
+		"criterion": {attribute.String, nil, fmt.Sprintf(`[default="%s"]
+The function to measure the quality of a split.
+possible values: "%s"`, criteria[0], strings.Join(criteria, `", "`)), attribute.StringChoicesChecker(criteria...)},
+		"max_depth": {attribute.Int, nil, `[default=None]
+The maximum depth of the trees.
+range: [1, Infinity]`, attribute.IntLowerBoundChecker(1, true)},
+		"min_samples_split": {attribute.Int, nil, `[default=2]
+The minimum number of samples required to split an internal node.
+range: [2, Infinity]`, attribute.IntLowerBoundChecker(2, true)},
+		"min_samples_leaf": {attribute.Int, nil, `[default=1]
+The minimum number of samples required to be at a leaf node.
+range: [1, Infinity]`, attribute.IntLowerBoundChecker(1, true)},
+		"max_features": {attribute.String, nil, `[default="auto"]
+The number of features to consider when looking for the best split.
+possible values: "auto", "sqrt", "log2"`, attribute.StringChoicesChecker("auto", "sqrt", "log2")},
+		"bootstrap": {attribute.Bool, nil, `[default=true]
+Whether bootstrap samples are used when building trees.`, nil},
+	}
+}
+
+var kmeansAttributes = attribute.Dictionary{
+	"n_clusters": {attribute.Int, nil, `[default=8]
+The number of clusters.
+range: [1, Infinity]`, attribute.IntLowerBoundChecker(1, true)},
+	"init": {attribute.String, nil, `[default="k-means++"]
+Method for initialization.
+possible values: "k-means++", "random"`, attribute.StringChoicesChecker("k-means++", "random")},
+	"n_init": {attribute.Int, nil, `[default=10]
+Number of time the k-means algorithm will be run with different centroid seeds.
+range: [1, Infinity]`, attribute.IntLowerBoundChecker(1, true)},
+	"max_iter": {attribute.Int, nil, `[default=300]
+Maximum number of iterations of the k-means algorithm for a single run.
+range: [1, Infinity]`, attribute.IntLowerBoundChecker(1, true)},
+	"tol": {attribute.Float, nil, `[default=1e-4]
+Relative tolerance to declare convergence.`, nil},
+	"algorithm": {attribute.String, nil, `[default="auto"]
+possible values: "auto", "full", "elkan"`, attribute.StringChoicesChecker("auto", "full", "elkan")},
+}
+
+var isolationForestAttributes = attribute.Dictionary{
+	"n_estimators": {attribute.Int, nil, `[default=100]
+The number of trees in the ensemble.
+range: [1, Infinity]`, attribute.IntLowerBoundChecker(1, true)},
+	"max_samples": {attribute.Int, nil, `[default=min(256, n_samples)]
+The number of samples to train each tree.
+range: [1, Infinity]`, attribute.IntLowerBoundChecker(1, true)},
+	"contamination": {attribute.Float, nil, `[default="auto"]
+The proportion of outliers in the data.
+range: (0, 0.5]`, attribute.Float32RangeChecker(0, 0.5, false, true)},
+	"max_features": {attribute.Float, nil, `[default=1.0]
+The ratio of features to train each tree.
+range: (0, 1]`, attribute.Float32RangeChecker(0, 1, false, true)},
+	"bootstrap": {attribute.Bool, nil, `[default=false]
+Whether the trees are trained on samples drawn with replacement.`, nil},
+}
+
+var autoEncoderAttributes = attribute.Dictionary{
+	"hidden_units": {attribute.IntList, nil, `[default=[8]]
+The number of units of each hidden layer of the multi-layer perceptron reconstructing the features.`, nil},
+	"activation": {attribute.String, nil, `[default="relu"]
+The activation function of the hidden layers.
+possible values: "identity", "logistic", "tanh", "relu"`, attribute.StringChoicesChecker("identity", "logistic", "tanh", "relu")},
+	"alpha": {attribute.Float, nil, `[default=1e-4]
+L2 regularization.
+range: [0, Infinity]`, attribute.Float32LowerBoundChecker(0, true)},
+	"learning_rate_init": {attribute.Float, nil, `[default=1e-3]
+The initial learning rate of the Adam optimizer.
+range: (0, Infinity]`, attribute.Float32LowerBoundChecker(0, false)},
+	"max_iter": {attribute.Int, nil, `[default=200]
+Maximum number of the epochs.
+range: [1, Infinity]`, attribute.IntLowerBoundChecker(1, true)},
+	"contamination": {attribute.Float, nil, `[default=0.1]
+The proportion of outliers in the data, which decides the threshold of the reconstruction errors of the outliers.
+range: (0, 0.5]`, attribute.Float32RangeChecker(0, 0.5, false, true)},
+}
+
+func newDictionary(dicts ...attribute.Dictionary) attribute.Dictionary {
+	d := attribute.Dictionary{}
+	for _, other := range dicts {
+		d.Update(other)
+	}
+	return d
+}
+
+// estimators maps the supported estimators, in upper case without the
+// prefix "sklearn.", to their descriptions.
+var estimators = map[string]*estimator{
+	"LOGISTICREGRESSION": {
+		"sklearn.linear_model.LogisticRegression", true, false,
+		newDictionary(commonAttributes, parallelAttributes, validationAttributes, logisticRegressionAttributes)},
+	"RANDOMFORESTCLASSIFIER": {
+		"sklearn.ensemble.RandomForestClassifier", true, false,
+		newDictionary(commonAttributes, parallelAttributes, validationAttributes, randomForestAttributes("gini", "entropy"),
+			attribute.Dictionary{"class_weight": logisticRegressionAttributes["class_weight"]})},
+	"RANDOMFORESTREGRESSOR": {
+		"sklearn.ensemble.RandomForestRegressor", true, false,
+		newDictionary(commonAttributes, parallelAttributes, validationAttributes, randomForestAttributes("mse", "mae"))},
+	"KMEANS": {
+		"sklearn.cluster.KMeans", false, false,
+		newDictionary(commonAttributes, kmeansAttributes)},
+	"ISOLATIONFOREST": {
+		"sklearn.ensemble.IsolationForest", false, true,
+		newDictionary(commonAttributes, parallelAttributes, isolationForestAttributes)},
+	"AUTOENCODER": {
+		"sqlflow_submitter.sklearn.anomaly.AutoEncoder", false, true,
+		newDictionary(commonAttributes, autoEncoderAttributes)},
+}
+
+func estimatorNames() []string {
+	names := []string{}
+	for _, e := range estimators {
+		names = append(names, "sklearn."+e.class[strings.LastIndex(e.class, ".")+1:])
+	}
+	sort.Strings(names)
+	return names
+}
+
+func resolveEstimator(name string) (*estimator, error) {
+	if strings.HasPrefix(strings.ToUpper(name), "SKLEARN.") {
+		if e, ok := estimators[strings.ToUpper(name[len("sklearn."):])]; ok {
+			return e, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported model name %v, currently supports %s", name, strings.Join(estimatorNames(), ", "))
+}
+
+// IsAnomalyDetector returns if the estimator is an anomaly detector, which
+// PREDICTs the anomaly scores.
+func IsAnomalyDetector(name string) bool {
+	e, err := resolveEstimator(name)
+	return err == nil && e.anomaly
+}
+
+func hasLabel(trainStmt *ir.TrainStmt) bool {
+	fds := trainStmt.Label.GetFieldDesc()
+	return len(fds) > 0 && fds[0].Name != ""
+}
+
+// AttributeDictionary returns the attributes of TRAIN by the
+// scikit-learn estimator. The caller shouldn't modify it.
+func AttributeDictionary(estimator string) (attribute.Dictionary, error) {
+	e, err := resolveEstimator(estimator)
+	if err != nil {
+		return nil, err
+	}
+	return e.attributes, nil
+}
+
+// InitializeAttributes initializes the attributes of scikit-learn and does type checking for them
+func InitializeAttributes(trainStmt *ir.TrainStmt) error {
+	e, err := resolveEstimator(trainStmt.Estimator)
+	if err != nil {
+		return err
+	}
+	if e.supervised && !hasLabel(trainStmt) {
+		return fmt.Errorf("%s requires the LABEL clause", trainStmt.Estimator)
+	}
+	e.attributes.FillDefaults(trainStmt.Attributes)
+	space, err := tuning.SplitSearchSpace(e.attributes, trainStmt.Attributes)
+	if err != nil {
+		return err
+	}
+	trainStmt.SearchSpace = space
+	if err := e.attributes.Validate(trainStmt.Attributes); err != nil {
+		return err
+	}
+	kfold, _ := trainStmt.Attributes["validation.kfold"].(int)
+	return tuning.CheckValidation(space, trainStmt.ValidationSelect, kfold)
+}
+
+// resolveMetas returns the JSON of the feature metas, the feature names,
+// and the JSON of the label meta of trainStmt, which is null for the
+// unsupervised estimators.
+func resolveMetas(trainStmt *ir.TrainStmt, e *estimator) (string, []string, string, error) {
+	if len(trainStmt.Features) != 1 {
+		return "", nil, "", fmt.Errorf("sklearn only support 1 feature column set, received %d", len(trainStmt.Features))
+	}
+	featureFieldDesc, err := fieldmeta.Features(trainStmt.Features["feature_columns"], true)
+	if err != nil {
+		return "", nil, "", err
+	}
+	f, featureNames, err := fieldmeta.ResolveFeatures(featureFieldDesc)
+	if err != nil {
+		return "", nil, "", err
+	}
+	if !e.supervised {
+		return string(f), featureNames, "null", nil
+	}
+	label, err := fieldmeta.Label(trainStmt.Label)
+	if err != nil {
+		return "", nil, "", err
+	}
+	l, err := json.Marshal(fieldmeta.Resolve(&label))
+	if err != nil {
+		return "", nil, "", err
+	}
+	return string(f), featureNames, string(l), nil
+}
+
+// Train generates a Python program for train a scikit-learn model.
+func Train(trainStmt *ir.TrainStmt, session *pb.Session) (string, error) {
+	if tf.IsPAI() {
+		return "", fmt.Errorf("sklearn models are not supported on PAI")
+	}
+	e, err := resolveEstimator(trainStmt.Estimator)
+	if err != nil {
+		return "", err
+	}
+	f, fs, l, err := resolveMetas(trainStmt, e)
+	if err != nil {
+		return "", err
+	}
+	params := map[string]interface{}{}
+	for k, v := range trainStmt.Attributes {
+		if !strings.HasPrefix(k, "validation.") && !strings.HasPrefix(k, "tuning.") {
+			params[k] = v
+		}
+	}
+	mp, err := json.Marshal(params)
+	if err != nil {
+		return "", err
+	}
+	metrics := "accuracy_score"
+	if strings.HasSuffix(e.class, "Regressor") {
+		metrics = "RMSE"
+	}
+	cv, err := evaluation.ResolveCrossValidation(trainStmt.Attributes, metrics).JSON()
+	if err != nil {
+		return "", err
+	}
+	tuningParams, err := tuning.Resolve(trainStmt.Attributes, trainStmt.SearchSpace, map[string]string{"": "model_params"}, metrics)
+	if err != nil {
+		return "", err
+	}
+	tp, err := tuningParams.JSON()
+	if err != nil {
+		return "", err
+	}
+	r := trainFiller{
+		DataSource:          session.DbConnStr,
+		TrainSelect:         trainStmt.Select,
+		ValidationSelect:    trainStmt.ValidationSelect,
+		Estimator:           trainStmt.Estimator,
+		ModelClass:          e.class,
+		ModelParamsJSON:     string(mp),
+		CrossValidationJSON: cv,
+		TuningJSON:          tp,
+		FieldDescJSON:       f,
+		FeatureColumnNames:  fs,
+		LabelJSON:           l,
+	}
+	var program bytes.Buffer
+	if err := trainTemplate.Execute(&program, r); err != nil {
+		return "", err
+	}
+	return program.String(), nil
+}
+
+// Pred generates a Python program for predict a scikit-learn model.
+func Pred(predStmt *ir.PredictStmt, session *pb.Session) (string, error) {
+	if tf.IsPAI() {
+		return "", fmt.Errorf("sklearn models are not supported on PAI")
+	}
+	e, err := resolveEstimator(predStmt.TrainStmt.Estimator)
+	if err != nil {
+		return "", err
+	}
+	f, fs, _, err := resolveMetas(predStmt.TrainStmt, e)
+	if err != nil {
+		return "", err
+	}
+	r := predFiller{
+		DataSource:         session.DbConnStr,
+		PredSelect:         predStmt.Select,
+		FeatureMetaJSON:    f,
+		FeatureColumnNames: fs,
+		ResultTable:        predStmt.ResultTable,
+		ResultColumn:       predStmt.ResultColumn,
+		AnomalyScore:       e.anomaly,
+		HDFSNameNodeAddr:   session.HdfsNamenodeAddr,
+		HiveLocation:       session.HiveLocation,
+		HDFSUser:           session.HdfsUser,
+		HDFSPass:           session.HdfsPass,
+		WriteBatchSize:     prediction.BatchSize(predStmt.Attributes),
+		NumWriters:         prediction.NumWriters(predStmt.Attributes),
+		Partition:          prediction.Partition(predStmt.Attributes),
+	}
+	var program bytes.Buffer
+	if err := predTemplate.Execute(&program, r); err != nil {
+		return "", err
+	}
+	return program.String(), nil
+}
+
+// Evaluate generates a Python program for evaluating a scikit-learn model.
+func Evaluate(evalStmt *ir.EvaluateStmt, session *pb.Session) (string, error) {
+	if tf.IsPAI() {
+		return "", fmt.Errorf("sklearn models are not supported on PAI")
+	}
+	e, err := resolveEstimator(evalStmt.TrainStmt.Estimator)
+	if err != nil {
+		return "", err
+	}
+	if !e.supervised {
+		return "", fmt.Errorf("EVALUATE is not supported for the unsupervised model %s", evalStmt.TrainStmt.Estimator)
+	}
+	f, fs, l, err := resolveMetas(evalStmt.TrainStmt, e)
+	if err != nil {
+		return "", err
+	}
+	// NOTE: support the metrics in https://scikit-learn.org/stable/modules/classes.html#sklearn-metrics-metrics
+	// and the ones in sqlflow_submitter/evaluation.py.
+	params, err := evaluation.Resolve(evalStmt, "accuracy_score")
+	if err != nil {
+		return "", err
+	}
+	evalParams, err := params.JSON()
+	if err != nil {
+		return "", err
+	}
+	r := evalFiller{
+		DataSource:         session.DbConnStr,
+		PredSelect:         evalStmt.Select,
+		FeatureMetaJSON:    f,
+		FeatureColumnNames: fs,
+		LabelMetaJSON:      l,
+		MetricNames:        params.MetricNames(),
+		EvalParamsJSON:     evalParams,
+		ResultTable:        evalStmt.Into,
+		HDFSNameNodeAddr:   session.HdfsNamenodeAddr,
+		HiveLocation:       session.HiveLocation,
+		HDFSUser:           session.HdfsUser,
+		HDFSPass:           session.HdfsPass,
+	}
+	var program bytes.Buffer
+	if err := evalTemplate.Execute(&program, r); err != nil {
+		return "", err
+	}
+	return program.String(), nil
+}
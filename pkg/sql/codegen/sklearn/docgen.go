@@ -0,0 +1,72 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sklearn
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+)
+
+// DocGenInMarkdown generates the doc of the scikit-learn models in Markdown format.
+func DocGenInMarkdown() string {
+	type model struct {
+		Name  string
+		Table string
+	}
+	models := []model{}
+	for _, name := range estimatorNames() {
+		models = append(models, model{name, estimators[strings.ToUpper(name[len("sklearn."):])].attributes.GenerateTableInHTML()})
+	}
+	var doc bytes.Buffer
+	docTemplate.Execute(&doc, models)
+
+	return doc.String()
+}
+
+const docTemplateText = `# scikit-learn Parameters
+
+## TRAIN
+
+### Example
+
+` + "```SQL" + `
+SELECT * FROM iris.train
+TO TRAIN sklearn.RandomForestClassifier
+WITH
+    n_estimators = 50,
+    max_depth = 4,
+    validation.select = "SELECT * FROM iris.test"
+LABEL class
+INTO sqlflow_models.my_random_forest_model;
+` + "```" + `
+
+The unsupervised models, ` + "`sklearn.KMeans`, `sklearn.IsolationForest` and `sklearn.AutoEncoder`" + `, don't need the LABEL clause. ` + "`sklearn.AutoEncoder`" + ` detects the anomalies by the errors of reconstructing the features by a multi-layer perceptron.
+{{range .}}
+### {{.Name}}
+
+{{.Table}}
+{{end}}
+## PREDICT
+
+` + "```SQL" + `
+SELECT * FROM shop.orders
+TO PREDICT shop.order_anomalies.anomaly_score
+USING sqlflow_models.my_isolation_forest_model;
+` + "```" + `
+
+The anomaly detectors, ` + "`sklearn.IsolationForest` and `sklearn.AutoEncoder`" + `, write the anomaly scores to the result column, the higher, the more abnormal.
+`
+
+var docTemplate = template.Must(template.New("Doc").Parse(docTemplateText))
@@ -0,0 +1,85 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sqlflow.org/sqlflow/pkg/sql/codegen/attribute"
+)
+
+// Dir is where the training program materializes the training data,
+// under the working directory of the training, see
+// python/sqlflow_submitter/snapshot.py. The submitter uploads the files
+// in Dir to the URI of the snapshot after the training.
+const Dir = "data_snapshot"
+
+// Formats are the file formats of the snapshots.
+var Formats = []string{"tfrecord", "parquet"}
+
+var uriRegex = regexp.MustCompile(`^[a-z][a-z0-9]*://.+`)
+
+// Attributes are the attributes of TRAIN of the snapshot of the training
+// data, for the code generators that support it.
+var Attributes = attribute.Dictionary{
+	"train.data_snapshot": {attribute.String, nil, `[default=""]
+The URI to save the snapshot of the training data to, like "oss://bucket/path" or "file:///data/path". SQLFlow materializes the training and the validation SELECT into the files under the URI before the training, and the training reads the files instead of the database for every epoch. Not set means no snapshot.`, func(i interface{}) error {
+		if s, _ := i.(string); s != "" && !uriRegex.MatchString(s) {
+			return fmt.Errorf(`train.data_snapshot should be like "oss://bucket/path", got %s`, s)
+		}
+		return nil
+	}},
+	"train.data_snapshot_format": {attribute.String, nil, `[default="tfrecord"]
+The file format of the snapshot of the training data, "tfrecord" or "parquet".`, func(i interface{}) error {
+		if s, _ := i.(string); s != "" && !isFormat(s) {
+			return fmt.Errorf("train.data_snapshot_format should be one of %s, got %s", strings.Join(Formats, ", "), s)
+		}
+		return nil
+	}},
+}
+
+func isFormat(s string) bool {
+	for _, f := range Formats {
+		if s == f {
+			return true
+		}
+	}
+	return false
+}
+
+// URI returns the URI of the snapshot of the training data in attrs, or
+// "" for no snapshot.
+func URI(attrs map[string]interface{}) string {
+	u, _ := attrs["train.data_snapshot"].(string)
+	return strings.TrimSuffix(u, "/")
+}
+
+// Format returns the file format of the snapshot in attrs.
+func Format(attrs map[string]interface{}) string {
+	if f, _ := attrs["train.data_snapshot_format"].(string); f != "" {
+		return f
+	}
+	return Formats[0]
+}
+
+// Check returns an error if attrs set the format of the snapshot without
+// the URI.
+func Check(attrs map[string]interface{}) error {
+	if _, ok := attrs["train.data_snapshot_format"]; ok && URI(attrs) == "" {
+		return fmt.Errorf("train.data_snapshot_format requires train.data_snapshot")
+	}
+	return nil
+}
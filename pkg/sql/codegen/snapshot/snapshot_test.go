@@ -0,0 +1,38 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot(t *testing.T) {
+	a := assert.New(t)
+	attrs := map[string]interface{}{"train.data_snapshot": "oss://bucket/iris/"}
+	a.NoError(Attributes.Validate(attrs))
+	a.NoError(Check(attrs))
+	a.Equal("oss://bucket/iris", URI(attrs))
+	a.Equal("tfrecord", Format(attrs))
+
+	attrs["train.data_snapshot_format"] = "parquet"
+	a.NoError(Attributes.Validate(attrs))
+	a.Equal("parquet", Format(attrs))
+
+	a.Equal("", URI(map[string]interface{}{}))
+	a.Error(Attributes.Validate(map[string]interface{}{"train.data_snapshot": "bucket/iris"}))
+	a.Error(Attributes.Validate(map[string]interface{}{"train.data_snapshot": "oss://bucket/iris", "train.data_snapshot_format": "csv"}))
+	a.Error(Check(map[string]interface{}{"train.data_snapshot_format": "parquet"}))
+}
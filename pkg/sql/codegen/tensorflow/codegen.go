@@ -24,6 +24,11 @@ import (
 	"sqlflow.org/sqlflow/pkg/ir"
 	pb "sqlflow.org/sqlflow/pkg/proto"
 	"sqlflow.org/sqlflow/pkg/sql/codegen/attribute"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/datacheck"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/evaluation"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/imbalance"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/prediction"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/snapshot"
 )
 
 var commonAttributes = attribute.Dictionary{
@@ -54,6 +59,9 @@ Specify the dataset for validation.
 example: "SELECT * FROM iris.train LIMIT 100"`, nil},
 	"validation.steps": {attribute.Int, 1, `[default=1]
 Specify steps for validation.`, attribute.IntLowerBoundChecker(1, true)},
+	"model.export_format": {attribute.String, nil, `[default=""]
+Convert the trained model into the format, and save the converted model file model.onnx along with the model.
+possible values: "onnx"`, attribute.StringChoicesChecker("onnx")},
 }
 var distributedTrainingAttributes = attribute.Dictionary{
 	"train.num_ps":        {attribute.Int, 0, "", nil},
@@ -67,6 +75,65 @@ var distributedTrainingAttributes = attribute.Dictionary{
 	"train.evaluator_gpu": {attribute.Int, 0, "", nil},
 }
 
+// tensorBoardDir is where the Keras models write the TensorBoard event
+// files in the working directory, so the files are saved with the model.
+// The estimators write them into their model_dir.
+const tensorBoardDir = "tensorboard"
+
+// The strategies of the distributed training out of PAI, see
+// python/sqlflow_submitter/tensorflow/distributed.py.
+const (
+	multiWorkerMirrored = "multi_worker_mirrored"
+	parameterServer     = "parameter_server"
+)
+
+// distributedAttributes are the attributes of the distributed training
+// out of PAI, which runs in the pods of the TFJob of the workflow step.
+var distributedAttributes = attribute.Dictionary{
+	"train.num_workers": {attribute.Int, 1, `[default=1]
+Number of workers of the distributed training, 1 means stand-alone training if train.num_ps is 0. The distributed training runs in the workflow mode.
+range: [1, 128]`, attribute.IntRangeChecker(1, 128, true, true)},
+	"train.num_ps": {attribute.Int, 0, `[default=0]
+Number of parameter servers of the distributed training.
+range: [0, 128]`, attribute.IntRangeChecker(0, 128, true, true)},
+	"train.strategy": {attribute.String, nil, `[default="multi_worker_mirrored" if train.num_ps is 0, "parameter_server" otherwise]
+The strategy of the distributed training.
+possible values: "multi_worker_mirrored", "parameter_server"`, attribute.StringChoicesChecker(multiWorkerMirrored, parameterServer)},
+}
+
+// DistributedReplicas returns the numbers of the workers and the
+// parameter servers of the distributed training set by attrs, which are 1
+// and 0 for the stand-alone training.
+func DistributedReplicas(attrs map[string]interface{}) (int, int) {
+	workers, ok := attrs["train.num_workers"].(int)
+	if !ok {
+		workers = 1
+	}
+	ps, _ := attrs["train.num_ps"].(int)
+	return workers, ps
+}
+
+// distributedStrategy returns the strategy of the distributed training
+// set by attrs, or "" for the stand-alone training.
+func distributedStrategy(attrs map[string]interface{}) (string, error) {
+	workers, ps := DistributedReplicas(attrs)
+	if workers == 1 && ps == 0 {
+		return "", nil
+	}
+	strategy, _ := attrs["train.strategy"].(string)
+	switch {
+	case strategy == "" && ps > 0:
+		return parameterServer, nil
+	case strategy == "":
+		return multiWorkerMirrored, nil
+	case strategy == multiWorkerMirrored && ps > 0:
+		return "", fmt.Errorf("train.strategy=%q doesn't use parameter servers, received train.num_ps=%d", strategy, ps)
+	case strategy == parameterServer && ps == 0:
+		return "", fmt.Errorf("train.strategy=%q requires train.num_ps > 0", strategy)
+	}
+	return strategy, nil
+}
+
 func intArrayToJSONString(ia []int) string {
 	return strings.Join(strings.Split(fmt.Sprint(ia), " "), ",")
 }
@@ -214,6 +281,26 @@ func IsPAI() bool {
 	return os.Getenv("SQLFLOW_submitter") == "pai" || os.Getenv("SQLFLOW_submitter") == "alisa"
 }
 
+// IsChief returns false if the program runs in a pod of the distributed
+// training other than the chief, by the TF_CONFIG set by the TFJob of the
+// workflow step. Only the chief saves the trained model.
+func IsChief() bool {
+	var config struct {
+		Cluster map[string][]string `json:"cluster"`
+		Task    struct {
+			Type  string `json:"type"`
+			Index int    `json:"index"`
+		} `json:"task"`
+	}
+	if e := json.Unmarshal([]byte(os.Getenv("TF_CONFIG")), &config); e != nil {
+		return true
+	}
+	if _, ok := config.Cluster["chief"]; ok {
+		return config.Task.Type == "chief"
+	}
+	return config.Task.Type == "worker" && config.Task.Index == 0
+}
+
 func setDefaultOptimizer(trainStmt *ir.TrainStmt, optimizerParamName string) {
 	// TODO(shendiaomo): Try to get the default value from the python `inspect` module instead of hard coding
 	defaultValue := "Adagrad" // Defaults to DNN with a single optimizer parameter
@@ -307,22 +394,18 @@ func constructLosses(trainStmt *ir.TrainStmt) {
 	}
 }
 
-// InitializeAttributes initializes the attributes of TensorFlow and does type checking for them
-func InitializeAttributes(trainStmt *ir.TrainStmt) error {
+// AttributeDictionary returns the attributes of TRAIN by the TensorFlow
+// estimator.
+func AttributeDictionary(estimator string) attribute.Dictionary {
 	attribute.ExtractDocStringsOnce()
-	commonAttributes.FillDefaults(trainStmt.Attributes)
-
-	modelAttr := attribute.NewDictionaryFromModelDefinition(trainStmt.Estimator, "model.")
-	// TODO(shendiaomo): Restrict optimizer parameters to the available set
-	constructOptimizers(trainStmt)
-	constructLosses(trainStmt)
+	modelAttr := attribute.NewDictionaryFromModelDefinition(estimator, "model.")
 	if len(modelAttr) == 0 {
 		// TODO(shendiaomo): Use the same mechanism as `sqlflow_models` to extract parameters automatically
 		// Unknown custom models
 		modelAttr.Update(attribute.Dictionary{"model.*": {attribute.Unknown, nil, "Any model parameters defined in custom models", nil}})
 	}
-	attrValidator := modelAttr.Update(commonAttributes)
-	if strings.HasPrefix(trainStmt.Estimator, "sqlflow_models.") {
+	modelAttr.Update(commonAttributes).Update(imbalance.Attributes).Update(evaluation.SplitAttributes).Update(datacheck.Attributes).Update(snapshot.Attributes)
+	if strings.HasPrefix(estimator, "sqlflow_models.") {
 		// Special attributes defined as global variables in `sqlflow_models`
 		modelAttr.Update(attribute.Dictionary{
 			"model.optimizer": {attribute.Unknown, nil, "Specify optimizer", nil},
@@ -330,9 +413,34 @@ func InitializeAttributes(trainStmt *ir.TrainStmt) error {
 			"model.*":         {attribute.Unknown, nil, "Any model parameters defined in custom models", nil}})
 	}
 	if IsPAI() {
-		modelAttr.Update(distributedTrainingAttributes)
+		return modelAttr.Update(distributedTrainingAttributes)
+	}
+	return modelAttr.Update(distributedAttributes)
+}
+
+// InitializeAttributes initializes the attributes of TensorFlow and does type checking for them
+func InitializeAttributes(trainStmt *ir.TrainStmt) error {
+	commonAttributes.FillDefaults(trainStmt.Attributes)
+	imbalance.Attributes.FillDefaults(trainStmt.Attributes)
+
+	attrValidator := AttributeDictionary(trainStmt.Estimator)
+	// TODO(shendiaomo): Restrict optimizer parameters to the available set
+	constructOptimizers(trainStmt)
+	constructLosses(trainStmt)
+	if err := attrValidator.Validate(trainStmt.Attributes); err != nil {
+		return err
+	}
+	if err := imbalance.Check(trainStmt.Attributes); err != nil {
+		return err
+	}
+	if err := snapshot.Check(trainStmt.Attributes); err != nil {
+		return err
+	}
+	if IsPAI() {
+		return nil
 	}
-	return attrValidator.Validate(trainStmt.Attributes)
+	_, err := distributedStrategy(trainStmt.Attributes)
+	return err
 }
 
 func categorizeAttributes(trainStmt *ir.TrainStmt) (trainParams, validateParams, modelParams map[string]interface{}) {
@@ -344,7 +452,8 @@ func categorizeAttributes(trainStmt *ir.TrainStmt) (trainParams, validateParams,
 		if strings.HasPrefix(attrKey, "train.") {
 			trainParams[strings.Replace(attrKey, "train.", "", 1)] = attr
 		}
-		if strings.HasPrefix(attrKey, "model.") {
+		// model.export_format is for SQLFlow instead of the model.
+		if strings.HasPrefix(attrKey, "model.") && attrKey != "model.export_format" {
 			modelParams[strings.Replace(attrKey, "model.", "", 1)] = attr
 		}
 		if strings.HasPrefix(attrKey, "validation.") {
@@ -395,6 +504,23 @@ func Train(trainStmt *ir.TrainStmt, session *pb.Session) (string, error) {
 		paiTrainTable = trainStmt.TmpTrainTable
 		paiValidateTable = trainStmt.TmpValidateTable
 	}
+	exportFormat, _ := trainStmt.Attributes["model.export_format"].(string)
+	if IsPAI() && exportFormat != "" {
+		return "", fmt.Errorf("model.export_format is not supported on PAI")
+	}
+	if IsPAI() && imbalance.IsSet(trainStmt.Attributes) {
+		return "", fmt.Errorf("train.class_weight and train.oversample_minority are not supported on PAI")
+	}
+	if IsPAI() && snapshot.URI(trainStmt.Attributes) != "" {
+		return "", fmt.Errorf("train.data_snapshot is not supported on PAI")
+	}
+	strategy, tbDir := "", ""
+	if !IsPAI() {
+		if strategy, err = distributedStrategy(trainStmt.Attributes); err != nil {
+			return "", err
+		}
+		tbDir = tensorBoardDir
+	}
 
 	filler := trainFiller{
 		DataSource:        session.DbConnStr,
@@ -411,6 +537,13 @@ func Train(trainStmt *ir.TrainStmt, session *pb.Session) (string, error) {
 		IsPAI:             IsPAI(),
 		PAITrainTable:     paiTrainTable,
 		PAIValidateTable:  paiValidateTable,
+		ExportFormat:      exportFormat,
+		Strategy:          strategy,
+		ClassWeight:       imbalance.ClassWeight(trainStmt.Attributes),
+		Oversample:        imbalance.Oversample(trainStmt.Attributes),
+		SnapshotURI:       snapshot.URI(trainStmt.Attributes),
+		SnapshotFormat:    snapshot.Format(trainStmt.Attributes),
+		TensorBoardDir:    tbDir,
 	}
 	var program bytes.Buffer
 	var trainTemplate = template.Must(template.New("Train").Funcs(template.FuncMap{
@@ -464,6 +597,9 @@ func Pred(predStmt *ir.PredictStmt, session *pb.Session) (string, error) {
 		HDFSPass:          session.HdfsPass,
 		IsPAI:             IsPAI(),
 		PAIPredictTable:   paiPredictTable,
+		WriteBatchSize:    prediction.BatchSize(predStmt.Attributes),
+		NumWriters:        prediction.NumWriters(predStmt.Attributes),
+		Partition:         prediction.Partition(predStmt.Attributes),
 	}
 	var program bytes.Buffer
 	var predTemplate = template.Must(template.New("Pred").Funcs(template.FuncMap{
@@ -529,9 +665,15 @@ func Evaluate(stmt *ir.EvaluateStmt, session *pb.Session) (string, error) {
 	}
 	labelFM := stmt.TrainStmt.Label.GetFieldDesc()[0]
 	validationParams := resolveParams(stmt.Attributes, "validation.")
-	if len(validationParams) == 0 {
-		// add default validation.metrics = "Accuracy".
-		validationParams["metrics"] = "Accuracy"
+	// add default validation.metrics = "Accuracy".
+	params, err := evaluation.Resolve(stmt, "Accuracy")
+	if err != nil {
+		return "", err
+	}
+	validationParams["metrics"] = params.MetricNames()
+	evalParams, err := params.JSON()
+	if err != nil {
+		return "", err
 	}
 
 	filler := evaluateFiller{
@@ -543,6 +685,7 @@ func Evaluate(stmt *ir.EvaluateStmt, session *pb.Session) (string, error) {
 		Y:                 labelFM,
 		ModelParams:       modelParams,
 		ValidationParams:  validationParams,
+		EvalParamsJSON:    evalParams,
 		Save:              "model_save",
 		ResultTable:       stmt.Into,
 		HDFSNameNodeAddr:  session.HdfsNamenodeAddr,
@@ -14,6 +14,7 @@
 package tensorflow
 
 import (
+	"os"
 	"regexp"
 	"testing"
 
@@ -28,8 +29,9 @@ func mockSession() *pb.Session {
 func TestTrainCodegen(t *testing.T) {
 	a := assert.New(t)
 	tir := ir.MockTrainStmt(false)
-	_, err := Train(tir, mockSession())
+	code, err := Train(tir, mockSession())
 	a.NoError(err)
+	a.Contains(code, `tensorboard_dir="tensorboard"`)
 
 	pir := ir.MockPredStmt(tir)
 
@@ -43,7 +45,7 @@ func TestTrainCodegen(t *testing.T) {
 		HdfsUser:         "sqlflow_admin",
 		HdfsPass:         "sqlflow_pass",
 	}
-	code, err := Pred(pir, sess)
+	code, err = Pred(pir, sess)
 	a.NoError(err)
 
 	r, _ := regexp.Compile(`hdfs_user="(.*)"`)
@@ -85,3 +87,131 @@ func TestTrainWithOptimizer(t *testing.T) {
 	a.NoError(err)
 	a.Equal(tir.Attributes["model.optimizer"], "RMSprop(learning_rate=0.002, )")
 }
+
+func TestTrainExportONNX(t *testing.T) {
+	a := assert.New(t)
+	tir := ir.MockTrainStmt(false)
+	code, err := Train(tir, mockSession())
+	a.NoError(err)
+	a.NotContains(code, "export_tensorflow")
+
+	tir.Attributes["model.export_format"] = "onnx"
+	a.NoError(InitializeAttributes(tir))
+	code, err = Train(tir, mockSession())
+	a.NoError(err)
+	a.Contains(code, "export_tensorflow()")
+	a.NotContains(code, "export_format")
+
+	tir.Attributes["model.export_format"] = "pmml"
+	a.Error(InitializeAttributes(tir))
+}
+
+func TestTrainDistributed(t *testing.T) {
+	a := assert.New(t)
+	tir := ir.MockTrainStmt(false)
+	a.NoError(InitializeAttributes(tir))
+	code, err := Train(tir, mockSession())
+	a.NoError(err)
+	a.Contains(code, `strategy=""`)
+
+	tir.Attributes["train.num_workers"] = 3
+	a.NoError(InitializeAttributes(tir))
+	code, err = Train(tir, mockSession())
+	a.NoError(err)
+	a.Contains(code, `strategy="multi_worker_mirrored"`)
+
+	tir.Attributes["train.num_ps"] = 2
+	a.NoError(InitializeAttributes(tir))
+	code, err = Train(tir, mockSession())
+	a.NoError(err)
+	a.Contains(code, `strategy="parameter_server"`)
+	workers, ps := DistributedReplicas(tir.Attributes)
+	a.Equal(3, workers)
+	a.Equal(2, ps)
+
+	tir.Attributes["train.strategy"] = "multi_worker_mirrored"
+	a.Error(InitializeAttributes(tir))
+	tir.Attributes["train.num_ps"] = 0
+	tir.Attributes["train.strategy"] = "parameter_server"
+	a.Error(InitializeAttributes(tir))
+	tir.Attributes["train.strategy"] = "all_reduce"
+	a.Error(InitializeAttributes(tir))
+}
+
+func TestAttributeDictionary(t *testing.T) {
+	a := assert.New(t)
+	dict := AttributeDictionary("DNNClassifier")
+	a.Contains(dict, "model.hidden_units")
+	a.Contains(dict, "validation.select")
+	a.Contains(dict, "train.class_weight")
+	a.Contains(AttributeDictionary("my_models.MyModel"), "model.*")
+
+	tir := ir.MockTrainStmt(false)
+	tir.Attributes["model.hiden_units"] = []int{10, 20}
+	err := InitializeAttributes(tir)
+	a.Error(err)
+	a.Contains(err.Error(), "did you mean model.hidden_units?")
+}
+
+func TestTrainWithImbalance(t *testing.T) {
+	a := assert.New(t)
+	tir := ir.MockTrainStmt(false)
+	a.NoError(InitializeAttributes(tir))
+	code, err := Train(tir, mockSession())
+	a.NoError(err)
+	a.Contains(code, "class_weight=None")
+	a.Contains(code, `oversample_minority="false" == "true"`)
+
+	tir.Attributes["train.class_weight"] = "balanced"
+	a.NoError(InitializeAttributes(tir))
+	code, err = Train(tir, mockSession())
+	a.NoError(err)
+	a.Contains(code, `class_weight="balanced"`)
+
+	tir.Attributes["train.oversample_minority"] = true
+	a.Error(InitializeAttributes(tir))
+	tir.Attributes["train.class_weight"] = ""
+	a.NoError(InitializeAttributes(tir))
+	code, err = Train(tir, mockSession())
+	a.NoError(err)
+	a.Contains(code, `oversample_minority="true" == "true"`)
+
+	tir.Attributes["train.class_weight"] = "0:1,1:-2"
+	tir.Attributes["train.oversample_minority"] = false
+	a.Error(InitializeAttributes(tir))
+}
+
+func TestTrainWithDataSnapshot(t *testing.T) {
+	a := assert.New(t)
+	tir := ir.MockTrainStmt(false)
+	a.NoError(InitializeAttributes(tir))
+	code, err := Train(tir, mockSession())
+	a.NoError(err)
+	a.Contains(code, `snapshot_uri=""`)
+
+	tir.Attributes["train.data_snapshot"] = "oss://bucket/iris/"
+	tir.Attributes["train.data_snapshot_format"] = "parquet"
+	a.NoError(InitializeAttributes(tir))
+	code, err = Train(tir, mockSession())
+	a.NoError(err)
+	a.Contains(code, `snapshot_uri="oss://bucket/iris"`)
+	a.Contains(code, `snapshot_format="parquet"`)
+
+	delete(tir.Attributes, "train.data_snapshot")
+	a.Error(InitializeAttributes(tir))
+}
+
+func TestIsChief(t *testing.T) {
+	a := assert.New(t)
+	defer os.Unsetenv("TF_CONFIG")
+	os.Unsetenv("TF_CONFIG")
+	a.True(IsChief())
+	os.Setenv("TF_CONFIG", `{"cluster": {"chief": ["c:2222"], "worker": ["w:2222"]}, "task": {"type": "chief", "index": 0}}`)
+	a.True(IsChief())
+	os.Setenv("TF_CONFIG", `{"cluster": {"chief": ["c:2222"], "worker": ["w:2222"]}, "task": {"type": "worker", "index": 0}}`)
+	a.False(IsChief())
+	os.Setenv("TF_CONFIG", `{"cluster": {"worker": ["w0:2222", "w1:2222"]}, "task": {"type": "worker", "index": 0}}`)
+	a.True(IsChief())
+	os.Setenv("TF_CONFIG", `{"cluster": {"worker": ["w0:2222"], "ps": ["p:2222"]}, "task": {"type": "ps", "index": 0}}`)
+	a.False(IsChief())
+}
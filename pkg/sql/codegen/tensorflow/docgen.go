@@ -16,12 +16,15 @@ package tensorflow
 import (
 	"bytes"
 	"text/template"
+
+	"sqlflow.org/sqlflow/pkg/sql/codegen/attribute"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/imbalance"
 )
 
 // DocGenInMarkdown generates the doc of the XGBoost in Markdown format.
 func DocGenInMarkdown() string {
 	var doc bytes.Buffer
-	docTemplate.Execute(&doc, commonAttributes.GenerateTableInHTML())
+	docTemplate.Execute(&doc, attribute.Dictionary{}.Update(commonAttributes).Update(distributedAttributes).Update(imbalance.Attributes).GenerateTableInHTML())
 
 	return doc.String()
 }
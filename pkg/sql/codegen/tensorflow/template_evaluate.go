@@ -26,6 +26,7 @@ type evaluateFiller struct {
 	Y                 *ir.FieldDesc
 	ModelParams       map[string]interface{}
 	ValidationParams  map[string]interface{}
+	EvalParamsJSON    string
 	Save              string
 	HDFSNameNodeAddr  string
 	HiveLocation      string
@@ -36,6 +37,7 @@ type evaluateFiller struct {
 }
 
 const tfEvaluateTemplateText = `
+import json
 import tensorflow as tf
 from sqlflow_submitter.tensorflow.evaluate import evaluate
 from sqlflow_submitter.tensorflow.get_tf_version import tf_is_version2
@@ -65,7 +67,13 @@ feature_metas["{{$value.Name}}"] = {
     "dtype": "{{$value.DType | DTypeToString}}",
     "delimiter": "{{$value.Delimiter}}",
     "shape": {{$value.Shape | intArrayToJSONString}},
-    "is_sparse": "{{$value.IsSparse}}" == "true"
+    "is_sparse": "{{$value.IsSparse}}" == "true",
+    "time_parts": [{{range $value.TimeParts}}"{{.}}",{{end}}],
+    "cyclical": "{{$value.Cyclical}}" == "true",
+    "is_array": "{{$value.IsArray}}" == "true",
+    "json_paths": [{{range $value.JSONPaths}}{{printf "%q" .}},{{end}}],
+    "tokenizer": "{{$value.Tokenizer}}",{{if $value.Tokenizer}}
+    "vocabulary": [{{range $k, $v := $value.Vocabulary}}{{printf "%q" $k}},{{end}}],{{end}}
 }
 {{end}}
 {{end}}
@@ -95,6 +103,7 @@ evaluate(datasource="{{.DataSource}}",
          label_meta=label_meta,
          model_params=model_params,
          validation_metrics="{{index .ValidationParams "metrics"}}".split(","),
+         evaluation_params=json.loads('''{{.EvalParamsJSON}}'''),
          save="{{.Save}}",
          batch_size=1,
          validation_steps=None,
@@ -62,7 +62,13 @@ feature_metas["{{$value.Name}}"] = {
     "dtype": "{{$value.DType | DTypeToString}}",
     "delimiter": "{{$value.Delimiter}}",
     "shape": {{$value.Shape | intArrayToJSONString}},
-    "is_sparse": "{{$value.IsSparse}}" == "true"
+    "is_sparse": "{{$value.IsSparse}}" == "true",
+    "time_parts": [{{range $value.TimeParts}}"{{.}}",{{end}}],
+    "cyclical": "{{$value.Cyclical}}" == "true",
+    "is_array": "{{$value.IsArray}}" == "true",
+    "json_paths": [{{range $value.JSONPaths}}{{printf "%q" .}},{{end}}],
+    "tokenizer": "{{$value.Tokenizer}}",{{if $value.Tokenizer}}
+    "vocabulary": [{{range $k, $v := $value.Vocabulary}}{{printf "%q" $k}},{{end}}],{{end}}
 }
 {{end}}
 {{end}}
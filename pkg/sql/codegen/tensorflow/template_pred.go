@@ -32,6 +32,9 @@ type predFiller struct {
 	HDFSPass          string
 	IsPAI             bool
 	PAIPredictTable   string
+	WriteBatchSize    int
+	NumWriters        int
+	Partition         string
 }
 
 const tfPredTemplateText = `
@@ -64,7 +67,13 @@ feature_metas["{{$value.Name}}"] = {
     "dtype": "{{$value.DType | DTypeToString}}",
     "delimiter": "{{$value.Delimiter}}",
     "shape": {{$value.Shape | intArrayToJSONString}},
-    "is_sparse": "{{$value.IsSparse}}" == "true"
+    "is_sparse": "{{$value.IsSparse}}" == "true",
+    "time_parts": [{{range $value.TimeParts}}"{{.}}",{{end}}],
+    "cyclical": "{{$value.Cyclical}}" == "true",
+    "is_array": "{{$value.IsArray}}" == "true",
+    "json_paths": [{{range $value.JSONPaths}}{{printf "%q" .}},{{end}}],
+    "tokenizer": "{{$value.Tokenizer}}",{{if $value.Tokenizer}}
+    "vocabulary": [{{range $k, $v := $value.Vocabulary}}{{printf "%q" $k}},{{end}}],{{end}}
 }
 {{end}}
 {{end}}
@@ -101,5 +110,8 @@ pred(datasource="{{.DataSource}}",
      hdfs_user="{{.HDFSUser}}",
      hdfs_pass="{{.HDFSPass}}",
      is_pai="{{.IsPAI}}" == "true",
-     pai_table="{{.PAIPredictTable}}")
+     pai_table="{{.PAIPredictTable}}",
+     write_batch_size={{.WriteBatchSize}},
+     num_writers={{.NumWriters}},
+     partition="{{.Partition}}")
 `
@@ -30,6 +30,13 @@ type trainFiller struct {
 	IsPAI             bool
 	PAITrainTable     string
 	PAIValidateTable  string
+	ExportFormat      string
+	Strategy          string
+	ClassWeight       string
+	Oversample        bool
+	SnapshotURI       string
+	SnapshotFormat    string
+	TensorBoardDir    string
 }
 
 const tfTrainTemplateText = `
@@ -76,7 +83,13 @@ feature_metas["{{$value.Name}}"] = {
     "dtype": "{{$value.DType | DTypeToString}}",
     "delimiter": "{{$value.Delimiter}}",
     "shape": {{$value.Shape | intArrayToJSONString}},
-    "is_sparse": "{{$value.IsSparse}}" == "true"
+    "is_sparse": "{{$value.IsSparse}}" == "true",
+    "time_parts": [{{range $value.TimeParts}}"{{.}}",{{end}}],
+    "cyclical": "{{$value.Cyclical}}" == "true",
+    "is_array": "{{$value.IsArray}}" == "true",
+    "json_paths": [{{range $value.JSONPaths}}{{printf "%q" .}},{{end}}],
+    "tokenizer": "{{$value.Tokenizer}}",{{if $value.Tokenizer}}
+    "vocabulary": [{{range $k, $v := $value.Vocabulary}}{{printf "%q" $k}},{{end}}],{{end}}
 }
 {{end}}
 {{end}}
@@ -134,5 +147,15 @@ train(datasource="{{.DataSource}}",
       log_every_n_iter={{index .TrainParams "log_every_n_iter" | attrToPythonValue}},
       is_pai="{{.IsPAI}}" == "true",
       pai_table="{{.PAITrainTable}}",
-      pai_val_table="{{.PAIValidateTable}}")
+      pai_val_table="{{.PAIValidateTable}}",
+      strategy="{{.Strategy}}",
+      class_weight={{.ClassWeight}},
+      oversample_minority="{{.Oversample}}" == "true",
+      snapshot_uri="{{.SnapshotURI}}",
+      snapshot_format="{{.SnapshotFormat}}",
+      tensorboard_dir="{{.TensorBoardDir}}")
+{{if eq .ExportFormat "onnx"}}
+from sqlflow_submitter.onnx import export_tensorflow
+export_tensorflow()
+{{end}}
 `
@@ -0,0 +1,163 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tuning
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"sqlflow.org/sqlflow/pkg/sql/codegen/attribute"
+)
+
+// The algorithms to search the hyperparameters.
+const (
+	Grid     = "grid"
+	Random   = "random"
+	Bayesian = "bayesian"
+)
+
+// Attributes are the attributes of TRAIN of the hyperparameter tuning,
+// for the code generators that support it.
+var Attributes = attribute.Dictionary{
+	"tuning.algorithm": {attribute.String, nil, `[default=""]
+The algorithm to search the hyperparameters valued by lists of candidates, like num_leaves=[15, 31, 63], or model.hidden_units=[[64], [128, 64]] for the attributes of lists. Not set means no tuning.
+possible values: "grid", "random", "bayesian"`, attribute.StringChoicesChecker(Grid, Random, Bayesian)},
+	"tuning.max_trials": {attribute.Int, 10, `[default=10]
+The max number of the trials, "grid" runs all the combinations of the candidates if there are fewer.
+range: [1, Infinity]`, attribute.IntLowerBoundChecker(1, true)},
+	"tuning.metric": {attribute.String, nil, `[default="accuracy_score" for the classifiers, "RMSE" for the regressors]
+The metric on the validation data to choose the best trial, the smaller the better for "RMSE", "MAE", and the losses, the larger the better for the others.`, nil},
+	"tuning.random_state": {attribute.Int, 0, `[default=0]
+Seed of the random numbers of the "random" and "bayesian" algorithms.`, nil},
+}
+
+// IsTuning returns true if attrs sets tuning.algorithm.
+func IsTuning(attrs map[string]interface{}) bool {
+	algorithm, ok := attrs["tuning.algorithm"].(string)
+	return ok && algorithm != ""
+}
+
+// SplitSearchSpace returns the attributes in attrs valued by lists of
+// candidates if attrs sets tuning.algorithm, and replaces each of them
+// in attrs by its first candidate, so attrs are valid for the code
+// generator. Each candidate is checked by d. The attributes of the
+// type attribute.IntList are lists of candidates only if they are
+// lists of lists, like model.hidden_units=[[64], [128, 64]].
+func SplitSearchSpace(d attribute.Dictionary, attrs map[string]interface{}) (map[string][]interface{}, error) {
+	if !IsTuning(attrs) {
+		return nil, nil
+	}
+	space := map[string][]interface{}{}
+	for k, v := range attrs {
+		candidates, ok := v.([]interface{})
+		if !ok || strings.HasPrefix(k, "tuning.") {
+			continue
+		}
+		if len(candidates) == 0 {
+			return nil, fmt.Errorf("no candidates of %s to tune", k)
+		}
+		if desc, ok := d[k]; ok && desc.Type == attribute.IntList {
+			if _, ok := candidates[0].([]interface{}); !ok {
+				continue
+			}
+		}
+		for _, c := range candidates {
+			if e := d.Validate(map[string]interface{}{k: c}); e != nil {
+				return nil, fmt.Errorf("bad candidate of %s: %v", k, e)
+			}
+		}
+		space[k] = candidates
+		attrs[k] = candidates[0]
+	}
+	if len(space) == 0 {
+		return nil, fmt.Errorf("tuning.algorithm requires some attributes valued by lists of candidates, like num_leaves=[15, 31, 63]")
+	}
+	return space, nil
+}
+
+// Dimension is an attribute to tune. Group and Name locate the
+// parameter of the attribute in the Python training function, like the
+// num_boost_round in train_params for train.num_boost_round.
+type Dimension struct {
+	Attribute  string        `json:"attribute"`
+	Group      string        `json:"group"`
+	Name       string        `json:"name"`
+	Candidates []interface{} `json:"candidates"`
+}
+
+// Params are the parameters of the hyperparameter tuning passed to the
+// Python training functions as tuning_params.
+type Params struct {
+	Algorithm   string      `json:"algorithm"`
+	MaxTrials   int         `json:"max_trials"`
+	Metric      string      `json:"metric"`
+	RandomState int         `json:"random_state"`
+	SearchSpace []Dimension `json:"search_space"`
+}
+
+// Resolve returns the parameters of the tuning of space, the result of
+// SplitSearchSpace, in attrs, or nil if space is empty. groups maps the
+// prefixes of the attributes to the arguments of the Python training
+// function where the parameters are, like {"train.": "train_params", "":
+// "model_params"}, and the longest matched prefix is removed from the
+// names of the parameters.
+func Resolve(attrs map[string]interface{}, space map[string][]interface{}, groups map[string]string, defaultMetric string) (*Params, error) {
+	if len(space) == 0 {
+		return nil, nil
+	}
+	p := &Params{Metric: defaultMetric}
+	p.Algorithm, _ = attrs["tuning.algorithm"].(string)
+	p.MaxTrials, _ = attrs["tuning.max_trials"].(int)
+	p.RandomState, _ = attrs["tuning.random_state"].(int)
+	if m, ok := attrs["tuning.metric"].(string); ok && m != "" {
+		p.Metric = m
+	}
+	for k, candidates := range space {
+		prefix, matched := "", false
+		for pp := range groups {
+			if strings.HasPrefix(k, pp) && len(pp) >= len(prefix) {
+				prefix, matched = pp, true
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("unsupported attribute %s to tune", k)
+		}
+		p.SearchSpace = append(p.SearchSpace, Dimension{k, groups[prefix], k[len(prefix):], candidates})
+	}
+	sort.Slice(p.SearchSpace, func(i, j int) bool { return p.SearchSpace[i].Attribute < p.SearchSpace[j].Attribute })
+	return p, nil
+}
+
+// JSON returns the parameters as tuning_params, or "{}" for nil, which
+// means no tuning.
+func (p *Params) JSON() (string, error) {
+	if p == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(p)
+	return string(b), err
+}
+
+// CheckValidation returns an error if there is space to tune but no
+// validation data to score the trials, which is validationSelect, or
+// the k-fold cross-validation of kfold folds, 0 for the code generators
+// without the cross-validation.
+func CheckValidation(space map[string][]interface{}, validationSelect string, kfold int) error {
+	if len(space) > 0 && validationSelect == "" && kfold < 2 {
+		return fmt.Errorf("tuning.algorithm requires validation.select or validation.kfold to score the trials")
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tuning
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/attribute"
+)
+
+func TestSplitSearchSpace(t *testing.T) {
+	a := assert.New(t)
+	d := attribute.Dictionary{
+		"num_leaves":         {attribute.Int, nil, "", attribute.IntLowerBoundChecker(2, true)},
+		"model.hidden_units": {attribute.IntList, []int{10, 10}, "", nil},
+		"train.batch_size":   {attribute.Int, 64, "", nil},
+	}.Update(Attributes)
+
+	attrs := map[string]interface{}{"num_leaves": []interface{}{15, 31}}
+	space, err := SplitSearchSpace(d, attrs)
+	a.NoError(err)
+	a.Nil(space)
+
+	attrs = map[string]interface{}{
+		"tuning.algorithm":   "random",
+		"num_leaves":         []interface{}{15, 31},
+		"model.hidden_units": []interface{}{[]interface{}{64}, []interface{}{128, 64}},
+		"train.batch_size":   32,
+	}
+	Attributes.FillDefaults(attrs)
+	space, err = SplitSearchSpace(d, attrs)
+	a.NoError(err)
+	a.Equal(2, len(space))
+	a.Equal(15, attrs["num_leaves"])
+	a.Equal([]interface{}{64}, attrs["model.hidden_units"])
+	a.NoError(d.Validate(attrs))
+
+	groups := map[string]string{"": "model_params", "model.": "model_params", "train.": "train_params"}
+	p, err := Resolve(attrs, space, groups, "accuracy_score")
+	a.NoError(err)
+	s, err := p.JSON()
+	a.NoError(err)
+	a.Equal(`{"algorithm":"random","max_trials":10,"metric":"accuracy_score","random_state":0,"search_space":[{"attribute":"model.hidden_units","group":"model_params","name":"hidden_units","candidates":[[64],[128,64]]},{"attribute":"num_leaves","group":"model_params","name":"num_leaves","candidates":[15,31]}]}`, s)
+
+	// A list of integers is a value of model.hidden_units but not candidates.
+	attrs = map[string]interface{}{"tuning.algorithm": "grid", "model.hidden_units": []interface{}{64, 32}}
+	_, err = SplitSearchSpace(d, attrs)
+	a.Error(err)
+	attrs = map[string]interface{}{"tuning.algorithm": "grid", "num_leaves": []interface{}{1, 31}}
+	_, err = SplitSearchSpace(d, attrs)
+	a.Error(err)
+
+	a.Error(CheckValidation(space, "", 0))
+	a.NoError(CheckValidation(space, "", 5))
+	a.NoError(CheckValidation(space, "SELECT * FROM iris.test", 0))
+	a.NoError(CheckValidation(nil, "", 0))
+
+	s, err = (*Params)(nil).JSON()
+	a.NoError(err)
+	a.Equal("{}", s)
+}
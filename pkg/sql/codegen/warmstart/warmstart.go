@@ -0,0 +1,49 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package warmstart
+
+import (
+	"fmt"
+
+	"sqlflow.org/sqlflow/pkg/sql/codegen/attribute"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/tuning"
+)
+
+// Dir is where the submitter loads the model to warm start from, under
+// the working directory of the training, see
+// python/sqlflow_submitter/warm_start.py.
+const Dir = "warm_start"
+
+// Attributes are the attributes of TRAIN of the warm-start training, for
+// the code generators that support it.
+var Attributes = attribute.Dictionary{
+	"train.warm_start": {attribute.String, nil, `[default=""]
+The saved model to continue training on the training data, like "sqlflow_models.my_model", instead of training from scratch. The model should be trained by the same estimator on the same columns. Not set means training from scratch.`, nil},
+}
+
+// Model returns the saved model to warm start from in attrs, or "" for
+// training from scratch.
+func Model(attrs map[string]interface{}) string {
+	m, _ := attrs["train.warm_start"].(string)
+	return m
+}
+
+// Check returns an error if attrs warm start from a model and tune the
+// hyperparameters, because the trials of the tuning train from scratch.
+func Check(attrs map[string]interface{}) error {
+	if Model(attrs) != "" && tuning.IsTuning(attrs) {
+		return fmt.Errorf("train.warm_start doesn't work with tuning.algorithm")
+	}
+	return nil
+}
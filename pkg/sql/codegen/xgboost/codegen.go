@@ -23,7 +23,12 @@ import (
 	"sqlflow.org/sqlflow/pkg/ir"
 	pb "sqlflow.org/sqlflow/pkg/proto"
 	"sqlflow.org/sqlflow/pkg/sql/codegen/attribute"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/datacheck"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/evaluation"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/imbalance"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/prediction"
 	tf "sqlflow.org/sqlflow/pkg/sql/codegen/tensorflow"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/warmstart"
 )
 
 func getXGBoostObjectives() (ret []string) {
@@ -60,7 +65,10 @@ example: "SELECT * FROM boston.train LIMIT 8"`, nil},
 	"train.num_workers": {attribute.Int, 1, `[default=1]
 Number of workers for distributed train, 1 means stand-alone mode.
 range: [1, 128]`, attribute.IntRangeChecker(1, 128, true, true)},
-}
+	"model.export_format": {attribute.String, nil, `[default=""]
+Convert the trained model into the format, and save the converted model file model.onnx along with the model.
+possible values: "onnx"`, attribute.StringChoicesChecker("onnx")},
+}.Update(warmstart.Attributes).Update(imbalance.Attributes).Update(evaluation.SplitAttributes).Update(datacheck.Attributes)
 var fullAttrValidator = attribute.Dictionary{}
 
 func objectiveChecker(obj interface{}) error {
@@ -131,10 +139,19 @@ func resolveModelParams(ir *ir.TrainStmt) error {
 	return nil
 }
 
+// AttributeDictionary returns the attributes of TRAIN by the XGBoost
+// estimators. The caller shouldn't modify it.
+func AttributeDictionary() attribute.Dictionary {
+	return fullAttrValidator
+}
+
 // InitializeAttributes initializes the attributes of XGBoost and does type checking for them
 func InitializeAttributes(trainStmt *ir.TrainStmt) error {
 	attributeDictionary.FillDefaults(trainStmt.Attributes)
-	return fullAttrValidator.Validate(trainStmt.Attributes)
+	if err := fullAttrValidator.Validate(trainStmt.Attributes); err != nil {
+		return err
+	}
+	return imbalance.Check(trainStmt.Attributes)
 }
 
 func parseAttribute(attrs map[string]interface{}) map[string]map[string]interface{} {
@@ -175,21 +192,37 @@ func getFieldDesc(fcs []ir.FeatureColumn, l ir.FeatureColumn) ([]ir.FieldDesc, i
 
 // FieldMeta delicates Field Meta with Json format which used in code generator
 type FieldMeta struct {
-	FeatureName string `json:"feature_name"`
-	DType       string `json:"dtype"`
-	Delimiter   string `json:"delimiter"`
-	Shap        []int  `json:"shape"`
-	IsSparse    bool   `json:"is_sparse"`
+	FeatureName string   `json:"feature_name"`
+	DType       string   `json:"dtype"`
+	Delimiter   string   `json:"delimiter"`
+	Shap        []int    `json:"shape"`
+	IsSparse    bool     `json:"is_sparse"`
+	TimeParts   []string `json:"time_parts"`
+	Cyclical    bool     `json:"cyclical"`
+	IsArray     bool     `json:"is_array"`
+	JSONPaths   []string `json:"json_paths"`
+	Tokenizer   string   `json:"tokenizer"`
+	// Vocabulary is only set for the TEXT columns, whose ids are decoded by it
+	Vocabulary map[string]string `json:"vocabulary,omitempty"`
 }
 
 func resolveFieldMeta(desc *ir.FieldDesc) FieldMeta {
-	return FieldMeta{
+	fm := FieldMeta{
 		FeatureName: desc.Name,
 		DType:       tf.DTypeToString(desc.DType),
 		Delimiter:   desc.Delimiter,
 		Shap:        desc.Shape,
 		IsSparse:    desc.IsSparse,
+		TimeParts:   desc.TimeParts,
+		Cyclical:    desc.Cyclical,
+		IsArray:     desc.IsArray,
+		JSONPaths:   desc.JSONPaths,
+		Tokenizer:   desc.Tokenizer,
+	}
+	if desc.Tokenizer != "" {
+		fm.Vocabulary = desc.Vocabulary
 	}
+	return fm
 }
 
 func resolveFeatureMeta(fds []ir.FieldDesc) ([]byte, []string, error) {
@@ -249,6 +282,30 @@ func newTrainFiller(trainStmt *ir.TrainStmt, session *pb.Session, ossURI string)
 	if _, ok := params["train."]["num_workers"]; ok {
 		delete(params["train."], "num_workers")
 	}
+	// model.export_format is for SQLFlow instead of XGBoost.
+	exportFormat, _ := params[""]["model.export_format"].(string)
+	delete(params[""], "model.export_format")
+	if tf.IsPAI() && exportFormat != "" {
+		return nil, fmt.Errorf("model.export_format is not supported on PAI")
+	}
+	// The time-based split is done in the SELECT by SQLFlow.
+	delete(params[""], "validation.split_column")
+	delete(params[""], "validation.split_at")
+	// train.warm_start is for SQLFlow to load the model instead of XGBoost.
+	warmStart := warmstart.Model(trainStmt.Attributes) != ""
+	delete(params["train."], "warm_start")
+	if tf.IsPAI() && warmStart {
+		return nil, fmt.Errorf("train.warm_start is not supported on PAI")
+	}
+	// train.class_weight and train.oversample_minority are for SQLFlow
+	// to weight the rows instead of XGBoost.
+	classWeight := imbalance.ClassWeight(trainStmt.Attributes)
+	oversample := imbalance.Oversample(trainStmt.Attributes)
+	delete(params["train."], "class_weight")
+	delete(params["train."], "oversample_minority")
+	if tf.IsPAI() && imbalance.IsSet(trainStmt.Attributes) {
+		return nil, fmt.Errorf("train.class_weight and train.oversample_minority are not supported on PAI")
+	}
 
 	if len(trainStmt.Features) != 1 {
 		return nil, fmt.Errorf("xgboost only support 1 feature column set, received %d", len(trainStmt.Features))
@@ -296,7 +353,11 @@ func newTrainFiller(trainStmt *ir.TrainStmt, session *pb.Session, ossURI string)
 		Epoch:              epoch,
 		IsPAI:              tf.IsPAI(),
 		PAITrainTable:      paiTrainTable,
-		PAIValidateTable:   paiValidateTable}, nil
+		PAIValidateTable:   paiValidateTable,
+		ExportFormat:       exportFormat,
+		WarmStart:          warmStart,
+		ClassWeight:        classWeight,
+		Oversample:         oversample}, nil
 }
 
 // Pred generates a Python program for predict a xgboost model.
@@ -332,6 +393,9 @@ func Pred(predStmt *ir.PredictStmt, session *pb.Session) (string, error) {
 		HDFSPass:           session.HdfsPass,
 		IsPAI:              tf.IsPAI(),
 		PAITable:           paiPredictTable,
+		WriteBatchSize:     prediction.BatchSize(predStmt.Attributes),
+		NumWriters:         prediction.NumWriters(predStmt.Attributes),
+		Partition:          prediction.Partition(predStmt.Attributes),
 	}
 
 	var program bytes.Buffer
@@ -363,9 +427,14 @@ func Evaluate(evalStmt *ir.EvaluateStmt, session *pb.Session) (string, error) {
 	}
 
 	// NOTE(typhoonzero): support all metrices defined in https://scikit-learn.org/stable/modules/classes.html#sklearn-metrics-metrics
-	metricNames := "accuracy_score"
-	if metricNamesAttr, ok := evalStmt.Attributes["validation.metrics"]; ok {
-		metricNames = metricNamesAttr.(string)
+	// and the ones in sqlflow_submitter/evaluation.py.
+	params, err := evaluation.Resolve(evalStmt, "accuracy_score")
+	if err != nil {
+		return "", err
+	}
+	evalParams, err := params.JSON()
+	if err != nil {
+		return "", err
 	}
 
 	r := evalFiller{
@@ -374,7 +443,8 @@ func Evaluate(evalStmt *ir.EvaluateStmt, session *pb.Session) (string, error) {
 		FeatureMetaJSON:    string(f),
 		FeatureColumnNames: fs,
 		LabelMetaJSON:      string(l),
-		MetricNames:        metricNames,
+		MetricNames:        params.MetricNames(),
+		EvalParamsJSON:     evalParams,
 		ResultTable:        evalStmt.Into,
 		HDFSNameNodeAddr:   session.HdfsNamenodeAddr,
 		HiveLocation:       session.HiveLocation,
@@ -32,8 +32,8 @@ func TestParseAttribute(t *testing.T) {
 
 func TestAttributes(t *testing.T) {
 	a := assert.New(t)
-	a.Equal(10, len(attributeDictionary))
-	a.Equal(33, len(fullAttrValidator))
+	a.Equal(18, len(attributeDictionary))
+	a.Equal(41, len(fullAttrValidator))
 }
 
 func mockSession() *pb.Session {
@@ -68,6 +68,43 @@ func TestTrainAndPredict(t *testing.T) {
 	a.NoError(err)
 }
 
+func TestTrainExportONNX(t *testing.T) {
+	a := assert.New(t)
+	tir := ir.MockTrainStmt(true)
+	tir.Attributes["model.export_format"] = "onnx"
+	a.NoError(InitializeAttributes(tir))
+	code, err := Train(tir, mockSession())
+	a.NoError(err)
+	a.Contains(code, "export_xgboost(len(feature_column_names))")
+	a.NotContains(code, "export_format")
+}
+
+func TestTrainWithClassWeight(t *testing.T) {
+	a := assert.New(t)
+	tir := ir.MockTrainStmt(true)
+	a.NoError(InitializeAttributes(tir))
+	code, err := Train(tir, mockSession())
+	a.NoError(err)
+	a.Contains(code, "class_weight=None")
+
+	tir = ir.MockTrainStmt(true)
+	tir.Attributes["train.class_weight"] = "0:1,1:50"
+	a.NoError(InitializeAttributes(tir))
+	code, err = Train(tir, mockSession())
+	a.NoError(err)
+	a.Contains(code, "class_weight={0: 1, 1: 50}")
+	// The attributes for SQLFlow are not passed to xgboost.train.
+	a.NotContains(code, `"class_weight"`)
+	a.NotContains(code, `"oversample_minority"`)
+
+	tir = ir.MockTrainStmt(true)
+	tir.Attributes["train.class_weight"] = "balanced"
+	tir.Attributes["train.oversample_minority"] = true
+	a.Error(InitializeAttributes(tir))
+	tir.Attributes["train.class_weight"] = "bad"
+	a.Error(InitializeAttributes(tir))
+}
+
 func TestResolveModelParams(t *testing.T) {
 	a := assert.New(t)
 	shortName := []string{"XGBOOST.XGBCLASSIFIER", "XGBOOST.XGBREGRESSOR", "XGBRANKER"}
@@ -24,6 +24,7 @@ type evalFiller struct {
 	LabelMetaJSON      string
 	FeatureColumnNames []string
 	MetricNames        string
+	EvalParamsJSON     string
 	ResultTable        string
 	HDFSNameNodeAddr   string
 	HiveLocation       string
@@ -50,7 +51,8 @@ evaluate(datasource='''{{.DataSource}}''',
          feature_column_names=feature_column_names,
          label_meta=label_meta,
          result_table='''{{.ResultTable}}''',
-         validation_metrics="{{.MetricNames}}".split(","), 
+         validation_metrics="{{.MetricNames}}".split(","),
+         evaluation_params=json.loads('''{{.EvalParamsJSON}}'''),
          hdfs_namenode_addr='''{{.HDFSNameNodeAddr}}''',
          hive_location='''{{.HiveLocation}}''',
          hdfs_user='''{{.HDFSUser}}''',
@@ -30,6 +30,9 @@ type predFiller struct {
 	HDFSPass           string
 	IsPAI              bool
 	PAITable           string
+	WriteBatchSize     int
+	NumWriters         int
+	Partition          string
 }
 
 const predTemplateText = `
@@ -54,7 +57,10 @@ pred(datasource='''{{.DataSource}}''',
      hdfs_user='''{{.HDFSUser}}''',
      hdfs_pass='''{{.HDFSPass}}''',
      is_pai="{{.IsPAI}}" == "true",
-     pai_table="{{.PAITable}}")
+     pai_table="{{.PAITable}}",
+     write_batch_size={{.WriteBatchSize}},
+     num_writers={{.NumWriters}},
+     partition="{{.Partition}}")
 
 `
 
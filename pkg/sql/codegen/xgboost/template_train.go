@@ -31,6 +31,10 @@ type trainFiller struct {
 	IsPAI              bool
 	PAITrainTable      string
 	PAIValidateTable   string
+	ExportFormat       string
+	WarmStart          bool
+	ClassWeight        string
+	Oversample         bool
 }
 
 const trainTemplateText = `
@@ -65,7 +69,14 @@ train(datasource='''{{.DataSource}}''',
       is_pai="{{.IsPAI}}" == "true",
       pai_train_table="{{.PAITrainTable}}",
       pai_validate_table="{{.PAIValidateTable}}",
-      oss_model_dir="{{.OSSModelDir}}")
+      oss_model_dir="{{.OSSModelDir}}",
+      warm_start="{{.WarmStart}}" == "true",
+      class_weight={{.ClassWeight}},
+      oversample_minority="{{.Oversample}}" == "true")
+{{if eq .ExportFormat "onnx"}}
+from sqlflow_submitter.onnx import export_xgboost
+export_xgboost(len(feature_column_names))
+{{end}}
 `
 
 const distTrainTemplateText = `
@@ -0,0 +1,62 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+
+	"sqlflow.org/sqlflow/pkg/database"
+	"sqlflow.org/sqlflow/pkg/ir"
+	"sqlflow.org/sqlflow/pkg/pipe"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/datacheck"
+)
+
+// checkData counts the rows of the training and the validation data of
+// cl violating data.validate, and writes the counts to w. If any row
+// violates it, checkData fails, or drops the rows from the SELECTs of cl
+// by data.on_violation.
+func checkData(w *pipe.Writer, db *database.DB, cl *ir.TrainStmt) error {
+	if e := datacheck.Check(cl.Attributes); e != nil {
+		return e
+	}
+	rule := datacheck.Rule(cl.Attributes)
+	if rule == "" {
+		return nil
+	}
+	onViolation := datacheck.OnViolation(cl.Attributes)
+	for _, data := range []struct {
+		name string
+		slct *string
+	}{{"training", &cl.Select}, {"validation", &cl.ValidationSelect}} {
+		if *data.slct == "" {
+			continue
+		}
+		var total, violations int64
+		if e := db.QueryRow(datacheck.CountQuery(*data.slct, rule)).Scan(&total, &violations); e != nil {
+			return fmt.Errorf("count the %s rows violating data.validate failed: %v", data.name, e)
+		}
+		if violations > 0 && onViolation == datacheck.Fail {
+			return fmt.Errorf(`%d of %d %s rows violate data.validate %q, set data.on_violation="drop" to train without them`, violations, total, data.name, rule)
+		}
+		msg := fmt.Sprintf("%d of %d %s rows violate data.validate %q", violations, total, data.name, rule)
+		if violations > 0 {
+			*data.slct = datacheck.Filter(*data.slct, rule)
+			msg += ", dropped"
+		}
+		if e := w.Write(msg); e != nil {
+			return e
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sqlflow.org/sqlflow/pkg/database"
+	"sqlflow.org/sqlflow/pkg/ir"
+	"sqlflow.org/sqlflow/pkg/pipe"
+)
+
+func TestCheckData(t *testing.T) {
+	a := assert.New(t)
+	dir, e := ioutil.TempDir("", "sqlflow_datacheck")
+	a.NoError(e)
+	defer os.RemoveAll(dir)
+	db, e := database.OpenAndConnectDB("sqlite3://" + filepath.Join(dir, "datacheck.db"))
+	a.NoError(e)
+	defer db.Close()
+	for _, stmt := range []string{
+		"CREATE TABLE people (age int, income int)",
+		"INSERT INTO people VALUES (30, 100), (-1, 200), (40, NULL), (50, 300)",
+	} {
+		_, e = db.Exec(stmt)
+		a.NoError(e)
+	}
+	check := func(cl *ir.TrainStmt) ([]interface{}, error) {
+		rd, wr := pipe.Pipe()
+		var e error
+		go func() {
+			defer wr.Close()
+			e = checkData(wr, db, cl)
+		}()
+		messages := []interface{}{}
+		for r := range rd.ReadAll() {
+			messages = append(messages, r)
+		}
+		return messages, e
+	}
+
+	cl := &ir.TrainStmt{Select: "SELECT * FROM people", Attributes: map[string]interface{}{}}
+	messages, e := check(cl)
+	a.NoError(e)
+	a.Empty(messages)
+
+	cl.Attributes["data.validate"] = "age >= 0 AND income IS NOT NULL"
+	_, e = check(cl)
+	a.Error(e)
+	a.Contains(e.Error(), "2 of 4 training rows violate")
+	a.Equal("SELECT * FROM people", cl.Select)
+
+	cl.Attributes["data.on_violation"] = "drop"
+	cl.ValidationSelect = "SELECT * FROM people WHERE age > 35"
+	messages, e = check(cl)
+	a.NoError(e)
+	a.Equal([]interface{}{
+		`2 of 4 training rows violate data.validate "age >= 0 AND income IS NOT NULL", dropped`,
+		`1 of 2 validation rows violate data.validate "age >= 0 AND income IS NOT NULL", dropped`,
+	}, messages)
+	var n int
+	a.NoError(db.QueryRow("SELECT COUNT(*) FROM (" + cl.Select + ") AS t").Scan(&n))
+	a.Equal(2, n)
+	a.NoError(db.QueryRow("SELECT COUNT(*) FROM (" + cl.ValidationSelect + ") AS t").Scan(&n))
+	a.Equal(1, n)
+
+	delete(cl.Attributes, "data.validate")
+	_, e = check(cl)
+	a.Error(e)
+}
@@ -16,18 +16,23 @@ package sql
 import (
 	"bytes"
 	"fmt"
-	"io/ioutil"
-	"os"
 	"strings"
 	"time"
 
 	"sqlflow.org/sqlflow/pkg/database"
 	"sqlflow.org/sqlflow/pkg/ir"
 	"sqlflow.org/sqlflow/pkg/log"
+	"sqlflow.org/sqlflow/pkg/metrics"
 	"sqlflow.org/sqlflow/pkg/parser"
 	"sqlflow.org/sqlflow/pkg/pipe"
 	pb "sqlflow.org/sqlflow/pkg/proto"
+	"sqlflow.org/sqlflow/pkg/secret"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/forecast"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/prediction"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/sklearn"
+	"sqlflow.org/sqlflow/pkg/sqlfs"
 	"sqlflow.org/sqlflow/pkg/verifier"
+	"sqlflow.org/sqlflow/pkg/workdir"
 )
 
 // EndOfExecution will push to the pipe when one SQL statement execution is finished.
@@ -47,7 +52,13 @@ func RunSQLProgram(sqlProgram string, modelDir string, session *pb.Session) *pip
 		var db *database.DB
 		var err error
 		defer wr.Close()
-		if db, err = database.OpenAndConnectDB(session.DbConnStr); err != nil {
+		// The programs run with the secrets in session resolved, but the
+		// references stay in the requests and the workflows.
+		if session, err = secret.ResolveSession(session); err != nil {
+			wr.Write(fmt.Errorf("resolve secrets failed: %v", err))
+			return
+		}
+		if db, err = database.OpenAndConnectDBWithSession(session); err != nil {
 			wr.Write(fmt.Errorf("create DB failed: %v", err))
 			return
 		}
@@ -75,6 +86,15 @@ func ResolveSQLProgram(sqlStmts []*parser.SQLFlowStmt, logger *log.Logger) ([]ir
 			} else if sql.ShowTrain {
 				logger.Info("resolveSQL:showTrain")
 				r, err = generateShowTrainStmt(sql.SQLFlowSelectStmt)
+			} else if sql.DropModel {
+				logger.Info("resolveSQL:dropModel")
+				r, err = generateDropModelStmt(sql.SQLFlowSelectStmt)
+			} else if sql.ShowModels {
+				logger.Info("resolveSQL:showModels")
+				r, err = generateShowModelsStmt(sql.SQLFlowSelectStmt)
+			} else if sql.ShowLineage {
+				logger.Info("resolveSQL:showLineage")
+				r, err = generateShowLineageStmt(sql.SQLFlowSelectStmt)
 			} else if sql.Explain {
 				logger.Info("resolveSQL:explain")
 				// since getTrainStmtFromModel is false, use empty cwd is fine.
@@ -107,6 +127,13 @@ func runSQLProgram(wr *pipe.Writer, sqlProgram string, db *database.DB, modelDir
 	if err != nil {
 		return err
 	}
+	tracker, err := newArtifactTracker(session.CleanupOnFailure)
+	if err != nil {
+		return err
+	}
+	if _, ok := GetSubmitter(session.Submitter).(*defaultSubmitter); session.DryRun && !ok {
+		return fmt.Errorf("the dry run doesn't support the submitter %s", session.Submitter)
+	}
 	// NOTE(tony): We generate IR and execute its translated program one-by-one since IR generation may depend on the execution
 	// of the previous statement. For example, consider a SQL program
 	//
@@ -116,14 +143,18 @@ func runSQLProgram(wr *pipe.Writer, sqlProgram string, db *database.DB, modelDir
 	// The IR generation on the second statement would fail since it requires inspection the schema of some_table,
 	// which depends on the execution of create table some_table as (select ...);.
 	for _, sql := range stmts {
-		if err := runSingleSQLFlowStatement(wr, sql, db, modelDir, session); err != nil {
+		if err := runStatement(wr, sql, db, modelDir, session, tracker); err != nil {
+			submitter := GetSubmitter(session.Submitter)
+			submitter.Setup(wr, db, modelDir, "", session)
+			tracker.cleanup(wr, db, submitter)
 			return err
 		}
 	}
 	return nil
 }
 
-func runSingleSQLFlowStatement(wr *pipe.Writer, sql *parser.SQLFlowStmt, db *database.DB, modelDir string, session *pb.Session) (e error) {
+func runSingleSQLFlowStatement(wr *pipe.Writer, sql *parser.SQLFlowStmt, db *database.DB, modelDir string, session *pb.Session, tracker *artifactTracker) (e error) {
+	defer func(start time.Time) { metrics.ObserveStatement(sql.Type(), start, e) }(time.Now())
 	defer func(startTime int64) {
 		// NOTE(tony): EndOfExecution indicates a successful run,
 		// so we only writes it when e != nil
@@ -136,22 +167,28 @@ func runSingleSQLFlowStatement(wr *pipe.Writer, sql *parser.SQLFlowStmt, db *dat
 		}
 	}(time.Now().UnixNano())
 
-	// use system default tmp dir
-	cwd, err := ioutil.TempDir("", "sqlflow_models")
+	dir, err := workdir.Default().Create(session.UserId)
 	if err != nil {
 		return err
 	}
-	defer func(cwd string) {
-		if err := os.RemoveAll(cwd); err != nil {
+	defer func(dir *workdir.Dir) {
+		if err := dir.Remove(); err != nil {
 			e = fmt.Errorf("encounter %v when dealwith error: %s", e, err)
 		}
-	}(cwd)
+	}(dir)
+	cwd := dir.Path
 	var r ir.SQLFlowStmt
 	if sql.IsExtendedSyntax() {
 		if sql.Train {
 			r, err = generateTrainStmtWithInferredColumns(sql.SQLFlowSelectStmt, session.DbConnStr, true)
 		} else if sql.ShowTrain {
 			r, err = generateShowTrainStmt(sql.SQLFlowSelectStmt)
+		} else if sql.DropModel {
+			r, err = generateDropModelStmt(sql.SQLFlowSelectStmt)
+		} else if sql.ShowModels {
+			r, err = generateShowModelsStmt(sql.SQLFlowSelectStmt)
+		} else if sql.ShowLineage {
+			r, err = generateShowLineageStmt(sql.SQLFlowSelectStmt)
 		} else if sql.Explain {
 			r, err = generateExplainStmt(sql.SQLFlowSelectStmt, session.DbConnStr, modelDir, cwd, GetSubmitter(session.Submitter).GetTrainStmtFromModel())
 		} else if sql.Predict {
@@ -167,11 +204,18 @@ func runSingleSQLFlowStatement(wr *pipe.Writer, sql *parser.SQLFlowStmt, db *dat
 		return err
 	}
 	r.SetOriginalSQL(sql.Original)
+	if session.DryRun {
+		return dryRun(wr, r, session)
+	}
 	// TODO(typhoonzero): can run feature.LogDerivationResult(wr, trainStmt) here to send
 	// feature derivation logs to client, yet we disable it for now so that it's less annoying.
 	submitter := GetSubmitter(session.Submitter)
 	submitter.Setup(wr, db, modelDir, cwd, session)
-	return r.Execute(submitter)
+	if err := r.Execute(submitter); err != nil {
+		return err
+	}
+	tracker.track(r)
+	return nil
 }
 
 // getColumnTypes is quiet like verify but accept a SQL string as input, and returns
@@ -208,8 +252,43 @@ func getColumnTypes(slct string, db *database.DB) ([]string, []string, error) {
 	return flds, ft, nil
 }
 
-// Create prediction table using the `PredictStmt`.
+// keepColumns returns the fields in flds named in names, and their types.
+func keepColumns(flds, fts []string, names ...string) ([]string, []string) {
+	kflds, kfts := []string{}, []string{}
+	for i, fld := range flds {
+		for _, name := range names {
+			if fld == name {
+				kflds = append(kflds, fld)
+				kfts = append(kfts, fts[i])
+				break
+			}
+		}
+	}
+	return kflds, kfts
+}
+
+// Create prediction table using the `PredictStmt`.  By predict.write_mode,
+// it drops and recreates the table, or the partition of
+// predict.partition, creates the table if it doesn't exist, or fails if
+// the table, or the partition, exists.
 func createPredictionTableFromIR(predStmt *ir.PredictStmt, db *database.DB, session *pb.Session) error {
+	mode := prediction.WriteMode(predStmt.Attributes)
+	partition := prediction.Partition(predStmt.Attributes)
+	if partition != "" && db.DriverName != "hive" && db.DriverName != "maxcompute" {
+		return fmt.Errorf("predict.partition is only supported by Hive and MaxCompute, not %s", db.DriverName)
+	}
+	exists := sqlfs.Exists(db.DB, predStmt.ResultTable)
+	if partition != "" && exists {
+		return preparePredictionPartition(predStmt.ResultTable, partition, mode, db)
+	}
+	if exists {
+		switch mode {
+		case prediction.Append:
+			return nil
+		case prediction.ErrorIfExists:
+			return fmt.Errorf("the result table %s exists, and predict.write_mode is %s", predStmt.ResultTable, mode)
+		}
+	}
 	dropStmt := fmt.Sprintf("drop table if exists %s;", predStmt.ResultTable)
 	if _, e := db.Exec(dropStmt); e != nil {
 		return fmt.Errorf("failed executing %s: %q", dropStmt, e)
@@ -227,6 +306,10 @@ func createPredictionTableFromIR(predStmt *ir.PredictStmt, db *database.DB, sess
 	trainLabelColumn := ""
 	if predStmt.TrainStmt != nil {
 		trainLabelColumn = predStmt.TrainStmt.Label.GetFieldDesc()[0].Name
+		if forecast.Supports(predStmt.TrainStmt.Estimator) {
+			// The forecasts are of the time column and the label only.
+			flds, fts = keepColumns(flds, fts, forecast.TimeColumn(predStmt.TrainStmt), trainLabelColumn, predStmt.ResultColumn)
+		}
 	}
 	labelColumnName := predStmt.ResultColumn
 	labelColumnType := ""
@@ -251,7 +334,10 @@ func createPredictionTableFromIR(predStmt *ir.PredictStmt, db *database.DB, sess
 	// the result column type by the prediction result.
 	//
 	// label column not found in predict table, create a column specified by PREDICT clause:
-	if labelColumnType == "" {
+	if predStmt.TrainStmt != nil && sklearn.IsAnomalyDetector(predStmt.TrainStmt.Estimator) {
+		// The anomaly scores are floats, whatever the type of the field of the result column.
+		labelColumnType = "FLOAT"
+	} else if labelColumnType == "" {
 		// NOTE(typhoonzero): Clustering model may not have label in select statement, default use INT type
 		labelColumnType = "INT"
 	}
@@ -259,8 +345,17 @@ func createPredictionTableFromIR(predStmt *ir.PredictStmt, db *database.DB, sess
 	if e != nil {
 		return e
 	}
+	partitionedBy := ""
+	if partition != "" {
+		columns, _ := prediction.PartitionColumns(partition)
+		partitionedBy = fmt.Sprintf(" PARTITIONED BY (%s STRING)", strings.Join(columns, " STRING, "))
+	}
 	if db.DriverName == "hive" {
-		fmt.Fprintf(&b, "%s %s) ROW FORMAT DELIMITED FIELDS TERMINATED BY \"\\001\" STORED AS TEXTFILE;", labelColumnName, stype)
+		fmt.Fprintf(&b, "%s %s)%s ROW FORMAT DELIMITED FIELDS TERMINATED BY \"\\001\" STORED AS TEXTFILE;", labelColumnName, stype, partitionedBy)
+	} else if db.DriverName == "maxcompute" {
+		fmt.Fprintf(&b, "%s %s)%s;", labelColumnName, stype, partitionedBy)
+	} else if db.DriverName == "clickhouse" {
+		fmt.Fprintf(&b, "%s %s) ENGINE = Log;", labelColumnName, stype)
 	} else {
 		fmt.Fprintf(&b, "%s %s);", labelColumnName, stype)
 	}
@@ -271,3 +366,32 @@ func createPredictionTableFromIR(predStmt *ir.PredictStmt, db *database.DB, sess
 	}
 	return nil
 }
+
+// preparePredictionPartition drops the partition of the existing result
+// table to overwrite it, or fails if the partition has rows and mode is
+// prediction.ErrorIfExists.  The writer of the prediction result creates
+// the partition.
+func preparePredictionPartition(table, partition, mode string, db *database.DB) error {
+	columns, values := prediction.PartitionColumns(partition)
+	spec := make([]string, len(columns))
+	for i, c := range columns {
+		spec[i] = fmt.Sprintf("%s='%s'", c, values[i])
+	}
+	switch mode {
+	case prediction.Overwrite:
+		dropStmt := fmt.Sprintf("ALTER TABLE %s DROP IF EXISTS PARTITION (%s);", table, strings.Join(spec, ", "))
+		if _, e := db.Exec(dropStmt); e != nil {
+			return fmt.Errorf("failed executing %s: %q", dropStmt, e)
+		}
+	case prediction.ErrorIfExists:
+		rows, e := db.Query(fmt.Sprintf("SELECT 1 FROM %s WHERE %s LIMIT 1", table, strings.Join(spec, " AND ")))
+		if e != nil {
+			return e
+		}
+		defer rows.Close()
+		if rows.Next() {
+			return fmt.Errorf("the partition %s of the result table %s exists, and predict.write_mode is %s", partition, table, mode)
+		}
+	}
+	return nil
+}
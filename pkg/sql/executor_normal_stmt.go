@@ -151,6 +151,22 @@ func isXGBoostModel(estimator string) bool {
 	return strings.HasPrefix(strings.ToUpper(estimator), `XGB`)
 }
 
+func isPyTorchModel(estimator string) bool {
+	return strings.HasPrefix(strings.ToUpper(estimator), `TORCH.`)
+}
+
+func isLightGBMModel(estimator string) bool {
+	return strings.HasPrefix(strings.ToUpper(estimator), `LIGHTGBM.`)
+}
+
+func isCatBoostModel(estimator string) bool {
+	return strings.HasPrefix(strings.ToUpper(estimator), `CATBOOST.`)
+}
+
+func isSKLearnModel(estimator string) bool {
+	return strings.HasPrefix(strings.ToUpper(estimator), `SKLEARN.`)
+}
+
 func isKMeansModel(estimator string) bool {
 	return strings.ToUpper(estimator) == "KMEANS"
 }
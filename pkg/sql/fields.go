@@ -100,7 +100,7 @@ func fieldValue(val interface{}) (interface{}, error) {
 }
 
 func fieldType(dbms, typeName string) (string, error) {
-	if dbms == "mysql" || dbms == "maxcompute" || dbms == "alisa" {
+	if dbms == "mysql" || dbms == "maxcompute" || dbms == "alisa" || dbms == "sqlite3" {
 		if typeName == "VARCHAR" {
 			// FIXME(tony): MySQL driver DatabaseName doesn't include the type length of a field.
 			// Hardcoded to 255 for now.
@@ -108,6 +108,17 @@ func fieldType(dbms, typeName string) (string, error) {
 			return "VARCHAR(255)", nil
 		}
 		return typeName, nil
+	} else if dbms == "postgres" {
+		// github.com/lib/pq reports the internal type names, like INT4
+		// and FLOAT8, which are also valid in CREATE TABLE.
+		if typeName == "VARCHAR" {
+			return "VARCHAR(255)", nil
+		}
+		return typeName, nil
+	} else if dbms == "clickhouse" {
+		// github.com/ClickHouse/clickhouse-go reports the type names
+		// in DDL, like Int32 and Nullable(Float64).
+		return typeName, nil
 	} else if dbms == "hive" {
 		const hiveCTypeSuffix = "_TYPE" // Hive field type names ends with _TYPE
 		if strings.HasSuffix(typeName, hiveCTypeSuffix) {
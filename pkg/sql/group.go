@@ -0,0 +1,167 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+	"strings"
+
+	"sqlflow.org/sqlflow/pkg/database"
+	"sqlflow.org/sqlflow/pkg/ir"
+	"sqlflow.org/sqlflow/pkg/model"
+	"sqlflow.org/sqlflow/pkg/parser"
+	"sqlflow.org/sqlflow/pkg/pipe"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/grouping"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/prediction"
+	"sqlflow.org/sqlflow/pkg/workdir"
+)
+
+// runStatement runs sql by runSingleSQLFlowStatement, once for each group
+// if sql trains a model per group by train.group_by, or predicts by the
+// models of the groups.
+func runStatement(wr *pipe.Writer, sql *parser.SQLFlowStmt, db *database.DB, modelDir string, session *pb.Session, tracker *artifactTracker) error {
+	if sql.IsExtendedSyntax() && sql.Train {
+		trainStmt, err := generateTrainStmt(sql.SQLFlowSelectStmt, false)
+		if err != nil {
+			return err
+		}
+		if g := trainStmt.Grouping; g.Column != "" && g.Value == "" {
+			return runGroupedTrain(wr, sql, trainStmt, db, modelDir, session, tracker)
+		}
+	} else if sql.IsExtendedSyntax() && sql.Predict && GetSubmitter(session.Submitter).GetTrainStmtFromModel() {
+		// runSingleSQLFlowStatement reports the errors of loading the model.
+		if m, err := model.LoadMeta(ModelURI(modelDir, sql.Model), session); err == nil && m.Groups != nil {
+			return runGroupedPredict(wr, sql, m.Groups, db, modelDir, session, tracker)
+		}
+	}
+	return runSingleSQLFlowStatement(wr, sql, db, modelDir, session, tracker)
+}
+
+// runGroupedTrain trains the model of each group in the rows of the
+// training SELECT of trainStmt, and saves the index of them as the model
+// of sql. With train.group_index, the models are trained by the steps of
+// a workflow before, so it checks they exist instead.
+func runGroupedTrain(wr *pipe.Writer, sql *parser.SQLFlowStmt, trainStmt *ir.TrainStmt, db *database.DB, modelDir string, session *pb.Session, tracker *artifactTracker) error {
+	if _, ok := GetSubmitter(session.Submitter).(*defaultSubmitter); !ok {
+		return fmt.Errorf("train.group_by is not supported by the submitter %s", session.Submitter)
+	}
+	column := trainStmt.Grouping.Column
+	values, err := grouping.Values(db, trainStmt.Select, column)
+	if err != nil {
+		return err
+	}
+	for _, v := range values {
+		if trainStmt.Grouping.IndexOnly {
+			if _, err := model.LoadMeta(ModelURI(modelDir, grouping.ModelName(trainStmt.Into, column, v)), session); err != nil {
+				return fmt.Errorf("the model of %s=%s is not trained: %v", column, v, err)
+			}
+			continue
+		}
+		if err := runSingleSQLFlowStatement(wr, groupTrainStmt(sql, v), db, modelDir, session, tracker); err != nil {
+			return fmt.Errorf("train the model of %s=%s: %v", column, v, err)
+		}
+	}
+	if session.DryRun {
+		return nil
+	}
+	return saveGroupIndex(wr, sql, &model.Groups{Column: column, Values: values}, modelDir, session)
+}
+
+// groupTrainStmt returns a copy of sql, which trains the model of the
+// group value only.
+func groupTrainStmt(sql *parser.SQLFlowStmt, value string) *parser.SQLFlowStmt {
+	slct := *sql.SQLFlowSelectStmt
+	slct.TrainAttrs = parser.Attributes{}
+	for k, v := range sql.TrainAttrs {
+		slct.TrainAttrs[k] = v
+	}
+	slct.TrainAttrs["train.group"] = &parser.Expr{Type: parser.STRING, Value: `"` + value + `"`}
+	stmt := *sql
+	stmt.SQLFlowSelectStmt = &slct
+	return &stmt
+}
+
+// saveGroupIndex saves the index of the models of groups as the model of
+// sql, which PREDICT reads to predict the rows of each group by the model
+// of the group.
+func saveGroupIndex(wr *pipe.Writer, sql *parser.SQLFlowStmt, groups *model.Groups, modelDir string, session *pb.Session) (err error) {
+	dir, err := workdir.Default().Create(session.UserId)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if e := dir.Remove(); err == nil {
+			err = e
+		}
+	}()
+	m := model.New(dir.Path, sql.Original)
+	m.Groups = groups
+	if err := m.Save(ModelURI(modelDir, sql.Save), nil, session); err != nil {
+		return err
+	}
+	return wr.Write(fmt.Sprintf("model %s of %d groups of %s saved", sql.Save, len(groups.Values), groups.Column))
+}
+
+// runGroupedPredict predicts the rows of each group of groups in the rows
+// of the SELECT of sql by the model of the group, and writes them to the
+// result table in turn.
+func runGroupedPredict(wr *pipe.Writer, sql *parser.SQLFlowStmt, groups *model.Groups, db *database.DB, modelDir string, session *pb.Session, tracker *artifactTracker) error {
+	values, err := grouping.Values(db, sql.StandardSelect.String(), groups.Column)
+	if err != nil {
+		return err
+	}
+	trained := map[string]bool{}
+	for _, v := range groups.Values {
+		trained[v] = true
+	}
+	missing := []string{}
+	for _, v := range values {
+		if !trained[v] {
+			missing = append(missing, v)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s has no models of the groups %s=%s", sql.Model, groups.Column, strings.Join(missing, ", "))
+	}
+	for i, v := range values {
+		stmt, err := groupPredictStmt(db.DriverName, sql, groups.Column, v)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			// The rows of the other groups are in the result table.
+			stmt.PredAttrs["predict.write_mode"] = &parser.Expr{Type: parser.STRING, Value: `"` + prediction.Append + `"`}
+		}
+		if err := runSingleSQLFlowStatement(wr, stmt, db, modelDir, session, tracker); err != nil {
+			return fmt.Errorf("predict by the model of %s=%s: %v", groups.Column, v, err)
+		}
+	}
+	return nil
+}
+
+// groupPredictStmt returns the statement predicting the rows of the group
+// column=value of sql by the model of the group.
+func groupPredictStmt(driver string, sql *parser.SQLFlowStmt, column, value string) (*parser.SQLFlowStmt, error) {
+	slct := sql.StandardSelect.String()
+	if !strings.HasPrefix(sql.Original, slct) {
+		return nil, fmt.Errorf("cannot find the SELECT in %s", sql.Original)
+	}
+	stmt, err := parser.ParseStatement(driver, grouping.Select(slct, column, value)+" "+sql.Original[len(slct):])
+	if err != nil {
+		return nil, err
+	}
+	stmt.Model = grouping.ModelName(sql.Model, column, value)
+	return stmt, nil
+}
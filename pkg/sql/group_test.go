@@ -0,0 +1,65 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sqlflow.org/sqlflow/pkg/ir"
+	"sqlflow.org/sqlflow/pkg/parser"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/grouping"
+)
+
+func TestGroupTrainStmt(t *testing.T) {
+	a := assert.New(t)
+	r, e := parser.ParseStatement("mysql", `SELECT * FROM iris.train TO TRAIN DNNClassifier WITH model.n_classes = 3, train.group_by = "region" LABEL class INTO my_model;`)
+	a.NoError(e)
+
+	trainStmt, err := generateTrainStmt(r.SQLFlowSelectStmt, false)
+	a.NoError(err)
+	a.Equal(ir.Grouping{Column: "region"}, trainStmt.Grouping)
+	a.Equal("my_model", trainStmt.Into)
+	_, ok := trainStmt.Attributes["train.group_by"]
+	a.False(ok)
+
+	trainStmt, err = generateTrainStmt(groupTrainStmt(r, "cn").SQLFlowSelectStmt, false)
+	a.NoError(err)
+	a.Equal(ir.Grouping{Column: "region", Value: "cn"}, trainStmt.Grouping)
+	a.Equal(grouping.Select("SELECT * FROM iris.train", "region", "cn"), trainStmt.Select)
+	a.Equal("my_model/region=cn", trainStmt.Into)
+	// the statement of the group is a copy
+	_, ok = r.TrainAttrs["train.group"]
+	a.False(ok)
+
+	r, e = parser.ParseStatement("mysql", `SELECT * FROM iris.train TO TRAIN DNNClassifier WITH train.group_by = "class" LABEL class INTO my_model;`)
+	a.NoError(e)
+	_, err = generateTrainStmt(r.SQLFlowSelectStmt, false)
+	a.Error(err)
+}
+
+func TestGroupPredictStmt(t *testing.T) {
+	a := assert.New(t)
+	r, e := parser.ParseStatement("mysql", `SELECT * FROM iris.test TO PREDICT iris.predict.class USING my_model;`)
+	a.NoError(e)
+
+	pr, err := groupPredictStmt("mysql", r, "region", "cn")
+	a.NoError(err)
+	a.True(pr.Predict)
+	a.Equal(grouping.Select("SELECT * FROM iris.test", "region", "cn"), strings.TrimSpace(pr.StandardSelect.String()))
+	a.Equal("my_model/region=cn", pr.Model)
+	a.Equal("iris.predict.class", pr.Into)
+	a.Equal("my_model", r.Model)
+}
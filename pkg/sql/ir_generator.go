@@ -15,6 +15,7 @@ package sql
 
 import (
 	"fmt"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -22,7 +23,17 @@ import (
 	"sqlflow.org/sqlflow/pkg/ir"
 	"sqlflow.org/sqlflow/pkg/model"
 	"sqlflow.org/sqlflow/pkg/parser"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/attribute"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/catboost"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/evaluation"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/forecast"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/grouping"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/lightgbm"
 	"sqlflow.org/sqlflow/pkg/sql/codegen/pai"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/prediction"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/pytorch"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/resource"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/sklearn"
 	"sqlflow.org/sqlflow/pkg/sql/codegen/tensorflow"
 	"sqlflow.org/sqlflow/pkg/sql/codegen/xgboost"
 	"sqlflow.org/sqlflow/pkg/step/feature"
@@ -42,10 +53,24 @@ const (
 	square        = "SQUARE"
 	dense         = "DENSE"
 	comma         = "COMMA"
+	minute        = "MINUTE"
+	hour          = "HOUR"
+	dayOfWeek     = "DAYOFWEEK"
+	dayOfMonth    = "DAYOFMONTH"
+	dayOfYear     = "DAYOFYEAR"
+	month         = "MONTH"
+	year          = "YEAR"
+	cyclical      = "CYCLICAL"
+	jsonValue     = "JSON_VALUE"
+	text          = "TEXT"
 )
 
+// defaultTextVocabularySize is the size of the vocabulary of a TEXT column
+// if not specified, like TEXT(title, word, 64).
+const defaultTextVocabularySize = 10000
+
 func generateTrainStmtWithInferredColumns(slct *parser.SQLFlowSelectStmt, connStr string, verifyLabel bool) (*ir.TrainStmt, error) {
-	trainStmt, err := generateTrainStmt(slct, true)
+	trainStmt, err := generateTrainStmt(slct, false)
 	if err != nil {
 		return nil, err
 	}
@@ -56,12 +81,13 @@ func generateTrainStmtWithInferredColumns(slct *parser.SQLFlowSelectStmt, connSt
 	}
 	defer db.Close()
 
-	if err := feature.InferFeatureColumns(trainStmt, db); err != nil {
+	// Report all the problems of the statement before the feature
+	// derivation and the training.
+	if err := validateTrainStmt(trainStmt, db, verifyLabel); err != nil {
 		return nil, err
 	}
 
-	err = verifyTrainStmt(trainStmt, db, verifyLabel)
-	if err != nil {
+	if err := feature.InferFeatureColumns(trainStmt, db); err != nil {
 		return nil, err
 	}
 
@@ -69,14 +95,45 @@ func generateTrainStmtWithInferredColumns(slct *parser.SQLFlowSelectStmt, connSt
 }
 
 func doAttrInitAndTypeChecking(ir *ir.TrainStmt) error {
-	if isXGBoostModel(ir.Estimator) {
+	if forecast.Supports(ir.Estimator) {
+		return forecast.InitializeAttributes(ir)
+	} else if isXGBoostModel(ir.Estimator) {
 		return xgboost.InitializeAttributes(ir)
+	} else if isPyTorchModel(ir.Estimator) {
+		return pytorch.InitializeAttributes(ir)
+	} else if isLightGBMModel(ir.Estimator) {
+		return lightgbm.InitializeAttributes(ir)
+	} else if isCatBoostModel(ir.Estimator) {
+		return catboost.InitializeAttributes(ir)
+	} else if isSKLearnModel(ir.Estimator) {
+		return sklearn.InitializeAttributes(ir)
 	} else if isKMeansModel(ir.Estimator) {
 		return pai.InitializeKMeansAttributes(ir)
 	}
 	return tensorflow.InitializeAttributes(ir)
 }
 
+// AttributeDictionary returns the attributes accepted by the WITH clause of
+// TO TRAIN the given estimator, e.g., for completion in IDE plugins.
+func AttributeDictionary(estimator string) (attribute.Dictionary, error) {
+	if forecast.Supports(estimator) {
+		return forecast.AttributeDictionary(), nil
+	} else if isXGBoostModel(estimator) {
+		return xgboost.AttributeDictionary(), nil
+	} else if isPyTorchModel(estimator) {
+		return pytorch.AttributeDictionary(estimator)
+	} else if isLightGBMModel(estimator) {
+		return lightgbm.AttributeDictionary(), nil
+	} else if isCatBoostModel(estimator) {
+		return catboost.AttributeDictionary(), nil
+	} else if isSKLearnModel(estimator) {
+		return sklearn.AttributeDictionary(estimator)
+	} else if isKMeansModel(estimator) {
+		return pai.KMeansAttributeDictionary(), nil
+	}
+	return tensorflow.AttributeDictionary(estimator), nil
+}
+
 func generateTrainStmt(slct *parser.SQLFlowSelectStmt, attrInitAndTypeCheck bool) (*ir.TrainStmt, error) {
 	tc := slct.TrainClause
 	modelURI := tc.Estimator
@@ -89,6 +146,17 @@ func generateTrainStmt(slct *parser.SQLFlowSelectStmt, attrInitAndTypeCheck bool
 	if err != nil {
 		return nil, err
 	}
+	resources, err := resource.Parse(attrList)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := grouping.Parse(attrList)
+	if err != nil {
+		return nil, err
+	}
+	if groups.Column != "" && groups.Column == tc.Label {
+		return nil, fmt.Errorf("train.group_by shouldn't be the label %s", tc.Label)
+	}
 
 	fcMap := make(map[string][]ir.FeatureColumn)
 	for target, columnList := range tc.Columns {
@@ -121,18 +189,32 @@ func generateTrainStmt(slct *parser.SQLFlowSelectStmt, attrInitAndTypeCheck bool
 		}}
 
 	vslct, _ := parseValidationSelect(attrList)
+	tslct, vslct, err := evaluation.SplitByTime(slct.StandardSelect.String(), vslct, attrList)
+	if err != nil {
+		return nil, err
+	}
 	trainStmt := &ir.TrainStmt{
-		Select: slct.StandardSelect.String(),
+		Select: tslct,
 		// TODO(weiguoz): This is a temporary implement. Specifying the
 		// validation dataset by keyword `VALIDATE` is the final solution.
 		ValidationSelect: vslct,
 		ModelImage:       modelImageName,
 		Estimator:        modelName,
 		Attributes:       attrList,
+		Resources:        resources,
+		Grouping:         groups,
 		Features:         fcMap,
 		Label:            label,
 		Into:             slct.Save,
 	}
+	if groups.Value != "" {
+		// The statement trains the model of one group.
+		trainStmt.Select = grouping.Select(trainStmt.Select, groups.Column, groups.Value)
+		if trainStmt.ValidationSelect != "" {
+			trainStmt.ValidationSelect = grouping.Select(trainStmt.ValidationSelect, groups.Column, groups.Value)
+		}
+		trainStmt.Into = grouping.ModelName(trainStmt.Into, groups.Column, groups.Value)
+	}
 	if attrInitAndTypeCheck {
 		if err = doAttrInitAndTypeChecking(trainStmt); err != nil {
 			return nil, err
@@ -144,13 +226,16 @@ func generateTrainStmt(slct *parser.SQLFlowSelectStmt, attrInitAndTypeCheck bool
 func loadModelMeta(pr *parser.SQLFlowSelectStmt, db *database.DB, cwd, modelDir, modelName string) (*parser.SQLFlowSelectStmt, *parser.SQLFlowSelectStmt, error) {
 	modelURI := modelName
 	if modelDir != "" {
-		modelURI = fmt.Sprintf("file://%s/%s", modelDir, modelName)
+		modelURI = "file://" + filepath.Join(modelDir, modelName)
 	}
 
 	m, e := model.Load(modelURI, cwd, db)
 	if e != nil {
 		return nil, nil, fmt.Errorf("load %v", e)
 	}
+	if m.Groups != nil {
+		return nil, nil, fmt.Errorf("%s has a model per group of train.group_by=%s, which only PREDICT supports", modelName, m.Groups.Column)
+	}
 	// Parse the training SELECT statement used to train
 	// the model for the prediction.
 	tr, e := parser.ParseStatement(db.DriverName, m.TrainSelect)
@@ -158,7 +243,13 @@ func loadModelMeta(pr *parser.SQLFlowSelectStmt, db *database.DB, cwd, modelDir,
 		return nil, nil, fmt.Errorf("parse: TrainSelect %v raise %v", m.TrainSelect, e)
 	}
 
-	if e := verifier.VerifyColumnNameAndType(tr.SQLFlowSelectStmt, pr, db); e != nil {
+	if m.Schema != nil {
+		if e := verifySchema(m.Schema, pr, db); e != nil {
+			return nil, nil, e
+		}
+	} else if e := verifier.VerifyColumnNameAndType(tr.SQLFlowSelectStmt, pr, db); e != nil {
+		// The models saved by older versions have no schema, so the
+		// columns are compared with the training SELECT.
 		return nil, nil, fmt.Errorf("VerifyColumnNameAndType: %v", e)
 	}
 
@@ -167,98 +258,61 @@ func loadModelMeta(pr *parser.SQLFlowSelectStmt, db *database.DB, cwd, modelDir,
 	return pr, tr.SQLFlowSelectStmt, nil
 }
 
-func generateTrainStmtByModel(slct *parser.SQLFlowSelectStmt, connStr, cwd, modelDir, model string) (*ir.TrainStmt, error) {
+func generateTrainStmtByModel(slct *parser.SQLFlowSelectStmt, connStr, cwd, modelDir, modelName string) (*ir.TrainStmt, error) {
 	db, err := database.OpenDB(connStr)
 	if err != nil {
 		return nil, err
 	}
 	defer db.Close()
 
-	_, trainSlct, err := loadModelMeta(slct, db, cwd, modelDir, model)
+	_, trainSlct, err := loadModelMeta(slct, db, cwd, modelDir, modelName)
 	if err != nil {
 		return nil, err
 	}
+	if cwd != "" {
+		// Reapply the feature columns derived at train time, so the
+		// vocabularies and statistics are the ones the model learned
+		// from, even if the training table has changed.
+		p, err := model.ReadPreprocessing(cwd)
+		if err != nil {
+			return nil, err
+		}
+		if p != nil {
+			return generateTrainStmtByPreprocessing(trainSlct, p)
+		}
+	}
+	// Models saved by older versions have no preprocessing spec.
 	return generateTrainStmtWithInferredColumns(trainSlct, connStr, false)
 }
 
-func verifyTrainStmt(trainStmt *ir.TrainStmt, db *database.DB, verifyLabel bool) error {
-	trainFields, e := verifier.Verify(trainStmt.Select, db)
-	if e != nil {
-		return e
-	}
-
-	for _, fc := range trainStmt.Features {
-		for _, field := range fc {
-			for _, fm := range field.GetFieldDesc() {
-				name := fm.Name
-				_, ok := trainFields.Get(name)
-				if !ok {
-					return fmt.Errorf("feature field does not exist in database: %s", name)
-				}
-			}
-		}
+func generateTrainStmtByPreprocessing(slct *parser.SQLFlowSelectStmt, p *model.Preprocessing) (*ir.TrainStmt, error) {
+	trainStmt, err := generateTrainStmt(slct, true)
+	if err != nil {
+		return nil, err
 	}
-	if verifyLabel {
-		labelFieldName := trainStmt.Label.GetFieldDesc()[0].Name
-		if labelFieldName == "" {
-			// empty labelFieldName means clustering model.
-			return nil
-		}
-		_, ok := trainFields.Get(labelFieldName)
-		if !ok {
-			return fmt.Errorf("label field does not exist in database: %s", labelFieldName)
-		}
+	trainStmt.Features, trainStmt.Label, err = p.FeatureColumns()
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return trainStmt, nil
 }
 
+// verifyIRWithTrainStmt checks the SELECT of sqlir gives rows. Its
+// columns are compared with the ones of the model by loadModelMeta.
 func verifyIRWithTrainStmt(sqlir ir.SQLFlowStmt, db *database.DB) error {
 	var selectStmt string
-	var trainStmt *ir.TrainStmt
 	switch s := sqlir.(type) {
 	case *ir.PredictStmt:
 		selectStmt = s.Select
-		trainStmt = s.TrainStmt
 	case *ir.ExplainStmt:
 		selectStmt = s.Select
-		trainStmt = s.TrainStmt
 	case *ir.EvaluateStmt:
 		selectStmt = s.Select
-		trainStmt = s.TrainStmt
 	default:
 		return fmt.Errorf("verifyIRWithTrainStmt doesn't support IR of type %T", sqlir)
 	}
-
-	trainFields, e := verifier.Verify(selectStmt, db)
-	if e != nil {
-		return e
-	}
-	if trainStmt == nil { // Implies we don't need to load model
-		return nil
-	}
-
-	predFields, e := verifier.Verify(trainStmt.Select, db)
-	if e != nil {
-		return e
-	}
-
-	for _, fc := range trainStmt.Features {
-		for _, field := range fc {
-			for _, fm := range field.GetFieldDesc() {
-				name := fm.Name
-				it, ok := predFields.Get(name)
-				if !ok {
-					return fmt.Errorf("predFields doesn't contain column %s", name)
-				}
-				tt, _ := trainFields.Get(name)
-				if it != tt {
-					return fmt.Errorf("field %s type dismatch %v(pred) vs %v(train)", name, it, tt)
-				}
-			}
-		}
-	}
-
-	return nil
+	_, e := verifier.Verify(selectStmt, db)
+	return e
 }
 
 func generatePredictStmt(slct *parser.SQLFlowSelectStmt, connStr string, modelDir string, cwd string, getTrainStmtFromModel bool) (*ir.PredictStmt, error) {
@@ -266,6 +320,13 @@ func generatePredictStmt(slct *parser.SQLFlowSelectStmt, connStr string, modelDi
 	if err != nil {
 		return nil, err
 	}
+	resources, err := resource.Parse(attrMap)
+	if err != nil {
+		return nil, err
+	}
+	if err := prediction.InitializeAttributes(attrMap); err != nil {
+		return nil, err
+	}
 
 	var trainStmt *ir.TrainStmt
 	if getTrainStmtFromModel {
@@ -275,8 +336,20 @@ func generatePredictStmt(slct *parser.SQLFlowSelectStmt, connStr string, modelDi
 		}
 	}
 
-	resultTable, resultCol, err := parseResultTable(slct.Into)
-	if err != nil {
+	resultTable, resultCol := "", ""
+	if isPredictToClient(slct.Into) {
+		// The empty result table streams the prediction result to the
+		// client, and the result column is the label of the model, or
+		// anomaly_score of the anomaly detectors.
+		resultCol = "prediction"
+		if trainStmt != nil && sklearn.IsAnomalyDetector(trainStmt.Estimator) {
+			resultCol = "anomaly_score"
+		} else if trainStmt != nil && trainStmt.Label != nil {
+			if fds := trainStmt.Label.GetFieldDesc(); len(fds) > 0 && fds[0].Name != "" {
+				resultCol = fds[0].Name
+			}
+		}
+	} else if resultTable, resultCol, err = parseResultTable(slct.Into); err != nil {
 		return nil, err
 	}
 
@@ -285,6 +358,7 @@ func generatePredictStmt(slct *parser.SQLFlowSelectStmt, connStr string, modelDi
 		ResultTable:  resultTable,
 		ResultColumn: resultCol,
 		Attributes:   attrMap,
+		Resources:    resources,
 		Using:        slct.Model,
 		TrainStmt:    trainStmt,
 	}
@@ -406,7 +480,8 @@ func generateAttributeIR(attrs *parser.Attributes) (map[string]interface{}, erro
 // or struct the expression stands for, e.g.
 // 1                 ->  int(1)
 // "string"          ->  string("string")
-// [1,2,3]           ->  []int{1,2,3}
+// [1,2,3]           ->  []interface{}{1,2,3}
+// [0.1,"a"]         ->  []interface{}{float32(0.1),"a"}
 // NUMERIC(c1)       ->  &ir.NumericColumn{Key: "c1"...}
 // [NUMERIC(c1), c2] ->  [&ir.NumericColumn{Key: "c1"...}, string("c2")]
 //
@@ -433,12 +508,14 @@ func parseExpression(e interface{}) (interface{}, error) {
 		for idx, expr := range *el {
 			if idx > 0 {
 				if expr.Sexp == nil {
-					intVal, err := strconv.Atoi(expr.Value)
-					// TODO(typhoonzero): support list of float etc.
-					if err != nil {
+					switch expr.Type {
+					case parser.NUMBER, parser.STRING:
+						// Numbers and quoted strings, like the candidates
+						// of an attribute to tune, num_leaves=[15, 31] or
+						// boosting=["gbdt", "dart"].
+						list = append(list, inferStringValue(expr.Value))
+					default:
 						list = append(list, expr.Value)
-					} else {
-						list = append(list, intVal)
 					}
 				} else {
 					value, err := parseExpression(&expr.Sexp)
@@ -499,6 +576,12 @@ func parseFeatureColumn(el *parser.ExprList) (ir.FeatureColumn, error) {
 		return parseEmbeddingColumn(el)
 	case indicator:
 		return parseIndicatorColumn(el)
+	case minute, hour, dayOfWeek, dayOfMonth, dayOfYear, month, year:
+		return parseTimeColumn(el)
+	case jsonValue:
+		return parseJSONValueColumn(el)
+	case text:
+		return parseTextColumn(el)
 	default:
 		return nil, fmt.Errorf("not supported expr: %s", head)
 	}
@@ -537,6 +620,104 @@ func parseNumericColumn(el *parser.ExprList) (*ir.NumericColumn, error) {
 	}, nil
 }
 
+func parseTimeColumn(el *parser.ExprList) (*ir.NumericColumn, error) {
+	head := strings.ToUpper((*el)[0].Value)
+	help := fmt.Sprintf("%s(col_name[, CYCLICAL])", head)
+	if len(*el) != 2 && len(*el) != 3 {
+		return nil, fmt.Errorf("bad %s expression format: %s, should be like: %s", head, *el, help)
+	}
+	key, err := expression2string((*el)[1])
+	if err != nil {
+		return nil, fmt.Errorf("bad %s key: %s, err: %s, should be like: %s", head, (*el)[1], err, help)
+	}
+	isCyclical := false
+	if len(*el) == 3 {
+		if strings.ToUpper((*el)[2].Value) != cyclical {
+			return nil, fmt.Errorf("bad %s expression format: %s, should be like: %s", head, *el, help)
+		}
+		isCyclical = true
+	}
+	// the shape is updated by feature derivation after merging the parts of the field
+	parts := []string{strings.ToLower(head)}
+	return &ir.NumericColumn{
+		FieldDesc: &ir.FieldDesc{
+			Name:      key,
+			DType:     ir.Float,
+			Shape:     []int{ir.TimeFeatureSize(parts, isCyclical)},
+			IsSparse:  false,
+			TimeParts: parts,
+			Cyclical:  isCyclical,
+		},
+	}, nil
+}
+
+func parseJSONValueColumn(el *parser.ExprList) (*ir.NumericColumn, error) {
+	help := "JSON_VALUE(col_name, '$.key')"
+	if len(*el) != 3 {
+		return nil, fmt.Errorf("bad JSON_VALUE expression format: %s, should be like: %s", *el, help)
+	}
+	key, err := expression2string((*el)[1])
+	if err != nil {
+		return nil, fmt.Errorf("bad JSON_VALUE key: %s, err: %s, should be like: %s", (*el)[1], err, help)
+	}
+	path := strings.Trim((*el)[2].Value, "\"'")
+	if (*el)[2].Type == 0 || !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("bad JSON_VALUE path: %s, should be like: %s", (*el)[2], help)
+	}
+	// the shape is updated by feature derivation after merging the paths of the field
+	return &ir.NumericColumn{
+		FieldDesc: &ir.FieldDesc{
+			Name:      key,
+			DType:     ir.Float,
+			Shape:     []int{1},
+			IsSparse:  false,
+			JSONPaths: []string{path},
+		},
+	}, nil
+}
+
+func parseTextColumn(el *parser.ExprList) (*ir.CategoryIDColumn, error) {
+	help := "TEXT(col_name, whitespace|word|char, MAX_LEN[, VOCABULARY_SIZE])"
+	if len(*el) != 4 && len(*el) != 5 {
+		return nil, fmt.Errorf("bad TEXT expression format: %s, should be like: %s", *el, help)
+	}
+	key, err := expression2string((*el)[1])
+	if err != nil {
+		return nil, fmt.Errorf("bad TEXT key: %s, err: %s, should be like: %s", (*el)[1], err, help)
+	}
+	tokenizer, err := expression2string((*el)[2])
+	if err != nil {
+		return nil, fmt.Errorf("bad TEXT tokenizer: %s, err: %s, should be like: %s", (*el)[2], err, help)
+	}
+	tokenizer = strings.ToLower(strings.Trim(tokenizer, "'"))
+	if tokenizer != "whitespace" && tokenizer != "word" && tokenizer != "char" {
+		return nil, fmt.Errorf("bad TEXT tokenizer: %s, should be like: %s", tokenizer, help)
+	}
+	maxLen, err := strconv.Atoi((*el)[3].Value)
+	if err != nil || maxLen <= 0 {
+		return nil, fmt.Errorf("bad TEXT max_len: %s, should be a positive integer", (*el)[3].Value)
+	}
+	vocabularySize := defaultTextVocabularySize
+	if len(*el) == 5 {
+		vocabularySize, err = strconv.Atoi((*el)[4].Value)
+		if err != nil || vocabularySize <= 0 {
+			return nil, fmt.Errorf("bad TEXT vocabulary size: %s, should be a positive integer", (*el)[4].Value)
+		}
+	}
+	// feature derivation builds the vocabulary and updates MaxID and the bucket size
+	return &ir.CategoryIDColumn{
+		FieldDesc: &ir.FieldDesc{
+			Name:      key,
+			DType:     ir.Int,
+			Shape:     []int{maxLen},
+			IsSparse:  false,
+			MaxID:     int64(vocabularySize),
+			Tokenizer: tokenizer,
+		},
+		BucketSize: int64(vocabularySize + 1),
+	}, nil
+}
+
 func parseBucketColumn(el *parser.ExprList) (*ir.BucketColumn, error) {
 	help := "BUCKET(NUMERIC(...), BOUNDARIES)"
 	if len(*el) != 3 {
@@ -911,6 +1092,12 @@ func parseValidationSelect(attrs map[string]interface{}) (string, error) {
 	return "", fmt.Errorf("validation.select not found")
 }
 
+// isPredictToClient returns true if the result table of PREDICT is
+// omitted or STDOUT.
+func isPredictToClient(into string) bool {
+	return into == "" || strings.EqualFold(into, "stdout")
+}
+
 // parseResultTable parse out the table name from the INTO statement
 // as the following 3 cases:
 // db.table.class_col -> db.table, class_col # cut the column name, using the specified db.
@@ -960,3 +1147,19 @@ func generateShowTrainStmt(showTrain *parser.SQLFlowSelectStmt) (*ir.ShowTrainSt
 		ModelName: showTrain.ShowTrainClause.ModelName,
 	}, nil
 }
+
+func generateDropModelStmt(dropModel *parser.SQLFlowSelectStmt) (*ir.DropModelStmt, error) {
+	return &ir.DropModelStmt{
+		ModelName: dropModel.DropModelClause.ModelToDrop,
+	}, nil
+}
+
+func generateShowModelsStmt(showModels *parser.SQLFlowSelectStmt) (*ir.ShowModelsStmt, error) {
+	return &ir.ShowModelsStmt{}, nil
+}
+
+func generateShowLineageStmt(showLineage *parser.SQLFlowSelectStmt) (*ir.ShowLineageStmt, error) {
+	return &ir.ShowLineageStmt{
+		ModelName: showLineage.ShowLineageClause.LineageModel,
+	}, nil
+}
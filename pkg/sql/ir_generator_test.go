@@ -17,12 +17,14 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
 
 	"sqlflow.org/sqlflow/pkg/database"
 
 	"github.com/stretchr/testify/assert"
 	"sqlflow.org/sqlflow/pkg/ir"
+	"sqlflow.org/sqlflow/pkg/model"
 	"sqlflow.org/sqlflow/pkg/parser"
 	pb "sqlflow.org/sqlflow/pkg/proto"
 )
@@ -246,6 +248,35 @@ func TestGenerateTrainStmtWithTypeCheck(t *testing.T) {
 	a.Equal("mymodel", trainStmt.Into)
 }
 
+func TestGenerateTrainStmtWithResources(t *testing.T) {
+	a := assert.New(t)
+	normal := `SELECT c1, c2, c3, c4 FROM my_table
+	TO TRAIN DNNClassifier
+	WITH
+		model.n_classes=2,
+		model.hidden_units=[128,64],
+		resources.gpu=1,
+		resources.cpu=4,
+		resources.memory="8Gi"
+	LABEL c4
+	INTO mymodel;
+	`
+
+	r, e := parser.ParseStatement("mysql", normal)
+	a.NoError(e)
+
+	trainStmt, err := generateTrainStmt(r.SQLFlowSelectStmt, true)
+	a.NoError(err)
+	a.Equal(ir.Resources{CPU: 4, GPU: 1, Memory: "8Gi"}, trainStmt.Resources)
+	_, ok := trainStmt.Attributes["resources.gpu"]
+	a.False(ok)
+
+	r, e = parser.ParseStatement("mysql", strings.Replace(normal, `"8Gi"`, `"8GB"`, 1))
+	a.NoError(e)
+	_, err = generateTrainStmt(r.SQLFlowSelectStmt, false)
+	a.Error(err)
+}
+
 func TestGenerateTrainStmtModelZoo(t *testing.T) {
 	a := assert.New(t)
 
@@ -269,6 +300,157 @@ func TestGenerateTrainStmtModelZoo(t *testing.T) {
 	a.Equal("MyDNNRegressor", trainStmt.Estimator)
 }
 
+func TestGenerateTrainStmtTimeColumns(t *testing.T) {
+	a := assert.New(t)
+
+	normal := `
+	SELECT ts, c2, c4
+	FROM my_table
+	TO TRAIN DNNClassifier
+	WITH model.n_classes=2
+	COLUMN HOUR(ts, CYCLICAL), DAYOFWEEK(ts), c2
+	LABEL c4
+	INTO mymodel;
+	`
+
+	r, e := parser.ParseStatement("mysql", normal)
+	a.NoError(e)
+
+	trainStmt, err := generateTrainStmt(r.SQLFlowSelectStmt, false)
+	a.NoError(err)
+	fcList := trainStmt.Features["feature_columns"]
+	a.Equal(3, len(fcList))
+	nc, ok := fcList[0].(*ir.NumericColumn)
+	a.True(ok)
+	a.Equal("ts", nc.FieldDesc.Name)
+	a.Equal([]string{"hour"}, nc.FieldDesc.TimeParts)
+	a.True(nc.FieldDesc.Cyclical)
+	a.Equal([]int{2}, nc.FieldDesc.Shape)
+	nc, ok = fcList[1].(*ir.NumericColumn)
+	a.True(ok)
+	a.Equal([]string{"dayofweek"}, nc.FieldDesc.TimeParts)
+	a.False(nc.FieldDesc.Cyclical)
+	a.Equal([]int{1}, nc.FieldDesc.Shape)
+
+	bad := strings.Replace(normal, "HOUR(ts, CYCLICAL)", "HOUR(ts, 24)", 1)
+	r, e = parser.ParseStatement("mysql", bad)
+	a.NoError(e)
+	_, err = generateTrainStmt(r.SQLFlowSelectStmt, false)
+	a.Error(err)
+}
+
+func TestGenerateTrainStmtJSONValue(t *testing.T) {
+	a := assert.New(t)
+
+	normal := `
+	SELECT payload, c4
+	FROM my_table
+	TO TRAIN DNNClassifier
+	WITH model.n_classes=2
+	COLUMN JSON_VALUE(payload, '$.age'), JSON_VALUE(payload, "$.scores[0]")
+	LABEL c4
+	INTO mymodel;
+	`
+
+	r, e := parser.ParseStatement("mysql", normal)
+	a.NoError(e)
+
+	trainStmt, err := generateTrainStmt(r.SQLFlowSelectStmt, false)
+	a.NoError(err)
+	fcList := trainStmt.Features["feature_columns"]
+	a.Equal(2, len(fcList))
+	nc, ok := fcList[0].(*ir.NumericColumn)
+	a.True(ok)
+	a.Equal("payload", nc.FieldDesc.Name)
+	a.Equal([]string{"$.age"}, nc.FieldDesc.JSONPaths)
+	nc, ok = fcList[1].(*ir.NumericColumn)
+	a.True(ok)
+	a.Equal([]string{"$.scores[0]"}, nc.FieldDesc.JSONPaths)
+
+	bad := strings.Replace(normal, "'$.age'", "'age'", 1)
+	r, e = parser.ParseStatement("mysql", bad)
+	a.NoError(e)
+	_, err = generateTrainStmt(r.SQLFlowSelectStmt, false)
+	a.Error(err)
+}
+
+func TestGenerateTrainStmtText(t *testing.T) {
+	a := assert.New(t)
+
+	normal := `
+	SELECT title, c4
+	FROM my_table
+	TO TRAIN DNNClassifier
+	WITH model.n_classes=2
+	COLUMN EMBEDDING(TEXT(title, word, 64), 128, mean), TEXT(title, char, 32, 5000)
+	LABEL c4
+	INTO mymodel;
+	`
+
+	r, e := parser.ParseStatement("mysql", normal)
+	a.NoError(e)
+
+	trainStmt, err := generateTrainStmt(r.SQLFlowSelectStmt, false)
+	a.NoError(err)
+	fcList := trainStmt.Features["feature_columns"]
+	a.Equal(2, len(fcList))
+	emb, ok := fcList[0].(*ir.EmbeddingColumn)
+	a.True(ok)
+	cat, ok := emb.CategoryColumn.(*ir.CategoryIDColumn)
+	a.True(ok)
+	a.Equal("title", cat.FieldDesc.Name)
+	a.Equal("word", cat.FieldDesc.Tokenizer)
+	a.Equal([]int{64}, cat.FieldDesc.Shape)
+	a.Equal(int64(defaultTextVocabularySize), cat.FieldDesc.MaxID)
+	cat, ok = fcList[1].(*ir.CategoryIDColumn)
+	a.True(ok)
+	a.Equal("char", cat.FieldDesc.Tokenizer)
+	a.Equal(int64(5000), cat.FieldDesc.MaxID)
+	a.Equal(int64(5001), cat.BucketSize)
+
+	bad := strings.Replace(normal, "TEXT(title, char, 32, 5000)", "TEXT(title, bpe, 32)", 1)
+	r, e = parser.ParseStatement("mysql", bad)
+	a.NoError(e)
+	_, err = generateTrainStmt(r.SQLFlowSelectStmt, false)
+	a.Error(err)
+}
+
+func TestGenerateTrainStmtByPreprocessing(t *testing.T) {
+	a := assert.New(t)
+	r, e := parser.ParseStatement("mysql", `
+	SELECT city, c4
+	FROM my_table
+	TO TRAIN DNNClassifier
+	WITH model.n_classes=2
+	COLUMN EMBEDDING(city, 8, sum)
+	LABEL c4
+	INTO mymodel;
+	`)
+	a.NoError(e)
+
+	// The columns derived at train time from a vocabulary of the
+	// training table.
+	city := &ir.CategoryIDColumn{
+		FieldDesc:  &ir.FieldDesc{Name: "city", DType: ir.String, Shape: []int{1}, Vocabulary: map[string]string{"beijing": "", "hangzhou": ""}, MaxID: 2},
+		BucketSize: 2,
+	}
+	derived := &ir.TrainStmt{
+		Features: map[string][]ir.FeatureColumn{
+			"feature_columns": {&ir.EmbeddingColumn{CategoryColumn: city, Dimension: 8, Combiner: "sum", Name: "city"}},
+		},
+		Label: &ir.NumericColumn{FieldDesc: &ir.FieldDesc{Name: "c4", DType: ir.Int, Shape: []int{1}}},
+	}
+	p, e := model.PreprocessingOf(derived)
+	a.NoError(e)
+
+	trainStmt, err := generateTrainStmtByPreprocessing(r.SQLFlowSelectStmt, p)
+	a.NoError(err)
+	a.Equal("DNNClassifier", trainStmt.Estimator)
+	a.Equal(2, trainStmt.Attributes["model.n_classes"])
+	a.Equal(derived.Features, trainStmt.Features)
+	a.Equal(derived.Label, trainStmt.Label)
+}
+
 func TestGeneratePredictStmt(t *testing.T) {
 	if getEnv("SQLFLOW_TEST_DB", "mysql") == "hive" {
 		t.Skip(fmt.Sprintf("%s: skip Hive test", getEnv("SQLFLOW_TEST_DB", "mysql")))
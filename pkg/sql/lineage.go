@@ -0,0 +1,61 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"sqlflow.org/sqlflow/pkg/model"
+)
+
+// reSourceTable matches the table after FROM or JOIN, which may be
+// qualified by the database and quoted by backticks.
+var reSourceTable = regexp.MustCompile("(?i)\\b(?:FROM|JOIN)\\s+(`?\\w+`?(?:\\s*\\.\\s*`?\\w+`?)?)")
+
+// sourceTables returns the sorted tables read by the SELECT statement
+// slct. Subqueries are looked into, but tables read by views are not.
+func sourceTables(slct string) []string {
+	seen := map[string]bool{}
+	tables := []string{}
+	for _, m := range reSourceTable.FindAllStringSubmatch(slct, -1) {
+		t := strings.NewReplacer("`", "", " ", "", "\t", "", "\n", "").Replace(m[1])
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		tables = append(tables, t)
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+// recordLineage records the lineage of the model in the model zoo
+// database, which is MySQL only now, and does nothing for the other
+// databases.
+func (s *defaultSubmitter) recordLineage(action, modelName, originalSQL, slct, parentModel, outputTable string) error {
+	if s.Db.DriverName != "mysql" {
+		return nil
+	}
+	return model.RecordLineage(&model.Lineage{
+		Model:         modelName,
+		Action:        action,
+		StatementHash: model.StatementHash(originalSQL),
+		SourceTables:  sourceTables(slct),
+		SourceSelect:  slct,
+		ParentModel:   parentModel,
+		OutputTable:   outputTable,
+	}, s.Db)
+}
@@ -0,0 +1,29 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSourceTables(t *testing.T) {
+	a := assert.New(t)
+	a.Equal([]string{"iris.train"}, sourceTables("SELECT * FROM iris.train"))
+	a.Equal([]string{"iris.train"}, sourceTables("select * from `iris` . `train` where class < 2"))
+	a.Equal([]string{"iris.test", "iris.train", "weights"},
+		sourceTables("SELECT * FROM iris.train t JOIN weights w ON t.id = w.id WHERE t.id IN (SELECT id FROM iris.test) AND 1 IN (SELECT 1 FROM iris.train)"))
+	a.Equal([]string{}, sourceTables("SELECT 1"))
+}
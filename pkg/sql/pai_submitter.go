@@ -30,7 +30,9 @@ import (
 	"sqlflow.org/gomaxcompute"
 	"sqlflow.org/sqlflow/pkg/database"
 	"sqlflow.org/sqlflow/pkg/ir"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/evaluation"
 	"sqlflow.org/sqlflow/pkg/sql/codegen/pai"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/prediction"
 )
 
 const (
@@ -148,6 +150,9 @@ func createPAIHyperParamFile(cwd string, filename string, modelPath string) erro
 // 1. argo mode server: generate a step running: bash -c "repl -e \"select * from xx to train\""
 // 2. non-argo mode server | repl -e: create tmp table in go, and use it to train
 func (s *paiSubmitter) ExecuteTrain(cl *ir.TrainStmt) (e error) {
+	if e = checkData(s.Writer, s.Db, cl); e != nil {
+		return e
+	}
 	cl.TmpTrainTable, cl.TmpValidateTable, e = createTempTrainAndValTable(cl.Select, cl.ValidationSelect, s.Session.DbConnStr)
 	if e != nil {
 		return
@@ -212,6 +217,12 @@ func (s *paiSubmitter) submitPAITask(code, paiCmd, requirements string) error {
 }
 
 func (s *paiSubmitter) ExecutePredict(cl *ir.PredictStmt) error {
+	if prediction.Partition(cl.Attributes) != "" {
+		return fmt.Errorf("predict.partition is not supported on PAI")
+	}
+	if cl.ResultTable == "" {
+		return fmt.Errorf("predicting to the client is not supported on PAI, please specify the result table")
+	}
 	// TODO(typhoonzero): Do **NOT** create tmp table when the select statement is like:
 	// "SELECT fields,... FROM table"
 	dbName, tableName, err := createTmpTableFromSelect(cl.Select, s.Session.DbConnStr)
@@ -287,7 +298,7 @@ func (s *paiSubmitter) ExecuteExplain(cl *ir.ExplainStmt) error {
 		if len(resultTableParts) == 1 {
 			cl.Into = fmt.Sprintf("%s.%s", currProject, cl.Into)
 		}
-		db, err := database.OpenAndConnectDB(s.Session.DbConnStr)
+		db, err := database.OpenAndConnectDBWithSession(s.Session)
 		if err != nil {
 			return err
 		}
@@ -315,6 +326,9 @@ func (s *paiSubmitter) ExecuteExplain(cl *ir.ExplainStmt) error {
 }
 
 func (s *paiSubmitter) ExecuteEvaluate(cl *ir.EvaluateStmt) error {
+	if evaluation.ResultSchema(cl.Attributes) == evaluation.LongSchema {
+		return fmt.Errorf("validation.result_schema=%q is not supported on PAI", evaluation.LongSchema)
+	}
 	// TODO(typhoonzero): Do **NOT** create tmp table when the select statement is like:
 	// "SELECT fields,... FROM table"
 	dbName, tableName, err := createTmpTableFromSelect(cl.Select, s.Session.DbConnStr)
@@ -346,7 +360,7 @@ func (s *paiSubmitter) ExecuteEvaluate(cl *ir.EvaluateStmt) error {
 		if len(resultTableParts) == 1 {
 			cl.Into = fmt.Sprintf("%s.%s", currProject, cl.Into)
 		}
-		db, err := database.OpenAndConnectDB(s.Session.DbConnStr)
+		db, err := database.OpenAndConnectDBWithSession(s.Session)
 		if err != nil {
 			return err
 		}
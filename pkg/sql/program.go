@@ -0,0 +1,141 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+
+	"sqlflow.org/sqlflow/pkg/ir"
+	"sqlflow.org/sqlflow/pkg/jobstore"
+	"sqlflow.org/sqlflow/pkg/pipe"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/catboost"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/explainer"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/forecast"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/lightgbm"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/pytorch"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/sklearn"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/tensorflow"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/xgboost"
+)
+
+// trainProgram returns the Python program that the default submitter
+// runs to train cl.
+func trainProgram(cl *ir.TrainStmt, session *pb.Session) (string, error) {
+	switch {
+	case forecast.Supports(cl.Estimator):
+		return forecast.Train(cl, session)
+	case isXGBoostModel(cl.Estimator):
+		return xgboost.Train(cl, session)
+	case isPyTorchModel(cl.Estimator):
+		return pytorch.Train(cl, session)
+	case isLightGBMModel(cl.Estimator):
+		return lightgbm.Train(cl, session)
+	case isCatBoostModel(cl.Estimator):
+		return catboost.Train(cl, session)
+	case isSKLearnModel(cl.Estimator):
+		return sklearn.Train(cl, session)
+	default:
+		return tensorflow.Train(cl, session)
+	}
+}
+
+// predProgram returns the Python program that the default submitter runs
+// to predict by cl.
+func predProgram(cl *ir.PredictStmt, session *pb.Session) (string, error) {
+	switch estimator := cl.TrainStmt.Estimator; {
+	case forecast.Supports(estimator):
+		return forecast.Pred(cl, session)
+	case isXGBoostModel(estimator):
+		return xgboost.Pred(cl, session)
+	case isPyTorchModel(estimator):
+		return pytorch.Pred(cl, session)
+	case isLightGBMModel(estimator):
+		return lightgbm.Pred(cl, session)
+	case isCatBoostModel(estimator):
+		return catboost.Pred(cl, session)
+	case isSKLearnModel(estimator):
+		return sklearn.Pred(cl, session)
+	default:
+		return tensorflow.Pred(cl, session)
+	}
+}
+
+// explainProgram returns the Python program that the default submitter
+// runs to explain by cl.
+func explainProgram(cl *ir.ExplainStmt, session *pb.Session) (string, error) {
+	switch estimator := cl.TrainStmt.Estimator; {
+	case forecast.Supports(estimator):
+		return "", fmt.Errorf("TO EXPLAIN doesn't support the forecasting model %s", estimator)
+	case explainer.Supports(estimator):
+		return explainer.Explain(cl, session)
+	case isXGBoostModel(estimator):
+		return xgboost.Explain(cl, session)
+	default:
+		return tensorflow.Explain(cl, session)
+	}
+}
+
+// evaluateProgram returns the Python program that the default submitter
+// runs to evaluate by cl.
+func evaluateProgram(cl *ir.EvaluateStmt, session *pb.Session) (string, error) {
+	switch estimator := cl.TrainStmt.Estimator; {
+	case forecast.Supports(estimator):
+		return "", fmt.Errorf("TO EVALUATE doesn't support the forecasting model %s", estimator)
+	case isXGBoostModel(estimator):
+		return xgboost.Evaluate(cl, session)
+	case isPyTorchModel(estimator):
+		return pytorch.Evaluate(cl, session)
+	case isLightGBMModel(estimator):
+		return lightgbm.Evaluate(cl, session)
+	case isCatBoostModel(estimator):
+		return catboost.Evaluate(cl, session)
+	case isSKLearnModel(estimator):
+		return sklearn.Evaluate(cl, session)
+	default:
+		return tensorflow.Evaluate(cl, session)
+	}
+}
+
+// dryRun writes the program generated for r to wr without running it.
+// The program is generated from the session without the credentials,
+// since session has the secrets resolved.
+func dryRun(wr *pipe.Writer, r ir.SQLFlowStmt, session *pb.Session) error {
+	session = jobstore.Redact(session)
+	var code string
+	var err error
+	switch s := r.(type) {
+	case *ir.TrainStmt:
+		code, err = trainProgram(s, session)
+	case *ir.PredictStmt:
+		if s.ResultTable == "" {
+			// The prediction to the client writes a temporary table
+			// first, see predictToClient.
+			cl := *s
+			cl.ResultTable = tmpPredictionTable(s.Using, session)
+			s = &cl
+		}
+		code, err = predProgram(s, session)
+	case *ir.ExplainStmt:
+		code, err = explainProgram(s, session)
+	case *ir.EvaluateStmt:
+		code, err = evaluateProgram(s, session)
+	default:
+		return wr.Write(fmt.Sprintf("Skipped in the dry run: %s", r.GetOriginalSQL()))
+	}
+	if err != nil {
+		return err
+	}
+	return wr.Write(fmt.Sprintf("Generated program of %s:\n%s", r.GetOriginalSQL(), code))
+}
@@ -0,0 +1,75 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// progressPrefix starts the lines of the progress events printed by
+// sqlflow_submitter.progress.report in the training programs.
+const progressPrefix = "SQLFLOW_PROGRESS "
+
+// Progress is a progress event of a TRAIN statement, which is pushed to
+// the pipe instead of the log line of the event.
+type Progress struct {
+	Epoch  int   `json:"epoch"`  // Epoch is 1-based, or the boosting round of XGBoost.
+	Epochs int   `json:"epochs"` // Epochs is the number of epochs, 0 if unknown.
+	Step   int64 `json:"step"`   // Step is 1-based in the epoch, 0 at the end of the epoch.
+	Steps  int64 `json:"steps"`  // Steps is the number of steps in an epoch, 0 if unknown.
+	// Metrics are like loss, accuracy, and the validation ones like val_loss.
+	Metrics map[string]float64 `json:"metrics,omitempty"`
+}
+
+// parseProgress returns the progress event in line, or false if line is
+// not one.
+func parseProgress(line string) (Progress, bool) {
+	p := Progress{}
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, progressPrefix) {
+		return p, false
+	}
+	if e := json.Unmarshal([]byte(line[len(progressPrefix):]), &p); e != nil || p.Epoch <= 0 {
+		return p, false
+	}
+	return p, true
+}
+
+// String returns the progress like "epoch 2/10, step 30/100, loss: 0.5",
+// with the metrics sorted by name.
+func (p Progress) String() string {
+	s := []string{fmt.Sprintf("epoch %d", p.Epoch)}
+	if p.Epochs > 0 {
+		s[0] += fmt.Sprintf("/%d", p.Epochs)
+	}
+	if p.Step > 0 {
+		step := fmt.Sprintf("step %d", p.Step)
+		if p.Steps > 0 {
+			step += fmt.Sprintf("/%d", p.Steps)
+		}
+		s = append(s, step)
+	}
+	names := []string{}
+	for name := range p.Metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		s = append(s, fmt.Sprintf("%s: %g", name, p.Metrics[name]))
+	}
+	return strings.Join(s, ", ")
+}
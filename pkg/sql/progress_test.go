@@ -0,0 +1,63 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sqlflow.org/sqlflow/pkg/pipe"
+)
+
+func TestParseProgress(t *testing.T) {
+	a := assert.New(t)
+	p, ok := parseProgress(`SQLFLOW_PROGRESS {"epoch": 2, "epochs": 10, "step": 30, "steps": 100, "metrics": {"loss": 0.5, "accuracy": 0.75}}` + "\n")
+	a.True(ok)
+	a.Equal(Progress{Epoch: 2, Epochs: 10, Step: 30, Steps: 100, Metrics: map[string]float64{"loss": 0.5, "accuracy": 0.75}}, p)
+	a.Equal("epoch 2/10, step 30/100, accuracy: 0.75, loss: 0.5", p.String())
+
+	p, ok = parseProgress(`SQLFLOW_PROGRESS {"epoch": 3, "epochs": 0}`)
+	a.True(ok)
+	a.Equal("epoch 3", p.String())
+
+	for _, line := range []string{
+		"epoch 1, step 0, loss: 0.5",
+		"SQLFLOW_PROGRESS {",
+		`SQLFLOW_PROGRESS {"epochs": 10}`,
+	} {
+		_, ok = parseProgress(line)
+		a.False(ok, line)
+	}
+}
+
+func TestLogChanWriterProgress(t *testing.T) {
+	a := assert.New(t)
+	rd, wr := pipe.Pipe()
+	epochs := []int{}
+	go func() {
+		defer wr.Close()
+		cw := &logChanWriter{wr: wr, onProgress: func(p Progress) { epochs = append(epochs, p.Epoch) }}
+		cw.Write([]byte("Start training\nSQLFLOW_PROGRESS {\"epoch\": 1, "))
+		cw.Write([]byte("\"epochs\": 2}\nSQLFLOW_PROGRESS {\"epoch\": 2, \"epochs\": 2}"))
+		cw.Close()
+	}()
+
+	c := rd.ReadAll()
+	a.Equal("Start training\n", <-c)
+	a.Equal(Progress{Epoch: 1, Epochs: 2}, <-c)
+	a.Equal(Progress{Epoch: 2, Epochs: 2}, <-c)
+	_, more := <-c
+	a.False(more)
+	a.Equal([]int{1, 2}, epochs)
+}
@@ -0,0 +1,126 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+	"strings"
+
+	"sqlflow.org/sqlflow/pkg/database"
+	"sqlflow.org/sqlflow/pkg/model"
+	"sqlflow.org/sqlflow/pkg/parser"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/prediction"
+	"sqlflow.org/sqlflow/pkg/step/feature"
+	"sqlflow.org/sqlflow/pkg/verifier"
+)
+
+// maxUnseenCategories is the max number of the unseen categories of a
+// column in the error of verifySchema.
+const maxUnseenCategories = 10
+
+// verifySchema compares the columns of the SELECT of pr with the columns
+// that the model of the schema s is trained on, before the code generation,
+// and returns the differences as the error. With WITH
+// predict.unseen_categories="error", it also checks the values of the
+// categorical columns of PREDICT are all seen at the training.
+func verifySchema(s *model.Schema, pr *parser.SQLFlowSelectStmt, db *database.DB) error {
+	slct := strings.TrimSuffix(strings.TrimSpace(pr.StandardSelect.String()), ";")
+	fields, e := verifier.Verify(slct, db)
+	if e != nil {
+		return e
+	}
+	// columns maps the lower-cased names like Schema.Diff, and names
+	// keeps the names as the database returns them for the queries.
+	columns := map[string]string{}
+	names := map[string]string{}
+	for name, typ := range fields {
+		columns[strings.ToLower(name)] = feature.UnifiedTypeName(typ)
+		names[strings.ToLower(name)] = name
+	}
+	d := s.Diff(columns, pr.Evaluate)
+	if pr.Predict {
+		attrs, e := generateAttributeIR(&pr.PredAttrs)
+		if e != nil {
+			return e
+		}
+		if prediction.UnseenCategories(attrs) == prediction.ErrorOnUnseen {
+			if d.Unseen, e = unseenCategories(db, slct, s, names); e != nil {
+				return e
+			}
+		}
+	}
+	if !d.Empty() {
+		return fmt.Errorf("the SELECT doesn't match the columns of the model:\n%s", d)
+	}
+	return nil
+}
+
+// unseenCategories returns the values of the categorical columns of s in
+// slct, which are not in their Categories. names maps the lower-cased
+// names of the columns of slct to their names in the database.
+func unseenCategories(db *database.DB, slct string, s *model.Schema, names map[string]string) ([]model.UnseenCategories, error) {
+	unseen := []model.UnseenCategories{}
+	seen := map[string]bool{}
+	for _, f := range s.Features {
+		name := strings.ToLower(f.Name)
+		column, ok := names[name]
+		if !ok || len(f.Categories) == 0 || seen[name] {
+			continue
+		}
+		seen[name] = true
+		rows, e := db.Query(unseenCategoriesQuery(db.DriverName, slct, column, f.Categories))
+		if e != nil {
+			return nil, fmt.Errorf("query the unseen categories of %s failed: %v", f.Name, e)
+		}
+		values := []string{}
+		for rows.Next() {
+			var v string
+			if e := rows.Scan(&v); e != nil {
+				rows.Close()
+				return nil, e
+			}
+			values = append(values, v)
+		}
+		e = rows.Err()
+		rows.Close()
+		if e != nil {
+			return nil, e
+		}
+		if len(values) > 0 {
+			unseen = append(unseen, model.UnseenCategories{Column: f.Name, Values: values})
+		}
+	}
+	return unseen, nil
+}
+
+// unseenCategoriesQuery returns the query of the distinct values of column
+// in slct that are not in categories, up to maxUnseenCategories. column is
+// quoted by the driver, so its case is kept.
+func unseenCategoriesQuery(driver, slct, column string, categories []string) string {
+	quoted := []string{}
+	for _, c := range categories {
+		quoted = append(quoted, "'"+strings.Replace(c, "'", "''", -1)+"'")
+	}
+	return fmt.Sprintf("SELECT DISTINCT %[1]s FROM (%[2]s) AS sqlflow_unseen WHERE %[1]s NOT IN (%[3]s) ORDER BY %[1]s LIMIT %[4]d",
+		quoteIdentifier(driver, column), slct, strings.Join(quoted, ", "), maxUnseenCategories)
+}
+
+// quoteIdentifier quotes the column name by double quotes for PostgreSQL,
+// and by backquotes for the other databases.
+func quoteIdentifier(driver, name string) string {
+	if driver == "postgres" {
+		return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+	}
+	return "`" + strings.Replace(name, "`", "``", -1) + "`"
+}
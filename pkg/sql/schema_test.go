@@ -0,0 +1,77 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sqlflow.org/sqlflow/pkg/database"
+	"sqlflow.org/sqlflow/pkg/model"
+	"sqlflow.org/sqlflow/pkg/parser"
+)
+
+func TestVerifySchema(t *testing.T) {
+	a := assert.New(t)
+	dir, e := ioutil.TempDir("", "sqlflow_schema")
+	a.NoError(e)
+	defer os.RemoveAll(dir)
+	db, e := database.OpenAndConnectDB("sqlite3://" + filepath.Join(dir, "schema.db"))
+	a.NoError(e)
+	defer db.Close()
+	for _, stmt := range []string{
+		"CREATE TABLE people (age int, income real, gender varchar(16), class int, Region varchar(16))",
+		"INSERT INTO people VALUES (30, 100, 'MALE', 0, 'cn'), (40, 200, 'FEMALE', 1, 'cn'), (50, 300, 'UNKNOWN', 1, 'us'), (60, 400, NULL, 0, 'ca')",
+	} {
+		_, e = db.Exec(stmt)
+		a.NoError(e)
+	}
+	s := &model.Schema{
+		Features: []model.Field{
+			{Name: "age", DType: "int", Type: "INT"},
+			{Name: "income", DType: "float", Type: "DOUBLE"},
+			{Name: "gender", DType: "string", Type: "VARCHAR", Categories: []string{"FEMALE", "MALE"}},
+			{Name: "Region", DType: "string", Type: "VARCHAR", Categories: []string{"cn", "us"}},
+		},
+		Label: &model.Field{Name: "class", DType: "int", Type: "INT"},
+	}
+	verify := func(sql string) error {
+		pr, e := parser.ParseStatement("mysql", sql)
+		a.NoError(e)
+		return verifySchema(s, pr.SQLFlowSelectStmt, db)
+	}
+
+	a.NoError(verify(`SELECT * FROM people TO PREDICT people_predict.class USING my_model;`))
+	a.NoError(verify(`SELECT * FROM people TO EVALUATE my_model WITH validation.metrics="Accuracy" LABEL class INTO people_evaluate;`))
+	a.EqualError(verify(`SELECT age, gender, Region FROM people TO PREDICT people_predict.class USING my_model;`),
+		"the SELECT doesn't match the columns of the model:\nmissing columns: income")
+	a.EqualError(verify(`SELECT age, gender, gender AS income, Region FROM people TO PREDICT people_predict.class USING my_model;`),
+		"the SELECT doesn't match the columns of the model:\ncolumn income: VARCHAR, but DOUBLE at the training")
+	a.EqualError(verify(`SELECT age, income, gender, Region FROM people TO EVALUATE my_model WITH validation.metrics="Accuracy" LABEL class INTO people_evaluate;`),
+		"the SELECT doesn't match the columns of the model:\nmissing columns: class")
+	a.EqualError(verify(`SELECT * FROM people TO PREDICT people_predict.class WITH predict.unseen_categories="error" USING my_model;`),
+		"the SELECT doesn't match the columns of the model:\ncolumn gender: unseen categories UNKNOWN\ncolumn Region: unseen categories ca")
+	a.NoError(verify(`SELECT * FROM people WHERE gender <> 'UNKNOWN' AND Region <> 'ca' TO PREDICT people_predict.class WITH predict.unseen_categories="error" USING my_model;`))
+}
+
+func TestUnseenCategoriesQuery(t *testing.T) {
+	a := assert.New(t)
+	a.Equal("SELECT DISTINCT `Gender` FROM (SELECT * FROM people) AS sqlflow_unseen WHERE `Gender` NOT IN ('FEMALE', 'MA''LE') ORDER BY `Gender` LIMIT 10",
+		unseenCategoriesQuery("mysql", "SELECT * FROM people", "Gender", []string{"FEMALE", "MA'LE"}))
+	a.Equal(`SELECT DISTINCT "Gender" FROM (SELECT * FROM people) AS sqlflow_unseen WHERE "Gender" NOT IN ('FEMALE') ORDER BY "Gender" LIMIT 10`,
+		unseenCategoriesQuery("postgres", "SELECT * FROM people", "Gender", []string{"FEMALE"}))
+}
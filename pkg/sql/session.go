@@ -16,19 +16,54 @@ package sql
 import (
 	"os"
 	pb "sqlflow.org/sqlflow/pkg/proto"
+	"strconv"
 	"strings"
 )
 
 // MakeSessionFromEnv returns proto.Session which comes from the environment variables
 func MakeSessionFromEnv() *pb.Session {
 	return &pb.Session{
-		Token:            os.Getenv("SQLFLOW_USER_TOKEN"),
-		DbConnStr:        os.Getenv("SQLFLOW_DATASOURCE"),
-		ExitOnSubmit:     strings.ToLower(os.Getenv("SQLFLOW_EXIT_ON_SUBMIT")) == "true",
-		UserId:           os.Getenv("SQLFLOW_USER_ID"),
-		HiveLocation:     os.Getenv("SQLFLOW_HIVE_LOCATION"),
-		HdfsNamenodeAddr: os.Getenv("SQLFLOW_HDFS_NAMENODE_ADDR"),
-		HdfsUser:         os.Getenv("SQLFLOW_HADOOP_USER"),
-		HdfsPass:         os.Getenv("SQLFLOW_HADOOP_PASS"),
-		Submitter:        os.Getenv("SQLFLOW_submitter")}
+		Token:                        os.Getenv("SQLFLOW_USER_TOKEN"),
+		DbConnStr:                    os.Getenv("SQLFLOW_DATASOURCE"),
+		ExitOnSubmit:                 strings.ToLower(os.Getenv("SQLFLOW_EXIT_ON_SUBMIT")) == "true",
+		UserId:                       os.Getenv("SQLFLOW_USER_ID"),
+		HiveLocation:                 os.Getenv("SQLFLOW_HIVE_LOCATION"),
+		HdfsNamenodeAddr:             os.Getenv("SQLFLOW_HDFS_NAMENODE_ADDR"),
+		HdfsUser:                     os.Getenv("SQLFLOW_HADOOP_USER"),
+		HdfsPass:                     os.Getenv("SQLFLOW_HADOOP_PASS"),
+		Submitter:                    os.Getenv("SQLFLOW_submitter"),
+		S3Region:                     os.Getenv("SQLFLOW_S3_REGION"),
+		S3AccessKeyId:                os.Getenv("SQLFLOW_S3_ACCESS_KEY_ID"),
+		S3SecretAccessKey:            os.Getenv("SQLFLOW_S3_SECRET_ACCESS_KEY"),
+		ModelEncryptionKey:           os.Getenv("SQLFLOW_MODEL_ENCRYPTION_KEY"),
+		GcsCredentialsFile:           os.Getenv("SQLFLOW_GCS_CREDENTIALS_FILE"),
+		AzureAccountName:             os.Getenv("SQLFLOW_AZURE_ACCOUNT_NAME"),
+		AzureAccountKey:              os.Getenv("SQLFLOW_AZURE_ACCOUNT_KEY"),
+		AzureSasToken:                os.Getenv("SQLFLOW_AZURE_SAS_TOKEN"),
+		OssEndpoint:                  os.Getenv("SQLFLOW_OSS_MODEL_ENDPOINT"),
+		OssAccessKeyId:               os.Getenv("SQLFLOW_OSS_AK"),
+		OssAccessKeySecret:           os.Getenv("SQLFLOW_OSS_SK"),
+		HdfsKerberosPrincipal:        os.Getenv("SQLFLOW_HDFS_KERBEROS_PRINCIPAL"),
+		HdfsKerberosKeytab:           os.Getenv("SQLFLOW_HDFS_KERBEROS_KEYTAB"),
+		HdfsKerberosCcache:           os.Getenv("SQLFLOW_HDFS_KERBEROS_CCACHE"),
+		HdfsKerberosServicePrincipal: os.Getenv("SQLFLOW_HDFS_KERBEROS_SERVICE_PRINCIPAL"),
+		DbMaxOpenConns:               int32(atoiEnv("SQLFLOW_DB_MAX_OPEN_CONNS")),
+		DbMaxIdleConns:               int32(atoiEnv("SQLFLOW_DB_MAX_IDLE_CONNS")),
+		DbConnMaxLifetime:            os.Getenv("SQLFLOW_DB_CONN_MAX_LIFETIME"),
+		DbKeepAlive:                  os.Getenv("SQLFLOW_DB_KEEP_ALIVE"),
+		DbRetries:                    int32(atoiEnv("SQLFLOW_DB_RETRIES")),
+		DbRetryDelay:                 os.Getenv("SQLFLOW_DB_RETRY_DELAY"),
+		Resumable:                    strings.ToLower(os.Getenv("SQLFLOW_RESUMABLE")) == "true",
+		NoCache:                      strings.ToLower(os.Getenv("SQLFLOW_NO_CACHE")) == "true",
+		CleanupOnFailure:             os.Getenv("SQLFLOW_CLEANUP_ON_FAILURE"),
+		DryRun:                       strings.ToLower(os.Getenv("SQLFLOW_DRY_RUN")) == "true",
+		ExplainArtifactStore:         os.Getenv("SQLFLOW_EXPLAIN_ARTIFACT_STORE"),
+		ExperimentTracker:            os.Getenv("SQLFLOW_EXPERIMENT_TRACKER")}
+}
+
+// atoiEnv returns the integer in the environment variable env, or 0 if
+// it's not an integer.
+func atoiEnv(env string) int {
+	i, _ := strconv.Atoi(os.Getenv(env))
+	return i
 }
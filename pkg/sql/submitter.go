@@ -17,23 +17,32 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"sqlflow.org/sqlflow/pkg/database"
 	"sqlflow.org/sqlflow/pkg/ir"
 	"sqlflow.org/sqlflow/pkg/model"
 	"sqlflow.org/sqlflow/pkg/pipe"
 	pb "sqlflow.org/sqlflow/pkg/proto"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/evaluation"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/explainer"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/grouping"
 	"sqlflow.org/sqlflow/pkg/sql/codegen/pai"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/prediction"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/snapshot"
 	"sqlflow.org/sqlflow/pkg/sql/codegen/tensorflow"
-	"sqlflow.org/sqlflow/pkg/sql/codegen/xgboost"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/warmstart"
 )
 
 var rePyDiagnosis = regexp.MustCompile("sqlflow_submitter.tensorflow.diag.SQLFlowDiagnostic: (.*)")
@@ -74,6 +83,8 @@ type logChanWriter struct {
 	m    sync.Mutex
 	buf  bytes.Buffer
 	prev string
+	// onProgress, if not nil, is called with each progress event.
+	onProgress func(Progress)
 }
 
 func (cw *logChanWriter) Write(p []byte) (n int, err error) {
@@ -93,7 +104,7 @@ func (cw *logChanWriter) Write(p []byte) (n int, err error) {
 		if err != nil {
 			break
 		}
-		if err := cw.wr.Write(cw.prev); err != nil {
+		if err := cw.writeLine(cw.prev); err != nil {
 			return len(cw.prev), err
 		}
 		cw.prev = ""
@@ -101,9 +112,20 @@ func (cw *logChanWriter) Write(p []byte) (n int, err error) {
 	return n, nil
 }
 
+// writeLine writes the progress event in line, or else line, to cw.wr.
+func (cw *logChanWriter) writeLine(line string) error {
+	if p, ok := parseProgress(line); ok {
+		if cw.onProgress != nil {
+			cw.onProgress(p)
+		}
+		return cw.wr.Write(p)
+	}
+	return cw.wr.Write(line)
+}
+
 func (cw *logChanWriter) Close() {
 	if len(cw.prev) > 0 {
-		cw.wr.Write(cw.prev)
+		cw.writeLine(cw.prev)
 		cw.prev = ""
 	}
 }
@@ -114,6 +136,9 @@ type defaultSubmitter struct {
 	ModelDir string
 	Cwd      string
 	Session  *pb.Session
+	// onProgress, if not nil, is called with the progress events of the
+	// programs run by runCommand.
+	onProgress func(Progress)
 }
 
 func (s *defaultSubmitter) Setup(w *pipe.Writer, db *database.DB, modelDir string, cwd string, session *pb.Session) {
@@ -121,17 +146,61 @@ func (s *defaultSubmitter) Setup(w *pipe.Writer, db *database.DB, modelDir strin
 	s.Writer, s.Db, s.ModelDir, s.Cwd, s.Session = w, db, modelDir, cwd, session
 }
 
+// modelURI returns where SaveModel saves the model trained by cl.
+func (s *defaultSubmitter) modelURI(cl *ir.TrainStmt) string {
+	return s.modelURIOf(cl.Into)
+}
+
+// modelURIOf returns the URI of the model saved as name.
+func (s *defaultSubmitter) modelURIOf(name string) string {
+	return ModelURI(s.ModelDir, name)
+}
+
+// ModelURI returns the URI of the model saved as name by the statements
+// run with modelDir, a file URI under modelDir if it's not empty, or else
+// name, which is the table in the database.
+func ModelURI(modelDir, name string) string {
+	if modelDir != "" {
+		return "file://" + filepath.Join(modelDir, name)
+	}
+	return name
+}
+
+// loadWarmStart loads the model to warm start the training of cl from
+// into warmstart.Dir under s.Cwd, where the training program reads it.
+func (s *defaultSubmitter) loadWarmStart(cl *ir.TrainStmt) error {
+	m, e := model.Load(s.modelURIOf(warmstart.Model(cl.Attributes)), filepath.Join(s.Cwd, warmstart.Dir), s.Db)
+	if e != nil {
+		return fmt.Errorf("load the model to warm start from: %v", e)
+	}
+	return m.CheckWarmStart(cl)
+}
+
+// uploadDataSnapshot uploads the snapshot of the training data of cl in
+// snapshot.Dir under s.Cwd to the URI of the snapshot, and removes the
+// snapshot from s.Cwd, so the saved model doesn't include it.
+func (s *defaultSubmitter) uploadDataSnapshot(cl *ir.TrainStmt) error {
+	dir := filepath.Join(s.Cwd, snapshot.Dir)
+	files, e := ioutil.ReadDir(dir)
+	if e != nil {
+		return fmt.Errorf("read the snapshot of the training data: %v", e)
+	}
+	for _, f := range files {
+		uri := snapshot.URI(cl.Attributes) + "/" + f.Name()
+		if e := model.PutFile(uri, filepath.Join(dir, f.Name()), s.Session); e != nil {
+			return fmt.Errorf("upload the snapshot of the training data to %s: %v", uri, e)
+		}
+	}
+	return os.RemoveAll(dir)
+}
+
 func (s *defaultSubmitter) SaveModel(cl *ir.TrainStmt) error {
 	m := model.New(s.Cwd, cl.OriginalSQL)
-	modelURI := cl.Into
-	if s.ModelDir != "" {
-		modelURI = fmt.Sprintf("file://%s/%s", s.ModelDir, cl.Into)
-	}
-	return m.Save(modelURI, cl, s.Session)
+	return m.Save(s.modelURI(cl), cl, s.Session)
 }
 
 func (s *defaultSubmitter) runCommand(program string) error {
-	cw := &logChanWriter{wr: s.Writer}
+	cw := &logChanWriter{wr: s.Writer, onProgress: s.onProgress}
 	var stderr bytes.Buffer
 	var stdout bytes.Buffer
 	w := io.MultiWriter(cw, &stdout)
@@ -139,7 +208,10 @@ func (s *defaultSubmitter) runCommand(program string) error {
 	defer cw.Close()
 	cmd := sqlflowCmd(s.Cwd, s.Db.DriverName)
 	cmd.Stdin, cmd.Stdout, cmd.Stderr = bytes.NewBufferString(program), w, wStderr
-	if e := cmd.Run(); e != nil {
+	if e := runCmd(cmd, s.Writer.Done()); e != nil {
+		if e == errCancelled {
+			return e
+		}
 		// return the diagnostic message
 		sub := rePyDiagnosis.FindStringSubmatch(stderr.String())
 		if len(sub) == 2 {
@@ -155,66 +227,134 @@ func (s *defaultSubmitter) ExecuteQuery(sql *ir.NormalStmt) error {
 	return runNormalStmt(s.Writer, string(*sql), s.Db)
 }
 
-func (s *defaultSubmitter) ExecuteTrain(cl *ir.TrainStmt) (e error) {
-	var code string
-	if isXGBoostModel(cl.Estimator) {
-		if code, e = xgboost.Train(cl, s.Session); e != nil {
+func (s *defaultSubmitter) ExecuteTrain(cl *ir.TrainStmt) error {
+	if s.Session.ExperimentTracker == "" {
+		return s.train(cl)
+	}
+	return s.trackTrain(cl)
+}
+
+func (s *defaultSubmitter) train(cl *ir.TrainStmt) (e error) {
+	// Fail before the training if the model can't be saved.
+	if e := model.Validate(s.modelURI(cl), s.Session); e != nil {
+		return e
+	}
+	if e := checkData(s.Writer, s.Db, cl); e != nil {
+		return e
+	}
+	if warmstart.Model(cl.Attributes) != "" {
+		if e := s.loadWarmStart(cl); e != nil {
 			return e
 		}
-	} else {
-		if code, e = tensorflow.Train(cl, s.Session); e != nil {
+	}
+	code, e := trainProgram(cl, s.Session)
+	if e != nil {
+		return e
+	}
+	if e := s.runCommand(code); e != nil {
+		return e
+	}
+	// Only the chief of the distributed training saves the model.
+	if !tensorflow.IsChief() {
+		return nil
+	}
+	if warmstart.Model(cl.Attributes) != "" {
+		// The trained model doesn't include the model it started from.
+		if e := os.RemoveAll(filepath.Join(s.Cwd, warmstart.Dir)); e != nil {
 			return e
 		}
 	}
-	if e := s.runCommand(code); e != nil {
+	if snapshot.URI(cl.Attributes) != "" {
+		if e := s.uploadDataSnapshot(cl); e != nil {
+			return e
+		}
+	}
+	if e := s.SaveModel(cl); e != nil {
 		return e
 	}
-	return s.SaveModel(cl)
+	return s.recordLineage("train", cl.Into, cl.OriginalSQL, cl.Select, warmstart.Model(cl.Attributes), "")
 }
 
 func (s *defaultSubmitter) ExecutePredict(cl *ir.PredictStmt) (e error) {
+	if cl.ResultTable == "" {
+		e = s.predictToClient(cl)
+	} else {
+		e = s.predict(cl)
+	}
+	if e != nil {
+		return e
+	}
+	return s.recordLineage("predict", cl.Using, cl.OriginalSQL, cl.Select, "", cl.ResultTable)
+}
+
+func (s *defaultSubmitter) predict(cl *ir.PredictStmt) (e error) {
 	// NOTE(typhoonzero): model is already loaded under s.Cwd
 	if e = createPredictionTableFromIR(cl, s.Db, s.Session); e != nil {
 		return e
 	}
 
-	var code string
-	if isXGBoostModel(cl.TrainStmt.Estimator) {
-		if code, e = xgboost.Pred(cl, s.Session); e != nil {
-			return e
-		}
-	} else {
-		if code, e = tensorflow.Pred(cl, s.Session); e != nil {
-			return e
-		}
+	code, e := predProgram(cl, s.Session)
+	if e != nil {
+		return e
 	}
 	return s.runCommand(code)
 }
 
+// predictToClient writes the prediction result of cl to a temporary
+// table, and streams the rows of the table to the client like a SELECT.
+func (s *defaultSubmitter) predictToClient(cl *ir.PredictStmt) (e error) {
+	if prediction.Partition(cl.Attributes) != "" {
+		return fmt.Errorf("predict.partition requires the result table")
+	}
+	cl.ResultTable = tmpPredictionTable(cl.Using, s.Session)
+	cl.Attributes["predict.write_mode"] = prediction.Overwrite
+	defer func(table string) {
+		cl.ResultTable = ""
+		if _, err := s.Db.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", table)); err != nil && e == nil {
+			e = err
+		}
+	}(cl.ResultTable)
+	if e = s.predict(cl); e != nil {
+		return e
+	}
+	return runQuery(s.Writer, fmt.Sprintf("SELECT * FROM %s", cl.ResultTable), s.Db)
+}
+
+// tmpPredictionTable returns a random table name in the database of the
+// data source, or of the model using if the data source has none.
+func tmpPredictionTable(using string, session *pb.Session) string {
+	table := "sqlflow_tmp_predict_" + strings.ToLower(randStringRunes(16))
+	if db, e := database.GetDatabaseName(session.DbConnStr); e == nil && db != "" {
+		return db + "." + table
+	}
+	if i := strings.LastIndex(using, "."); i > 0 {
+		return using[:i] + "." + table
+	}
+	return table
+}
+
 func (s *defaultSubmitter) ExecuteExplain(cl *ir.ExplainStmt) error {
 	// NOTE(typhoonzero): model is already loaded under s.Cwd
-	var code string
-	var err error
-	db, err := database.OpenAndConnectDB(s.Session.DbConnStr)
+	code, err := explainProgram(cl, s.Session)
 	if err != nil {
 		return err
 	}
-	if isXGBoostModel(cl.TrainStmt.Estimator) {
-		code, err = xgboost.Explain(cl, s.Session)
-		// TODO(typhoonzero): deal with XGBoost model explain result table creation.
-	} else {
-		code, err = tensorflow.Explain(cl, s.Session)
-		if cl.Into != "" {
-			err := createExplainResultTable(db, cl, cl.Into, pai.ModelTypeTF, cl.TrainStmt.Estimator)
-			if err != nil {
-				return err
-			}
-		}
-	}
-
+	db, err := database.OpenAndConnectDBWithSession(s.Session)
 	if err != nil {
 		return err
 	}
+	defer db.Close()
+	if cl.Into != "" {
+		if explainer.Supports(cl.TrainStmt.Estimator) {
+			err = createAttributionTable(db, cl.Into)
+		} else if !isXGBoostModel(cl.TrainStmt.Estimator) {
+			// TODO(typhoonzero): deal with XGBoost model explain result table creation.
+			err = createExplainResultTable(db, cl, cl.Into, pai.ModelTypeTF, cl.TrainStmt.Estimator)
+		}
+		if err != nil {
+			return err
+		}
+	}
 	if err = s.runCommand(code); err != nil {
 		return err
 	}
@@ -227,39 +367,41 @@ func (s *defaultSubmitter) ExecuteExplain(cl *ir.ExplainStmt) error {
 		return err
 	}
 	s.Writer.Write(Figures{img, string(termFigure)})
-	return nil
+	if err = s.saveExplainFigures(cl.ModelName, img); err != nil {
+		return err
+	}
+	if err = writeAttributions(s.Writer, path.Join(s.Cwd, "attributions.json")); err != nil {
+		return err
+	}
+	return s.recordLineage("explain", cl.ModelName, cl.OriginalSQL, cl.Select, "", cl.Into)
 }
 
 func (s *defaultSubmitter) ExecuteEvaluate(cl *ir.EvaluateStmt) error {
 	// NOTE(typhoonzero): model is already loaded under s.Cwd
-	var code string
-	var err error
-	if isXGBoostModel(cl.TrainStmt.Estimator) {
-		code, err = xgboost.Evaluate(cl, s.Session)
-		if err != nil {
-			return err
-		}
-	} else {
-		code, err = tensorflow.Evaluate(cl, s.Session)
-		if err != nil {
-			return err
-		}
+	code, err := evaluateProgram(cl, s.Session)
+	if err != nil {
+		return err
 	}
 
 	if cl.Into != "" {
 		// create evaluation result table
-		db, err := database.OpenAndConnectDB(s.Session.DbConnStr)
+		db, err := database.OpenAndConnectDBWithSession(s.Session)
 		if err != nil {
 			return err
 		}
-		// default always output evaluation loss
-		metricNames := []string{"loss"}
-		metricsAttr, ok := cl.Attributes["validation.metrics"]
-		if ok {
-			metricsList := strings.Split(metricsAttr.(string), ",")
-			metricNames = append(metricNames, metricsList...)
+		defer db.Close()
+		if evaluation.ResultSchema(cl.Attributes) == evaluation.LongSchema {
+			err = createEvaluationHistoryTable(db, cl.Into)
+		} else {
+			// default always output evaluation loss
+			metricNames := []string{"loss"}
+			metricsAttr, ok := cl.Attributes["validation.metrics"]
+			if ok {
+				metricsList := strings.Split(metricsAttr.(string), ",")
+				metricNames = append(metricNames, metricsList...)
+			}
+			err = createEvaluationResultTable(db, cl.Into, metricNames)
 		}
-		err = createEvaluationResultTable(db, cl.Into, metricNames)
 		if err != nil {
 			return err
 		}
@@ -267,7 +409,7 @@ func (s *defaultSubmitter) ExecuteEvaluate(cl *ir.EvaluateStmt) error {
 	if err = s.runCommand(code); err != nil {
 		return err
 	}
-	return nil
+	return s.recordLineage("evaluate", cl.ModelName, cl.OriginalSQL, cl.Select, "", cl.Into)
 }
 
 func createEvaluationResultTable(db *database.DB, tableName string, metricNames []string) error {
@@ -278,14 +420,14 @@ func createEvaluationResultTable(db *database.DB, tableName string, metricNames
 	}
 	columnDef := ""
 	columnDefList := []string{}
-	if db.DriverName == "mysql" {
+	if db.DriverName == "mysql" || db.DriverName == "postgres" || db.DriverName == "sqlite3" {
 		for _, mn := range metricNames {
 			columnDefList = append(columnDefList,
 				fmt.Sprintf("%s VARCHAR(255)", mn))
 		}
 
 	} else {
-		// Hive, MaxCompute
+		// Hive, MaxCompute, ClickHouse
 		for _, mn := range metricNames {
 			columnDefList = append(columnDefList,
 				fmt.Sprintf("%s STRING", mn))
@@ -293,12 +435,34 @@ func createEvaluationResultTable(db *database.DB, tableName string, metricNames
 	}
 	columnDef = strings.Join(columnDefList, ",")
 	createStmt := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (%s);`, tableName, columnDef)
+	if db.DriverName == "clickhouse" {
+		createStmt = fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (%s) ENGINE = Log;`, tableName, columnDef)
+	}
 	if _, e = db.Exec(createStmt); e != nil {
 		return fmt.Errorf("failed executing %s: %q", createStmt, e)
 	}
 	return nil
 }
 
+// createEvaluationHistoryTable creates the result table of EVALUATE in
+// evaluation.LongSchema if it doesn't exist. Unlike
+// createEvaluationResultTable, it keeps the existing rows, so the table
+// records the evaluations of the models over time.
+func createEvaluationHistoryTable(db *database.DB, tableName string) error {
+	columnDef := "(model VARCHAR(255), model_version VARCHAR(255), evaluated_at VARCHAR(255), metric VARCHAR(255), value DOUBLE PRECISION)"
+	if db.DriverName == "hive" || db.DriverName == "maxcompute" || db.DriverName == "clickhouse" {
+		columnDef = "(model STRING, model_version STRING, evaluated_at STRING, metric STRING, value DOUBLE)"
+	}
+	createStmt := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s %s;`, tableName, columnDef)
+	if db.DriverName == "clickhouse" {
+		createStmt = fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s %s ENGINE = Log;`, tableName, columnDef)
+	}
+	if _, e := db.Exec(createStmt); e != nil {
+		return fmt.Errorf("failed executing %s: %q", createStmt, e)
+	}
+	return nil
+}
+
 func readExplainResult(target string) (string, error) {
 	r, err := os.Open(target)
 	if err != nil {
@@ -313,18 +477,179 @@ func readExplainResult(target string) (string, error) {
 	return fmt.Sprintf("<div align='center'><img src='data:image/png;base64,%s' /></div>", img), nil
 }
 
+// saveExplainFigures saves the figures of explaining the model, the PNG
+// and the HTML embedding it, into a new directory of the explain artifact
+// store of the session, if any, and writes their URIs to the client.
+func (s *defaultSubmitter) saveExplainFigures(modelName, img string) error {
+	store := s.Session.ExplainArtifactStore
+	if store == "" {
+		return nil
+	}
+	html := fmt.Sprintf("<html><body>%s</body></html>\n", img)
+	if err := ioutil.WriteFile(path.Join(s.Cwd, "summary.html"), []byte(html), 0644); err != nil {
+		return err
+	}
+	dir := fmt.Sprintf("%s/%s_%s", strings.TrimSuffix(store, "/"), path.Base(modelName), time.Now().UTC().Format("20060102T150405Z"))
+	uris := []string{}
+	for _, f := range []string{"summary.png", "summary.html"} {
+		uri := dir + "/" + f
+		if err := model.PutFile(uri, path.Join(s.Cwd, f), s.Session); err != nil {
+			return fmt.Errorf("save the figure to %s failed: %v", uri, err)
+		}
+		uris = append(uris, uri)
+	}
+	return s.Writer.Write(fmt.Sprintf("Saved the figures of explaining %s to:\n%s", modelName, strings.Join(uris, "\n")))
+}
+
+// writeAttributions writes the attribution of each feature saved in file
+// by the explain program to wr as a table, from the most important
+// feature. The custom explain programs may not save the file.
+func writeAttributions(wr *pipe.Writer, file string) error {
+	b, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var attributions []struct {
+		Feature     string  `json:"feature"`
+		Attribution float64 `json:"attribution"`
+	}
+	if err := json.Unmarshal(b, &attributions); err != nil {
+		return fmt.Errorf("read the attributions failed: %v", err)
+	}
+	sort.SliceStable(attributions, func(i, j int) bool {
+		return attributions[i].Attribution > attributions[j].Attribution
+	})
+	if err := wr.Write(map[string]interface{}{"columnNames": []string{"feature", "attribution"}}); err != nil {
+		return err
+	}
+	for _, a := range attributions {
+		if err := wr.Write([]interface{}{a.Feature, a.Attribution}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createAttributionTable creates the table to record the attribution of
+// each feature explained by pkg/sql/codegen/explainer.
+func createAttributionTable(db *database.DB, tableName string) error {
+	dropStmt := fmt.Sprintf(`DROP TABLE IF EXISTS %s;`, tableName)
+	if _, err := db.Exec(dropStmt); err != nil {
+		return fmt.Errorf("failed executing %s: %q", dropStmt, err)
+	}
+	columnDef := "(feature VARCHAR(255), attribution FLOAT)"
+	if db.DriverName == "hive" || db.DriverName == "maxcompute" {
+		columnDef = "(feature STRING, attribution DOUBLE)"
+	}
+	createStmt := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s %s;`, tableName, columnDef)
+	if _, err := db.Exec(createStmt); err != nil {
+		return fmt.Errorf("failed executing %s: %q", createStmt, err)
+	}
+	return nil
+}
+
 func (s *defaultSubmitter) GetTrainStmtFromModel() bool { return true }
 
 func (s *defaultSubmitter) ExecuteShowTrain(showTrain *ir.ShowTrainStmt) error {
-	model, err := model.Load(showTrain.ModelName, "", s.Db)
+	// LoadMeta reads only the model meta but not the model.
+	m, err := model.LoadMeta(s.modelURIOf(showTrain.ModelName), s.Session)
 	if err != nil {
 		s.Writer.Write("Load model meta " + showTrain.ModelName + " failed.")
 		return err
 	}
+	estimator, attributes := "", ""
+	if m.Metadata != nil {
+		estimator = m.Metadata.Estimator
+		// The attributes are of the best trial if the model is tuned.
+		attrs := m.Metadata.Attributes
+		if attrs == nil {
+			attrs = map[string]interface{}{}
+		}
+		b, err := json.Marshal(attrs)
+		if err != nil {
+			return err
+		}
+		attributes = string(b)
+	}
 	header := make(map[string]interface{})
-	header["columnNames"] = []string{"Table", "Train Statement"}
+	header["columnNames"] = []string{"Table", "Train Statement", "Estimator", "Attributes"}
 	s.Writer.Write(header)
-	s.Writer.Write([]interface{}{showTrain.ModelName, strings.TrimSpace(model.TrainSelect)})
+	s.Writer.Write([]interface{}{showTrain.ModelName, strings.TrimSpace(m.TrainSelect), estimator, attributes})
+
+	return nil
+}
 
+func (s *defaultSubmitter) ExecuteDropModel(dropModel *ir.DropModelStmt) error {
+	// The models of the groups trained by train.group_by go with their
+	// index.
+	if m, e := model.LoadMeta(s.modelURIOf(dropModel.ModelName), s.Session); e == nil && m.Groups != nil {
+		for _, v := range m.Groups.Values {
+			e := model.Delete(s.modelURIOf(grouping.ModelName(dropModel.ModelName, m.Groups.Column, v)), s.Session)
+			if e != nil && e != model.ErrModelNotFound {
+				return e
+			}
+		}
+	}
+	e := model.Delete(s.modelURIOf(dropModel.ModelName), s.Session)
+	if e == model.ErrModelNotFound && s.ModelDir == "" && s.Db.DriverName == "mysql" {
+		// The model may be saved by versions.
+		e = model.DeleteVersions(dropModel.ModelName, s.Db)
+	}
+	if e == model.ErrModelNotFound {
+		return fmt.Errorf("model %s doesn't exist", dropModel.ModelName)
+	}
+	if e != nil {
+		return e
+	}
+	return s.Writer.Write(fmt.Sprintf("model %s dropped", dropModel.ModelName))
+}
+
+func (s *defaultSubmitter) ExecuteShowModels(showModels *ir.ShowModelsStmt) error {
+	models, e := model.List(s.Db)
+	if e != nil {
+		return e
+	}
+	sort.Slice(models, func(i, j int) bool {
+		if models[i].Model != models[j].Model {
+			return models[i].Model < models[j].Model
+		}
+		return models[i].Version < models[j].Version
+	})
+	header := make(map[string]interface{})
+	header["columnNames"] = []string{"Model", "Version", "Estimator", "Created At", "Size"}
+	if e := s.Writer.Write(header); e != nil {
+		return e
+	}
+	for _, m := range models {
+		createdAt := ""
+		if m.Version > 0 {
+			createdAt = m.CreatedAt.Format("2006-01-02 15:04:05")
+		}
+		if e := s.Writer.Write([]interface{}{m.Model, m.Version, m.Estimator, createdAt, m.Size}); e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+func (s *defaultSubmitter) ExecuteShowLineage(showLineage *ir.ShowLineageStmt) error {
+	lineages, e := model.LineageOf(showLineage.ModelName, s.Db)
+	if e != nil {
+		return e
+	}
+	header := make(map[string]interface{})
+	header["columnNames"] = []string{"Action", "Source Tables", "Parent Model", "Output Table", "Statement Hash", "Created At"}
+	if e := s.Writer.Write(header); e != nil {
+		return e
+	}
+	for _, l := range lineages {
+		row := []interface{}{l.Action, strings.Join(l.SourceTables, ","), l.ParentModel, l.OutputTable, l.StatementHash, l.CreatedAt.Format("2006-01-02 15:04:05")}
+		if e := s.Writer.Write(row); e != nil {
+			return e
+		}
+	}
 	return nil
 }
@@ -14,9 +14,15 @@
 package sql
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"sqlflow.org/sqlflow/pkg/pipe"
+	pb "sqlflow.org/sqlflow/pkg/proto"
 )
 
 func TestGetSubmitter(t *testing.T) {
@@ -29,3 +35,42 @@ func TestGetSubmitter(t *testing.T) {
 	_, ok := s3.(*paiSubmitter)
 	a.True(ok)
 }
+
+func TestExplainArtifacts(t *testing.T) {
+	a := assert.New(t)
+	cwd, e := ioutil.TempDir("", "sqlflow_explain")
+	a.NoError(e)
+	defer os.RemoveAll(cwd)
+	a.NoError(ioutil.WriteFile(filepath.Join(cwd, "summary.png"), []byte("png"), 0644))
+	a.NoError(ioutil.WriteFile(filepath.Join(cwd, "attributions.json"), []byte(`[{"feature": "sepal_length", "attribution": 0.1}, {"feature": "petal_length", "attribution": 0.5}]`), 0644))
+
+	rd, wr := pipe.Pipe()
+	s := &defaultSubmitter{Writer: wr, Cwd: cwd, Session: &pb.Session{ExplainArtifactStore: "file://" + filepath.Join(cwd, "store") + "/"}}
+	go func() {
+		defer wr.Close()
+		if e := s.saveExplainFigures("sqlflow_models.my_model", "<img />"); e != nil {
+			wr.Write(e)
+		}
+		if e := writeAttributions(wr, filepath.Join(cwd, "attributions.json")); e != nil {
+			wr.Write(e)
+		}
+		if e := writeAttributions(wr, filepath.Join(cwd, "no_such_file")); e != nil {
+			wr.Write(e)
+		}
+	}()
+	c := rd.ReadAll()
+	lines := strings.Split((<-c).(string), "\n")
+	a.Equal(3, len(lines))
+	a.Equal("Saved the figures of explaining sqlflow_models.my_model to:", lines[0])
+	for i, f := range []string{"summary.png", "summary.html"} {
+		a.True(strings.HasPrefix(lines[i+1], "file://"+filepath.Join(cwd, "store", "sqlflow_models.my_model_")), lines[i+1])
+		a.True(strings.HasSuffix(lines[i+1], "/"+f), lines[i+1])
+		_, e := os.Stat(strings.TrimPrefix(lines[i+1], "file://"))
+		a.NoError(e)
+	}
+	a.Equal(map[string]interface{}{"columnNames": []string{"feature", "attribution"}}, <-c)
+	a.Equal([]interface{}{"petal_length", 0.5}, <-c)
+	a.Equal([]interface{}{"sepal_length", 0.1}, <-c)
+	_, more := <-c
+	a.False(more)
+}
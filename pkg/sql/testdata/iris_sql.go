@@ -13,6 +13,8 @@
 
 package testdata
 
+import "strings"
+
 // IrisSQL is .sql format data sample of iris dataset.
 var IrisSQL = `CREATE DATABASE IF NOT EXISTS iris;
 DROP TABLE IF EXISTS iris.train;
@@ -175,6 +177,30 @@ CREATE TABLE iris.iris_empty (
        petal_width  float,
        class int);`
 
+// IrisSQLiteSQL is IrisSQL in SQLite, which has neither CONCAT_WS nor
+// CREATE TABLE ... AS (SELECT ...).
+var IrisSQLiteSQL = strings.Split(IrisSQL, "DROP TABLE IF EXISTS iris.train_dense;")[0] + `DROP TABLE IF EXISTS iris.train_dense;
+CREATE TABLE iris.train_dense AS
+  SELECT sepal_length || "," || sepal_width || "," || petal_length || "," || petal_width
+         AS dense,
+         class
+  FROM   iris.train;
+
+DROP TABLE IF EXISTS iris.test_dense;
+CREATE TABLE iris.test_dense AS
+  SELECT sepal_length || "," || sepal_width || "," || petal_length || "," || petal_width
+         AS dense,
+         class
+  FROM   iris.test;
+
+DROP TABLE IF EXISTS iris.iris_empty;
+CREATE TABLE iris.iris_empty (
+       sepal_length float,
+       sepal_width  float,
+       petal_length float,
+       petal_width  float,
+       class int);`
+
 // IrisHiveSQL is .sql format data sample of iris dataset in Hive SQL.
 var IrisHiveSQL = `CREATE DATABASE IF NOT EXISTS iris;
 DROP TABLE IF EXISTS iris.train;
@@ -0,0 +1,83 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sqlflow.org/sqlflow/pkg/ir"
+	"sqlflow.org/sqlflow/pkg/model"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/tensorflow"
+	"sqlflow.org/sqlflow/pkg/tracking"
+)
+
+// trackTrain runs the training of cl, and logs the run to the experiment
+// tracker of the session. The training doesn't fail if the tracker
+// does, but the client is warned.
+func (s *defaultSubmitter) trackTrain(cl *ir.TrainStmt) error {
+	tr, e := tracking.Open(s.Session.ExperimentTracker)
+	if e != nil {
+		return e
+	}
+	run := trainRun(cl, s.modelURI(cl), s.Session.UserId)
+	s.onProgress = func(p Progress) {
+		// Only the end of each epoch is logged.
+		if p.Step > 0 {
+			return
+		}
+		now := time.Now()
+		for k, v := range p.Metrics {
+			run.Metrics = append(run.Metrics, tracking.Metric{Key: k, Value: v, Step: int64(p.Epoch), Timestamp: now})
+		}
+	}
+	e = s.train(cl)
+	s.onProgress = nil
+	// Only the chief of the distributed training logs the run.
+	if !tensorflow.IsChief() {
+		return e
+	}
+	run.EndTime, run.Status = time.Now(), tracking.StatusFinished
+	if e != nil {
+		run.Status, run.Error = tracking.StatusFailed, e.Error()
+	}
+	if err := tr.LogRun(context.Background(), run); err != nil {
+		s.Writer.Write(fmt.Sprintf("Failed to log the training to the experiment tracker: %v", err))
+	}
+	return e
+}
+
+// trainRun returns the run of training cl to save the model at modelURI
+// by user, with the estimator and the attributes as the params.
+func trainRun(cl *ir.TrainStmt, modelURI, user string) *tracking.Run {
+	params := map[string]string{"estimator": cl.Estimator}
+	for k, v := range cl.Attributes {
+		params[k] = fmt.Sprint(v)
+	}
+	tags := map[string]string{
+		"sqlflow.model_uri":      modelURI,
+		"sqlflow.select":         cl.Select,
+		"sqlflow.statement_hash": model.StatementHash(cl.OriginalSQL),
+	}
+	if user != "" {
+		tags["mlflow.user"] = user
+	}
+	return &tracking.Run{
+		Name:      cl.Into,
+		Params:    params,
+		Tags:      tags,
+		StartTime: time.Now(),
+	}
+}
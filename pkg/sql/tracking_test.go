@@ -0,0 +1,43 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sqlflow.org/sqlflow/pkg/ir"
+)
+
+func TestTrainRun(t *testing.T) {
+	a := assert.New(t)
+	cl := &ir.TrainStmt{
+		OriginalSQL: "SELECT * FROM iris.train TO TRAIN DNNClassifier WITH model.n_classes=3 LABEL class INTO sqlflow_models.my_model;",
+		Select:      "SELECT * FROM iris.train",
+		Estimator:   "DNNClassifier",
+		Attributes:  map[string]interface{}{"model.n_classes": 3, "model.hidden_units": []int{10, 20}},
+		Into:        "sqlflow_models.my_model",
+	}
+	run := trainRun(cl, "sqlflow_models.my_model", "alice")
+	a.Equal("sqlflow_models.my_model", run.Name)
+	a.Equal(map[string]string{"estimator": "DNNClassifier", "model.n_classes": "3", "model.hidden_units": "[10 20]"}, run.Params)
+	a.Equal("sqlflow_models.my_model", run.Tags["sqlflow.model_uri"])
+	a.Equal("SELECT * FROM iris.train", run.Tags["sqlflow.select"])
+	a.Len(run.Tags["sqlflow.statement_hash"], 64)
+	a.Equal("alice", run.Tags["mlflow.user"])
+	a.False(run.StartTime.IsZero())
+
+	run = trainRun(cl, "file:///models/sqlflow_models.my_model", "")
+	a.NotContains(run.Tags, "mlflow.user")
+}
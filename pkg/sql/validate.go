@@ -0,0 +1,128 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"sqlflow.org/sqlflow/pkg/database"
+	"sqlflow.org/sqlflow/pkg/ir"
+	"sqlflow.org/sqlflow/pkg/step/feature"
+	"sqlflow.org/sqlflow/pkg/verifier"
+)
+
+// validationErrors are the problems of a statement found by
+// validateTrainStmt, reported in one error.
+type validationErrors []error
+
+func (v validationErrors) Error() string {
+	msgs := []string{}
+	for _, e := range v {
+		msgs = append(msgs, strings.Split(e.Error(), "\n")...)
+	}
+	if len(msgs) == 1 {
+		return msgs[0]
+	}
+	return fmt.Sprintf("%d problems found in the statement:\n  %s", len(msgs), strings.Join(msgs, "\n  "))
+}
+
+// validateTrainStmt initializes the attributes of trainStmt and checks
+// it against the fields of its SELECT before the feature derivation, so
+// that the fields of the COLUMN clause and the label that don't exist,
+// the label of a type the estimator can't learn, and the invalid
+// attributes of the WITH clause are all reported at once, instead of one
+// by one after the training program starts.
+func validateTrainStmt(trainStmt *ir.TrainStmt, db *database.DB, verifyLabel bool) error {
+	var errs validationErrors
+	fields, err := verifier.Verify(trainStmt.Select, db)
+	if err != nil {
+		errs = append(errs, err)
+	} else {
+		errs = append(errs, validateColumns(trainStmt, fields)...)
+		if verifyLabel {
+			if err := validateLabel(trainStmt, fields); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if err := doAttrInitAndTypeChecking(trainStmt); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateColumns returns the errors of the fields referred by the
+// COLUMN clause of trainStmt that are not in fields.
+func validateColumns(trainStmt *ir.TrainStmt, fields verifier.FieldTypes) []error {
+	targets := []string{}
+	for target := range trainStmt.Features {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+	errs := []error{}
+	missing := map[string]bool{}
+	for _, target := range targets {
+		for _, fc := range trainStmt.Features[target] {
+			for _, fd := range fc.GetFieldDesc() {
+				_, fld := verifier.Decomp(fd.Name)
+				if _, ok := fields.Get(fld); !ok && !missing[fld] {
+					missing[fld] = true
+					errs = append(errs, fmt.Errorf("the COLUMN clause refers to the field %s, which is not in the result of the SELECT", fd.Name))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// validateLabel checks that the label of trainStmt is in fields, and of a
+// type that the estimator can learn.
+func validateLabel(trainStmt *ir.TrainStmt, fields verifier.FieldTypes) error {
+	label := trainStmt.Label.GetFieldDesc()[0].Name
+	if label == "" {
+		// empty label means clustering model.
+		return nil
+	}
+	_, fld := verifier.Decomp(label)
+	typ, ok := fields.Get(fld)
+	if !ok {
+		return fmt.Errorf("the label %s is not in the result of the SELECT", label)
+	}
+	switch feature.UnifiedTypeName(typ) {
+	case "FLOAT", "DOUBLE":
+		if isTensorFlowClassifier(trainStmt) {
+			return fmt.Errorf("the label %s is of type %s, but the classifier %s requires integer labels", label, typ, trainStmt.Estimator)
+		}
+	case "DATE", "DATETIME", "TIMESTAMP", "JSON":
+		return fmt.Errorf("the label %s is of type %s, which can't be the label of %s", label, typ, trainStmt.Estimator)
+	}
+	return nil
+}
+
+// isTensorFlowClassifier returns true if the estimator of trainStmt is a
+// TensorFlow classifier, which requires the integer labels, unlike XGBoost and the
+// scikit-learn API that accept the floats of the classes.
+func isTensorFlowClassifier(trainStmt *ir.TrainStmt) bool {
+	for _, other := range []func(string) bool{isXGBoostModel, isPyTorchModel, isLightGBMModel, isCatBoostModel, isSKLearnModel, isKMeansModel} {
+		if other(trainStmt.Estimator) {
+			return false
+		}
+	}
+	return strings.HasSuffix(strings.ToUpper(trainStmt.Estimator), "CLASSIFIER")
+}
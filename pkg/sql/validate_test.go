@@ -0,0 +1,55 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sqlflow.org/sqlflow/pkg/ir"
+	"sqlflow.org/sqlflow/pkg/verifier"
+)
+
+func TestValidateTrainStmt(t *testing.T) {
+	a := assert.New(t)
+	fields := verifier.FieldTypes{"sepal_length": "FLOAT", "sepal_width": "FLOAT", "petal_length": "FLOAT", "petal_width": "FLOAT", "class": "BIGINT"}
+	trainStmt := ir.MockTrainStmt(false)
+	a.Empty(validateColumns(trainStmt, fields))
+	a.NoError(validateLabel(trainStmt, fields))
+
+	delete(fields, "sepal_width")
+	delete(fields, "petal_width")
+	a.Equal([]error{
+		fmt.Errorf("the COLUMN clause refers to the field sepal_width, which is not in the result of the SELECT"),
+		fmt.Errorf("the COLUMN clause refers to the field petal_width, which is not in the result of the SELECT"),
+	}, validateColumns(trainStmt, fields))
+
+	fields["class"] = "DOUBLE"
+	a.EqualError(validateLabel(trainStmt, fields), "the label class is of type DOUBLE, but the classifier DNNClassifier requires integer labels")
+	trainStmt.Estimator = "DNNRegressor"
+	a.NoError(validateLabel(trainStmt, fields))
+	fields["class"] = "DATETIME"
+	a.EqualError(validateLabel(trainStmt, fields), "the label class is of type DATETIME, which can't be the label of DNNRegressor")
+	delete(fields, "class")
+	a.EqualError(validateLabel(trainStmt, fields), "the label class is not in the result of the SELECT")
+
+	xgb := ir.MockTrainStmt(true)
+	a.False(isTensorFlowClassifier(xgb))
+	xgb.Estimator = "xgboost.XGBClassifier"
+	a.False(isTensorFlowClassifier(xgb))
+
+	a.EqualError(validationErrors{fmt.Errorf("a")}, "a")
+	a.EqualError(validationErrors{fmt.Errorf("a"), fmt.Errorf("b\nc")}, "3 problems found in the statement:\n  a\n  b\n  c")
+}
@@ -0,0 +1,183 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlfs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Codec compresses the content of sqlfs files.  A file written with a
+// codec starts with codecMagic and the name of the codec, so Open knows
+// how to decompress it.  The files written without a codec have no
+// such header, like those written by older versions of SQLFlow.
+type Codec interface {
+	// Name identifies the codec in the header of a file.  It must be
+	// shorter than 256 bytes.
+	Name() string
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+// codecMagic precedes the length and the name of the codec of a file.
+var codecMagic = []byte("\x00SQLFS\x00")
+
+var (
+	muCodecs sync.RWMutex
+	codecs   = map[string]Codec{}
+)
+
+func init() {
+	RegisterCodec(gzipCodec{})
+	RegisterCodec(zstdCodec{})
+}
+
+// RegisterCodec makes a codec available by its name to CreateWithCodec
+// and Open.  It panics if a codec of the same name has been registered.
+func RegisterCodec(c Codec) {
+	muCodecs.Lock()
+	defer muCodecs.Unlock()
+	if len(c.Name()) == 0 || len(c.Name()) > 255 {
+		panic(fmt.Sprintf("sqlfs: invalid codec name %q", c.Name()))
+	}
+	if _, ok := codecs[c.Name()]; ok {
+		panic(fmt.Sprintf("sqlfs: codec %s registered twice", c.Name()))
+	}
+	codecs[c.Name()] = c
+}
+
+func codecByName(name string) (Codec, error) {
+	muCodecs.RLock()
+	defer muCodecs.RUnlock()
+	c, ok := codecs[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown sqlfs codec %s", name)
+	}
+	return c, nil
+}
+
+// newCodecWriter writes the header of c to w, and returns a writer that
+// compresses into w.  Closing the returned writer closes w.
+func newCodecWriter(w io.WriteCloser, c Codec) (io.WriteCloser, error) {
+	header := append(append([]byte{}, codecMagic...), byte(len(c.Name())))
+	if _, e := w.Write(append(header, c.Name()...)); e != nil {
+		return nil, e
+	}
+	cw, e := c.NewWriter(w)
+	if e != nil {
+		return nil, e
+	}
+	return &codecWriter{cw, w}, nil
+}
+
+type codecWriter struct {
+	io.WriteCloser                // the compressor
+	w              io.WriteCloser // the sqlfs writer
+}
+
+func (w *codecWriter) Close() error {
+	if e := w.WriteCloser.Close(); e != nil {
+		w.w.Close()
+		return e
+	}
+	return w.w.Close()
+}
+
+// newCodecReader returns a reader that decompresses r by the codec in
+// the header of r, or reads r as is if r has no header.
+func newCodecReader(r io.Reader) (io.ReadCloser, error) {
+	magic := make([]byte, len(codecMagic))
+	n, e := io.ReadFull(r, magic)
+	if e == io.EOF || e == io.ErrUnexpectedEOF || (e == nil && !bytes.Equal(magic, codecMagic)) {
+		return ioutil.NopCloser(&prefixReader{magic[:n], r}), nil
+	}
+	if e != nil {
+		return nil, e
+	}
+	length := make([]byte, 1)
+	if _, e := io.ReadFull(r, length); e != nil {
+		return nil, fmt.Errorf("read sqlfs codec header: %v", e)
+	}
+	name := make([]byte, length[0])
+	if _, e := io.ReadFull(r, name); e != nil {
+		return nil, fmt.Errorf("read sqlfs codec header: %v", e)
+	}
+	c, e := codecByName(string(name))
+	if e != nil {
+		return nil, e
+	}
+	return c.NewReader(r)
+}
+
+// prefixReader reads prefix and then r.  Unlike io.MultiReader, a Read
+// goes on to r after the prefix, so it fills p like reading r alone.
+type prefixReader struct {
+	prefix []byte
+	r      io.Reader
+}
+
+func (p *prefixReader) Read(b []byte) (int, error) {
+	n := copy(b, p.prefix)
+	p.prefix = p.prefix[n:]
+	if n == len(b) {
+		return n, nil
+	}
+	m, e := p.r.Read(b[n:])
+	return n + m, e
+}
+
+type gzipCodec struct{}
+
+func (gzipCodec) Name() string { return "gzip" }
+
+func (gzipCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriter(w), nil
+}
+
+func (gzipCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+type zstdCodec struct{}
+
+func (zstdCodec) Name() string { return "zstd" }
+
+func (zstdCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return zstd.NewWriter(w)
+}
+
+func (zstdCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	d, e := zstd.NewReader(r)
+	if e != nil {
+		return nil, e
+	}
+	return zstdReader{d}, nil
+}
+
+// zstdReader adapts zstd.Decoder, whose Close returns nothing, to
+// io.ReadCloser.
+type zstdReader struct {
+	*zstd.Decoder
+}
+
+func (r zstdReader) Close() error {
+	r.Decoder.Close()
+	return nil
+}
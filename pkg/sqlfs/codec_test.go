@@ -0,0 +1,76 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlfs
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type bufferCloser struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *bufferCloser) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestSQLFSCodecs(t *testing.T) {
+	a := assert.New(t)
+	content := bytes.Repeat([]byte("sqlflow "), 1024)
+	for _, name := range []string{"gzip", "zstd"} {
+		c, e := codecByName(name)
+		a.NoError(e)
+		buf := &bufferCloser{}
+		w, e := newCodecWriter(buf, c)
+		a.NoError(e)
+		_, e = w.Write(content)
+		a.NoError(e)
+		a.NoError(w.Close())
+		a.True(buf.closed)
+		a.True(bytes.HasPrefix(buf.Bytes(), append(codecMagic, byte(len(name)))))
+		a.True(buf.Len() < len(content))
+
+		r, e := newCodecReader(bytes.NewReader(buf.Bytes()))
+		a.NoError(e)
+		b, e := ioutil.ReadAll(r)
+		a.NoError(e)
+		a.True(bytes.Equal(content, b))
+		a.NoError(r.Close())
+	}
+
+	_, e := codecByName("lz4")
+	a.Error(e)
+}
+
+func TestSQLFSCodecReaderWithoutHeader(t *testing.T) {
+	a := assert.New(t)
+	for _, content := range []string{"", "\n\n\n", "\x00SQLFS", "a file without the codec header"} {
+		r, e := newCodecReader(bytes.NewReader([]byte(content)))
+		a.NoError(e)
+		b, e := ioutil.ReadAll(r)
+		a.NoError(e)
+		a.Equal(content, string(b))
+	}
+
+	// An unknown codec in the header.
+	header := append(append([]byte{}, codecMagic...), 3)
+	_, e := newCodecReader(bytes.NewReader(append(header, "lz4"...)))
+	a.Error(e)
+}
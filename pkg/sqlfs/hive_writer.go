@@ -25,21 +25,19 @@ import (
 	"strings"
 )
 
-func flushToCSV() (func([]byte) error, *os.File, error) {
+// insertToCSV returns a function that writes the chunk buf as the
+// row id of a CSV file, which is loaded into a Hive table on close.
+func insertToCSV() (func(id int, buf []byte) error, *os.File, error) {
 	csv, e := ioutil.TempFile("", "sqlflow-sqlfs")
 	if e != nil {
 		return nil, nil, fmt.Errorf("cannot create CSV file: %v", e)
 	}
 
-	row := 0
-	return func(buf []byte) error {
-		if len(buf) > 0 {
-			block := base64.StdEncoding.EncodeToString(buf)
-			_, e := csv.Write([]byte(fmt.Sprintf("%d\001%s\n", row, block)))
-			if e != nil {
-				return fmt.Errorf("cannot flush to CSV, %v", e)
-			}
-			row++
+	return func(id int, buf []byte) error {
+		block := base64.StdEncoding.EncodeToString(buf)
+		_, e := csv.Write([]byte(fmt.Sprintf("%d\001%s\n", id, block)))
+		if e != nil {
+			return fmt.Errorf("cannot flush to CSV, %v", e)
 		}
 		return nil
 	}, csv, nil
@@ -104,10 +102,24 @@ func newHiveWriter(db *sql.DB, hivePath, table, user, passwd, namenodeAddr strin
 		return nil, fmt.Errorf("cannot create table %s: %v", table, e)
 	}
 
-	flush, csv, e := flushToCSV()
+	insert, csv, e := insertToCSV()
 	if e != nil {
 		return nil, e
 	}
+	// The CSV file is written by one goroutine, and loaded at once, so
+	// the markers are loaded with all chunks or nothing.
+	u := newChunkUploader(db, insert, 1)
+	if e := u.begin(); e != nil {
+		return nil, e
+	}
 	upload := uploadCSVFile(csv, db, hivePath, table, user, passwd, namenodeAddr)
-	return newFlushWriteCloser(flush, upload, bufSize), nil
+	wrapup := func() error {
+		if e := u.wrapup(); e != nil {
+			csv.Close()
+			os.Remove(csv.Name())
+			return e
+		}
+		return upload()
+	}
+	return newFlushWriteCloser(u.flush, wrapup, bufSize), nil
 }
@@ -26,14 +26,21 @@ type fragment struct {
 	block string
 }
 
-// Reader implements io.ReadCloser
+// readBatchSize is the number of fragments Reader queries at a time, so
+// it holds at most readBatchSize chunks of a file in memory.
+const readBatchSize = 64
+
+// Reader implements io.ReadCloser. It queries the fragments of a sqlfs
+// file in batches when they are read, so reading a file of any size
+// takes constant memory.
 type Reader struct {
 	db    *sql.DB
 	table string
 	buf   []byte
-	rows  *sql.Rows
-	frams []fragment
-	cur   int
+	ids   []int      // ids are the sorted ids of all fragments.
+	frams []fragment // frams are the queried fragments not yet read.
+	cur   int        // cur is the index in ids of the next fragment to query.
+	dec   io.ReadCloser
 }
 
 // Open returns a reader to read from the given table in db.
@@ -46,45 +53,106 @@ func Open(db *sql.DB, table string) (*Reader, error) {
 		return nil, fmt.Errorf("open: hasTable failed with %v", e)
 	}
 
-	r := &Reader{db, table, nil, nil, nil, 0}
-	stmt := fmt.Sprintf("SELECT id,block FROM %s;", table)
-	r.rows, e = r.db.Query(stmt)
+	state, e := openFileState(db, table)
+	if e != nil {
+		return nil, e
+	}
+	r := &Reader{db: db, table: table}
+	stmt := fmt.Sprintf("SELECT id FROM %s WHERE id >= 0;", table)
+	rows, e := r.db.Query(stmt)
 	if e != nil {
 		return nil, fmt.Errorf("open: db query [%s] failed: %v", stmt, e)
 	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		if e = rows.Scan(&id); e != nil {
+			return nil, e
+		}
+		// Skip the orphaned rows after the last chunk.
+		if !state.complete || id < state.chunks {
+			r.ids = append(r.ids, id)
+		}
+	}
+	if e := rows.Err(); e != nil {
+		return nil, fmt.Errorf("open: db query [%s] failed: %v", stmt, e)
+	}
 	// Since statement like `SELECT id,block FROM tbl ORDER BY id` causes an
 	// error in hive randomly. We decide to sort results here instead of
 	// by SQL engine.
-	// Probably you would worry about the dataset is too huge to be held in
-	// memory. It's fine due to we are going to save models to file system
-	// for a long term plan.
-	for r.rows.Next() {
+	sort.Ints(r.ids)
+	return r, nil
+}
+
+// fetch queries the next batch of fragments into r.frams. The ids are
+// sorted, so the fragments in the batch are the ones with ids in the
+// range of the batch.
+func (r *Reader) fetch() error {
+	n := len(r.ids) - r.cur
+	if n > readBatchSize {
+		n = readBatchSize
+	}
+	lo, hi := r.ids[r.cur], r.ids[r.cur+n-1]
+	stmt := fmt.Sprintf("SELECT id,block FROM %s WHERE id >= %d AND id <= %d;", r.table, lo, hi)
+	rows, e := r.db.Query(stmt)
+	if e != nil {
+		return fmt.Errorf("read: db query [%s] failed: %v", stmt, e)
+	}
+	defer rows.Close()
+	frams := make([]fragment, 0, n)
+	for rows.Next() {
 		var f fragment
-		if e = r.rows.Scan(&f.id, &f.block); e != nil {
-			r.Close()
-			return nil, e
+		if e := rows.Scan(&f.id, &f.block); e != nil {
+			return e
 		}
-		r.frams = append(r.frams, f)
+		frams = append(frams, f)
+	}
+	if e := rows.Err(); e != nil {
+		return fmt.Errorf("read: db query [%s] failed: %v", stmt, e)
+	}
+	if len(frams) != n {
+		return fmt.Errorf("read: expect %d fragments of ids in [%d, %d] in %s, got %d", n, lo, hi, r.table, len(frams))
 	}
-	sort.Slice(r.frams, func(i, j int) bool {
-		return r.frams[i].id < r.frams[j].id
+	sort.Slice(frams, func(i, j int) bool {
+		return frams[i].id < frams[j].id
 	})
-	return r, nil
+	r.frams = frams
+	r.cur += n
+	return nil
 }
 
-func (r *Reader) Read(p []byte) (n int, e error) {
+// Read reads the decompressed content of the file, if it's written by
+// CreateWithCodec.
+func (r *Reader) Read(p []byte) (int, error) {
 	if r.db == nil {
 		return 0, fmt.Errorf("read from a closed reader")
 	}
+	if r.dec == nil {
+		dec, e := newCodecReader(readerFunc(r.readFragments))
+		if e != nil {
+			return 0, e
+		}
+		r.dec = dec
+	}
+	return r.dec.Read(p)
+}
+
+// readFragments reads the content of the fragments as is.
+func (r *Reader) readFragments(p []byte) (n int, e error) {
 	n = 0
 	for n < len(p) {
 		m := copy(p[n:], r.buf)
 		n += m
 		r.buf = r.buf[m:]
 		if len(r.buf) <= 0 {
-			if r.cur < len(r.frams) {
-				blk := r.frams[r.cur].block
-				r.cur++
+			if len(r.frams) == 0 && r.cur < len(r.ids) {
+				if e = r.fetch(); e != nil {
+					break
+				}
+			}
+			if len(r.frams) > 0 {
+				blk := r.frams[0].block
+				r.frams = r.frams[1:]
 				if r.buf, e = base64.StdEncoding.DecodeString(blk); e != nil {
 					break
 				}
@@ -99,12 +167,136 @@ func (r *Reader) Read(p []byte) (n int, e error) {
 
 // Close the reader connection to sqlfs
 func (r *Reader) Close() error {
-	if r.rows != nil {
-		if e := r.rows.Close(); e != nil {
-			return e
-		}
-		r.rows = nil
+	var e error
+	if r.dec != nil {
+		e = r.dec.Close()
+		r.dec = nil
 	}
+	r.frams = nil
 	r.db = nil // Mark closed.
-	return nil
+	return e
+}
+
+// LazyReader implements io.ReadCloser. Unlike Reader, it queries the
+// fragments of a sqlfs file one by one in the order of id when they are
+// read, so reading the beginning of a big file doesn't load the whole
+// table.
+type LazyReader struct {
+	db    *sql.DB
+	table string
+	buf   []byte
+	next  int
+	dec   io.ReadCloser
+}
+
+// OpenLazy returns a LazyReader to read from the given table.
+func OpenLazy(db *sql.DB, table string) (*LazyReader, error) {
+	has, e := hasTable(db, table)
+	if !has {
+		return nil, fmt.Errorf("open: table %s doesn't exist", table)
+	}
+	if e != nil {
+		return nil, fmt.Errorf("open: hasTable failed with %v", e)
+	}
+	if _, e := openFileState(db, table); e != nil {
+		return nil, e
+	}
+	return &LazyReader{db: db, table: table}, nil
+}
+
+// openFileState returns the state of the file to open, or an error if
+// the file is partial.
+func openFileState(db *sql.DB, table string) (*fileState, error) {
+	state, e := readFileState(db, table)
+	if e != nil {
+		return nil, fmt.Errorf("open: %v", e)
+	}
+	if state.partial() {
+		return nil, fmt.Errorf("open: sqlfs file %s is partial", table)
+	}
+	return state, nil
+}
+
+// Read reads up to len(p) bytes into p, querying the next fragment when
+// the fetched ones are consumed.  Like Reader, it decompresses the
+// content of the file written by CreateWithCodec.
+func (r *LazyReader) Read(p []byte) (int, error) {
+	if r.db == nil {
+		return 0, fmt.Errorf("read from a closed reader")
+	}
+	if r.dec == nil {
+		dec, e := newCodecReader(readerFunc(r.readFragments))
+		if e != nil {
+			return 0, e
+		}
+		r.dec = dec
+	}
+	return r.dec.Read(p)
+}
+
+func (r *LazyReader) readFragments(p []byte) (n int, e error) {
+	n = 0
+	for n < len(p) {
+		m := copy(p[n:], r.buf)
+		n += m
+		r.buf = r.buf[m:]
+		if len(r.buf) > 0 || n == len(p) {
+			break
+		}
+		var blk string
+		stmt := fmt.Sprintf("SELECT block FROM %s WHERE id=%d", r.table, r.next)
+		if e = r.db.QueryRow(stmt).Scan(&blk); e != nil {
+			if e == sql.ErrNoRows {
+				e = io.EOF
+			}
+			break
+		}
+		r.next++
+		if r.buf, e = base64.StdEncoding.DecodeString(blk); e != nil {
+			break
+		}
+	}
+	return n, e
+}
+
+// Close marks the reader closed.
+func (r *LazyReader) Close() error {
+	var e error
+	if r.dec != nil {
+		e = r.dec.Close()
+		r.dec = nil
+	}
+	r.db = nil
+	return e
+}
+
+// readerFunc adapts a function to io.Reader.
+type readerFunc func(p []byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) {
+	return f(p)
+}
+
+// Size returns the number of bytes stored in the blocks of the given
+// table, which is the base64-encoded size of the sqlfs file, excluding
+// the markers.
+func Size(db *sql.DB, table string) (int64, error) {
+	var size sql.NullInt64
+	stmt := fmt.Sprintf("SELECT SUM(LENGTH(block)) FROM %s WHERE id >= 0", table)
+	if e := db.QueryRow(stmt).Scan(&size); e != nil {
+		return 0, fmt.Errorf("size: db query [%s] failed: %v", stmt, e)
+	}
+	return size.Int64, nil
+}
+
+// EncodedSize returns the size that Size reports for a sqlfs file of n
+// bytes written by Create with chunkSize, which encodes every chunkSize
+// bytes into a block.  chunkSize zero or negative means the default of
+// Create.
+func EncodedSize(n int64, chunkSize int) int64 {
+	if chunkSize <= 0 {
+		chunkSize = bufSize
+	}
+	size := n / int64(chunkSize) * int64(base64.StdEncoding.EncodedLen(chunkSize))
+	return size + int64(base64.StdEncoding.EncodedLen(int(n%int64(chunkSize))))
 }
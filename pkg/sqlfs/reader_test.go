@@ -14,8 +14,10 @@
 package sqlfs
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"math/rand"
 	"strings"
 	"testing"
@@ -35,7 +37,7 @@ func TestSQLFSWriteAndRead(t *testing.T) {
 
 	tbl := fmt.Sprintf("%s.unittest%d", testDatabaseName, rand.Int())
 
-	w, e := Create(db.DB, db.DriverName, tbl, database.GetSessionFromTestingDB())
+	w, e := Create(db.DB, db.DriverName, tbl, database.GetSessionFromTestingDB(), 0)
 	a.NoError(e)
 	a.NotNil(w)
 
@@ -83,3 +85,106 @@ func TestSQLFSWriteAndRead(t *testing.T) {
 
 	a.NoError(dropTableIfExists(db.DB, tbl))
 }
+
+func TestSQLFSReadBatches(t *testing.T) {
+	a := assert.New(t)
+	createSQLFSTestingDatabaseOnce.Do(createSQLFSTestingDatabase)
+	db := database.GetTestingDBSingleton()
+
+	tbl := fmt.Sprintf("%s.unittest%d", testDatabaseName, rand.Int())
+	w, e := Create(db.DB, db.DriverName, tbl, database.GetSessionFromTestingDB(), 0)
+	a.NoError(e)
+	// More fragments than a batch, each filled with its index.
+	buf := make([]byte, (readBatchSize+2)*bufSize)
+	for i := range buf {
+		buf[i] = byte(i / bufSize)
+	}
+	_, e = w.Write(buf)
+	a.NoError(e)
+	a.NoError(w.Close())
+
+	r, e := Open(db.DB, tbl)
+	a.NoError(e)
+	a.Equal(0, len(r.frams))
+	b, e := ioutil.ReadAll(r)
+	a.NoError(e)
+	a.True(bytes.Equal(buf, b))
+	a.NoError(r.Close())
+
+	a.NoError(dropTableIfExists(db.DB, tbl))
+}
+
+func TestSQLFSLazyReader(t *testing.T) {
+	a := assert.New(t)
+	createSQLFSTestingDatabaseOnce.Do(createSQLFSTestingDatabase)
+	db := database.GetTestingDBSingleton()
+
+	tbl := fmt.Sprintf("%s.unittest%d", testDatabaseName, rand.Int())
+	w, e := Create(db.DB, db.DriverName, tbl, database.GetSessionFromTestingDB(), 0)
+	a.NoError(e)
+	buf := make([]byte, bufSize+1)
+	for i := range buf {
+		buf[i] = 'x'
+	}
+	_, e = w.Write(buf)
+	a.NoError(e)
+	a.NoError(w.Close())
+
+	r, e := OpenLazy(db.DB, tbl)
+	a.NoError(e)
+	// Reading within the first fragment doesn't fetch the second one.
+	p := make([]byte, 2)
+	n, e := r.Read(p)
+	a.NoError(e)
+	a.Equal(2, n)
+	a.Equal(1, r.next)
+
+	p = make([]byte, bufSize*2)
+	n, e = r.Read(p)
+	a.Equal(io.EOF, e)
+	a.Equal(bufSize-1, n)
+	a.NoError(r.Close())
+
+	size, e := Size(db.DB, tbl)
+	a.NoError(e)
+	a.True(size > int64(bufSize))
+
+	a.NoError(dropTableIfExists(db.DB, tbl))
+}
+
+func TestSQLFSWriteAndReadWithCodec(t *testing.T) {
+	a := assert.New(t)
+	createSQLFSTestingDatabaseOnce.Do(createSQLFSTestingDatabase)
+	db := database.GetTestingDBSingleton()
+
+	tbl := fmt.Sprintf("%s.unittest%d", testDatabaseName, rand.Int())
+	w, e := CreateWithCodec(db.DB, db.DriverName, tbl, database.GetSessionFromTestingDB(), 0, "zstd")
+	a.NoError(e)
+	buf := bytes.Repeat([]byte("x"), 2*bufSize)
+	_, e = w.Write(buf)
+	a.NoError(e)
+	a.NoError(w.Close())
+
+	size, e := Size(db.DB, tbl)
+	a.NoError(e)
+	a.True(size < int64(bufSize))
+
+	r, e := Open(db.DB, tbl)
+	a.NoError(e)
+	b, e := ioutil.ReadAll(r)
+	a.NoError(e)
+	a.True(bytes.Equal(buf, b))
+	a.NoError(r.Close())
+
+	lr, e := OpenLazy(db.DB, tbl)
+	a.NoError(e)
+	b, e = ioutil.ReadAll(lr)
+	a.NoError(e)
+	a.True(bytes.Equal(buf, b))
+	a.NoError(lr.Close())
+
+	_, e = CreateWithCodec(db.DB, db.DriverName, tbl, database.GetSessionFromTestingDB(), 0, "lz4")
+	a.Error(e)
+
+	a.NoError(dropTableIfExists(db.DB, tbl))
+}
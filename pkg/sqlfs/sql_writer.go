@@ -18,26 +18,137 @@ import (
 	"encoding/base64"
 	"fmt"
 	"io"
+	"strconv"
+	"sync"
 )
 
-func flushToSQLTable(db *sql.DB, table string) func([]byte) error {
-	row := 0
-	return func(buf []byte) error {
-		if db == nil {
-			return fmt.Errorf("flushToSQLTable: no database connection")
+// writeWorkers is the maximum number of chunks a SQL writer inserts
+// concurrently, which speeds up saving large models.
+const writeWorkers = 4
+
+// insertToSQLTable returns a function that inserts the chunk buf as the
+// row id of table.
+func insertToSQLTable(db *sql.DB, table string) func(id int, buf []byte) error {
+	return func(id int, buf []byte) error {
+		block := base64.StdEncoding.EncodeToString(buf)
+		query := fmt.Sprintf("INSERT INTO %s (id, block) VALUES(%d, '%s')",
+			table, id, block)
+		if _, e := db.Exec(query); e != nil {
+			return fmt.Errorf("cannot flush to table %s: %v", table, e)
+		}
+		return nil
+	}
+}
+
+// insertToClickHouseTable is insertToSQLTable for ClickHouse, whose Go
+// driver runs INSERT only in a transaction with a prepared statement.
+func insertToClickHouseTable(db *sql.DB, table string) func(id int, buf []byte) error {
+	return func(id int, buf []byte) error {
+		block := base64.StdEncoding.EncodeToString(buf)
+		tx, e := db.Begin()
+		if e != nil {
+			return fmt.Errorf("cannot flush to table %s: %v", table, e)
+		}
+		stmt, e := tx.Prepare(fmt.Sprintf("INSERT INTO %s (id, block) VALUES (?, ?)", table))
+		if e != nil {
+			tx.Rollback()
+			return fmt.Errorf("cannot flush to table %s: %v", table, e)
+		}
+		defer stmt.Close()
+		if _, e := stmt.Exec(id, block); e != nil {
+			tx.Rollback()
+			return fmt.Errorf("cannot flush to table %s: %v", table, e)
+		}
+		if e := tx.Commit(); e != nil {
+			return fmt.Errorf("cannot flush to table %s: %v", table, e)
 		}
+		return nil
+	}
+}
+
+// chunkUploader numbers the flushed chunks in order, and inserts them
+// by at most workers goroutines.  The first error fails the following
+// flushes and wrapup, which waits for the running inserts, and then
+// writes the end marker.
+type chunkUploader struct {
+	db      *sql.DB
+	insert  func(id int, buf []byte) error
+	workers chan struct{}
+	wg      sync.WaitGroup
+	row     int
+
+	mu  sync.Mutex
+	err error
+}
+
+func newChunkUploader(db *sql.DB, insert func(id int, buf []byte) error, workers int) *chunkUploader {
+	return &chunkUploader{db: db, insert: insert, workers: make(chan struct{}, workers)}
+}
+
+// begin writes the begin marker.
+func (u *chunkUploader) begin() error {
+	if u.db == nil {
+		return fmt.Errorf("flushToSQLTable: no database connection")
+	}
+	return u.insert(beginMarkerID, nil)
+}
 
-		if len(buf) > 0 {
-			block := base64.StdEncoding.EncodeToString(buf)
-			query := fmt.Sprintf("INSERT INTO %s (id, block) VALUES(%d, '%s')",
-				table, row, block)
-			if _, e := db.Exec(query); e != nil {
-				return fmt.Errorf("cannot flush to table %s: %v", table, e)
-			}
-			row++
+func (u *chunkUploader) flush(buf []byte) error {
+	if u.db == nil {
+		return fmt.Errorf("flushToSQLTable: no database connection")
+	}
+	if e := u.error(); e != nil {
+		u.wg.Wait()
+		return e
+	}
+	if len(buf) == 0 {
+		return nil
+	}
+	id := u.row
+	u.row++
+	if cap(u.workers) <= 1 {
+		if e := u.insert(id, buf); e != nil {
+			u.setError(e)
+			return e
 		}
 		return nil
 	}
+	// flushWriteCloser reuses buf after flush returns.
+	chunk := append([]byte{}, buf...)
+	u.workers <- struct{}{}
+	u.wg.Add(1)
+	go func() {
+		defer func() {
+			<-u.workers
+			u.wg.Done()
+		}()
+		if e := u.insert(id, chunk); e != nil {
+			u.setError(e)
+		}
+	}()
+	return nil
+}
+
+func (u *chunkUploader) wrapup() error {
+	u.wg.Wait()
+	if e := u.error(); e != nil {
+		return e
+	}
+	return u.insert(endMarkerID, []byte(strconv.Itoa(u.row)))
+}
+
+func (u *chunkUploader) error() error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.err
+}
+
+func (u *chunkUploader) setError(e error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.err == nil {
+		u.err = e
+	}
 }
 
 func noopWrapUp() error {
@@ -51,5 +162,17 @@ func newSQLWriter(db *sql.DB, dbms, table string, bufSize int) (io.WriteCloser,
 	if e := createTable(db, dbms, table); e != nil {
 		return nil, fmt.Errorf("cannot create table %s: %v", table, e)
 	}
-	return newFlushWriteCloser(flushToSQLTable(db, table), noopWrapUp, bufSize), nil
+	insert, workers := insertToSQLTable(db, table), writeWorkers
+	switch dbms {
+	case "clickhouse":
+		insert = insertToClickHouseTable(db, table)
+	case "sqlite3":
+		// SQLite locks the whole database file for writing.
+		workers = 1
+	}
+	u := newChunkUploader(db, insert, workers)
+	if e := u.begin(); e != nil {
+		return nil, e
+	}
+	return newFlushWriteCloser(u.flush, u.wrapup, bufSize), nil
 }
@@ -14,10 +14,14 @@
 package sqlfs
 
 import (
+	"bytes"
+	"database/sql"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"math/rand"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -106,3 +110,79 @@ func TestSQLFSSQLWriterWriteAndRead(t *testing.T) {
 
 	a.NoError(dropTableIfExists(db.DB, tbl))
 }
+
+func TestSQLFSChunkUploader(t *testing.T) {
+	a := assert.New(t)
+	var (
+		mu              sync.Mutex
+		chunks          = map[int]string{}
+		running, most   int
+		injectedFailure = 5
+	)
+	insert := func(id int, buf []byte) error {
+		mu.Lock()
+		running++
+		if running > most {
+			most = running
+		}
+		chunks[id] = string(buf)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}()
+		if id == injectedFailure {
+			return fmt.Errorf("injected failure")
+		}
+		return nil
+	}
+
+	u := newChunkUploader(&sql.DB{}, insert, 3)
+	w := newFlushWriteCloser(u.flush, u.wrapup, 2)
+	_, e := w.Write([]byte("0011223344"))
+	a.NoError(e)
+	a.NoError(w.Close())
+	a.Equal(map[int]string{0: "00", 1: "11", 2: "22", 3: "33", 4: "44", endMarkerID: "5"}, chunks)
+	a.True(most <= 3)
+
+	chunks = map[int]string{}
+	u = newChunkUploader(&sql.DB{}, insert, 3)
+	w = newFlushWriteCloser(u.flush, u.wrapup, 2)
+	w.Write([]byte("00112233445566778899"))
+	a.Error(w.Close())
+	a.Equal(0, running)
+}
+
+func TestSQLFSWriteWithChunkSize(t *testing.T) {
+	createSQLFSTestingDatabaseOnce.Do(createSQLFSTestingDatabase)
+	db := database.GetTestingDBSingleton()
+	a := assert.New(t)
+
+	tbl := fmt.Sprintf("%s.unittest%d", testDatabaseName, rand.Int())
+	w, e := Create(db.DB, db.DriverName, tbl, database.GetSessionFromTestingDB(), 1000)
+	a.NoError(e)
+	buf := make([]byte, 100*1000+1)
+	for i := range buf {
+		buf[i] = byte(i)
+	}
+	_, e = w.Write(buf)
+	a.NoError(e)
+	a.NoError(w.Close())
+
+	var rows int
+	a.NoError(db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE id >= 0", tbl)).Scan(&rows))
+	a.Equal(101, rows)
+	size, e := Size(db.DB, tbl)
+	a.NoError(e)
+	a.Equal(EncodedSize(int64(len(buf)), 1000), size)
+
+	r, e := Open(db.DB, tbl)
+	a.NoError(e)
+	b, e := ioutil.ReadAll(r)
+	a.NoError(e)
+	a.True(bytes.Equal(buf, b))
+	a.NoError(r.Close())
+
+	a.NoError(dropTableIfExists(db.DB, tbl))
+}
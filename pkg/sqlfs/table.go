@@ -16,6 +16,7 @@ package sqlfs
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 )
 
 // createTable creates a table, if it doesn't exist.  If the table
@@ -24,9 +25,17 @@ import (
 func createTable(db *sql.DB, dbms, table string) error {
 	// HIVE and ODPS don't support AUTO_INCREMENT
 	// Hive and ODPS don't support BLOB, use BINARY instead
+	// PostgreSQL has no BLOB, use BYTEA instead
+	// ClickHouse tables require an engine
 	var stmt string
 	if dbms == "mysql" {
 		stmt = fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id INT, block TEXT, PRIMARY KEY (id))", table)
+	} else if dbms == "postgres" {
+		stmt = fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id INT, block BYTEA, PRIMARY KEY (id))", table)
+	} else if dbms == "sqlite3" {
+		stmt = fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id INT, block BLOB, PRIMARY KEY (id))", table)
+	} else if dbms == "clickhouse" {
+		stmt = fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id Int32, block String) ENGINE = MergeTree() ORDER BY id", table)
 	} else if dbms == "hive" {
 		stmt = fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id INT, block STRING) ROW FORMAT DELIMITED FIELDS TERMINATED BY \"\\001\" STORED AS TEXTFILE", table)
 	} else if dbms == "maxcompute" {
@@ -69,3 +78,71 @@ func hasTable(db *sql.DB, table string) (bool, error) {
 	}
 	return true, nil
 }
+
+// Exists returns true if the table of a sqlfs file exists.
+func Exists(db *sql.DB, table string) bool {
+	has, _ := hasTable(db, table)
+	return has
+}
+
+// Remove drops the table of a sqlfs file.
+func Remove(db *sql.DB, table string) error {
+	return dropTableIfExists(db, table)
+}
+
+// Rename renames the table of a sqlfs file from to, replacing the file
+// to, if any. MySQL and ClickHouse swap the tables in one RENAME TABLE,
+// PostgreSQL and SQLite replace the table in a transaction, so readers
+// see either the old or the new file. Hive and MaxCompute drop to before
+// renaming, so there is a moment with no file. from and to should be in
+// the same database.
+func Rename(db *sql.DB, dbms, from, to string) error {
+	switch dbms {
+	case "mysql", "clickhouse":
+		if !Exists(db, to) {
+			return execRename(db, fmt.Sprintf("RENAME TABLE %s TO %s", from, to))
+		}
+		old := from + "_old"
+		if e := execRename(db, fmt.Sprintf("RENAME TABLE %s TO %s, %s TO %s", to, old, from, to)); e != nil {
+			return e
+		}
+		return dropTableIfExists(db, old)
+	case "postgres", "sqlite3":
+		tx, e := db.Begin()
+		if e != nil {
+			return e
+		}
+		if _, e := tx.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", to)); e != nil {
+			tx.Rollback()
+			return fmt.Errorf("rename %s to %s: %v", from, to, e)
+		}
+		if _, e := tx.Exec(fmt.Sprintf("ALTER TABLE %s RENAME TO %s", from, unqualified(to))); e != nil {
+			tx.Rollback()
+			return fmt.Errorf("rename %s to %s: %v", from, to, e)
+		}
+		return tx.Commit()
+	case "hive", "maxcompute":
+		if e := dropTableIfExists(db, to); e != nil {
+			return e
+		}
+		if dbms == "maxcompute" {
+			// MaxCompute doesn't take the project in the new name.
+			to = unqualified(to)
+		}
+		return execRename(db, fmt.Sprintf("ALTER TABLE %s RENAME TO %s", from, to))
+	}
+	return fmt.Errorf("Rename doesn't recognize dbms %s", dbms)
+}
+
+func execRename(db *sql.DB, stmt string) error {
+	if _, e := db.Exec(stmt); e != nil {
+		return fmt.Errorf("exec:[%s] failed: %v", stmt, e)
+	}
+	return nil
+}
+
+// unqualified returns the table name without the database name, like
+// "tbl" of "db.tbl".
+func unqualified(table string) string {
+	return table[strings.LastIndex(table, ".")+1:]
+}
@@ -15,6 +15,7 @@ package sqlfs
 
 import (
 	"fmt"
+	"io/ioutil"
 	"math/rand"
 	"testing"
 
@@ -35,3 +36,39 @@ func TestSQLFSCreateHasDropTable(t *testing.T) {
 	a.True(has)
 	a.NoError(dropTableIfExists(db.DB, tbl))
 }
+
+func TestSQLFSRename(t *testing.T) {
+	createSQLFSTestingDatabaseOnce.Do(createSQLFSTestingDatabase)
+	db := database.GetTestingDBSingleton()
+	a := assert.New(t)
+
+	write := func(tbl, content string) {
+		w, e := Create(db.DB, db.DriverName, tbl, database.GetSessionFromTestingDB(), 0)
+		a.NoError(e)
+		_, e = w.Write([]byte(content))
+		a.NoError(e)
+		a.NoError(w.Close())
+	}
+	read := func(tbl string) string {
+		r, e := Open(db.DB, tbl)
+		a.NoError(e)
+		defer r.Close()
+		b, e := ioutil.ReadAll(r)
+		a.NoError(e)
+		return string(b)
+	}
+
+	to := fmt.Sprintf("%s.unittest%d", testDatabaseName, rand.Int())
+	from := to + "_tmp"
+	write(from, "first")
+	a.NoError(Rename(db.DB, db.DriverName, from, to))
+	a.False(Exists(db.DB, from))
+	a.Equal("first", read(to))
+
+	// Rename replaces the existing file.
+	write(from, "second")
+	a.NoError(Rename(db.DB, db.DriverName, from, to))
+	a.False(Exists(db.DB, from))
+	a.Equal("second", read(to))
+	a.NoError(dropTableIfExists(db.DB, to))
+}
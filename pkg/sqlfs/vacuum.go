@@ -0,0 +1,123 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlfs
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+)
+
+// The writer returned by Create writes two marker rows besides the
+// chunks, whose ids are from 0: the begin marker when it creates the
+// table, and the end marker, whose block is the number of chunks, when
+// it's closed after all chunks are written.  A file with the begin
+// marker but not the end marker is partial, like one whose writing
+// failed halfway.  The files written by older versions have no marker,
+// and are taken as complete.
+const (
+	beginMarkerID = -1
+	endMarkerID   = -2
+)
+
+// fileState describes the markers of a sqlfs file.
+type fileState struct {
+	begun    bool
+	complete bool
+	chunks   int // chunks is the number of chunks of a complete file.
+}
+
+// partial returns true if the file has been created but not closed.
+func (s *fileState) partial() bool {
+	return s.begun && !s.complete
+}
+
+func readFileState(db *sql.DB, table string) (*fileState, error) {
+	stmt := fmt.Sprintf("SELECT id,block FROM %s WHERE id < 0", table)
+	rows, e := db.Query(stmt)
+	if e != nil {
+		return nil, fmt.Errorf("db query [%s] failed: %v", stmt, e)
+	}
+	defer rows.Close()
+	s := &fileState{}
+	for rows.Next() {
+		var f fragment
+		if e := rows.Scan(&f.id, &f.block); e != nil {
+			return nil, e
+		}
+		switch f.id {
+		case beginMarkerID:
+			s.begun = true
+		case endMarkerID:
+			b, e := base64.StdEncoding.DecodeString(f.block)
+			if e != nil {
+				return nil, fmt.Errorf("invalid end marker of %s: %v", table, e)
+			}
+			if s.chunks, e = strconv.Atoi(string(b)); e != nil {
+				return nil, fmt.Errorf("invalid end marker of %s: %v", table, e)
+			}
+			s.complete = true
+		}
+	}
+	if e := rows.Err(); e != nil {
+		return nil, fmt.Errorf("db query [%s] failed: %v", stmt, e)
+	}
+	if s.complete {
+		s.begun = true
+	}
+	return s, nil
+}
+
+// Complete returns true if the sqlfs file in table has been closed
+// after all its content is written, or it was written by an older
+// version of sqlfs without the markers.  Open and OpenLazy refuse to
+// read a file that is not complete.
+func Complete(db *sql.DB, table string) (bool, error) {
+	s, e := readFileState(db, table)
+	if e != nil {
+		return false, e
+	}
+	return !s.partial(), nil
+}
+
+// Vacuum removes the chunks in table that aren't part of a complete
+// file.  It removes the table of a partial file, like one left by a
+// crashed save, and the rows after the last chunk of a complete file.
+// It does nothing to a file without the markers.
+func Vacuum(db *sql.DB, table string) error {
+	s, e := readFileState(db, table)
+	if e != nil {
+		return fmt.Errorf("vacuum %s: %v", table, e)
+	}
+	if s.partial() {
+		return dropTableIfExists(db, table)
+	}
+	if !s.complete {
+		return nil
+	}
+	var orphans int
+	stmt := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE id >= %d", table, s.chunks)
+	if e := db.QueryRow(stmt).Scan(&orphans); e != nil {
+		return fmt.Errorf("vacuum %s: db query [%s] failed: %v", table, stmt, e)
+	}
+	if orphans == 0 {
+		return nil
+	}
+	stmt = fmt.Sprintf("DELETE FROM %s WHERE id >= %d", table, s.chunks)
+	if _, e := db.Exec(stmt); e != nil {
+		return fmt.Errorf("vacuum %s: exec [%s] failed: %v", table, stmt, e)
+	}
+	return nil
+}
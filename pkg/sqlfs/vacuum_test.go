@@ -0,0 +1,89 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlfs
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sqlflow.org/sqlflow/pkg/database"
+)
+
+func TestSQLFSVacuum(t *testing.T) {
+	createSQLFSTestingDatabaseOnce.Do(createSQLFSTestingDatabase)
+	db := database.GetTestingDBSingleton()
+	a := assert.New(t)
+
+	if db.DriverName == "hive" || db.DriverName == "clickhouse" {
+		t.Skipf("Skip as SQLFLOW_TEST_DB is %s", db.DriverName)
+	}
+
+	// A partial file, like one left by a crashed writer.
+	tbl := fmt.Sprintf("%s.unittest%d", testDatabaseName, rand.Int())
+	a.NoError(createTable(db.DB, db.DriverName, tbl))
+	insert := insertToSQLTable(db.DB, tbl)
+	a.NoError(insert(beginMarkerID, nil))
+	a.NoError(insert(0, []byte("hello")))
+	complete, e := Complete(db.DB, tbl)
+	a.NoError(e)
+	a.False(complete)
+	_, e = Open(db.DB, tbl)
+	a.Error(e)
+	_, e = OpenLazy(db.DB, tbl)
+	a.Error(e)
+	a.NoError(Vacuum(db.DB, tbl))
+	a.False(Exists(db.DB, tbl))
+
+	// A complete file with an orphaned chunk.
+	w, e := Create(db.DB, db.DriverName, tbl, database.GetSessionFromTestingDB(), 0)
+	a.NoError(e)
+	_, e = w.Write([]byte("hello"))
+	a.NoError(e)
+	a.NoError(w.Close())
+	insert = insertToSQLTable(db.DB, tbl)
+	a.NoError(insert(1, []byte(" world")))
+	complete, e = Complete(db.DB, tbl)
+	a.NoError(e)
+	a.True(complete)
+	r, e := Open(db.DB, tbl)
+	a.NoError(e)
+	b, e := ioutil.ReadAll(r)
+	a.NoError(e)
+	a.Equal("hello", string(b))
+	a.NoError(r.Close())
+	a.NoError(Vacuum(db.DB, tbl))
+	size, e := Size(db.DB, tbl)
+	a.NoError(e)
+	a.Equal(EncodedSize(5, 0), size)
+
+	// A file without the markers, written by an older version.
+	a.NoError(dropTableIfExists(db.DB, tbl))
+	a.NoError(createTable(db.DB, db.DriverName, tbl))
+	a.NoError(insert(0, []byte("hello")))
+	complete, e = Complete(db.DB, tbl)
+	a.NoError(e)
+	a.True(complete)
+	a.NoError(Vacuum(db.DB, tbl))
+	r, e = Open(db.DB, tbl)
+	a.NoError(e)
+	b, e = ioutil.ReadAll(r)
+	a.NoError(e)
+	a.Equal("hello", string(b))
+	a.NoError(r.Close())
+
+	a.NoError(dropTableIfExists(db.DB, tbl))
+}
@@ -20,13 +20,45 @@ import (
 	pb "sqlflow.org/sqlflow/pkg/proto"
 )
 
+// bufSize is the default chunk size, the number of bytes written to
+// each row of a table.
 const bufSize = 32 * 1024
 
 // Create creates a new table or truncates an existing table and
-// returns a writer.
-func Create(db *sql.DB, dbms, table string, session *pb.Session) (io.WriteCloser, error) {
+// returns a writer.  The writer writes each chunkSize bytes to a row.
+// Small chunks make many tiny INSERTs, and some databases reject large
+// ones, like MySQL with a small max_allowed_packet.  chunkSize zero or
+// negative means 32KB.
+func Create(db *sql.DB, dbms, table string, session *pb.Session, chunkSize int) (io.WriteCloser, error) {
+	if chunkSize <= 0 {
+		chunkSize = bufSize
+	}
 	if dbms == "hive" {
-		return newHiveWriter(db, session.HiveLocation, table, session.HdfsUser, session.HdfsPass, session.HdfsNamenodeAddr, bufSize)
+		return newHiveWriter(db, session.HiveLocation, table, session.HdfsUser, session.HdfsPass, session.HdfsNamenodeAddr, chunkSize)
+	}
+	return newSQLWriter(db, dbms, table, chunkSize)
+}
+
+// CreateWithCodec is Create, but the returned writer compresses the
+// content with the registered codec of the given name, like "zstd".
+// Open and OpenLazy decompress the file by the codec recorded in it.
+// An empty codec writes the content as is, like Create.
+func CreateWithCodec(db *sql.DB, dbms, table string, session *pb.Session, chunkSize int, codec string) (io.WriteCloser, error) {
+	if codec == "" {
+		return Create(db, dbms, table, session, chunkSize)
+	}
+	c, e := codecByName(codec)
+	if e != nil {
+		return nil, e
+	}
+	w, e := Create(db, dbms, table, session, chunkSize)
+	if e != nil {
+		return nil, e
+	}
+	cw, e := newCodecWriter(w, c)
+	if e != nil {
+		w.Close()
+		return nil, e
 	}
-	return newSQLWriter(db, dbms, table, bufSize)
+	return cw, nil
 }
@@ -45,7 +45,7 @@ func TestSQLFSWriterCreate(t *testing.T) {
 	a := assert.New(t)
 
 	tbl := fmt.Sprintf("%s.unittest%d", testDatabaseName, rand.Int())
-	w, e := Create(db.DB, db.DriverName, tbl, database.GetSessionFromTestingDB())
+	w, e := Create(db.DB, db.DriverName, tbl, database.GetSessionFromTestingDB(), 0)
 	a.NoError(e)
 	a.NotNil(w)
 	defer w.Close()
@@ -15,10 +15,14 @@ package feature
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"sqlflow.org/sqlflow/pkg/database"
 	"sqlflow.org/sqlflow/pkg/ir"
@@ -118,23 +122,85 @@ func makeFieldDescMap(features map[string][]ir.FeatureColumn) FieldDescMap {
 	return fmMap
 }
 
-func unifyDatabaseTypeName(typeName string) string {
+// UnifiedTypeName returns the type name of a field unified across the
+// databases, like INT for INT4 of PostgreSQL and Int32 of ClickHouse.
+func UnifiedTypeName(typeName string) string {
 	// NOTE(typhoonzero): Hive uses typenames like "XXX_TYPE"
 	if strings.HasSuffix(typeName, "_TYPE") {
 		typeName = strings.Replace(typeName, "_TYPE", "", 1)
 	}
 
 	// NOTE(tony): MaxCompute type name is in lower cases
-	return strings.ToUpper(typeName)
+	typeName = strings.ToUpper(typeName)
+
+	// NOTE: ClickHouse type names are like Nullable(Float64)
+	for _, wrapper := range []string{"NULLABLE(", "LOWCARDINALITY("} {
+		if strings.HasPrefix(typeName, wrapper) && strings.HasSuffix(typeName, ")") {
+			typeName = typeName[len(wrapper) : len(typeName)-1]
+		}
+	}
+	// NOTE: SQLite type names are the declared ones, like VARCHAR(255), and
+	// MaxCompute array type names are like ARRAY<FLOAT>
+	if i := strings.IndexAny(typeName, "(<"); i > 0 {
+		typeName = typeName[:i]
+	}
+
+	if t, ok := typeNameAliases[typeName]; ok {
+		return t
+	}
+	return typeName
+}
+
+// typeNameAliases maps the type names of PostgreSQL, like INT4 and
+// FLOAT8, ClickHouse, like INT32 and FLOAT64, and SQLite, like INTEGER
+// and REAL, to the unified ones.
+var typeNameAliases = map[string]string{
+	"INT2":    "INT",
+	"INT4":    "INT",
+	"INT8":    "BIGINT",
+	"FLOAT4":  "FLOAT",
+	"FLOAT8":  "DOUBLE",
+	"NUMERIC": "DECIMAL",
+	"BPCHAR":  "VARCHAR",
+	"INT16":   "INT",
+	"INT32":   "INT",
+	"INT64":   "BIGINT",
+	"UINT8":   "INT",
+	"UINT16":  "INT",
+	"UINT32":  "BIGINT",
+	"FLOAT32": "FLOAT",
+	"FLOAT64": "DOUBLE",
+	"INTEGER": "INT",
+	"REAL":    "DOUBLE",
+	// NOTE: ClickHouse DateTime64(3) and PostgreSQL TIMESTAMPTZ
+	"DATETIME64":  "DATETIME",
+	"TIMESTAMPTZ": "TIMESTAMP",
+	// NOTE: PostgreSQL array type names are like _FLOAT8
+	"_FLOAT4": "ARRAY",
+	"_FLOAT8": "ARRAY",
+	"_INT4":   "ARRAY",
+	"_INT8":   "ARRAY",
+	"JSONB":   "JSON",
+}
+
+// defaultTimeParts maps the unified type names of the time fields to the
+// parts derived from them if the COLUMN clause specifies none, like HOUR(ts).
+var defaultTimeParts = map[string][]string{
+	"TIMESTAMP": {"hour", "dayofweek", "month"},
+	"DATETIME":  {"hour", "dayofweek", "month"},
+	"DATE":      {"dayofweek", "month"},
 }
 
 func newRowValue(columnTypeList []*sql.ColumnType) ([]interface{}, error) {
 	rowData := make([]interface{}, len(columnTypeList))
 	for idx, ct := range columnTypeList {
 		typeName := ct.DatabaseTypeName()
-		switch unifyDatabaseTypeName(typeName) {
-		case "VARCHAR", "TEXT", "STRING":
+		switch UnifiedTypeName(typeName) {
+		case "VARCHAR", "TEXT", "STRING", "DATE", "DATETIME", "TIMESTAMP", "JSON":
 			rowData[idx] = new(string)
+		case "ARRAY":
+			// NOTE: Hive returns arrays as strings and ClickHouse as slices
+			rowData[idx] = new(interface{})
 		case "INT":
 			rowData[idx] = new(int32)
 		case "BIGINT", "DECIMAL":
@@ -225,6 +291,249 @@ func fillNonCSVFieldDesc(cellData string, fieldDescMap FieldDescMap, fieldName s
 	return nil
 }
 
+// fillTimeFieldDesc sets the FieldDesc of a time field to decode the time
+// parts as a dense float tensor. If the COLUMN clause doesn't specify the
+// parts, it derives the default ones of typeName, cyclically encoded.
+func fillTimeFieldDesc(fd *ir.FieldDesc, typeName string) {
+	if fd.TimeParts == nil {
+		fd.TimeParts = append([]string{}, defaultTimeParts[typeName]...)
+		fd.Cyclical = true
+	}
+	fd.DType = ir.Float
+	fd.Shape = []int{ir.TimeFeatureSize(fd.TimeParts, fd.Cyclical)}
+}
+
+// arrayValues returns the values in an array, like the Hive one "[0.1,0.2]",
+// the PostgreSQL one "{0.1,0.2}", or a slice returned by ClickHouse.
+func arrayValues(v interface{}) ([]string, error) {
+	switch a := v.(type) {
+	case []byte:
+		return splitArray(string(a)), nil
+	case string:
+		return splitArray(a), nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("unsupported array value: %v", v)
+	}
+	values := make([]string, rv.Len())
+	for i := range values {
+		values[i] = fmt.Sprint(rv.Index(i).Interface())
+	}
+	return values, nil
+}
+
+func splitArray(s string) []string {
+	s = strings.TrimSpace(strings.Trim(strings.TrimSpace(s), "[]{}"))
+	if s == "" {
+		return []string{}
+	}
+	values := strings.Split(s, ",")
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
+	}
+	return values
+}
+
+// fillArrayFieldDesc sets the FieldDesc of a field saving arrays of
+// numbers to decode them as a dense tensor. The arrays of a dense field
+// should be of the same length.
+func fillArrayFieldDesc(values []string, fd *ir.FieldDesc) error {
+	fd.IsArray = true
+	if !fd.IsSparse && fd.Shape == nil {
+		fd.Shape = []int{len(values)}
+	}
+	if !fd.IsSparse && (len(fd.Shape) != 1 || fd.Shape[0] != len(values)) {
+		return fmt.Errorf("the arrays in column %s should be of the shape %v, got length %d", fd.Name, fd.Shape, len(values))
+	}
+	for _, v := range values {
+		intValue, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			if _, err := strconv.ParseFloat(v, 32); err != nil {
+				return fmt.Errorf("column %s should save arrays of numbers, got %s", fd.Name, v)
+			}
+			fd.DType = ir.Float
+		} else if intValue > fd.MaxID {
+			fd.MaxID = intValue
+		}
+	}
+	return nil
+}
+
+var jsonPathRegex = regexp.MustCompile(`\.([^.\[\]]+)|\[([0-9]+)\]`)
+
+// jsonValue returns the value at path, like "$.user.age" or "$.scores[0]",
+// in doc, or nil if there is no such value.
+func jsonValue(doc interface{}, path string) (interface{}, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("JSON path should start with $, got %s", path)
+	}
+	steps := jsonPathRegex.FindAllStringSubmatch(path[1:], -1)
+	matched := ""
+	for _, step := range steps {
+		matched += step[0]
+	}
+	if matched != path[1:] {
+		return nil, fmt.Errorf("invalid JSON path %s, should be like $.user.age or $.scores[0]", path)
+	}
+	v := doc
+	for _, step := range steps {
+		if step[1] != "" {
+			obj, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, nil
+			}
+			v = obj[step[1]]
+		} else {
+			arr, ok := v.([]interface{})
+			i, _ := strconv.Atoi(step[2])
+			if !ok || i >= len(arr) {
+				return nil, nil
+			}
+			v = arr[i]
+		}
+	}
+	return v, nil
+}
+
+// fillJSONFieldDesc sets the FieldDesc of a field saving JSON strings to
+// decode the numbers at fd.JSONPaths as a dense float tensor. The missing
+// values are decoded as 0.
+func fillJSONFieldDesc(cellData string, fd *ir.FieldDesc) error {
+	var doc interface{}
+	if err := json.Unmarshal([]byte(cellData), &doc); err != nil {
+		return fmt.Errorf("column %s should save JSON strings, got %s", fd.Name, cellData)
+	}
+	for _, p := range fd.JSONPaths {
+		v, err := jsonValue(doc, p)
+		if err != nil {
+			return err
+		}
+		switch v.(type) {
+		case nil, float64, bool:
+		default:
+			return fmt.Errorf("JSON_VALUE(%s, '%s') should extract numbers, got %v", fd.Name, p, v)
+		}
+	}
+	fd.DType = ir.Float
+	fd.Shape = []int{len(fd.JSONPaths)}
+	return nil
+}
+
+var wordRegex = regexp.MustCompile(`[\p{L}\p{N}_]+`)
+
+// tokenize splits text into tokens by tokenizer. Keep consistent with
+// tokenize in python/sqlflow_submitter/db.py.
+func tokenize(text, tokenizer string) ([]string, error) {
+	switch tokenizer {
+	case "whitespace":
+		return strings.Fields(text), nil
+	case "word":
+		return wordRegex.FindAllString(strings.ToLower(text), -1), nil
+	case "char":
+		tokens := []string{}
+		for _, r := range text {
+			if !unicode.IsSpace(r) {
+				tokens = append(tokens, string(r))
+			}
+		}
+		return tokens, nil
+	}
+	return nil, fmt.Errorf("unsupported tokenizer %s, should be one of whitespace, word and char", tokenizer)
+}
+
+// buildTextVocabularies makes a pass over all the rows of query to build
+// the vocabularies of the TEXT columns in fmMap. Each vocabulary keeps the
+// most frequent MaxID tokens, and MaxID is set to the vocabulary size.
+func buildTextVocabularies(db *database.DB, query string, fmMap FieldDescMap) error {
+	texts := make(map[string]*ir.FieldDesc)
+	for name, fd := range fmMap {
+		if fd.Tokenizer != "" {
+			texts[name] = fd
+		}
+	}
+	if len(texts) == 0 {
+		return nil
+	}
+	rows, err := db.Query(query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	values := make([]interface{}, len(columns))
+	for i := range values {
+		values[i] = new(interface{})
+	}
+	counts := make(map[string]map[string]int)
+	for rows.Next() {
+		if err := rows.Scan(values...); err != nil {
+			return err
+		}
+		for i, c := range columns {
+			_, fld := decomp(c)
+			fd, ok := texts[fld]
+			if !ok {
+				continue
+			}
+			text := ""
+			switch v := (*values[i].(*interface{})).(type) {
+			case []byte:
+				text = string(v)
+			case string:
+				text = v
+			case nil:
+			default:
+				text = fmt.Sprint(v)
+			}
+			tokens, err := tokenize(text, fd.Tokenizer)
+			if err != nil {
+				return err
+			}
+			if counts[fld] == nil {
+				counts[fld] = make(map[string]int)
+			}
+			for _, t := range tokens {
+				counts[fld][t]++
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for fld, fd := range texts {
+		fd.Vocabulary = topTokens(counts[fld], int(fd.MaxID))
+		fd.MaxID = int64(len(fd.Vocabulary))
+	}
+	return nil
+}
+
+// topTokens returns the n most frequent tokens in counts. The tokens of the
+// same count are in the alphabetical order.
+func topTokens(counts map[string]int, n int) map[string]string {
+	tokens := make([]string, 0, len(counts))
+	for t := range counts {
+		tokens = append(tokens, t)
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		if counts[tokens[i]] != counts[tokens[j]] {
+			return counts[tokens[i]] > counts[tokens[j]]
+		}
+		return tokens[i] < tokens[j]
+	})
+	if len(tokens) > n {
+		tokens = tokens[:n]
+	}
+	vocabulary := make(map[string]string)
+	for _, t := range tokens {
+		vocabulary[t] = t
+	}
+	return vocabulary
+}
+
 func fillFieldDesc(columnTypeList []*sql.ColumnType, rowdata []interface{}, fieldDescMap FieldDescMap) error {
 	csvRegex, err := regexp.Compile("(\\-?[0-9\\.]\\,)+(\\-?[0-9\\.])")
 	if err != nil {
@@ -238,7 +547,7 @@ func fillFieldDesc(columnTypeList []*sql.ColumnType, rowdata []interface{}, fiel
 		}
 		// start the feature derivation routine
 		typeName := ct.DatabaseTypeName()
-		switch unifyDatabaseTypeName(typeName) {
+		switch t := UnifiedTypeName(typeName); t {
 		case "INT", "DECIMAL", "BIGINT":
 			fieldDescMap[fld].DType = ir.Int
 			fieldDescMap[fld].Shape = []int{1}
@@ -247,11 +556,39 @@ func fillFieldDesc(columnTypeList []*sql.ColumnType, rowdata []interface{}, fiel
 			fieldDescMap[fld].Shape = []int{1}
 		case "VARCHAR", "TEXT", "STRING":
 			cellData := rowdata[idx].(*string)
-			if csvRegex.MatchString(*cellData) {
+			if fieldDescMap[fld].Tokenizer != "" {
+				// the vocabulary of a TEXT column is built by buildTextVocabularies
+				continue
+			}
+			// the strings of time, like "2020-08-01 10:00:00", transformed by HOUR(ts) etc.
+			if fieldDescMap[fld].TimeParts != nil {
+				fillTimeFieldDesc(fieldDescMap[fld], t)
+			} else if fieldDescMap[fld].JSONPaths != nil {
+				if err := fillJSONFieldDesc(*cellData, fieldDescMap[fld]); err != nil {
+					return err
+				}
+			} else if csvRegex.MatchString(*cellData) {
 				fillCSVFieldDesc(*cellData, fieldDescMap, fld)
 			} else {
 				fillNonCSVFieldDesc(*cellData, fieldDescMap, fld)
 			}
+		case "DATE", "DATETIME", "TIMESTAMP":
+			fillTimeFieldDesc(fieldDescMap[fld], t)
+		case "ARRAY":
+			values, err := arrayValues(*rowdata[idx].(*interface{}))
+			if err != nil {
+				return err
+			}
+			if err := fillArrayFieldDesc(values, fieldDescMap[fld]); err != nil {
+				return err
+			}
+		case "JSON":
+			if fieldDescMap[fld].JSONPaths == nil {
+				return fmt.Errorf("column %s of type JSON should be extracted like JSON_VALUE(%s, '$.key') in the COLUMN clause", fld, fld)
+			}
+			if err := fillJSONFieldDesc(*rowdata[idx].(*string), fieldDescMap[fld]); err != nil {
+				return err
+			}
 		default:
 			return fmt.Errorf("fillFieldDesc: unsupported database column type: %s", typeName)
 		}
@@ -263,6 +600,7 @@ func fillFieldDesc(columnTypeList []*sql.ColumnType, rowdata []interface{}, fiel
 // for all fields.
 // if wr is not nil, then write
 func InferFeatureColumns(trainStmt *ir.TrainStmt, db *database.DB) error {
+	mergeExtractionColumns(trainStmt.Features)
 	fcMap := makeColumnMap(trainStmt.Features)
 	fmMap := makeFieldDescMap(trainStmt.Features)
 
@@ -280,6 +618,7 @@ func InferFeatureColumns(trainStmt *ir.TrainStmt, db *database.DB) error {
 
 	selectFieldTypeMap := make(fieldTypes)
 	selectFieldNames := []string{}
+	trainStmt.ColumnTypes = map[string]string{}
 	for _, ct := range columnTypes {
 		_, fld := decomp(ct.Name())
 		typeName := ct.DatabaseTypeName()
@@ -288,12 +627,17 @@ func InferFeatureColumns(trainStmt *ir.TrainStmt, db *database.DB) error {
 		}
 		selectFieldTypeMap[fld] = typeName
 		selectFieldNames = append(selectFieldNames, fld)
+		trainStmt.ColumnTypes[fld] = UnifiedTypeName(typeName)
 	}
 
 	err = fillFieldDescs(rows, columnTypes, fmMap)
 	if err != nil {
 		return err
 	}
+	err = buildTextVocabularies(db, trainStmt.Select, fmMap)
+	if err != nil {
+		return err
+	}
 
 	columnTargets := getFeatureColumnTargets(trainStmt)
 	err = deriveFeatureColumn(fcMap, columnTargets, fmMap, selectFieldTypeMap, trainStmt)
@@ -305,6 +649,52 @@ func InferFeatureColumns(trainStmt *ir.TrainStmt, db *database.DB) error {
 	return deriveLabel(trainStmt, fmMap)
 }
 
+// mergeExtractionColumns merges the extractions from the same field in
+// each target, like HOUR(ts), DAYOFWEEK(ts) or JSON_VALUE(payload, '$.age'),
+// JSON_VALUE(payload, '$.income'), into one NumericColumn extracting all the
+// values, so that the field is decoded once. The merged time parts are
+// cyclically encoded if any of the transforms is.
+func mergeExtractionColumns(features map[string][]ir.FeatureColumn) {
+	for target, fcList := range features {
+		merged := []ir.FeatureColumn{}
+		extractions := make(map[string]*ir.NumericColumn)
+		for _, fc := range fcList {
+			nc, ok := fc.(*ir.NumericColumn)
+			if !ok || (nc.FieldDesc.TimeParts == nil && nc.FieldDesc.JSONPaths == nil) {
+				merged = append(merged, fc)
+				continue
+			}
+			m, ok := extractions[nc.FieldDesc.Name]
+			if !ok {
+				extractions[nc.FieldDesc.Name] = nc
+				merged = append(merged, nc)
+				continue
+			}
+			m.FieldDesc.TimeParts = appendUnique(m.FieldDesc.TimeParts, nc.FieldDesc.TimeParts)
+			m.FieldDesc.Cyclical = m.FieldDesc.Cyclical || nc.FieldDesc.Cyclical
+			m.FieldDesc.JSONPaths = appendUnique(m.FieldDesc.JSONPaths, nc.FieldDesc.JSONPaths)
+		}
+		features[target] = merged
+	}
+}
+
+// appendUnique appends the strings in values not in list to list.
+func appendUnique(list []string, values []string) []string {
+	for _, s := range values {
+		exists := false
+		for _, v := range list {
+			if v == s {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
 // getFeatureColumnTargets returns the list of strings, which will be used as
 // the parameter keys when initialize a model, e.g.
 // https://www.tensorflow.org/api_docs/python/tf/estimator/DNNLinearCombinedClassifier#__init__
@@ -372,8 +762,9 @@ func deriveFeatureColumn(fcMap ColumnMap, columnTargets []string, fdMap FieldDes
 		// ================== MAIN LOOP ==================
 		// Update or generate FeatureColumn for each selected field:
 		for slctKey := range selectFieldTypeMap {
-			// skip label field
-			if trainStmt.Label.GetFieldDesc()[0].Name == slctKey {
+			// skip label field, and the column of the groups, which is
+			// the same in the rows of a group
+			if trainStmt.Label.GetFieldDesc()[0].Name == slctKey || trainStmt.Grouping.Column == slctKey {
 				continue
 			}
 			if fcList, ok := fcTargetMap[slctKey]; ok {
@@ -437,10 +828,20 @@ func fillFieldDescs(rows *sql.Rows, columnTypes []*sql.ColumnType, fmMap FieldDe
 	return rows.Err()
 }
 
+// updateTextBucketSize sets the bucket size of the CategoryIDColumn of a
+// TEXT column to its vocabulary size plus one for the out-of-vocabulary id 0.
+func updateTextBucketSize(fc ir.FeatureColumn) {
+	if c, ok := fc.(*ir.CategoryIDColumn); ok && c.FieldDesc.Tokenizer != "" {
+		c.BucketSize = c.FieldDesc.MaxID + 1
+	}
+}
+
 func updateFeatureColumn(fcList []ir.FeatureColumn, fmMap FieldDescMap) error {
 	for _, fc := range fcList {
+		updateTextBucketSize(fc)
 		switch c := fc.(type) {
 		case *ir.EmbeddingColumn:
+			updateTextBucketSize(c.CategoryColumn)
 			if c.CategoryColumn == nil {
 				cs, ok := fmMap[c.Name]
 				if !ok {
@@ -463,6 +864,7 @@ func updateFeatureColumn(fcList []ir.FeatureColumn, fmMap FieldDescMap) error {
 				}
 			}
 		case *ir.IndicatorColumn:
+			updateTextBucketSize(c.CategoryColumn)
 			if c.CategoryColumn == nil {
 				cs, ok := fmMap[c.Name]
 				if !ok {
@@ -521,8 +923,9 @@ func setDerivedFeatureColumnToIR(trainStmt *ir.TrainStmt, fcMap ColumnMap, colum
 		// append cross columns at the end of all selected fields.
 		crossColumns := []*ir.CrossColumn{}
 		for _, slctKey := range selectFieldNames {
-			// label should not be added to feature columns
-			if slctKey == trainStmt.Label.GetFieldDesc()[0].Name {
+			// label and the column of the groups should not be added to
+			// feature columns
+			if slctKey == trainStmt.Label.GetFieldDesc()[0].Name || slctKey == trainStmt.Grouping.Column {
 				continue
 			}
 			for _, fc := range targetFeatureColumnMap[slctKey] {
@@ -67,6 +67,8 @@ func Render(rsp interface{}, table tablewriter.TableWriter, isTerminal, it2Check
 			table.FlushWithError(s)
 			log.Fatalf("workflow step failed: %v", s)
 		}
+	case sql.Progress:
+		log.Println(s)
 	case sql.EndOfExecution:
 	case sql.Figures:
 		if isHTMLCode(s.Image) {
@@ -0,0 +1,235 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package stepcache skips the steps of the workflows whose outputs are
+// up to date.  The fingerprint of a step is of its statement and the
+// snapshots of its input tables.  After a step succeeds, the cache
+// records the snapshots of its outputs, like the trained model and the
+// result tables, for the fingerprint.  Running the step again with the
+// same fingerprint is skipped if its outputs are unchanged since.
+package stepcache
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"time"
+
+	"sqlflow.org/sqlflow/pkg/database"
+)
+
+// timeFormat is the format of the times in the cache table.
+const timeFormat = "2006-01-02T15:04:05.000000Z"
+
+// Step is a statement of a workflow step to cache.
+type Step struct {
+	SQL string
+	// Attributes are the WITH attributes of the statement, which are
+	// in SQL as well, but in any order there.
+	Attributes map[string]string
+	// Inputs are the tables that the statement reads, including the
+	// models.
+	Inputs []string
+	// Outputs are the tables that the statement writes, including the
+	// models.
+	Outputs []string
+}
+
+// Cache records the outputs of the steps in a table.
+type Cache struct {
+	db    *database.DB
+	table string
+}
+
+// New returns a Cache in table of db, which is created if not exists.
+// db is of MySQL, PostgreSQL or SQLite.
+func New(db *database.DB, table string) (*Cache, error) {
+	switch db.DriverName {
+	case "mysql", "postgres", "sqlite3":
+	default:
+		return nil, fmt.Errorf("step cache in the tables of %s is not supported", db.DriverName)
+	}
+	stmt := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (fingerprint VARCHAR(64) PRIMARY KEY, outputs TEXT, created_time VARCHAR(32))",
+		table)
+	if _, e := db.Exec(stmt); e != nil {
+		return nil, fmt.Errorf("create the step cache table %s failed: %v", table, e)
+	}
+	return &Cache{db: db, table: table}, nil
+}
+
+// placeholder returns the i-th placeholder of the statements, 1-based.
+func (c *Cache) placeholder(i int) string {
+	if c.db.DriverName == "postgres" {
+		return fmt.Sprintf("$%d", i)
+	}
+	return "?"
+}
+
+// Fingerprint returns the fingerprint of s, or an error if the
+// snapshot of any input fails.
+func (c *Cache) Fingerprint(s *Step) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "sql %s\n", normalize(s.SQL))
+	keys := []string{}
+	for k := range s.Attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(h, "attribute %s=%s\n", k, s.Attributes[k])
+	}
+	for _, t := range uniqueSorted(s.Inputs) {
+		snapshot, e := Snapshot(c.db, t)
+		if e != nil {
+			return "", e
+		}
+		fmt.Fprintf(h, "input %s %s\n", t, snapshot)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Hit returns true if the step of fingerprint has run, and outputs are
+// unchanged since.
+func (c *Cache) Hit(fingerprint string, outputs []string) (bool, error) {
+	stmt := fmt.Sprintf("SELECT outputs FROM %s WHERE fingerprint = %s", c.table, c.placeholder(1))
+	var text string
+	if e := c.db.QueryRow(stmt, fingerprint).Scan(&text); e == sql.ErrNoRows {
+		return false, nil
+	} else if e != nil {
+		return false, e
+	}
+	saved := map[string]string{}
+	if e := json.Unmarshal([]byte(text), &saved); e != nil {
+		return false, e
+	}
+	outputs = uniqueSorted(outputs)
+	if len(outputs) == 0 || len(outputs) != len(saved) {
+		return false, nil
+	}
+	for _, t := range outputs {
+		// A dropped output fails the snapshot, which is a miss.
+		if snapshot, e := Snapshot(c.db, t); e != nil || snapshot != saved[t] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Save records the snapshots of outputs for the step of fingerprint,
+// which has succeeded.
+func (c *Cache) Save(fingerprint string, outputs []string) error {
+	saved := map[string]string{}
+	for _, t := range uniqueSorted(outputs) {
+		snapshot, e := Snapshot(c.db, t)
+		if e != nil {
+			return e
+		}
+		saved[t] = snapshot
+	}
+	text, e := json.Marshal(saved)
+	if e != nil {
+		return e
+	}
+	tx, e := c.db.Begin()
+	if e != nil {
+		return e
+	}
+	if _, e := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE fingerprint = %s", c.table, c.placeholder(1)), fingerprint); e != nil {
+		tx.Rollback()
+		return e
+	}
+	stmt := fmt.Sprintf("INSERT INTO %s (fingerprint, outputs, created_time) VALUES (%s, %s, %s)",
+		c.table, c.placeholder(1), c.placeholder(2), c.placeholder(3))
+	if _, e := tx.Exec(stmt, fingerprint, string(text), time.Now().UTC().Format(timeFormat)); e != nil {
+		tx.Rollback()
+		return e
+	}
+	return tx.Commit()
+}
+
+// Snapshot returns a string that changes with the content of table.
+// It's the checksum of MySQL, or the number of the rows and the sum of
+// their hashes for the others, which reads the whole table.
+func Snapshot(db *database.DB, table string) (string, error) {
+	if db.DriverName == "mysql" {
+		var name string
+		var checksum sql.NullString
+		if e := db.QueryRow("CHECKSUM TABLE "+table).Scan(&name, &checksum); e != nil {
+			return "", fmt.Errorf("checksum the table %s failed: %v", table, e)
+		}
+		// The checksum of a nonexistent table is NULL.
+		if !checksum.Valid {
+			return "", fmt.Errorf("table %s doesn't exist", table)
+		}
+		return checksum.String, nil
+	}
+	rows, e := db.Query("SELECT * FROM " + table)
+	if e != nil {
+		return "", fmt.Errorf("read the table %s failed: %v", table, e)
+	}
+	defer rows.Close()
+	columns, e := rows.Columns()
+	if e != nil {
+		return "", e
+	}
+	values := make([]sql.RawBytes, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	// The sum of the row hashes doesn't depend on the order of the rows.
+	count, sum := 0, uint64(0)
+	for rows.Next() {
+		if e := rows.Scan(dest...); e != nil {
+			return "", e
+		}
+		h := fnv.New64a()
+		for _, v := range values {
+			if v == nil {
+				fmt.Fprint(h, "-1:")
+			} else {
+				fmt.Fprintf(h, "%d:%s", len(v), v)
+			}
+		}
+		count++
+		sum += h.Sum64()
+	}
+	if e := rows.Err(); e != nil {
+		return "", e
+	}
+	return fmt.Sprintf("%d:%016x", count, sum), nil
+}
+
+// normalize returns sql with the whitespaces collapsed and without the
+// trailing semicolon.
+func normalize(sql string) string {
+	return strings.TrimSpace(strings.TrimSuffix(strings.Join(strings.Fields(sql), " "), ";"))
+}
+
+func uniqueSorted(tables []string) []string {
+	set := map[string]bool{}
+	for _, t := range tables {
+		set[t] = true
+	}
+	unique := []string{}
+	for t := range set {
+		unique = append(unique, t)
+	}
+	sort.Strings(unique)
+	return unique
+}
@@ -0,0 +1,132 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stepcache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sqlflow.org/sqlflow/pkg/database"
+)
+
+func TestCache(t *testing.T) {
+	a := assert.New(t)
+	dir, e := ioutil.TempDir("", "sqlflow_step_cache")
+	a.NoError(e)
+	defer os.RemoveAll(dir)
+	db, e := database.OpenAndConnectDB("sqlite3://" + filepath.Join(dir, "step_cache.db"))
+	a.NoError(e)
+	defer db.Close()
+	for _, stmt := range []string{
+		"CREATE TABLE train (sepal_length FLOAT, class INT)",
+		"INSERT INTO train VALUES (5.1, 0), (6.4, 1)",
+		"CREATE TABLE my_model (id INT, block TEXT)",
+	} {
+		_, e := db.Exec(stmt)
+		a.NoError(e)
+	}
+
+	c, e := New(db, "sqlflow_step_cache")
+	a.NoError(e)
+	step := &Step{
+		SQL:        "SELECT * FROM train TO TRAIN DNNClassifier WITH model.n_classes = 2, train.epoch = 10 LABEL class INTO my_model;",
+		Attributes: map[string]string{"model.n_classes": "2", "train.epoch": "10"},
+		Inputs:     []string{"train"},
+		Outputs:    []string{"my_model"},
+	}
+	fp, e := c.Fingerprint(step)
+	a.NoError(e)
+	hit, e := c.Hit(fp, step.Outputs)
+	a.NoError(e)
+	a.False(hit)
+
+	_, e = db.Exec("INSERT INTO my_model VALUES (0, 'model')")
+	a.NoError(e)
+	a.NoError(c.Save(fp, step.Outputs))
+	hit, e = c.Hit(fp, step.Outputs)
+	a.NoError(e)
+	a.True(hit)
+
+	// The whitespaces and the order of the attributes don't matter.
+	same := &Step{
+		SQL:        "SELECT *\nFROM train\nTO TRAIN DNNClassifier\nWITH model.n_classes = 2, train.epoch = 10\nLABEL class\nINTO my_model",
+		Attributes: step.Attributes,
+		Inputs:     []string{"train", "train"},
+		Outputs:    step.Outputs,
+	}
+	f, e := c.Fingerprint(same)
+	a.NoError(e)
+	a.Equal(fp, f)
+
+	// Changing the attributes, the inputs or the outputs misses.
+	f, e = c.Fingerprint(&Step{SQL: step.SQL, Attributes: map[string]string{"train.epoch": "20"}, Inputs: step.Inputs})
+	a.NoError(e)
+	a.NotEqual(fp, f)
+
+	_, e = db.Exec("INSERT INTO my_model VALUES (1, 'overwritten')")
+	a.NoError(e)
+	hit, e = c.Hit(fp, step.Outputs)
+	a.NoError(e)
+	a.False(hit)
+
+	_, e = db.Exec("INSERT INTO train VALUES (4.9, 0)")
+	a.NoError(e)
+	f, e = c.Fingerprint(step)
+	a.NoError(e)
+	a.NotEqual(fp, f)
+
+	_, e = db.Exec("DROP TABLE my_model")
+	a.NoError(e)
+	hit, e = c.Hit(fp, step.Outputs)
+	a.NoError(e)
+	a.False(hit)
+
+	_, e = c.Fingerprint(&Step{SQL: step.SQL, Inputs: []string{"nonexistent"}})
+	a.Error(e)
+}
+
+func TestSnapshot(t *testing.T) {
+	a := assert.New(t)
+	dir, e := ioutil.TempDir("", "sqlflow_step_cache")
+	a.NoError(e)
+	defer os.RemoveAll(dir)
+	db, e := database.OpenAndConnectDB("sqlite3://" + filepath.Join(dir, "step_cache.db"))
+	a.NoError(e)
+	defer db.Close()
+	_, e = db.Exec("CREATE TABLE t1 (a INT, b TEXT)")
+	a.NoError(e)
+	_, e = db.Exec("CREATE TABLE t2 (a INT, b TEXT)")
+	a.NoError(e)
+	_, e = db.Exec("INSERT INTO t1 VALUES (1, 'x'), (2, NULL)")
+	a.NoError(e)
+	_, e = db.Exec("INSERT INTO t2 VALUES (2, NULL), (1, 'x')")
+	a.NoError(e)
+
+	// The order of the rows doesn't matter.
+	s1, e := Snapshot(db, "t1")
+	a.NoError(e)
+	s2, e := Snapshot(db, "t2")
+	a.NoError(e)
+	a.Equal(s1, s2)
+
+	_, e = db.Exec("UPDATE t2 SET b = '' WHERE a = 2")
+	a.NoError(e)
+	s2, e = Snapshot(db, "t2")
+	a.NoError(e)
+	a.NotEqual(s1, s2)
+}
@@ -45,6 +45,12 @@ func Create(name string, bufSize int, w io.Writer) (TableWriter, error) {
 		return createASCIIWriter(bufSize, w), nil
 	} else if name == "protobuf" {
 		return createProtobufWriter(bufSize, w), nil
+	} else if name == "csv" {
+		return createCSVWriter(bufSize, w, ','), nil
+	} else if name == "tsv" {
+		return createCSVWriter(bufSize, w, '\t'), nil
+	} else if name == "json" {
+		return createJSONWriter(bufSize, w), nil
 	}
 	return nil, fmt.Errorf("SQLFLow does not support the tablewriter : %s", name)
 }
@@ -0,0 +1,74 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tablewriter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// CSVWriter writes table as CSV, or TSV if the separator is a tab
+type CSVWriter struct {
+	w       *csv.Writer
+	bufSize int
+	rows    int
+}
+
+func createCSVWriter(bufSize int, w io.Writer, comma rune) *CSVWriter {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	return &CSVWriter{w: cw, bufSize: bufSize}
+}
+
+// SetHeader writes the column names as the first line
+func (t *CSVWriter) SetHeader(head map[string]interface{}) error {
+	cn, ok := head["columnNames"]
+	if !ok {
+		return fmt.Errorf("can't find field columnNames in head")
+	}
+	cols, ok := cn.([]string)
+	if !ok {
+		return fmt.Errorf("invalid header type")
+	}
+	return t.w.Write(cols)
+}
+
+// AppendRow writes row into buffer
+func (t *CSVWriter) AppendRow(row []interface{}) error {
+	s := []string{}
+	for _, d := range row {
+		s = append(s, fmt.Sprint(d))
+	}
+	if e := t.w.Write(s); e != nil {
+		return e
+	}
+	if t.rows++; t.rows >= t.bufSize {
+		return t.Flush()
+	}
+	return nil
+}
+
+// FlushWithError flushes the buffer. It never writes the error message,
+// which would break the CSV, and leaves reporting it to the caller.
+func (t *CSVWriter) FlushWithError(e error) error {
+	return t.Flush()
+}
+
+// Flush the buffer
+func (t *CSVWriter) Flush() error {
+	t.w.Flush()
+	t.rows = 0
+	return t.w.Error()
+}
@@ -0,0 +1,45 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tablewriter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSVWriter(t *testing.T) {
+	a := assert.New(t)
+	b := new(bytes.Buffer)
+	table, e := Create("csv", 1, b)
+	a.NoError(e)
+	a.NoError(table.SetHeader(mockHead()))
+	for _, row := range mockRows() {
+		a.NoError(table.AppendRow(row))
+	}
+	a.NoError(table.AppendRow([]interface{}{"a,b", `say "hi"`}))
+	a.NoError(table.Flush())
+	a.Equal("col1,col2\n1,1.1\n2,2.1\n\"a,b\",\"say \"\"hi\"\"\"\n", b.String())
+
+	b.Reset()
+	table, e = Create("tsv", 1000, b)
+	a.NoError(e)
+	a.NoError(table.SetHeader(mockHead()))
+	for _, row := range mockRows() {
+		a.NoError(table.AppendRow(row))
+	}
+	a.NoError(table.FlushWithError(nil))
+	a.Equal("col1\tcol2\n1\t1.1\n2\t2.1\n", b.String())
+}
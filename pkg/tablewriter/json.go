@@ -0,0 +1,98 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tablewriter
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// JSONWriter writes table as JSON Lines, a JSON object of the columns
+// for each row
+type JSONWriter struct {
+	w       *bufio.Writer
+	cols    []string
+	bufSize int
+	rows    int
+}
+
+func createJSONWriter(bufSize int, w io.Writer) *JSONWriter {
+	return &JSONWriter{w: bufio.NewWriter(w), bufSize: bufSize}
+}
+
+// SetHeader set the keys of the objects
+func (t *JSONWriter) SetHeader(head map[string]interface{}) error {
+	cn, ok := head["columnNames"]
+	if !ok {
+		return fmt.Errorf("can't find field columnNames in head")
+	}
+	cols, ok := cn.([]string)
+	if !ok {
+		return fmt.Errorf("invalid header type")
+	}
+	t.cols = cols
+	return nil
+}
+
+// AppendRow writes row as an object, whose keys are in the order of the
+// columns, or as an array if there's no header
+func (t *JSONWriter) AppendRow(row []interface{}) error {
+	if len(t.cols) != len(row) {
+		b, e := json.Marshal(row)
+		if e != nil {
+			return e
+		}
+		return t.writeLine(b)
+	}
+	b := []byte{'{'}
+	for i, d := range row {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		k, e := json.Marshal(t.cols[i])
+		if e != nil {
+			return e
+		}
+		v, e := json.Marshal(d)
+		if e != nil {
+			return e
+		}
+		b = append(append(append(b, k...), ':'), v...)
+	}
+	return t.writeLine(append(b, '}'))
+}
+
+func (t *JSONWriter) writeLine(b []byte) error {
+	if _, e := t.w.Write(append(b, '\n')); e != nil {
+		return e
+	}
+	if t.rows++; t.rows >= t.bufSize {
+		return t.Flush()
+	}
+	return nil
+}
+
+// FlushWithError flushes the buffer. Like CSVWriter, it leaves reporting
+// the error to the caller.
+func (t *JSONWriter) FlushWithError(e error) error {
+	return t.Flush()
+}
+
+// Flush the buffer
+func (t *JSONWriter) Flush() error {
+	t.rows = 0
+	return t.w.Flush()
+}
@@ -0,0 +1,46 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tablewriter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONWriter(t *testing.T) {
+	a := assert.New(t)
+	b := new(bytes.Buffer)
+	table, e := Create("json", 1000, b)
+	a.NoError(e)
+	head := mockHead()
+	head["columnNames"] = []string{"col2", "col1"}
+	a.NoError(table.SetHeader(head))
+	for _, row := range mockRows() {
+		a.NoError(table.AppendRow(row))
+	}
+	a.NoError(table.AppendRow([]interface{}{"x"}))
+	a.NoError(table.Flush())
+	a.Equal(`{"col2":1,"col1":1.1}
+{"col2":2,"col1":2.1}
+["x"]
+`, b.String())
+
+	b.Reset()
+	table, e = Create("json", 1000, b)
+	a.NoError(e)
+	a.NoError(table.Flush())
+	a.Equal("", b.String())
+}
@@ -0,0 +1,115 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracking
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeMLflow records the requests to the MLflow REST API.
+type fakeMLflow struct {
+	experiments map[string]string
+	batches     []map[string]interface{}
+	update      map[string]interface{}
+	auth        string
+}
+
+func (f *fakeMLflow) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.auth = r.Header.Get("Authorization")
+	body := map[string]interface{}{}
+	if b, _ := ioutil.ReadAll(r.Body); len(b) > 0 {
+		json.Unmarshal(b, &body)
+	}
+	switch strings.TrimPrefix(r.URL.Path, "/api/2.0/mlflow/") {
+	case "experiments/get-by-name":
+		id, ok := f.experiments[r.URL.Query().Get("experiment_name")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error_code": "RESOURCE_DOES_NOT_EXIST", "message": "not found"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"experiment": {"experiment_id": "%s"}}`, id)
+	case "experiments/create":
+		id := fmt.Sprint(len(f.experiments) + 1)
+		f.experiments[body["name"].(string)] = id
+		fmt.Fprintf(w, `{"experiment_id": "%s"}`, id)
+	case "runs/create":
+		fmt.Fprintf(w, `{"run": {"info": {"run_id": "run-of-%s"}}}`, body["experiment_id"])
+	case "runs/log-batch":
+		f.batches = append(f.batches, body)
+		fmt.Fprint(w, `{}`)
+	case "runs/update":
+		f.update = body
+		fmt.Fprint(w, `{}`)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error_code": "INVALID_PARAMETER_VALUE", "message": "unknown method"}`)
+	}
+}
+
+func TestMLflowLogRun(t *testing.T) {
+	a := assert.New(t)
+	f := &fakeMLflow{experiments: map[string]string{}}
+	ts := httptest.NewServer(f)
+	defer ts.Close()
+
+	m := &MLflow{Endpoint: ts.URL, Experiment: "sqlflow", Token: "secret"}
+	metrics := []Metric{}
+	for i := 1; i <= 1200; i++ {
+		metrics = append(metrics, Metric{Key: "loss", Value: 1 / float64(i), Step: int64(i)})
+	}
+	run := &Run{
+		Name:      "sqlflow_models.my_model",
+		Params:    map[string]string{"estimator": "DNNClassifier", "train.epoch": "10"},
+		Metrics:   metrics,
+		Tags:      map[string]string{"sqlflow.model_uri": "sqlflow_models.my_model"},
+		StartTime: time.Unix(1000, 0),
+		EndTime:   time.Unix(2000, 0),
+	}
+	a.NoError(m.LogRun(context.Background(), run))
+	a.Equal(map[string]string{"sqlflow": "1"}, f.experiments)
+	a.Equal("Bearer secret", f.auth)
+	a.Len(f.batches, 2)
+	a.Equal("run-of-1", f.batches[0]["run_id"])
+	a.Len(f.batches[0]["params"], 2)
+	a.Len(f.batches[0]["tags"], 3)
+	a.Len(f.batches[0]["metrics"], 995)
+	a.Len(f.batches[1]["metrics"], 205)
+	a.Equal(StatusFinished, f.update["status"])
+	a.Equal(float64(2000000), f.update["end_time"])
+
+	// The experiment exists for the second run.
+	run.Status, run.Error, run.Metrics = StatusFailed, "out of memory", nil
+	f.batches = nil
+	a.NoError(m.LogRun(context.Background(), run))
+	a.Len(f.experiments, 1)
+	a.Len(f.batches, 1)
+	a.Len(f.batches[0]["tags"], 4)
+	a.Equal(StatusFailed, f.update["status"])
+
+	m.Endpoint = ts.URL + "/unknown"
+	e := m.LogRun(context.Background(), run)
+	a.Error(e)
+	a.Contains(e.Error(), "INVALID_PARAMETER_VALUE")
+}
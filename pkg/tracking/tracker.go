@@ -0,0 +1,103 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tracking logs the TRAIN runs of SQLFlow to the experiment
+// trackers, like MLflow, so the models trained by SQLFlow show up along
+// with the other experiments.
+package tracking
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The status of a Run.
+const (
+	StatusFinished = "FINISHED"
+	StatusFailed   = "FAILED"
+)
+
+// Metric is a value of a metric at a step, like the loss of an epoch.
+type Metric struct {
+	Key       string
+	Value     float64
+	Step      int64
+	Timestamp time.Time
+}
+
+// Run is a TRAIN statement.
+type Run struct {
+	// Name is the run name, like the model "sqlflow_models.my_model".
+	Name string
+	// Params are the estimator and the attributes of the WITH clause.
+	Params map[string]string
+	// Metrics are the metrics reported by the training program by epoch.
+	Metrics []Metric
+	// Tags are like {"sqlflow.model_uri": "sqlflow_models.my_model"}.
+	Tags      map[string]string
+	StartTime time.Time
+	EndTime   time.Time
+	Status    string
+	// Error is the error of the failed run, if any.
+	Error string
+}
+
+// Tracker logs the runs to an experiment tracker.
+type Tracker interface {
+	// LogRun logs a finished or failed run.
+	LogRun(ctx context.Context, run *Run) error
+}
+
+// Opener returns the Tracker of the URI like mlflow+http://host:5000.
+type Opener func(u *url.URL) (Tracker, error)
+
+var (
+	openersMu sync.RWMutex
+	openers   = map[string]Opener{}
+)
+
+// Register makes the trackers of the URIs name://... and name+scheme://...
+// opened by open. It replaces the Opener registered for name, if any.
+func Register(name string, open Opener) {
+	if name == "" || strings.ContainsAny(name, "+:/") {
+		panic(fmt.Sprintf("tracking: invalid tracker name %q", name))
+	}
+	if open == nil {
+		panic(fmt.Sprintf("tracking: register nil opener %q", name))
+	}
+	openersMu.Lock()
+	defer openersMu.Unlock()
+	openers[name] = open
+}
+
+// Open returns the Tracker of uri, like
+// mlflow+http://host:5000?experiment=sqlflow, by the Opener registered
+// for the scheme, or for the part of the scheme before "+".
+func Open(uri string) (Tracker, error) {
+	u, e := url.Parse(uri)
+	if e != nil {
+		return nil, fmt.Errorf("invalid experiment tracker %s: %v", uri, e)
+	}
+	name := strings.SplitN(u.Scheme, "+", 2)[0]
+	openersMu.RLock()
+	open, ok := openers[name]
+	openersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no experiment tracker is registered for %s", uri)
+	}
+	return open(u)
+}
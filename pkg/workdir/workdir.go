@@ -0,0 +1,272 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package workdir manages the working directories of the statements, where
+// the generated programs run and the models are loaded. The directories of
+// each session are in a directory named after the session under the root,
+// so the disk usage of a session is limited by a quota, and the directories
+// left behind by the killed jobs are removed after a TTL.
+package workdir
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"sqlflow.org/sqlflow/pkg/metrics"
+)
+
+const (
+	// dirPrefix is the prefix of the working directories, so Sweep leaves
+	// the other files in the root alone.
+	dirPrefix = "sqlflow_workdir_"
+	// anonymous is the session directory of the sessions without a user.
+	anonymous = "_anonymous"
+)
+
+// Manager creates and removes the working directories under Root.
+type Manager struct {
+	root  string
+	quota int64
+	ttl   time.Duration
+
+	mu     sync.Mutex
+	active map[string]bool // the paths of the directories in use
+}
+
+// New returns a Manager of the directories under root, or the system
+// temporary directory if root is "". quota is the maximum number of bytes
+// of the directories of a session, and ttl is how long Sweep keeps a
+// directory not in use after its last modification. Zero means
+// unlimited.
+func New(root string, quota int64, ttl time.Duration) *Manager {
+	if root == "" {
+		root = filepath.Join(os.TempDir(), "sqlflow_workdirs")
+	}
+	return &Manager{root: root, quota: quota, ttl: ttl, active: map[string]bool{}}
+}
+
+var (
+	defaultMu      sync.Mutex
+	defaultManager *Manager
+)
+
+// Default returns the Manager set by SetDefault, or a Manager in the system
+// temporary directory without the quota and the TTL.
+func Default() *Manager {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultManager == nil {
+		defaultManager = New("", 0, 0)
+	}
+	return defaultManager
+}
+
+// SetDefault sets the Manager returned by Default.
+func SetDefault(m *Manager) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultManager = m
+}
+
+// Root returns the directory of the working directories.
+func (m *Manager) Root() string {
+	return m.root
+}
+
+// Dir is a working directory created by Manager.Create.
+type Dir struct {
+	// Path is the path of the directory.
+	Path string
+	m    *Manager
+}
+
+// Create creates a working directory of session, which is usually the user
+// of the pb.Session. It fails if the directories of session already use
+// up the quota.
+func (m *Manager) Create(session string) (*Dir, error) {
+	sessionDir := filepath.Join(m.root, sessionName(session))
+	if m.quota > 0 {
+		used, _, e := usage(sessionDir)
+		if e != nil && !os.IsNotExist(e) {
+			return nil, e
+		}
+		if used >= m.quota {
+			metrics.WorkdirQuotaExceeded.Inc()
+			return nil, fmt.Errorf("the working directories of session %q use %d bytes, exceeding the quota of %d bytes, please retry after the running statements finish", session, used, m.quota)
+		}
+	}
+	if e := os.MkdirAll(sessionDir, 0755); e != nil {
+		return nil, e
+	}
+	p, e := ioutil.TempDir(sessionDir, dirPrefix)
+	if e != nil {
+		return nil, e
+	}
+	m.mu.Lock()
+	m.active[p] = true
+	m.mu.Unlock()
+	metrics.Workdirs.Inc()
+	return &Dir{Path: p, m: m}, nil
+}
+
+// Remove removes the directory and everything in it.
+func (d *Dir) Remove() error {
+	d.m.mu.Lock()
+	active := d.m.active[d.Path]
+	delete(d.m.active, d.Path)
+	d.m.mu.Unlock()
+	if active {
+		metrics.Workdirs.Dec()
+		metrics.WorkdirsRemoved.Inc("done")
+	}
+	return os.RemoveAll(d.Path)
+}
+
+// Usage returns the number of bytes of the files in the directories of
+// session.
+func (m *Manager) Usage(session string) (int64, error) {
+	n, _, e := usage(filepath.Join(m.root, sessionName(session)))
+	if os.IsNotExist(e) {
+		return 0, nil
+	}
+	return n, e
+}
+
+// Sweep removes the working directories not in use whose files were all
+// last modified before now minus the TTL, like those of the jobs killed
+// with the server, and the empty session directories. It returns the
+// number of the removed working directories, and updates the metric of
+// the disk usage. Sweep removes nothing if the TTL is zero.
+func (m *Manager) Sweep(now time.Time) (int, error) {
+	sessions, e := ioutil.ReadDir(m.root)
+	if os.IsNotExist(e) {
+		metrics.WorkdirBytes.Set(0)
+		return 0, nil
+	} else if e != nil {
+		return 0, e
+	}
+	removed, total := 0, int64(0)
+	for _, s := range sessions {
+		if !s.IsDir() {
+			continue
+		}
+		sessionDir := filepath.Join(m.root, s.Name())
+		dirs, e := ioutil.ReadDir(sessionDir)
+		if e != nil {
+			return removed, e
+		}
+		kept := 0
+		for _, d := range dirs {
+			if !d.IsDir() || !strings.HasPrefix(d.Name(), dirPrefix) {
+				kept++
+				continue
+			}
+			p := filepath.Join(sessionDir, d.Name())
+			n, modified, e := usage(p)
+			if os.IsNotExist(e) {
+				continue // removed by Dir.Remove in the meantime
+			} else if e != nil {
+				return removed, e
+			}
+			if m.expired(p, modified, now) {
+				if e := os.RemoveAll(p); e != nil {
+					return removed, e
+				}
+				metrics.WorkdirsRemoved.Inc("expired")
+				removed++
+				continue
+			}
+			total += n
+			kept++
+		}
+		if kept == 0 {
+			// os.Remove fails if Create has just created a directory in
+			// it, which is fine.
+			os.Remove(sessionDir)
+		}
+	}
+	metrics.WorkdirBytes.Set(float64(total))
+	return removed, nil
+}
+
+func (m *Manager) expired(p string, modified, now time.Time) bool {
+	if m.ttl <= 0 {
+		return false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return !m.active[p] && modified.Add(m.ttl).Before(now)
+}
+
+// Run calls Sweep every interval until stop is closed, and calls onError
+// with the errors of Sweep if it's not nil.
+func (m *Manager) Run(interval time.Duration, stop <-chan struct{}, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			if _, e := m.Sweep(now); e != nil && onError != nil {
+				onError(e)
+			}
+		}
+	}
+}
+
+// usage returns the number of bytes of the files in dir and the latest
+// modification time of dir and everything in it.
+func usage(dir string) (int64, time.Time, error) {
+	var n int64
+	var modified time.Time
+	e := filepath.Walk(dir, func(p string, fi os.FileInfo, e error) error {
+		if e != nil {
+			if p != dir && os.IsNotExist(e) {
+				return nil // removed while walking
+			}
+			return e
+		}
+		if fi.Mode().IsRegular() {
+			n += fi.Size()
+		}
+		if fi.ModTime().After(modified) {
+			modified = fi.ModTime()
+		}
+		return nil
+	})
+	return n, modified, e
+}
+
+// sessionName returns the name of the directory of session, which keeps
+// only the characters safe in file names.
+func sessionName(session string) string {
+	if session == "" {
+		return anonymous
+	}
+	name := []rune(session)
+	for i, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.' || c == '@') {
+			name[i] = '_'
+		}
+	}
+	if s := string(name); s != "." && s != ".." {
+		return s
+	}
+	return anonymous
+}
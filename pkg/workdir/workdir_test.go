@@ -0,0 +1,109 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workdir
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateAndRemove(t *testing.T) {
+	a := assert.New(t)
+	root, e := ioutil.TempDir("", "workdir_test")
+	a.NoError(e)
+	defer os.RemoveAll(root)
+
+	m := New(root, 10, 0)
+	d, e := m.Create("alice")
+	a.NoError(e)
+	a.Equal(filepath.Join(root, "alice"), filepath.Dir(d.Path))
+	a.NoError(ioutil.WriteFile(filepath.Join(d.Path, "model"), []byte("0123456789"), 0644))
+	n, e := m.Usage("alice")
+	a.NoError(e)
+	a.Equal(int64(10), n)
+
+	// The quota is per session.
+	_, e = m.Create("alice")
+	a.Error(e)
+	a.Contains(e.Error(), "exceeding the quota of 10 bytes")
+	bob, e := m.Create("bob")
+	a.NoError(e)
+	a.NoError(bob.Remove())
+
+	a.NoError(d.Remove())
+	_, e = os.Stat(d.Path)
+	a.True(os.IsNotExist(e))
+	n, e = m.Usage("alice")
+	a.NoError(e)
+	a.Equal(int64(0), n)
+	d, e = m.Create("alice")
+	a.NoError(e)
+	a.NoError(d.Remove())
+}
+
+func TestSweep(t *testing.T) {
+	a := assert.New(t)
+	root, e := ioutil.TempDir("", "workdir_test")
+	a.NoError(e)
+	defer os.RemoveAll(root)
+
+	m := New(root, 0, time.Hour)
+	inUse, e := m.Create("")
+	a.NoError(e)
+	a.Equal(filepath.Join(root, anonymous), filepath.Dir(inUse.Path))
+	a.NoError(ioutil.WriteFile(filepath.Join(inUse.Path, "a"), []byte("abc"), 0644))
+
+	// A working directory left behind by another server process.
+	orphan, e := New(root, 0, time.Hour).Create("alice")
+	a.NoError(e)
+	a.NoError(ioutil.WriteFile(filepath.Join(orphan.Path, "b"), []byte("abcd"), 0644))
+	other := filepath.Join(root, "alice", "other")
+	a.NoError(ioutil.WriteFile(other, nil, 0644))
+
+	n, e := m.Sweep(time.Now())
+	a.NoError(e)
+	a.Equal(0, n)
+
+	n, e = m.Sweep(time.Now().Add(2 * time.Hour))
+	a.NoError(e)
+	a.Equal(1, n)
+	_, e = os.Stat(orphan.Path)
+	a.True(os.IsNotExist(e))
+	_, e = os.Stat(other)
+	a.NoError(e)
+	_, e = os.Stat(inUse.Path)
+	a.NoError(e)
+
+	a.NoError(inUse.Remove())
+	a.NoError(os.Remove(other))
+	n, e = m.Sweep(time.Now().Add(2 * time.Hour))
+	a.NoError(e)
+	a.Equal(0, n)
+	fis, e := ioutil.ReadDir(root)
+	a.NoError(e)
+	a.Empty(fis)
+}
+
+func TestSessionName(t *testing.T) {
+	a := assert.New(t)
+	a.Equal(anonymous, sessionName(""))
+	a.Equal(anonymous, sessionName(".."))
+	a.Equal("alice@example.com", sessionName("alice@example.com"))
+	a.Equal(".._etc_passwd", sessionName("../etc/passwd"))
+}
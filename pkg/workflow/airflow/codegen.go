@@ -0,0 +1,85 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package airflow
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"sqlflow.org/sqlflow/pkg/ir"
+	"sqlflow.org/sqlflow/pkg/log"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+	"sqlflow.org/sqlflow/pkg/workflow/couler"
+)
+
+var defaultNamespace = "default"
+
+// Codegen generates Airflow DAG
+type Codegen struct{}
+
+func stepTaskID(idx int) string {
+	return fmt.Sprintf("step_%d", idx)
+}
+
+// GenFiller generates Filler to fill the template, whose steps are the
+// ones of the Couler program of the same SQL program.
+func GenFiller(programIR []ir.SQLFlowStmt, session *pb.Session) (*Filler, error) {
+	cf, e := couler.GenFiller(programIR, session)
+	if e != nil {
+		return nil, e
+	}
+	r := &Filler{
+		DAGID:      "sqlflow-" + log.UUID(),
+		Namespace:  defaultNamespace,
+		StepEnvs:   cf.StepEnvs,
+		SecretName: cf.SecretName,
+		Resources:  cf.Resources,
+	}
+	if ns := os.Getenv("SQLFLOW_WORKFLOW_AIRFLOW_NAMESPACE"); ns != "" {
+		r.Namespace = ns
+	}
+	for i, s := range cf.SQLStatements {
+		if s.IsTFJob || s.IsKatibTrain {
+			return nil, fmt.Errorf("the airflow backend does not support the TFJob or Katib step of: %s", s.OriginalSQL)
+		}
+		r.Steps = append(r.Steps, &step{
+			TaskID:      stepTaskID(i + 1),
+			OriginalSQL: s.OriginalSQL,
+			DockerImage: s.DockerImage,
+			Resources:   s.Resources,
+		})
+	}
+	return r, nil
+}
+
+// GenCode generates an Airflow DAG, which runs each step in a Kubernetes
+// Pod by the KubernetesPodOperator, one after another.
+func (cg *Codegen) GenCode(programIR []ir.SQLFlowStmt, session *pb.Session) (string, error) {
+	r, e := GenFiller(programIR, session)
+	if e != nil {
+		return "", e
+	}
+	var program bytes.Buffer
+	if e := dagTemplate.Execute(&program, r); e != nil {
+		return "", e
+	}
+	return program.String(), nil
+}
+
+// GenYAML returns the DAG as is, since Airflow loads the DAGs from the
+// Python programs in its DAG folder.
+func (cg *Codegen) GenYAML(dag string) (string, error) {
+	return dag, nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package airflow
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sqlflow.org/sqlflow/pkg/ir"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+	"sqlflow.org/sqlflow/pkg/workflow/couler"
+)
+
+func TestAirflowCodegen(t *testing.T) {
+	a := assert.New(t)
+	os.Setenv("SQLFLOW_OSS_AK", "oss_key")
+	os.Setenv("SQLFLOW_WORKFLOW_SECRET", `{"sqlflow-secret":{"oss_sk": "oss_sk"}}`)
+	os.Setenv("SQLFLOW_WORKFLOW_AIRFLOW_NAMESPACE", "sqlflow")
+	defer os.Unsetenv("SQLFLOW_OSS_AK")
+	defer os.Unsetenv("SQLFLOW_WORKFLOW_SECRET")
+	defer os.Unsetenv("SQLFLOW_WORKFLOW_AIRFLOW_NAMESPACE")
+	cg := &Codegen{}
+	code, e := cg.GenCode(couler.MockSQLProgramIR(), &pb.Session{})
+	a.NoError(e)
+
+	m := dagIDRegex.FindStringSubmatch(code)
+	a.NotNil(m)
+	a.True(strings.HasPrefix(m[1], "sqlflow-"))
+	a.Contains(code, `schedule_interval="@once"`)
+	a.Contains(code, `step_envs["SQLFLOW_OSS_AK"] = '''oss_key'''`)
+	a.NotContains(code, `step_envs["SQLFLOW_WORKFLOW_SECRET"]`)
+	a.Contains(code, `secrets.append(Secret("env", None, "sqlflow-secret"))`)
+	a.Contains(code, `namespace="sqlflow"`)
+	a.Contains(code, `arguments=["-e", '''SELECT * FROM iris.train limit 10;''']`)
+	a.Contains(code, `task_id="step_1"`)
+	a.Contains(code, `task_id="step_2"`)
+	a.Equal(2, strings.Count(code, "KubernetesPodOperator(task_id="))
+
+	// Airflow loads the DAG as is
+	dag, e := cg.GenYAML(code)
+	a.NoError(e)
+	a.Equal(code, dag)
+
+	// each DAG has a unique ID
+	code2, e := cg.GenCode(couler.MockSQLProgramIR(), &pb.Session{})
+	a.NoError(e)
+	a.NotEqual(m[1], dagIDRegex.FindStringSubmatch(code2)[1])
+}
+
+func TestAirflowCodegenResources(t *testing.T) {
+	a := assert.New(t)
+	os.Setenv("SQLFLOW_WORKFLOW_RESOURCES", `{"memory": "32Mi", "cpu": "100m"}`)
+	defer os.Unsetenv("SQLFLOW_WORKFLOW_RESOURCES")
+	trainStmt := ir.MockTrainStmt(false)
+	trainStmt.Resources = ir.Resources{GPU: 1, Memory: "8Gi"}
+	predStmt := ir.MockPredStmt(trainStmt)
+	cg := &Codegen{}
+	code, e := cg.GenCode([]ir.SQLFlowStmt{trainStmt, predStmt}, &pb.Session{})
+	a.NoError(e)
+	a.Contains(code, `resources = json.loads('''{"memory": "32Mi", "cpu": "100m"}''')`)
+	a.Contains(code, `step_resources = json.loads('''{"cpu":"100m","memory":"8Gi","nvidia.com/gpu":"1"}''')`)
+	a.Equal(2, strings.Count(code, "resources=requirements(step_resources)"))
+}
+
+func TestAirflowCodegenTFJob(t *testing.T) {
+	a := assert.New(t)
+	trainStmt := ir.MockTrainStmt(false)
+	trainStmt.Attributes["train.num_workers"] = 3
+	cg := &Codegen{}
+	_, e := cg.GenCode([]ir.SQLFlowStmt{trainStmt}, &pb.Session{})
+	a.Error(e)
+}
@@ -0,0 +1,164 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package airflow
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"sqlflow.org/sqlflow/pkg/log"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+	wfrsp "sqlflow.org/sqlflow/pkg/workflow/response"
+)
+
+// logPrefixRegex matches the prefix of the task logs, like
+// "[2020-08-01 10:00:00,000] {pod_launcher.py:173} INFO - ".
+var logPrefixRegex = regexp.MustCompile(`^\[[^\]]*\] \{[^}]*\} [A-Z]+ - `)
+
+func logViewURL(dagID, taskID, executionDate string) string {
+	ep := os.Getenv("SQLFLOW_WORKFLOW_LOGVIEW_ENDPOINT")
+	// Airflow UI log view page
+	return fmt.Sprintf("%s/log?dag_id=%s&task_id=%s&execution_date=%s", ep,
+		url.QueryEscape(dagID), url.QueryEscape(taskID), url.QueryEscape(executionDate))
+}
+
+// stepLogs returns the non-empty outputs of the step in the task logs.
+func stepLogs(logs []string) []string {
+	out := []string{}
+	for _, l := range logs {
+		if l = logPrefixRegex.ReplaceAllString(l, ""); strings.TrimSpace(l) != "" {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// Fetch fetches the step logs and status of a DAG run like the Argo
+// workflow, where the step ID is the task ID and the step phase is the
+// phase of the task instance.
+func (w *Workflow) Fetch(req *pb.FetchRequest) (*pb.FetchResponse, error) {
+	logger := log.WithFields(log.Fields{
+		"requestID": log.UUID(),
+		"jobID":     req.Job.Id,
+		"stepID":    req.StepId,
+		"event":     "fetch",
+	})
+	dagID := req.Job.Id
+	run, e := getDAGRun(dagID)
+	if e != nil {
+		logger.Errorf("workflowFailed/getDAGRun, error: %v", e)
+		return nil, e
+	}
+	if run == nil {
+		return wfrsp.New().Response(dagID, "", "", false), nil
+	}
+	tis, e := getTaskInstances(dagID, run.ID)
+	if e != nil {
+		return nil, e
+	}
+	taskID := req.StepId
+	if taskID == "" {
+		taskID = stepTaskID(1)
+	}
+	stepIdx, e := stepIndex(taskID)
+	if e != nil {
+		return nil, e
+	}
+	var ti *taskInstance
+	for _, t := range tis {
+		if t.TaskID == taskID {
+			ti = t
+		}
+	}
+	if ti == nil {
+		return nil, fmt.Errorf("the step %s of the DAG %s does not exist", taskID, dagID)
+	}
+	stepCnt := len(tis)
+	logPrefix := fmt.Sprintf("SQLFlow Step: [%d/%d]", stepIdx, stepCnt)
+	logURL := logViewURL(dagID, taskID, ti.ExecutionDate)
+
+	r := wfrsp.New()
+	if req.StepPhase == "" {
+		r.AppendMessage(fmt.Sprintf("%s Log: %s", logPrefix, logURL))
+	}
+	phase := taskPhase(ti.State)
+	if req.StepPhase != phase {
+		r.AppendMessage(fmt.Sprintf("%s Status: %s", logPrefix, phase))
+	}
+	if phase != phaseSucceeded && phase != phaseFailed {
+		return r.Response(dagID, taskID, phase, false), nil
+	}
+
+	logs, e := getTaskLogs(dagID, run.ID, ti)
+	if e != nil {
+		return nil, e
+	}
+	if e := r.AppendProtoMessages(stepLogs(logs)); e != nil {
+		return nil, e
+	}
+	if phase == phaseFailed {
+		logger.Errorf("workflowFailed, step:%s", taskID)
+		return r.ResponseWithStepComplete(dagID, taskID, phase, false),
+			fmt.Errorf("%s Failed, Log: %s\n%s", logPrefix, logURL, r.ErrorMessage())
+	}
+	if stepIdx == stepCnt {
+		logger.Infof("workflowSucceed")
+		return r.ResponseWithStepComplete(dagID, taskID, phase, true), nil
+	}
+	// move to the next step
+	return r.ResponseWithStepComplete(dagID, stepTaskID(stepIdx+1), "", false), nil
+}
+
+// StepLogs returns the outputs of the step-th step in its task logs
+// after the first offset lines, or none if offset < 0, and the phase of
+// its task instance read before the logs, which is "" if the task
+// instance hasn't been scheduled.
+func (w *Workflow) StepLogs(workflowID string, step, offset int) ([]string, string, error) {
+	run, e := getDAGRun(workflowID)
+	if e != nil || run == nil {
+		return nil, "", e
+	}
+	tis, e := getTaskInstances(workflowID, run.ID)
+	if e != nil {
+		return nil, "", e
+	}
+	taskID := stepTaskID(step)
+	var ti *taskInstance
+	for _, t := range tis {
+		if t.TaskID == taskID {
+			ti = t
+		}
+	}
+	if ti == nil {
+		return nil, "", fmt.Errorf("the step %s of the DAG %s does not exist", taskID, workflowID)
+	}
+	if ti.State == "" {
+		return nil, "", nil
+	}
+	phase := taskPhase(ti.State)
+	if offset < 0 || phase == phasePending {
+		return nil, phase, nil
+	}
+	logs, e := getTaskLogs(workflowID, run.ID, ti)
+	if e != nil {
+		return nil, "", e
+	}
+	if lines := stepLogs(logs); offset < len(lines) {
+		return lines[offset:], phase, nil
+	}
+	return nil, phase, nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package airflow
+
+import "text/template"
+
+type step struct {
+	TaskID      string
+	OriginalSQL string
+	DockerImage string
+	// Resources is the resources of the step in JSON, "" means the ones
+	// of Filler.Resources.
+	Resources string
+}
+
+// Filler is used to fill the template
+type Filler struct {
+	DAGID      string
+	Namespace  string
+	StepEnvs   map[string]string
+	SecretName string
+	Resources  string
+	Steps      []*step
+}
+
+const dagTemplateText = `
+import json
+
+from airflow import DAG
+from airflow.kubernetes.secret import Secret
+from airflow.providers.cncf.kubernetes.operators.kubernetes_pod import KubernetesPodOperator
+from airflow.utils.dates import days_ago
+from kubernetes.client import models as k8s
+
+step_envs = dict()
+{{range $k, $v := .StepEnvs}}
+step_envs["{{$k}}"] = '''{{$v}}'''
+{{end}}
+
+# note: the secret should be created in the namespace before submitting the DAG,
+# all of its keys are the environment variables of the steps.
+secrets = []
+if "{{.SecretName}}" != "":
+    secrets.append(Secret("env", None, "{{.SecretName}}"))
+
+resources = None
+if '''{{.Resources}}''' != "":
+    resources = json.loads('''{{.Resources}}''')
+
+
+def requirements(r):
+    if r is None:
+        return None
+    return k8s.V1ResourceRequirements(requests=r, limits=r)
+
+
+dag = DAG(dag_id="{{.DAGID}}",
+          schedule_interval="@once",
+          start_date=days_ago(1),
+          catchup=False,
+          is_paused_upon_creation=False,
+          tags=["sqlflow"])
+
+previous = None
+{{range $s := .Steps}}
+step_resources = resources
+{{if $s.Resources}}step_resources = json.loads('''{{$s.Resources}}'''){{end}}
+step = KubernetesPodOperator(task_id="{{$s.TaskID}}",
+                             name="sqlflow-step",
+                             namespace="{{$.Namespace}}",
+                             image="{{$s.DockerImage}}",
+                             cmds=["step"],
+                             arguments=["-e", '''{{$s.OriginalSQL}}'''],
+                             env_vars=step_envs,
+                             secrets=secrets,
+                             resources=requirements(step_resources),
+                             get_logs=True,
+                             is_delete_operator_pod=True,
+                             dag=dag)
+if previous is not None:
+    previous >> step
+previous = step
+{{end}}
+`
+
+var dagTemplate = template.Must(template.New("DAG").Parse(dagTemplateText))
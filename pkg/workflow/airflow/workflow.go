@@ -0,0 +1,278 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package airflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Workflow operates the Airflow DAGs by the stable REST API of Airflow 2.
+//
+// Submit writes the DAG into the DAG folder SQLFLOW_WORKFLOW_AIRFLOW_DAGS_DIR
+// shared with the Airflow scheduler, which runs the DAG once after loading
+// it. The others call the REST API at SQLFLOW_WORKFLOW_AIRFLOW_ENDPOINT
+// with the basic authentication of SQLFLOW_WORKFLOW_AIRFLOW_USER and
+// SQLFLOW_WORKFLOW_AIRFLOW_PASSWORD.
+type Workflow struct{}
+
+var dagIDRegex = regexp.MustCompile(`(?m)^dag = DAG\(dag_id="([^"]+)"`)
+
+// the phases of the workflows and the steps, the same as the Argo ones.
+const (
+	phasePending   = "Pending"
+	phaseRunning   = "Running"
+	phaseSucceeded = "Succeeded"
+	phaseFailed    = "Failed"
+)
+
+type dagRun struct {
+	ID            string `json:"dag_run_id"`
+	State         string `json:"state"`
+	ExecutionDate string `json:"execution_date"`
+}
+
+type taskInstance struct {
+	TaskID        string `json:"task_id"`
+	State         string `json:"state"`
+	TryNumber     int    `json:"try_number"`
+	ExecutionDate string `json:"execution_date"`
+}
+
+func dagsDir() (string, error) {
+	dir := os.Getenv("SQLFLOW_WORKFLOW_AIRFLOW_DAGS_DIR")
+	if dir == "" {
+		return "", fmt.Errorf("should set SQLFLOW_WORKFLOW_AIRFLOW_DAGS_DIR for the airflow backend")
+	}
+	return dir, nil
+}
+
+// request sends a request to the Airflow REST API, and decodes the JSON
+// response into out if it is not nil. It returns the status code of the
+// response, and an error if the code is neither 2xx nor 404.
+func request(method, path string, in, out interface{}) (int, error) {
+	ep := os.Getenv("SQLFLOW_WORKFLOW_AIRFLOW_ENDPOINT")
+	if ep == "" {
+		return 0, fmt.Errorf("should set SQLFLOW_WORKFLOW_AIRFLOW_ENDPOINT for the airflow backend")
+	}
+	var body bytes.Buffer
+	if in != nil {
+		if e := json.NewEncoder(&body).Encode(in); e != nil {
+			return 0, e
+		}
+	}
+	req, e := http.NewRequest(method, strings.TrimRight(ep, "/")+"/api/v1"+path, &body)
+	if e != nil {
+		return 0, e
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if _, ok := out.(*string); ok {
+		req.Header.Set("Accept", "text/plain")
+	} else {
+		req.Header.Set("Accept", "application/json")
+	}
+	if user := os.Getenv("SQLFLOW_WORKFLOW_AIRFLOW_USER"); user != "" {
+		req.SetBasicAuth(user, os.Getenv("SQLFLOW_WORKFLOW_AIRFLOW_PASSWORD"))
+	}
+	resp, e := http.DefaultClient.Do(req)
+	if e != nil {
+		return 0, e
+	}
+	defer resp.Body.Close()
+	b, e := ioutil.ReadAll(resp.Body)
+	if e != nil {
+		return resp.StatusCode, e
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return resp.StatusCode, nil
+	}
+	if resp.StatusCode/100 != 2 {
+		return resp.StatusCode, fmt.Errorf("%s %s: %s %s", method, path, resp.Status, b)
+	}
+	switch o := out.(type) {
+	case nil:
+	case *string:
+		*o = string(b)
+	default:
+		if e := json.Unmarshal(b, out); e != nil {
+			return resp.StatusCode, fmt.Errorf("%s %s: %v", method, path, e)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// getDAGRun returns the run of the DAG, or nil if the scheduler has not
+// loaded the DAG or created the run yet.
+func getDAGRun(dagID string) (*dagRun, error) {
+	runs := struct {
+		DAGRuns []*dagRun `json:"dag_runs"`
+	}{}
+	code, e := request("GET", fmt.Sprintf("/dags/%s/dagRuns", url.PathEscape(dagID)), nil, &runs)
+	if e != nil || code == http.StatusNotFound || len(runs.DAGRuns) == 0 {
+		return nil, e
+	}
+	return runs.DAGRuns[0], nil
+}
+
+func getTaskInstances(dagID, runID string) ([]*taskInstance, error) {
+	tis := struct {
+		TaskInstances []*taskInstance `json:"task_instances"`
+	}{}
+	code, e := request("GET", fmt.Sprintf("/dags/%s/dagRuns/%s/taskInstances", url.PathEscape(dagID), url.PathEscape(runID)), nil, &tis)
+	if e != nil {
+		return nil, e
+	}
+	if code == http.StatusNotFound {
+		return nil, fmt.Errorf("the run %s of the DAG %s does not exist", runID, dagID)
+	}
+	return tis.TaskInstances, nil
+}
+
+func getTaskLogs(dagID, runID string, ti *taskInstance) ([]string, error) {
+	// the try number of a finished task instance may be the next try in
+	// some versions of Airflow, read the logs of the last existing one.
+	for try := ti.TryNumber; try >= 1; try-- {
+		logs := ""
+		code, e := request("GET", fmt.Sprintf("/dags/%s/dagRuns/%s/taskInstances/%s/logs/%d?full_content=true",
+			url.PathEscape(dagID), url.PathEscape(runID), url.PathEscape(ti.TaskID), try), nil, &logs)
+		if e != nil {
+			return nil, e
+		}
+		if code != http.StatusNotFound {
+			return strings.Split(logs, "\n"), nil
+		}
+	}
+	return nil, nil
+}
+
+// dagRunPhase returns the phase of the state of a DAG run.
+func dagRunPhase(state string) string {
+	switch state {
+	case "running":
+		return phaseRunning
+	case "success":
+		return phaseSucceeded
+	case "failed":
+		return phaseFailed
+	}
+	return phasePending
+}
+
+// taskPhase returns the phase of the state of a task instance.
+func taskPhase(state string) string {
+	switch state {
+	case "running":
+		return phaseRunning
+	case "success", "skipped":
+		return phaseSucceeded
+	case "failed", "upstream_failed", "removed", "shutdown":
+		return phaseFailed
+	}
+	// no status, scheduled, queued, up_for_retry and up_for_reschedule
+	return phasePending
+}
+
+// stepIndex returns the 1-based index of a step from its task ID.
+func stepIndex(taskID string) (int, error) {
+	idx, e := strconv.Atoi(strings.TrimPrefix(taskID, "step_"))
+	if e != nil || !strings.HasPrefix(taskID, "step_") {
+		return 0, fmt.Errorf("unrecognized SQLFlow step: %s", taskID)
+	}
+	return idx, nil
+}
+
+// Submit writes the Airflow DAG into the DAG folder and returns the DAG ID
+// as the workflow ID.
+func (w *Workflow) Submit(dag string) (string, error) {
+	m := dagIDRegex.FindStringSubmatch(dag)
+	if m == nil {
+		return "", fmt.Errorf("no DAG ID in the Airflow DAG")
+	}
+	dir, e := dagsDir()
+	if e != nil {
+		return "", e
+	}
+	// write a file whose name is not ending with .py, and rename it to
+	// avoid the scheduler loading an incomplete DAG.
+	path := filepath.Join(dir, m[1]+".py")
+	if e := ioutil.WriteFile(path+".tmp", []byte(dag), 0644); e != nil {
+		return "", e
+	}
+	if e := os.Rename(path+".tmp", path); e != nil {
+		os.Remove(path + ".tmp")
+		return "", e
+	}
+	return m[1], nil
+}
+
+// Status returns the phase of the DAG run, one of Pending, Running,
+// Succeeded and Failed, and the failed steps as the message.
+func (w *Workflow) Status(workflowID string) (string, string, error) {
+	run, e := getDAGRun(workflowID)
+	if e != nil {
+		return "", "", e
+	}
+	if run == nil {
+		return phasePending, "", nil
+	}
+	phase := dagRunPhase(run.State)
+	if phase != phaseFailed {
+		return phase, "", nil
+	}
+	tis, e := getTaskInstances(workflowID, run.ID)
+	if e != nil {
+		return "", "", e
+	}
+	failed := []string{}
+	for _, ti := range tis {
+		if ti.State == "failed" {
+			failed = append(failed, ti.TaskID)
+		}
+	}
+	if len(failed) == 0 {
+		return phase, "", nil
+	}
+	return phase, fmt.Sprintf("failed steps: %s", strings.Join(failed, ", ")), nil
+}
+
+// Terminate removes the DAG from the DAG folder, pauses it and fails its
+// run, which stops the running step and skips the rest.
+func (w *Workflow) Terminate(workflowID string) error {
+	dir, e := dagsDir()
+	if e != nil {
+		return e
+	}
+	if e := os.Remove(filepath.Join(dir, workflowID+".py")); e != nil && !os.IsNotExist(e) {
+		return e
+	}
+	dag := fmt.Sprintf("/dags/%s", url.PathEscape(workflowID))
+	if _, e := request("PATCH", dag+"?update_mask=is_paused", map[string]interface{}{"is_paused": true}, nil); e != nil {
+		return e
+	}
+	run, e := getDAGRun(workflowID)
+	if e != nil || run == nil || dagRunPhase(run.State) == phaseSucceeded || dagRunPhase(run.State) == phaseFailed {
+		return e
+	}
+	_, e = request("PATCH", fmt.Sprintf("%s/dagRuns/%s", dag, url.PathEscape(run.ID)), map[string]interface{}{"state": "failed"}, nil)
+	return e
+}
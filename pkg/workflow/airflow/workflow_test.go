@@ -0,0 +1,232 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package airflow
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+	wfrsp "sqlflow.org/sqlflow/pkg/workflow/response"
+)
+
+// fakeAirflow serves the REST API of a DAG run of dagID with two steps.
+type fakeAirflow struct {
+	mu       sync.Mutex
+	dagID    string
+	started  bool
+	runState string
+	states   map[string]string
+	logs     map[string]string
+	paused   bool
+}
+
+func (f *fakeAirflow) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	dag := "/api/v1/dags/" + f.dagID
+	if !strings.HasPrefix(r.URL.Path, dag) || !f.started {
+		http.NotFound(w, r)
+		return
+	}
+	switch p := strings.TrimPrefix(r.URL.Path, dag); {
+	case p == "" && r.Method == "PATCH":
+		f.paused = true
+		w.Write([]byte(`{}`))
+	case p == "/dagRuns" && r.Method == "GET":
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"dag_runs": []interface{}{map[string]string{"dag_run_id": "run1", "state": f.runState, "execution_date": "2020-08-01T00:00:00+00:00"}},
+		})
+	case p == "/dagRuns/run1" && r.Method == "PATCH":
+		f.runState = "failed"
+		w.Write([]byte(`{}`))
+	case p == "/dagRuns/run1/taskInstances":
+		tis := []interface{}{}
+		for _, id := range []string{"step_1", "step_2"} {
+			tis = append(tis, map[string]interface{}{"task_id": id, "state": f.states[id], "try_number": 2, "execution_date": "2020-08-01T00:00:00+00:00"})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"task_instances": tis})
+	case strings.HasPrefix(p, "/dagRuns/run1/taskInstances/") && strings.HasSuffix(p, "/logs/1"):
+		a := r.Header.Get("Accept")
+		if a != "text/plain" {
+			http.Error(w, a, http.StatusNotAcceptable)
+			return
+		}
+		w.Write([]byte(f.logs[strings.Split(p, "/")[4]]))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeAirflow) set(fn func()) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fn()
+}
+
+func TestAirflowWorkflow(t *testing.T) {
+	a := assert.New(t)
+	dir, e := ioutil.TempDir("", "sqlflow-dags")
+	a.NoError(e)
+	defer os.RemoveAll(dir)
+	f := &fakeAirflow{
+		states: map[string]string{},
+		logs: map[string]string{
+			"step_1": "*** Reading local file\n[2020-08-01 10:00:00,000] {pod_launcher.py:173} INFO - head: {column_names: \"1\"}\n",
+		},
+	}
+	ts := httptest.NewServer(f)
+	defer ts.Close()
+	os.Setenv("SQLFLOW_WORKFLOW_AIRFLOW_ENDPOINT", ts.URL)
+	os.Setenv("SQLFLOW_WORKFLOW_AIRFLOW_DAGS_DIR", dir)
+	os.Setenv("SQLFLOW_WORKFLOW_LOGVIEW_ENDPOINT", "http://localhost:8080")
+	defer os.Unsetenv("SQLFLOW_WORKFLOW_AIRFLOW_ENDPOINT")
+	defer os.Unsetenv("SQLFLOW_WORKFLOW_AIRFLOW_DAGS_DIR")
+	defer os.Unsetenv("SQLFLOW_WORKFLOW_LOGVIEW_ENDPOINT")
+
+	wf := &Workflow{}
+	_, e = wf.Submit("print(1)")
+	a.Error(e)
+	dag := "import airflow\n" + `dag = DAG(dag_id="sqlflow-abc",` + "\n"
+	id, e := wf.Submit(dag)
+	a.NoError(e)
+	a.Equal("sqlflow-abc", id)
+	b, e := ioutil.ReadFile(filepath.Join(dir, "sqlflow-abc.py"))
+	a.NoError(e)
+	a.Equal(dag, string(b))
+	f.set(func() { f.dagID = id })
+
+	// pending before the scheduler loads the DAG
+	phase, _, e := wf.Status(id)
+	a.NoError(e)
+	a.Equal(phasePending, phase)
+	resp, e := wf.Fetch(wfrsp.NewFetchRequest(id, "", ""))
+	a.NoError(e)
+	a.False(resp.Eof)
+	a.Equal("", resp.UpdatedFetchSince.StepId)
+
+	f.set(func() { f.started, f.runState, f.states["step_1"] = true, "running", "running" })
+	phase, _, e = wf.Status(id)
+	a.NoError(e)
+	a.Equal(phaseRunning, phase)
+	resp, e = wf.Fetch(resp.UpdatedFetchSince)
+	a.NoError(e)
+	a.Equal("step_1", resp.UpdatedFetchSince.StepId)
+	a.Equal(phaseRunning, resp.UpdatedFetchSince.StepPhase)
+	msgs := []string{}
+	for _, r := range resp.Responses.Response {
+		msgs = append(msgs, r.GetMessage().GetMessage())
+	}
+	a.Equal([]string{
+		"SQLFlow Step: [1/2] Log: http://localhost:8080/log?dag_id=sqlflow-abc&task_id=step_1&execution_date=2020-08-01T00%3A00%3A00%2B00%3A00",
+		"SQLFlow Step: [1/2] Status: Running"}, msgs)
+
+	// move to the next step after the first one succeeded
+	f.set(func() { f.states["step_1"] = "success" })
+	resp, e = wf.Fetch(resp.UpdatedFetchSince)
+	a.NoError(e)
+	a.False(resp.Eof)
+	a.Equal("step_2", resp.UpdatedFetchSince.StepId)
+	a.Equal("", resp.UpdatedFetchSince.StepPhase)
+	rs := resp.Responses.Response
+	a.Equal(3, len(rs))
+	a.Equal("SQLFlow Step: [1/2] Status: Succeeded", rs[0].GetMessage().GetMessage())
+	a.Equal([]string{"1"}, rs[1].GetHead().GetColumnNames())
+	a.NotNil(rs[2].GetEoe())
+
+	f.set(func() { f.states["step_2"] = "running" })
+	resp, e = wf.Fetch(resp.UpdatedFetchSince)
+	a.NoError(e)
+	a.Equal("step_2", resp.UpdatedFetchSince.StepId)
+
+	// terminate the run and fail the running step
+	a.NoError(wf.Terminate(id))
+	_, e = os.Stat(filepath.Join(dir, "sqlflow-abc.py"))
+	a.True(os.IsNotExist(e))
+	a.True(f.paused)
+	f.set(func() { f.states["step_2"] = "failed" })
+	phase, msg, e := wf.Status(id)
+	a.NoError(e)
+	a.Equal(phaseFailed, phase)
+	a.Equal("failed steps: step_2", msg)
+	resp, e = wf.Fetch(resp.UpdatedFetchSince)
+	a.Error(e)
+	a.Contains(e.Error(), "SQLFlow Step: [2/2] Failed")
+	a.False(resp.Eof)
+}
+
+func TestAirflowFetchEOF(t *testing.T) {
+	a := assert.New(t)
+	f := &fakeAirflow{dagID: "sqlflow-abc", started: true, runState: "success",
+		states: map[string]string{"step_1": "success", "step_2": "success"},
+		logs:   map[string]string{}}
+	ts := httptest.NewServer(f)
+	defer ts.Close()
+	os.Setenv("SQLFLOW_WORKFLOW_AIRFLOW_ENDPOINT", ts.URL)
+	defer os.Unsetenv("SQLFLOW_WORKFLOW_AIRFLOW_ENDPOINT")
+
+	wf := &Workflow{}
+	resp, e := wf.Fetch(&pb.FetchRequest{Job: &pb.Job{Id: "sqlflow-abc"}, StepId: "step_2", StepPhase: phaseRunning})
+	a.NoError(e)
+	a.True(resp.Eof)
+	_, e = wf.Fetch(&pb.FetchRequest{Job: &pb.Job{Id: "sqlflow-abc"}, StepId: "step_3"})
+	a.Error(e)
+}
+
+func TestAirflowStepLogs(t *testing.T) {
+	a := assert.New(t)
+	f := &fakeAirflow{
+		dagID:  "sqlflow-abc",
+		states: map[string]string{},
+		logs: map[string]string{
+			"step_1": "*** Reading local file\n[2020-08-01 10:00:00,000] {pod_launcher.py:173} INFO - epoch 1\n[2020-08-01 10:00:01,000] {pod_launcher.py:173} INFO - epoch 2\n",
+		},
+	}
+	ts := httptest.NewServer(f)
+	defer ts.Close()
+	os.Setenv("SQLFLOW_WORKFLOW_AIRFLOW_ENDPOINT", ts.URL)
+	defer os.Unsetenv("SQLFLOW_WORKFLOW_AIRFLOW_ENDPOINT")
+
+	wf := &Workflow{}
+	// no step has started before the scheduler loads the DAG
+	logs, phase, e := wf.StepLogs("sqlflow-abc", 1, 0)
+	a.NoError(e)
+	a.Equal("", phase)
+	a.Equal(0, len(logs))
+
+	f.set(func() { f.started, f.runState, f.states["step_1"] = true, "running", "running" })
+	logs, phase, e = wf.StepLogs("sqlflow-abc", 1, 1)
+	a.NoError(e)
+	a.Equal(phaseRunning, phase)
+	a.Equal([]string{"epoch 1", "epoch 2"}, logs)
+	logs, phase, e = wf.StepLogs("sqlflow-abc", 1, 3)
+	a.NoError(e)
+	a.Equal(phaseRunning, phase)
+	a.Equal(0, len(logs))
+
+	// the second step hasn't been scheduled
+	logs, phase, e = wf.StepLogs("sqlflow-abc", 2, 0)
+	a.NoError(e)
+	a.Equal("", phase)
+	_, _, e = wf.StepLogs("sqlflow-abc", 3, 0)
+	a.Error(e)
+}
@@ -158,6 +158,54 @@ func (w *Workflow) Fetch(req *pb.FetchRequest) (*pb.FetchResponse, error) {
 	return r.Response(req.Job.Id, stepGroupName, newStepPhase, eof), nil
 }
 
+// StepLogs returns the logs of the step-th step of the workflow after the
+// first offset lines, or none if offset < 0, and the phase of the step
+// read before the logs, which is "" if the step hasn't started.
+func (w *Workflow) StepLogs(workflowID string, step, offset int) ([]string, string, error) {
+	if step < 1 {
+		return nil, "", fmt.Errorf("the step %d of the workflow %s does not exist", step, workflowID)
+	}
+	wf, err := k8sReadWorkflow(workflowID)
+	if err != nil {
+		return nil, "", err
+	}
+	if isWorkflowPending(wf) || len(wf.Status.Nodes[workflowID].Children) == 0 {
+		return nil, "", nil
+	}
+	stepGroupName, err := getFirstStepGroup(wf, workflowID)
+	if err != nil {
+		return nil, "", err
+	}
+	for i := 1; i < step && stepGroupName != ""; i++ {
+		if len(wf.Status.Nodes[stepGroupName].Children) == 0 {
+			return nil, "", nil
+		}
+		if stepGroupName, err = getNextStepGroup(wf, stepGroupName); err != nil {
+			return nil, "", err
+		}
+	}
+	// The pod of the step group is created after the step group.
+	if stepGroupName == "" || len(wf.Status.Nodes[stepGroupName].Children) == 0 {
+		return nil, "", nil
+	}
+	pod, err := getPodByStepGroup(wf, stepGroupName)
+	if err != nil {
+		return nil, "", err
+	}
+	phase := string(pod.Status.Phase)
+	if offset < 0 || isPodPending(pod) {
+		return nil, phase, nil
+	}
+	logs, err := k8sReadPodLogs(pod.ObjectMeta.Name, "main", "", false)
+	if err != nil {
+		return nil, "", err
+	}
+	if offset >= len(logs) || len(logs) == 1 && logs[0] == "" {
+		return nil, phase, nil
+	}
+	return logs[offset:], phase, nil
+}
+
 func parseOffset(content string) (string, string, error) {
 	reTimestamps := regexp.MustCompile(`([^\s]+)\s(.*)$`)
 	msg := reTimestamps.FindStringSubmatch(content)
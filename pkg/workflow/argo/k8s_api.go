@@ -88,3 +88,13 @@ func k8sDeleteWorkflow(workflowID string) error {
 	}
 	return nil
 }
+
+func k8sTerminateWorkflow(workflowID string) error {
+	// argo terminate sets the deadline of the workflow to now.
+	cmd := exec.Command("kubectl", "patch", "workflow", workflowID, "--type", "merge", "-p", `{"spec":{"activeDeadlineSeconds":0}}`)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("terminate workflow error: %v\n%v", string(output), err)
+	}
+	return nil
+}
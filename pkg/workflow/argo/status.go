@@ -0,0 +1,44 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argo
+
+import (
+	wfv1 "github.com/argoproj/argo/pkg/apis/workflow/v1alpha1"
+)
+
+// Status returns the phase of the workflow, one of Pending, Running,
+// Succeeded and Failed, and the message of the workflow status.
+func (w *Workflow) Status(workflowID string) (string, string, error) {
+	wf, err := k8sReadWorkflow(workflowID)
+	if err != nil {
+		return "", "", err
+	}
+	return workflowPhase(wf), wf.Status.Message, nil
+}
+
+// Terminate stops the workflow like `argo terminate`, which fails the
+// running steps and skips the rest.
+func (w *Workflow) Terminate(workflowID string) error {
+	return k8sTerminateWorkflow(workflowID)
+}
+
+func workflowPhase(wf *wfv1.Workflow) string {
+	switch wf.Status.Phase {
+	case "":
+		return string(wfv1.NodePending)
+	case wfv1.NodeError:
+		return string(wfv1.NodeFailed)
+	}
+	return string(wf.Status.Phase)
+}
@@ -342,6 +342,11 @@ func TestUnmarshal(t *testing.T) {
 	}
 
 	a.Equal(wf.Status.Phase, wfv1.NodePhase("Succeeded"))
+	a.Equal("Succeeded", workflowPhase(wf))
+	wf.Status.Phase = wfv1.NodeError
+	a.Equal("Failed", workflowPhase(wf))
+	wf.Status.Phase = ""
+	a.Equal("Pending", workflowPhase(wf))
 }
 
 func TestGetStepGroup(t *testing.T) {
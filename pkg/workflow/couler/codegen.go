@@ -23,8 +23,14 @@ import (
 	"strconv"
 	"strings"
 
+	"sqlflow.org/sqlflow/pkg/database"
 	"sqlflow.org/sqlflow/pkg/ir"
+	"sqlflow.org/sqlflow/pkg/model"
 	pb "sqlflow.org/sqlflow/pkg/proto"
+	"sqlflow.org/sqlflow/pkg/secret"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/grouping"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/resource"
+	"sqlflow.org/sqlflow/pkg/sql/codegen/tensorflow"
 )
 
 var defaultDockerImage = "sqlflow/sqlflow"
@@ -49,6 +55,10 @@ func fillEnvFromSession(envs *map[string]string, session *pb.Session) {
 	fillMapIfValueNotEmpty(*envs, "SQLFLOW_HADOOP_USER", session.HdfsUser)
 	fillMapIfValueNotEmpty(*envs, "SQLFLOW_HADOOP_PASS", session.HdfsUser)
 	fillMapIfValueNotEmpty(*envs, "SQLFLOW_submitter", session.Submitter)
+	fillMapIfValueNotEmpty(*envs, "SQLFLOW_EXPERIMENT_TRACKER", session.ExperimentTracker)
+	if session.NoCache {
+		(*envs)["SQLFLOW_NO_CACHE"] = "true"
+	}
 }
 
 func getStepEnvs(session *pb.Session) (map[string]string, error) {
@@ -59,8 +69,9 @@ func getStepEnvs(session *pb.Session) (map[string]string, error) {
 		if len(pair) != 2 {
 			return nil, fmt.Errorf("env: %s should format key=value", env)
 		}
-		// should not pass the workflow env into step
-		if strings.HasPrefix(pair[0], "SQLFLOW_") && !strings.HasPrefix(pair[0], "SQLFLOW_WORKFLOW_") {
+		// should not pass the workflow env and the secrets into step, the
+		// secrets are passed by SQLFLOW_WORKFLOW_SECRET
+		if strings.HasPrefix(pair[0], "SQLFLOW_") && !strings.HasPrefix(pair[0], "SQLFLOW_WORKFLOW_") && !secret.IsSecretEnv(pair[0]) {
 			envs[pair[0]] = pair[1]
 		}
 	}
@@ -77,6 +88,29 @@ func verifyResources(resources string) error {
 	return nil
 }
 
+// stepResources returns the resources of the step running a statement in
+// JSON, which are the ones in SQLFLOW_WORKFLOW_RESOURCES overridden by the
+// ones the statement requests, or "" for the former.
+func stepResources(r ir.Resources) (string, error) {
+	if r.IsEmpty() {
+		return "", nil
+	}
+	res := make(map[string]interface{})
+	if env := os.Getenv(envResource); env != "" {
+		if e := json.Unmarshal([]byte(env), &res); e != nil {
+			return "", fmt.Errorf("%s: %s should be JSON format", envResource, env)
+		}
+	}
+	for k, v := range resource.Requests(r) {
+		res[k] = v
+	}
+	b, e := json.Marshal(res)
+	if e != nil {
+		return "", e
+	}
+	return string(b), nil
+}
+
 func getSecret() (string, string, error) {
 	secretMap := make(map[string]map[string]string)
 	secretCfg := os.Getenv("SQLFLOW_WORKFLOW_SECRET")
@@ -97,6 +131,32 @@ func getSecret() (string, string, error) {
 	return name, string(value), nil
 }
 
+// groupSQLs returns the statements of the steps training the model of
+// each group of trainStmt in parallel. It returns nil if the groups can't
+// be queried before the workflow runs, like the table is created by the
+// former statements, and then one step trains the groups in turn and
+// reports the errors.
+func groupSQLs(trainStmt *ir.TrainStmt, session *pb.Session) ([]string, error) {
+	db, e := database.OpenAndConnectDB(session.DbConnStr)
+	if e != nil {
+		return nil, e
+	}
+	defer db.Close()
+	values, e := grouping.Values(db, trainStmt.Select, trainStmt.Grouping.Column)
+	if e != nil {
+		return nil, nil
+	}
+	sqls := []string{}
+	for _, v := range values {
+		sql, e := grouping.StepSQL(trainStmt.OriginalSQL, v)
+		if e != nil {
+			return nil, e
+		}
+		sqls = append(sqls, sql)
+	}
+	return sqls, nil
+}
+
 // GenFiller generates Filler to fill the template
 func GenFiller(programIR []ir.SQLFlowStmt, session *pb.Session) (*Filler, error) {
 	stepEnvs, err := getStepEnvs(session)
@@ -133,12 +193,20 @@ func GenFiller(programIR []ir.SQLFlowStmt, session *pb.Session) (*Filler, error)
 
 	for _, sqlIR := range programIR {
 		switch i := sqlIR.(type) {
-		case *ir.NormalStmt, *ir.PredictStmt, *ir.ExplainStmt:
+		case *ir.NormalStmt, *ir.ExplainStmt, *ir.DropModelStmt, *ir.ShowModelsStmt, *ir.ShowLineageStmt:
 			// TODO(typhoonzero): get model image used when training.
 			sqlStmt := &sqlStatement{
 				OriginalSQL: sqlIR.GetOriginalSQL(), IsExtendedSQL: sqlIR.IsExtended(),
 				DockerImage: defaultDockerImage}
 			r.SQLStatements = append(r.SQLStatements, sqlStmt)
+		case *ir.PredictStmt:
+			sqlStmt := &sqlStatement{
+				OriginalSQL: sqlIR.GetOriginalSQL(), IsExtendedSQL: sqlIR.IsExtended(),
+				DockerImage: defaultDockerImage}
+			if sqlStmt.Resources, e = stepResources(i.Resources); e != nil {
+				return nil, e
+			}
+			r.SQLStatements = append(r.SQLStatements, sqlStmt)
 		case *ir.TrainStmt:
 			stepImage := defaultDockerImage
 			if i.ModelImage != "" {
@@ -154,7 +222,30 @@ func GenFiller(programIR []ir.SQLFlowStmt, session *pb.Session) (*Filler, error)
 				sqlStmt := &sqlStatement{
 					OriginalSQL: sqlIR.GetOriginalSQL(), IsExtendedSQL: sqlIR.IsExtended(),
 					DockerImage: stepImage}
+				if sqlStmt.Resources, e = stepResources(i.Resources); e != nil {
+					return nil, e
+				}
+				// The distributed training of TensorFlow out of PAI runs in a TFJob.
+				if model.FrameworkOf(i.Estimator) == "tensorflow" && !tensorflow.IsPAI() {
+					sqlStmt.NumWorkers, sqlStmt.NumPS = tensorflow.DistributedReplicas(i.Attributes)
+					sqlStmt.IsTFJob = sqlStmt.NumWorkers > 1 || sqlStmt.NumPS > 0
+				}
+				if g := i.Grouping; g.Column != "" && g.Value == "" && !g.IndexOnly {
+					if sqlStmt.GroupSQLs, e = groupSQLs(i, session); e != nil {
+						return nil, e
+					}
+				}
 				r.SQLStatements = append(r.SQLStatements, sqlStmt)
+				if sqlStmt.GroupSQLs != nil {
+					// The step after the steps of the groups saves the index
+					// of their models.
+					indexSQL, e := grouping.IndexSQL(i.OriginalSQL)
+					if e != nil {
+						return nil, e
+					}
+					r.SQLStatements = append(r.SQLStatements, &sqlStatement{
+						OriginalSQL: indexSQL, IsExtendedSQL: true, DockerImage: stepImage})
+				}
 			}
 		default:
 			return nil, fmt.Errorf("unrecognized IR type: %v", i)
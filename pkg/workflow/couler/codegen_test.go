@@ -17,6 +17,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"testing"
@@ -88,9 +89,13 @@ func TestCoulerCodegen(t *testing.T) {
 	a := assert.New(t)
 	sqlIR := MockSQLProgramIR()
 	os.Setenv("SQLFLOW_OSS_AK", "oss_key")
+	os.Setenv("SQLFLOW_SECRET_PROVIDER", "env")
+	os.Setenv("SQLFLOW_SECRET_PROD_MYSQL_PASSWORD", "p@ss")
 	os.Setenv("SQLFLOW_WORKFLOW_SECRET", `{"sqlflow-secret":{"oss_sk": "oss_sk"}}`)
 	os.Setenv(envResource, `{"memory": "32Mi", "cpu": "100m"}`)
 	defer os.Unsetenv("SQLFLOW_OSS_AK")
+	defer os.Unsetenv("SQLFLOW_SECRET_PROVIDER")
+	defer os.Unsetenv("SQLFLOW_SECRET_PROD_MYSQL_PASSWORD")
 	cg := &Codegen{}
 	code, err := cg.GenCode(sqlIR, &pb.Session{})
 	a.NoError(err)
@@ -100,6 +105,8 @@ func TestCoulerCodegen(t *testing.T) {
 	a.Equal(r.FindStringSubmatch(code)[1], "SELECT * FROM iris.train limit 10")
 	a.True(strings.Contains(code, `step_envs["SQLFLOW_OSS_AK"] = '''oss_key'''`))
 	a.False(strings.Contains(code, `step_envs["SQLFLOW_WORKFLOW_SECRET"]`))
+	a.True(strings.Contains(code, `step_envs["SQLFLOW_SECRET_PROVIDER"] = '''env'''`))
+	a.False(strings.Contains(code, "p@ss"))
 	a.True(strings.Contains(code, `couler.clean_workflow_after_seconds_finished(86400)`))
 	a.True(strings.Contains(code, `couler.secret(secret_data, name="sqlflow-secret", dry_run=True)`))
 	a.True(strings.Contains(code, `resources=json.loads('''{"memory": "32Mi", "cpu": "100m"}''')`))
@@ -113,6 +120,76 @@ func TestCoulerCodegen(t *testing.T) {
 	a.NoError(e)
 }
 
+func TestCoulerCodegenTFJob(t *testing.T) {
+	a := assert.New(t)
+	trainStmt := ir.MockTrainStmt(false)
+	trainStmt.Attributes["train.num_workers"] = 3
+	trainStmt.Attributes["train.num_ps"] = 1
+	cg := &Codegen{}
+	code, err := cg.GenCode([]ir.SQLFlowStmt{trainStmt}, &pb.Session{})
+	a.NoError(err)
+	a.Contains(code, `steps.sqlflow_tfjob(sql=`)
+	a.Contains(code, `num_workers=3, num_ps=1)`)
+
+	trainStmt.Attributes["train.num_workers"] = 1
+	trainStmt.Attributes["train.num_ps"] = 0
+	code, err = cg.GenCode([]ir.SQLFlowStmt{trainStmt}, &pb.Session{})
+	a.NoError(err)
+	a.NotContains(code, `sqlflow_tfjob`)
+}
+
+func TestCoulerCodegenResources(t *testing.T) {
+	a := assert.New(t)
+	os.Setenv(envResource, `{"memory": "32Mi", "cpu": "100m"}`)
+	defer os.Unsetenv(envResource)
+	trainStmt := ir.MockTrainStmt(false)
+	trainStmt.Resources = ir.Resources{GPU: 1, Memory: "8Gi"}
+	predStmt := ir.MockPredStmt(trainStmt)
+	cg := &Codegen{}
+	code, err := cg.GenCode([]ir.SQLFlowStmt{trainStmt, predStmt}, &pb.Session{})
+	a.NoError(err)
+	a.Contains(code, `step_resources = json.loads('''{"cpu":"100m","memory":"8Gi","nvidia.com/gpu":"1"}''')`)
+	a.Equal(2, strings.Count(code, "resources=step_resources"))
+	// the predict statement runs with the default resources
+	a.Equal(1, strings.Count(code, "step_resources = json.loads"))
+}
+
+func TestCoulerCodegenGroups(t *testing.T) {
+	a := assert.New(t)
+	dir, e := ioutil.TempDir("", "sqlflow_couler")
+	a.NoError(e)
+	defer os.RemoveAll(dir)
+	session := &pb.Session{DbConnStr: "sqlite3://" + filepath.Join(dir, "group.db")}
+	db, e := database.OpenAndConnectDB(session.DbConnStr)
+	a.NoError(e)
+	defer db.Close()
+	_, e = db.Exec("CREATE TABLE train (region TEXT, c1 FLOAT, class INT)")
+	a.NoError(e)
+	_, e = db.Exec("INSERT INTO train VALUES ('us', 1.5, 0), ('cn', 2.5, 1), ('cn', 0.5, 1)")
+	a.NoError(e)
+
+	trainStmt := ir.MockTrainStmt(false)
+	trainStmt.OriginalSQL = `SELECT * FROM train TO TRAIN DNNClassifier WITH model.n_classes = 2, train.group_by = "region" LABEL class INTO my_dnn_model;`
+	trainStmt.Select = "SELECT * FROM train"
+	trainStmt.Grouping = ir.Grouping{Column: "region"}
+	cg := &Codegen{}
+	code, err := cg.GenCode([]ir.SQLFlowStmt{trainStmt}, session)
+	a.NoError(err)
+	// a step for each group in parallel, and the step saving the index after
+	a.Contains(code, "couler.concurrent([")
+	a.Contains(code, `lambda: steps.sqlflow(sql='''SELECT * FROM train TO TRAIN DNNClassifier WITH model.n_classes = 2, train.group = "cn", train.group_by = "region" LABEL class INTO my_dnn_model;'''`)
+	a.Contains(code, `lambda: steps.sqlflow(sql='''SELECT * FROM train TO TRAIN DNNClassifier WITH model.n_classes = 2, train.group = "us", train.group_by = "region" LABEL class INTO my_dnn_model;'''`)
+	a.Contains(code, `steps.sqlflow(sql='''SELECT * FROM train TO TRAIN DNNClassifier WITH model.n_classes = 2, train.group_index = true, train.group_by = "region" LABEL class INTO my_dnn_model;'''`)
+	a.True(strings.Index(code, "couler.concurrent") < strings.Index(code, "train.group_index"))
+
+	// the groups of the tables created by the program are trained in one step
+	trainStmt.Select = "SELECT * FROM absent"
+	code, err = cg.GenCode([]ir.SQLFlowStmt{trainStmt}, session)
+	a.NoError(err)
+	a.NotContains(code, "couler.concurrent")
+	a.NotContains(code, "train.group_index")
+}
+
 func TestCoulerCodegenSpecialChars(t *testing.T) {
 	a := assert.New(t)
 	specialCharsStmt := ir.NormalStmt("`$\"\\;")
@@ -193,9 +270,9 @@ INTO sqlflow_models.my_xgboost_model;
 		Attributes:       attrs,
 		Features: map[string][]ir.FeatureColumn{
 			"feature_columns": {
-				&ir.NumericColumn{&ir.FieldDesc{"sepal_length", ir.Float, "", []int{1}, false, nil, 0}},
-				&ir.NumericColumn{&ir.FieldDesc{"sepal_width", ir.Float, "", []int{1}, false, nil, 0}},
-				&ir.NumericColumn{&ir.FieldDesc{"petal_length", ir.Float, "", []int{1}, false, nil, 0}},
-				&ir.NumericColumn{&ir.FieldDesc{"petal_width", ir.Float, "", []int{1}, false, nil, 0}}}},
-		Label: &ir.NumericColumn{&ir.FieldDesc{"class", ir.Int, "", []int{1}, false, nil, 0}}}
+				&ir.NumericColumn{&ir.FieldDesc{"sepal_length", ir.Float, "", []int{1}, false, nil, 0, nil, false, false, nil, ""}},
+				&ir.NumericColumn{&ir.FieldDesc{"sepal_width", ir.Float, "", []int{1}, false, nil, 0, nil, false, false, nil, ""}},
+				&ir.NumericColumn{&ir.FieldDesc{"petal_length", ir.Float, "", []int{1}, false, nil, 0, nil, false, false, nil, ""}},
+				&ir.NumericColumn{&ir.FieldDesc{"petal_width", ir.Float, "", []int{1}, false, nil, 0, nil, false, false, nil, ""}}}},
+		Label: &ir.NumericColumn{&ir.FieldDesc{"class", ir.Int, "", []int{1}, false, nil, 0, nil, false, false, nil, ""}}}
 }
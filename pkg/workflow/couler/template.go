@@ -25,6 +25,17 @@ type sqlStatement struct {
 	Model          string
 	Parameters     string
 	IsKatibTrain   bool
+	// IsTFJob is true for the distributed training of TensorFlow, which
+	// runs in NumWorkers workers and NumPS parameter servers.
+	IsTFJob    bool
+	NumWorkers int
+	NumPS      int
+	// Resources is the resources of the step in JSON requested by the
+	// "resources.*" attributes, "" means the ones of Filler.Resources.
+	Resources string
+	// GroupSQLs are the statements of the steps training the models of
+	// the groups of train.group_by in parallel, instead of OriginalSQL.
+	GroupSQLs []string
 }
 
 // Filler is used to fill the template
@@ -66,9 +77,20 @@ if '''{{.Resources}}''' != "":
 couler.clean_workflow_after_seconds_finished({{.WorkflowTTL}})
 
 {{ range $ss := .SQLStatements }}
-	{{if $ss.IsExtendedSQL }}
+step_resources = resources
+{{if $ss.Resources}}step_resources = json.loads('''{{$ss.Resources}}'''){{end}}
+	{{if $ss.GroupSQLs }}
 
-steps.sqlflow(sql='''{{ $ss.OriginalSQL }}''', image="{{ $ss.DockerImage }}", env=step_envs, secret=sqlflow_secret, resources=resources)
+couler.concurrent([{{ range $sql := $ss.GroupSQLs }}
+	{{if $ss.IsTFJob }}lambda: steps.sqlflow_tfjob(sql='''{{ $sql }}''', image="{{ $ss.DockerImage }}", env=step_envs, secret=sqlflow_secret, resources=step_resources, num_workers={{ $ss.NumWorkers }}, num_ps={{ $ss.NumPS }}),
+	{{else}}lambda: steps.sqlflow(sql='''{{ $sql }}''', image="{{ $ss.DockerImage }}", env=step_envs, secret=sqlflow_secret, resources=step_resources),
+	{{end}}{{end}}])
+	{{else if $ss.IsTFJob }}
+
+steps.sqlflow_tfjob(sql='''{{ $ss.OriginalSQL }}''', image="{{ $ss.DockerImage }}", env=step_envs, secret=sqlflow_secret, resources=step_resources, num_workers={{ $ss.NumWorkers }}, num_ps={{ $ss.NumPS }})
+	{{else if $ss.IsExtendedSQL }}
+
+steps.sqlflow(sql='''{{ $ss.OriginalSQL }}''', image="{{ $ss.DockerImage }}", env=step_envs, secret=sqlflow_secret, resources=step_resources)
 	{{else if $ss.IsKatibTrain}}
 import couler.sqlflow.katib as auto
 
@@ -80,7 +102,7 @@ auto.train(model=model, params=params, sql=escape_sql(train_sql), datasource=dat
 # TODO(yancey1989): 
 #	using "repl -parse" to output IR and
 #	feed to "sqlflow_submitter.{submitter}.train" to submit the job
-steps.sqlflow(sql='''{{ $ss.OriginalSQL }}''', image="{{ $ss.DockerImage }}", env=step_envs, resources=resources)
+steps.sqlflow(sql='''{{ $ss.OriginalSQL }}''', image="{{ $ss.DockerImage }}", env=step_envs, resources=step_resources)
 	{{end}}
 {{end}}
 `
@@ -0,0 +1,122 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kfp
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"sqlflow.org/sqlflow/pkg/ir"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+	"sqlflow.org/sqlflow/pkg/workflow/couler"
+)
+
+// Codegen generates Kubeflow Pipelines program
+type Codegen struct{}
+
+func stepName(idx int) string {
+	return fmt.Sprintf("step-%d", idx)
+}
+
+// modelOf returns the model used by the statement, "" if it uses no model.
+func modelOf(stmt ir.SQLFlowStmt) string {
+	switch s := stmt.(type) {
+	case *ir.PredictStmt:
+		return s.Using
+	case *ir.ExplainStmt:
+		return s.ModelName
+	case *ir.EvaluateStmt:
+		return s.ModelName
+	}
+	return ""
+}
+
+// GenFiller generates Filler to fill the template, whose steps are the
+// ones of the Couler program of the same SQL program. The model trained
+// by a step is passed as an artifact to the following steps using it.
+func GenFiller(programIR []ir.SQLFlowStmt, session *pb.Session) (*Filler, error) {
+	cf, e := couler.GenFiller(programIR, session)
+	if e != nil {
+		return nil, e
+	}
+	r := &Filler{
+		StepEnvs:    cf.StepEnvs,
+		WorkflowTTL: cf.WorkflowTTL,
+		SecretName:  cf.SecretName,
+		Resources:   cf.Resources,
+	}
+	// the steps training the models in the program
+	trainedBy := map[string]string{}
+	for i, s := range cf.SQLStatements {
+		if s.IsTFJob || s.IsKatibTrain {
+			return nil, fmt.Errorf("the kfp backend does not support the TFJob or Katib step of: %s", s.OriginalSQL)
+		}
+		st := &step{
+			Name:        stepName(i + 1),
+			OriginalSQL: s.OriginalSQL,
+			DockerImage: s.DockerImage,
+			Resources:   s.Resources,
+			ModelInput:  trainedBy[modelOf(programIR[i])],
+		}
+		if t, ok := programIR[i].(*ir.TrainStmt); ok {
+			st.ExportModel = t.Into
+			trainedBy[t.Into] = st.Name
+		}
+		r.Steps = append(r.Steps, st)
+	}
+	return r, nil
+}
+
+// GenCode generates a Kubeflow Pipelines program by the KFP SDK, which
+// runs the steps one after another.
+func (cg *Codegen) GenCode(programIR []ir.SQLFlowStmt, session *pb.Session) (string, error) {
+	r, e := GenFiller(programIR, session)
+	if e != nil {
+		return "", e
+	}
+	var program bytes.Buffer
+	if e := pipelineTemplate.Execute(&program, r); e != nil {
+		return "", e
+	}
+	return program.String(), nil
+}
+
+// GenYAML compiles the Kubeflow Pipelines program into the workflow YAML
+// of the pipeline by the KFP SDK.
+func (cg *Codegen) GenYAML(program string) (string, error) {
+	dir, e := ioutil.TempDir("", "sqlflow-kfp")
+	if e != nil {
+		return "", e
+	}
+	defer os.RemoveAll(dir)
+	programFile := filepath.Join(dir, "pipeline.py")
+	if e := ioutil.WriteFile(programFile, []byte(program), 0644); e != nil {
+		return "", e
+	}
+	yamlFile := filepath.Join(dir, "pipeline.yaml")
+	// execute command: `python pipeline.py pipeline.yaml`
+	cmd := exec.Command("python", programFile, yamlFile)
+	if out, e := cmd.CombinedOutput(); e != nil {
+		return "", fmt.Errorf("failed %s, %v %s", cmd, e, out)
+	}
+	b, e := ioutil.ReadFile(yamlFile)
+	if e != nil {
+		return "", e
+	}
+	return string(b), nil
+}
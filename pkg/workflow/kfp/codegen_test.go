@@ -0,0 +1,64 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kfp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sqlflow.org/sqlflow/pkg/ir"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+)
+
+func TestKFPCodegen(t *testing.T) {
+	a := assert.New(t)
+	standardSQL := ir.NormalStmt("SELECT * FROM iris.train limit 10;")
+	trainStmt := ir.MockTrainStmt(false)
+	trainStmt.Into = "sqlflow_models.my_dnn_model"
+	predStmt := ir.MockPredStmt(trainStmt)
+	predStmt.OriginalSQL = "SELECT * FROM iris.test TO PREDICT iris.predict.class USING sqlflow_models.my_dnn_model;"
+	predStmt.Using = trainStmt.Into
+	otherStmt := ir.MockPredStmt(trainStmt)
+	otherStmt.Using = "sqlflow_models.other_model"
+
+	r, e := GenFiller([]ir.SQLFlowStmt{&standardSQL, trainStmt, predStmt, otherStmt}, &pb.Session{})
+	a.NoError(e)
+	a.Equal(4, len(r.Steps))
+	a.Equal("step-1", r.Steps[0].Name)
+	a.Equal("", r.Steps[0].ExportModel)
+	a.Equal("sqlflow_models.my_dnn_model", r.Steps[1].ExportModel)
+	a.Equal("step-2", r.Steps[2].ModelInput)
+	// the model not trained in the program is loaded from the table
+	a.Equal("", r.Steps[3].ModelInput)
+	a.Equal(86400, r.WorkflowTTL)
+
+	cg := &Codegen{}
+	code, e := cg.GenCode([]ir.SQLFlowStmt{&standardSQL, trainStmt, predStmt}, &pb.Session{})
+	a.NoError(e)
+	a.Contains(code, `op = sqlflow_step("step-1", '''SELECT * FROM iris.train limit 10;''', "sqlflow/sqlflow", step_resources)`)
+	a.Contains(code, `export_model="sqlflow_models.my_dnn_model")`)
+	a.Contains(code, `model_input=steps["step-2"].outputs["model"])`)
+	a.Equal(3, strings.Count(code, "op.after(previous)"))
+	a.Contains(code, `kfp.compiler.Compiler().compile(sqlflow, sys.argv[1])`)
+}
+
+func TestKFPCodegenTFJob(t *testing.T) {
+	a := assert.New(t)
+	trainStmt := ir.MockTrainStmt(false)
+	trainStmt.Attributes["train.num_workers"] = 3
+	cg := &Codegen{}
+	_, e := cg.GenCode([]ir.SQLFlowStmt{trainStmt}, &pb.Session{})
+	a.Error(e)
+}
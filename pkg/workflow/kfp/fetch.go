@@ -0,0 +1,172 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kfp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"sqlflow.org/sqlflow/pkg/log"
+	pb "sqlflow.org/sqlflow/pkg/proto"
+	wfrsp "sqlflow.org/sqlflow/pkg/workflow/response"
+)
+
+func logViewURL(runID string) string {
+	ep := os.Getenv("SQLFLOW_WORKFLOW_LOGVIEW_ENDPOINT")
+	// Kubeflow Pipelines UI run details page
+	return fmt.Sprintf("%s/#/runs/details/%s", ep, runID)
+}
+
+// stepIndex returns the 1-based index of a step from its name.
+func stepIndex(name string) (int, error) {
+	idx, e := strconv.Atoi(strings.TrimPrefix(name, "step-"))
+	if e != nil || !strings.HasPrefix(name, "step-") {
+		return 0, fmt.Errorf("unrecognized SQLFlow step: %s", name)
+	}
+	return idx, nil
+}
+
+// stepCount returns the number of the steps, each runs in a container.
+func (wf *workflowManifest) stepCount() int {
+	n := 0
+	for _, t := range wf.Spec.Templates {
+		if t.Container != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// stepPod returns the pod node of the step, or nil if it's not created.
+func (wf *workflowManifest) stepPod(name string) *workflowNode {
+	for _, n := range wf.Status.Nodes {
+		if n.Type == "Pod" && n.DisplayName == name {
+			return n
+		}
+	}
+	return nil
+}
+
+// Fetch fetches the step logs and status of a run like the Argo workflow,
+// where the step ID is the name of the step and the step phase is the
+// phase of its pod.
+func (w *Workflow) Fetch(req *pb.FetchRequest) (*pb.FetchResponse, error) {
+	logger := log.WithFields(log.Fields{
+		"requestID": log.UUID(),
+		"jobID":     req.Job.Id,
+		"stepID":    req.StepId,
+		"event":     "fetch",
+	})
+	runID := req.Job.Id
+	rd, e := getRun(runID)
+	if e != nil {
+		logger.Errorf("workflowFailed/getRun, error: %v", e)
+		return nil, e
+	}
+	if rd.PipelineRuntime.WorkflowManifest == "" {
+		return wfrsp.New().Response(runID, "", "", false), nil
+	}
+	wf := &workflowManifest{}
+	if e := json.Unmarshal([]byte(rd.PipelineRuntime.WorkflowManifest), wf); e != nil {
+		return nil, fmt.Errorf("parse the workflow of the run %s failed: %v", runID, e)
+	}
+	name := req.StepId
+	if name == "" {
+		name = stepName(1)
+	}
+	stepIdx, e := stepIndex(name)
+	if e != nil {
+		return nil, e
+	}
+	stepCnt := wf.stepCount()
+	logPrefix := fmt.Sprintf("SQLFlow Step: [%d/%d]", stepIdx, stepCnt)
+	logURL := logViewURL(runID)
+
+	r := wfrsp.New()
+	pod := wf.stepPod(name)
+	if pod == nil {
+		if runPhase(rd.Run.Status) == phaseFailed {
+			return nil, fmt.Errorf("%s Failed, Log: %s\n%s", logPrefix, logURL, rd.Run.Error)
+		}
+		return r.Response(runID, name, req.StepPhase, false), nil
+	}
+	if req.StepPhase == "" {
+		r.AppendMessage(fmt.Sprintf("%s Log: %s", logPrefix, logURL))
+	}
+	phase := runPhase(pod.Phase)
+	if req.StepPhase != phase {
+		r.AppendMessage(fmt.Sprintf("%s Status: %s", logPrefix, phase))
+	}
+	if phase != phaseSucceeded && phase != phaseFailed {
+		return r.Response(runID, name, phase, false), nil
+	}
+
+	logs, e := podLogs(wf.Metadata.Namespace, pod.ID)
+	if e != nil {
+		return nil, e
+	}
+	if e := r.AppendProtoMessages(logs); e != nil {
+		return nil, e
+	}
+	if phase == phaseFailed {
+		logger.Errorf("workflowFailed, step:%s", name)
+		return r.ResponseWithStepComplete(runID, name, phase, false),
+			fmt.Errorf("%s Failed, Log: %s\n%s", logPrefix, logURL, r.ErrorMessage())
+	}
+	if stepIdx == stepCnt {
+		logger.Infof("workflowSucceed")
+		return r.ResponseWithStepComplete(runID, name, phase, true), nil
+	}
+	// move to the next step
+	return r.ResponseWithStepComplete(runID, stepName(stepIdx+1), "", false), nil
+}
+
+// StepLogs returns the logs of the step-th step after the first offset
+// lines, or none if offset < 0, and the phase of its pod read before the
+// logs, which is "" if the pod hasn't been created.
+func (w *Workflow) StepLogs(workflowID string, step, offset int) ([]string, string, error) {
+	rd, e := getRun(workflowID)
+	if e != nil {
+		return nil, "", e
+	}
+	if rd.PipelineRuntime.WorkflowManifest == "" {
+		return nil, "", nil
+	}
+	wf := &workflowManifest{}
+	if e := json.Unmarshal([]byte(rd.PipelineRuntime.WorkflowManifest), wf); e != nil {
+		return nil, "", fmt.Errorf("parse the workflow of the run %s failed: %v", workflowID, e)
+	}
+	if step < 1 || step > wf.stepCount() {
+		return nil, "", fmt.Errorf("the step %s of the run %s does not exist", stepName(step), workflowID)
+	}
+	pod := wf.stepPod(stepName(step))
+	if pod == nil {
+		return nil, "", nil
+	}
+	phase := runPhase(pod.Phase)
+	if offset < 0 || phase == phasePending {
+		return nil, phase, nil
+	}
+	logs, e := podLogs(wf.Metadata.Namespace, pod.ID)
+	if e != nil {
+		return nil, "", e
+	}
+	if offset < len(logs) {
+		return logs[offset:], phase, nil
+	}
+	return nil, phase, nil
+}
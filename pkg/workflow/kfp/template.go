@@ -0,0 +1,110 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kfp
+
+import "text/template"
+
+type step struct {
+	Name        string
+	OriginalSQL string
+	DockerImage string
+	// Resources is the resources of the step in JSON, "" means the ones
+	// of Filler.Resources.
+	Resources string
+	// ExportModel is the model trained by the step to pass as the output
+	// artifact "model" to the following steps.
+	ExportModel string
+	// ModelInput is the step whose output artifact "model" is the model
+	// used by this step, "" if the model is not trained in the program.
+	ModelInput string
+}
+
+// Filler is used to fill the template
+type Filler struct {
+	StepEnvs    map[string]string
+	WorkflowTTL int
+	SecretName  string
+	Resources   string
+	Steps       []*step
+}
+
+const pipelineTemplateText = `
+import json
+import sys
+
+import kfp
+import kfp.dsl as dsl
+from kfp.components import load_component_from_text
+from kubernetes.client import models as k8s
+
+step_envs = dict()
+{{range $k, $v := .StepEnvs}}
+step_envs["{{$k}}"] = '''{{$v}}'''
+{{end}}
+
+resources = None
+if '''{{.Resources}}''' != "":
+    resources = json.loads('''{{.Resources}}''')
+
+
+def sqlflow_step(name, sql, image, resources=None, model_input=None, export_model=None):
+    '''sqlflow_step runs sql in image, which loads the model from the artifact
+    model_input, or passes export_model trained by sql as the artifact "model".
+    '''
+    command = ["step", "-e", sql]
+    spec = {"name": name, "implementation": {"container": {"image": image, "command": command}}}
+    args = dict()
+    if model_input is not None:
+        spec["inputs"] = [{"name": "model", "type": "SQLFlowModel"}]
+        command.extend(["-model_dir", {"inputPath": "model"}])
+        args["model"] = model_input
+    if export_model is not None:
+        spec["outputs"] = [{"name": "model", "type": "SQLFlowModel"}]
+        command.extend(["-export_model", export_model, "-export_dir", {"outputPath": "model"}])
+    op = load_component_from_text(json.dumps(spec))(**args)
+    for k, v in step_envs.items():
+        op.container.add_env_variable(k8s.V1EnvVar(name=k, value=v))
+    # note: the secret should be created before submitting the pipeline,
+    # all of its keys are the environment variables of the steps.
+    if "{{.SecretName}}" != "":
+        op.container.add_env_from(k8s.V1EnvFromSource(secret_ref=k8s.V1SecretEnvSource(name="{{.SecretName}}")))
+    if resources is not None:
+        for k, v in resources.items():
+            op.container.add_resource_request(k, v)
+            op.container.add_resource_limit(k, v)
+    return op
+
+
+@dsl.pipeline(name="sqlflow", description="SQLFlow program")
+def sqlflow():
+    dsl.get_pipeline_conf().set_ttl_seconds_after_finished({{.WorkflowTTL}})
+    steps = dict()
+    previous = None
+{{range $s := .Steps}}
+    step_resources = resources
+    {{if $s.Resources}}step_resources = json.loads('''{{$s.Resources}}'''){{end}}
+    op = sqlflow_step("{{$s.Name}}", '''{{$s.OriginalSQL}}''', "{{$s.DockerImage}}", step_resources{{if $s.ModelInput}},
+                      model_input=steps["{{$s.ModelInput}}"].outputs["model"]{{end}}{{if $s.ExportModel}},
+                      export_model="{{$s.ExportModel}}"{{end}})
+    if previous is not None:
+        op.after(previous)
+    steps["{{$s.Name}}"] = op
+    previous = op
+{{end}}
+
+if __name__ == "__main__":
+    kfp.compiler.Compiler().compile(sqlflow, sys.argv[1])
+`
+
+var pipelineTemplate = template.Must(template.New("Pipeline").Parse(pipelineTemplateText))
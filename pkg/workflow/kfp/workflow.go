@@ -0,0 +1,200 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kfp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+
+	"sqlflow.org/sqlflow/pkg/log"
+)
+
+// Workflow operates the runs of Kubeflow Pipelines by the REST API of the
+// KFP API server at SQLFLOW_WORKFLOW_KFP_ENDPOINT, and creates the runs in
+// the experiment SQLFLOW_WORKFLOW_KFP_EXPERIMENT if it is set.
+type Workflow struct{}
+
+// the phases of the workflows and the steps, the same as the Argo ones.
+const (
+	phasePending   = "Pending"
+	phaseRunning   = "Running"
+	phaseSucceeded = "Succeeded"
+	phaseFailed    = "Failed"
+)
+
+type run struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Error  string `json:"error"`
+}
+
+type runDetail struct {
+	Run             *run `json:"run"`
+	PipelineRuntime struct {
+		WorkflowManifest string `json:"workflow_manifest"`
+	} `json:"pipeline_runtime"`
+}
+
+// workflowManifest is the Argo workflow of a run with the fields used by
+// Fetch.
+type workflowManifest struct {
+	Metadata struct {
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+	Spec struct {
+		Templates []struct {
+			Name      string           `json:"name"`
+			Container *json.RawMessage `json:"container"`
+		} `json:"templates"`
+	} `json:"spec"`
+	Status struct {
+		Nodes map[string]*workflowNode `json:"nodes"`
+	} `json:"status"`
+}
+
+type workflowNode struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+	Type        string `json:"type"`
+	Phase       string `json:"phase"`
+}
+
+// podLogs returns the non-empty lines of the logs of the main container
+// of a step pod.
+var podLogs = func(namespace, pod string) ([]string, error) {
+	cmd := exec.Command("kubectl", "logs", pod, "-c", "main", "-n", namespace)
+	out, e := cmd.CombinedOutput()
+	if e != nil {
+		return nil, fmt.Errorf("failed %s, %v %s", cmd, e, out)
+	}
+	logs := []string{}
+	for _, l := range strings.Split(string(out), "\n") {
+		if strings.TrimSpace(l) != "" {
+			logs = append(logs, l)
+		}
+	}
+	return logs, nil
+}
+
+// request sends a request to the KFP API server, and decodes the JSON
+// response into out if it is not nil.
+func request(method, path string, in, out interface{}) error {
+	ep := os.Getenv("SQLFLOW_WORKFLOW_KFP_ENDPOINT")
+	if ep == "" {
+		return fmt.Errorf("should set SQLFLOW_WORKFLOW_KFP_ENDPOINT for the kfp backend")
+	}
+	var body bytes.Buffer
+	if in != nil {
+		if e := json.NewEncoder(&body).Encode(in); e != nil {
+			return e
+		}
+	}
+	req, e := http.NewRequest(method, strings.TrimRight(ep, "/")+"/apis/v1beta1"+path, &body)
+	if e != nil {
+		return e
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token := os.Getenv("SQLFLOW_WORKFLOW_KFP_TOKEN"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, e := http.DefaultClient.Do(req)
+	if e != nil {
+		return e
+	}
+	defer resp.Body.Close()
+	b, e := ioutil.ReadAll(resp.Body)
+	if e != nil {
+		return e
+	}
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s %s: %s %s", method, path, resp.Status, b)
+	}
+	if out != nil {
+		if e := json.Unmarshal(b, out); e != nil {
+			return fmt.Errorf("%s %s: %v", method, path, e)
+		}
+	}
+	return nil
+}
+
+func getRun(runID string) (*runDetail, error) {
+	rd := &runDetail{}
+	if e := request("GET", "/runs/"+url.PathEscape(runID), nil, rd); e != nil {
+		return nil, e
+	}
+	if rd.Run == nil {
+		return nil, fmt.Errorf("the run %s does not exist", runID)
+	}
+	return rd, nil
+}
+
+// runPhase returns the phase of the status of a run or a node of its
+// workflow, which are the phases of Argo.
+func runPhase(status string) string {
+	switch status {
+	case "", "Pending":
+		return phasePending
+	case "Succeeded", "Skipped", "Omitted":
+		return phaseSucceeded
+	case "Failed", "Error":
+		return phaseFailed
+	}
+	return phaseRunning
+}
+
+// Submit creates a run of the pipeline and returns the run ID as the
+// workflow ID.
+func (w *Workflow) Submit(workflowYAML string) (string, error) {
+	body := map[string]interface{}{
+		"name":          "sqlflow-" + log.UUID(),
+		"pipeline_spec": map[string]string{"workflow_manifest": workflowYAML},
+	}
+	if exp := os.Getenv("SQLFLOW_WORKFLOW_KFP_EXPERIMENT"); exp != "" {
+		body["resource_references"] = []interface{}{map[string]interface{}{
+			"key":          map[string]string{"type": "EXPERIMENT", "id": exp},
+			"relationship": "OWNER",
+		}}
+	}
+	rd := &runDetail{}
+	if e := request("POST", "/runs", body, rd); e != nil {
+		return "", e
+	}
+	if rd.Run == nil || rd.Run.ID == "" {
+		return "", fmt.Errorf("no run ID in the response of creating the run")
+	}
+	return rd.Run.ID, nil
+}
+
+// Status returns the phase of the run, one of Pending, Running, Succeeded
+// and Failed, and the error of the run as the message.
+func (w *Workflow) Status(workflowID string) (string, string, error) {
+	rd, e := getRun(workflowID)
+	if e != nil {
+		return "", "", e
+	}
+	return runPhase(rd.Run.Status), rd.Run.Error, nil
+}
+
+// Terminate terminates the run, which fails the running step and skips
+// the rest.
+func (w *Workflow) Terminate(workflowID string) error {
+	return request("POST", fmt.Sprintf("/runs/%s/terminate", url.PathEscape(workflowID)), nil, nil)
+}
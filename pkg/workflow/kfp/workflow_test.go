@@ -0,0 +1,211 @@
+// Copyright 2020 The SQLFlow Authors. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kfp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	wfrsp "sqlflow.org/sqlflow/pkg/workflow/response"
+)
+
+const testManifest = `{"metadata": {"namespace": "kubeflow"},
+"spec": {"templates": [{"name": "sqlflow", "dag": {}}, {"name": "step-1", "container": {}}, {"name": "step-2", "container": {}}]},
+"status": {"nodes": {%s}}}`
+
+// fakeKFP serves the REST API of the KFP API server of a run run1.
+type fakeKFP struct {
+	mu         sync.Mutex
+	submitted  map[string]interface{}
+	status     string
+	nodes      string
+	terminated bool
+}
+
+func (f *fakeKFP) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case r.Method == "POST" && r.URL.Path == "/apis/v1beta1/runs":
+		b, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(b, &f.submitted)
+		w.Write([]byte(`{"run": {"id": "run1"}}`))
+	case r.Method == "GET" && r.URL.Path == "/apis/v1beta1/runs/run1":
+		manifest := ""
+		if f.nodes != "" {
+			manifest = fmt.Sprintf(testManifest, f.nodes)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"run":              map[string]string{"id": "run1", "status": f.status},
+			"pipeline_runtime": map[string]string{"workflow_manifest": manifest},
+		})
+	case r.Method == "POST" && r.URL.Path == "/apis/v1beta1/runs/run1/terminate":
+		f.terminated = true
+		w.Write([]byte(`{}`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeKFP) set(status, nodes string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.status, f.nodes = status, nodes
+}
+
+func TestKFPWorkflow(t *testing.T) {
+	a := assert.New(t)
+	f := &fakeKFP{}
+	ts := httptest.NewServer(f)
+	defer ts.Close()
+	os.Setenv("SQLFLOW_WORKFLOW_KFP_ENDPOINT", ts.URL)
+	os.Setenv("SQLFLOW_WORKFLOW_KFP_EXPERIMENT", "exp1")
+	os.Setenv("SQLFLOW_WORKFLOW_LOGVIEW_ENDPOINT", "http://localhost:8080")
+	defer os.Unsetenv("SQLFLOW_WORKFLOW_KFP_ENDPOINT")
+	defer os.Unsetenv("SQLFLOW_WORKFLOW_KFP_EXPERIMENT")
+	defer os.Unsetenv("SQLFLOW_WORKFLOW_LOGVIEW_ENDPOINT")
+	podLogs = func(namespace, pod string) ([]string, error) {
+		a.Equal("kubeflow", namespace)
+		return []string{"SQLFlow Step Execute:", `head: {column_names: "` + pod + `"}`}, nil
+	}
+
+	wf := &Workflow{}
+	id, e := wf.Submit("kind: Workflow")
+	a.NoError(e)
+	a.Equal("run1", id)
+	a.Equal(map[string]interface{}{"workflow_manifest": "kind: Workflow"}, f.submitted["pipeline_spec"])
+	a.Contains(f.submitted, "resource_references")
+
+	// pending before the workflow is created
+	phase, _, e := wf.Status(id)
+	a.NoError(e)
+	a.Equal(phasePending, phase)
+	resp, e := wf.Fetch(wfrsp.NewFetchRequest(id, "", ""))
+	a.NoError(e)
+	a.False(resp.Eof)
+
+	f.set("Running", `"n1": {"id": "pod1", "displayName": "step-1", "type": "Pod", "phase": "Running"}`)
+	phase, _, e = wf.Status(id)
+	a.NoError(e)
+	a.Equal(phaseRunning, phase)
+	resp, e = wf.Fetch(resp.UpdatedFetchSince)
+	a.NoError(e)
+	a.Equal("step-1", resp.UpdatedFetchSince.StepId)
+	a.Equal(phaseRunning, resp.UpdatedFetchSince.StepPhase)
+	rs := resp.Responses.Response
+	a.Equal(2, len(rs))
+	a.Equal("SQLFlow Step: [1/2] Log: http://localhost:8080/#/runs/details/run1", rs[0].GetMessage().GetMessage())
+	a.Equal("SQLFlow Step: [1/2] Status: Running", rs[1].GetMessage().GetMessage())
+
+	// move to the next step after the first one succeeded
+	f.set("Running", `"n1": {"id": "pod1", "displayName": "step-1", "type": "Pod", "phase": "Succeeded"}`)
+	resp, e = wf.Fetch(resp.UpdatedFetchSince)
+	a.NoError(e)
+	a.Equal("step-2", resp.UpdatedFetchSince.StepId)
+	a.Equal("", resp.UpdatedFetchSince.StepPhase)
+	rs = resp.Responses.Response
+	a.Equal(3, len(rs))
+	a.Equal("SQLFlow Step: [1/2] Status: Succeeded", rs[0].GetMessage().GetMessage())
+	a.Equal([]string{"pod1"}, rs[1].GetHead().GetColumnNames())
+	a.NotNil(rs[2].GetEoe())
+
+	// the next step is pending before its pod is created
+	resp, e = wf.Fetch(resp.UpdatedFetchSince)
+	a.NoError(e)
+	a.Equal("step-2", resp.UpdatedFetchSince.StepId)
+	a.Equal(0, len(resp.Responses.Response))
+
+	f.set("Succeeded", `"n1": {"id": "pod1", "displayName": "step-1", "type": "Pod", "phase": "Succeeded"},
+"n2": {"id": "pod2", "displayName": "step-2", "type": "Pod", "phase": "Succeeded"}`)
+	resp, e = wf.Fetch(resp.UpdatedFetchSince)
+	a.NoError(e)
+	a.True(resp.Eof)
+	phase, _, e = wf.Status(id)
+	a.NoError(e)
+	a.Equal(phaseSucceeded, phase)
+
+	a.NoError(wf.Terminate(id))
+	a.True(f.terminated)
+}
+
+func TestKFPFetchFailed(t *testing.T) {
+	a := assert.New(t)
+	f := &fakeKFP{}
+	ts := httptest.NewServer(f)
+	defer ts.Close()
+	os.Setenv("SQLFLOW_WORKFLOW_KFP_ENDPOINT", ts.URL)
+	defer os.Unsetenv("SQLFLOW_WORKFLOW_KFP_ENDPOINT")
+	podLogs = func(namespace, pod string) ([]string, error) {
+		return []string{`message: {message: "runtime error"}`}, nil
+	}
+
+	wf := &Workflow{}
+	f.set("Failed", `"n1": {"id": "pod1", "displayName": "step-1", "type": "Pod", "phase": "Error"}`)
+	resp, e := wf.Fetch(wfrsp.NewFetchRequest("run1", "step-1", phaseRunning))
+	a.Error(e)
+	a.Contains(e.Error(), "SQLFlow Step: [1/2] Failed")
+	a.Contains(e.Error(), "runtime error")
+	a.False(resp.Eof)
+
+	// the run failed before creating the pod of the step
+	_, e = wf.Fetch(wfrsp.NewFetchRequest("run1", "step-2", ""))
+	a.Error(e)
+	_, _, e = wf.Status("run2")
+	a.Error(e)
+}
+
+func TestKFPStepLogs(t *testing.T) {
+	a := assert.New(t)
+	f := &fakeKFP{}
+	ts := httptest.NewServer(f)
+	defer ts.Close()
+	os.Setenv("SQLFLOW_WORKFLOW_KFP_ENDPOINT", ts.URL)
+	defer os.Unsetenv("SQLFLOW_WORKFLOW_KFP_ENDPOINT")
+	podLogs = func(namespace, pod string) ([]string, error) {
+		return []string{"epoch 1", "epoch 2", "epoch 3"}, nil
+	}
+
+	wf := &Workflow{}
+	logs, phase, e := wf.StepLogs("run1", 1, 0)
+	a.NoError(e)
+	a.Equal("", phase)
+	a.Equal(0, len(logs))
+
+	f.set("Running", `"n1": {"id": "pod1", "displayName": "step-1", "type": "Pod", "phase": "Running"}`)
+	logs, phase, e = wf.StepLogs("run1", 1, 1)
+	a.NoError(e)
+	a.Equal(phaseRunning, phase)
+	a.Equal([]string{"epoch 2", "epoch 3"}, logs)
+	logs, phase, e = wf.StepLogs("run1", 1, 3)
+	a.NoError(e)
+	a.Equal(0, len(logs))
+	logs, phase, e = wf.StepLogs("run1", 1, -1)
+	a.NoError(e)
+	a.Equal(phaseRunning, phase)
+	a.Equal(0, len(logs))
+
+	// the second step hasn't started
+	logs, phase, e = wf.StepLogs("run1", 2, 0)
+	a.NoError(e)
+	a.Equal("", phase)
+	_, _, e = wf.StepLogs("run1", 3, 0)
+	a.Error(e)
+}
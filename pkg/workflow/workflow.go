@@ -22,8 +22,10 @@ import (
 	"sqlflow.org/sqlflow/pkg/parser"
 	pb "sqlflow.org/sqlflow/pkg/proto"
 	"sqlflow.org/sqlflow/pkg/sql"
+	"sqlflow.org/sqlflow/pkg/workflow/airflow"
 	"sqlflow.org/sqlflow/pkg/workflow/argo"
 	"sqlflow.org/sqlflow/pkg/workflow/couler"
+	"sqlflow.org/sqlflow/pkg/workflow/kfp"
 )
 
 // Codegen generates workflow YAML
@@ -36,6 +38,16 @@ type Codegen interface {
 type Workflow interface {
 	Submit(string) (string, error)
 	Fetch(*pb.FetchRequest) (*pb.FetchResponse, error)
+	// Status returns the phase and the message of the status of a
+	// workflow, where the phase is one of Pending, Running, Succeeded
+	// and Failed.
+	Status(string) (string, string, error)
+	Terminate(string) error
+	// StepLogs returns the logs of the n-th step of a workflow, 1-based,
+	// after the first offset lines, and the phase of the step, which is
+	// "" if the step hasn't started. The phase is read before the logs,
+	// so the logs are complete if the step has finished.
+	StepLogs(id string, n, offset int) ([]string, string, error)
 }
 
 // New returns Codegen and Workflow instance
@@ -43,11 +55,31 @@ func New(backend string) (Codegen, Workflow, error) {
 	if backend == "couler" {
 		return &couler.Codegen{}, &argo.Workflow{}, nil
 	}
+	if backend == "airflow" {
+		return &airflow.Codegen{}, &airflow.Workflow{}, nil
+	}
+	if backend == "kfp" {
+		return &kfp.Codegen{}, &kfp.Workflow{}, nil
+	}
 	return nil, nil, fmt.Errorf("the specified backend: %s has not support", backend)
 }
 
 // Run compiles a SQL program to IRs and submits workflow YAML to Kubernetes
 func Run(backend string, sqlProgram string, session *pb.Session, logger *log.Logger) (string, error) {
+	yaml, e := Compile(backend, sqlProgram, session, logger)
+	if e != nil {
+		return "", e
+	}
+	_, wf, e := New(backend)
+	if e != nil {
+		return "", e
+	}
+	return wf.Submit(yaml)
+}
+
+// Compile compiles a SQL program to IRs and returns the workflow YAML
+// without submitting it
+func Compile(backend string, sqlProgram string, session *pb.Session, logger *log.Logger) (string, error) {
 	driverName, _, e := database.ParseURL(session.DbConnStr)
 	if e != nil {
 		return "", e
@@ -63,8 +95,8 @@ func Run(backend string, sqlProgram string, session *pb.Session, logger *log.Log
 		return "", e
 	}
 
-	// New Codegen and workflow operator instance according to the backend identifier
-	cg, wf, e := New(backend)
+	// New Codegen instance according to the backend identifier
+	cg, _, e := New(backend)
 	if e != nil {
 		return "", e
 	}
@@ -76,10 +108,5 @@ func Run(backend string, sqlProgram string, session *pb.Session, logger *log.Log
 	}
 
 	// translate Couler program to workflow YAML
-	yaml, e := cg.GenYAML(py)
-	if e != nil {
-		return "", e
-	}
-
-	return wf.Submit(yaml)
+	return cg.GenYAML(py)
 }
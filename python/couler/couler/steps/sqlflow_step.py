@@ -13,6 +13,7 @@
 '''This Module implements SQLFlow Step in Couler'''
 
 import couler.argo as couler
+import pyaml
 
 
 def escape_sql(original_sql):
@@ -33,3 +34,79 @@ def sqlflow(sql,
                          env=env,
                          secret=secret,
                          resources=resources)
+
+
+def tfjob_manifest(sql,
+                   image="sqlflow/sqlflow",
+                   env=None,
+                   secret=None,
+                   resources=None,
+                   num_workers=1,
+                   num_ps=0):
+    '''tfjob_manifest returns the TFJob of the Kubeflow tf-operator which
+    runs sql in a chief, num_workers - 1 workers, and num_ps parameter
+    servers. The tf-operator sets TF_CONFIG in each pod for the distributed
+    training of TensorFlow.
+    '''
+    container = {
+        "name": "tensorflow",
+        "image": image,
+        "command": ["bash", "-c",
+                    '''step -e "%s"''' % escape_sql(sql)],
+    }
+    container_env = []
+    if env is not None:
+        container_env.extend(couler._convert_dict_to_list(env))
+    if secret is not None:
+        container_env.extend(couler._convert_secret_to_list(secret))
+    if container_env:
+        container["env"] = container_env
+    if resources is not None:
+        container["resources"] = couler._resources(resources)
+
+    def replica(replicas):
+        return {
+            "replicas": replicas,
+            "restartPolicy": "Never",
+            "template": {
+                "spec": {
+                    "containers": [container]
+                }
+            },
+        }
+
+    specs = {"Chief": replica(1)}
+    if num_workers > 1:
+        specs["Worker"] = replica(num_workers - 1)
+    if num_ps > 0:
+        specs["PS"] = replica(num_ps)
+    return {
+        "apiVersion": "kubeflow.org/v1",
+        "kind": "TFJob",
+        "metadata": {
+            "generateName": "sqlflow-tfjob-"
+        },
+        "spec": {
+            "cleanPodPolicy": "Running",
+            "tfReplicaSpecs": specs
+        },
+    }
+
+
+def sqlflow_tfjob(sql,
+                  image="sqlflow/sqlflow",
+                  env=None,
+                  secret=None,
+                  resources=None,
+                  num_workers=1,
+                  num_ps=0):
+    '''sqlflow_tfjob appends a workflow step which creates the TFJob of
+    tfjob_manifest for the distributed training, and succeeds or fails with
+    the chief of the TFJob.
+    '''
+    manifest = tfjob_manifest(sql, image, env, secret, resources,
+                              num_workers, num_ps)
+    couler.run_job(
+        manifest=pyaml.dump(manifest),
+        success_condition="status.replicaStatuses.Chief.succeeded > 0",
+        failure_condition="status.replicaStatuses.Chief.failed > 0")
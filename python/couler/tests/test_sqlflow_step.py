@@ -24,3 +24,24 @@ class TestSQLFlowStep(unittest.TestCase):
         actual = steps.sqlflow_step.escape_sql(special_char_sql)
         expected = r'\`\$\"\\;'
         self.assertEqual(actual, expected)
+
+    def test_tfjob_manifest(self):
+        '''Test the TFJob of the distributed training'''
+        manifest = steps.sqlflow_step.tfjob_manifest(
+            "SELECT 1;", env={"SQLFLOW_DATASOURCE": "mysql://"},
+            num_workers=3, num_ps=2)
+        self.assertEqual(manifest["kind"], "TFJob")
+        specs = manifest["spec"]["tfReplicaSpecs"]
+        self.assertEqual(specs["Chief"]["replicas"], 1)
+        self.assertEqual(specs["Worker"]["replicas"], 2)
+        self.assertEqual(specs["PS"]["replicas"], 2)
+        container = specs["Chief"]["template"]["spec"]["containers"][0]
+        self.assertEqual(container["command"][-1], 'step -e "SELECT 1;"')
+        self.assertEqual(container["env"], [{
+            "name": "SQLFLOW_DATASOURCE",
+            "value": "mysql://"
+        }])
+
+        manifest = steps.sqlflow_step.tfjob_manifest("SELECT 1;",
+                                                     num_workers=2)
+        self.assertNotIn("PS", manifest["spec"]["tfReplicaSpecs"])
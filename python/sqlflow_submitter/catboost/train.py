@@ -0,0 +1,99 @@
+# Copyright 2020 The SQLFlow Authors. All rights reserved.
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import json
+
+import catboost
+from sqlflow_submitter import cross_validation, db, tuning
+from sqlflow_submitter.warm_start import warm_start_file
+
+MODEL_FILE = "my_model"
+META_FILE = "model_meta.json"
+
+
+def model_class(estimator):
+    """Returns catboost.CatBoostClassifier for catboost.CatBoostClassifier,
+    or catboost.CatBoostRegressor for catboost.CatBoostRegressor."""
+    if estimator.lower().endswith("classifier"):
+        return catboost.CatBoostClassifier
+    return catboost.CatBoostRegressor
+
+
+def fit_predictor(estimator, model_params):
+    """Returns a fit_predict function of cross_validation.kfold_metrics,
+    which trains a CatBoost model by the parameters."""
+    def fit_predict(train_x, train_y, test_x):
+        model = model_class(estimator)(**model_params)
+        model.fit(train_x, train_y)
+        scores = model.predict_proba(test_x) if hasattr(
+            model, "predict_proba") else None
+        return model.predict(test_x).ravel(), scores
+
+    return fit_predict
+
+
+def train(datasource,
+          select,
+          validation_select,
+          estimator,
+          model_params,
+          train_params,
+          feature_metas,
+          feature_column_names,
+          label_meta,
+          cv_params={},
+          tuning_params={},
+          warm_start=False):
+    x, y, _ = db.read_numpy(datasource, select, feature_metas,
+                            feature_column_names, label_meta)
+    vx, vy = None, None
+    if len(validation_select.strip()) > 0:
+        vx, vy, _ = db.read_numpy(datasource, validation_select,
+                                  feature_metas, feature_column_names,
+                                  label_meta)
+    if tuning_params:
+
+        def score(params):
+            fit_predict = fit_predictor(estimator, params["model_params"])
+            return tuning.validation_score(tuning_params["metric"],
+                                           fit_predict, x, y, vx, vy,
+                                           cv_params.get("kfold", 0))
+
+        best = tuning.tune(tuning_params, {
+            "model_params": model_params,
+            "train_params": train_params
+        }, score)
+        model_params, train_params = best["model_params"], best["train_params"]
+    if cv_params.get("kfold", 0) > 1:
+        print("Start cross-validating CatBoost model...")
+        cross_validation.cross_validate(x, y, cv_params["kfold"],
+                                        cv_params["metrics"],
+                                        fit_predictor(estimator, model_params))
+    early_stopping_rounds = train_params.get("early_stopping_rounds", 0)
+    print("Start training CatBoost model...")
+    model = model_class(estimator)(**model_params)
+    model.fit(x,
+              y,
+              eval_set=(vx, vy) if vx is not None else None,
+              early_stopping_rounds=early_stopping_rounds
+              if early_stopping_rounds > 0 else None,
+              init_model=warm_start_file(MODEL_FILE) if warm_start else None)
+    model.save_model(MODEL_FILE)
+    print("Evaluation result: %s" % model.get_best_score())
+    with open(META_FILE, "w") as f:
+        json.dump(
+            {
+                "estimator": estimator,
+                "model_params": model_params,
+                "catboost_version": catboost.__version__,
+            }, f)
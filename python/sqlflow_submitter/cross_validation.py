@@ -0,0 +1,65 @@
+# Copyright 2020 The SQLFlow Authors. All rights reserved.
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import json
+
+import numpy as np
+from sqlflow_submitter import evaluation
+
+# CV_FILE records the result of the cross-validation in the model
+# directory, which pkg/model saves into the metadata of the model.
+CV_FILE = "cross_validation.json"
+
+
+def kfold_indices(n, kfold, seed=0):
+    """Shuffles the indices of n rows by seed, and splits them into kfold
+    folds of almost equal sizes."""
+    return np.array_split(np.random.RandomState(seed).permutation(n), kfold)
+
+
+def kfold_metrics(x, y, kfold, metric_names, fit_predict):
+    """Runs the k-fold cross-validation on x and y, and returns the mean and
+    the standard deviation of each metric over the folds. fit_predict(
+    train_x, train_y, test_x) trains a model and returns its predictions of
+    test_x, and the probabilities of the classes, or None, like the
+    arguments of evaluation.evaluate_metrics."""
+    if kfold > len(x):
+        raise ValueError("validation.kfold=%d is more than the %d rows" %
+                         (kfold, len(x)))
+    folds = kfold_indices(len(x), kfold)
+    fold_results = []
+    for i, test in enumerate(folds):
+        train = np.concatenate([f for j, f in enumerate(folds) if j != i])
+        preds, scores = fit_predict(x[train], y[train], x[test])
+        results = evaluation.evaluate_metrics(metric_names, y[test], preds,
+                                              scores)
+        print("Cross-validation fold %d/%d: %s" % (i + 1, kfold, results))
+        fold_results.append(results)
+    metrics = dict()
+    for name in metric_names:
+        values = [float(r[name]) for r in fold_results]
+        metrics[name] = {
+            "mean": float(np.mean(values)),
+            "std": float(np.std(values))
+        }
+    return metrics
+
+
+def cross_validate(x, y, kfold, metric_names, fit_predict):
+    """Runs kfold_metrics, writes the result into CV_FILE, and returns
+    it."""
+    metrics = kfold_metrics(x, y, kfold, metric_names, fit_predict)
+    print("Cross-validation result: %s" % metrics)
+    with open(CV_FILE, "w") as f:
+        json.dump({"kfold": kfold, "metrics": metrics}, f)
+    return metrics
@@ -13,6 +13,9 @@
 
 import contextlib
 import copy
+import datetime
+import json
+import math
 import os
 import re
 
@@ -74,8 +77,64 @@ def parseMaxComputeDSN(dsn):
     return user, passwd, address, config["curr_project"]
 
 
+def parsePostgresDSN(dsn):
+    # usr:pswd@127.0.0.1:5432/mydb?sslmode=disable
+    user_passwd, host, port, database, config_str = re.findall(
+        "^(.*)@([-.a-zA-Z0-9_]*):?([0-9]*)/(\w*)(\?.*)?$", dsn)[0]
+    user, passwd = user_passwd.split(":")
+    config = {}
+    if len(config_str) > 1:
+        for c in config_str[1:].split("&"):
+            k, v = c.split("=")
+            config[k] = v
+    return user, passwd, host, port or "5432", database, config
+
+
+def parseClickHouseDSN(dsn):
+    # 127.0.0.1:9000?username=usr&password=pswd&database=mydb
+    address, config_str = re.findall("^([-.a-zA-Z0-9_:]*)(\?.*)?$", dsn)[0]
+    if len(address.split(":")) > 1:
+        host, port = address.split(":")
+    else:
+        host, port = address, "9000"
+    config = {}
+    if len(config_str) > 1:
+        for c in config_str[1:].split("&"):
+            k, v = c.split("=")
+            config[k] = v
+    user = config.pop("username", "default")
+    passwd = config.pop("password", "")
+    database = config.pop("database", "default")
+    return user, passwd, host, port, database, config
+
+
+def connect_sqlite(path):
+    # NOTE: Attach the database files next to path like
+    # pkg/database/sqlite.go, so iris.train refers to the table train in
+    # iris.db.
+    import glob
+    import sqlite3
+
+    # NOTE: sqlite3.Connection has no __dict__ for conn.driver.
+    class Connection(sqlite3.Connection):
+        pass
+
+    conn = sqlite3.connect(path, factory=Connection)
+    files = sorted(glob.glob(os.path.join(os.path.dirname(path), "*.db")))
+    files.sort(key=lambda f: f != path)
+    for f in files:
+        name = os.path.basename(f)[:-len(".db")]
+        if name in ("main", "temp"):
+            continue
+        conn.execute("ATTACH DATABASE ? AS \"%s\"" % name, (f, ))
+    return conn
+
+
 def connect_with_data_source(driver_dsn):
     driver, dsn = driver_dsn.split("://")
+    # NOTE: the options like sqlflow_max_open_conns=10 are for the
+    # connection pool in pkg/database.
+    dsn = re.sub("(?<=[?&])sqlflow_\w+=[^&]*(&|$)", "", dsn).rstrip("?&")
     if driver == "mysql":
         # NOTE: use MySQLdb to avoid bugs like infinite reading:
         # https://bugs.mysql.com/bug.php?id=91971
@@ -86,6 +145,26 @@ def connect_with_data_source(driver_dsn):
                        db=database,
                        host=host,
                        port=int(port))
+    elif driver == "postgres":
+        from psycopg2 import connect
+        user, passwd, host, port, database, config = parsePostgresDSN(dsn)
+        conn = connect(user=user,
+                       password=passwd,
+                       dbname=database,
+                       host=host,
+                       port=int(port),
+                       **config)
+    elif driver == "sqlite3":
+        conn = connect_sqlite(os.path.abspath(dsn.split("?")[0]))
+    elif driver == "clickhouse":
+        from clickhouse_driver.dbapi import connect
+        user, passwd, host, port, database, config = parseClickHouseDSN(dsn)
+        conn = connect(user=user,
+                       password=passwd,
+                       database=database,
+                       host=host,
+                       port=int(port),
+                       **config)
     elif driver == "hive":
         from impala.dbapi import connect
         user, passwd, host, port, database, auth, session_cfg = parseHiveDSN(
@@ -128,6 +207,20 @@ def connect(driver,
                        db=database,
                        host=host,
                        port=int(port))
+    elif driver == "postgres":
+        from psycopg2 import connect
+        return connect(user=user,
+                       password=password,
+                       dbname=database,
+                       host=host,
+                       port=int(port))
+    elif driver == "clickhouse":
+        from clickhouse_driver.dbapi import connect
+        return connect(user=user,
+                       password=password,
+                       database=database,
+                       host=host,
+                       port=int(port))
     elif driver == "hive":
         from impala.dbapi import connect
         conn = connect(user=user,
@@ -146,8 +239,176 @@ def connect(driver,
     raise ValueError("unrecognized database driver: %s" % driver)
 
 
+# The periods of the time parts, keep consistent with ir.TimePeriods in Go.
+# The part "year" is not periodic.
+TIME_PERIODS = {
+    "minute": 60,
+    "hour": 24,
+    "dayofweek": 7,
+    "dayofmonth": 31,
+    "dayofyear": 366,
+    "month": 12,
+    "year": 0,
+}
+
+
+def parse_time(raw_val):
+    """Returns the datetime of raw_val, which is a datetime, a date, the
+    seconds since the epoch, or a string like "2020-08-01 10:00:00" or
+    "2020-08-01". The fraction of seconds and the time zone in the string
+    are ignored, so the parts are of the local time in the string.
+    """
+    if isinstance(raw_val, datetime.datetime):
+        return raw_val
+    if isinstance(raw_val, datetime.date):
+        return datetime.datetime(raw_val.year, raw_val.month, raw_val.day)
+    if isinstance(raw_val, (int, float)):
+        return datetime.datetime.utcfromtimestamp(raw_val)
+    if isinstance(raw_val, bytes):
+        raw_val = raw_val.decode("utf-8")
+    val = raw_val.strip().replace("T", " ")
+    val = re.sub(r"(\.\d+)?(Z|[+-]\d{2}:?\d{2})?$", "", val)
+    for fmt in ["%Y-%m-%d %H:%M:%S", "%Y-%m-%d %H:%M", "%Y-%m-%d"]:
+        try:
+            return datetime.datetime.strptime(val, fmt)
+        except ValueError:
+            pass
+    raise ValueError("cannot parse the time {}".format(raw_val))
+
+
+def time_part(t, part):
+    if part == "minute":
+        return t.minute
+    elif part == "hour":
+        return t.hour
+    elif part == "dayofweek":
+        return t.weekday()  # 0 for Monday
+    elif part == "dayofmonth":
+        return t.day
+    elif part == "dayofyear":
+        return t.timetuple().tm_yday
+    elif part == "month":
+        return t.month
+    elif part == "year":
+        return t.year
+    raise ValueError("unrecognized time part {}".format(part))
+
+
+def read_time_feature(raw_val, parts, cyclical):
+    """Extracts the parts of the time raw_val as a float vector. If cyclical,
+    each periodic part p of the period T is encoded as sin(2*pi*p/T) and
+    cos(2*pi*p/T).
+    """
+    t = parse_time(raw_val)
+    values = []
+    for part in parts:
+        p = time_part(t, part)
+        period = TIME_PERIODS[part]
+        if cyclical and period > 0:
+            angle = 2 * math.pi * p / period
+            values.extend([math.sin(angle), math.cos(angle)])
+        else:
+            values.append(p)
+    return np.array(values, dtype=np.float32)
+
+
+def read_array_feature(raw_val, dtype):
+    """Returns the numbers in the array raw_val, which is a list returned by
+    the driver, or a string like the Hive one "[0.1,0.2]" or the PostgreSQL
+    one "{0.1,0.2}".
+    """
+    if isinstance(raw_val, bytes):
+        raw_val = raw_val.decode("utf-8")
+    if isinstance(raw_val, str):
+        return np.fromstring(raw_val.strip().strip("[]{}"),
+                             dtype=dtype,
+                             sep=",")
+    return np.array(raw_val, dtype=dtype)
+
+
+def json_value(doc, path):
+    """Returns the value at path, like "$.user.age" or "$.scores[0]", in doc,
+    or None if there is no such value. Keep consistent with jsonValue in
+    pkg/step/feature.
+    """
+    steps = re.findall(r"\.([^.\[\]]+)|\[([0-9]+)\]", path[1:])
+    v = doc
+    for key, index in steps:
+        if key:
+            if not isinstance(v, dict):
+                return None
+            v = v.get(key)
+        else:
+            if not isinstance(v, list) or int(index) >= len(v):
+                return None
+            v = v[int(index)]
+    return v
+
+
+def read_json_feature(raw_val, paths):
+    """Extracts the numbers at paths in the JSON string raw_val as a float
+    vector. The missing values are read as 0.
+    """
+    if isinstance(raw_val, bytes):
+        raw_val = raw_val.decode("utf-8")
+    # NOTE: psycopg2 returns the JSON values as parsed
+    doc = json.loads(raw_val) if isinstance(raw_val, str) else raw_val
+    values = []
+    for p in paths:
+        v = json_value(doc, p)
+        values.append(0 if v is None else float(v))
+    return np.array(values, dtype=np.float32)
+
+
+def tokenize(text, tokenizer):
+    """Splits text into tokens by tokenizer. Keep consistent with tokenize in
+    pkg/step/feature.
+    """
+    if tokenizer == "whitespace":
+        return text.split()
+    elif tokenizer == "word":
+        return re.findall(r"\w+", text.lower())
+    elif tokenizer == "char":
+        return [c for c in text if not c.isspace()]
+    raise ValueError("unsupported tokenizer {}".format(tokenizer))
+
+
+# The token ids of the vocabularies, keyed by the ids of the vocabularies.
+_token_ids = dict()
+
+
+def read_text_feature(raw_val, feature_spec):
+    """Returns the ids of the tokens in the text raw_val, padded by -1 to the
+    length feature_spec["shape"][0]. The ids of the tokens in the sorted
+    vocabulary are from 1, and 0 is for the out-of-vocabulary tokens.
+    """
+    vocabulary = feature_spec["vocabulary"]
+    cached = _token_ids.get(id(vocabulary))
+    if cached is None or cached[0] is not vocabulary:
+        ids = dict((t, i + 1) for i, t in enumerate(sorted(vocabulary)))
+        cached = _token_ids[id(vocabulary)] = (vocabulary, ids)
+    ids = cached[1]
+    if isinstance(raw_val, bytes):
+        raw_val = raw_val.decode("utf-8")
+    max_len = feature_spec["shape"][0]
+    tokens = tokenize(raw_val or "", feature_spec["tokenizer"])[:max_len]
+    values = [ids.get(t, 0) for t in tokens]
+    values.extend([-1] * (max_len - len(values)))
+    return np.array(values, dtype=np.int64)
+
+
 def read_feature(raw_val, feature_spec, feature_name):
     # FIXME(typhoonzero): Should use correct dtype here.
+    if feature_spec.get("tokenizer"):
+        return read_text_feature(raw_val, feature_spec)
+    if feature_spec.get("json_paths"):
+        return read_json_feature(raw_val, feature_spec["json_paths"])
+    if feature_spec.get("is_array"):
+        return read_array_feature(
+            raw_val, float if feature_spec["dtype"] == "float32" else int)
+    if feature_spec.get("time_parts"):
+        return read_time_feature(raw_val, feature_spec["time_parts"],
+                                 feature_spec.get("cyclical", False))
     if feature_spec["is_sparse"]:
         indices = np.fromstring(raw_val,
                                 dtype=int,
@@ -173,6 +434,73 @@ def read_feature(raw_val, feature_spec, feature_name):
         return (raw_val, )
 
 
+def read_row(row, field_names, label_idx, feature_column_names, label_spec,
+             feature_specs):
+    """Returns the features and the label in the row of the fields
+    field_names as db_generator yields them."""
+    # NOTE: If there is no label clause in the extended SQL, the default label value would
+    # be -1, the Model implementation can determine use it or not.
+    label = row[label_idx] if label_idx is not None else -1
+    if label_spec and label_spec["delimiter"] != "":
+        if label_spec["dtype"] == "float32":
+            label = np.fromstring(label,
+                                  dtype=float,
+                                  sep=label_spec["delimiter"])
+        elif label_spec["dtype"] == "int64":
+            label = np.fromstring(label,
+                                  dtype=int,
+                                  sep=label_spec["delimiter"])
+    features = []
+    for name in feature_column_names:
+        feature = read_feature(row[field_names.index(name)],
+                               feature_specs[name], name)
+        features.append(feature)
+    if label_idx is None:
+        return (tuple(features), )
+    return tuple(features), label
+
+
+def fetch_blocks(driver, conn, statement, fetch_size=128):
+    """Yields the field names and the rows of each block of fetch_size rows
+    of the result of statement."""
+    if driver == "hive":
+        cursor = conn.cursor(configuration=conn.session_cfg)
+    else:
+        cursor = conn.cursor()
+    if driver == "clickhouse":
+        # NOTE: stream the result set by blocks of fetch_size rows,
+        # or clickhouse_driver fetches all rows at once.
+        cursor.set_stream_results(True, fetch_size)
+    cursor.execute(statement)
+    if driver == "hive":
+        field_names = None if cursor.description is None \
+            else [i[0][i[0].find('.') + 1:] for i in cursor.description]
+    else:
+        field_names = None if cursor.description is None \
+            else [i[0] for i in cursor.description]
+    while True:
+        rows = cursor.fetchmany(size=fetch_size)
+        if not rows:
+            break
+        # NOTE: keep the connection while training or connection will lost if no activities appear.
+        if driver == "mysql":
+            conn.ping(True)
+        yield field_names, rows
+        if len(rows) < fetch_size:
+            break
+    cursor.close()
+
+
+def label_index(field_names, label_spec):
+    if not label_spec:
+        return None
+    try:
+        return field_names.index(label_spec["feature_name"])
+    except ValueError:
+        # NOTE(typhoonzero): For clustering model, label_column_name may not in field_names when predicting.
+        return None
+
+
 def db_generator(driver,
                  conn,
                  statement,
@@ -181,58 +509,13 @@ def db_generator(driver,
                  feature_specs,
                  fetch_size=128):
     def reader():
-        if driver == "hive":
-            cursor = conn.cursor(configuration=conn.session_cfg)
-        else:
-            cursor = conn.cursor()
-        cursor.execute(statement)
-        if driver == "hive":
-            field_names = None if cursor.description is None \
-                else [i[0][i[0].find('.') + 1:] for i in cursor.description]
-        else:
-            field_names = None if cursor.description is None \
-                else [i[0] for i in cursor.description]
-        if label_spec:
-            try:
-                label_idx = field_names.index(label_spec["feature_name"])
-            except ValueError:
-                # NOTE(typhoonzero): For clustering model, label_column_name may not in field_names when predicting.
-                label_idx = None
-        else:
-            label_idx = None
-
-        while True:
-            rows = cursor.fetchmany(size=fetch_size)
-            if not rows:
-                break
-            # NOTE: keep the connection while training or connection will lost if no activities appear.
-            if driver == "mysql":
-                conn.ping(True)
+        for field_names, rows in fetch_blocks(driver, conn, statement,
+                                              fetch_size):
+            label_idx = label_index(field_names, label_spec)
             for row in rows:
-                # NOTE: If there is no label clause in the extended SQL, the default label value would
-                # be -1, the Model implementation can determine use it or not.
-                label = row[label_idx] if label_idx is not None else -1
-                if label_spec and label_spec["delimiter"] != "":
-                    if label_spec["dtype"] == "float32":
-                        label = np.fromstring(label,
-                                              dtype=float,
-                                              sep=label_spec["delimiter"])
-                    elif label_spec["dtype"] == "int64":
-                        label = np.fromstring(label,
-                                              dtype=int,
-                                              sep=label_spec["delimiter"])
-                features = []
-                for name in feature_column_names:
-                    feature = read_feature(row[field_names.index(name)],
-                                           feature_specs[name], name)
-                    features.append(feature)
-                if label_idx is None:
-                    yield (tuple(features), )
-                else:
-                    yield tuple(features), label
-            if len(rows) < fetch_size:
-                break
-        cursor.close()
+                yield read_row(row, field_names, label_idx,
+                               feature_column_names, label_spec,
+                               feature_specs)
 
     if driver == "maxcompute":
         from sqlflow_submitter.maxcompute import MaxCompute
@@ -244,6 +527,148 @@ def db_generator(driver,
     return reader
 
 
+def is_plain_column(feature_spec):
+    """Returns true if the column is a number, a string, or a dense vector
+    of delimited numbers, which read_column decodes as a whole."""
+    for key in ["tokenizer", "json_paths", "is_array", "time_parts"]:
+        if feature_spec.get(key):
+            return False
+    return not feature_spec["is_sparse"]
+
+
+def read_column(values, feature_spec, feature_name):
+    """Decodes the values of a column in a block of rows into a NumPy
+    array of a row per value, like stacking read_feature of each value.
+    The values of a plain column without a delimiter are kept as they are
+    in an object array. The columns other than the plain ones, and the
+    dense vectors NumPy can't convert as a whole, like the vectors of
+    different lengths, are decoded by read_feature and returned as the
+    list of the features."""
+    if is_plain_column(feature_spec):
+        try:
+            column = read_plain_column(values, feature_spec)
+            if column is not None:
+                return column
+        except (TypeError, ValueError):
+            pass
+    return [read_feature(v, feature_spec, feature_name) for v in values]
+
+
+def read_plain_column(values, feature_spec):
+    dtype = feature_spec["dtype"]
+    delimiter = feature_spec["delimiter"]
+    if delimiter == "":
+        # NOTE: keep the values as they are like read_feature, so that
+        # feature_to_string writes 1 of an INT column as 1 rather than 1.0.
+        # flatten_column casts them to float32.
+        return np.array(values, dtype=object).reshape(len(values), 1)
+    if dtype not in ["float32", "int64"] or not all(
+            isinstance(v, str) for v in values):
+        return None
+    # NOTE: parse all the vectors of the block by one np.fromstring. The
+    # vectors of different lengths are left to read_feature.
+    n = int(np.prod(feature_spec["shape"]))
+    parsed = np.fromstring(delimiter.join(values),
+                           dtype=float if dtype == "float32" else int,
+                           sep=delimiter)
+    if parsed.size != n * len(values):
+        return None
+    return parsed.reshape(len(values), n)
+
+
+def read_label_column(values, label_spec):
+    if label_spec and label_spec["delimiter"] != "":
+        return np.array([
+            np.fromstring(v,
+                          dtype=float
+                          if label_spec["dtype"] == "float32" else int,
+                          sep=label_spec["delimiter"]) for v in values
+        ])
+    return np.array(values)
+
+
+def db_batch_generator(driver,
+                       conn,
+                       statement,
+                       feature_column_names,
+                       label_spec,
+                       feature_specs,
+                       fetch_size=128):
+    """Returns a generator of the blocks of fetch_size rows of statement,
+    decoded by columns instead of by rows like db_generator. Each block is
+    the list of the features by read_column in the order of
+    feature_column_names, and the labels, or None if there is no label."""
+    def reader():
+        for field_names, rows in fetch_blocks(driver, conn, statement,
+                                              fetch_size):
+            columns = list(zip(*rows))
+            features = [
+                read_column(list(columns[field_names.index(name)]),
+                            feature_specs[name], name)
+                for name in feature_column_names
+            ]
+            label_idx = label_index(field_names, label_spec)
+            labels = None
+            if label_idx is not None:
+                labels = read_label_column(list(columns[label_idx]),
+                                           label_spec)
+            yield features, labels
+
+    def maxcompute_reader():
+        # NOTE: the rows of MaxCompute are decoded by the tunnel reader
+        # already, so the blocks stack the decoded features.
+        gen = db_generator(driver, conn, statement, feature_column_names,
+                           label_spec, feature_specs, fetch_size)
+        block = []
+        for row in gen():
+            block.append(row)
+            if len(block) == fetch_size:
+                yield stack_rows(block, feature_column_names, feature_specs)
+                block = []
+        if block:
+            yield stack_rows(block, feature_column_names, feature_specs)
+
+    if driver == "maxcompute":
+        return maxcompute_reader
+    if driver == "hive":
+        # trip the suffix ';' to avoid the ParseException in hive
+        statement = statement.rstrip(';')
+    return reader
+
+
+def stack_rows(rows, feature_column_names, feature_specs):
+    """Returns the block of the rows yielded by db_generator like
+    db_batch_generator yields it."""
+    features = []
+    for i, name in enumerate(feature_column_names):
+        column = [r[0][i] for r in rows]
+        if is_plain_column(feature_specs[name]):
+            try:
+                if feature_specs[name]["delimiter"] == "":
+                    column = np.array([f[0] for f in column],
+                                      dtype=object).reshape(len(column), 1)
+                else:
+                    column = np.array(column)
+            except (TypeError, ValueError):
+                pass
+        features.append(column)
+    labels = None
+    if len(rows[0]) > 1:
+        labels = np.array([r[1] for r in rows])
+    return features, labels
+
+
+def batch_rows(features):
+    """Returns the rows of the features of a block by db_batch_generator,
+    each of which is the features of a row like db_generator yields."""
+    # NOTE: the values of the plain columns without a delimiter are
+    # returned as the tuples by read_feature.
+    columns = [[tuple(v) for v in c]
+               if isinstance(c, np.ndarray) and c.dtype == object else c
+               for c in features]
+    return list(zip(*columns))
+
+
 def pai_maxcompute_db_generator(table,
                                 feature_column_names,
                                 label_column_name,
@@ -288,6 +713,99 @@ def pai_maxcompute_db_generator(table,
     return reader
 
 
+def flatten_features(features):
+    """Concatenates the features read by db_generator into a float32
+    vector."""
+    return np.concatenate(
+        [np.array(f, dtype=np.float32).ravel() for f in features])
+
+
+def flatten_column(column):
+    """Returns the column of a block by db_batch_generator as a 2-D float32
+    array of a row per value."""
+    if isinstance(column, np.ndarray):
+        return column.astype(np.float32).reshape(len(column), -1)
+    return np.array([flatten_features([f]) for f in column],
+                    dtype=np.float32).reshape(len(column), -1)
+
+
+def feature_to_string(feature, feature_spec):
+    """Converts a feature read by db_generator back to the string to
+    write to a table."""
+    if feature_spec["delimiter"] != "":
+        return feature_spec["delimiter"].join(str(v) for v in feature)
+    return str(feature[0])
+
+
+def read_numpy(datasource, select, feature_specs, feature_column_names,
+               label_spec):
+    """Reads the result of select into memory, and returns the features as
+    a 2-D float32 array, the labels as an array, or None if label_spec is
+    None, and the features of each row like db_generator reads them. The
+    blocks of the rows are decoded by columns by db_batch_generator."""
+    conn = connect_with_data_source(datasource)
+    gen = db_batch_generator(conn.driver, conn, select, feature_column_names,
+                             label_spec, feature_specs)
+    xs, labels, rows = [], [], []
+    for features, block_labels in gen():
+        xs.append(
+            np.concatenate([flatten_column(c) for c in features], axis=1))
+        rows.extend(batch_rows(features))
+        if block_labels is not None:
+            labels.append(block_labels)
+    n = sum(
+        np.prod(feature_specs[name]["shape"])
+        for name in feature_column_names)
+    if not xs:
+        x = np.zeros((0, int(n)), dtype=np.float32)
+    else:
+        x = np.concatenate(xs).reshape(len(rows), int(n))
+    if label_spec is None:
+        return x, None, rows
+    return x, np.concatenate(labels) if labels else np.array([]), rows
+
+
+# The drivers of which buffered_db_writer writes by parallel writers.
+PARALLEL_WRITER_DRIVERS = ["mysql", "postgres", "clickhouse", "maxcompute"]
+
+
+def _new_db_writer(driver, conn, table_name, table_schema, buff_size,
+                   hdfs_namenode_addr, hive_location, hdfs_user, hdfs_pass,
+                   partition):
+    if driver == "maxcompute":
+        return db_writer.MaxComputeDBWriter(conn,
+                                            table_name,
+                                            table_schema,
+                                            buff_size,
+                                            partition=partition)
+    elif driver == "mysql":
+        return db_writer.MySQLDBWriter(conn, table_name, table_schema,
+                                       buff_size)
+    elif driver == "postgres":
+        return db_writer.PostgresDBWriter(conn, table_name, table_schema,
+                                          buff_size)
+    elif driver == "sqlite3":
+        return db_writer.SQLiteDBWriter(conn, table_name, table_schema,
+                                        buff_size)
+    elif driver == "clickhouse":
+        return db_writer.ClickHouseDBWriter(conn, table_name, table_schema,
+                                            buff_size)
+    elif driver == "hive":
+        return db_writer.HiveDBWriter(conn,
+                                      table_name,
+                                      table_schema,
+                                      buff_size,
+                                      hdfs_namenode_addr=hdfs_namenode_addr,
+                                      hive_location=hive_location,
+                                      hdfs_user=hdfs_user,
+                                      hdfs_pass=hdfs_pass,
+                                      partition=partition)
+    elif driver == "pai_maxcompute":
+        return db_writer.PAIMaxComputeDBWriter(table_name, table_schema,
+                                               buff_size)
+    raise ValueError("unrecognized database driver: %s" % driver)
+
+
 @contextlib.contextmanager
 def buffered_db_writer(driver,
                        conn,
@@ -297,28 +815,39 @@ def buffered_db_writer(driver,
                        hdfs_namenode_addr="",
                        hive_location="",
                        hdfs_user="",
-                       hdfs_pass=""):
-    if driver == "maxcompute":
-        w = db_writer.MaxComputeDBWriter(conn, table_name, table_schema,
-                                         buff_size)
-    elif driver == "mysql":
-        w = db_writer.MySQLDBWriter(conn, table_name, table_schema, buff_size)
-    elif driver == "hive":
-        w = db_writer.HiveDBWriter(conn,
-                                   table_name,
-                                   table_schema,
-                                   buff_size,
-                                   hdfs_namenode_addr=hdfs_namenode_addr,
-                                   hive_location=hive_location,
-                                   hdfs_user=hdfs_user,
-                                   hdfs_pass=hdfs_pass)
-    elif driver == "pai_maxcompute":
-        w = db_writer.PAIMaxComputeDBWriter(table_name, table_schema,
-                                            buff_size)
-    else:
-        raise ValueError("unrecognized database driver: %s" % driver)
-
+                       hdfs_pass="",
+                       num_writers=1,
+                       datasource="",
+                       partition=""):
+    """Yields a writer writing rows to table_name in batches of buff_size
+    rows. For the drivers in PARALLEL_WRITER_DRIVERS, num_writers writers
+    write in parallel, each of which but the first connects to datasource.
+    The writers of Hive and MaxCompute write to the partition like
+    "dt=20200601" of table_name if it's not empty.
+    """
+    conns = [conn]
+    if num_writers > 1:
+        if driver in PARALLEL_WRITER_DRIVERS and datasource != "":
+            conns += [
+                connect_with_data_source(datasource)
+                for _ in range(num_writers - 1)
+            ]
+        else:
+            print("%s doesn't support parallel writers, using one writer" %
+                  driver)
+    writers = [
+        _new_db_writer(driver, c, table_name, table_schema, buff_size,
+                       hdfs_namenode_addr, hive_location, hdfs_user,
+                       hdfs_pass, partition) for c in conns
+    ]
+    w = db_writer.ParallelDBWriter(writers, table_name, buff_size)
     try:
         yield w
     finally:
-        w.close()
+        try:
+            w.close()
+        finally:
+            # NOTE: the connections of MaxCompute have no close.
+            for c in conns[1:]:
+                if hasattr(c, "close"):
+                    c.close()
@@ -11,15 +11,23 @@
 # See the License for the specific language governing permissions and
 # limitations under the License.
 
+import datetime
 import os
+import sqlite3
+import tempfile
 from unittest import TestCase
 
+import numpy as np
 import tensorflow as tf
 from odps import ODPS, tunnel
-from sqlflow_submitter.db import (buffered_db_writer, connect,
-                                  connect_with_data_source, db_generator,
+from sqlflow_submitter.db import (batch_rows, buffered_db_writer, connect,
+                                  connect_with_data_source, db_batch_generator,
+                                  db_generator, feature_to_string,
+                                  flatten_column, parseClickHouseDSN,
                                   parseHiveDSN, parseMaxComputeDSN,
-                                  parseMySQLDSN)
+                                  parseMySQLDSN, parsePostgresDSN,
+                                  read_column, read_feature, read_numpy)
+from sqlflow_submitter.db_writer.parallel import ParallelDBWriter
 
 
 def _execute_maxcompute(conn, statement):
@@ -111,6 +119,24 @@ class TestDB(TestCase):
                 hive_location="/sqlflow")
             conn.close()
 
+    def test_sqlite(self):
+        # NOTE: SQLite needs no server, so the test always runs.
+        driver = "sqlite3"
+        with tempfile.TemporaryDirectory() as d:
+            for name in ["iris", "test_db"]:
+                sqlite3.connect(os.path.join(d, name + ".db")).close()
+            conn = connect_with_data_source("sqlite3://" +
+                                            os.path.join(d, "iris.db"))
+            self._do_test(driver, conn)
+            # test_db.db is attached as test_db.
+            execute(driver, conn, "create table test_db.tbl (label int)")
+            with buffered_db_writer(driver, conn, "test_db.tbl",
+                                    ["label"]) as w:
+                w.write((1, ))
+            self.assertEqual((["label"], [[1]]),
+                             execute(driver, conn, "select * from test_db.tbl"))
+            conn.close()
+
     def _do_test_hive_specified_db(self,
                                    driver,
                                    conn,
@@ -259,6 +285,126 @@ class TestGenerator(TestCase):
             self.assertEqual(len([g for g in gen()]), 10)
 
 
+class TestBatchGenerator(TestCase):
+    feature_specs = {
+        "x": {
+            "feature_name": "x",
+            "delimiter": "",
+            "dtype": "float32",
+            "is_sparse": False,
+            "shape": [1]
+        },
+        "v": {
+            "feature_name": "v",
+            "delimiter": ",",
+            "dtype": "int64",
+            "is_sparse": False,
+            "shape": [3]
+        },
+        "s": {
+            "feature_name": "s",
+            "delimiter": ",",
+            "dtype": "int64",
+            "is_sparse": True,
+            "shape": [10]
+        }
+    }
+    label_spec = {
+        "feature_name": "label",
+        "shape": [],
+        "delimiter": "",
+        "dtype": "int64"
+    }
+
+    def test_read_column(self):
+        x = read_column([1.5, 2, None], self.feature_specs["x"], "x")
+        self.assertEqual((3, 1), x.shape)
+        # The values are kept as read_feature reads them.
+        self.assertEqual([1.5, 2, None], x[:, 0].tolist())
+        self.assertIsInstance(x[1, 0], int)
+        x = flatten_column(x)
+        self.assertEqual(np.float32, x.dtype)
+        self.assertEqual([1.5, 2.0], x[:2, 0].tolist())
+        self.assertTrue(np.isnan(x[2, 0]))
+        v = read_column(["1,2,3", "4,5,6"], self.feature_specs["v"], "v")
+        self.assertEqual([[1, 2, 3], [4, 5, 6]], v.tolist())
+        # The vectors of different lengths are decoded by read_feature.
+        v = read_column(["1,2,3", "4,5"], self.feature_specs["v"], "v")
+        self.assertEqual([1, 2, 3], v[0].tolist())
+        self.assertEqual([4, 5], v[1].tolist())
+        s = read_column(["1,3"], self.feature_specs["s"], "s")
+        self.assertEqual(1, len(s))
+        self.assertEqual([[1], [3]], s[0][0].tolist())
+
+    def test_sqlite(self):
+        with tempfile.TemporaryDirectory() as d:
+            path = os.path.join(d, "iris.db")
+            conn = connect_with_data_source("sqlite3://" + path)
+            execute("sqlite3", conn,
+                    "create table t (x real, v text, s text, label int)")
+            conn.executemany(
+                "insert into t values (?, ?, ?, ?)",
+                [(i + 0.5, "%d,%d,%d" % (i, i + 1, i + 2), "%d" % i, i % 2)
+                 for i in range(5)])
+            conn.commit()
+            names = ["x", "v", "s"]
+            gen = db_batch_generator("sqlite3",
+                                     conn,
+                                     "SELECT * FROM t",
+                                     names,
+                                     self.label_spec,
+                                     self.feature_specs,
+                                     fetch_size=2)
+            blocks = list(gen())
+            self.assertEqual(3, len(blocks))
+            features, labels = blocks[0]
+            self.assertEqual([[0.5], [1.5]], features[0].tolist())
+            self.assertEqual([[0, 1, 2], [1, 2, 3]], features[1].tolist())
+            self.assertEqual([0, 1], labels.tolist())
+
+            # The rows of the blocks are the rows of db_generator.
+            rows = [r for f, _ in blocks for r in batch_rows(f)]
+            gen = db_generator("sqlite3", conn, "SELECT * FROM t", names,
+                               self.label_spec, self.feature_specs)
+            expected = [r[0] for r in gen()]
+            self.assertEqual(len(expected), len(rows))
+            for r, e in zip(rows, expected):
+                self.assertEqual(e[0][0], r[0][0])
+                self.assertEqual(e[1].tolist(), r[1].tolist())
+                self.assertEqual(e[2][0].tolist(), r[2][0].tolist())
+
+            x, y, rows = read_numpy("sqlite3://" + path,
+                                    "SELECT x, v, label FROM t",
+                                    self.feature_specs,
+                                    ["x", "v"], self.label_spec)
+            self.assertEqual((5, 4), x.shape)
+            self.assertEqual(np.float32, x.dtype)
+            self.assertEqual([4.5, 4, 5, 6], x[4].tolist())
+            self.assertEqual([0, 1, 0, 1, 0], y.tolist())
+            self.assertEqual(5, len(rows))
+
+            # The rows are the features by read_feature, so the predictions
+            # write 0 of an INT column in a float32 feature as "0", not "0.0".
+            select = "SELECT label AS x, v FROM t"
+            x, _, rows = read_numpy("sqlite3://" + path, select,
+                                    self.feature_specs, ["x", "v"], None)
+            self.assertEqual([0.0, 1.0, 0.0, 1.0, 0.0], x[:, 0].tolist())
+            gen = db_generator("sqlite3", conn, select, ["x", "v"], None,
+                               self.feature_specs)
+            self.assertEqual([r[0][0] for r in gen()], [r[0] for r in rows])
+            self.assertEqual(["0", "1", "0", "1", "0"], [
+                feature_to_string(r[0], self.feature_specs["x"]) for r in rows
+            ])
+            self.assertEqual(
+                "4,5,6", feature_to_string(rows[4][1], self.feature_specs["v"]))
+            _, _, rows = read_numpy("sqlite3://" + path, "SELECT x, v FROM t",
+                                    self.feature_specs, ["x", "v"], None)
+            self.assertEqual(["0.5", "1.5", "2.5", "3.5", "4.5"], [
+                feature_to_string(r[0], self.feature_specs["x"]) for r in rows
+            ])
+            conn.close()
+
+
 class TestConnectWithDataSource(TestCase):
     def test_parse_mysql_dsn(self):
         # [username[:password]@][protocol[(address)]]/dbname[?param1=value1&...&paramN=valueN]
@@ -287,6 +433,25 @@ class TestConnectWithDataSource(TestCase):
         self.assertEqual(("root", "root", "127.0.0.1", None, None, "", {}),
                          parseHiveDSN("root:root@127.0.0.1"))
 
+    def test_parse_postgres_dsn(self):
+        self.assertEqual(
+            ("usr", "pswd", "localhost", "5432", "mydb", {
+                "sslmode": "disable"
+            }), parsePostgresDSN("usr:pswd@localhost:5432/mydb?sslmode=disable"))
+        self.assertEqual(("usr", "pswd", "localhost", "5432", "mydb", {}),
+                         parsePostgresDSN("usr:pswd@localhost/mydb"))
+
+    def test_parse_clickhouse_dsn(self):
+        self.assertEqual(
+            ("usr", "pswd", "localhost", "9000", "mydb", {
+                "compression": "lz4"
+            }),
+            parseClickHouseDSN(
+                "localhost:9000?username=usr&password=pswd&database=mydb&compression=lz4"
+            ))
+        self.assertEqual(("default", "", "localhost", "9000", "default", {}),
+                         parseClickHouseDSN("localhost"))
+
     def test_parse_maxcompute_dsn(self):
         self.assertEqual(
             ("access_id", "access_key", "http://maxcompute-service.com/api",
@@ -294,3 +459,115 @@ class TestConnectWithDataSource(TestCase):
             parseMaxComputeDSN(
                 "access_id:access_key@maxcompute-service.com/api?curr_project=test_ci&scheme=http"
             ))
+
+
+class TestReadTimeFeature(TestCase):
+    def test_read_time_feature(self):
+        spec = {
+            "delimiter": "",
+            "is_sparse": False,
+            "time_parts": ["hour", "dayofweek", "month"],
+            "cyclical": False
+        }
+        # 2020-08-01 is a Saturday
+        np.testing.assert_array_equal(
+            read_feature("2020-08-01 10:00:00", spec, "ts"), [10, 5, 8])
+        np.testing.assert_array_equal(
+            read_feature("2020-08-01T10:00:00Z", spec, "ts"), [10, 5, 8])
+        np.testing.assert_array_equal(
+            read_feature(datetime.date(2020, 8, 1), spec, "ts"), [0, 5, 8])
+
+        spec["cyclical"] = True
+        spec["time_parts"] = ["hour", "year"]
+        v = read_feature(datetime.datetime(2020, 8, 1, 6), spec, "ts")
+        # sin and cos of the hour, and the year as is
+        np.testing.assert_allclose(v, [1, 0, 2020], atol=1e-6)
+        with self.assertRaises(ValueError):
+            read_feature("not a time", spec, "ts")
+
+
+class TestReadArrayAndJSONFeature(TestCase):
+    def test_read_array_feature(self):
+        spec = {
+            "delimiter": "",
+            "dtype": "float32",
+            "is_sparse": False,
+            "is_array": True,
+            "shape": [3]
+        }
+        for raw in ["[0.1,0.2,0.3]", "{0.1,0.2,0.3}", [0.1, 0.2, 0.3]]:
+            np.testing.assert_allclose(read_feature(raw, spec, "emb"),
+                                       [0.1, 0.2, 0.3])
+
+    def test_read_json_feature(self):
+        spec = {
+            "delimiter": "",
+            "dtype": "float32",
+            "is_sparse": False,
+            "json_paths": ["$.age", "$.scores[1]", "$.income"]
+        }
+        payload = '{"age": 30, "scores": [1, 2]}'
+        np.testing.assert_array_equal(read_feature(payload, spec, "payload"),
+                                      [30, 2, 0])
+        np.testing.assert_array_equal(
+            read_feature({"age": 40}, spec, "payload"), [40, 0, 0])
+
+
+class TestReadTextFeature(TestCase):
+    def test_read_text_feature(self):
+        spec = {
+            "delimiter": "",
+            "dtype": "int64",
+            "is_sparse": False,
+            "shape": [6],
+            "tokenizer": "word",
+            "vocabulary": ["the", "cat", "sat"]
+        }
+        # the ids of the sorted vocabulary: cat 1, sat 2, the 3, and 0 for
+        # the out-of-vocabulary words, padded by -1
+        np.testing.assert_array_equal(
+            read_feature("The cat sat on the mat", spec, "title"),
+            [3, 1, 2, 0, 3, 0])
+        np.testing.assert_array_equal(read_feature("Cat!", spec, "title"),
+                                      [1, -1, -1, -1, -1, -1])
+        spec["tokenizer"] = "char"
+        spec["shape"] = [2]
+        spec["vocabulary"] = {"a": "a"}
+        np.testing.assert_array_equal(read_feature("a b c", spec, "title"),
+                                      [1, 0])
+
+
+class FakeDBWriter(object):
+    def __init__(self):
+        self.rows = []
+        self.written = []
+        self.closed = False
+
+    def flush(self):
+        self.written.append(self.rows)
+        self.rows = []
+
+    def close(self):
+        self.closed = True
+
+
+class TestParallelDBWriter(TestCase):
+    def test_one_writer(self):
+        w = FakeDBWriter()
+        pw = ParallelDBWriter([w], "test_db.tbl", buff_size=2)
+        for i in range(5):
+            pw.write([i])
+        pw.close()
+        self.assertEqual([[[0], [1]], [[2], [3]], [[4]]], w.written)
+        self.assertTrue(w.closed)
+        self.assertEqual(5, pw.num_written)
+
+    def test_parallel_writers(self):
+        writers = [FakeDBWriter() for _ in range(3)]
+        pw = ParallelDBWriter(writers, "test_db.tbl", buff_size=10)
+        for i in range(1001):
+            pw.write([i])
+        pw.close()
+        rows = sorted(r[0] for w in writers for b in w.written for r in b)
+        self.assertEqual(list(range(1001)), rows)
+        self.assertTrue(all(w.closed for w in writers))
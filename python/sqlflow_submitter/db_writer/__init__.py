@@ -11,12 +11,17 @@
 # See the License for the specific language governing permissions and
 # limitations under the License.
 
+from .clickhouse import ClickHouseDBWriter
 from .hive import HiveDBWriter
 from .maxcompute import MaxComputeDBWriter
 from .mysql import MySQLDBWriter
+from .parallel import ParallelDBWriter
 from .pai_maxcompute import PAIMaxComputeDBWriter
+from .postgres import PostgresDBWriter
+from .sqlite import SQLiteDBWriter
 
 __all__ = [
     "MySQLDBWriter", "HiveDBWriter", "MaxComputeDBWriter",
-    "PAIMaxComputeDBWriter"
+    "PAIMaxComputeDBWriter", "PostgresDBWriter", "ClickHouseDBWriter",
+    "SQLiteDBWriter", "ParallelDBWriter"
 ]
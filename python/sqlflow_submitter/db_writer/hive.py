@@ -20,6 +20,14 @@ from .base import BufferedDBWriter
 CSV_DELIMITER = '\001'
 
 
+def partition_clause(partition):
+    """Returns the partition clause of Hive like "dt='20200601'" of the
+    partition like "dt=20200601".
+    """
+    return ", ".join("%s='%s'" % tuple(kv.split("=", 1))
+                     for kv in partition.split(","))
+
+
 class HiveDBWriter(BufferedDBWriter):
     def __init__(self,
                  conn,
@@ -29,7 +37,8 @@ class HiveDBWriter(BufferedDBWriter):
                  hdfs_namenode_addr="",
                  hive_location="",
                  hdfs_user="",
-                 hdfs_pass=""):
+                 hdfs_pass="",
+                 partition=""):
         super().__init__(conn, table_name, table_schema, buff_size)
         self.tmp_f = tempfile.NamedTemporaryFile(dir="./")
         self.f = open(self.tmp_f.name, "w")
@@ -38,6 +47,7 @@ class HiveDBWriter(BufferedDBWriter):
         self.hive_location = hive_location
         self.hdfs_user = hdfs_user
         self.hdfs_pass = hdfs_pass
+        self.partition = partition
 
     def _column_list(self):
         # NOTE(yancey1989): for the tablename: mydb.tblname, if 'mydb' is
@@ -113,10 +123,13 @@ class HiveDBWriter(BufferedDBWriter):
         cmd_str = "hdfs dfs %s -copyFromLocal %s %s/%s/" % (
             cmd_namenode_str, self.tmp_f.name, hdfs_path, self.table_name)
         subprocess.check_output(cmd_str.split(), env=hdfs_envs)
-        # load CSV into Hive
+        # load CSV into Hive. NOTE: SQLFlow drops the table, or the
+        # partition, before writing to overwrite it.
         cursor = self.conn.cursor()
-        load_sql = "LOAD DATA INPATH '%s/%s/' OVERWRITE INTO TABLE %s" % (
+        load_sql = "LOAD DATA INPATH '%s/%s/' INTO TABLE %s" % (
             hdfs_path, self.table_name, self.table_name)
+        if self.partition != "":
+            load_sql += " PARTITION (%s)" % partition_clause(self.partition)
         cursor.execute(load_sql)
         self.conn.commit()
         cursor.close()
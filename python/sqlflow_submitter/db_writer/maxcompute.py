@@ -17,13 +17,22 @@ from .base import BufferedDBWriter
 
 
 class MaxComputeDBWriter(BufferedDBWriter):
-    def __init__(self, conn, table_name, table_schema, buff_size):
-        return super(MaxComputeDBWriter,
-                     self).__init__(conn, table_name, table_schema, buff_size)
+    def __init__(self, conn, table_name, table_schema, buff_size,
+                 partition=""):
+        super(MaxComputeDBWriter, self).__init__(conn, table_name,
+                                                 table_schema, buff_size)
+        self.partition = partition
 
     def flush(self):
         compress = tunnel.CompressOption.CompressAlgorithm.ODPS_ZLIB
-        self.conn.write_table(self.table_name,
-                              self.rows,
-                              compress_option=compress)
+        if self.partition != "":
+            self.conn.write_table(self.table_name,
+                                  self.rows,
+                                  partition=self.partition,
+                                  create_partition=True,
+                                  compress_option=compress)
+        else:
+            self.conn.write_table(self.table_name,
+                                  self.rows,
+                                  compress_option=compress)
         self.rows = []
@@ -0,0 +1,107 @@
+# Copyright 2020 The SQLFlow Authors. All rights reserved.
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import sys
+import threading
+import time
+
+try:
+    import queue
+except ImportError:
+    import Queue as queue
+
+# ParallelDBWriter prints the progress at most once per REPORT_INTERVAL
+# seconds.
+REPORT_INTERVAL = 10
+
+
+class ParallelDBWriter(object):
+    """ParallelDBWriter writes rows to a table in batches of buff_size rows
+    by writers, each of which is a BufferedDBWriter with its own connection
+    and runs in a thread. With one writer, it writes in the calling thread.
+    It prints the number of written rows, which the SQLFlow server streams
+    to the client as the progress.
+    """
+    def __init__(self, writers, table_name, buff_size=100):
+        self.writers = writers
+        self.table_name = table_name
+        self.buff_size = buff_size
+        self.rows = []
+        self.num_written = 0
+        self.last_report = time.time()
+        self.lock = threading.Lock()
+        self.error = None
+        self.queue = None
+        self.threads = []
+        if len(writers) > 1:
+            # Bound the pending batches to bound the memory.
+            self.queue = queue.Queue(maxsize=2 * len(writers))
+            for w in writers:
+                t = threading.Thread(target=self._run, args=(w, ))
+                t.daemon = True
+                t.start()
+                self.threads.append(t)
+
+    def _report(self):
+        print("Wrote %d rows to %s" % (self.num_written, self.table_name))
+        sys.stdout.flush()
+
+    def _flush(self, writer, rows):
+        writer.rows = rows
+        writer.flush()
+        with self.lock:
+            self.num_written += len(rows)
+            if time.time() - self.last_report >= REPORT_INTERVAL:
+                self.last_report = time.time()
+                self._report()
+
+    def _run(self, writer):
+        while True:
+            rows = self.queue.get()
+            if rows is None:
+                return
+            # Drain the queue after an error to not block write.
+            if self.error is None:
+                try:
+                    self._flush(writer, rows)
+                except Exception as e:
+                    self.error = e
+
+    def _write_batch(self):
+        rows, self.rows = self.rows, []
+        if self.queue is None:
+            self._flush(self.writers[0], rows)
+            return
+        if self.error is not None:
+            raise self.error
+        self.queue.put(rows)
+
+    def write(self, value):
+        self.rows.append(value)
+        if len(self.rows) >= self.buff_size:
+            self._write_batch()
+
+    def close(self):
+        try:
+            if len(self.rows) > 0:
+                self._write_batch()
+        finally:
+            for _ in self.threads:
+                self.queue.put(None)
+            for t in self.threads:
+                t.join()
+            for w in self.writers:
+                w.close()
+        if self.error is not None:
+            raise self.error
+        self._report()
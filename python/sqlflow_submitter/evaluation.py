@@ -0,0 +1,157 @@
+# Copyright 2020 The SQLFlow Authors. All rights reserved.
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import datetime
+
+import numpy as np
+import sklearn.metrics
+import sklearn.preprocessing
+from sqlflow_submitter import db
+
+WIDE_SCHEMA = "wide"
+LONG_SCHEMA = "long"
+
+# The columns of the result table in LONG_SCHEMA, which Go creates in
+# pkg/sql/submitter.go.
+LONG_SCHEMA_COLUMNS = [
+    "model", "model_version", "evaluated_at", "metric", "value"
+]
+
+
+def _positive_scores(scores):
+    """Returns the scores of the positive class of the binary classifiers,
+    or the scores as is."""
+    if scores.ndim == 2 and scores.shape[1] == 2:
+        return scores[:, 1]
+    return scores
+
+
+def _auc(y_true, preds, scores, params):
+    scores = _positive_scores(scores)
+    if scores.ndim == 2:
+        return sklearn.metrics.roc_auc_score(y_true, scores, multi_class="ovr")
+    return sklearn.metrics.roc_auc_score(y_true, scores)
+
+
+def _pr_auc(y_true, preds, scores, params):
+    scores = _positive_scores(scores)
+    if scores.ndim == 2:
+        y_true = sklearn.preprocessing.label_binarize(
+            y_true, classes=range(scores.shape[1]))
+    return sklearn.metrics.average_precision_score(y_true, scores)
+
+
+def _f1(y_true, preds, scores, params):
+    scores = _positive_scores(scores)
+    if scores.ndim == 2:
+        return sklearn.metrics.f1_score(y_true,
+                                        np.argmax(scores, axis=1),
+                                        average="macro")
+    return sklearn.metrics.f1_score(
+        y_true, (scores >= params.get("threshold", 0.5)).astype(int))
+
+
+def _rmse(y_true, preds, scores, params):
+    return np.sqrt(sklearn.metrics.mean_squared_error(y_true, preds))
+
+
+def _mae(y_true, preds, scores, params):
+    return sklearn.metrics.mean_absolute_error(y_true, preds)
+
+
+def _ndcg(y_true, preds, scores, params):
+    # NOTE: rank all the rows as one list by the scores.
+    k = params.get("ndcg_k", 0)
+    return sklearn.metrics.ndcg_score([y_true], [_positive_scores(scores)],
+                                      k=k if k > 0 else None)
+
+
+# NAMED_METRICS are the metrics that need the scores of the classes, or
+# are not in sklearn.metrics under the names.
+NAMED_METRICS = {
+    "AUC": _auc,
+    "PR_AUC": _pr_auc,
+    "F1": _f1,
+    "RMSE": _rmse,
+    "MAE": _mae,
+    "NDCG": _ndcg,
+}
+
+
+def evaluate_metrics(metric_names, y_true, preds, scores=None, params={}):
+    """Returns a dict from the metric names to the values. The metrics are
+    in NAMED_METRICS, or the functions in sklearn.metrics, like
+    accuracy_score, which are called with the labels and the predictions.
+    The scores are the probabilities of the classes of the classifiers, or
+    the predictions if None."""
+    y_true = np.asarray(y_true)
+    preds = np.asarray(preds)
+    scores = preds if scores is None else np.asarray(scores)
+    results = dict()
+    for name in metric_names:
+        if name in NAMED_METRICS:
+            results[name] = NAMED_METRICS[name](y_true, preds, scores, params)
+        elif hasattr(sklearn.metrics, name):
+            results[name] = getattr(sklearn.metrics, name)(y_true, preds)
+        else:
+            raise ValueError("unsupported metric %s" % name)
+    return results
+
+
+def _to_string(value):
+    """Converts the value of a metric to a string to write, as the numpy
+    numbers are not supported by all the database drivers."""
+    return None if value is None else str(value)
+
+
+def write_result(driver,
+                 conn,
+                 result_table,
+                 metric_names,
+                 results,
+                 params={},
+                 hdfs_namenode_addr="",
+                 hive_location="",
+                 hdfs_user="",
+                 hdfs_pass=""):
+    """Writes the results of the metrics into result_table by the schema
+    params["result_schema"]. In WIDE_SCHEMA, the table has the columns
+    loss and metric_names, and a row of the results. In LONG_SCHEMA, it
+    has LONG_SCHEMA_COLUMNS, and a row of each metric keyed by the model,
+    its version, and the time of the evaluation."""
+    if params.get("result_schema", WIDE_SCHEMA) == LONG_SCHEMA:
+        evaluated_at = datetime.datetime.utcnow().strftime("%Y-%m-%d %H:%M:%S")
+        key = [
+            params.get("model_name", ""),
+            params.get("model_version", ""), evaluated_at
+        ]
+        names = metric_names
+        if "loss" in results and "loss" not in names:
+            names = ["loss"] + names
+        rows = [key + [n, _to_string(results[n])] for n in names]
+        columns = LONG_SCHEMA_COLUMNS
+    else:
+        rows = [[_to_string(results.get("loss", 0.0))] +
+                [_to_string(results[n]) for n in metric_names]]
+        columns = ["loss"] + metric_names
+    with db.buffered_db_writer(driver,
+                               conn,
+                               result_table,
+                               columns,
+                               100,
+                               hdfs_namenode_addr=hdfs_namenode_addr,
+                               hive_location=hive_location,
+                               hdfs_user=hdfs_user,
+                               hdfs_pass=hdfs_pass) as w:
+        for row in rows:
+            w.write(row)
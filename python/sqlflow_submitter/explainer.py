@@ -11,11 +11,13 @@
 # See the License for the specific language governing permissions and
 # limitations under the License.
 
+import json
 import sys
 
 import matplotlib
 # The default backend
 import matplotlib.pyplot as plt
+import numpy as np
 import sqlflow_submitter.pai.utils as utils
 
 # TODO(shendiaomo): extract common code from tensorflow/explain.py and xgboost/explain.py
@@ -53,3 +55,34 @@ def plot_and_save(plotfunc,
         sys.stdout.isatty = lambda: True
         plotfunc()
         plt_text_backend.savefig(filename, bbox_inches='tight')
+
+
+def mean_abs_shap_values(shap_values):
+    '''
+    mean_abs_shap_values returns the mean absolute SHAP value of each
+    feature, over the outputs, too, if the model predicts vectors and
+    shap_values is a list of those of each output.
+    '''
+    values = np.abs(np.array(shap_values))
+    return values.reshape(-1, values.shape[-1]).mean(axis=0)
+
+
+def save_attributions(feature_names,
+                      attributions,
+                      filename='attributions.json'):
+    '''
+    save_attributions saves the attribution of each feature, for the
+    submitter to return them as a table along with the figures
+    Args:
+        feature_names: The names of the features
+        attributions: The attribution of each feature, like the mean
+            absolute SHAP value
+        filename: The file to be saved
+    Return:
+        None
+    '''
+    with open(filename, 'w') as f:
+        json.dump([{
+            'feature': str(name),
+            'attribution': float(v)
+        } for name, v in zip(feature_names, attributions)], f)
@@ -0,0 +1,100 @@
+# Copyright 2020 The SQLFlow Authors. All rights reserved.
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+import datetime
+import json
+
+import numpy as np
+from sqlflow_submitter import db
+from sqlflow_submitter.forecast.train import (META_FILE, MODEL_FILE,
+                                              build_model, is_deepar,
+                                              read_series)
+
+
+def next_times(times, n):
+    """Returns the n times following times by the interval of the last two,
+    formatted like "2020-06-01", or "2020-06-01 10:00:00", unless they are
+    numbers."""
+    if len(times) < 2:
+        raise ValueError("forecasting requires at least two rows to infer "
+                         "the interval of the times")
+    if all(isinstance(t, (int, float)) for t in times[-2:]):
+        step = times[-1] - times[-2]
+        return [times[-1] + step * (i + 1) for i in range(n)]
+    last, prev = db.parse_time(times[-1]), db.parse_time(times[-2])
+    step = last - prev
+    fmt = "%Y-%m-%d %H:%M:%S"
+    if last.time() == datetime.time() and step % datetime.timedelta(1) == \
+            datetime.timedelta(0):
+        fmt = "%Y-%m-%d"
+    return [(last + step * (i + 1)).strftime(fmt) for i in range(n)]
+
+
+def forecast(model, meta, window):
+    """Returns the forecasts of the horizon rows following the normalized
+    window."""
+    horizon = meta["model_params"]["horizon"]
+    if not is_deepar(meta["estimator"]):
+        return model.predict(window[np.newaxis])[0]
+    # NOTE: DeepAR forecasts a row each time by the mean of the Gaussian
+    # distribution, and forecasts by it the next row.
+    history, preds = window, []
+    for _ in range(horizon):
+        mean = model.predict(history[np.newaxis])[0, -1, 0]
+        preds.append(mean)
+        history = np.concatenate([history[1:], [[mean]]]).astype(np.float32)
+    return np.array(preds)
+
+
+def pred(datasource,
+         select,
+         time_column,
+         series_columns,
+         result_table,
+         result_column,
+         hdfs_namenode_addr="",
+         hive_location="",
+         hdfs_user="",
+         hdfs_pass="",
+         write_batch_size=100,
+         partition=""):
+    with open(META_FILE) as f:
+        meta = json.load(f)
+    times, values = read_series(datasource, select, time_column,
+                                series_columns)
+    window_size = meta["model_params"]["window_size"]
+    if len(values) < window_size:
+        raise ValueError("forecasting requires the last %d rows, received %d"
+                         % (window_size, len(values)))
+    mean = np.array(meta["mean"], dtype=np.float32)
+    std = np.array(meta["std"], dtype=np.float32)
+    model = build_model(meta["estimator"], len(series_columns),
+                        meta["model_params"])
+    model.load_weights(MODEL_FILE)
+    print("Start forecasting by %s..." % meta["estimator"])
+    preds = forecast(model, meta, (values[-window_size:] - mean) / std)
+    preds = preds * std[0] + mean[0]
+    conn = db.connect_with_data_source(datasource)
+    with db.buffered_db_writer(conn.driver,
+                               conn,
+                               result_table, [time_column, result_column],
+                               write_batch_size,
+                               hdfs_namenode_addr=hdfs_namenode_addr,
+                               hive_location=hive_location,
+                               hdfs_user=hdfs_user,
+                               hdfs_pass=hdfs_pass,
+                               partition=partition) as w:
+        for t, p in zip(next_times(times, len(preds)), preds):
+            w.write([str(t), str(p)])
+    print("Done forecasting. Forecast table : %s" % result_table)
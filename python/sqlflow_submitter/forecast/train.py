@@ -0,0 +1,143 @@
+# Copyright 2020 The SQLFlow Authors. All rights reserved.
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+import json
+
+import numpy as np
+import tensorflow as tf
+from sqlflow_submitter import db, progress
+
+MODEL_FILE = "forecast_model"
+META_FILE = "model_meta.json"
+
+DEEPAR = "SQLFLOW_MODELS.DEEPARFORECASTER"
+
+
+def _time_key(t):
+    if isinstance(t, (int, float)):
+        return t
+    return db.parse_time(t)
+
+
+def read_series(datasource, select, time_column, series_columns):
+    """Reads the result of select, and returns the values of time_column in
+    the ascending order, and the values of series_columns in the order as a
+    2-D float32 array."""
+    names = [time_column] + series_columns
+    specs = dict((n, {"is_sparse": False, "delimiter": ""}) for n in names)
+    conn = db.connect_with_data_source(datasource)
+    gen = db.db_generator(conn.driver, conn, select, names, None, specs)
+    rows = sorted((r[0] for r in gen()), key=lambda r: _time_key(r[0][0]))
+    times = [r[0][0] for r in rows]
+    values = np.array([[float(f[0]) for f in r[1:]] for r in rows],
+                      dtype=np.float32).reshape(len(rows), len(series_columns))
+    return times, values
+
+
+def is_deepar(estimator):
+    return estimator.upper() == DEEPAR
+
+
+def make_windows(values, estimator, window_size, horizon):
+    """Returns the sliding windows of values and the targets to fit. The
+    targets of DeepAR are the windows shifted by one row, and the ones of
+    the others are the labels, the first series, of the horizon rows
+    following the windows."""
+    size = window_size + (1 if is_deepar(estimator) else horizon)
+    if len(values) < size:
+        raise ValueError("forecasting requires at least %d rows, received %d"
+                         % (size, len(values)))
+    n = len(values) - size + 1
+    x = np.stack([values[i:i + window_size] for i in range(n)])
+    if is_deepar(estimator):
+        y = np.stack([values[i + 1:i + window_size + 1] for i in range(n)])
+    else:
+        y = np.stack([
+            values[i + window_size:i + window_size + horizon, 0]
+            for i in range(n)
+        ])
+    return x, y
+
+
+def gaussian_loss(y_true, y_pred):
+    """The negative log likelihood of y_true in the Gaussian distributions
+    of the means and the scales in y_pred."""
+    mean, scale = y_pred[..., :1], tf.math.softplus(y_pred[..., 1:]) + 1e-6
+    return tf.reduce_mean(
+        tf.math.log(scale) + 0.5 * tf.square((y_true - mean) / scale))
+
+
+def build_model(estimator, num_series, model_params):
+    window_size = model_params["window_size"]
+    units = model_params.get("lstm_units", 32)
+    if is_deepar(estimator):
+        inputs = tf.keras.Input(shape=(None, 1))
+        h = tf.keras.layers.LSTM(units, return_sequences=True)(inputs)
+        outputs = tf.keras.layers.Dense(2)(h)
+        loss = gaussian_loss
+    else:
+        inputs = tf.keras.Input(shape=(window_size, num_series))
+        h = tf.keras.layers.LSTM(units)(inputs)
+        outputs = tf.keras.layers.Dense(model_params["horizon"])(h)
+        loss = "mse"
+    model = tf.keras.Model(inputs=inputs, outputs=outputs)
+    model.compile(optimizer=tf.keras.optimizers.Adam(
+        model_params.get("learning_rate", 0.001)),
+                  loss=loss)
+    return model
+
+
+def train(datasource,
+          select,
+          validation_select,
+          estimator,
+          time_column,
+          series_columns,
+          model_params,
+          train_params={}):
+    _, values = read_series(datasource, select, time_column, series_columns)
+    # NOTE: normalize the series by the training data, and save the mean
+    # and the std to restore the forecasts.
+    mean, std = values.mean(axis=0), values.std(axis=0)
+    std[std == 0] = 1
+    window_size, horizon = model_params["window_size"], model_params[
+        "horizon"]
+    x, y = make_windows((values - mean) / std, estimator, window_size,
+                        horizon)
+    validation_data = None
+    if len(validation_select.strip()) > 0:
+        _, vvalues = read_series(datasource, validation_select, time_column,
+                                 series_columns)
+        validation_data = make_windows((vvalues - mean) / std, estimator,
+                                       window_size, horizon)
+    model = build_model(estimator, len(series_columns), model_params)
+    print("Start training %s on %d windows..." % (estimator, len(x)))
+    model.fit(x,
+              y,
+              batch_size=train_params.get("batch_size", 32),
+              epochs=train_params.get("epoch", 10),
+              validation_data=validation_data,
+              verbose=2,
+              callbacks=[progress.keras_callback()])
+    model.save_weights(MODEL_FILE)
+    with open(META_FILE, "w") as f:
+        json.dump(
+            {
+                "estimator": estimator,
+                "time_column": time_column,
+                "series_columns": series_columns,
+                "model_params": model_params,
+                "mean": mean.tolist(),
+                "std": std.tolist(),
+            }, f)
@@ -0,0 +1,188 @@
+# Copyright 2020 The SQLFlow Authors. All rights reserved.
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import numpy as np
+import shap
+from sqlflow_submitter import db
+from sqlflow_submitter.explainer import plot_and_save, save_attributions
+
+KERNEL_EXPLAINER = "KernelExplainer"
+PERMUTATION_IMPORTANCE = "PermutationImportance"
+
+
+def load_predict_fn(framework):
+    """Loads the model saved by the submitter of framework in the current
+    directory, and returns a function that predicts a 2-D float32 array
+    of the flattened features."""
+    if framework == "pytorch":
+        import torch
+        from sqlflow_submitter.pytorch.model import load_model
+        model, _ = load_model()
+
+        def predict(x):
+            with torch.no_grad():
+                return model.predict(torch.from_numpy(x)).numpy()
+
+        return predict
+    elif framework == "lightgbm":
+        from sqlflow_submitter.lightgbm.predict import load_model, predict
+        bst, meta = load_model()
+        return lambda x: predict(bst, meta, x)
+    elif framework == "catboost":
+        from sqlflow_submitter.catboost.predict import load_model, predict
+        model, meta = load_model()
+        return lambda x: predict(model, meta, x)
+    elif framework == "sklearn":
+        from sqlflow_submitter.sklearn.train import load_model
+        return load_model().predict
+    raise ValueError("unsupported framework %s" % framework)
+
+
+def sample_numpy(datasource, select, feature_metas, feature_column_names,
+                 sample_size, rng):
+    """Samples at most sample_size rows of the result of select by
+    reservoir sampling, so the rows are read only once and not kept in
+    memory, and returns the flattened features as a 2-D float32 array."""
+    conn = db.connect_with_data_source(datasource)
+    gen = db.db_generator(conn.driver, conn, select, feature_column_names,
+                          None, feature_metas)
+    rows = []
+    for i, row in enumerate(gen()):
+        if i < sample_size:
+            rows.append(row[0])
+        else:
+            j = rng.randint(0, i + 1)
+            if j < sample_size:
+                rows[j] = row[0]
+    n = sum(
+        np.prod(feature_metas[name]["shape"])
+        for name in feature_column_names)
+    return np.array([db.flatten_features(r) for r in rows],
+                    dtype=np.float32).reshape(len(rows), int(n))
+
+
+def flattened_names(feature_metas, feature_column_names):
+    """Returns the names of the flattened features, like ["a", "b_0",
+    "b_1"] if a is a scalar and b is a vector of 2 elements, and the
+    index of the feature each of them belongs to."""
+    names, owners = [], []
+    for i, name in enumerate(feature_column_names):
+        n = int(np.prod(feature_metas[name]["shape"]))
+        if n == 1:
+            names.append(name)
+        else:
+            names += ["%s_%d" % (name, k) for k in range(n)]
+        owners += [i] * n
+    return names, np.array(owners)
+
+
+def kernel_shap_values(predict, x, background_size):
+    if len(x) > background_size:
+        background = shap.kmeans(x, background_size)
+    else:
+        background = x
+    e = shap.KernelExplainer(predict, background)
+    return np.array(e.shap_values(x))
+
+
+def permutation_importance(predict, x, n_repeats, rng):
+    """Returns the mean absolute change of the predictions when each
+    column of x is shuffled. Unlike the score-based permutation importance,
+    it needs no label, so it explains the unsupervised models, too."""
+    base = predict(x)
+    importances = np.zeros(x.shape[1])
+    for j in range(x.shape[1]):
+        for _ in range(n_repeats):
+            shuffled = x.copy()
+            shuffled[:, j] = rng.permutation(shuffled[:, j])
+            importances[j] += np.mean(np.abs(predict(shuffled) - base))
+    return importances / n_repeats
+
+
+def plot_importances(names, importances):
+    import matplotlib.pyplot as plt
+    order = np.argsort(importances)
+    plt.figure()
+    plt.barh([names[i] for i in order], importances[order])
+    plt.xlabel("mean absolute change of predictions")
+
+
+def write_attributions(datasource, result_table, feature_column_names,
+                       attributions, hdfs_namenode_addr, hive_location,
+                       hdfs_user, hdfs_pass):
+    conn = db.connect_with_data_source(datasource)
+    with db.buffered_db_writer(conn.driver,
+                               conn,
+                               result_table, ["feature", "attribution"],
+                               100,
+                               hdfs_namenode_addr=hdfs_namenode_addr,
+                               hive_location=hive_location,
+                               hdfs_user=hdfs_user,
+                               hdfs_pass=hdfs_pass) as w:
+        for name, v in zip(feature_column_names, attributions):
+            w.write([name, float(v)])
+
+
+def explain(datasource,
+            select,
+            framework,
+            explainer,
+            feature_metas,
+            feature_column_names,
+            summary_params,
+            sample_size=100,
+            background_size=50,
+            n_repeats=5,
+            random_state=0,
+            result_table="",
+            hdfs_namenode_addr="",
+            hive_location="",
+            hdfs_user="",
+            hdfs_pass=""):
+    """Explains the model of framework saved in the current directory by
+    KernelExplainer or PermutationImportance, saves the plots to
+    summary.png and summary.txt, and the attribution of each feature, the
+    sum of those of its flattened elements, to attributions.json and into
+    result_table if it's not empty."""
+    rng = np.random.RandomState(random_state)
+    predict = load_predict_fn(framework)
+    x = sample_numpy(datasource, select, feature_metas, feature_column_names,
+                     sample_size, rng)
+    names, owners = flattened_names(feature_metas, feature_column_names)
+    print("Start explaining %s model by %s..." % (framework, explainer))
+    if explainer == KERNEL_EXPLAINER:
+        shap_values = kernel_shap_values(predict, x, background_size)
+        # NOTE: the shap values of the models predicting vectors have
+        # the shape (n_outputs, n_rows, n_features).
+        if shap_values.ndim == 3:
+            importances = np.abs(shap_values).mean(axis=(0, 1))
+            shap_values = list(shap_values)
+        else:
+            importances = np.abs(shap_values).mean(axis=0)
+        plot_and_save(lambda: shap.summary_plot(
+            shap_values, x, feature_names=names, show=False, **summary_params))
+    elif explainer == PERMUTATION_IMPORTANCE:
+        importances = permutation_importance(predict, x, n_repeats, rng)
+        plot_and_save(lambda: plot_importances(names, importances))
+    else:
+        raise ValueError("unsupported explainer %s" % explainer)
+
+    attributions = np.bincount(owners,
+                               weights=importances,
+                               minlength=len(feature_column_names))
+    save_attributions(feature_column_names, attributions)
+    if result_table != "":
+        write_attributions(datasource, result_table, feature_column_names,
+                           attributions, hdfs_namenode_addr, hive_location,
+                           hdfs_user, hdfs_pass)
+    print("Done explaining.")
@@ -0,0 +1,83 @@
+# Copyright 2020 The SQLFlow Authors. All rights reserved.
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import collections
+
+import numpy as np
+from sqlflow_submitter import db
+
+BALANCED = "balanced"
+# WEIGHT_COLUMN is the feature of the weights of the rows, which is the
+# weight_column of the TensorFlow estimators.
+WEIGHT_COLUMN = "__sqlflow_class_weight"
+
+
+def count_labels(datasource, select, label_meta):
+    """Goes through the result of select, and returns the number of rows of
+    each label."""
+    conn = db.connect_with_data_source(datasource)
+    gen = db.db_generator(conn.driver, conn, select, [], label_meta, {})
+    return collections.Counter(int(row[1]) for row in gen())
+
+
+def class_weights(counts, class_weight):
+    """Returns the weights of the labels in counts by the attribute
+    train.class_weight, which is "balanced" or a dict of the weights of the
+    labels. The labels missing in the dict have the weight 1."""
+    if class_weight == BALANCED:
+        total = sum(counts.values())
+        return {
+            label: float(total) / (len(counts) * c)
+            for label, c in counts.items()
+        }
+    return {
+        label: float(class_weight.get(label, 1))
+        for label in counts.keys()
+    }
+
+
+def oversample_repeats(counts):
+    """Returns how many times to repeat the rows of each label in counts, so
+    each label has about as many rows as the majority label."""
+    majority = max(counts.values())
+    return {
+        label: max(1, int(round(float(majority) / c)))
+        for label, c in counts.items()
+    }
+
+
+def weight_list(weights):
+    """Returns the list of the weights of the labels 0, 1, ..., the largest
+    label in weights, to look up the weights by the labels."""
+    if min(weights.keys()) < 0:
+        raise ValueError(
+            "train.class_weight and train.oversample_minority require "
+            "non-negative integer labels, received %d" % min(weights.keys()))
+    return [weights.get(i, 1) for i in range(max(weights.keys()) + 1)]
+
+
+def sample_weights(labels, weights):
+    """Returns the weights of the rows of labels."""
+    return np.array([weights.get(int(l), 1) for l in labels],
+                    dtype=np.float32)
+
+
+def label_weights(labels, class_weight, oversample_minority):
+    """Returns the weights of the rows of labels to weight the classes by
+    train.class_weight, or to oversample the minority classes, which
+    weights each row by its repeats. For XGBoost, the weight of a row is
+    the same as duplicating it."""
+    counts = collections.Counter(int(l) for l in labels)
+    if oversample_minority:
+        return sample_weights(labels, oversample_repeats(counts))
+    return sample_weights(labels, class_weights(counts, class_weight))
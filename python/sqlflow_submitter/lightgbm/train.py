@@ -0,0 +1,105 @@
+# Copyright 2020 The SQLFlow Authors. All rights reserved.
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import json
+
+import lightgbm as lgb
+from sqlflow_submitter import cross_validation, db, tuning
+from sqlflow_submitter.warm_start import warm_start_file
+
+MODEL_FILE = "my_model"
+META_FILE = "model_meta.json"
+
+
+def fit_predictor(model_params, num_boost_round):
+    """Returns a fit_predict function of cross_validation.kfold_metrics,
+    which trains a LightGBM model by the parameters."""
+    from sqlflow_submitter.lightgbm.predict import predict
+
+    def fit_predict(train_x, train_y, test_x):
+        bst = lgb.train(model_params,
+                        lgb.Dataset(train_x, label=train_y),
+                        num_boost_round=num_boost_round)
+        return predict(bst, {"model_params": model_params},
+                       test_x), bst.predict(test_x)
+
+    return fit_predict
+
+
+def train(datasource,
+          select,
+          validation_select,
+          estimator,
+          model_params,
+          train_params,
+          feature_metas,
+          feature_column_names,
+          label_meta,
+          cv_params={},
+          tuning_params={},
+          warm_start=False):
+    x, y, _ = db.read_numpy(datasource, select, feature_metas,
+                            feature_column_names, label_meta)
+    vx, vy = None, None
+    if len(validation_select.strip()) > 0:
+        vx, vy, _ = db.read_numpy(datasource, validation_select,
+                                  feature_metas, feature_column_names,
+                                  label_meta)
+    if tuning_params:
+
+        def score(params):
+            fit_predict = fit_predictor(
+                params["model_params"],
+                params["train_params"].get("num_boost_round", 100))
+            return tuning.validation_score(tuning_params["metric"],
+                                           fit_predict, x, y, vx, vy,
+                                           cv_params.get("kfold", 0))
+
+        best = tuning.tune(tuning_params, {
+            "model_params": model_params,
+            "train_params": train_params
+        }, score)
+        model_params, train_params = best["model_params"], best["train_params"]
+    num_boost_round = train_params.get("num_boost_round", 100)
+    if cv_params.get("kfold", 0) > 1:
+        print("Start cross-validating LightGBM model...")
+        cross_validation.cross_validate(
+            x, y, cv_params["kfold"], cv_params["metrics"],
+            fit_predictor(model_params, num_boost_round))
+    dtrain = lgb.Dataset(x, label=y)
+    valid_sets, valid_names = [dtrain], ["train"]
+    if vx is not None:
+        valid_sets.append(lgb.Dataset(vx, label=vy, reference=dtrain))
+        valid_names.append("validate")
+    early_stopping_rounds = train_params.get("early_stopping_rounds", 0)
+    print("Start training LightGBM model...")
+    re = dict()
+    bst = lgb.train(model_params,
+                    dtrain,
+                    num_boost_round=num_boost_round,
+                    valid_sets=valid_sets,
+                    valid_names=valid_names,
+                    early_stopping_rounds=early_stopping_rounds
+                    if early_stopping_rounds > 0 else None,
+                    evals_result=re,
+                    init_model=warm_start_file(MODEL_FILE)
+                    if warm_start else None)
+    bst.save_model(MODEL_FILE)
+    print("Evaluation result: %s" % re)
+    with open(META_FILE, "w") as f:
+        json.dump(
+            {
+                "estimator": estimator,
+                "model_params": model_params,
+                "lightgbm_version": lgb.__version__,
+            }, f)
@@ -0,0 +1,45 @@
+# Copyright 2020 The SQLFlow Authors. All rights reserved.
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Convert the trained models to ONNX, so they can be served by ONNX Runtime
+# or Triton outside of SQLFlow. The .onnx file is written in the working
+# directory, which is saved along with the model.
+
+import subprocess
+import sys
+
+ONNX_MODEL_FILE = "model.onnx"
+
+
+def export_tensorflow(output=ONNX_MODEL_FILE):
+    # train writes the path of the exported SavedModel into exported_path.
+    with open("exported_path") as f:
+        saved_model = f.read().strip()
+    subprocess.check_call([
+        sys.executable, "-m", "tf2onnx.convert", "--saved-model", saved_model,
+        "--output", output
+    ])
+    print("Done exporting the ONNX model to: %s" % output)
+
+
+def export_xgboost(num_features, model_file="my_model",
+                   output=ONNX_MODEL_FILE):
+    import onnxmltools
+    import xgboost as xgb
+    from onnxmltools.convert.common.data_types import FloatTensorType
+
+    bst = xgb.Booster(model_file=model_file)
+    onnx_model = onnxmltools.convert_xgboost(
+        bst, initial_types=[("input", FloatTensorType([None, num_features]))])
+    onnxmltools.utils.save_model(onnx_model, output)
+    print("Done exporting the ONNX model to: %s" % output)
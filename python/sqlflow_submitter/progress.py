@@ -0,0 +1,98 @@
+# Copyright 2020 The SQLFlow Authors. All rights reserved.
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+import json
+import math
+import sys
+import time
+
+# PREFIX starts the lines of the progress events, which the SQLFlow server
+# forwards to the clients as the structured progress instead of the logs.
+PREFIX = "SQLFLOW_PROGRESS "
+
+
+def report(epoch, epochs, step=0, steps=0, metrics=None):
+    """Report the training progress to the SQLFlow server.
+
+    Args:
+        epoch: the 1-based epoch, or the boosting round of XGBoost.
+        epochs: the number of epochs, 0 if unknown.
+        step: the 1-based step in the epoch, 0 at the end of the epoch.
+        steps: the number of steps in an epoch, 0 if unknown.
+        metrics: a dict like {"loss": 0.3, "val_accuracy": 0.9}, the values
+            that are not finite numbers are dropped.
+    """
+    event = {"epoch": int(epoch), "epochs": int(epochs or 0)}
+    if step:
+        event["step"] = int(step)
+    if steps:
+        event["steps"] = int(steps)
+    if metrics:
+        values = {}
+        for k, v in metrics.items():
+            try:
+                v = float(v)
+            except (TypeError, ValueError):
+                continue
+            if math.isfinite(v):
+                values[k] = v
+        event["metrics"] = values
+    sys.stdout.write(PREFIX + json.dumps(event) + "\n")
+    sys.stdout.flush()
+
+
+def xgboost_callback(num_boost_round):
+    """Returns the callback of xgb.train that reports each boosting round
+    with the evaluation results like train-rmse."""
+    def callback(env):
+        report(env.iteration + 1,
+               num_boost_round,
+               metrics=dict(env.evaluation_result_list))
+
+    return callback
+
+
+def keras_callback(interval=1.0):
+    """Returns the Keras callback that reports each epoch, and the steps
+    in every interval seconds."""
+    import tensorflow as tf
+
+    class ProgressCallback(tf.keras.callbacks.Callback):
+        def __init__(self):
+            super(ProgressCallback, self).__init__()
+            self.epoch = 0
+            self.reported = 0
+
+        def on_epoch_begin(self, epoch, logs=None):
+            self.epoch = epoch + 1
+
+        def on_batch_end(self, batch, logs=None):
+            now = time.time()
+            if now - self.reported < interval:
+                return
+            self.reported = now
+            metrics = {
+                k: v
+                for k, v in (logs or {}).items() if k not in ("batch", "size")
+            }
+            report(self.epoch,
+                   self.params.get("epochs"),
+                   step=batch + 1,
+                   steps=self.params.get("steps"),
+                   metrics=metrics)
+
+        def on_epoch_end(self, epoch, logs=None):
+            report(epoch + 1, self.params.get("epochs"), metrics=logs)
+
+    return ProgressCallback()
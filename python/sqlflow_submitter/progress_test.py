@@ -0,0 +1,82 @@
+# Copyright 2020 The SQLFlow Authors. All rights reserved.
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+import io
+import json
+import sys
+import unittest
+from collections import namedtuple
+
+from sqlflow_submitter import progress
+
+
+class TestProgress(unittest.TestCase):
+    def report(self, *args, **kwargs):
+        stdout = sys.stdout
+        sys.stdout = io.StringIO()
+        try:
+            progress.report(*args, **kwargs)
+            line = sys.stdout.getvalue()
+        finally:
+            sys.stdout = stdout
+        self.assertTrue(line.startswith(progress.PREFIX))
+        return json.loads(line[len(progress.PREFIX):])
+
+    def test_report(self):
+        self.assertEqual({
+            "epoch": 1,
+            "epochs": 10
+        }, self.report(1, 10))
+        self.assertEqual(
+            {
+                "epoch": 2,
+                "epochs": 0,
+                "step": 30,
+                "steps": 100,
+                "metrics": {
+                    "loss": 0.5
+                }
+            },
+            self.report(2,
+                        None,
+                        step=30,
+                        steps=100,
+                        metrics={
+                            "loss": 0.5,
+                            "nan": float("nan"),
+                            "name": "x"
+                        }))
+
+    def test_xgboost_callback(self):
+        env = namedtuple("CallbackEnv",
+                         ["iteration", "evaluation_result_list"])
+        stdout = sys.stdout
+        sys.stdout = io.StringIO()
+        try:
+            progress.xgboost_callback(30)(env(4, [("train-rmse", 0.2)]))
+            line = sys.stdout.getvalue()
+        finally:
+            sys.stdout = stdout
+        self.assertEqual(
+            {
+                "epoch": 5,
+                "epochs": 30,
+                "metrics": {
+                    "train-rmse": 0.2
+                }
+            }, json.loads(line[len(progress.PREFIX):]))
+
+
+if __name__ == "__main__":
+    unittest.main()
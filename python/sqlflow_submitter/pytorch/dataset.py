@@ -0,0 +1,52 @@
+# Copyright 2020 The SQLFlow Authors. All rights reserved.
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import torch
+from sqlflow_submitter import db
+
+
+class DBDataset(torch.utils.data.IterableDataset):
+    """DBDataset iterates the rows of a SELECT as (features, label) tensors,
+    or (features, ) if label_meta is None."""
+    def __init__(self, datasource, select, feature_metas,
+                 feature_column_names, label_meta):
+        self.datasource = datasource
+        self.select = select
+        self.feature_metas = feature_metas
+        self.feature_column_names = feature_column_names
+        self.label_meta = label_meta
+
+    def __iter__(self):
+        conn = db.connect_with_data_source(self.datasource)
+        gen = db.db_generator(conn.driver, conn, self.select,
+                              self.feature_column_names, self.label_meta,
+                              self.feature_metas)
+        for row in gen():
+            features = torch.from_numpy(db.flatten_features(row[0]))
+            if self.label_meta is None:
+                yield (features, )
+            else:
+                yield features, torch.tensor(row[1])
+
+
+def torch_dataloader(datasource,
+                     select,
+                     feature_metas,
+                     feature_column_names,
+                     label_meta,
+                     batch_size=64):
+    return torch.utils.data.DataLoader(DBDataset(datasource, select,
+                                                 feature_metas,
+                                                 feature_column_names,
+                                                 label_meta),
+                                       batch_size=batch_size)
@@ -0,0 +1,71 @@
+# Copyright 2020 The SQLFlow Authors. All rights reserved.
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import numpy as np
+import torch
+from sqlflow_submitter import db, evaluation
+from sqlflow_submitter.pytorch.dataset import torch_dataloader
+from sqlflow_submitter.pytorch.model import load_model
+
+DEFAULT_EVALUATE_BATCH_SIZE = 1000
+
+
+def evaluate(datasource,
+             select,
+             feature_metas,
+             feature_column_names,
+             label_meta,
+             result_table,
+             validation_metrics=["accuracy_score"],
+             evaluation_params={},
+             hdfs_namenode_addr="",
+             hive_location="",
+             hdfs_user="",
+             hdfs_pass=""):
+    model, _ = load_model()
+    loader = torch_dataloader(datasource, select, feature_metas,
+                              feature_column_names, label_meta,
+                              DEFAULT_EVALUATE_BATCH_SIZE)
+    loss_fn = model.loss_fn()
+    print("Start evaluating PyTorch model...")
+    total_loss, labels, preds, scores = 0.0, [], [], []
+    with torch.no_grad():
+        for x, y in loader:
+            total_loss += loss_fn(model(x), model.target(y)).item() * len(x)
+            labels += y.tolist()
+            preds += model.predict(x).tolist()
+            if hasattr(model, "predict_proba"):
+                scores += model.predict_proba(x).tolist()
+    y_test = np.array(labels)
+    preds = np.array(preds)
+    scores = np.array(scores) if scores else None
+
+    evaluate_results = evaluation.evaluate_metrics(validation_metrics, y_test,
+                                                   preds, scores,
+                                                   evaluation_params)
+    if labels:
+        evaluate_results["loss"] = total_loss / len(labels)
+
+    # write evaluation result to result table
+    conn = db.connect_with_data_source(datasource)
+    evaluation.write_result(conn.driver,
+                            conn,
+                            result_table,
+                            validation_metrics,
+                            evaluate_results,
+                            evaluation_params,
+                            hdfs_namenode_addr=hdfs_namenode_addr,
+                            hive_location=hive_location,
+                            hdfs_user=hdfs_user,
+                            hdfs_pass=hdfs_pass)
+    print("Done evaluating. Result table : %s" % result_table)
@@ -0,0 +1,107 @@
+# Copyright 2020 The SQLFlow Authors. All rights reserved.
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import json
+
+import numpy as np
+import torch
+import torch.nn as nn
+
+# The model directory of SQLFlow, saved by pkg/model, has the state_dict
+# of the trained model in MODEL_FILE, and how to rebuild the model in
+# META_FILE.
+MODEL_FILE = "model.pt"
+META_FILE = "model_meta.json"
+
+
+def _dnn(n_inputs, hidden_units, n_outputs, dropout):
+    layers = []
+    for units in hidden_units:
+        layers += [nn.Linear(n_inputs, units), nn.ReLU()]
+        if dropout > 0:
+            layers.append(nn.Dropout(dropout))
+        n_inputs = units
+    layers.append(nn.Linear(n_inputs, n_outputs))
+    return nn.Sequential(*layers)
+
+
+class DNNClassifier(nn.Module):
+    def __init__(self, n_inputs, hidden_units=[10, 10], n_classes=2,
+                 dropout=0):
+        super(DNNClassifier, self).__init__()
+        self.net = _dnn(n_inputs, hidden_units, n_classes, dropout)
+
+    def forward(self, x):
+        return self.net(x)
+
+    def loss_fn(self):
+        return nn.CrossEntropyLoss()
+
+    def target(self, y):
+        return y.long()
+
+    def predict(self, x):
+        return torch.argmax(self.forward(x), dim=1)
+
+    def predict_proba(self, x):
+        return torch.softmax(self.forward(x), dim=1)
+
+
+class DNNRegressor(nn.Module):
+    def __init__(self, n_inputs, hidden_units=[10, 10], dropout=0):
+        super(DNNRegressor, self).__init__()
+        self.net = _dnn(n_inputs, hidden_units, 1, dropout)
+
+    def forward(self, x):
+        return self.net(x).squeeze(1)
+
+    def loss_fn(self):
+        return nn.MSELoss()
+
+    def target(self, y):
+        return y.float()
+
+    def predict(self, x):
+        return self.forward(x)
+
+
+def num_inputs(feature_metas, feature_column_names):
+    return int(
+        sum(
+            np.prod(feature_metas[name]["shape"])
+            for name in feature_column_names))
+
+
+def build_model(model_class, n_inputs, model_params):
+    return globals()[model_class](n_inputs, **model_params)
+
+
+def save_model(model, meta):
+    """Saves the model to the current directory, which pkg/model saves
+    after the training."""
+    torch.save(model.state_dict(), MODEL_FILE)
+    meta["torch_version"] = torch.__version__
+    with open(META_FILE, "w") as f:
+        json.dump(meta, f)
+
+
+def load_model():
+    """Loads the model saved by save_model, and returns the model in the
+    evaluation mode and its metadata."""
+    with open(META_FILE) as f:
+        meta = json.load(f)
+    model = build_model(meta["model_class"], meta["n_inputs"],
+                        meta["model_params"])
+    model.load_state_dict(torch.load(MODEL_FILE))
+    model.eval()
+    return model, meta
@@ -0,0 +1,76 @@
+# Copyright 2020 The SQLFlow Authors. All rights reserved.
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import torch
+from sqlflow_submitter import db
+from sqlflow_submitter.pytorch.model import load_model
+
+DEFAULT_PREDICT_BATCH_SIZE = 1000
+
+
+def pred(datasource,
+         select,
+         feature_metas,
+         feature_column_names,
+         label_meta,
+         result_table,
+         hdfs_namenode_addr="",
+         hive_location="",
+         hdfs_user="",
+         hdfs_pass="",
+         write_batch_size=100,
+         num_writers=1,
+         partition=""):
+    model, _ = load_model()
+    conn = db.connect_with_data_source(datasource)
+    gen = db.db_generator(conn.driver, conn, select, feature_column_names,
+                          None, feature_metas)
+    result_column_names = feature_column_names + [label_meta["feature_name"]]
+    print("Start predicting PyTorch model...")
+    with db.buffered_db_writer(conn.driver,
+                               conn,
+                               result_table,
+                               result_column_names,
+                               write_batch_size,
+                               hdfs_namenode_addr=hdfs_namenode_addr,
+                               hive_location=hive_location,
+                               hdfs_user=hdfs_user,
+                               hdfs_pass=hdfs_pass,
+                               num_writers=num_writers,
+                               datasource=datasource,
+                               partition=partition) as w:
+        rows = []
+        for row in gen():
+            rows.append(row[0])
+            if len(rows) == DEFAULT_PREDICT_BATCH_SIZE:
+                write_predictions(model, rows, feature_metas,
+                                  feature_column_names, w)
+                rows = []
+        if rows:
+            write_predictions(model, rows, feature_metas,
+                              feature_column_names, w)
+    print("Done predicting. Predict table : %s" % result_table)
+
+
+def write_predictions(model, rows, feature_metas, feature_column_names, w):
+    x = torch.stack(
+        [torch.from_numpy(db.flatten_features(features)) for features in rows])
+    with torch.no_grad():
+        preds = model.predict(x).tolist()
+    for features, p in zip(rows, preds):
+        row = [
+            db.feature_to_string(f, feature_metas[name])
+            for f, name in zip(features, feature_column_names)
+        ]
+        row.append(str(p))
+        w.write(row)
@@ -0,0 +1,162 @@
+# Copyright 2020 The SQLFlow Authors. All rights reserved.
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import numpy as np
+import torch
+from sqlflow_submitter import evaluation, progress, tuning
+from sqlflow_submitter.pytorch.dataset import torch_dataloader
+from sqlflow_submitter.pytorch.model import (MODEL_FILE, build_model,
+                                             num_inputs, save_model)
+from sqlflow_submitter.warm_start import warm_start_file
+
+
+def evaluate_loss(model, loader):
+    loss_fn = model.loss_fn()
+    total, count = 0.0, 0
+    with torch.no_grad():
+        for x, y in loader:
+            total += loss_fn(model(x), model.target(y)).item() * len(x)
+            count += len(x)
+    return total / count if count > 0 else 0.0
+
+
+def predict_loader(model, loader):
+    """Returns the labels, the predictions, and the probabilities of the
+    classes, or None, of the model on the rows of loader."""
+    labels, preds, scores = [], [], []
+    with torch.no_grad():
+        for x, y in loader:
+            labels += y.tolist()
+            preds += model.predict(x).tolist()
+            if hasattr(model, "predict_proba"):
+                scores += model.predict_proba(x).tolist()
+    return np.array(labels), np.array(preds), np.array(
+        scores) if scores else None
+
+
+def fit(model, optimizer, optimizer_params, train_loader, validate_loader,
+        epoch):
+    opt = getattr(torch.optim, optimizer)(model.parameters(),
+                                          lr=optimizer_params.get(
+                                              "learning_rate", 0.001))
+    loss_fn = model.loss_fn()
+    try:
+        steps = len(train_loader)
+    except TypeError:
+        # The loaders of DBDataset, an IterableDataset, have no len.
+        steps = 0
+    for i in range(epoch):
+        model.train()
+        metrics = {}
+        for step, (x, y) in enumerate(train_loader):
+            opt.zero_grad()
+            loss = loss_fn(model(x), model.target(y))
+            loss.backward()
+            opt.step()
+            if step % 100 == 0:
+                print("epoch %d, step %d, loss: %f" % (i, step, loss.item()))
+                progress.report(i + 1,
+                                epoch,
+                                step=step + 1,
+                                steps=steps,
+                                metrics={"loss": loss.item()})
+            metrics["loss"] = loss.item()
+        if validate_loader is not None:
+            model.eval()
+            metrics["val_loss"] = evaluate_loss(model, validate_loader)
+            print("epoch %d, validation loss: %f" % (i, metrics["val_loss"]))
+        progress.report(i + 1, epoch, metrics=metrics)
+    model.eval()
+    return model
+
+
+def train(datasource,
+          select,
+          validation_select,
+          estimator,
+          model_class,
+          model_params,
+          optimizer,
+          optimizer_params,
+          feature_metas,
+          feature_column_names,
+          label_meta,
+          batch_size=64,
+          epoch=1,
+          tuning_params={},
+          warm_start=False):
+    n_inputs = num_inputs(feature_metas, feature_column_names)
+
+    def loaders(batch_size):
+        train_loader = torch_dataloader(datasource, select, feature_metas,
+                                        feature_column_names, label_meta,
+                                        batch_size)
+        validate_loader = None
+        if len(validation_select.strip()) > 0:
+            validate_loader = torch_dataloader(datasource, validation_select,
+                                               feature_metas,
+                                               feature_column_names,
+                                               label_meta, batch_size)
+        return train_loader, validate_loader
+
+    if tuning_params:
+        metric = tuning_params["metric"]
+
+        def score(params):
+            trial_params = dict(params["model_params"])
+            trial_optimizer = trial_params.pop("optimizer", optimizer)
+            train_loader, validate_loader = loaders(
+                params["train_params"]["batch_size"])
+            model = fit(build_model(model_class, n_inputs, trial_params),
+                        trial_optimizer, params["optimizer_params"],
+                        train_loader, validate_loader,
+                        params["train_params"]["epoch"])
+            if metric == "loss":
+                return evaluate_loss(model, validate_loader)
+            labels, preds, scores = predict_loader(model, validate_loader)
+            return evaluation.evaluate_metrics([metric], labels, preds,
+                                               scores)[metric]
+
+        best = tuning.tune(
+            tuning_params, {
+                "model_params": model_params,
+                "optimizer_params": optimizer_params,
+                "train_params": {
+                    "batch_size": batch_size,
+                    "epoch": epoch
+                }
+            }, score)
+        model_params = dict(best["model_params"])
+        optimizer = model_params.pop("optimizer", optimizer)
+        optimizer_params = best["optimizer_params"]
+        batch_size = best["train_params"]["batch_size"]
+        epoch = best["train_params"]["epoch"]
+
+    print("Start training PyTorch model...")
+    train_loader, validate_loader = loaders(batch_size)
+    model = build_model(model_class, n_inputs, model_params)
+    if warm_start:
+        # The warm start model should have the same model.* attributes.
+        model.load_state_dict(torch.load(warm_start_file(MODEL_FILE)))
+    model = fit(model, optimizer, optimizer_params, train_loader,
+                validate_loader, epoch)
+    save_model(
+        model, {
+            "estimator": estimator,
+            "model_class": model_class,
+            "model_params": model_params,
+            "n_inputs": n_inputs,
+            "feature_column_names": feature_column_names,
+            "feature_metas": feature_metas,
+            "label_meta": label_meta,
+        })
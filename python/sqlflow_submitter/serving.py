@@ -0,0 +1,108 @@
+# Copyright 2020 The SQLFlow Authors. All rights reserved.
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""The predictor of pkg/serving. It loads the model in the working
+directory, reads a JSON line like {"x": [[5.1, 3.5]]} from stdin for each
+request, and writes a JSON line like {"predictions": [0]} or
+{"error": "..."} to stdout.
+
+Usage: python -m sqlflow_submitter.serving <framework> <attributes-json>
+"""
+
+import json
+import os
+import sys
+
+import numpy as np
+
+
+def xgboost_predictor(attributes):
+    import xgboost as xgb
+    bst = xgb.Booster({'nthread': 4})
+    bst.load_model("my_model")
+    obj = attributes.get("objective", "")
+
+    def predict(x):
+        preds = bst.predict(xgb.DMatrix(x))
+        if obj.startswith("binary:"):
+            return (preds > 0.5).astype(int)
+        if obj.startswith("multi:") or len(preds.shape) == 2:
+            return np.argmax(preds, axis=1)
+        return preds
+
+    return predict
+
+
+def lightgbm_predictor(attributes):
+    from sqlflow_submitter.lightgbm import predict as lgb_predict
+    bst, meta = lgb_predict.load_model()
+    return lambda x: lgb_predict.predict(bst, meta, x)
+
+
+def catboost_predictor(attributes):
+    from sqlflow_submitter.catboost import predict as cb_predict
+    model, meta = cb_predict.load_model()
+    return lambda x: cb_predict.predict(model, meta, x)
+
+
+def sklearn_predictor(attributes):
+    from sqlflow_submitter.sklearn.train import load_model
+    model = load_model()
+    return lambda x: model.predict(x).ravel()
+
+
+def pytorch_predictor(attributes):
+    import torch
+    from sqlflow_submitter.pytorch.model import load_model
+    model, _ = load_model()
+
+    def predict(x):
+        with torch.no_grad():
+            return model.predict(torch.from_numpy(x).float()).numpy().ravel()
+
+    return predict
+
+
+PREDICTORS = {
+    "xgboost": xgboost_predictor,
+    "lightgbm": lightgbm_predictor,
+    "catboost": catboost_predictor,
+    "sklearn": sklearn_predictor,
+    "pytorch": pytorch_predictor,
+}
+
+
+def serve(predict, stdin, stdout):
+    for line in stdin:
+        try:
+            x = np.array(json.loads(line)["x"], dtype=np.float64)
+            preds = [float(p) for p in np.asarray(predict(x)).ravel()]
+            resp = {"predictions": preds}
+        except Exception as e:  # pylint: disable=broad-except
+            resp = {"error": str(e)}
+        stdout.write(json.dumps(resp) + "\n")
+        stdout.flush()
+
+
+def main(argv):
+    framework, attributes = argv[1], json.loads(argv[2])
+    # Keep the stdout for the protocol, and print the logs of the models
+    # to stderr.
+    stdout = os.fdopen(os.dup(sys.stdout.fileno()), "w")
+    os.dup2(sys.stderr.fileno(), sys.stdout.fileno())
+    predict = PREDICTORS[framework](attributes)
+    serve(predict, sys.stdin, stdout)
+
+
+if __name__ == "__main__":
+    main(sys.argv)
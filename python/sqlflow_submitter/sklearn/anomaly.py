@@ -0,0 +1,67 @@
+# Copyright 2020 The SQLFlow Authors. All rights reserved.
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+import numpy as np
+from sklearn.base import BaseEstimator, OutlierMixin
+from sklearn.neural_network import MLPRegressor
+from sklearn.preprocessing import StandardScaler
+
+
+class AutoEncoder(BaseEstimator, OutlierMixin):
+    """Detects the anomalies by the errors of reconstructing the
+    standardized features by a multi-layer perceptron. Like
+    sklearn.ensemble.IsolationForest, score_samples returns the lower
+    scores for the more abnormal samples, and predict returns -1 for the
+    outliers, the contamination of the training data with the largest
+    errors, and 1 for the inliers."""
+    def __init__(self,
+                 hidden_units=(8, ),
+                 activation="relu",
+                 alpha=1e-4,
+                 learning_rate_init=1e-3,
+                 max_iter=200,
+                 contamination=0.1,
+                 random_state=None):
+        self.hidden_units = hidden_units
+        self.activation = activation
+        self.alpha = alpha
+        self.learning_rate_init = learning_rate_init
+        self.max_iter = max_iter
+        self.contamination = contamination
+        self.random_state = random_state
+
+    def fit(self, x, y=None):
+        self.scaler_ = StandardScaler().fit(x)
+        z = self.scaler_.transform(x)
+        self.mlp_ = MLPRegressor(hidden_layer_sizes=tuple(self.hidden_units),
+                                 activation=self.activation,
+                                 alpha=self.alpha,
+                                 learning_rate_init=self.learning_rate_init,
+                                 max_iter=self.max_iter,
+                                 random_state=self.random_state).fit(z, z)
+        self.offset_ = np.percentile(self.score_samples(x),
+                                     100 * self.contamination)
+        return self
+
+    def score_samples(self, x):
+        """Returns the opposite of the mean squared errors of reconstructing
+        the samples in x."""
+        z = self.scaler_.transform(x)
+        return -np.mean(np.square(self.mlp_.predict(z) - z), axis=1)
+
+    def decision_function(self, x):
+        return self.score_samples(x) - self.offset_
+
+    def predict(self, x):
+        return np.where(self.decision_function(x) < 0, -1, 1)
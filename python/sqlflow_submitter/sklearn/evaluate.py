@@ -0,0 +1,54 @@
+# Copyright 2020 The SQLFlow Authors. All rights reserved.
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+from sqlflow_submitter import db, evaluation
+from sqlflow_submitter.sklearn.train import load_model
+
+
+def evaluate(datasource,
+             select,
+             feature_metas,
+             feature_column_names,
+             label_meta,
+             result_table,
+             validation_metrics=["accuracy_score"],
+             evaluation_params={},
+             hdfs_namenode_addr="",
+             hive_location="",
+             hdfs_user="",
+             hdfs_pass=""):
+    model = load_model()
+    x, y_test, _ = db.read_numpy(datasource, select, feature_metas,
+                                 feature_column_names, label_meta)
+    print("Start evaluating scikit-learn model...")
+    preds = model.predict(x)
+    scores = model.predict_proba(x) if hasattr(model,
+                                               "predict_proba") else None
+
+    evaluate_results = evaluation.evaluate_metrics(validation_metrics, y_test,
+                                                   preds, scores,
+                                                   evaluation_params)
+
+    # write evaluation result to result table
+    conn = db.connect_with_data_source(datasource)
+    evaluation.write_result(conn.driver,
+                            conn,
+                            result_table,
+                            validation_metrics,
+                            evaluate_results,
+                            evaluation_params,
+                            hdfs_namenode_addr=hdfs_namenode_addr,
+                            hive_location=hive_location,
+                            hdfs_user=hdfs_user,
+                            hdfs_pass=hdfs_pass)
+    print("Done evaluating. Result table : %s" % result_table)
@@ -0,0 +1,62 @@
+# Copyright 2020 The SQLFlow Authors. All rights reserved.
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+from sqlflow_submitter import db
+from sqlflow_submitter.sklearn.train import load_model
+
+
+def pred(datasource,
+         select,
+         feature_metas,
+         feature_column_names,
+         result_table,
+         result_column,
+         anomaly_score=False,
+         hdfs_namenode_addr="",
+         hive_location="",
+         hdfs_user="",
+         hdfs_pass="",
+         write_batch_size=100,
+         num_writers=1,
+         partition=""):
+    model = load_model()
+    x, _, rows = db.read_numpy(datasource, select, feature_metas,
+                               feature_column_names, None)
+    print("Start predicting scikit-learn model...")
+    if anomaly_score:
+        # NOTE: the lower score_samples, the more abnormal.
+        preds = -model.score_samples(x)
+    else:
+        preds = model.predict(x)
+    conn = db.connect_with_data_source(datasource)
+    result_column_names = feature_column_names + [result_column]
+    with db.buffered_db_writer(conn.driver,
+                               conn,
+                               result_table,
+                               result_column_names,
+                               write_batch_size,
+                               hdfs_namenode_addr=hdfs_namenode_addr,
+                               hive_location=hive_location,
+                               hdfs_user=hdfs_user,
+                               hdfs_pass=hdfs_pass,
+                               num_writers=num_writers,
+                               datasource=datasource,
+                               partition=partition) as w:
+        for features, p in zip(rows, preds):
+            row = [
+                db.feature_to_string(f, feature_metas[name])
+                for f, name in zip(features, feature_column_names)
+            ]
+            row.append(str(p))
+            w.write(row)
+    print("Done predicting. Predict table : %s" % result_table)
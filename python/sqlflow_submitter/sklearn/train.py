@@ -0,0 +1,101 @@
+# Copyright 2020 The SQLFlow Authors. All rights reserved.
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import importlib
+import json
+import pickle
+
+import sklearn
+from sqlflow_submitter import cross_validation, db, tuning
+
+MODEL_FILE = "model.pkl"
+META_FILE = "model_meta.json"
+
+
+def model_class(name):
+    """Returns the class of name, like sklearn.cluster.KMeans."""
+    module, cls = name.rsplit(".", 1)
+    return getattr(importlib.import_module(module), cls)
+
+
+def fit_predictor(model_class_name, model_params):
+    """Returns a fit_predict function of cross_validation.kfold_metrics,
+    which trains a supervised model by the parameters."""
+    def fit_predict(train_x, train_y, test_x):
+        model = model_class(model_class_name)(**model_params)
+        model.fit(train_x, train_y)
+        scores = model.predict_proba(test_x) if hasattr(
+            model, "predict_proba") else None
+        return model.predict(test_x), scores
+
+    return fit_predict
+
+
+def train(datasource,
+          select,
+          validation_select,
+          estimator,
+          model_class_name,
+          model_params,
+          feature_metas,
+          feature_column_names,
+          label_meta,
+          cv_params={},
+          tuning_params={}):
+    x, y, _ = db.read_numpy(datasource, select, feature_metas,
+                            feature_column_names, label_meta)
+    vx, vy = None, None
+    if label_meta is not None and len(validation_select.strip()) > 0:
+        vx, vy, _ = db.read_numpy(datasource, validation_select,
+                                  feature_metas, feature_column_names,
+                                  label_meta)
+    if label_meta is not None and tuning_params:
+
+        def score(params):
+            fit_predict = fit_predictor(model_class_name,
+                                        params["model_params"])
+            return tuning.validation_score(tuning_params["metric"],
+                                           fit_predict, x, y, vx, vy,
+                                           cv_params.get("kfold", 0))
+
+        model_params = tuning.tune(tuning_params,
+                                   {"model_params": model_params},
+                                   score)["model_params"]
+    if label_meta is not None and cv_params.get("kfold", 0) > 1:
+        print("Start cross-validating %s..." % estimator)
+        cross_validation.cross_validate(
+            x, y, cv_params["kfold"], cv_params["metrics"],
+            fit_predictor(model_class_name, model_params))
+    model = model_class(model_class_name)(**model_params)
+    print("Start training %s..." % estimator)
+    if label_meta is None:
+        model.fit(x)
+    else:
+        model.fit(x, y)
+    if vx is not None:
+        print("Validation score: %f" % model.score(vx, vy))
+    with open(MODEL_FILE, "wb") as f:
+        pickle.dump(model, f)
+    with open(META_FILE, "w") as f:
+        json.dump(
+            {
+                "estimator": estimator,
+                "model_class": model_class_name,
+                "model_params": model_params,
+                "sklearn_version": sklearn.__version__,
+            }, f)
+
+
+def load_model():
+    with open(MODEL_FILE, "rb") as f:
+        return pickle.load(f)
@@ -0,0 +1,168 @@
+# Copyright 2020 The SQLFlow Authors. All rights reserved.
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+import json
+import os
+
+from sqlflow_submitter import db
+
+# DIR is where materialize writes the snapshot of the training data, see
+# snapshot.Dir in pkg/sql/codegen/snapshot. The submitter uploads the files
+# in DIR to the URI of the snapshot after the training.
+DIR = "data_snapshot"
+
+# SNAPSHOT_FILE records the snapshot in the metadata of the model, see
+# readDataSnapshot in pkg/model.
+SNAPSHOT_FILE = "data_snapshot.json"
+
+EXTENSIONS = {"tfrecord": ".tfrecord", "parquet": ".parquet"}
+
+
+def columns_of(feature_column_names, label_meta):
+    """Returns the columns of the snapshot, which are the features and the
+    label, if any."""
+    columns = list(feature_column_names)
+    if label_meta and label_meta["feature_name"]:
+        columns.append(label_meta["feature_name"])
+    return columns
+
+
+def read_rows(datasource, select, columns):
+    """Yields the columns of the rows of select as the database returns
+    them."""
+    conn = db.connect_with_data_source(datasource)
+    specs = {c: {"delimiter": "", "is_sparse": False} for c in columns}
+    gen = db.db_generator(conn.driver, conn, select, columns, {}, specs)
+    for (features, ) in gen():
+        yield [f[0] for f in features]
+
+
+def _to_feature(tf, value):
+    if value is None:
+        return None
+    if isinstance(value, (bool, int)):
+        return tf.train.Feature(int64_list=tf.train.Int64List(value=[value]))
+    if isinstance(value, float):
+        return tf.train.Feature(float_list=tf.train.FloatList(value=[value]))
+    if not isinstance(value, bytes):
+        value = str(value).encode("utf-8")
+    return tf.train.Feature(bytes_list=tf.train.BytesList(value=[value]))
+
+
+def _from_feature(feature):
+    kind = feature.WhichOneof("kind")
+    if kind is None:
+        return None
+    value = getattr(feature, kind).value[0]
+    return value.decode("utf-8") if kind == "bytes_list" else value
+
+
+def write_tfrecord(rows, columns, path):
+    import tensorflow as tf
+    n = 0
+    with tf.io.TFRecordWriter(path) as writer:
+        for row in rows:
+            features = {}
+            for c, v in zip(columns, row):
+                f = _to_feature(tf, v)
+                if f is not None:
+                    features[c] = f
+            example = tf.train.Example(features=tf.train.Features(
+                feature=features))
+            writer.write(example.SerializeToString())
+            n += 1
+    return n
+
+
+def read_tfrecord(path, columns):
+    import tensorflow as tf
+    for record in tf.compat.v1.io.tf_record_iterator(path):
+        example = tf.train.Example.FromString(record)
+        feature = example.features.feature
+        yield [
+            _from_feature(feature[c]) if c in feature else None
+            for c in columns
+        ]
+
+
+def write_parquet(rows, columns, path):
+    import pyarrow as pa
+    import pyarrow.parquet as pq
+    data = {c: [] for c in columns}
+    for row in rows:
+        for c, v in zip(columns, row):
+            data[c].append(v)
+    pq.write_table(pa.Table.from_pydict(data), path)
+    return len(data[columns[0]]) if columns else 0
+
+
+def read_parquet(path, columns):
+    import pyarrow.parquet as pq
+    data = pq.read_table(path, columns=columns).to_pydict()
+    for i in range(len(data[columns[0]]) if columns else 0):
+        yield [data[c][i] for c in columns]
+
+
+def path_of(name, fmt):
+    return os.path.join(DIR, name + EXTENSIONS[fmt])
+
+
+def materialize(datasource, select, validation_select, feature_column_names,
+                label_meta, uri, fmt):
+    """Writes the rows of select and validation_select into the files of
+    the snapshot in DIR, and records the snapshot in SNAPSHOT_FILE. It
+    returns the paths of the training and the validation files, the latter
+    is "" if there is no validation_select."""
+    if fmt not in EXTENSIONS:
+        raise ValueError("unsupported snapshot format %s" % fmt)
+    write = write_tfrecord if fmt == "tfrecord" else write_parquet
+    columns = columns_of(feature_column_names, label_meta)
+    if not os.path.exists(DIR):
+        os.makedirs(DIR)
+    paths, rows = [], {}
+    for name, stmt in [("train", select), ("validation", validation_select)]:
+        if not stmt:
+            paths.append("")
+            continue
+        path = path_of(name, fmt)
+        rows[os.path.basename(path)] = write(
+            read_rows(datasource, stmt, columns), columns, path)
+        paths.append(path)
+    with open(SNAPSHOT_FILE, "w") as f:
+        json.dump(
+            {
+                "uri": uri,
+                "format": fmt,
+                "columns": columns,
+                "rows": rows
+            }, f)
+    print("Saved the snapshot of the training data %s to %s" % (rows, uri))
+    return paths[0], paths[1]
+
+
+def db_generator(path, fmt, feature_column_names, label_spec,
+                 feature_specs):
+    """Works like db.db_generator, and reads the rows from the snapshot
+    file at path instead of the database."""
+    read = read_tfrecord if fmt == "tfrecord" else read_parquet
+    columns = columns_of(feature_column_names, label_spec)
+    label_idx = len(feature_column_names) \
+        if len(columns) > len(feature_column_names) else None
+
+    def reader():
+        for row in read(path, columns):
+            yield db.read_row(row, columns, label_idx, feature_column_names,
+                              label_spec, feature_specs)
+
+    return reader
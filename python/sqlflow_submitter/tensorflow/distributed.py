@@ -0,0 +1,73 @@
+# Copyright 2020 The SQLFlow Authors. All rights reserved.
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import contextlib
+import json
+import os
+
+import tensorflow as tf
+
+from .get_tf_version import tf_is_version2
+
+# The strategies of the distributed training out of PAI, set by WITH
+# train.strategy, see pkg/sql/codegen/tensorflow.
+MULTI_WORKER_MIRRORED = "multi_worker_mirrored"
+PARAMETER_SERVER = "parameter_server"
+
+
+def tf_config():
+    """Returns TF_CONFIG, which is set in the pods of the distributed
+    training by the TFJob of the workflow step, or None out of the pods."""
+    config = os.environ.get("TF_CONFIG", "")
+    return json.loads(config) if config else None
+
+
+def is_chief():
+    """Returns True if the program runs stand-alone or in the chief of the
+    distributed training, which saves the trained model."""
+    config = tf_config()
+    if config is None:
+        return True
+    task = config.get("task", {})
+    if "chief" in config.get("cluster", {}):
+        return task.get("type") == "chief"
+    return task.get("type") == "worker" and task.get("index", 0) == 0
+
+
+def make_strategy(strategy):
+    """Returns the tf.distribute strategy of the distributed training, or
+    None if the program runs stand-alone without TF_CONFIG."""
+    if not strategy or tf_config() is None:
+        return None
+    if strategy == MULTI_WORKER_MIRRORED:
+        if tf_is_version2():
+            return tf.distribute.experimental.MultiWorkerMirroredStrategy()
+        return tf.contrib.distribute.CollectiveAllReduceStrategy()
+    if strategy == PARAMETER_SERVER:
+        if tf_is_version2():
+            return tf.distribute.experimental.ParameterServerStrategy()
+        return tf.contrib.distribute.ParameterServerStrategy()
+    raise ValueError("unknown distributed strategy %s" % strategy)
+
+
+@contextlib.contextmanager
+def _no_scope():
+    yield
+
+
+def strategy_scope(strategy):
+    """Returns the scope of strategy to build the Keras models in, or a
+    scope doing nothing if strategy is None."""
+    if strategy is None:
+        return _no_scope()
+    return strategy.scope()
@@ -16,6 +16,7 @@ import sys
 import types
 
 import tensorflow as tf
+from sqlflow_submitter import evaluation
 from sqlflow_submitter.db import buffered_db_writer, connect_with_data_source
 
 from . import metrics
@@ -39,6 +40,7 @@ def evaluate(datasource,
              label_meta={},
              model_params={},
              validation_metrics=["Accuracy"],
+             evaluation_params={},
              save="",
              batch_size=1,
              validation_steps=None,
@@ -107,7 +109,8 @@ def evaluate(datasource,
                              hdfs_namenode_addr=hdfs_namenode_addr,
                              hive_location=hive_location,
                              hdfs_user=hdfs_user,
-                             hdfs_pass=hdfs_pass)
+                             hdfs_pass=hdfs_pass,
+                             evaluation_params=evaluation_params)
 
 
 def estimator_evaluate(estimator, eval_dataset, validation_metrics):
@@ -176,9 +179,28 @@ def keras_evaluate(keras_model, eval_dataset_fn, save, keras_model_pkg,
     return result_metrics
 
 
-def write_result_metrics(result_metrics, metric_name_list, result_table,
-                         driver, conn, hdfs_namenode_addr, hive_location,
-                         hdfs_user, hdfs_pass):
+def write_result_metrics(result_metrics,
+                         metric_name_list,
+                         result_table,
+                         driver,
+                         conn,
+                         hdfs_namenode_addr,
+                         hive_location,
+                         hdfs_user,
+                         hdfs_pass,
+                         evaluation_params={}):
+    if evaluation_params.get("result_schema") == evaluation.LONG_SCHEMA:
+        evaluation.write_result(driver,
+                                conn,
+                                result_table,
+                                metric_name_list,
+                                result_metrics,
+                                evaluation_params,
+                                hdfs_namenode_addr=hdfs_namenode_addr,
+                                hive_location=hive_location,
+                                hdfs_user=hdfs_user,
+                                hdfs_pass=hdfs_pass)
+        return
     # NOTE: assume that the result table is already created with columns:
     # loss | metric_names ...
     column_names = metric_name_list
@@ -118,6 +118,7 @@ def explain_boosted_trees(datasource, estimator, input_fn, plot_type,
     df_dfc = pd.DataFrame([pred['dfc'] for pred in pred_dicts])
     dfc_mean = df_dfc.abs().mean()
     gain = estimator.experimental_feature_importances(normalize=True)
+    explainer.save_attributions(dfc_mean.index, dfc_mean.values)
     if result_table != "":
         if is_pai:
             write_dfc_result(dfc_mean, gain, result_table, "pai_maxcompute",
@@ -167,6 +168,8 @@ def explain_dnns(datasource, estimator, shap_dataset, plot_type, result_table,
         shap_dataset_summary = shap_dataset
     shap_values = shap.KernelExplainer(
         predict, shap_dataset_summary).shap_values(shap_dataset, l1_reg="aic")
+    explainer.save_attributions(feature_column_names,
+                                explainer.mean_abs_shap_values(shap_values))
     if result_table != "":
         if is_pai:
             write_shap_values(shap_values, "pai_maxcompute", None,
@@ -186,7 +189,7 @@ def explain_dnns(datasource, estimator, shap_dataset, plot_type, result_table,
 
 def create_explain_result_table(conn, result_table):
     column_clause = ""
-    if conn.driver == "mysql":
+    if conn.driver in ("mysql", "postgres", "sqlite3"):
         column_clause = "(feature VARCHAR(255), dfc float, gain float)"
     else:
         column_clause = "(feature STRING, dfc float, gain float)"
@@ -16,7 +16,7 @@ import functools
 
 import numpy as np
 import tensorflow as tf
-from sqlflow_submitter import db
+from sqlflow_submitter import db, imbalance, snapshot
 
 
 def parse_sparse_feature(features, label, feature_column_names, feature_metas):
@@ -61,7 +61,9 @@ def input_fn(select,
              is_pai=False,
              pai_table="",
              num_workers=1,
-             worker_id=0):
+             worker_id=0,
+             snapshot_path="",
+             snapshot_format=""):
     feature_types = []
     shapes = []
     for name in feature_column_names:
@@ -79,6 +81,10 @@ def input_fn(select,
                            feature_metas,
                            slice_id=worker_id,
                            slice_count=num_workers)
+    elif snapshot_path:
+        gen = snapshot.db_generator(snapshot_path, snapshot_format,
+                                    feature_column_names, label_meta,
+                                    feature_metas)
     else:
         conn = db.connect_with_data_source(datasource)
         gen = db.db_generator(conn.driver, conn, select, feature_column_names,
@@ -176,6 +182,38 @@ def pai_dataset(table,
                               label_spec, feature_specs))
 
 
+def imbalance_dataset(dataset,
+                      class_weights=None,
+                      repeats=None,
+                      is_estimator=True):
+    """Repeats the rows of each label by repeats, or weights them by
+    class_weights, which the estimators read from the feature
+    imbalance.WEIGHT_COLUMN, and the Keras models as the sample weights."""
+    def label_index(label):
+        return tf.reshape(tf.cast(label, tf.int64), [])
+
+    if repeats:
+        r = tf.constant(imbalance.weight_list(repeats), dtype=tf.int64)
+        dataset = dataset.flat_map(
+            lambda features, label: tf.data.Dataset.from_tensors(
+                (features, label)).repeat(tf.gather(r, label_index(label))))
+    if class_weights:
+        w = tf.constant(imbalance.weight_list(class_weights), dtype=tf.float32)
+        if is_estimator:
+
+            def add_weight(features, label):
+                features = dict(features)
+                features[imbalance.WEIGHT_COLUMN] = tf.reshape(
+                    tf.gather(w, label_index(label)), [1])
+                return features, label
+
+            dataset = dataset.map(add_weight)
+        else:
+            dataset = dataset.map(lambda features, label: (
+                features, label, tf.gather(w, label_index(label))))
+    return dataset
+
+
 def get_dataset_fn(select,
                    validate_select,
                    datasource,
@@ -190,7 +228,11 @@ def get_dataset_fn(select,
                    shuffle_size,
                    num_workers=1,
                    worker_id=0,
-                   is_estimator=True):
+                   is_estimator=True,
+                   class_weights=None,
+                   repeats=None,
+                   snapshot_paths=("", ""),
+                   snapshot_format=""):
     def train_input_fn():
         train_dataset = input_fn(select,
                                  datasource,
@@ -200,7 +242,11 @@ def get_dataset_fn(select,
                                  is_pai=is_pai,
                                  pai_table=pai_table,
                                  num_workers=num_workers,
-                                 worker_id=worker_id)
+                                 worker_id=worker_id,
+                                 snapshot_path=snapshot_paths[0],
+                                 snapshot_format=snapshot_format)
+        train_dataset = imbalance_dataset(train_dataset, class_weights,
+                                          repeats, is_estimator)
         train_dataset = train_dataset.cache("cache_train").shuffle(
             shuffle_size).batch(batch_size).repeat(epochs if epochs else 1)
         return train_dataset
@@ -212,7 +258,9 @@ def get_dataset_fn(select,
                                     feature_metas,
                                     label_meta,
                                     is_pai=is_pai,
-                                    pai_table=pai_val_table)
+                                    pai_table=pai_val_table,
+                                    snapshot_path=snapshot_paths[1],
+                                    snapshot_format=snapshot_format)
         validate_dataset = validate_dataset.cache("cache_validation").batch(
             batch_size)
         return validate_dataset
@@ -47,7 +47,8 @@ else:
 def keras_predict(estimator, model_params, save, result_table, is_pai,
                   pai_table, feature_column_names, feature_metas,
                   result_col_name, datasource, select, hdfs_namenode_addr,
-                  hive_location, hdfs_user, hdfs_pass):
+                  hive_location, hdfs_user, hdfs_pass, write_batch_size=100,
+                  num_writers=1, partition=""):
     classifier = estimator(**model_params)
     classifier_pkg = sys.modules[estimator.__module__]
     conn = None
@@ -97,9 +98,18 @@ def keras_predict(estimator, model_params, save, result_table, is_pai,
     pred_dataset = eval_input_fn(1, cache=True).make_one_shot_iterator()
     column_names = feature_column_names[:]
     column_names.append(result_col_name)
-    with db.buffered_db_writer(driver, conn, result_table, column_names, 100,
-                               hdfs_namenode_addr, hive_location, hdfs_user,
-                               hdfs_pass) as w:
+    with db.buffered_db_writer(driver,
+                               conn,
+                               result_table,
+                               column_names,
+                               write_batch_size,
+                               hdfs_namenode_addr,
+                               hive_location,
+                               hdfs_user,
+                               hdfs_pass,
+                               num_writers=num_writers,
+                               datasource=datasource,
+                               partition=partition) as w:
         for features in pred_dataset:
             result = classifier.predict_on_batch(features)
             result = classifier_pkg.prepare_prediction_column(result[0])
@@ -124,7 +134,8 @@ def estimator_predict(estimator, model_params, save, result_table,
                       feature_column_names, feature_column_names_map,
                       feature_columns, feature_metas, result_col_name,
                       datasource, select, hdfs_namenode_addr, hive_location,
-                      hdfs_user, hdfs_pass, is_pai, pai_table):
+                      hdfs_user, hdfs_pass, is_pai, pai_table,
+                      write_batch_size=100, num_writers=1, partition=""):
     if not is_pai:
         conn = db.connect_with_data_source(datasource)
 
@@ -213,9 +224,18 @@ def estimator_predict(estimator, model_params, save, result_table,
         return imported.signatures["predict"](
             examples=tf.constant([example.SerializeToString()]))
 
-    with db.buffered_db_writer(driver, conn, result_table, column_names, 100,
-                               hdfs_namenode_addr, hive_location, hdfs_user,
-                               hdfs_pass) as w:
+    with db.buffered_db_writer(driver,
+                               conn,
+                               result_table,
+                               column_names,
+                               write_batch_size,
+                               hdfs_namenode_addr,
+                               hive_location,
+                               hdfs_user,
+                               hdfs_pass,
+                               num_writers=num_writers,
+                               datasource=datasource,
+                               partition=partition) as w:
         for features in predict_generator:
             result = predict(features)
             row = []
@@ -254,7 +274,10 @@ def pred(datasource,
          hdfs_user="",
          hdfs_pass="",
          is_pai=False,
-         pai_table=""):
+         pai_table="",
+         write_batch_size=100,
+         num_writers=1,
+         partition=""):
     if not is_pai:
         conn = db.connect_with_data_source(datasource)
     model_params.update(feature_columns)
@@ -271,7 +294,10 @@ def pred(datasource,
         keras_predict(estimator, model_params, save, result_table, is_pai,
                       pai_table, feature_column_names, feature_metas,
                       result_col_name, datasource, select, hdfs_namenode_addr,
-                      hive_location, hdfs_user, hdfs_pass)
+                      hive_location, hdfs_user, hdfs_pass,
+                      write_batch_size=write_batch_size,
+                      num_writers=num_writers,
+                      partition=partition)
     else:
         model_params['model_dir'] = save
         print("Start predicting using estimator model...")
@@ -280,6 +306,8 @@ def pred(datasource,
                           feature_columns, feature_metas, result_col_name,
                           datasource, select, hdfs_namenode_addr,
                           hive_location, hdfs_user, hdfs_pass, is_pai,
-                          pai_table)
+                          pai_table, write_batch_size=write_batch_size,
+                          num_writers=num_writers,
+                          partition=partition)
 
     print("Done predicting. Predict table : %s" % result_table)
@@ -21,9 +21,11 @@ import types
 
 import numpy as np
 import tensorflow as tf
+from sqlflow_submitter import imbalance, snapshot
 from sqlflow_submitter.db import (connect_with_data_source, db_generator,
                                   parseMaxComputeDSN)
 
+from .distributed import make_strategy
 from .get_tf_version import tf_is_version2
 from .input_fn import get_dataset_fn
 from .pai_distributed import define_tf_flags, set_oss_environs
@@ -62,7 +64,13 @@ def train(datasource,
           log_every_n_iter=10,
           is_pai=False,
           pai_table="",
-          pai_val_table=""):
+          pai_val_table="",
+          strategy="",
+          class_weight=None,
+          oversample_minority=False,
+          snapshot_uri="",
+          snapshot_format="tfrecord",
+          tensorboard_dir=""):
     if isinstance(estimator, types.FunctionType):
         is_estimator = False
     else:
@@ -88,6 +96,9 @@ def train(datasource,
             is_distributed = True
         num_workers = len(FLAGS.worker_hosts.split(","))
         worker_id = FLAGS.task_index
+    # out of PAI, the distributed training runs in the pods of the TFJob of
+    # the workflow step, which sets TF_CONFIG.
+    dist_strategy = None if is_pai else make_strategy(strategy)
 
     # TODO(typhoonzero): remove this after update the keras models.
     # copy feature_name to name field for Keras functional models:
@@ -95,6 +106,29 @@ def train(datasource,
     for k in feature_metas:
         feature_metas[k]["name"] = feature_metas[k]["feature_name"]
 
+    # train.class_weight and train.oversample_minority count the labels of
+    # the training data to weight or repeat the rows of the classes.
+    class_weights, repeats = None, None
+    if class_weight is not None or oversample_minority:
+        counts = imbalance.count_labels(datasource, select, label_meta)
+        print("Label counts of the training data: %s" % dict(counts))
+        if oversample_minority:
+            repeats = imbalance.oversample_repeats(counts)
+        else:
+            class_weights = imbalance.class_weights(counts, class_weight)
+            if is_estimator:
+                model_params["weight_column"] = imbalance.WEIGHT_COLUMN
+
+    # train.data_snapshot materializes the training data before the
+    # training, so the epochs read the snapshot instead of the database.
+    snapshot_paths = ("", "")
+    if snapshot_uri:
+        snapshot_paths = snapshot.materialize(datasource, select,
+                                              validation_select,
+                                              feature_column_names,
+                                              label_meta, snapshot_uri,
+                                              snapshot_format)
+
     train_dataset_fn, val_dataset_fn = get_dataset_fn(
         select,
         validation_select,
@@ -110,7 +144,11 @@ def train(datasource,
         1000,
         num_workers=num_workers,
         worker_id=worker_id,
-        is_estimator=is_estimator)
+        is_estimator=is_estimator,
+        class_weights=class_weights,
+        repeats=repeats,
+        snapshot_paths=snapshot_paths,
+        snapshot_format=snapshot_format)
 
     if not is_estimator:  # keras
         if isinstance(estimator, types.FunctionType):
@@ -119,14 +157,15 @@ def train(datasource,
         keras_train_and_save(estimator, model_params, save, is_pai, FLAGS,
                              train_dataset_fn, val_dataset_fn, label_meta,
                              epoch, verbose, validation_metrics,
-                             validation_steps)
+                             validation_steps, dist_strategy, tensorboard_dir)
     else:
         estimator_train_and_save(estimator, model_params, save, is_pai, FLAGS,
                                  train_dataset_fn, val_dataset_fn,
                                  log_every_n_iter, max_steps,
                                  validation_start_delay_secs,
                                  validation_throttle_secs,
-                                 save_checkpoints_steps, validation_metrics)
+                                 save_checkpoints_steps, validation_metrics,
+                                 dist_strategy)
 
     # remove cache files
     any(map(os.remove, glob.glob('cache_train.*')))
@@ -15,6 +15,7 @@ import tensorflow as tf
 
 from . import metrics
 from .diag import check_and_load_estimator
+from .distributed import is_chief
 from .get_tf_version import tf_is_version2
 from .input_fn import input_fn
 from .pai_distributed import make_estimator_distributed_runconfig
@@ -24,18 +25,29 @@ def estimator_train_and_save(estimator, model_params, save, is_pai, FLAGS,
                              train_dataset_fn, val_dataset_fn,
                              log_every_n_iter, train_max_steps,
                              eval_start_delay_secs, eval_throttle_secs,
-                             save_checkpoints_steps, metric_names):
+                             save_checkpoints_steps, metric_names,
+                             dist_strategy=None):
 
     print("Start training using estimator model...")
 
     is_distributed = False
     if is_pai and len(FLAGS.worker_hosts.split(",")) > 1:
         is_distributed = True
-    model_params["config"] = make_estimator_distributed_runconfig(
-        FLAGS,
-        estimator,
-        is_distributed,
-        save_checkpoints_steps=save_checkpoints_steps)
+    if dist_strategy is not None:
+        model_params["config"] = tf.estimator.RunConfig(
+            save_checkpoints_steps=save_checkpoints_steps,
+            train_distribute=dist_strategy)
+        # tf.estimator.train_and_evaluate runs the tasks of the distributed
+        # training, including the parameter servers, so evaluate on the
+        # training data if there is no validation data.
+        if val_dataset_fn is None:
+            val_dataset_fn = train_dataset_fn
+    else:
+        model_params["config"] = make_estimator_distributed_runconfig(
+            FLAGS,
+            estimator,
+            is_distributed,
+            save_checkpoints_steps=save_checkpoints_steps)
     if is_pai:
         print("Using checkpoint path: %s" % FLAGS.checkpointDir)
         model_params["model_dir"] = FLAGS.checkpointDir
@@ -55,7 +67,7 @@ def estimator_train_and_save(estimator, model_params, save, is_pai, FLAGS,
                              val_dataset_fn, log_every_n_iter, train_max_steps,
                              eval_start_delay_secs, eval_throttle_secs)
 
-    if is_pai and FLAGS.task_index != 0:
+    if (is_pai and FLAGS.task_index != 0) or not is_chief():
         print("skip exporting model on worker != 0")
         return
     # export saved model for prediction
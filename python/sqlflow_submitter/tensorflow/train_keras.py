@@ -14,10 +14,12 @@
 import sys
 
 import tensorflow as tf
+from sqlflow_submitter import progress
 from sqlflow_submitter.pai import model
 
 from . import metrics
 from .diag import check_and_load_estimator
+from .distributed import strategy_scope
 from .get_tf_version import tf_is_version2
 from .input_fn import input_fn
 from .pai_distributed import (dump_into_tf_config,
@@ -27,7 +29,8 @@ from .train_estimator import estimator_train_compiled
 
 def keras_train_and_save(estimator, model_params, save, is_pai, FLAGS,
                          train_dataset_fn, val_dataset_fn, label_meta, epochs,
-                         verbose, metric_names, validation_steps):
+                         verbose, metric_names, validation_steps,
+                         dist_strategy=None, tensorboard_dir=""):
     print("Start training using keras model...")
     # remove optimizer param from model_params and use it when call "compile()"
     optimizer = None
@@ -71,9 +74,13 @@ def keras_train_and_save(estimator, model_params, save, is_pai, FLAGS,
     else:
         validate_dataset = None
 
-    classifier = check_and_load_estimator(estimator, model_params)
-
-    classifier.compile(optimizer=optimizer, loss=loss, metrics=keras_metrics)
+    # The model of the distributed training out of PAI is built in the
+    # scope of dist_strategy.
+    with strategy_scope(dist_strategy):
+        classifier = check_and_load_estimator(estimator, model_params)
+        classifier.compile(optimizer=optimizer,
+                           loss=loss,
+                           metrics=keras_metrics)
 
     if is_pai and len(FLAGS.worker_hosts.split(",")) > 1:
         # train keras model distributed
@@ -123,6 +130,12 @@ def keras_train_and_save(estimator, model_params, save, is_pai, FLAGS,
         print("Done training, model exported to: %s" % export_path)
         return
 
+    callbacks = [progress.keras_callback()]
+    # The event files under tensorboard_dir are saved with the model, so
+    # TensorBoard can show the training curves after the training.
+    if tensorboard_dir:
+        callbacks.append(
+            tf.keras.callbacks.TensorBoard(log_dir=tensorboard_dir))
     if hasattr(classifier, 'sqlflow_train_loop'):
         classifier.sqlflow_train_loop(train_dataset)
     else:
@@ -139,13 +152,15 @@ def keras_train_and_save(estimator, model_params, save, is_pai, FLAGS,
                                      epochs=epochs if epochs else
                                      classifier.default_training_epochs(),
                                      validation_data=validate_dataset,
-                                     verbose=verbose)
+                                     verbose=verbose,
+                                     callbacks=callbacks)
         else:
             history = classifier.fit(train_dataset,
                                      validation_steps=validation_steps,
                                      epochs=epochs if epochs else
                                      classifier.default_training_epochs(),
-                                     verbose=verbose)
+                                     verbose=verbose,
+                                     callbacks=callbacks)
         train_keys = []
         val_keys = []
         for k in history.history.keys():
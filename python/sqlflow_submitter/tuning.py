@@ -0,0 +1,157 @@
+# Copyright 2020 The SQLFlow Authors. All rights reserved.
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import copy
+import itertools
+import json
+import math
+
+import numpy as np
+from sqlflow_submitter import cross_validation, evaluation
+
+# TUNING_FILE records the trials of the hyperparameter tuning in the model
+# directory, which pkg/model saves into the metadata of the model.
+TUNING_FILE = "tuning.json"
+
+GRID = "grid"
+RANDOM = "random"
+BAYESIAN = "bayesian"
+
+# LOWER_IS_BETTER are the metrics to minimize besides the ones named like
+# *_loss and *_error.
+LOWER_IS_BETTER = ["RMSE", "MAE", "loss"]
+
+
+def lower_is_better(metric):
+    return metric in LOWER_IS_BETTER or metric.endswith(
+        "_loss") or metric.endswith("_error")
+
+
+def _encode(points, search_space):
+    """Maps the indices of the candidates of points into [0, 1] for the
+    Gaussian process."""
+    scales = np.array([max(len(d["candidates"]) - 1, 1) for d in search_space],
+                      dtype=float)
+    return np.array(points, dtype=float) / scales
+
+
+def _rbf(a, b, length_scale=0.3):
+    d = ((a[:, None, :] - b[None, :, :])**2).sum(axis=2)
+    return np.exp(-d / (2 * length_scale**2))
+
+
+def _expected_improvement(tried_x, tried_y, x):
+    """Returns the expected improvement of x over the max of tried_y by a
+    Gaussian process fitted on tried_x and the standardized tried_y."""
+    y = np.array(tried_y, dtype=float)
+    y = (y - y.mean()) / (y.std() if y.std() > 0 else 1.0)
+    k = _rbf(tried_x, tried_x) + 1e-6 * np.eye(len(tried_x))
+    ks = _rbf(x, tried_x)
+    k_inv = np.linalg.inv(k)
+    mu = ks.dot(k_inv).dot(y)
+    sigma = np.sqrt(np.maximum(1 - (ks.dot(k_inv) * ks).sum(axis=1), 1e-12))
+    z = (mu - y.max()) / sigma
+    cdf = np.array([0.5 * (1 + math.erf(v / math.sqrt(2))) for v in z])
+    pdf = np.exp(-z**2 / 2) / math.sqrt(2 * math.pi)
+    return (mu - y.max()) * cdf + sigma * pdf
+
+
+def search(algorithm, search_space, max_trials, random_state, objective):
+    """Calls objective with the indices of the candidates of each trial,
+    and returns the indices of the trials and the results of objective in
+    order. The algorithm maximizes objective. "grid" tries the combinations
+    of the candidates in order, "random" tries the random ones, and
+    "bayesian" tries the random ones first, and then the ones of the max
+    expected improvement."""
+    points = list(
+        itertools.product(*[range(len(d["candidates"]))
+                            for d in search_space]))
+    n = min(max_trials, len(points))
+    rng = np.random.RandomState(random_state)
+    if algorithm == GRID:
+        order = list(range(n))
+    elif algorithm in (RANDOM, BAYESIAN):
+        order = list(rng.permutation(len(points)))
+    else:
+        raise ValueError("unsupported tuning.algorithm %s" % algorithm)
+    n_random = n if algorithm != BAYESIAN else min(n, 3)
+    tried, results = order[:n_random], []
+    for i in tried:
+        results.append(objective(points[i]))
+    encoded = _encode(points, search_space)
+    while len(tried) < n:
+        rest = [i for i in range(len(points)) if i not in tried]
+        ei = _expected_improvement(encoded[tried], results, encoded[rest])
+        tried.append(rest[int(np.argmax(ei))])
+        results.append(objective(points[tried[-1]]))
+    return [points[i] for i in tried], results
+
+
+def tune(tuning_params, params, score):
+    """Searches the hyperparameters in tuning_params, see
+    pkg/sql/codegen/tuning. Each trial calls score(trial_params), which
+    trains a model by trial_params and returns the metric of the model on
+    the validation data, where trial_params is a copy of params, a dict
+    from the groups like "model_params" to the parameters, updated by the
+    candidates of the trial. It writes the trials into TUNING_FILE, and
+    returns the trial_params of the best trial."""
+    space = tuning_params["search_space"]
+    metric = tuning_params["metric"]
+    sign = -1.0 if lower_is_better(metric) else 1.0
+    trials = []
+
+    def trial_params_of(point):
+        trial_params = copy.deepcopy(params)
+        attributes = dict()
+        for i, d in zip(point, space):
+            trial_params.setdefault(d["group"], dict())[d["name"]] = \
+                d["candidates"][i]
+            attributes[d["attribute"]] = d["candidates"][i]
+        return trial_params, attributes
+
+    def objective(point):
+        trial_params, attributes = trial_params_of(point)
+        value = float(score(trial_params))
+        trials.append({"params": attributes, "score": value})
+        print("Trial %d: %s, %s=%f" %
+              (len(trials), attributes, metric, value))
+        return sign * value
+
+    print("Start tuning the hyperparameters by %s search..." %
+          tuning_params["algorithm"])
+    points, results = search(tuning_params["algorithm"], space,
+                             tuning_params["max_trials"],
+                             tuning_params["random_state"], objective)
+    best = int(np.argmax(results))
+    print("The best trial: %s" % trials[best])
+    with open(TUNING_FILE, "w") as f:
+        json.dump(
+            {
+                "algorithm": tuning_params["algorithm"],
+                "metric": metric,
+                "trials": trials,
+                "best": best,
+            }, f)
+    return trial_params_of(points[best])[0]
+
+
+def validation_score(metric, fit_predict, x, y, vx=None, vy=None, kfold=0):
+    """Returns the metric of the model by fit_predict, like the argument of
+    cross_validation.kfold_metrics, trained on x and y, on vx and vy, or the
+    mean metric of the k-fold cross-validation on x and y if vx is
+    None."""
+    if vx is not None:
+        preds, scores = fit_predict(x, y, vx)
+        return evaluation.evaluate_metrics([metric], vy, preds, scores)[metric]
+    return cross_validation.kfold_metrics(x, y, kfold, [metric],
+                                          fit_predict)[metric]["mean"]
@@ -0,0 +1,25 @@
+# Copyright 2020 The SQLFlow Authors. All rights reserved.
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import os
+
+# SQLFlow loads the model of WITH train.warm_start into WARM_START_DIR
+# under the working directory before the training, see
+# pkg/sql/codegen/warmstart.
+WARM_START_DIR = "warm_start"
+
+
+def warm_start_file(name):
+    """Returns the path of the file name of the model to warm start from,
+    like warm_start_file("my_model")."""
+    return os.path.join(WARM_START_DIR, name)
@@ -14,20 +14,9 @@
 import numpy as np
 import sklearn
 import xgboost as xgb
-# yapf: disable
-from sklearn.metrics import (accuracy_score, average_precision_score,
-                             balanced_accuracy_score, brier_score_loss,
-                             cohen_kappa_score, explained_variance_score,
-                             f1_score, fbeta_score, hamming_loss, hinge_loss,
-                             log_loss, mean_absolute_error, mean_squared_error,
-                             mean_squared_log_error, median_absolute_error,
-                             precision_score, r2_score, recall_score,
-                             roc_auc_score, zero_one_loss)
-from sqlflow_submitter import db
+from sqlflow_submitter import db, evaluation
 from sqlflow_submitter.xgboost.dataset import xgb_dataset
 
-# yapf: enable
-
 DEFAULT_PREDICT_BATCH_SIZE = 10000
 
 
@@ -38,6 +27,7 @@ def evaluate(datasource,
              label_meta,
              result_table,
              validation_metrics=["accuracy_score"],
+             evaluation_params={},
              is_pai=False,
              hdfs_namenode_addr="",
              hive_location="",
@@ -68,8 +58,9 @@ def evaluate(datasource,
     feature_file_id = 0
     for pred_dmatrix in dpred:
         evaluate_and_store_result(bst, pred_dmatrix, feature_file_id,
-                                  validation_metrics, model_params,
-                                  feature_column_names, label_meta, is_pai,
+                                  validation_metrics, evaluation_params,
+                                  model_params, feature_column_names,
+                                  label_meta, is_pai,
                                   conn, result_table, hdfs_namenode_addr,
                                   hive_location, hdfs_user, hdfs_pass)
         feature_file_id += 1
@@ -77,10 +68,14 @@ def evaluate(datasource,
 
 
 def evaluate_and_store_result(bst, dpred, feature_file_id, validation_metrics,
-                              model_params, feature_column_names, label_meta,
-                              is_pai, conn, result_table, hdfs_namenode_addr,
-                              hive_location, hdfs_user, hdfs_pass):
+                              evaluation_params, model_params,
+                              feature_column_names, label_meta, is_pai, conn,
+                              result_table, hdfs_namenode_addr, hive_location,
+                              hdfs_user, hdfs_pass):
     preds = bst.predict(dpred)
+    # NOTE: the classifiers predict the probabilities of the classes,
+    # unless the objective is multi:softmax.
+    scores = preds
     # FIXME(typhoonzero): copied from predict.py
     if model_params:
         obj = model_params["objective"]
@@ -116,27 +111,22 @@ def evaluate_and_store_result(bst, dpred, feature_file_id, validation_metrics,
         y_test_list.append(label)
     y_test = np.array(y_test_list)
 
-    evaluate_results = dict()
-    for metric_name in validation_metrics:
-        metric_value = eval("%s(y_test, preds)" % metric_name)
-        evaluate_results[metric_name] = metric_value
+    evaluate_results = evaluation.evaluate_metrics(validation_metrics, y_test,
+                                                   preds, scores,
+                                                   evaluation_params)
 
     # write evaluation result to result table
     if is_pai:
         driver = "pai_maxcompute"
     else:
         driver = conn.driver
-    result_columns = ["loss"] + validation_metrics
-    with db.buffered_db_writer(driver,
-                               conn,
-                               result_table,
-                               result_columns,
-                               100,
-                               hdfs_namenode_addr=hdfs_namenode_addr,
-                               hive_location=hive_location,
-                               hdfs_user=hdfs_user,
-                               hdfs_pass=hdfs_pass) as w:
-        row = ["0.0"]
-        for mn in validation_metrics:
-            row.append(str(evaluate_results[mn]))
-        w.write(row)
+    evaluation.write_result(driver,
+                            conn,
+                            result_table,
+                            validation_metrics,
+                            evaluate_results,
+                            evaluation_params,
+                            hdfs_namenode_addr=hdfs_namenode_addr,
+                            hive_location=hive_location,
+                            hdfs_user=hdfs_user,
+                            hdfs_pass=hdfs_pass)
@@ -84,6 +84,8 @@ def explain(datasource,
                          feature_field_meta, is_pai, pai_explain_table)
 
     shap_values, shap_interaction_values, expected_value = xgb_shap_values(x)
+    explainer.save_attributions(feature_column_names,
+                                explainer.mean_abs_shap_values(shap_values))
 
     if result_table != "":
         if is_pai:
@@ -32,7 +32,10 @@ def pred(datasource,
          hdfs_pass="",
          pai_table="",
          model_params=None,
-         train_params=None):
+         train_params=None,
+         write_batch_size=100,
+         num_writers=1,
+         partition=""):
     if not is_pai:
         conn = db.connect_with_data_source(datasource)
     else:
@@ -59,7 +62,8 @@ def pred(datasource,
                                  model_params, feature_column_names,
                                  label_name, is_pai, conn, result_table,
                                  hdfs_namenode_addr, hive_location, hdfs_user,
-                                 hdfs_pass)
+                                 hdfs_pass, write_batch_size, num_writers,
+                                 datasource, partition)
         feature_file_id += 1
     print("Done predicting. Predict table : %s" % result_table)
 
@@ -67,7 +71,8 @@ def pred(datasource,
 def predict_and_store_result(bst, dpred, feature_file_id, model_params,
                              feature_column_names, label_name, is_pai, conn,
                              result_table, hdfs_namenode_addr, hive_location,
-                             hdfs_user, hdfs_pass):
+                             hdfs_user, hdfs_pass, write_batch_size,
+                             num_writers, datasource, partition):
     preds = bst.predict(dpred)
 
     #TODO(yancey1989): should save train_params and model_params not only on PAI submitter
@@ -102,11 +107,14 @@ def predict_and_store_result(bst, dpred, feature_file_id, model_params,
                                conn,
                                result_table,
                                result_column_names,
-                               100,
+                               write_batch_size,
                                hdfs_namenode_addr=hdfs_namenode_addr,
                                hive_location=hive_location,
                                hdfs_user=hdfs_user,
-                               hdfs_pass=hdfs_pass) as w:
+                               hdfs_pass=hdfs_pass,
+                               num_writers=num_writers,
+                               datasource=datasource,
+                               partition=partition) as w:
         while True:
             line = feature_file_read.readline()
             if not line:
@@ -15,7 +15,9 @@ import os
 
 import sqlflow_submitter.tensorflow.pai_distributed as pai_dist
 import xgboost as xgb
+from sqlflow_submitter import imbalance, progress
 from sqlflow_submitter.pai import model
+from sqlflow_submitter.warm_start import warm_start_file
 from sqlflow_submitter.xgboost.dataset import xgb_dataset
 from sqlflow_submitter.xgboost.pai_rabit import (PaiXGBoostTracker,
                                                  PaiXGBoostWorker)
@@ -109,7 +111,10 @@ def train(datasource,
           pai_validate_table="",
           rank=0,
           nworkers=1,
-          oss_model_dir=""):
+          oss_model_dir="",
+          warm_start=False,
+          class_weight=None,
+          oversample_minority=False):
     if batch_size == -1:
         batch_size = None
     print("Start training XGBoost model...")
@@ -131,8 +136,15 @@ def train(datasource,
             xgb_dataset(datasource, 'validate.txt', validation_select,
                         feature_metas, feature_column_names, label_meta,
                         is_pai, pai_validate_table, rank, nworkers))[0]
-    bst = None
+    # xgb.train continues training the model file of xgb_model.
+    bst = warm_start_file("my_model") if warm_start else None
     for per_batch_dmatrix in dtrain:
+        if class_weight is not None or oversample_minority:
+            # Weight the rows by the labels of the batch, which is all the
+            # training data if train.batch_size is -1.
+            per_batch_dmatrix.set_weight(
+                imbalance.label_weights(per_batch_dmatrix.get_label(),
+                                        class_weight, oversample_minority))
         watchlist = [(per_batch_dmatrix, "train")]
         if len(validation_select.strip()) > 0:
             watchlist.append((dvalidate, "validate"))
@@ -143,6 +155,10 @@ def train(datasource,
                         evals=watchlist,
                         evals_result=re,
                         xgb_model=bst,
+                        callbacks=[
+                            progress.xgboost_callback(
+                                train_params.get("num_boost_round", 10))
+                        ],
                         **train_params)
         bst.save_model("my_model")
         print("Evaluation result: %s" % re)
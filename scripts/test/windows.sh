@@ -0,0 +1,32 @@
+#!/bin/bash
+# Copyright 2020 The SQLFlow Authors. All rights reserved.
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# This script runs the unit tests of the client-side packages that don't
+# require a database on Windows.
+
+set -e
+
+echo "Install protoc ..."
+choco install protoc
+protoc --version
+
+echo "Install protoc-gen-go ..."
+go get github.com/golang/protobuf/protoc-gen-go@v1.3.3
+export PATH=$PATH:$(go env GOPATH)/bin
+
+cd "$TRAVIS_BUILD_DIR"
+go generate ./pkg/proto/...
+
+# Tests whose names contain DB require a database server.
+go test -v -run 'File|Context|Compression|TarGz' ./pkg/model/...
@@ -80,6 +80,11 @@ echo "Build cmd/sqlflow into /tmp ..."
 cd $TRAVIS_BUILD_DIR
 go generate ./...
 GOBIN=/tmp go install ./cmd/sqlflow
+# go install names the executable sqlflow.exe on Windows.
+BIN="sqlflow"
+if [[ "$TRAVIS_OS_NAME" == "windows" ]]; then
+    BIN="sqlflow.exe"
+fi
 
 
 echo "Install Qiniu client for $TRAVIS_OS_NAME ..."
@@ -96,5 +101,5 @@ sudo mv $F /usr/local/bin/qshell
 echo "Publish /tmp/sqlflow to Qiniu Object Storage ..."
 qshell account "$QINIU_AK" "$QINIU_SK" "wu"
 qshell rput sqlflow-release \
-       $RELEASE_TAG/$TRAVIS_OS_NAME/sqlflow \
-       /tmp/sqlflow
+       $RELEASE_TAG/$TRAVIS_OS_NAME/$BIN \
+       /tmp/$BIN